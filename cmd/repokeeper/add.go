@@ -27,7 +27,7 @@ var addCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
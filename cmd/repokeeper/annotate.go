@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <repo-id-or-path | pattern> [key=value | key-]...",
+	Short: "View or update annotations for a tracked repository",
+	Long: "Sets (key=value) or removes (key-) registry annotations on one tracked repository, " +
+		"selected the same way as describe. Removing a key that is not set is a no-op. " +
+		"--list prints the annotations after any changes are applied. " +
+		"With --match glob|regex the first argument is a pattern and every repo whose ID matches is updated.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		setValues, removeKeys, err := parseAnnotateArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		if !list && len(setValues) == 0 && len(removeKeys) == 0 {
+			return fmt.Errorf("nothing to do: pass key=value, key-, or --list")
+		}
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "" && format != "table" && format != "json" {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		cfgRoot := config.EffectiveRoot(cfgPath)
+
+		registryOverride, _ := cmd.Flags().GetString("registry")
+		var reg *registry.Registry
+		if registryOverride != "" {
+			reg, err = registry.Load(registryOverride)
+			if err != nil {
+				return err
+			}
+		} else {
+			reg = cfg.Registry
+			if reg == nil {
+				return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+			}
+		}
+
+		if matchMode, _ := cmd.Flags().GetString("match"); strings.TrimSpace(matchMode) != "" {
+			if list {
+				return fmt.Errorf("--list is not supported with --match")
+			}
+			result, applied, err := applyBulkMetadataChange(cmd, reg, matchMode, args[0], "annotations", func(entry *registry.Entry) bool {
+				updated := applyMetadataChanges(entry.Annotations, setValues, removeKeys)
+				if maps.Equal(updated, entry.Annotations) {
+					return false
+				}
+				entry.Annotations = updated
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			if !applied {
+				infof(cmd, "annotate cancelled")
+				return nil
+			}
+			if result.Updated > 0 {
+				if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, reg); err != nil {
+					return err
+				}
+			}
+			return writeBulkMetadataResult(cmd, result, "annotated", format)
+		}
+
+		entry, err := selectRegistryEntryForDescribe(reg.Entries, args[0], cwd, []string{cfgRoot})
+		if err != nil {
+			return err
+		}
+		idx := findRegistryEntryIndex(reg.Entries, entry)
+		if idx < 0 {
+			return fmt.Errorf("entry not found for selector %q", args[0])
+		}
+
+		updated := applyMetadataChanges(entry.Annotations, setValues, removeKeys)
+		if !maps.Equal(updated, entry.Annotations) {
+			entry.Annotations = updated
+			entry.LastSeen = time.Now()
+			reg.Entries[idx] = entry
+			reg.UpdatedAt = time.Now()
+
+			if registryOverride != "" {
+				if err := registry.Save(reg, registryOverride); err != nil {
+					return err
+				}
+			} else {
+				cfg.Registry = reg
+				if err := config.Save(cfg, cfgPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !list {
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "annotated %s\n", entry.RepoID)
+			return err
+		}
+		if format == "json" {
+			payload := struct {
+				RepoID      string            `json:"repo_id"`
+				Path        string            `json:"path"`
+				Annotations map[string]string `json:"annotations,omitempty"`
+			}{
+				RepoID:      entry.RepoID,
+				Path:        entry.Path,
+				Annotations: entry.Annotations,
+			}
+			data, err := json.MarshalIndent(payload, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return err
+		}
+		return writeAnnotationList(cmd, entry)
+	},
+}
+
+// parseAnnotateArgs splits kubectl-style annotate arguments into assignments
+// (key=value) and removals (key-). A later argument for the same key wins.
+func parseAnnotateArgs(args []string) (map[string]string, []string, error) {
+	setValues := make(map[string]string)
+	var removeKeys []string
+	for _, raw := range args {
+		expr := strings.TrimSpace(raw)
+		if strings.Contains(expr, "=") {
+			assignment, err := parseMetadataAssignments([]string{expr}, "annotation")
+			if err != nil {
+				return nil, nil, err
+			}
+			for key, value := range assignment {
+				setValues[key] = value
+				removeKeys = slices.DeleteFunc(removeKeys, func(k string) bool { return k == key })
+			}
+			continue
+		}
+		key, ok := strings.CutSuffix(expr, "-")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid annotation %q: expected key=value or key-", raw)
+		}
+		if err := validateMetadataKey(key, "annotation"); err != nil {
+			return nil, nil, err
+		}
+		delete(setValues, key)
+		if !slices.Contains(removeKeys, key) {
+			removeKeys = append(removeKeys, key)
+		}
+	}
+	return setValues, removeKeys, nil
+}
+
+// applyMetadataChanges returns a copy of a label or annotation map with the
+// changes applied. An emptied map is returned as nil so the registry omits it.
+func applyMetadataChanges(current, setValues map[string]string, removeKeys []string) map[string]string {
+	updated := cloneMetadataMap(current)
+	if updated == nil {
+		updated = make(map[string]string)
+	}
+	for key, value := range setValues {
+		updated[key] = value
+	}
+	for _, key := range removeKeys {
+		delete(updated, key)
+	}
+	return normalizeMetadataMap(updated)
+}
+
+func writeAnnotationList(cmd *cobra.Command, entry registry.Entry) error {
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "REPO: %s\n", entry.RepoID); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "PATH: %s\n", entry.Path); err != nil {
+		return err
+	}
+	if len(entry.Annotations) == 0 {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "ANNOTATIONS: -")
+		return err
+	}
+	keys := make([]string, 0, len(entry.Annotations))
+	for key := range entry.Annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), "ANNOTATIONS:"); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "- %s=%s\n", key, entry.Annotations[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	annotateCmd.Flags().String("registry", "", "override registry file path")
+	annotateCmd.Flags().String("match", "", matchUsage)
+	annotateCmd.Flags().Bool("list", false, "print the repository's annotations after applying any changes")
+	annotateCmd.Flags().StringP("format", "o", "table", "output format for --list: table or json")
+	rootCmd.AddCommand(annotateCmd)
+}
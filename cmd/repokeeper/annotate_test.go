@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func runAnnotate(t *testing.T, list bool, args ...string) *bytes.Buffer {
+	t.Helper()
+	out := &bytes.Buffer{}
+	annotateCmd.SetOut(out)
+	annotateCmd.SetContext(context.Background())
+	defer annotateCmd.SetOut(os.Stdout)
+	_ = annotateCmd.Flags().Set("registry", "")
+	_ = annotateCmd.Flags().Set("format", "json")
+	if list {
+		_ = annotateCmd.Flags().Set("list", "true")
+	}
+	defer func() {
+		_ = annotateCmd.Flags().Set("list", "false")
+		_ = annotateCmd.Flags().Set("format", "table")
+	}()
+	if err := annotateCmd.RunE(annotateCmd, args); err != nil {
+		t.Fatalf("annotate %v: %v", args, err)
+	}
+	return out
+}
+
+func loadAnnotations(t *testing.T, cfgPath string) registry.Entry {
+	t.Helper()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	entry := cfg.Registry.FindByRepoID("github.com/org/repo-a")
+	if entry == nil {
+		t.Fatal("expected entry")
+	}
+	return *entry
+}
+
+func TestAnnotateCommandSetsOverwritesAndDeletes(t *testing.T) {
+	tmp := t.TempDir()
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo-a", Path: filepath.Join(tmp, "repo-a"), Status: registry.StatusPresent, LastSeen: time.Now()},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	runAnnotate(t, false, "github.com/org/repo-a", "owner=sre", "ticket=OPS-1")
+	if got := loadAnnotations(t, cfgPath).Annotations; got["owner"] != "sre" || got["ticket"] != "OPS-1" {
+		t.Fatalf("expected annotations set, got %#v", got)
+	}
+
+	out := runAnnotate(t, true, "github.com/org/repo-a", "owner=platform")
+	var listed struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &listed); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if listed.Annotations["owner"] != "platform" || listed.Annotations["ticket"] != "OPS-1" {
+		t.Fatalf("expected overwritten owner in --list output, got %#v", listed.Annotations)
+	}
+
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	runAnnotate(t, false, "github.com/org/repo-a", "absent-")
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected deleting a missing key to leave the config untouched")
+	}
+
+	runAnnotate(t, false, "github.com/org/repo-a", "owner-", "ticket-")
+	if got := loadAnnotations(t, cfgPath).Annotations; got != nil {
+		t.Fatalf("expected annotations to be nil after deleting the last key, got %#v", got)
+	}
+}
+
+func TestParseAnnotateArgs(t *testing.T) {
+	set, remove, err := parseAnnotateArgs([]string{"a=1", "b-", "c=x-", "a-"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(set) != 1 || set["c"] != "x-" {
+		t.Fatalf("expected only c set (a removed later), got %#v", set)
+	}
+	if len(remove) != 2 || remove[0] != "b" || remove[1] != "a" {
+		t.Fatalf("unexpected removals %#v", remove)
+	}
+	for _, bad := range []string{"plain", "-", "=value", "sp ace-"} {
+		if _, _, err := parseAnnotateArgs([]string{bad}); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Execute a sync plan saved with sync --plan-only",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting apply")
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		cfgRoot := config.EffectiveRoot(cfgPath)
+		debugf(cmd, "using config %s", cfgPath)
+
+		reg := cfg.Registry
+		if reg == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+
+		planPath, _ := cmd.Flags().GetString("plan")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+		yes := assumeYes(cmd)
+		summary, _ := cmd.Flags().GetBool("summary")
+		retries, _ := cmd.Flags().GetInt("retries")
+		retryBackoff, _ := cmd.Flags().GetDuration("retry-backoff")
+		allowOversubscribe, _ := cmd.Flags().GetBool("allow-oversubscribe")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		wrap, _ := cmd.Flags().GetBool("wrap")
+		strictHooks, _ := cmd.Flags().GetBool("strict-hooks")
+		if strings.TrimSpace(planPath) == "" {
+			return fmt.Errorf("--plan is required")
+		}
+		if err := validateSyncExecutionFlags(timeout, retries, retryBackoff); err != nil {
+			return err
+		}
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+
+		plan, err := loadSavedSyncPlan(planPath)
+		if err != nil {
+			return err
+		}
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		eng := engine.New(cfg, reg, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), cmdLogger{cmd})
+		if err := eng.ValidateSyncPlan(plan); err != nil {
+			return err
+		}
+
+		logOutputWriteFailure(cmd, "sync plan", writeSyncPlan(cmd, plan, cwd, []string{cfgRoot}))
+		if !yes && syncPlanNeedsConfirmation(plan) {
+			confirmed, err := confirmSyncExecution(cmd, plan)
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				infof(cmd, "apply cancelled")
+				return nil
+			}
+		}
+
+		hook, err := postSyncHook(cfg.Hooks.PostSync)
+		if err != nil {
+			return err
+		}
+		results, err := executeSyncPlan(cmd, eng, plan, engine.SyncOptions{
+			Concurrency:        concurrency,
+			Timeout:            timeout,
+			ContinueOnError:    continueOnError,
+			RetryAttempts:      retries,
+			RetryBackoff:       retryBackoff,
+			AllowOversubscribe: allowOversubscribe,
+			MaxJobs:            maxJobs,
+			PostSync:           hook,
+			StrictHooks:        strictHooks,
+		}, cwd, []string{cfgRoot}, false)
+		if err != nil {
+			return err
+		}
+		if err := persistSyncRegistryAfterCheckoutMissing(cfg, cfgPath, results); err != nil {
+			return err
+		}
+		if err := reportSyncResults(cmd, results, syncReportOptions{
+			mode:      mode,
+			format:    format,
+			cwd:       cwd,
+			roots:     []string{cfgRoot},
+			noHeaders: noHeaders,
+			wrap:      wrap,
+			summary:   summary,
+		}); err != nil {
+			return err
+		}
+		infof(cmd, "apply completed: %d repos", len(results))
+		return nil
+	},
+}
+
+// loadSavedSyncPlan reads a plan written by sync --plan-only.
+func loadSavedSyncPlan(path string) ([]engine.SyncResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var saved engine.SavedSyncPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("invalid sync plan %q: %w", path, err)
+	}
+	plan, err := saved.SyncResults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync plan %q: %w", path, err)
+	}
+	return plan, nil
+}
+
+func init() {
+	applyCmd.Flags().String("plan", "", "sync plan file written by sync --plan-only")
+	applyCmd.Flags().Int("concurrency", 0, "max concurrent repo operations (default: min(8, NumCPU))")
+	applyCmd.Flags().Bool("allow-oversubscribe", false, allowOversubscribeUsage)
+	applyCmd.Flags().Int("timeout", 0, "timeout in seconds per repo (0 uses config default)")
+	applyCmd.Flags().Bool("continue-on-error", true, "continue applying remaining repos after a per-repo failure")
+	applyCmd.Flags().Bool("summary", false, syncSummaryUsage)
+	applyCmd.Flags().Int("retries", 0, retriesUsage)
+	applyCmd.Flags().Duration("retry-backoff", time.Second, retryBackoffUsage)
+	applyCmd.Flags().Bool("strict-hooks", false, strictHooksUsage)
+	addFormatFlag(applyCmd, "output format: table, wide, json, or yaml")
+	addNoHeadersFlag(applyCmd)
+	applyCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
+	addVCSFlag(applyCmd)
+
+	rootCmd.AddCommand(applyCmd)
+}
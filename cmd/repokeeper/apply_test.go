@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func savePlanOnlySyncPlan(t *testing.T, cfgPath, planPath string) {
+	t.Helper()
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	syncCmd.SetOut(&bytes.Buffer{})
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("dry-run", "false")
+	_ = syncCmd.Flags().Set("checkout-missing", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	_ = syncCmd.Flags().Set("plan-only", "true")
+	_ = syncCmd.Flags().Set("output", planPath)
+	defer func() {
+		_ = syncCmd.Flags().Set("checkout-missing", "false")
+		_ = syncCmd.Flags().Set("plan-only", "false")
+		_ = syncCmd.Flags().Set("output", "")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("sync --plan-only: %v", err)
+	}
+}
+
+func runApplyWithPlan(t *testing.T, cfgPath, planPath string) (*bytes.Buffer, error) {
+	t.Helper()
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	applyCmd.SetOut(out)
+	applyCmd.SetErr(&bytes.Buffer{})
+	applyCmd.SetContext(context.Background())
+	defer applyCmd.SetOut(os.Stdout)
+	defer applyCmd.SetErr(os.Stderr)
+
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	_ = applyCmd.Flags().Set("plan", planPath)
+	_ = applyCmd.Flags().Set("format", "json")
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = applyCmd.Flags().Set("plan", "")
+	}()
+
+	return out, applyCmd.RunE(applyCmd, nil)
+}
+
+func TestSyncPlanOnlySavesPlanAndApplyExecutesIt(t *testing.T) {
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+	planPath := filepath.Join(t.TempDir(), "plans", "plan.json")
+
+	savePlanOnlySyncPlan(t, cfgPath, planPath)
+	if _, err := os.Stat(missingPath); !os.IsNotExist(err) {
+		t.Fatalf("expected --plan-only not to clone, stat err: %v", err)
+	}
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		t.Fatalf("read saved plan: %v", err)
+	}
+	var saved engine.SavedSyncPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("decode saved plan: %v", err)
+	}
+	if len(saved.Items) != 1 || !saved.Items[0].Planned || strings.Join(saved.Items[0].Steps, ",") != "clone" {
+		t.Fatalf("unexpected saved plan: %+v", saved)
+	}
+
+	out, err := runApplyWithPlan(t, cfgPath, planPath)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	var results []syncResultJSON
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("decode apply output %q: %v", out.String(), err)
+	}
+	if len(results) != 1 || !results[0].OK || results[0].Outcome != string(engine.SyncOutcomeCheckoutMissing) {
+		t.Fatalf("unexpected apply results: %+v", results)
+	}
+	if _, err := os.Stat(filepath.Join(missingPath, ".git")); err != nil {
+		t.Fatalf("expected planned clone to run: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if cfg.Registry.Entries[0].Status != registry.StatusPresent {
+		t.Fatalf("expected registry entry to be marked present, got %q", cfg.Registry.Entries[0].Status)
+	}
+}
+
+func TestApplyRejectsPlanThatNoLongerMatchesRegistry(t *testing.T) {
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+	planPath := filepath.Join(t.TempDir(), "plan.json")
+	savePlanOnlySyncPlan(t, cfgPath, planPath)
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Registry.Entries[0].Path = filepath.Join(filepath.Dir(missingPath), "moved")
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	_, err = runApplyWithPlan(t, cfgPath, planPath)
+	if err == nil || !strings.Contains(err.Error(), "github.com/org/repo-checkout") {
+		t.Fatalf("expected registry mismatch error, got %v", err)
+	}
+	if _, statErr := os.Stat(missingPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no clone for rejected plan, stat err: %v", statErr)
+	}
+}
+
+func TestSyncPlanOnlyFlagValidation(t *testing.T) {
+	cfgPath, _ := setupCheckoutMissingSyncFixture(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	syncCmd.SetContext(context.Background())
+
+	_ = syncCmd.Flags().Set("plan-only", "true")
+	err := syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("plan-only", "false")
+	if err == nil || !strings.Contains(err.Error(), "--plan-only requires --output") {
+		t.Fatalf("expected --plan-only without --output to fail, got %v", err)
+	}
+
+	_ = syncCmd.Flags().Set("output", "plan.json")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("output", "")
+	if err == nil || !strings.Contains(err.Error(), "--output requires --plan-only") {
+		t.Fatalf("expected --output without --plan-only to fail, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <repo-id-or-path>",
+	Short: "Mark a tracked repository archived so status and sync skip it",
+	Long: "Marks one registry entry, selected the same way as describe, as archived. Archived entries stay in the " +
+		"registry and on disk, and describe still shows them, but status/get and sync/reconcile skip them unless " +
+		"--include-archived is given. --undo clears the mark.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		undo, _ := cmd.Flags().GetBool("undo")
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		target, err := loadRegistryTarget(cmd, cwd)
+		if err != nil {
+			return err
+		}
+		entry, err := selectRegistryEntryForDescribe(target.reg.Entries, args[0], cwd, []string{config.EffectiveRoot(target.cfgPath)})
+		if err != nil {
+			return err
+		}
+		idx := findRegistryEntryIndex(target.reg.Entries, entry)
+		if idx < 0 {
+			return fmt.Errorf("entry not found for selector %q", args[0])
+		}
+
+		verb := "archived"
+		if undo {
+			verb = "unarchived"
+		}
+		if entry.Archived == !undo {
+			_, err := fmt.Fprintf(cmd.OutOrStdout(), "%s is already %s\n", entry.RepoID, verb)
+			return err
+		}
+		entry.Archived = !undo
+		target.reg.Entries[idx] = entry
+		target.reg.UpdatedAt = time.Now()
+		if err := target.save(); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", verb, entry.RepoID)
+		return err
+	},
+}
+
+func init() {
+	archiveCmd.Flags().String("registry", "", "override registry file path")
+	archiveCmd.Flags().Bool("undo", false, "clear the archived mark instead of setting it")
+	rootCmd.AddCommand(archiveCmd)
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// setupArchiveFixture registers two git repos, active and old.
+func setupArchiveFixture(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{}
+	for _, name := range []string{"active", "old"} {
+		repoPath := filepath.Join(tmp, name)
+		if out, err := exec.Command("git", "init", repoPath).CombinedOutput(); err != nil {
+			t.Fatalf("git init %s: %v %s", name, err, out)
+		}
+		cfg.Registry.Entries = append(cfg.Registry.Entries, registry.Entry{
+			RepoID: "github.com/org/" + name, Path: repoPath, Status: registry.StatusPresent, LastSeen: time.Now(),
+		})
+	}
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return cfgPath
+}
+
+func runArchive(t *testing.T, undo bool, selector string) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	archiveCmd.SetOut(out)
+	archiveCmd.SetContext(context.Background())
+	defer archiveCmd.SetOut(os.Stdout)
+	_ = archiveCmd.Flags().Set("undo", boolToFlag(undo))
+	defer func() { _ = archiveCmd.Flags().Set("undo", "false") }()
+	if err := archiveCmd.RunE(archiveCmd, []string{selector}); err != nil {
+		t.Fatalf("archive %s: %v", selector, err)
+	}
+	return out.String()
+}
+
+func runStatusJSON(t *testing.T, includeArchived bool) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("registry", "")
+	_ = statusCmd.Flags().Set("format", "json")
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("include-archived", boolToFlag(includeArchived))
+	defer func() { _ = statusCmd.Flags().Set("include-archived", "false") }()
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	return out.String()
+}
+
+func TestArchiveCommandSetsAndClearsTheMark(t *testing.T) {
+	cfgPath := setupArchiveFixture(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	archived := func() bool {
+		cfg, err := config.Load(cfgPath)
+		if err != nil {
+			t.Fatalf("reload config: %v", err)
+		}
+		return cfg.Registry.FindByRepoID("github.com/org/old").Archived
+	}
+	if got := runArchive(t, false, "github.com/org/old"); got != "archived github.com/org/old\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if !archived() {
+		t.Fatal("expected entry to be archived")
+	}
+	if got := runArchive(t, false, "github.com/org/old"); got != "github.com/org/old is already archived\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if got := runArchive(t, true, "github.com/org/old"); got != "unarchived github.com/org/old\n" {
+		t.Fatalf("unexpected output %q", got)
+	}
+	if archived() {
+		t.Fatal("expected --undo to clear the mark")
+	}
+}
+
+func TestArchivedEntriesSkippedByStatusAndSyncButShownByDescribe(t *testing.T) {
+	cfgPath := setupArchiveFixture(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	runArchive(t, false, "github.com/org/old")
+	activePath := filepath.Join(filepath.Dir(cfgPath), "active")
+	oldPath := filepath.Join(filepath.Dir(cfgPath), "old")
+
+	got := runStatusJSON(t, false)
+	if !strings.Contains(got, activePath) || strings.Contains(got, oldPath) {
+		t.Fatalf("expected status to skip the archived repo by default, got %s", got)
+	}
+	got = runStatusJSON(t, true)
+	if !strings.Contains(got, oldPath) || !strings.Contains(got, `"archived": true`) {
+		t.Fatalf("expected --include-archived to report the archived repo, got %s", got)
+	}
+
+	out := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("dry-run", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	defer func() { _ = syncCmd.Flags().Set("dry-run", "false") }()
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if !strings.Contains(out.String(), activePath) || strings.Contains(out.String(), oldPath) {
+		t.Fatalf("expected sync to skip the archived repo, got %s", out.String())
+	}
+
+	out.Reset()
+	describeRepoCmd.SetOut(out)
+	describeRepoCmd.SetErr(&bytes.Buffer{})
+	describeRepoCmd.SetContext(context.Background())
+	defer describeRepoCmd.SetOut(os.Stdout)
+	defer describeRepoCmd.SetErr(os.Stderr)
+	_ = describeRepoCmd.Flags().Set("registry", "")
+	_ = describeRepoCmd.Flags().Set("format", "table")
+	if err := describeRepoCmd.RunE(describeRepoCmd, []string{"github.com/org/old"}); err != nil {
+		t.Fatalf("describe archived repo: %v", err)
+	}
+	if !strings.Contains(out.String(), "ARCHIVED: true") {
+		t.Fatalf("expected describe to show the archived repo, got %q", out.String())
+	}
+}
@@ -4,6 +4,7 @@ package repokeeper
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"os/exec"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
 	"github.com/skaphos/repokeeper/internal/repometa"
@@ -79,6 +81,33 @@ func withAssumeYes(t *testing.T, yes bool) func() {
 	}
 }
 
+func TestStatusRunEYAMLFormat(t *testing.T) {
+	cfgPath, regPath := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+
+	_ = statusCmd.Flags().Set("registry", regPath)
+	_ = statusCmd.Flags().Set("format", "yaml")
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("selector", "")
+	defer func() { _ = statusCmd.Flags().Set("format", "table") }()
+
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status -o yaml: %v", err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, "apiVersion: ") || !strings.Contains(got, "repo_id: github.com/org/repo-missing") {
+		t.Fatalf("expected YAML status report, got %q", got)
+	}
+}
+
 func TestStatusRunEUnsupportedFormat(t *testing.T) {
 	cfgPath, regPath := writeTestConfigAndRegistry(t)
 	cleanup := withTestConfig(t, cfgPath)
@@ -93,7 +122,7 @@ func TestStatusRunEUnsupportedFormat(t *testing.T) {
 	defer statusCmd.SetErr(os.Stderr)
 
 	_ = statusCmd.Flags().Set("registry", regPath)
-	_ = statusCmd.Flags().Set("format", "yaml")
+	_ = statusCmd.Flags().Set("format", "xml")
 	_ = statusCmd.Flags().Set("only", "all")
 	_ = statusCmd.Flags().Set("selector", "")
 
@@ -187,6 +216,279 @@ func TestStatusRunELocalLabelSelectorFilter(t *testing.T) {
 	}
 }
 
+func TestStatusRunECountOnlyPrintsTallies(t *testing.T) {
+	tmp := t.TempDir()
+	clean := filepath.Join(tmp, "clean")
+	mustRunGit(t, tmp, "init", clean)
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/org/clean", Path: clean, Status: registry.StatusPresent, LastSeen: time.Now()},
+			{RepoID: "github.com/org/gone", Path: filepath.Join(tmp, "gone"), Status: registry.StatusMissing, LastSeen: time.Now()},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	state := runtimeStateFor(statusCmd)
+	prevExitCode := state.exitCode
+	defer func() { state.exitCode = prevExitCode }()
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("field-selector", "")
+	_ = statusCmd.Flags().Set("selector", "")
+	_ = statusCmd.Flags().Set("local-selector", "")
+	_ = statusCmd.Flags().Set("registry", "")
+	_ = statusCmd.Flags().Set("count-only", "true")
+	defer func() {
+		_ = statusCmd.Flags().Set("count-only", "false")
+		_ = statusCmd.Flags().Set("format", "table")
+	}()
+
+	_ = statusCmd.Flags().Set("format", "table")
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --count-only: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "TOTAL") || strings.Join(strings.Fields(lines[1]), " ") != "2 1 0 0 0 1 0" {
+		t.Fatalf("expected a single tally row, got %q", out.String())
+	}
+	if state.exitCode != 2 {
+		t.Fatalf("expected the missing repo to keep exit code 2, got %d", state.exitCode)
+	}
+
+	out.Reset()
+	_ = statusCmd.Flags().Set("format", "json")
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --count-only -o json: %v", err)
+	}
+	var summary engine.StatusSummary
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("decode summary %q: %v", out.String(), err)
+	}
+	if summary != (engine.StatusSummary{Total: 2, Clean: 1, Missing: 1}) {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	out.Reset()
+	_ = statusCmd.Flags().Set("format", "csv")
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --count-only -o csv: %v", err)
+	}
+	if got := out.String(); got != "total,clean,dirty,diverged,gone,missing,errors\n2,1,0,0,0,1,0\n" {
+		t.Fatalf("expected the tally as one CSV row, got %q", got)
+	}
+
+	_ = statusCmd.Flags().Set("format", "ndjson")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil || !strings.Contains(err.Error(), "--count-only is not supported") {
+		t.Fatalf("expected --count-only to reject ndjson, got %v", err)
+	}
+
+	_ = statusCmd.Flags().Set("count-only", "false")
+	_ = statusCmd.Flags().Set("group-by", "host")
+	defer func() { _ = statusCmd.Flags().Set("group-by", "") }()
+	_ = statusCmd.Flags().Set("format", "csv")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil || !strings.Contains(err.Error(), "--group-by is not supported with -o csv") {
+		t.Fatalf("expected --group-by to reject csv, got %v", err)
+	}
+}
+
+func TestStatusRunESortValidation(t *testing.T) {
+	cfgPath, regPath := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	statusCmd.SetOut(&bytes.Buffer{})
+	statusCmd.SetErr(&bytes.Buffer{})
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("registry", regPath)
+	defer func() {
+		_ = statusCmd.Flags().Set("sort", "")
+		_ = statusCmd.Flags().Set("format", "table")
+		_ = statusCmd.Flags().Set("severity", "false")
+		_ = statusCmd.Flags().Set("only", "all")
+	}()
+
+	_ = statusCmd.Flags().Set("sort", "size")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil || !strings.Contains(err.Error(), "invalid --sort") {
+		t.Fatalf("expected unknown sort key error, got %v", err)
+	}
+
+	_ = statusCmd.Flags().Set("sort", "-path")
+	_ = statusCmd.Flags().Set("format", "ndjson")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil || !strings.Contains(err.Error(), "--sort is not supported with -o ndjson") {
+		t.Fatalf("expected --sort to reject ndjson, got %v", err)
+	}
+
+	_ = statusCmd.Flags().Set("format", "table")
+	_ = statusCmd.Flags().Set("only", "diverged")
+	_ = statusCmd.Flags().Set("severity", "true")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil || !strings.Contains(err.Error(), "--sort cannot be combined with --severity") {
+		t.Fatalf("expected --sort to reject --severity, got %v", err)
+	}
+}
+
+func TestStatusRunENDJSONStreamsOneRepoPerLine(t *testing.T) {
+	tmp := t.TempDir()
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/org/repo-a", Path: filepath.Join(tmp, "missing-a"), Status: registry.StatusMissing, LastSeen: time.Now(), Labels: map[string]string{"team": "a"}},
+			{RepoID: "github.com/org/repo-b", Path: filepath.Join(tmp, "missing-b"), Status: registry.StatusMissing, LastSeen: time.Now()},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	// statusCmd is not attached to rootCmd, so it carries its own runtime state.
+	state := runtimeStateFor(statusCmd)
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("format", "ndjson")
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("field-selector", "")
+	_ = statusCmd.Flags().Set("selector", "")
+	_ = statusCmd.Flags().Set("local-selector", "")
+	_ = statusCmd.Flags().Set("registry", "")
+	defer func() { _ = statusCmd.Flags().Set("format", "table") }()
+
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status -o ndjson failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per repo, got %q", out.String())
+	}
+	seen := map[string]statusJSONRepo{}
+	for _, line := range lines {
+		var repo statusJSONRepo
+		if err := json.Unmarshal([]byte(line), &repo); err != nil {
+			t.Fatalf("decode line %q: %v", line, err)
+		}
+		seen[repo.RepoID] = repo
+	}
+	if seen["github.com/org/repo-a"].LocalLabels["team"] != "a" || seen["github.com/org/repo-b"].Error != "path missing" {
+		t.Fatalf("expected enriched per-repo objects, got %+v", seen)
+	}
+	if state.exitCode != 2 {
+		t.Fatalf("expected missing repos to raise exit code 2, got %d", state.exitCode)
+	}
+
+	_ = statusCmd.Flags().Set("severity", "true")
+	err := statusCmd.RunE(statusCmd, nil)
+	_ = statusCmd.Flags().Set("severity", "false")
+	if err == nil {
+		t.Fatal("expected --severity to be rejected with -o ndjson")
+	}
+}
+
+func TestStatusRunEBranchesBehindDefaultMatchesStaleFeatureBranch(t *testing.T) {
+	tmp := t.TempDir()
+	stale := filepath.Join(tmp, "stale")
+	fresh := filepath.Join(tmp, "fresh")
+	for _, repo := range []string{stale, fresh} {
+		mustRunGit(t, tmp, "init", "-b", "main", repo)
+		mustRunGit(t, repo, "commit", "--allow-empty", "-m", "init")
+		mustRunGit(t, repo, "checkout", "-q", "-b", "feature")
+		mustRunGit(t, repo, "commit", "--allow-empty", "-m", "feature work")
+		mustRunGit(t, repo, "checkout", "-q", "main")
+	}
+	for i := 0; i < 3; i++ {
+		mustRunGit(t, stale, "commit", "--allow-empty", "-m", "main moves on")
+	}
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "local/stale", Path: stale, Branch: "main", Status: registry.StatusPresent, LastSeen: time.Now()},
+		{RepoID: "local/fresh", Path: fresh, Branch: "main", Status: registry.StatusPresent, LastSeen: time.Now()},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("format", "json")
+	_ = statusCmd.Flags().Set("only", "branches-behind-default")
+	_ = statusCmd.Flags().Set("field-selector", "")
+	_ = statusCmd.Flags().Set("selector", "")
+	_ = statusCmd.Flags().Set("local-selector", "")
+	_ = statusCmd.Flags().Set("registry", "")
+	_ = statusCmd.Flags().Set("threshold", "2")
+	defer func() {
+		_ = statusCmd.Flags().Set("format", "table")
+		_ = statusCmd.Flags().Set("only", "all")
+		_ = statusCmd.Flags().Set("threshold", "1")
+		statusCmd.Flags().Lookup("threshold").Changed = false
+	}()
+
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --only branches-behind-default failed: %v", err)
+	}
+	var report statusJSONReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if len(report.Repos) != 1 || report.Repos[0].Path != stale {
+		t.Fatalf("expected only the repo with a stale feature branch, got %+v", report.Repos)
+	}
+	local := report.Repos[0].LocalBranches
+	if local.BehindBaseCount != 1 {
+		t.Fatalf("expected one branch counted behind default, got %d", local.BehindBaseCount)
+	}
+	for _, branch := range local.Branches {
+		if branch.Name == "feature" && (branch.BehindBase == nil || *branch.BehindBase != 3) {
+			t.Fatalf("expected feature 3 commits behind main, got %+v", branch.BehindBase)
+		}
+		if branch.Name == "main" && branch.BehindBase != nil {
+			t.Fatalf("expected the default branch itself to be skipped, got %d", *branch.BehindBase)
+		}
+	}
+
+	out.Reset()
+	_ = statusCmd.Flags().Set("threshold", "4")
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --threshold 4 failed: %v", err)
+	}
+	report = statusJSONReport{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if len(report.Repos) != 0 {
+		t.Fatalf("expected no repos above threshold 4, got %+v", report.Repos)
+	}
+
+	_ = statusCmd.Flags().Set("only", "all")
+	if err := statusCmd.RunE(statusCmd, nil); err == nil {
+		t.Fatal("expected --threshold without --only branches-behind-default to be rejected")
+	}
+}
+
 func TestStatusRunESelectorUsesSharedRepoLabels(t *testing.T) {
 	tmp := t.TempDir()
 	repoMetadataMatchPath := filepath.Join(tmp, "repo-metadata-match")
@@ -481,6 +783,38 @@ func TestSyncRunEJSONMissingFilter(t *testing.T) {
 	}
 }
 
+func TestSyncRunESummaryTableGoesToStderr(t *testing.T) {
+	cfgPath, _ := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(errOut)
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	_ = syncCmd.Flags().Set("only", "missing")
+	_ = syncCmd.Flags().Set("dry-run", "true")
+	_ = syncCmd.Flags().Set("format", "table")
+	_ = syncCmd.Flags().Set("summary", "true")
+	defer func() {
+		_ = syncCmd.Flags().Set("summary", "false")
+		_ = syncCmd.Flags().Set("format", "json")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("sync run failed: %v", err)
+	}
+	if strings.Contains(out.String(), "\"total\"") {
+		t.Fatalf("expected table-mode summary to stay off stdout, got %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), `"dry_run":true,"total":1,"ok":0,"applied":{},"planned":{},"skipped":{"skipped_missing":1}`) {
+		t.Fatalf("expected summary on stderr, got %q", errOut.String())
+	}
+}
+
 func TestRepairUpstreamRunEUnsupportedFormat(t *testing.T) {
 	cfgPath, regPath := writeTestConfigAndRegistry(t)
 	cleanup := withTestConfig(t, cfgPath)
@@ -533,6 +867,72 @@ func TestSyncRunEValidationFlags(t *testing.T) {
 	if err == nil || !strings.Contains(err.Error(), "--push-local requires --update-local") {
 		t.Fatalf("expected push-local validation error, got %v", err)
 	}
+
+	_ = syncCmd.Flags().Set("push-local", "false")
+	_ = syncCmd.Flags().Set("backup-branch", "backup/{branch}")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("backup-branch", "")
+	if err == nil || !strings.Contains(err.Error(), "--backup-branch requires --update-local") {
+		t.Fatalf("expected backup-branch validation error, got %v", err)
+	}
+
+	_ = syncCmd.Flags().Set("remote-template", "git@{host}:{owner}/{name}.git")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("remote-template", "")
+	if err == nil || !strings.Contains(err.Error(), "--remote-template requires --checkout-missing") {
+		t.Fatalf("expected remote-template validation error, got %v", err)
+	}
+
+	_ = syncCmd.Flags().Set("depth", "1")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("depth", "0")
+	if err == nil || !strings.Contains(err.Error(), "--depth requires --checkout-missing") {
+		t.Fatalf("expected depth validation error, got %v", err)
+	}
+
+	_ = syncCmd.Flags().Set("retries", "11")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("retries", "0")
+	if err == nil || !strings.Contains(err.Error(), "--retries must be between 0 and 10") {
+		t.Fatalf("expected retries validation error, got %v", err)
+	}
+
+	_ = syncCmd.Flags().Set("retry-backoff", "0s")
+	err = syncCmd.RunE(syncCmd, nil)
+	_ = syncCmd.Flags().Set("retry-backoff", "1s")
+	if err == nil || !strings.Contains(err.Error(), "--retry-backoff must be positive, got 0s") {
+		t.Fatalf("expected retry-backoff validation error, got %v", err)
+	}
+}
+
+func TestSyncRunEAllowOversubscribeKeepsHighConcurrency(t *testing.T) {
+	cfgPath, _ := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(errOut)
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	_ = syncCmd.Flags().Set("only", "missing")
+	_ = syncCmd.Flags().Set("dry-run", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	_ = syncCmd.Flags().Set("concurrency", "100")
+	_ = syncCmd.Flags().Set("allow-oversubscribe", "true")
+	defer func() {
+		_ = syncCmd.Flags().Set("concurrency", "0")
+		_ = syncCmd.Flags().Set("allow-oversubscribe", "false")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("expected --concurrency 100 --allow-oversubscribe to run, got %v", err)
+	}
+	if !strings.Contains(out.String(), "skipped_missing") {
+		t.Fatalf("expected the dry-run plan on stdout, got %q", out.String())
+	}
 }
 
 func TestSyncRunEUnsupportedFormat(t *testing.T) {
@@ -550,7 +950,7 @@ func TestSyncRunEUnsupportedFormat(t *testing.T) {
 	_ = syncCmd.Flags().Set("only", "missing")
 	_ = syncCmd.Flags().Set("dry-run", "true")
 	_ = syncCmd.Flags().Set("yes", "true")
-	_ = syncCmd.Flags().Set("format", "yaml")
+	_ = syncCmd.Flags().Set("format", "xml")
 	_ = syncCmd.Flags().Set("update-local", "false")
 	_ = syncCmd.Flags().Set("rebase-dirty", "false")
 	_ = syncCmd.Flags().Set("push-local", "false")
@@ -707,6 +1107,132 @@ func TestSyncRunEPersistsRegistryAfterCheckoutMissingClone(t *testing.T) {
 	}
 }
 
+func setupCheckoutMissingSyncFixture(t *testing.T) (cfgPath, missingPath string) {
+	t.Helper()
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	mustRunGit(t, tmp, "init", "--bare", remote)
+
+	seed := filepath.Join(tmp, "seed")
+	mustRunGit(t, tmp, "clone", remote, seed)
+	mustRunGit(t, seed, "checkout", "-b", "main")
+	mustRunGit(t, seed, "commit", "--allow-empty", "-m", "init")
+	mustRunGit(t, seed, "push", "-u", "origin", "main")
+
+	missingPath = filepath.Join(tmp, "missing-repo")
+	cfgPath = filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{
+				RepoID:    "github.com/org/repo-checkout",
+				Path:      missingPath,
+				RemoteURL: remote,
+				Branch:    "main",
+				Status:    registry.StatusMissing,
+				LastSeen:  time.Now(),
+			},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return cfgPath, missingPath
+}
+
+func runSyncWithPreRunCommand(t *testing.T, cfgPath, preRun string) (*bytes.Buffer, error) {
+	t.Helper()
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(errOut)
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("dry-run", "false")
+	_ = syncCmd.Flags().Set("checkout-missing", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	_ = syncCmd.Flags().Set("pre-run-command", preRun)
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = syncCmd.Flags().Set("checkout-missing", "false")
+		_ = syncCmd.Flags().Set("pre-run-command", "")
+	}()
+
+	return out, syncCmd.RunE(syncCmd, nil)
+}
+
+func TestSyncRunEPreRunCommandFailureAbortsBeforeExecution(t *testing.T) {
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+
+	out, err := runSyncWithPreRunCommand(t, cfgPath, "git rev-parse --verify refs/does/not/exist")
+	if err == nil || !strings.Contains(err.Error(), "pre-run command") {
+		t.Fatalf("expected pre-run command failure, got %v", err)
+	}
+	if _, statErr := os.Stat(missingPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no clone after failed pre-run command, stat err: %v", statErr)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no sync results after abort, got %q", out.String())
+	}
+}
+
+func TestSyncRunEPreRunCommandSuccessProceeds(t *testing.T) {
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+
+	out, err := runSyncWithPreRunCommand(t, cfgPath, "git --version")
+	if err != nil {
+		t.Fatalf("sync run failed: %v", err)
+	}
+	if _, statErr := os.Stat(missingPath); statErr != nil {
+		t.Fatalf("expected repo cloned after successful pre-run command: %v", statErr)
+	}
+	if !strings.Contains(out.String(), "\"repo_id\": \"github.com/org/repo-checkout\"") {
+		t.Fatalf("expected sync results after pre-run command, got %q", out.String())
+	}
+}
+
+func TestSyncRunEPreRunCommandInvalidQuoting(t *testing.T) {
+	cfgPath, _ := setupCheckoutMissingSyncFixture(t)
+
+	_, err := runSyncWithPreRunCommand(t, cfgPath, "echo 'unterminated")
+	if err == nil || !strings.Contains(err.Error(), "invalid --pre-run-command") {
+		t.Fatalf("expected invalid pre-run command error, got %v", err)
+	}
+}
+
+func TestSyncRunEResetHardRejectsLocalUpdates(t *testing.T) {
+	cfgPath, _ := setupCheckoutMissingSyncFixture(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	syncCmd.SetOut(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer func() {
+		_ = syncCmd.Flags().Set("reset-hard", "false")
+		_ = syncCmd.Flags().Set("update-local", "false")
+		_ = syncCmd.Flags().Set("autostash-all", "false")
+	}()
+
+	_ = syncCmd.Flags().Set("reset-hard", "true")
+	for _, flag := range []string{"update-local", "autostash-all"} {
+		_ = syncCmd.Flags().Set(flag, "true")
+		err := syncCmd.RunE(syncCmd, nil)
+		if err == nil || !strings.Contains(err.Error(), "--reset-hard cannot be combined with --"+flag) {
+			t.Fatalf("expected --%s conflict, got %v", flag, err)
+		}
+		_ = syncCmd.Flags().Set(flag, "false")
+	}
+}
+
 func TestDescribeRunEPaths(t *testing.T) {
 	cfgPath, regPath := writeTestConfigAndRegistry(t)
 	cleanup := withTestConfig(t, cfgPath)
@@ -907,6 +1433,96 @@ func TestStatusRunEIncludesRepoMetadata(t *testing.T) {
 	}
 }
 
+func TestStatusRunEVerifyIgnoredFlagsReposWithIgnoredFiles(t *testing.T) {
+	tmp := t.TempDir()
+	initCleanRepo := func(name string, ignored bool) string {
+		repoPath := filepath.Join(tmp, name)
+		mustRunGit(t, tmp, "init", repoPath)
+		if err := os.WriteFile(filepath.Join(repoPath, ".gitignore"), []byte("*.log\n"), 0o644); err != nil {
+			t.Fatalf("write gitignore: %v", err)
+		}
+		mustRunGit(t, repoPath, "add", ".gitignore")
+		mustRunGit(t, repoPath, "commit", "-m", "init")
+		if ignored {
+			if err := os.WriteFile(filepath.Join(repoPath, "notes.log"), []byte("work in progress\n"), 0o644); err != nil {
+				t.Fatalf("write ignored file: %v", err)
+			}
+		}
+		return repoPath
+	}
+	flaggedPath := initCleanRepo("repo-ignored", true)
+	cleanPath := initCleanRepo("repo-plain", false)
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "local/repo-ignored", Path: flaggedPath, Status: registry.StatusPresent, LastSeen: time.Now()},
+		{RepoID: "local/repo-plain", Path: cleanPath, Status: registry.StatusPresent, LastSeen: time.Now()},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(errOut)
+	statusCmd.SetContext(context.Background())
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("registry", "")
+	_ = statusCmd.Flags().Set("format", "json")
+	_ = statusCmd.Flags().Set("only", "clean")
+	_ = statusCmd.Flags().Set("field-selector", "")
+	_ = statusCmd.Flags().Set("selector", "")
+	_ = statusCmd.Flags().Set("local-selector", "")
+	_ = statusCmd.Flags().Set("reconcile-remote-mismatch", "none")
+	_ = statusCmd.Flags().Set("dry-run", "true")
+	_ = statusCmd.Flags().Set("verify-ignored", "true")
+	defer func() {
+		_ = statusCmd.Flags().Set("verify-ignored", "false")
+		_ = statusCmd.Flags().Set("only", "all")
+		_ = statusCmd.Flags().Set("format", "table")
+	}()
+
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --verify-ignored failed: %v", err)
+	}
+	var report struct {
+		Repos []model.RepoStatus `json:"repos"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode status json: %v (%q)", err, out.String())
+	}
+	byPath := map[string]model.RepoStatus{}
+	for _, repo := range report.Repos {
+		byPath[repo.Path] = repo
+	}
+	flagged, ok := byPath[flaggedPath]
+	if !ok || flagged.Ignored == nil || flagged.Ignored.Count != 1 || flagged.Ignored.Paths[0] != "notes.log" {
+		t.Fatalf("expected repo-ignored to report notes.log, got %+v", flagged.Ignored)
+	}
+	plain, ok := byPath[cleanPath]
+	if !ok || plain.Ignored == nil || plain.Ignored.Count != 0 {
+		t.Fatalf("expected repo-plain to be clean with no ignored files, got %+v", plain.Ignored)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	_ = statusCmd.Flags().Set("format", "table")
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --verify-ignored table failed: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "1 repo(s) have ignored files") || !strings.Contains(errOut.String(), "notes.log") {
+		t.Fatalf("expected ignored-file warning for repo-ignored, got %q", errOut.String())
+	}
+	if strings.Contains(errOut.String(), "repo-plain") {
+		t.Fatalf("expected repo-plain not to be flagged, got %q", errOut.String())
+	}
+}
+
 func TestDescribeRunEIncludesRepoMetadata(t *testing.T) {
 	tmp := t.TempDir()
 	repoPath := filepath.Join(tmp, "repo-with-meta")
@@ -1489,3 +2105,89 @@ func TestScanRunEIncludesRepoMetadataWithoutWritingIt(t *testing.T) {
 		t.Fatal("expected scan to leave repo metadata file unchanged")
 	}
 }
+
+func TestSyncRecordsLastRunAndReplaysOnlyItsFailures(t *testing.T) {
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+	seedPath := filepath.Join(filepath.Dir(cfgPath), "seed")
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Registry.Entries = append(cfg.Registry.Entries, registry.Entry{
+		RepoID:    "github.com/org/repo-seed",
+		Path:      seedPath,
+		RemoteURL: cfg.Registry.Entries[0].RemoteURL,
+		Status:    registry.StatusPresent,
+	})
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	restoreYes := withAssumeYes(t, true)
+	defer restoreYes()
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	defer func() { state.exitCode = prevExitCode }()
+
+	out := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("dry-run", "false")
+	_ = syncCmd.Flags().Set("format", "json")
+	defer func() {
+		_ = syncCmd.Flags().Set("only", "all")
+		_ = syncCmd.Flags().Set("checkout-missing", "false")
+		_ = syncCmd.Flags().Set("from-last-run", "false")
+		_ = syncCmd.Flags().Set("format", "table")
+	}()
+	runSync := func() []syncResultJSON {
+		t.Helper()
+		out.Reset()
+		if err := syncCmd.RunE(syncCmd, nil); err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		if out.Len() == 0 {
+			return nil
+		}
+		var results []syncResultJSON
+		if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+			t.Fatalf("decode %q: %v", out.String(), err)
+		}
+		return results
+	}
+
+	if results := runSync(); len(results) != 2 {
+		t.Fatalf("expected both repos in the first run, got %+v", results)
+	}
+	failures, err := loadLastSyncFailures(config.LastSyncPath(cfgPath))
+	if err != nil {
+		t.Fatalf("load last run: %v", err)
+	}
+	if len(failures) != 1 || failures[0] != missingPath {
+		t.Fatalf("expected only the missing repo to be recorded as failed, got %v", failures)
+	}
+
+	_ = syncCmd.Flags().Set("only", "errors")
+	_ = syncCmd.Flags().Set("from-last-run", "true")
+	_ = syncCmd.Flags().Set("checkout-missing", "true")
+	results := runSync()
+	if len(results) != 1 || results[0].Path != missingPath || !results[0].OK {
+		t.Fatalf("expected the replay to clone only the failed repo, got %+v", results)
+	}
+
+	if results := runSync(); len(results) != 0 {
+		t.Fatalf("expected nothing to replay once the failure is fixed, got %+v", results)
+	}
+}
+
+func TestLoadLastSyncFailuresRequiresARecordedRun(t *testing.T) {
+	_, err := loadLastSyncFailures(filepath.Join(t.TempDir(), config.LastSyncFilename))
+	if err == nil || !strings.Contains(err.Error(), "no recorded sync run") {
+		t.Fatalf("expected missing record error, got %v", err)
+	}
+}
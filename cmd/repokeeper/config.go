@@ -0,0 +1,376 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the RepoKeeper configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the config file, or the fully resolved configuration",
+	Long: "Prints the config file that commands in this directory would use, exactly as stored.\n\n" +
+		"--effective prints the resolved configuration instead: the file merged over the built-in defaults, " +
+		"with the --profile profile applied, paths expanded, and defaulted values filled in. Every value is annotated with where it came from: " +
+		"file when the config file sets it, profile:<name> when the active profile replaces it, env when $VARS or ~ were expanded in it, " +
+		"flag when a command-line flag (--jobs for defaults.max_jobs) overrides it, and default when the built-in default applies " +
+		"(including empty values the loader backfills). " +
+		"The output also names the active profile, the config file, and how it was selected (--config flag, REPOKEEPER_CONFIG, a local .repokeeper.yaml, or the global path). " +
+		"Registry entries are summarized as a count rather than listed.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting config show")
+		effective, _ := cmd.Flags().GetBool("effective")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindYAML && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q (expected yaml or json)", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+		raw, err := os.ReadFile(cfgPath)
+		if err != nil {
+			return err
+		}
+		if !effective {
+			if mode.kind == outputKindJSON {
+				var doc any
+				if err := yaml.Unmarshal(raw, &doc); err != nil {
+					return err
+				}
+				return writeConfigJSON(cmd, doc)
+			}
+			_, err = cmd.OutOrStdout().Write(raw)
+			return err
+		}
+
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		jobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+		var flagKeys []string
+		if jobs > 0 {
+			cfg.Defaults.MaxJobs = jobs
+			flagKeys = append(flagKeys, "defaults.max_jobs")
+		}
+		report, err := buildEffectiveConfig(cfg, raw, cfgPath, configPathSource(configOverride(cmd)), flagKeys...)
+		if err != nil {
+			return err
+		}
+		if mode.kind == outputKindJSON {
+			return writeConfigJSON(cmd, report)
+		}
+		return writeEffectiveConfigYAML(cmd, report)
+	},
+}
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report unknown keys in the config file",
+	Long: "Re-reads the config file that commands in this directory would use and compares every key, including keys " +
+		"nested under defaults, roots, and profiles, against the config schema. Loading a config silently ignores keys it " +
+		"does not know, so a typo such as excludes: for exclude: has no effect; lint reports it with the closest valid key " +
+		"at the same level.\n\n" +
+		"A config without apiVersion and kind is reported as deprecated: it loads as the legacy schema and is rewritten on " +
+		"the next save. Exits 1 when any unknown key is found; a deprecation alone does not fail.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting config lint")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindTable && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+		raw, err := os.ReadFile(cfgPath)
+		if err != nil {
+			return err
+		}
+		findings, err := config.LintKeys(raw)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", cfgPath, err)
+		}
+
+		switch mode.kind {
+		case outputKindJSON:
+			if err := writeConfigJSON(cmd, configLintReport{ConfigPath: cfgPath, Findings: findings}); err != nil {
+				return err
+			}
+		default:
+			if len(findings) > 0 {
+				rows := make([][]string, 0, len(findings))
+				for _, finding := range findings {
+					rows = append(rows, []string{finding.Type, finding.Key, strconv.Itoa(finding.Line), finding.Message})
+				}
+				if err := cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"TYPE", "KEY", "LINE", "MESSAGE"}, rows); err != nil {
+					return err
+				}
+			}
+		}
+		unknown := 0
+		for _, finding := range findings {
+			if finding.Type == config.LintUnknownKey {
+				unknown++
+			}
+		}
+		if unknown > 0 {
+			raiseExitCode(cmd, 1)
+			infof(cmd, "config lint: %d unknown keys in %s", unknown, cfgPath)
+			return nil
+		}
+		infof(cmd, "config lint: no unknown keys in %s", cfgPath)
+		return nil
+	},
+}
+
+// configLintReport is the config lint -o json document.
+type configLintReport struct {
+	ConfigPath string               `json:"config_path"`
+	Findings   []config.LintFinding `json:"findings"`
+}
+
+// Provenance values reported by config show --effective.
+const (
+	configSourceFile    = "file"
+	configSourceFlag    = "flag"
+	configSourceEnv     = config.SourceEnv
+	configSourceDefault = config.SourceDefault
+)
+
+// effectiveConfigReport is the config show --effective document.
+type effectiveConfigReport struct {
+	ConfigPath      string            `json:"config_path"`
+	ConfigSource    string            `json:"config_source"`
+	ActiveProfile   string            `json:"active_profile,omitempty"`
+	RegistryPath    string            `json:"registry_path,omitempty"`
+	RegistryEntries int               `json:"registry_entries"`
+	Config          map[string]any    `json:"config"`
+	Provenance      map[string]string `json:"provenance"`
+}
+
+// configPathSource names how ResolveConfigPath picked the config file, using
+// the same precedence: --config, REPOKEEPER_CONFIG, a local dotfile, then the
+// global path.
+func configPathSource(override string) string {
+	switch {
+	case override != "":
+		return "flag"
+	case os.Getenv("REPOKEEPER_CONFIG") != "":
+		return "env"
+	default:
+		return "discovered"
+	}
+}
+
+// buildEffectiveConfig renders cfg as loaded and attributes every leaf value
+// to where it came from. flagKeys are the keys a command-line flag set after
+// Load; other values take the source Load recorded for them (a profile, env
+// expansion, or a backfilled default), and the rest are attributed to the
+// config file or the built-in defaults by comparing them with raw, the file
+// as stored.
+func buildEffectiveConfig(cfg *config.Config, raw []byte, cfgPath, source string, flagKeys ...string) (effectiveConfigReport, error) {
+	report := effectiveConfigReport{ConfigPath: cfgPath, ConfigSource: source, ActiveProfile: cfg.ActiveProfile()}
+	if source == "discovered" {
+		report.ConfigSource = "global"
+		if filepath.Base(cfgPath) == config.LocalConfigFilename {
+			report.ConfigSource = "local"
+		}
+	}
+	if cfg.Registry != nil {
+		report.RegistryEntries = len(cfg.Registry.Entries)
+	}
+	report.RegistryPath = config.ResolveRegistryPath(cfgPath, cfg.RegistryPath)
+
+	resolved := *cfg
+	resolved.Registry = nil
+	effective, err := yamlGenericMap(&resolved)
+	if err != nil {
+		return report, err
+	}
+	var stored map[string]any
+	if err := yaml.Unmarshal(raw, &stored); err != nil {
+		return report, err
+	}
+	delete(stored, "registry")
+
+	report.Config = effective
+	report.Provenance = make(map[string]string)
+	fileValues := flattenConfigValues(stored, "")
+	for key, value := range flattenConfigValues(effective, "") {
+		switch {
+		case slices.Contains(flagKeys, key):
+			report.Provenance[key] = configSourceFlag
+		case cfg.ValueSource(key) != "":
+			report.Provenance[key] = cfg.ValueSource(key)
+		default:
+			report.Provenance[key] = configSourceDefault
+			if fileValue, ok := fileValues[key]; ok && reflect.DeepEqual(fileValue, value) {
+				report.Provenance[key] = configSourceFile
+			}
+		}
+	}
+	return report, nil
+}
+
+// yamlGenericMap round-trips v through YAML so keys follow the config's yaml
+// tags, which is also what the config file uses.
+func yamlGenericMap(v any) (map[string]any, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// flattenConfigValues maps dotted key paths to leaf values. Lists are leaves:
+// a list comes from one place as a whole.
+func flattenConfigValues(doc map[string]any, prefix string) map[string]any {
+	out := make(map[string]any)
+	for key, value := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]any); ok {
+			for k, v := range flattenConfigValues(nested, path) {
+				out[k] = v
+			}
+			continue
+		}
+		out[path] = value
+	}
+	return out
+}
+
+func writeConfigJSON(cmd *cobra.Command, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return err
+}
+
+// writeEffectiveConfigYAML prints the report as YAML with each config value's
+// provenance as a trailing comment instead of a separate provenance map.
+func writeEffectiveConfigYAML(cmd *cobra.Command, report effectiveConfigReport) error {
+	var configNode yaml.Node
+	if err := configNode.Encode(report.Config); err != nil {
+		return err
+	}
+	annotateConfigProvenance(&configNode, "", report.Provenance)
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	addPair := func(key string, value *yaml.Node) {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+	scalar := func(value any) *yaml.Node {
+		var node yaml.Node
+		_ = node.Encode(value)
+		return &node
+	}
+	addPair("config_path", scalar(report.ConfigPath))
+	addPair("config_source", scalar(report.ConfigSource))
+	if report.ActiveProfile != "" {
+		addPair("active_profile", scalar(report.ActiveProfile))
+	}
+	if report.RegistryPath != "" {
+		addPair("registry_path", scalar(report.RegistryPath))
+	}
+	addPair("registry_entries", scalar(report.RegistryEntries))
+	addPair("config", &configNode)
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+func annotateConfigProvenance(node *yaml.Node, prefix string, provenance map[string]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if value.Kind == yaml.MappingNode {
+			annotateConfigProvenance(value, path, provenance)
+			continue
+		}
+		source, ok := provenance[path]
+		if !ok {
+			continue
+		}
+		// Block sequences print the comment after the key; scalars and empty
+		// flow sequences after the value.
+		if value.Kind == yaml.SequenceNode && len(value.Content) > 0 {
+			key.LineComment = source
+		} else {
+			value.LineComment = source
+		}
+	}
+}
+
+func init() {
+	configShowCmd.Flags().Bool("effective", false, "print the resolved configuration with defaults applied and per-value provenance")
+	configShowCmd.Flags().StringP("format", "o", "yaml", "output format: yaml or json")
+
+	addFormatFlag(configLintCmd, "output format: table or json")
+	addNoHeadersFlag(configLintCmd)
+
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configLintCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigShowEffectiveFillsDefaultsAndAttributesFileValues(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	raw := "apiVersion: skaphos.io/repokeeper/v1beta1\nkind: RepoKeeperConfig\ndefaults:\n  concurrency: 3\n  timeout_seconds: 0\nlabel_overlay:\n  enabled: true\n"
+	if err := os.WriteFile(cfgPath, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	configShowCmd.SetOut(out)
+	configShowCmd.SetContext(context.Background())
+	defer configShowCmd.SetOut(os.Stdout)
+	_ = configShowCmd.Flags().Set("effective", "true")
+	_ = configShowCmd.Flags().Set("format", "json")
+	defer func() {
+		_ = configShowCmd.Flags().Set("effective", "false")
+		_ = configShowCmd.Flags().Set("format", "yaml")
+	}()
+
+	if err := configShowCmd.RunE(configShowCmd, nil); err != nil {
+		t.Fatalf("config show --effective: %v", err)
+	}
+	var report effectiveConfigReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if report.ConfigPath != cfgPath || report.ConfigSource != "flag" {
+		t.Fatalf("expected the --config path and source, got %q (%s)", report.ConfigPath, report.ConfigSource)
+	}
+	defaults, _ := report.Config["defaults"].(map[string]any)
+	if defaults["concurrency"] != float64(3) || defaults["timeout_seconds"] != float64(60) || defaults["remote_name"] != "origin" {
+		t.Fatalf("expected file and defaulted values merged, got %+v", defaults)
+	}
+	for key, want := range map[string]string{
+		"defaults.concurrency":     configSourceFile,
+		"label_overlay.enabled":    configSourceFile,
+		"defaults.timeout_seconds": configSourceDefault,
+		"defaults.remote_name":     configSourceDefault,
+		"registry_stale_days":      configSourceDefault,
+	} {
+		if got := report.Provenance[key]; got != want {
+			t.Fatalf("expected %s provenance %q, got %q", key, want, got)
+		}
+	}
+
+	out.Reset()
+	_ = configShowCmd.Flags().Set("format", "yaml")
+	if err := configShowCmd.RunE(configShowCmd, nil); err != nil {
+		t.Fatalf("config show --effective -o yaml: %v", err)
+	}
+	for _, want := range []string{"concurrency: 3 # file", "timeout_seconds: 60 # default", "config_source: flag"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in YAML output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	_ = configShowCmd.Flags().Set("effective", "false")
+	if err := configShowCmd.RunE(configShowCmd, nil); err != nil {
+		t.Fatalf("config show: %v", err)
+	}
+	if out.String() != raw {
+		t.Fatalf("expected the stored file verbatim, got:\n%s", out.String())
+	}
+}
+
+func TestConfigShowEffectiveAttributesProfileEnvAndFlagValues(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	raw := "apiVersion: skaphos.io/repokeeper/v1beta1\nkind: RepoKeeperConfig\nexclude:\n  - vendor\n" +
+		"registry_path: $RK_TEST_REGISTRY_DIR/registry.yaml\nprofiles:\n  work:\n    exclude:\n      - node_modules\n"
+	if err := os.WriteFile(cfgPath, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("RK_TEST_REGISTRY_DIR", dir)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	_ = rootCmd.PersistentFlags().Set("profile", "work")
+	_ = rootCmd.PersistentFlags().Set("jobs", "2")
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("profile", "")
+		_ = rootCmd.PersistentFlags().Set("jobs", "0")
+	}()
+
+	out := &bytes.Buffer{}
+	configShowCmd.SetOut(out)
+	configShowCmd.SetContext(context.Background())
+	defer configShowCmd.SetOut(os.Stdout)
+	_ = configShowCmd.Flags().Set("effective", "true")
+	_ = configShowCmd.Flags().Set("format", "json")
+	defer func() {
+		_ = configShowCmd.Flags().Set("effective", "false")
+		_ = configShowCmd.Flags().Set("format", "yaml")
+	}()
+
+	if err := configShowCmd.RunE(configShowCmd, nil); err != nil {
+		t.Fatalf("config show --effective: %v", err)
+	}
+	var report effectiveConfigReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if report.ActiveProfile != "work" {
+		t.Fatalf("expected active profile work, got %q", report.ActiveProfile)
+	}
+	for key, want := range map[string]string{
+		"exclude":           "profile:work",
+		"registry_path":     configSourceEnv,
+		"defaults.max_jobs": configSourceFlag,
+	} {
+		if got := report.Provenance[key]; got != want {
+			t.Fatalf("expected %s provenance %q, got %q", key, want, got)
+		}
+	}
+	if defaults, _ := report.Config["defaults"].(map[string]any); defaults["max_jobs"] != float64(2) {
+		t.Fatalf("expected --jobs in defaults.max_jobs, got %+v", defaults)
+	}
+
+	out.Reset()
+	_ = configShowCmd.Flags().Set("format", "yaml")
+	if err := configShowCmd.RunE(configShowCmd, nil); err != nil {
+		t.Fatalf("config show --effective -o yaml: %v", err)
+	}
+	for _, want := range []string{"active_profile: work", "exclude: # profile:work", "max_jobs: 2 # flag"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in YAML output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConfigLintFlagsUnknownKeysAndExitsNonZero(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), ".repokeeper.yaml")
+	raw := "apiVersion: skaphos.io/repokeeper/v1beta1\nkind: RepoKeeperConfig\nexcludes:\n  - node_modules\n"
+	if err := os.WriteFile(cfgPath, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	configLintCmd.SetOut(out)
+	configLintCmd.SetErr(&bytes.Buffer{})
+	configLintCmd.SetContext(context.Background())
+	defer configLintCmd.SetOut(os.Stdout)
+	defer configLintCmd.SetErr(os.Stderr)
+	state := runtimeStateFor(configLintCmd)
+	prevExitCode := state.exitCode
+	defer func() { state.exitCode = prevExitCode }()
+
+	if err := configLintCmd.RunE(configLintCmd, nil); err != nil {
+		t.Fatalf("config lint: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "unknown_key") || !strings.Contains(got, `did you mean "exclude"?`) {
+		t.Fatalf("expected the excludes typo with a suggestion, got %q", got)
+	}
+	if state.exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", state.exitCode)
+	}
+}
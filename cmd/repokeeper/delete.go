@@ -25,7 +25,7 @@ var deleteCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -109,9 +109,7 @@ var deleteCmd = &cobra.Command{
 			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "stopped tracking %s (%s)\n", entry.RepoID, entry.Path); err != nil {
 				return err
 			}
-			if _, err := fmt.Fprintln(cmd.ErrOrStderr(), "warning: future scan/import runs will ignore this path and not add it back to the registry"); err != nil {
-				return err
-			}
+			warnf(cmd, "future scan/import runs will ignore this path and not add it back to the registry")
 			return nil
 		}
 		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "deleted %s (%s)\n", entry.RepoID, entry.Path); err != nil {
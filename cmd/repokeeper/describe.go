@@ -11,6 +11,7 @@ import (
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/gitx"
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
 	"github.com/skaphos/repokeeper/internal/repometa"
@@ -42,7 +43,7 @@ func runDescribeRepo(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	cfg, err := config.Load(cfgPath)
+	cfg, err := loadConfig(cmd, cfgPath)
 	if err != nil {
 		return err
 	}
@@ -67,6 +68,14 @@ func runDescribeRepo(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	fetch, _ := cmd.Flags().GetBool("fetch")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if dryRun && !fetch {
+		return fmt.Errorf("--dry-run requires --fetch")
+	}
+	if fetch && entry.Status == registry.StatusMissing {
+		return fmt.Errorf("cannot fetch %s: path missing", entry.Path)
+	}
 
 	repo := model.RepoStatus{
 		RepoID:      entry.RepoID,
@@ -78,19 +87,34 @@ func runDescribeRepo(cmd *cobra.Command, args []string) error {
 		Tracking:    model.Tracking{Status: model.TrackingNone},
 	}
 	registry.SeedRepoMetadataStatus(entry, &repo)
+	remoteRepoID := ""
 	if entry.Status == registry.StatusMissing {
 		repo.Error = "path missing"
 		repo.ErrorClass = "missing"
 	} else {
 		classifier := vcs.NewGitErrorClassifier()
 		eng := engine.New(cfg, reg, vcs.NewGitAdapter(nil), classifier, vcs.NewGitURLNormalizer(), nil)
-		status, err := eng.InspectRepo(cmd.Context(), entry.Path)
+		var status *model.RepoStatus
+		switch {
+		case fetch && dryRun:
+			infof(cmd, "dry-run: would fetch %s with %s", entry.Path, describeFetchAction(entry))
+			status, err = eng.InspectRepo(cmd.Context(), entry.Path)
+		case fetch:
+			debugf(cmd, "fetching %s", entry.Path)
+			status, err = eng.FetchRepo(cmd.Context(), entry, 0)
+			if err != nil {
+				raiseExitCode(cmd, 1)
+			}
+		default:
+			status, err = eng.InspectRepo(cmd.Context(), entry.Path)
+		}
 		if err != nil {
 			repo.Error = err.Error()
 			repo.ErrorClass = classifier.ClassifyError(err)
 			repometa.Apply(&repo)
 		} else {
 			repo = *status
+			remoteRepoID = status.RepoID
 			if repo.RepoID == "" {
 				repo.RepoID = entry.RepoID
 			}
@@ -104,25 +128,70 @@ func runDescribeRepo(cmd *cobra.Command, args []string) error {
 			repo.Annotations = cloneMetadataMap(entry.Annotations)
 		}
 	}
+	repo.Archived = entry.Archived
 
 	if err := persistDescribeMetadataSnapshot(cfg, cfgPath, registryOverride, reg, entry, repo); err != nil {
 		return err
 	}
 
+	verifyIdentity, _ := cmd.Flags().GetBool("verify-identity")
+	var identity *repoIdentityReport
+	if verifyIdentity {
+		metadataRepoID := ""
+		if repo.RepoMetadata != nil {
+			metadataRepoID = repo.RepoMetadata.RepoID
+		}
+		report := verifyRepoIdentity(remoteRepoID, entry.RepoID, metadataRepoID)
+		identity = &report
+		if report.Status != repoIdentityAgree {
+			raiseExitCode(cmd, 1)
+		}
+	}
+
+	showNormalizedID, _ := cmd.Flags().GetBool("normalized-id")
+	var normalized *repoNormalizedIDReport
+	if showNormalizedID {
+		report := normalizeRepoIdentity(repo, entry)
+		normalized = &report
+	}
+
+	history, _ := cmd.Flags().GetInt("history")
+	if history < 0 {
+		return fmt.Errorf("--history must not be negative, got %d", history)
+	}
+	var commits []model.Commit
+	// Missing, bare, and uninspectable repos have no history to show; the
+	// section is left out rather than failing describe.
+	if history > 0 && entry.Status != registry.StatusMissing && repo.Error == "" && !repo.Bare {
+		commits, err = vcs.NewGitAdapter(nil).RecentCommits(cmd.Context(), entry.Path, history)
+		if err != nil {
+			debugf(cmd, "skipping history for %s: %v", entry.Path, err)
+			commits = nil
+		}
+	}
+
 	format, _ := cmd.Flags().GetString("format")
 	mode, err := parseOutputMode(format)
 	if err != nil {
 		return err
 	}
+	var payload any = repo
+	if identity != nil || normalized != nil || len(commits) > 0 {
+		payload = describeIdentityJSON{RepoStatus: repo, Identity: identity, NormalizedID: normalized, RecentCommits: commits}
+	}
 	switch mode.kind {
 	case outputKindJSON:
-		data, err := json.MarshalIndent(repo, "", "  ")
+		data, err := json.MarshalIndent(payload, "", "  ")
 		if err != nil {
 			return err
 		}
 		if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
 			return err
 		}
+	case outputKindYAML:
+		if err := writeYAMLOutput(cmd, payload); err != nil {
+			return err
+		}
 	case outputKindCustomColumns:
 		if err := writeCustomColumnsOutput(cmd, repo, mode.expr, false); err != nil {
 			return err
@@ -131,12 +200,35 @@ func runDescribeRepo(cmd *cobra.Command, args []string) error {
 		if err := writeStatusDetails(cmd, repo, cwd, []string{cfgRoot}); err != nil {
 			return err
 		}
+		if identity != nil {
+			if err := writeRepoIdentityDetails(cmd, *identity); err != nil {
+				return err
+			}
+		}
+		if normalized != nil {
+			if err := writeRepoNormalizedIDDetails(cmd, *normalized); err != nil {
+				return err
+			}
+		}
+		if len(commits) > 0 {
+			if err := writeRecentCommits(cmd, commits); err != nil {
+				return err
+			}
+		}
 	default:
 		return fmt.Errorf("unsupported format %q", format)
 	}
 	return nil
 }
 
+// describeFetchAction names the git command describe --fetch runs for entry.
+func describeFetchAction(entry registry.Entry) string {
+	if entry.Type == "mirror" {
+		return "git remote update --prune"
+	}
+	return "git fetch --all --prune --prune-tags"
+}
+
 func persistDescribeMetadataSnapshot(
 	cfg *config.Config,
 	cfgPath string,
@@ -165,12 +257,201 @@ func persistDescribeMetadataSnapshot(
 	return config.Save(cfg, cfgPath)
 }
 
+const (
+	repoIdentityAgree        = "agree"
+	repoIdentityReconcilable = "reconcilable"
+	repoIdentityMismatch     = "mismatch"
+
+	repoIdentityMatchReference = "reference"
+	repoIdentityMatchExact     = "exact"
+	repoIdentityMatchCasing    = "casing"
+	repoIdentityMatchDiffers   = "differs"
+	repoIdentityMatchAbsent    = "absent"
+)
+
+// repoIdentitySource is one place a repo_id is recorded, compared against the
+// first available source (remote, then registry, then repo metadata).
+type repoIdentitySource struct {
+	Source string `json:"source"`
+	RepoID string `json:"repo_id,omitempty"`
+	Match  string `json:"match"`
+}
+
+// repoIdentityReport summarizes whether every recorded repo_id names the same
+// repository. Canonical is the case-folded form that casing-only drift
+// reconciles to.
+type repoIdentityReport struct {
+	Status    string               `json:"status"`
+	Canonical string               `json:"canonical"`
+	Sources   []repoIdentitySource `json:"sources"`
+}
+
+// repoNormalizedIDReport shows what gitx.NormalizeURL makes of a repo's remote
+// next to the repo_id stored in the registry. RemoteSource is "remote" when the
+// URL came from the live primary remote and "registry" when the checkout could
+// not be inspected and the recorded remote_url was used instead.
+type repoNormalizedIDReport struct {
+	RemoteURL      string `json:"remote_url"`
+	RemoteSource   string `json:"remote_source"`
+	NormalizedID   string `json:"normalized_id"`
+	RegistryRepoID string `json:"registry_repo_id"`
+	Match          string `json:"match"`
+}
+
+type describeIdentityJSON struct {
+	model.RepoStatus
+	Identity      *repoIdentityReport     `json:"identity,omitempty"`
+	NormalizedID  *repoNormalizedIDReport `json:"normalized_id,omitempty"`
+	RecentCommits []model.Commit          `json:"recent_commits,omitempty"`
+}
+
+func verifyRepoIdentity(remoteRepoID, registryRepoID, metadataRepoID string) repoIdentityReport {
+	sources := []repoIdentitySource{
+		{Source: "remote", RepoID: strings.TrimSpace(remoteRepoID)},
+		{Source: "registry", RepoID: strings.TrimSpace(registryRepoID)},
+		{Source: "repo_metadata", RepoID: strings.TrimSpace(metadataRepoID)},
+	}
+	report := repoIdentityReport{Status: repoIdentityAgree}
+	reference := ""
+	for i := range sources {
+		id := sources[i].RepoID
+		switch {
+		case id == "":
+			sources[i].Match = repoIdentityMatchAbsent
+		case reference == "":
+			reference = id
+			sources[i].Match = repoIdentityMatchReference
+		case id == reference:
+			sources[i].Match = repoIdentityMatchExact
+		case strings.EqualFold(id, reference):
+			sources[i].Match = repoIdentityMatchCasing
+			if report.Status == repoIdentityAgree {
+				report.Status = repoIdentityReconcilable
+			}
+		default:
+			sources[i].Match = repoIdentityMatchDiffers
+			report.Status = repoIdentityMismatch
+		}
+	}
+	report.Canonical = strings.ToLower(reference)
+	report.Sources = sources
+	return report
+}
+
+func writeRepoIdentityDetails(cmd *cobra.Command, report repoIdentityReport) error {
+	w := cmd.OutOrStdout()
+	if _, err := fmt.Fprintf(w, "IDENTITY: %s\n", report.Status); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "IDENTITY_CANONICAL: %s\n", report.Canonical); err != nil {
+		return err
+	}
+	for _, source := range report.Sources {
+		id := source.RepoID
+		if id == "" {
+			id = "-"
+		}
+		if _, err := fmt.Fprintf(w, "IDENTITY_%s: %s (%s)\n", strings.ToUpper(source.Source), sanitizeForDisplay(id), source.Match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeRepoIdentity(repo model.RepoStatus, entry registry.Entry) repoNormalizedIDReport {
+	report := repoNormalizedIDReport{RegistryRepoID: strings.TrimSpace(entry.RepoID)}
+	for _, remote := range repo.Remotes {
+		if remote.Name == repo.PrimaryRemote {
+			report.RemoteURL = strings.TrimSpace(remote.URL)
+			report.RemoteSource = "remote"
+			break
+		}
+	}
+	if report.RemoteURL == "" {
+		if recorded := strings.TrimSpace(entry.RemoteURL); recorded != "" {
+			report.RemoteURL = recorded
+			report.RemoteSource = "registry"
+		}
+	}
+	if report.RemoteURL != "" {
+		report.NormalizedID = gitx.NormalizeURL(report.RemoteURL)
+	}
+	switch {
+	case report.NormalizedID == "" || report.RegistryRepoID == "":
+		report.Match = repoIdentityMatchAbsent
+	case report.NormalizedID == report.RegistryRepoID:
+		report.Match = repoIdentityMatchExact
+	case strings.EqualFold(report.NormalizedID, report.RegistryRepoID):
+		report.Match = repoIdentityMatchCasing
+	default:
+		report.Match = repoIdentityMatchDiffers
+	}
+	return report
+}
+
+func writeRepoNormalizedIDDetails(cmd *cobra.Command, report repoNormalizedIDReport) error {
+	w := cmd.OutOrStdout()
+	source := ""
+	if report.RemoteSource != "" {
+		source = " (" + report.RemoteSource + ")"
+	}
+	marker := ""
+	if report.Match == repoIdentityMatchDiffers || report.Match == repoIdentityMatchCasing {
+		marker = " <- " + report.Match
+	}
+	lines := []string{
+		fmt.Sprintf("REMOTE_URL: %s%s", sanitizeForDisplay(dashIfEmpty(report.RemoteURL)), source),
+		fmt.Sprintf("NORMALIZED_ID: %s%s", sanitizeForDisplay(dashIfEmpty(report.NormalizedID)), marker),
+		fmt.Sprintf("REGISTRY_REPO_ID: %s%s", sanitizeForDisplay(dashIfEmpty(report.RegistryRepoID)), marker),
+		fmt.Sprintf("NORMALIZED_MATCH: %s", report.Match),
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRecentCommits prints --history as one line per commit: short hash,
+// author date, author, and subject.
+func writeRecentCommits(cmd *cobra.Command, commits []model.Commit) error {
+	w := cmd.OutOrStdout()
+	if _, err := fmt.Fprintln(w, "RECENT_COMMITS:"); err != nil {
+		return err
+	}
+	for _, commit := range commits {
+		hash := commit.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		if _, err := fmt.Fprintf(w, "- %s %s %s: %s\n",
+			hash,
+			commit.Date.Format("2006-01-02"),
+			sanitizeForDisplay(commit.Author),
+			sanitizeForDisplay(commit.Subject)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	describeCmd.Flags().String("registry", "", "override registry file path")
-	addFormatFlag(describeCmd, "output format: table or json")
+	addFormatFlag(describeCmd, "output format: table, json, or yaml")
+	describeCmd.Flags().Bool("verify-identity", false, verifyIdentityUsage)
+	describeCmd.Flags().Bool("normalized-id", false, normalizedIDUsage)
+	describeCmd.Flags().Int("history", 0, historyUsage)
+	describeCmd.Flags().Bool("fetch", false, describeFetchUsage)
+	describeCmd.Flags().Bool("dry-run", false, "with --fetch, print the fetch that would run and describe the repo without fetching")
 
 	describeRepoCmd.Flags().String("registry", "", "override registry file path")
-	addFormatFlag(describeRepoCmd, "output format: table or json")
+	addFormatFlag(describeRepoCmd, "output format: table, json, or yaml")
+	describeRepoCmd.Flags().Bool("verify-identity", false, verifyIdentityUsage)
+	describeRepoCmd.Flags().Bool("normalized-id", false, normalizedIDUsage)
+	describeRepoCmd.Flags().Int("history", 0, historyUsage)
+	describeRepoCmd.Flags().Bool("fetch", false, describeFetchUsage)
+	describeRepoCmd.Flags().Bool("dry-run", false, "with --fetch, print the fetch that would run and describe the repo without fetching")
 	describeCmd.AddCommand(describeRepoCmd)
 
 	rootCmd.AddCommand(describeCmd)
@@ -227,7 +227,7 @@ func TestRunDescribeRepoUnsupportedFormat(t *testing.T) {
 	cmd.Flags().String("registry", "", "")
 	cmd.Flags().String("format", "table", "")
 	_ = cmd.Flags().Set("registry", regPath)
-	_ = cmd.Flags().Set("format", "yaml")
+	_ = cmd.Flags().Set("format", "xml")
 
 	origWD, err := os.Getwd()
 	if err != nil {
@@ -647,3 +647,341 @@ func TestRunDescribeRepoJSONShape(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyRepoIdentityAllAgree(t *testing.T) {
+	report := verifyRepoIdentity("github.com/org/repo", "github.com/org/repo", "github.com/org/repo")
+	if report.Status != repoIdentityAgree {
+		t.Fatalf("expected agree, got %q", report.Status)
+	}
+	if report.Canonical != "github.com/org/repo" {
+		t.Fatalf("unexpected canonical form %q", report.Canonical)
+	}
+	wantMatches := []string{repoIdentityMatchReference, repoIdentityMatchExact, repoIdentityMatchExact}
+	for i, source := range report.Sources {
+		if source.Match != wantMatches[i] {
+			t.Fatalf("source %s: expected match %q, got %q", source.Source, wantMatches[i], source.Match)
+		}
+	}
+}
+
+func TestVerifyRepoIdentityCasingOnlyIsReconcilable(t *testing.T) {
+	report := verifyRepoIdentity("github.com/Org/Repo", "github.com/org/repo", "")
+	if report.Status != repoIdentityReconcilable {
+		t.Fatalf("expected reconcilable, got %q", report.Status)
+	}
+	if report.Canonical != "github.com/org/repo" {
+		t.Fatalf("unexpected canonical form %q", report.Canonical)
+	}
+	if report.Sources[1].Match != repoIdentityMatchCasing {
+		t.Fatalf("expected registry casing match, got %q", report.Sources[1].Match)
+	}
+	if report.Sources[2].Match != repoIdentityMatchAbsent {
+		t.Fatalf("expected absent repo metadata, got %q", report.Sources[2].Match)
+	}
+
+	mismatch := verifyRepoIdentity("github.com/org/repo", "github.com/org/repo", "github.com/org/other")
+	if mismatch.Status != repoIdentityMismatch {
+		t.Fatalf("expected mismatch, got %q", mismatch.Status)
+	}
+}
+
+func TestRunDescribeRepoVerifyIdentityFlagsCasingDrift(t *testing.T) {
+	tmp := t.TempDir()
+	repoPath := filepath.Join(tmp, "repo")
+	mustRunGit(t, tmp, "init", repoPath)
+	mustRunGit(t, repoPath, "remote", "add", "origin", "https://github.com/Org/Repo.git")
+	if err := os.WriteFile(filepath.Join(repoPath, ".repokeeper-repo.yaml"), []byte("repo_id: github.com/Org/Repo\n"), 0o644); err != nil {
+		t.Fatalf("write repo metadata: %v", err)
+	}
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/org/repo", Path: repoPath, Status: registry.StatusPresent, LastSeen: time.Now()},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	restoreConfig := withConfigFlag(t, cfgPath)
+	defer restoreConfig()
+
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(out)
+	cmd.Flags().String("registry", "", "")
+	cmd.Flags().String("format", "table", "")
+	cmd.Flags().Bool("verify-identity", false, "")
+	_ = cmd.Flags().Set("format", "json")
+	_ = cmd.Flags().Set("verify-identity", "true")
+
+	if err := runDescribeRepo(cmd, []string{"github.com/org/repo"}); err != nil {
+		t.Fatalf("runDescribeRepo: %v", err)
+	}
+
+	var got struct {
+		RepoID   string             `json:"repo_id"`
+		Identity repoIdentityReport `json:"identity"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("decode describe json: %v (%q)", err, out.String())
+	}
+	if got.Identity.Status != repoIdentityReconcilable {
+		t.Fatalf("expected reconcilable identity, got %#v", got.Identity)
+	}
+	if got.Identity.Canonical != "github.com/org/repo" {
+		t.Fatalf("unexpected canonical form %q", got.Identity.Canonical)
+	}
+	if got.Identity.Sources[2].RepoID != "github.com/Org/Repo" || got.Identity.Sources[2].Match != repoIdentityMatchExact {
+		t.Fatalf("expected repo metadata to agree with remote, got %#v", got.Identity.Sources[2])
+	}
+}
+
+func TestNormalizeRepoIdentityReportsMatchAndFallback(t *testing.T) {
+	repo := model.RepoStatus{
+		PrimaryRemote: "origin",
+		Remotes:       []model.Remote{{Name: "origin", URL: "git@github.com:org/repo.git"}},
+	}
+	report := normalizeRepoIdentity(repo, registry.Entry{RepoID: "github.com/org/repo"})
+	if report.Match != repoIdentityMatchExact || report.NormalizedID != "github.com/org/repo" || report.RemoteSource != "remote" {
+		t.Fatalf("expected exact match from live remote, got %#v", report)
+	}
+
+	fallback := normalizeRepoIdentity(model.RepoStatus{}, registry.Entry{RepoID: "github.com/org/repo", RemoteURL: "https://github.com/org/renamed.git"})
+	if fallback.RemoteSource != "registry" || fallback.Match != repoIdentityMatchDiffers {
+		t.Fatalf("expected registry remote_url fallback to differ, got %#v", fallback)
+	}
+	if absent := normalizeRepoIdentity(model.RepoStatus{}, registry.Entry{RepoID: "github.com/org/repo"}); absent.Match != repoIdentityMatchAbsent {
+		t.Fatalf("expected absent without a remote URL, got %#v", absent)
+	}
+}
+
+func TestRunDescribeRepoNormalizedIDHighlightsDifference(t *testing.T) {
+	tmp := t.TempDir()
+	drifted := filepath.Join(tmp, "drifted")
+	matching := filepath.Join(tmp, "matching")
+	mustRunGit(t, tmp, "init", drifted)
+	mustRunGit(t, drifted, "remote", "add", "origin", "https://GitHub.com/Org/Renamed.git")
+	mustRunGit(t, tmp, "init", matching)
+	mustRunGit(t, matching, "remote", "add", "origin", "git@github.com:org/matching.git")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/org/drifted", Path: drifted, Status: registry.StatusPresent, LastSeen: time.Now()},
+			{RepoID: "github.com/org/matching", Path: matching, Status: registry.StatusPresent, LastSeen: time.Now()},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	restoreConfig := withConfigFlag(t, cfgPath)
+	defer restoreConfig()
+
+	run := func(selector, format string) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		cmd.SetOut(out)
+		cmd.Flags().String("registry", "", "")
+		cmd.Flags().String("format", "table", "")
+		cmd.Flags().Bool("verify-identity", false, "")
+		cmd.Flags().Bool("normalized-id", false, "")
+		_ = cmd.Flags().Set("format", format)
+		_ = cmd.Flags().Set("normalized-id", "true")
+		if err := runDescribeRepo(cmd, []string{selector}); err != nil {
+			t.Fatalf("runDescribeRepo %s: %v", selector, err)
+		}
+		return out.String()
+	}
+
+	table := run("github.com/org/drifted", "table")
+	for _, want := range []string{
+		"REMOTE_URL: https://GitHub.com/Org/Renamed.git (remote)",
+		"NORMALIZED_ID: github.com/Org/Renamed <- differs",
+		"REGISTRY_REPO_ID: github.com/org/drifted <- differs",
+		"NORMALIZED_MATCH: differs",
+	} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in describe output:\n%s", want, table)
+		}
+	}
+
+	var got struct {
+		Identity     *repoIdentityReport    `json:"identity"`
+		NormalizedID repoNormalizedIDReport `json:"normalized_id"`
+	}
+	raw := run("github.com/org/matching", "json")
+	if err := json.Unmarshal([]byte(raw), &got); err != nil {
+		t.Fatalf("decode describe json: %v (%q)", err, raw)
+	}
+	if got.Identity != nil {
+		t.Fatalf("expected identity to be omitted without --verify-identity, got %#v", got.Identity)
+	}
+	if got.NormalizedID.Match != repoIdentityMatchExact || got.NormalizedID.NormalizedID != "github.com/org/matching" {
+		t.Fatalf("expected an exact normalized match, got %#v", got.NormalizedID)
+	}
+}
+
+func TestRunDescribeRepoHistory(t *testing.T) {
+	tmp := t.TempDir()
+	repoPath := filepath.Join(tmp, "repo")
+	mustRunGit(t, tmp, "init", repoPath)
+	mustRunGit(t, repoPath, "commit", "--allow-empty", "-m", "first")
+	mustRunGit(t, repoPath, "commit", "--allow-empty", "-m", "second")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: repoPath, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(tmp, "gone"), Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	restoreConfig := withConfigFlag(t, cfgPath)
+	defer restoreConfig()
+
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+
+	describe := func(selector, format string) string {
+		t.Helper()
+		out := &bytes.Buffer{}
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		cmd.SetOut(out)
+		cmd.Flags().String("registry", "", "")
+		cmd.Flags().String("format", "table", "")
+		cmd.Flags().Int("history", 0, "")
+		_ = cmd.Flags().Set("format", format)
+		_ = cmd.Flags().Set("history", "1")
+		if err := runDescribeRepo(cmd, []string{selector}); err != nil {
+			t.Fatalf("describe %s: %v", selector, err)
+		}
+		return out.String()
+	}
+
+	var payload struct {
+		RecentCommits []struct {
+			Hash    string `json:"hash"`
+			Author  string `json:"author"`
+			Subject string `json:"subject"`
+		} `json:"recent_commits"`
+	}
+	if err := json.Unmarshal([]byte(describe("github.com/org/repo", "json")), &payload); err != nil {
+		t.Fatalf("decode describe json: %v", err)
+	}
+	if len(payload.RecentCommits) != 1 || payload.RecentCommits[0].Subject != "second" ||
+		payload.RecentCommits[0].Author != "repokeeper-test" || len(payload.RecentCommits[0].Hash) != 40 {
+		t.Fatalf("expected the latest commit only, got %+v", payload.RecentCommits)
+	}
+	if table := describe("github.com/org/repo", "table"); !strings.Contains(table, "RECENT_COMMITS:") || !strings.Contains(table, "repokeeper-test: second") {
+		t.Fatalf("expected history section in table output, got:\n%s", table)
+	}
+	if missing := describe("github.com/org/gone", "json"); strings.Contains(missing, "recent_commits") {
+		t.Fatalf("expected no history for a missing repo, got:\n%s", missing)
+	}
+}
+
+func TestRunDescribeRepoFetch(t *testing.T) {
+	tmp := t.TempDir()
+	origin := filepath.Join(tmp, "origin.git")
+	repoPath := filepath.Join(tmp, "repo")
+	other := filepath.Join(tmp, "other")
+	mustRunGit(t, tmp, "init", "--bare", origin)
+	mustRunGit(t, tmp, "clone", origin, repoPath)
+	mustRunGit(t, repoPath, "commit", "--allow-empty", "-m", "first")
+	mustRunGit(t, repoPath, "push", "-u", "origin", "HEAD")
+	mustRunGit(t, tmp, "clone", origin, other)
+	mustRunGit(t, other, "commit", "--allow-empty", "-m", "second")
+	mustRunGit(t, other, "push", "origin", "HEAD")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: repoPath, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(tmp, "gone"), Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	restoreConfig := withConfigFlag(t, cfgPath)
+	defer restoreConfig()
+
+	origWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(tmp); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(origWD) }()
+
+	describe := func(selector string, fetch, dryRun bool) (model.RepoStatus, string, error) {
+		t.Helper()
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		cmd := &cobra.Command{}
+		cmd.SetContext(context.Background())
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		cmd.Flags().String("registry", "", "")
+		cmd.Flags().String("format", "table", "")
+		cmd.Flags().Bool("fetch", false, "")
+		cmd.Flags().Bool("dry-run", false, "")
+		_ = cmd.Flags().Set("format", "json")
+		if fetch {
+			_ = cmd.Flags().Set("fetch", "true")
+		}
+		if dryRun {
+			_ = cmd.Flags().Set("dry-run", "true")
+		}
+		var repo model.RepoStatus
+		err := runDescribeRepo(cmd, []string{selector})
+		if err == nil {
+			if decodeErr := json.Unmarshal(out.Bytes(), &repo); decodeErr != nil {
+				t.Fatalf("decode describe json: %v\n%s", decodeErr, out.String())
+			}
+		}
+		return repo, errOut.String(), err
+	}
+	behind := func(repo model.RepoStatus) int {
+		if repo.Tracking.Behind == nil {
+			return -1
+		}
+		return *repo.Tracking.Behind
+	}
+
+	repo, stderr, err := describe("github.com/org/repo", true, true)
+	if err != nil {
+		t.Fatalf("describe --fetch --dry-run: %v", err)
+	}
+	if behind(repo) != 0 || !strings.Contains(stderr, "would fetch "+repoPath) {
+		t.Fatalf("expected dry-run to print the fetch and leave the repo unfetched, got behind %d, stderr %q", behind(repo), stderr)
+	}
+
+	repo, _, err = describe("github.com/org/repo", true, false)
+	if err != nil {
+		t.Fatalf("describe --fetch: %v", err)
+	}
+	if behind(repo) != 1 || repo.Error != "" {
+		t.Fatalf("expected the refreshed status to be one commit behind, got %+v", repo.Tracking)
+	}
+
+	if _, _, err := describe("github.com/org/repo", false, true); err == nil || !strings.Contains(err.Error(), "--dry-run requires --fetch") {
+		t.Fatalf("expected --dry-run without --fetch to be rejected, got %v", err)
+	}
+	if _, _, err := describe("github.com/org/gone", true, false); err == nil || !strings.Contains(err.Error(), "path missing") {
+		t.Fatalf("expected fetching a missing repo to fail, got %v", err)
+	}
+}
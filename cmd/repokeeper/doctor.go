@@ -0,0 +1,401 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/pathutil"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the config and registry for inconsistencies",
+	Long: "Loads the config and registry and reports entries that need attention: paths that vanished without being marked missing, " +
+		"repo IDs that do not match their remote URL, duplicate repo IDs or paths, relative or unclean paths, and entries under an ignored path. " +
+		"It also runs git --version and reports an error when git cannot be run.\n\n" +
+		"--registry checks a standalone registry file instead of the config's registry; --fix then saves to that file.\n\n" +
+		"doctor is read-only unless --fix is set. --fix then offers each safe remediation in turn (or applies them all with --yes): " +
+		"vanished repos are marked missing, paths are made absolute and clean, and entries under an ignored path are removed. " +
+		"Repo ID mismatches, duplicates, and a missing git are only reported. RepoKeeper records no git version, so there is none to refresh; " +
+		"each doctor run checks the installed git afresh.\n\n" +
+		"doctor exits 1 when it finds warnings and 2 when it finds errors; with --fix, only problems left unfixed count.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting doctor")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		target, err := loadRegistryTarget(cmd, cwd)
+		if err != nil {
+			return err
+		}
+
+		base := config.ConfigRoot(target.cfgPath)
+		findings := append(diagnoseGit(cmd, &gitx.GitRunner{}), diagnoseRegistry(target.cfg, target.reg, base)...)
+
+		switch mode.kind {
+		case outputKindTable:
+			if len(findings) == 0 {
+				infof(cmd, "doctor: no problems found in %d registry entries", len(target.reg.Entries))
+			} else if err := writeDoctorTable(cmd, findings, noHeaders); err != nil {
+				return err
+			}
+		case outputKindJSON:
+			data, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		if !fix {
+			raiseExitCode(cmd, doctorExitCode(findings))
+			return nil
+		}
+		remaining, fixed, err := fixDoctorFindings(cmd, target.reg, findings, base)
+		if err != nil {
+			return err
+		}
+		raiseExitCode(cmd, doctorExitCode(remaining))
+		if fixed == 0 {
+			return nil
+		}
+		target.reg.UpdatedAt = time.Now()
+		if err := target.save(); err != nil {
+			return err
+		}
+		infof(cmd, "doctor: applied %d fixes", fixed)
+		return nil
+	},
+}
+
+const (
+	doctorSeverityWarning = "warning"
+	doctorSeverityError   = "error"
+)
+
+const (
+	doctorCheckMissingPath     = "missing_path"
+	doctorCheckRepoIDMismatch  = "repo_id_mismatch"
+	doctorCheckDuplicateRepoID = "duplicate_repo_id"
+	doctorCheckDuplicatePath   = "duplicate_path"
+	doctorCheckIgnoredPath     = "ignored_path"
+	doctorCheckNonCanonical    = "non_canonical_path"
+	doctorCheckGitUnavailable  = "git_unavailable"
+)
+
+// doctorFinding is one problem reported by doctor.
+type doctorFinding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	RepoID   string `json:"repo_id"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+	// entry is the index of the registry entry the finding is about, or -1
+	// for findings that span several entries; --fix uses it to find the
+	// entry again.
+	entry int
+}
+
+// canonicalEntryPath returns path as an absolute, clean path, resolving a
+// relative path against base (the config directory).
+func canonicalEntryPath(path, base string) string {
+	if !filepath.IsAbs(path) && base != "" {
+		path = filepath.Join(base, path)
+	}
+	return filepath.Clean(path)
+}
+
+// ignoredAncestor returns the key in ignored, a set built with
+// pathutil.CanonicalNormalize, that is path itself or the nearest directory
+// above it.
+func ignoredAncestor(path string, ignored map[string]struct{}) (string, bool) {
+	if len(ignored) == 0 {
+		return "", false
+	}
+	for key := pathutil.CanonicalNormalize(path); ; {
+		if _, ok := ignored[key]; ok {
+			return key, true
+		}
+		parent := filepath.Dir(key)
+		if parent == key {
+			return "", false
+		}
+		key = parent
+	}
+}
+
+// isLinkedWorktree reports whether path is a worktree added with `git worktree
+// add`, as gitx.WorktreeRole resolves it from the git dir. Paths that are not
+// repositories are not linked worktrees.
+func isLinkedWorktree(path string) bool {
+	role, _, err := gitx.WorktreeRole(path)
+	return err == nil && role == model.WorktreeRoleLinked
+}
+
+// diagnoseGit runs git --version through r and reports an error finding when
+// git cannot be run, since every other command depends on it.
+func diagnoseGit(cmd *cobra.Command, r gitx.Runner) []doctorFinding {
+	version, err := gitx.Version(cmd.Context(), r)
+	if err != nil {
+		return []doctorFinding{{
+			Severity: doctorSeverityError,
+			Check:    doctorCheckGitUnavailable,
+			Message:  err.Error(),
+			entry:    -1,
+		}}
+	}
+	debugf(cmd, "doctor: git version %s", version)
+	return nil
+}
+
+// diagnoseRegistry runs every doctor check against reg. Relative entry paths
+// are resolved against base. It only reads the filesystem; nothing in cfg or
+// reg is modified.
+func diagnoseRegistry(cfg *config.Config, reg *registry.Registry, base string) []doctorFinding {
+	findings := []doctorFinding{}
+	ignored := pathutil.IgnoredPathSet(cfg.IgnoredPaths, pathutil.CanonicalNormalize)
+	byRepoID := make(map[string][]registry.Entry)
+	byPath := make(map[string][]registry.Entry)
+
+	for idx, entry := range reg.Entries {
+		canonical := canonicalEntryPath(entry.Path, base)
+		if canonical != entry.Path {
+			findings = append(findings, doctorFinding{
+				Severity: doctorSeverityWarning,
+				Check:    doctorCheckNonCanonical,
+				RepoID:   entry.RepoID,
+				Path:     entry.Path,
+				Message:  fmt.Sprintf("path is not absolute and clean; canonical form is %s", canonical),
+				entry:    idx,
+			})
+		}
+		if entry.Status != registry.StatusMissing {
+			if _, err := os.Stat(canonical); os.IsNotExist(err) {
+				findings = append(findings, doctorFinding{
+					Severity: doctorSeverityWarning,
+					Check:    doctorCheckMissingPath,
+					RepoID:   entry.RepoID,
+					Path:     entry.Path,
+					Message:  fmt.Sprintf("path does not exist but status is %q", entry.Status),
+					entry:    idx,
+				})
+			}
+		}
+		if remoteURL := strings.TrimSpace(entry.RemoteURL); remoteURL != "" {
+			if normalized := gitx.NormalizeURL(remoteURL); normalized != entry.RepoID {
+				findings = append(findings, doctorFinding{
+					Severity: doctorSeverityWarning,
+					Check:    doctorCheckRepoIDMismatch,
+					RepoID:   entry.RepoID,
+					Path:     entry.Path,
+					Message:  fmt.Sprintf("remote_url %s normalizes to %q", remoteURL, normalized),
+					entry:    idx,
+				})
+			}
+		}
+		if ignoredPath, ok := ignoredAncestor(canonical, ignored); ok {
+			message := "path is listed in ignored_paths"
+			if ignoredPath != pathutil.CanonicalNormalize(canonical) {
+				message = fmt.Sprintf("path is under %s, which is listed in ignored_paths", ignoredPath)
+			}
+			findings = append(findings, doctorFinding{
+				Severity: doctorSeverityWarning,
+				Check:    doctorCheckIgnoredPath,
+				RepoID:   entry.RepoID,
+				Path:     entry.Path,
+				Message:  message,
+				entry:    idx,
+			})
+		}
+		byRepoID[entry.RepoID] = append(byRepoID[entry.RepoID], entry)
+		byPath[canonical] = append(byPath[canonical], entry)
+	}
+
+	// Several checkouts of one repo are legitimate, so a shared repo_id is only
+	// a warning. Two entries claiming the same directory cannot both be right.
+	for repoID, entries := range byRepoID {
+		if len(entries) < 2 {
+			continue
+		}
+		for _, entry := range entries {
+			message := fmt.Sprintf("repo_id is shared by %d entries", len(entries))
+			if isLinkedWorktree(canonicalEntryPath(entry.Path, base)) {
+				message += "; this entry is a linked worktree"
+			}
+			findings = append(findings, doctorFinding{
+				Severity: doctorSeverityWarning,
+				Check:    doctorCheckDuplicateRepoID,
+				RepoID:   repoID,
+				Path:     entry.Path,
+				Message:  message,
+				entry:    -1,
+			})
+		}
+	}
+	for path, entries := range byPath {
+		if len(entries) < 2 {
+			continue
+		}
+		for _, entry := range entries {
+			findings = append(findings, doctorFinding{
+				Severity: doctorSeverityError,
+				Check:    doctorCheckDuplicatePath,
+				RepoID:   entry.RepoID,
+				Path:     path,
+				Message:  fmt.Sprintf("path is recorded by %d entries", len(entries)),
+				entry:    -1,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity == doctorSeverityError
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		if a.RepoID != b.RepoID {
+			return a.RepoID < b.RepoID
+		}
+		return a.Path < b.Path
+	})
+	return findings
+}
+
+// doctorFixDescription returns what --fix does for finding, or "" when the
+// check is report-only. Only fixes that cannot lose work qualify: the status
+// and path rewrites touch registry metadata alone, and an ignored entry is
+// dropped from the registry without touching its checkout.
+func doctorFixDescription(finding doctorFinding, base string) string {
+	switch finding.Check {
+	case doctorCheckMissingPath:
+		return "mark missing"
+	case doctorCheckNonCanonical:
+		return "rewrite path to " + canonicalEntryPath(finding.Path, base)
+	case doctorCheckIgnoredPath:
+		return "remove registry entry"
+	default:
+		return ""
+	}
+}
+
+// fixDoctorFindings offers each safe fix for confirmation (all are accepted
+// with --yes) and applies the accepted ones to reg. It returns the findings
+// left unfixed and the number of fixes applied. Removals run last so entry
+// indices stay valid while the other fixes are applied.
+func fixDoctorFindings(cmd *cobra.Command, reg *registry.Registry, findings []doctorFinding, base string) ([]doctorFinding, int, error) {
+	yes := assumeYes(cmd)
+	remaining := make([]doctorFinding, 0, len(findings))
+	removed := make(map[int]bool)
+	fixed := 0
+	for _, finding := range findings {
+		description := doctorFixDescription(finding, base)
+		if description == "" || finding.entry < 0 || finding.entry >= len(reg.Entries) {
+			remaining = append(remaining, finding)
+			continue
+		}
+		if !yes {
+			confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Fix %s for %s (%s)? [y/N]: ", finding.Check, finding.RepoID, description))
+			if err != nil {
+				return nil, 0, err
+			}
+			if !confirmed {
+				infof(cmd, "doctor: skipped %s for %s", finding.Check, finding.RepoID)
+				remaining = append(remaining, finding)
+				continue
+			}
+		}
+		entry := &reg.Entries[finding.entry]
+		switch finding.Check {
+		case doctorCheckMissingPath:
+			entry.Status = registry.StatusMissing
+		case doctorCheckNonCanonical:
+			entry.Path = canonicalEntryPath(entry.Path, base)
+		case doctorCheckIgnoredPath:
+			removed[finding.entry] = true
+		}
+		fixed++
+		infof(cmd, "doctor: fixed %s for %s: %s", finding.Check, finding.RepoID, description)
+	}
+	if len(removed) > 0 {
+		kept := reg.Entries[:0]
+		for idx, entry := range reg.Entries {
+			if !removed[idx] {
+				kept = append(kept, entry)
+			}
+		}
+		reg.Entries = kept
+		// Other findings about a removed entry no longer apply.
+		remaining = slices.DeleteFunc(remaining, func(finding doctorFinding) bool {
+			return finding.entry >= 0 && removed[finding.entry]
+		})
+	}
+	return remaining, fixed, nil
+}
+
+// doctorExitCode maps findings to the shared exit code scale: 1 when any
+// warning was found, 2 when any error was found.
+func doctorExitCode(findings []doctorFinding) int {
+	code := 0
+	for _, finding := range findings {
+		switch finding.Severity {
+		case doctorSeverityError:
+			return 2
+		case doctorSeverityWarning:
+			code = 1
+		}
+	}
+	return code
+}
+
+func writeDoctorTable(cmd *cobra.Command, findings []doctorFinding, noHeaders bool) error {
+	rows := make([][]string, 0, len(findings))
+	for _, finding := range findings {
+		rows = append(rows, []string{
+			strings.ToUpper(finding.Severity),
+			finding.Check,
+			dashIfEmpty(finding.RepoID),
+			dashIfEmpty(finding.Path),
+			finding.Message,
+		})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"SEVERITY", "CHECK", "REPO", "PATH", "MESSAGE"}, rows)
+}
+
+func init() {
+	addFormatFlag(doctorCmd, "output format: table or json")
+	addNoHeadersFlag(doctorCmd)
+	doctorCmd.Flags().String("registry", "", "override registry file path")
+	doctorCmd.Flags().Bool("fix", false, "after reporting, offer to fix safe problems (mark vanished repos missing, canonicalize paths, drop ignored entries); --yes applies them all")
+
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+func TestDiagnoseRegistryReportsEachCheck(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present")
+	ignored := filepath.Join(dir, "ignored")
+	for _, path := range []string{present, ignored} {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	vanished := filepath.Join(dir, "vanished")
+	cfg := &config.Config{IgnoredPaths: []string{ignored}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/present", Path: present, RemoteURL: "git@github.com:org/present.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/vanished", Path: vanished, RemoteURL: "git@github.com:org/vanished.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(dir, "gone"), RemoteURL: "git@github.com:org/gone.git", Status: registry.StatusMissing},
+		{RepoID: "github.com/org/renamed", Path: ignored, RemoteURL: "git@github.com:org/other.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/present", Path: present, RemoteURL: "git@github.com:org/present.git", Status: registry.StatusPresent},
+	}}
+
+	findings := diagnoseRegistry(cfg, reg, dir)
+	got := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		got = append(got, finding.Severity+":"+finding.Check+":"+finding.RepoID)
+	}
+	want := []string{
+		"error:duplicate_path:github.com/org/present",
+		"error:duplicate_path:github.com/org/present",
+		"warning:duplicate_repo_id:github.com/org/present",
+		"warning:duplicate_repo_id:github.com/org/present",
+		"warning:ignored_path:github.com/org/renamed",
+		"warning:missing_path:github.com/org/vanished",
+		"warning:repo_id_mismatch:github.com/org/renamed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if doctorExitCode(findings) != 2 {
+		t.Fatalf("expected errors to map to exit code 2")
+	}
+	if doctorExitCode(findings[2:]) != 1 || doctorExitCode(nil) != 0 {
+		t.Fatalf("expected warnings to map to 1 and no findings to 0")
+	}
+}
+
+func TestDiagnoseRegistryMarksLinkedWorktrees(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main")
+	linked := filepath.Join(dir, "linked")
+	if err := os.MkdirAll(main, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	mustRunGit(t, main, "init")
+	mustRunGit(t, main, "commit", "--allow-empty", "-m", "init")
+	mustRunGit(t, main, "worktree", "add", "-b", "feature", linked)
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: main, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/repo", Path: linked, Status: registry.StatusPresent},
+	}}
+	findings := diagnoseRegistry(&config.Config{}, reg, dir)
+	if len(findings) != 2 {
+		t.Fatalf("expected two duplicate_repo_id findings, got %+v", findings)
+	}
+	for _, finding := range findings {
+		isLinked := strings.Contains(finding.Message, "linked worktree")
+		if finding.Check != doctorCheckDuplicateRepoID || isLinked != (finding.Path == linked) {
+			t.Fatalf("expected only the linked entry to be marked, got %+v", finding)
+		}
+	}
+}
+
+func TestDoctorCommandIsReadOnlyAndSetsExitCode(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/vanished", Path: filepath.Join(dir, "vanished"), RemoteURL: "git@github.com:org/vanished.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExitCode }()
+	doctorCmd.SetOut(out)
+	doctorCmd.SetContext(context.Background())
+	defer doctorCmd.SetOut(os.Stdout)
+	_ = doctorCmd.Flags().Set("format", "json")
+	defer func() { _ = doctorCmd.Flags().Set("format", "table") }()
+
+	if err := doctorCmd.RunE(doctorCmd, nil); err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	var findings []doctorFinding
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if len(findings) != 1 || findings[0].Check != doctorCheckMissingPath {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+	if state.exitCode != 1 {
+		t.Fatalf("expected warning exit code 1, got %d", state.exitCode)
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected doctor not to modify the config")
+	}
+
+	out.Reset()
+	_ = doctorCmd.Flags().Set("format", "table")
+	if err := doctorCmd.RunE(doctorCmd, nil); err != nil {
+		t.Fatalf("doctor table: %v", err)
+	}
+	if !strings.Contains(out.String(), "SEVERITY") || !strings.Contains(out.String(), "WARNING") {
+		t.Fatalf("unexpected table output:\n%s", out.String())
+	}
+}
+
+func TestDoctorFixRepairsSafeFindingsOnly(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"present", "renamed"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/vanished", Path: filepath.Join(dir, "vanished"), RemoteURL: "git@github.com:org/vanished.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/present", Path: "present", RemoteURL: "git@github.com:org/present.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/renamed", Path: filepath.Join(dir, "renamed"), RemoteURL: "git@github.com:org/other.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	defer withAssumeYes(t, true)()
+
+	out := &bytes.Buffer{}
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExitCode }()
+	doctorCmd.SetOut(out)
+	doctorCmd.SetErr(&bytes.Buffer{})
+	doctorCmd.SetContext(context.Background())
+	defer doctorCmd.SetOut(os.Stdout)
+	defer doctorCmd.SetErr(os.Stderr)
+	_ = doctorCmd.Flags().Set("fix", "true")
+	defer func() { _ = doctorCmd.Flags().Set("fix", "false") }()
+
+	if err := doctorCmd.RunE(doctorCmd, nil); err != nil {
+		t.Fatalf("doctor --fix: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	entries := saved.Registry.Entries
+	if len(entries) != 3 {
+		t.Fatalf("expected all entries kept, got %+v", entries)
+	}
+	if entries[0].Status != registry.StatusMissing {
+		t.Fatalf("expected vanished repo marked missing, got %q", entries[0].Status)
+	}
+	if want := filepath.Join(dir, "present"); entries[1].Path != want {
+		t.Fatalf("expected canonical path %q, got %q", want, entries[1].Path)
+	}
+	if entries[2].RepoID != "github.com/org/renamed" || entries[2].RemoteURL != "git@github.com:org/other.git" {
+		t.Fatalf("expected repo_id mismatch to be left alone, got %+v", entries[2])
+	}
+	// The repo_id mismatch is report-only, so it still drives the exit code.
+	if state.exitCode != 1 {
+		t.Fatalf("expected exit code 1 for the unfixed mismatch, got %d", state.exitCode)
+	}
+}
+
+// doctorGitRunner answers every git command with out and err.
+type doctorGitRunner struct {
+	out string
+	err error
+}
+
+func (r doctorGitRunner) Run(context.Context, string, ...string) (string, error) {
+	return r.out, r.err
+}
+
+func TestDiagnoseGitReportsUnrunnableGit(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	if findings := diagnoseGit(cmd, doctorGitRunner{out: "git version 2.43.0"}); len(findings) != 0 {
+		t.Fatalf("expected no findings for a working git, got %+v", findings)
+	}
+	findings := diagnoseGit(cmd, doctorGitRunner{err: errors.New(`exec: "git": executable file not found in $PATH`)})
+	if len(findings) != 1 || findings[0].Check != doctorCheckGitUnavailable || findings[0].Severity != doctorSeverityError {
+		t.Fatalf("expected a git_unavailable error, got %+v", findings)
+	}
+	if doctorFixDescription(findings[0], "") != "" {
+		t.Fatal("expected git_unavailable to be report-only")
+	}
+}
+
+func TestDoctorFixWithRegistryOverrideDropsEntriesUnderIgnoredPaths(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "vendor", "lib")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.IgnoredPaths = []string{filepath.Join(dir, "vendor") + string(filepath.Separator)}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	regPath := filepath.Join(dir, "registry.yaml")
+	if err := registry.Save(&registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/lib", Path: nested, RemoteURL: "git@github.com:org/lib.git", Status: registry.StatusPresent},
+	}}, regPath); err != nil {
+		t.Fatalf("save registry: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	defer withAssumeYes(t, true)()
+
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExitCode }()
+	doctorCmd.SetOut(&bytes.Buffer{})
+	doctorCmd.SetErr(&bytes.Buffer{})
+	doctorCmd.SetContext(context.Background())
+	defer doctorCmd.SetOut(os.Stdout)
+	defer doctorCmd.SetErr(os.Stderr)
+	_ = doctorCmd.Flags().Set("registry", regPath)
+	defer func() { _ = doctorCmd.Flags().Set("registry", "") }()
+	_ = doctorCmd.Flags().Set("fix", "true")
+	defer func() { _ = doctorCmd.Flags().Set("fix", "false") }()
+
+	if err := doctorCmd.RunE(doctorCmd, nil); err != nil {
+		t.Fatalf("doctor --registry --fix: %v", err)
+	}
+	saved, err := registry.Load(regPath)
+	if err != nil {
+		t.Fatalf("reload registry: %v", err)
+	}
+	if len(saved.Entries) != 0 {
+		t.Fatalf("expected the entry under the ignored path to be dropped, got %+v", saved.Entries)
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected --registry fixes to leave the config untouched")
+	}
+	if state.exitCode != 0 {
+		t.Fatalf("expected exit code 0 once fixed, got %d", state.exitCode)
+	}
+}
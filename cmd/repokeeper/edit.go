@@ -30,7 +30,7 @@ var editCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -6,8 +6,10 @@ import (
 	"context"
 	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -110,6 +112,54 @@ func TestPrepareRegistryForExportOmitsMetadataSnapshotCache(t *testing.T) {
 	}
 }
 
+func TestPolicyAnnotationsSurviveExportImportRoundTrip(t *testing.T) {
+	policy := map[string]string{"frozen": "true", "weight": "10", "timeout": "5m", "disabled": "false"}
+	source := &registry.Registry{Entries: []registry.Entry{{
+		RepoID:      "github.com/org/repo",
+		Path:        "/repos/team/repo",
+		RemoteURL:   "git@github.com:org/repo.git",
+		Status:      registry.StatusPresent,
+		Annotations: cloneMetadataMap(policy),
+	}}}
+
+	exported := prepareRegistryForExport(source, "/repos")
+	exported.Entries[0].Annotations["frozen"] = "mutated"
+	if source.Entries[0].Annotations["frozen"] != "true" {
+		t.Fatal("expected export to copy annotations rather than alias the live registry")
+	}
+	exported.Entries[0].Annotations["frozen"] = "true"
+
+	data, err := yaml.Marshal(exportBundle{Version: currentExportBundleVersion, Root: "/repos", Registry: exported})
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	var decoded exportBundle
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+	bundle, err := normalizeImportedBundle(decoded)
+	if err != nil {
+		t.Fatalf("normalize bundle: %v", err)
+	}
+
+	for _, mode := range []importMode{importModeMerge, importModeReplace} {
+		cfg := &config.Config{Registry: &registry.Registry{}}
+		mergeImportedRegistry(cfg, mode, true, bundle.Registry, importConflictPolicyBundle)
+		if len(cfg.Registry.Entries) != 1 {
+			t.Fatalf("%s: expected one imported entry, got %+v", mode, cfg.Registry.Entries)
+		}
+		got := cfg.Registry.Entries[0].Annotations
+		if len(got) != len(policy) {
+			t.Fatalf("%s: expected policy annotations %v, got %v", mode, policy, got)
+		}
+		for key, want := range policy {
+			if got[key] != want {
+				t.Fatalf("%s: expected annotation %s=%s, got %v", mode, key, want, got)
+			}
+		}
+	}
+}
+
 func TestInferRegistrySharedRootIgnoresNonPresentEntries(t *testing.T) {
 	presentRoot := filepath.Join(string(filepath.Separator), "workspace", "repos", "team", "repo-a")
 	missingPath := filepath.Join(string(filepath.Separator), "elsewhere", "legacy", "repo-gone")
@@ -441,6 +491,107 @@ func TestExportCommandRunEWarnsOnEmbeddedCredentials(t *testing.T) {
 	}
 }
 
+func runFilteredExport(t *testing.T, entries []registry.Entry, flags map[string]string) (*exportBundle, error) {
+	t.Helper()
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Registry = &registry.Registry{Entries: entries}
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	exportCmd.SetOut(out)
+	exportCmd.SetErr(&bytes.Buffer{})
+	exportCmd.SetContext(context.Background())
+	defer exportCmd.SetOut(os.Stdout)
+	defer exportCmd.SetErr(os.Stderr)
+	_ = exportCmd.Flags().Set("include-registry", "true")
+	_ = exportCmd.Flags().Set("output", "-")
+	for name, value := range flags {
+		_ = exportCmd.Flags().Set(name, value)
+	}
+	defer func() {
+		_ = exportCmd.Flags().Set("include-registry", "true")
+		_ = exportCmd.Flags().Set("only", "all")
+		_ = exportCmd.Flags().Set("selector", "")
+		_ = exportCmd.Flags().Set("local-selector", "")
+	}()
+
+	if err := exportCmd.RunE(exportCmd, nil); err != nil {
+		return nil, err
+	}
+	var bundle exportBundle
+	if err := yaml.Unmarshal(out.Bytes(), &bundle); err != nil {
+		t.Fatalf("unmarshal exported bundle: %v", err)
+	}
+	return &bundle, nil
+}
+
+func exportedRepoIDs(bundle *exportBundle) []string {
+	var ids []string
+	if bundle.Registry != nil {
+		for _, entry := range bundle.Registry.Entries {
+			ids = append(ids, entry.RepoID)
+		}
+	}
+	return ids
+}
+
+func TestExportCommandRunEFiltersBySelector(t *testing.T) {
+	root := t.TempDir()
+	entries := []registry.Entry{
+		{RepoID: "github.com/org/api", Path: filepath.Join(root, "api"), Status: registry.StatusPresent, RepoMetadata: &model.RepoMetadata{Labels: map[string]string{"tier": "prod"}}},
+		{RepoID: "github.com/org/sandbox", Path: filepath.Join(root, "sandbox"), Status: registry.StatusPresent, RepoMetadata: &model.RepoMetadata{Labels: map[string]string{"tier": "dev"}}},
+		{RepoID: "github.com/org/web", Path: filepath.Join(root, "web"), Status: registry.StatusPresent, Labels: map[string]string{"tier": "prod"}},
+	}
+
+	bundle, err := runFilteredExport(t, entries, map[string]string{"selector": "tier=prod"})
+	if err != nil {
+		t.Fatalf("export --selector failed: %v", err)
+	}
+	if got := exportedRepoIDs(bundle); !slices.Equal(got, []string{"github.com/org/api"}) {
+		t.Fatalf("expected only the tier=prod repo, got %v", got)
+	}
+
+	bundle, err = runFilteredExport(t, entries, map[string]string{"local-selector": "tier=prod"})
+	if err != nil {
+		t.Fatalf("export --local-selector failed: %v", err)
+	}
+	if got := exportedRepoIDs(bundle); !slices.Equal(got, []string{"github.com/org/web"}) {
+		t.Fatalf("expected only the locally labelled tier=prod repo, got %v", got)
+	}
+}
+
+func TestExportCommandRunEFiltersByStatus(t *testing.T) {
+	root := t.TempDir()
+	clean := filepath.Join(root, "clean")
+	if out, err := exec.Command("git", "init", clean).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v %s", err, string(out))
+	}
+	entries := []registry.Entry{
+		{RepoID: "github.com/org/clean", Path: clean, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/broken", Path: filepath.Join(root, "broken"), Status: registry.StatusPresent},
+	}
+
+	bundle, err := runFilteredExport(t, entries, map[string]string{"only": "errors"})
+	if err != nil {
+		t.Fatalf("export --only errors failed: %v", err)
+	}
+	if got := exportedRepoIDs(bundle); !slices.Equal(got, []string{"github.com/org/broken"}) {
+		t.Fatalf("expected only the repo that failed inspection, got %v", got)
+	}
+
+	if _, err := runFilteredExport(t, entries, map[string]string{"include-registry": "false", "selector": "tier=prod"}); err == nil || !strings.Contains(err.Error(), "require --include-registry") {
+		t.Fatalf("expected filters without a registry to be rejected, got %v", err)
+	}
+}
+
 func TestExportEntriesWithEmbeddedCredentials(t *testing.T) {
 	reg := &registry.Registry{
 		Entries: []registry.Entry{
@@ -458,3 +609,34 @@ func TestExportEntriesWithEmbeddedCredentials(t *testing.T) {
 		t.Fatalf("expected nil result for nil registry, got %#v", got)
 	}
 }
+
+func TestPopulateExportDefaultBranches(t *testing.T) {
+	reg := &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "r1", Path: "/repos/r1", Status: registry.StatusPresent},
+			{RepoID: "r2", Path: "/repos/r2", Status: registry.StatusPresent, DefaultBranch: "keep-me"},
+			{RepoID: "r3", Path: "/repos/r3", Status: registry.StatusMissing, DefaultBranch: "stale"},
+		},
+	}
+
+	populateExportDefaultBranches(context.Background(), reg, func(_ context.Context, path string) (string, error) {
+		switch path {
+		case "/repos/r1":
+			return "develop", nil
+		case "/repos/r3":
+			return "should-not-apply", nil
+		default:
+			return "", errors.New("remote HEAD not set")
+		}
+	})
+
+	if got, want := reg.Entries[0].DefaultBranch, "develop"; got != want {
+		t.Fatalf("expected default branch %q, got %q", want, got)
+	}
+	if got, want := reg.Entries[1].DefaultBranch, "keep-me"; got != want {
+		t.Fatalf("expected recorded default branch %q to remain, got %q", want, got)
+	}
+	if got, want := reg.Entries[2].DefaultBranch, "stale"; got != want {
+		t.Fatalf("expected missing entry default branch %q to remain, got %q", want, got)
+	}
+}
@@ -4,12 +4,77 @@ package repokeeper
 import "github.com/spf13/cobra"
 
 const (
-	repoFilterUsage           = "filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing"
+	repoFilterUsage           = "filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata, stale-upstream, branches-behind-default"
 	fieldSelectorUsage        = "field selector (phase 1): tracking.status=all|gone|diverged|behind|ahead|equal, worktree.dirty=true|false, repo.error=true, repo.missing=true, remote.mismatch=true"
 	labelSelectorUsage        = "label selector: key or key=value (comma-separated AND)"
-	upstreamRepairFilterUsage = "filter: all, missing, mismatch"
+	upstreamRepairFilterUsage = "filter: all, missing, mismatch, stale-upstream"
 	noHeadersUsage            = "when using table format, do not print headers"
-	vcsUsage                  = "comma-separated vcs backends: git,hg (default: git)"
+	vcsUsage                  = "comma-separated vcs backends: git,hg, or auto to detect each repo from its .git/.hg marker (default: git)"
+	syncSummaryUsage          = "also emit a JSON summary of per-outcome counts (stdout for json, stderr for table output)"
+	normalizedIDUsage         = "show the raw remote URL, its normalized repo ID, and the registry repo_id side by side"
+	verifyIdentityUsage       = "compare remote-derived, registry, and repo metadata repo_id values and report drift"
+	retriesUsage              = "retry fetch/clone up to this many times after network or timeout failures"
+	retryBackoffUsage         = "wait before the first fetch/clone retry; doubles on each retry"
+	allowOversubscribeUsage   = "allow --concurrency above 8x NumCPU instead of clamping it"
+	pruneRegistryUsage        = "after discovery, mark (default) or remove registry entries that were not rediscovered and whose path no longer exists: mark|remove"
+	resetHardUsage            = "after fetch, force the checked-out branch to match its upstream with git reset --hard and git clean -fdx, discarding local commits, changes, and untracked files; skips bare, mirror, and protected-branch repos and always asks unless --yes"
+	concurrencyPerHostUsage   = "max concurrent repo operations against the same Git host, e.g. to stay under rate limits (0 = unlimited)"
+	olderThanUsage            = "only show repos whose last commit is at least this old (e.g. 90d, 12w, 720h); excludes bare repos and repos without commits"
+	newerThanUsage            = "only show repos whose last commit is at most this old (e.g. 30d, 2w, 48h); excludes bare repos and repos without commits"
+	minAheadUsage             = "only show repos at least this many commits ahead of their upstream; repos without an upstream count as 0"
+	minBehindUsage            = "only show repos at least this many commits behind their upstream; repos without an upstream count as 0"
+	explainUsage              = "add a REASON column (or reason field) saying why each repo matched the filters"
+	severityUsage             = "with --only diverged, score each repo by behind count, dirty state, and staleness (weights from diverged_severity) and list the riskiest first"
+	deepenUsage               = "fetch shallow clones with --deepen N to backfill N more commits of history; full clones fetch normally"
+	matchUsage                = "treat the argument as a pattern over repo IDs and update every match: glob (path.Match, * stops at /) or regex (unanchored); asks for confirmation unless --yes"
+	sinceScanUsage            = "reuse the cached status of repos whose git state is unchanged since the last cached run; unstaged edits to tracked files are not detected"
+	fullUsage                 = "inspect every repo and rebuild the status cache used by --since-scan"
+	failFastUsage             = "stop as soon as one matching repo is dirty, has a gone upstream, or fails inspection, and exit with its code; only repos inspected so far are reported"
+	verifyIgnoredUsage        = "also list ignored files under each worktree to audit overly broad .gitignore rules"
+	strictHooksUsage          = "fail a repo whose hooks.post_sync command exits nonzero instead of reporting the failure as a warning"
+	preRunCommandUsage        = "command to run once before sync executes (e.g. VPN or credential check); nonzero exit aborts the run"
+	planOnlyUsage             = "build the sync plan and save it to --output without executing (apply it later with repokeeper apply --plan)"
+	planOutputUsage           = "file to write the --plan-only sync plan to"
+	fetchRemoteUsage          = "fetch only this named remote instead of --all; repos without it are skipped"
+	statusFormatUsage         = "output format: table, wide, json, yaml, csv, ndjson (one repo object per line, streamed as each inspection completes), or template (see --template)"
+	syncFormatUsage           = "output format: table, wide, json, yaml, csv, or template (see --template)"
+	templateUsage             = "Go template executed once per repo for --format template, e.g. '{{.RepoID}} {{.Tracking.Status}}'; helpers: short, join, lower, upper"
+	backupBranchUsage         = "with --update-local, create a local backup branch at the pre-rebase tip of each diverged repo; {branch} and {timestamp} expand (e.g. backup/{branch}-{timestamp})"
+	noPruneTagsUsage          = "fetch without --prune-tags so local tags missing on the remote are kept"
+	scanConcurrencyUsage      = "max directories probed in parallel during discovery (filesystem only, no network; default: number of CPUs)"
+	fromLastRunUsage          = "only sync repos that failed in the last recorded sync run"
+	pruneEmptyDirsUsage       = "after syncing, remove directories under the configured roots left empty by moved or deleted repos (roots and repos are never removed; --dry-run only lists them)"
+	syncForceUsage            = "when used with --update-local, allow rebase even when branch tracking state is diverged; with --delete-gone-branches, also delete gone branches that are not merged (git branch -D)"
+	deleteGoneBranchesUsage   = "after syncing, delete local branches whose upstream is gone and which are fully merged into the default branch (prints a plan and asks for confirmation; --dry-run only prints the plan; --force also deletes unmerged ones)"
+	autostashAllUsage         = "stash local changes (including untracked files) in dirty repos before syncing them and pop the stash afterwards; a failed pop leaves the stash and is reported as a warning"
+	remoteReconcileUsage      = "optional reconcile mode for remote mismatch: none, registry, git (set-url on the primary remote), or add-remote (add the registry URL as remote repokeeper-upstream)"
+	cloneDepthUsage           = "with --checkout-missing, clone missing checkouts shallowly with git clone --depth N --single-branch; mirrors are always cloned in full"
+	importConcurrencyUsage    = "max clones to run at once (default: the config's concurrency, capped by --jobs)"
+	importDepthUsage          = "clone imported checkouts shallowly with git clone --depth N --single-branch; mirrors are always cloned in full"
+	remoteTemplateUsage       = "with --checkout-missing, build a clone URL for entries without remote_url from their repo ID, e.g. git@{host}:{owner}/{name}.git (local: IDs are skipped)"
+	syncSortByUsage           = "order final results: duration (slowest first); default is by repo id"
+	describeFetchUsage        = "fetch this repo's remote branches and tags first, then describe the refreshed state; a failed fetch is shown as the repo error and exits 1"
+	historyUsage              = "show the N most recent commits (hash, date, author, subject); skipped for missing or bare repos"
+	syncReportUsage           = "also write a JSON run report (timestamp, options, results) to this file, whatever --format is"
+	syncLogDirUsage           = "write each executed repo's raw git commands and output to <dir>/<repo-id>.log (slashes become _); ignored with --dry-run"
+	excludeRemoteHostUsage    = "skip repos whose remote host matches (repeatable; case-insensitive; *.example.com matches subdomains)"
+	includeArchivedUsage      = "also include registry entries marked archived (see repokeeper archive), which are skipped by default"
+	profileUsage              = "merge the named config profile's roots, exclude, and ignored_paths over the base config"
+	nameOnlyUsage             = "print only the path of each matching repo, one per line, with no headers or color (ignores --format)"
+	nullUsage                 = "with --name-only, terminate each path with a NUL byte instead of a newline (for xargs -0)"
+	fromStdinUsage            = "read newline-separated repo directories from stdin and register the ones that are repos, instead of walking roots"
+	maxDepthUsage             = "do not descend more than this many directory levels below each root; repos at exactly that depth are still found (0 = unlimited)"
+	registerOnlyUsage         = "only record discovered paths, without reading remotes; new repos are registered as local:<path> with no remote URL until the next status resolves them"
+	lfsUsage                  = "run git lfs fetch after syncing repos whose .gitattributes use the lfs filter (repos are only probed for LFS with this flag)"
+	updateSubmodulesUsage     = "with --update-local, run git submodule update --init --recursive after a successful rebase in repos with submodules"
+	colorUsage                = "when to color table output: auto (only on a terminal), always (even when piped), or never; JSON/YAML/NDJSON are never colored"
+	jobsUsage                 = "global cap on parallel repo workers for every command, scan included, applied on top of --concurrency (default: defaults.max_jobs)"
+	logJSONUsage              = "write log messages to stderr as one JSON object per line (time, level, msg)"
+	groupByUsage              = "group table output under per-group headers with clean/dirty/gone/error counts, and JSON/YAML repos into a groups map: host or label:<key>"
+	statusSortUsage           = "order repos by path, repo, tracking (gone, diverged, behind first), dirty (dirty first), or behind (most behind first); prefix with - to reverse; ties fall back to repo id"
+	watchUsage                = "re-run the report every interval (e.g. 30s) until interrupted; a terminal is cleared between runs, other output gets a timestamp line before each report; -o json emits one compact report per line"
+	countOnlyUsage            = "print only the total, clean, dirty, diverged, gone, missing, and error counts instead of the repo table"
+	behindThresholdUsage      = "with --only branches-behind-default, the minimum number of commits a local branch must be behind the default branch"
 )
 
 func addFormatFlag(cmd *cobra.Command, usage string) {
@@ -23,6 +88,13 @@ func addNoHeadersFlag(cmd *cobra.Command) {
 func addRepoFilterFlags(cmd *cobra.Command) {
 	cmd.Flags().String("only", "all", repoFilterUsage)
 	cmd.Flags().String("field-selector", "", fieldSelectorUsage)
+	cmd.Flags().StringArray("exclude-remote-host", nil, excludeRemoteHostUsage)
+	cmd.Flags().Bool("include-archived", false, includeArchivedUsage)
+}
+
+func addNameOnlyFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("name-only", false, nameOnlyUsage)
+	cmd.Flags().Bool("null", false, nullUsage)
 }
 
 func addLabelSelectorFlag(cmd *cobra.Command) {
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check every registered repo for object store corruption",
+	Long: "Runs `git fsck --no-dangling` in every present registered repo, bare and mirror clones included, and lists the repos where it found problems. " +
+		"Dangling objects are normal leftovers and are not reported. Checks run concurrently with the same limits as get.\n\n" +
+		"Exits 2 when any repo has integrity issues and 1 when a repo could not be checked.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting fsck")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+		if cfg.Registry == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		eng := engine.New(cfg, cfg.Registry, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), cmdLogger{cmd})
+		report, err := eng.Fsck(cmd.Context(), engine.FsckOptions{Concurrency: concurrency, Timeout: timeout, MaxJobs: maxJobs})
+		if err != nil {
+			return err
+		}
+
+		switch mode.kind {
+		case outputKindTable:
+			setColorOutputMode(cmd, string(mode.kind))
+			logOutputWriteFailure(cmd, "fsck table", writeFsckTable(cmd, report, cwd, []string{config.ConfigRoot(cfgPath)}, noHeaders))
+		case outputKindJSON:
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			logOutputWriteFailure(cmd, "fsck json", err)
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		raiseExitCode(cmd, fsckExitCode(report))
+		infof(cmd, "fsck completed: %d repos checked, %d with issues or errors", report.Checked, len(report.Repos))
+		return nil
+	},
+}
+
+// writeFsckTable prints one row per repo with integrity issues. Repos that
+// could not be checked are reported on stderr instead.
+func writeFsckTable(cmd *cobra.Command, report *model.FsckReport, cwd string, roots []string, noHeaders bool) error {
+	var rows [][]string
+	for _, repo := range report.Repos {
+		path := displayRepoPath(repo.Path, cwd, roots)
+		if repo.Error != "" {
+			warnf(cmd, "could not check %s: %s", sanitizeForDisplay(path), sanitizeForDisplay(repo.Error))
+			continue
+		}
+		rows = append(rows, []string{sanitizeForDisplay(path), sanitizeForDisplay(repo.RepoID), sanitizeForDisplay(strings.Join(repo.Issues, "; "))})
+	}
+	if len(rows) == 0 {
+		infof(cmd, "no integrity issues found")
+		return nil
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"PATH", "REPO", "ISSUES"}, rows)
+}
+
+func fsckExitCode(report *model.FsckReport) int {
+	code := 0
+	for _, repo := range report.Repos {
+		if len(repo.Issues) > 0 {
+			return 2
+		}
+		if repo.Error != "" {
+			code = 1
+		}
+	}
+	return code
+}
+
+func init() {
+	addFormatFlag(fsckCmd, "output format: table or json")
+	addNoHeadersFlag(fsckCmd)
+	addVCSFlag(fsckCmd)
+	fsckCmd.Flags().Int("concurrency", 0, "max concurrent repo checks (default: min(8, NumCPU))")
+	fsckCmd.Flags().Int("timeout", 0, "timeout in seconds per repo (0 uses config default)")
+	rootCmd.AddCommand(fsckCmd)
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func TestWriteFsckTable(t *testing.T) {
+	t.Parallel()
+
+	report := &model.FsckReport{Checked: 3, Repos: []model.FsckRepo{
+		{RepoID: "github.com/org/a", Path: "/work/a", Issues: []string{"missing blob 2222", "error: object file .git/objects/22/22 is empty"}},
+		{RepoID: "github.com/org/b", Path: "/work/b", Error: "context deadline exceeded"},
+	}}
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	if err := writeFsckTable(cmd, report, "/work", nil, false); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "ISSUES") || !strings.Contains(lines[1], "missing blob 2222; error: object file") {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "could not check b: context deadline exceeded") {
+		t.Fatalf("expected error on stderr, got %q", errOut.String())
+	}
+	if code := fsckExitCode(report); code != 2 {
+		t.Fatalf("expected exit code 2 with an issue row, got %d", code)
+	}
+	if code := fsckExitCode(&model.FsckReport{Repos: report.Repos[1:]}); code != 1 {
+		t.Fatalf("expected exit code 1 when a repo could not be checked, got %d", code)
+	}
+}
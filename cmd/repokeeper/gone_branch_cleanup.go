@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+type goneBranchCleanupOptions struct {
+	engine engine.GoneBranchesOptions
+	dryRun bool
+	yes    bool
+	// table prints the plan on stdout; otherwise it goes to stderr so
+	// machine-readable sync output stays parseable.
+	table     bool
+	cwd       string
+	roots     []string
+	noHeaders bool
+}
+
+// deleteGoneBranchesAfterSync plans and, once confirmed, deletes gone local
+// branches in the repos the sync covered. It runs after the sync itself so the
+// fetch --prune has refreshed which upstreams are gone. Failed deletes raise
+// the exit code to 2.
+func deleteGoneBranchesAfterSync(cmd *cobra.Command, eng *engine.Engine, synced []engine.SyncResult, opts goneBranchCleanupOptions) error {
+	plans, err := eng.PlanGoneBranchDeletions(cmd.Context(), opts.engine)
+	if err != nil {
+		return err
+	}
+	plans = goneBranchPlansForSynced(plans, synced)
+	if len(plans) == 0 {
+		infof(cmd, "no gone branches to delete")
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if !opts.table {
+		out = cmd.ErrOrStderr()
+	}
+	if err := writeGoneBranchPlanTable(out, plans, opts.cwd, opts.roots, opts.noHeaders); err != nil {
+		return err
+	}
+	pending := 0
+	for _, plan := range plans {
+		if plan.Action != engine.GoneBranchSkip {
+			pending++
+		}
+	}
+	if opts.dryRun || pending == 0 {
+		return nil
+	}
+	if !opts.yes {
+		confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Delete %d gone branches? [y/N]: ", pending))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			infof(cmd, "gone branch cleanup cancelled")
+			return nil
+		}
+	}
+
+	deleted := 0
+	for _, res := range eng.DeleteGoneBranches(cmd.Context(), plans) {
+		path := sanitizeForDisplay(displayRepoPath(res.Path, opts.cwd, opts.roots))
+		switch {
+		case res.Error != "" && res.Action != engine.GoneBranchSkip:
+			warnf(cmd, "could not delete branch %s in %s: %s", sanitizeForDisplay(res.Branch), path, sanitizeForDisplay(res.Error))
+			raiseExitCode(cmd, 2)
+		case res.Deleted:
+			deleted++
+			debugf(cmd, "deleted branch %s in %s", res.Branch, path)
+		}
+	}
+	infof(cmd, "deleted %d gone branches", deleted)
+	return nil
+}
+
+// goneBranchPlansForSynced keeps the plans for repos in synced, so --only,
+// --field-selector, and --from-last-run scope the cleanup like the sync.
+func goneBranchPlansForSynced(plans []engine.GoneBranchDeletion, synced []engine.SyncResult) []engine.GoneBranchDeletion {
+	paths := make(map[string]bool, len(synced))
+	for _, res := range synced {
+		paths[res.Path] = true
+	}
+	kept := plans[:0]
+	for _, plan := range plans {
+		if paths[plan.Path] {
+			kept = append(kept, plan)
+		}
+	}
+	return kept
+}
+
+func writeGoneBranchPlanTable(w io.Writer, plans []engine.GoneBranchDeletion, cwd string, roots []string, noHeaders bool) error {
+	rows := make([][]string, 0, len(plans))
+	for _, plan := range plans {
+		reason := plan.Reason
+		if plan.Error != "" {
+			reason = plan.Reason + ": " + plan.Error
+		}
+		rows = append(rows, []string{
+			sanitizeForDisplay(displayRepoPath(plan.Path, cwd, roots)),
+			sanitizeForDisplay(dashIfEmpty(plan.Branch)),
+			sanitizeForDisplay(dashIfEmpty(plan.Upstream)),
+			plan.Action,
+			sanitizeForDisplay(dashIfEmpty(reason)),
+		})
+	}
+	return cliio.WriteTable(w, false, noHeaders, []string{"PATH", "BRANCH", "UPSTREAM", "ACTION", "REASON"}, rows)
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// setupGoneBranchRepo clones a fresh remote and leaves two local branches
+// whose upstreams were deleted on the remote: merged (merged into main) and
+// unmerged. The clone has not fetched since, so only a sync sees them gone.
+func setupGoneBranchRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	work := filepath.Join(tmp, "work")
+	mustRunGit(t, tmp, "init", "-q", "--bare", remote)
+	mustRunGit(t, tmp, "clone", "-q", remote, work)
+	if err := os.WriteFile(filepath.Join(work, "file.txt"), []byte("base\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustRunGit(t, work, "add", "file.txt")
+	mustRunGit(t, work, "commit", "-q", "-m", "base")
+	mustRunGit(t, work, "branch", "-M", "main")
+	mustRunGit(t, work, "push", "-q", "-u", "origin", "main")
+	mustRunGit(t, remote, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	for _, branch := range []string{"merged", "unmerged"} {
+		mustRunGit(t, work, "checkout", "-q", "-b", branch, "main")
+		if err := os.WriteFile(filepath.Join(work, branch+".txt"), []byte(branch+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		mustRunGit(t, work, "add", branch+".txt")
+		mustRunGit(t, work, "commit", "-q", "-m", branch)
+		mustRunGit(t, work, "push", "-q", "-u", "origin", branch)
+	}
+	mustRunGit(t, work, "checkout", "-q", "main")
+	mustRunGit(t, work, "merge", "-q", "--no-ff", "-m", "merge merged", "merged")
+	mustRunGit(t, work, "push", "-q", "origin", "main")
+	mustRunGit(t, remote, "branch", "-D", "merged", "unmerged")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "local:work", Path: work, RemoteURL: remote, Status: registry.StatusPresent, LastSeen: time.Now()},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	return cfgPath, work
+}
+
+func runSyncDeletingGoneBranches(t *testing.T, cfgPath string, dryRun bool) string {
+	t.Helper()
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("format", "table")
+	_ = syncCmd.Flags().Set("dry-run", boolToFlag(dryRun))
+	_ = syncCmd.Flags().Set("delete-gone-branches", "true")
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = syncCmd.Flags().Set("dry-run", "false")
+		_ = syncCmd.Flags().Set("delete-gone-branches", "false")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("sync --delete-gone-branches: %v", err)
+	}
+	return out.String()
+}
+
+func TestSyncDeleteGoneBranchesKeepsUnmergedAndCurrentBranch(t *testing.T) {
+	cfgPath, work := setupGoneBranchRepo(t)
+	state := runtimeStateFor(rootCmd)
+	prevExit := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExit }()
+
+	// A dry run fetches nothing, so the upstreams do not look gone yet.
+	runSyncDeletingGoneBranches(t, cfgPath, true)
+	if strings.TrimSpace(mustRunGit(t, work, "branch", "--list", "merged")) == "" {
+		t.Fatal("dry run must not delete branches")
+	}
+
+	out := runSyncDeletingGoneBranches(t, cfgPath, false)
+	if !strings.Contains(out, "BRANCH") || !strings.Contains(out, "not merged into origin/main") {
+		t.Fatalf("expected a plan table skipping the unmerged branch, got:\n%s", out)
+	}
+	if got := strings.TrimSpace(mustRunGit(t, work, "branch", "--list", "merged")); got != "" {
+		t.Fatalf("expected merged gone branch to be deleted, got %q", got)
+	}
+	if strings.TrimSpace(mustRunGit(t, work, "branch", "--list", "unmerged")) == "" {
+		t.Fatal("expected unmerged gone branch to be kept without --force")
+	}
+	if got := strings.TrimSpace(mustRunGit(t, work, "branch", "--show-current")); got != "main" {
+		t.Fatalf("expected main to stay checked out, got %q", got)
+	}
+	if state.exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", state.exitCode)
+	}
+}
+
+func TestGoneBranchPlansForSyncedKeepsSyncedRepos(t *testing.T) {
+	t.Parallel()
+
+	plans := []engine.GoneBranchDeletion{
+		{Path: "/work/a", Branch: "old", Action: engine.GoneBranchDelete},
+		{Path: "/work/b", Branch: "old", Action: engine.GoneBranchDelete},
+	}
+	got := goneBranchPlansForSynced(plans, []engine.SyncResult{{Path: "/work/b"}})
+	if len(got) != 1 || got[0].Path != "/work/b" {
+		t.Fatalf("expected only /work/b, got %+v", got)
+	}
+}
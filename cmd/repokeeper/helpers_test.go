@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/engine"
 	"github.com/skaphos/repokeeper/internal/model"
@@ -311,6 +312,27 @@ func TestWriteStatusTableDoesNotTruncatePathBranchOrTracking(t *testing.T) {
 	}
 }
 
+func TestWriteStatusTableMarksLinkedWorktrees(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+
+	report := &model.StatusReport{
+		Repos: []model.RepoStatus{
+			{RepoID: "github.com/org/repo", Path: "/tmp/repo", WorktreeRole: model.WorktreeRoleMain, Worktree: &model.Worktree{}},
+			{RepoID: "github.com/org/repo", Path: "/tmp/repo-feature", WorktreeRole: model.WorktreeRoleLinked, Worktree: &model.Worktree{}},
+		},
+	}
+	if err := writeStatusTable(cmd, report, "/tmp", nil, false, false); err != nil {
+		t.Fatalf("writeStatusTable returned error: %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "repo-feature [linked]") || strings.Count(got, "[linked]") != 1 {
+		t.Fatalf("expected only the linked worktree to be marked, got: %q", got)
+	}
+}
+
 func TestWriteStatusTableStripsEscapeMarkers(t *testing.T) {
 	out := &bytes.Buffer{}
 	cmd := &cobra.Command{}
@@ -359,7 +381,7 @@ func TestConfirmSyncExecution(t *testing.T) {
 	cmd := &cobra.Command{}
 	cmd.SetIn(strings.NewReader("yes\n"))
 	cmd.SetErr(&bytes.Buffer{})
-	ok, err := confirmSyncExecution(cmd)
+	ok, err := confirmSyncExecution(cmd, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -370,13 +392,28 @@ func TestConfirmSyncExecution(t *testing.T) {
 	cmd = &cobra.Command{}
 	cmd.SetIn(strings.NewReader("n\n"))
 	cmd.SetErr(&bytes.Buffer{})
-	ok, err = confirmSyncExecution(cmd)
+	ok, err = confirmSyncExecution(cmd, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if ok {
 		t.Fatal("expected confirmation to be rejected")
 	}
+
+	cmd = &cobra.Command{}
+	stderr := &bytes.Buffer{}
+	cmd.SetIn(strings.NewReader("y\n"))
+	cmd.SetErr(stderr)
+	plan := []engine.SyncResult{{RepoID: "r1", Path: "/repo", Planned: true, ResetTarget: "origin/main"}}
+	if ok, err = confirmSyncExecution(cmd, plan); err != nil || !ok {
+		t.Fatalf("expected reset confirmation to be accepted, got %t, %v", ok, err)
+	}
+	if !strings.Contains(stderr.String(), "git reset --hard") || !strings.Contains(stderr.String(), "1 repos") {
+		t.Fatalf("expected the reset prompt, got %q", stderr.String())
+	}
+	if !syncPlanNeedsConfirmation(plan) {
+		t.Fatal("expected a reset plan to need confirmation")
+	}
 }
 
 func TestSyncPlanNeedsConfirmation(t *testing.T) {
@@ -576,6 +613,11 @@ func TestDescribeSyncActionAdditionalBranches(t *testing.T) {
 			},
 			want: "fetch",
 		},
+		{
+			name: "fetch with lfs",
+			in:   engine.SyncResult{Action: "git fetch --all --prune --prune-tags --no-recurse-submodules && git lfs fetch"},
+			want: "fetch + lfs",
+		},
 		{name: "skip generic", in: engine.SyncResult{Error: engine.SyncErrorSkipped}, want: "skip"},
 		{name: "skip missing", in: engine.SyncResult{Error: engine.SyncErrorMissing}, want: "skip missing"},
 		{name: "skip local update no reason", in: engine.SyncResult{Error: engine.SyncErrorSkippedLocalUpdatePrefix}, want: "skip local update"},
@@ -595,7 +637,7 @@ func TestConfirmSyncExecutionEOF(t *testing.T) {
 	cmd := &cobra.Command{}
 	cmd.SetIn(strings.NewReader("y"))
 	cmd.SetErr(&bytes.Buffer{})
-	ok, err := confirmSyncExecution(cmd)
+	ok, err := confirmSyncExecution(cmd, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -675,6 +717,62 @@ func TestWriteSyncTableWideBranches(t *testing.T) {
 	}
 }
 
+func TestWriteSyncTableWideShowsTagsPrunedAndDuration(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+
+	results := []engine.SyncResult{
+		{RepoID: "r1", Path: "/tmp/repo-a", OK: true, Outcome: engine.SyncOutcomeFetched, Action: "git fetch --all --prune --prune-tags --no-recurse-submodules", TagsPruned: 3, Duration: 1530 * time.Millisecond},
+	}
+	if err := writeSyncTable(cmd, results, nil, "/tmp", nil, false, false, true); err != nil {
+		t.Fatalf("writeSyncTable returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	header := strings.Fields(lines[0])
+	row := strings.Fields(lines[1])
+	if header[len(header)-2] != "TAGS_PRUNED" || row[len(row)-2] != "3" {
+		t.Fatalf("expected a TAGS_PRUNED column of 3, got:\n%s", out.String())
+	}
+	if header[len(header)-1] != "DURATION" || row[len(row)-1] != "1.5s" {
+		t.Fatalf("expected a trailing DURATION column of 1.5s, got:\n%s", out.String())
+	}
+	got := toSyncResultJSON(results[0])
+	if got.TagsPruned != 3 {
+		t.Fatalf("expected tags_pruned in JSON projection, got %+v", got)
+	}
+	if got.DurationMS != 1530 {
+		t.Fatalf("expected duration_ms in JSON projection, got %+v", got)
+	}
+}
+
+func TestSyncDurationDisplayAndSort(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                       "-",
+		42*time.Millisecond + 7: "42ms",
+		2345 * time.Millisecond: "2.3s",
+	} {
+		if got := syncDurationDisplay(d); got != want {
+			t.Fatalf("syncDurationDisplay(%v) = %q, want %q", d, got, want)
+		}
+	}
+
+	results := []engine.SyncResult{
+		{RepoID: "a", Duration: time.Second},
+		{RepoID: "b", Duration: 3 * time.Second},
+		{RepoID: "c"},
+		{RepoID: "d", Duration: time.Second},
+	}
+	sortSyncResultsByDuration(results)
+	var order []string
+	for _, res := range results {
+		order = append(order, res.RepoID)
+	}
+	if strings.Join(order, ",") != "b,a,d,c" {
+		t.Fatalf("expected slowest-first order with stable ties, got %v", order)
+	}
+}
+
 func TestDivergedAdviceAndTable(t *testing.T) {
 	out := &bytes.Buffer{}
 	cmd := &cobra.Command{}
@@ -708,7 +806,7 @@ func TestDivergedAdviceAndTable(t *testing.T) {
 		t.Fatalf("expected diverged reason and recommendation, got %#v", advice[0])
 	}
 
-	if err := writeDivergedStatusTable(cmd, report, "/tmp", nil, false, true); err != nil {
+	if err := writeDivergedStatusTable(cmd, report, "/tmp", nil, false, true, nil); err != nil {
 		t.Fatalf("writeDivergedStatusTable returned error: %v", err)
 	}
 	got := out.String()
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/caarlos0/go-shellwords"
+	"github.com/skaphos/repokeeper/internal/engine"
+)
+
+// postSyncHook turns the configured hooks.post_sync command into an engine
+// hook. Like --pre-run-command, the command is split with shell quoting rules
+// and run without a shell; wrap it in sh -c to use shell syntax. It runs in
+// the repo's directory with REPOKEEPER_PATH, REPOKEEPER_REPO_ID, and
+// REPOKEEPER_OUTCOME added to the environment. Hooks for different repos may
+// run concurrently, so output is captured and reported only on failure.
+func postSyncHook(command string) (engine.PostSyncHook, error) {
+	parts, err := parseCommandLine("hooks.post_sync", command)
+	if err != nil || parts == nil {
+		return nil, err
+	}
+	return func(ctx context.Context, res engine.SyncResult) error {
+		run := exec.CommandContext(ctx, parts[0], parts[1:]...)
+		run.Dir = res.Path
+		run.Env = append(os.Environ(),
+			"REPOKEEPER_PATH="+res.Path,
+			"REPOKEEPER_REPO_ID="+res.RepoID,
+			"REPOKEEPER_OUTCOME="+string(res.Outcome),
+		)
+		out, err := run.CombinedOutput()
+		if err != nil {
+			if trimmed := strings.TrimSpace(string(out)); trimmed != "" {
+				return fmt.Errorf("%w: %s", err, trimmed)
+			}
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// parseCommandLine splits a configured command with shell quoting rules for
+// running without a shell. It returns nil parts for a blank command, and
+// names flagName in the error for malformed quoting.
+func parseCommandLine(flagName, command string) ([]string, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, nil
+	}
+	parts, err := shellwords.Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", flagName, command, err)
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	return parts, nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+)
+
+func setPostSyncHook(t *testing.T, cfgPath, command string) {
+	t.Helper()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Hooks.PostSync = command
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+}
+
+func TestSyncRunEPostSyncHookReceivesRepoEnv(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	cfgPath, missingPath := setupCheckoutMissingSyncFixture(t)
+	marker := filepath.Join(t.TempDir(), "hook.txt")
+	setPostSyncHook(t, cfgPath, `sh -c 'echo "$REPOKEEPER_PATH|$REPOKEEPER_REPO_ID|$REPOKEEPER_OUTCOME|$PWD" > `+marker+`'`)
+
+	if _, err := runSyncWithPreRunCommand(t, cfgPath, ""); err != nil {
+		t.Fatalf("sync run failed: %v", err)
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("expected the hook to run: %v", err)
+	}
+	want := strings.Join([]string{missingPath, "github.com/org/repo-checkout", string(engine.SyncOutcomeCheckoutMissing), missingPath}, "|")
+	if got := strings.TrimSpace(string(data)); got != want {
+		t.Fatalf("unexpected hook env: got %q, want %q", got, want)
+	}
+}
+
+func TestSyncRunEPostSyncHookFailure(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	t.Run("reported as a warning by default", func(t *testing.T) {
+		cfgPath, _ := setupCheckoutMissingSyncFixture(t)
+		setPostSyncHook(t, cfgPath, `sh -c 'echo hook broke; exit 3'`)
+
+		out, err := runSyncWithPreRunCommand(t, cfgPath, "")
+		if err != nil {
+			t.Fatalf("sync run failed: %v", err)
+		}
+		var results []syncResultJSON
+		if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+			t.Fatalf("decode sync output %q: %v", out.String(), err)
+		}
+		if len(results) != 1 || !results[0].OK || !strings.Contains(results[0].Warning, "hook broke") {
+			t.Fatalf("expected an OK result with a hook warning, got %+v", results)
+		}
+	})
+
+	t.Run("fails the repo with --strict-hooks", func(t *testing.T) {
+		cfgPath, _ := setupCheckoutMissingSyncFixture(t)
+		setPostSyncHook(t, cfgPath, `sh -c 'exit 3'`)
+		_ = syncCmd.Flags().Set("strict-hooks", "true")
+		defer func() { _ = syncCmd.Flags().Set("strict-hooks", "false") }()
+
+		out, err := runSyncWithPreRunCommand(t, cfgPath, "")
+		if err != nil {
+			t.Fatalf("sync run failed: %v", err)
+		}
+		var results []syncResultJSON
+		if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+			t.Fatalf("decode sync output %q: %v", out.String(), err)
+		}
+		if len(results) != 1 || results[0].OK || results[0].Outcome != string(engine.SyncOutcomeFailedHook) {
+			t.Fatalf("expected a failed_hook result, got %+v", results)
+		}
+		if got := runtimeStateFor(syncCmd).exitCode; got == 0 {
+			t.Fatal("expected a nonzero exit code for the failed hook")
+		}
+	})
+}
+
+func TestParseCommandLine(t *testing.T) {
+	parts, err := parseCommandLine("hooks.post_sync", `  sh -c 'echo "a b"'  `)
+	if err != nil || strings.Join(parts, "|") != `sh|-c|echo "a b"` {
+		t.Fatalf("expected quoted words, got %q (err %v)", parts, err)
+	}
+	if parts, err := parseCommandLine("hooks.post_sync", "   "); err != nil || parts != nil {
+		t.Fatalf("expected nil parts for a blank command, got %q (err %v)", parts, err)
+	}
+	if _, err := parseCommandLine("--pre-run-command", `echo 'unterminated`); err == nil || !strings.Contains(err.Error(), `invalid --pre-run-command "echo 'unterminated"`) {
+		t.Fatalf("expected a quoting error naming the flag, got %v", err)
+	}
+}
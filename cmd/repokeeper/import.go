@@ -2,6 +2,7 @@
 package repokeeper
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -33,13 +34,35 @@ const (
 	importConflictPolicySkip   importConflictPolicy = "skip"
 	importConflictPolicyBundle importConflictPolicy = "bundle"
 	importConflictPolicyLocal  importConflictPolicy = "local"
+	// importConflictPolicyMergeMetadata keeps the local entry but unions its
+	// labels and annotations with the bundle's, key by key.
+	importConflictPolicyMergeMetadata importConflictPolicy = "merge-metadata"
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import [bundle-file|-]",
 	Short: "Import an exported config bundle",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Import an exported config bundle, or with --repos-file clone a plain list of
+remote URLs (one per line) under the current directory at host/owner/repo
+paths and register them.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		reposFile, _ := cmd.Flags().GetString("repos-file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		depth, _ := cmd.Flags().GetInt("depth")
+		if depth < 0 {
+			return fmt.Errorf("--depth must not be negative, got %d", depth)
+		}
+		if concurrency, _ := cmd.Flags().GetInt("concurrency"); concurrency < 0 {
+			return fmt.Errorf("--concurrency must not be negative, got %d", concurrency)
+		}
+		if reposFile != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("--repos-file cannot be combined with a bundle file")
+			}
+			dangerouslyDeleteExisting, _ := cmd.Flags().GetBool("dangerously-delete-existing")
+			return runImportReposFile(cmd, reposFile, dryRun, dangerouslyDeleteExisting, depth)
+		}
 		force, _ := cmd.Flags().GetBool("force")
 		modeRaw, _ := cmd.Flags().GetString("mode")
 		mode, err := parseImportMode(modeRaw)
@@ -51,10 +74,17 @@ var importCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		strict, _ := cmd.Flags().GetBool("strict")
+		if strict && onConflict != importConflictPolicyMergeMetadata {
+			return fmt.Errorf("--strict requires --on-conflict %s", importConflictPolicyMergeMetadata)
+		}
 		includeRegistry, _ := cmd.Flags().GetBool("include-registry")
 		preserveRegistryPath, _ := cmd.Flags().GetBool("preserve-registry-path")
 		dangerouslyDeleteExisting, _ := cmd.Flags().GetBool("dangerously-delete-existing")
 		fileOnly, _ := cmd.Flags().GetBool("file-only")
+		if fileOnly && depth > 0 {
+			return fmt.Errorf("--depth cannot be combined with --file-only")
+		}
 		cloneRepos := !fileOnly
 
 		if fileOnly {
@@ -102,6 +132,13 @@ var importCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		hasBundleConfig, err := bundleHasConfigSection(data)
+		if err != nil {
+			return err
+		}
+		if fileOnly && !hasBundleConfig {
+			return fmt.Errorf("--file-only imports only the config, but the bundle has no config section")
+		}
 
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -120,10 +157,34 @@ var importCmd = &cobra.Command{
 			return fmt.Errorf("config already exists at %q (use --force to overwrite)", cfgPath)
 		}
 
-		cfg := prepareImportedConfig(mode, existingCfg, hasExistingCfg, bundle.Config)
-		mergeImportedRegistry(&cfg, mode, includeRegistry, bundle.Registry, onConflict)
+		if mode == importModeReplace && !hasBundleConfig {
+			if hasExistingCfg {
+				warnf(cmd, "bundle has no config section; keeping the existing config at %s and replacing only its registry", cfgPath)
+			} else {
+				warnf(cmd, "bundle has no config section; writing default config settings with the bundled registry")
+			}
+		}
+		cfg := prepareImportedConfig(mode, existingCfg, hasExistingCfg, bundle.Config, hasBundleConfig)
+		metadataConflicts := mergeImportedRegistry(&cfg, mode, includeRegistry, bundle.Registry, onConflict)
+		for _, conflict := range metadataConflicts {
+			warnf(cmd, "%s: %s %q is %q in the bundle, replacing local %q", conflict.RepoID, conflict.Field, conflict.Key, conflict.Kept, conflict.Dropped)
+		}
+		if strict && len(metadataConflicts) > 0 {
+			return fmt.Errorf("%d label or annotation conflicts; resolve them or rerun without --strict", len(metadataConflicts))
+		}
 		dropIgnoredImportEntries(&cfg, bundle, cwd)
-		if !preserveRegistryPath && mode == importModeReplace {
+		if dryRun {
+			// Preview only: nothing is cloned or saved, and the bundled entries
+			// are compared as they would be registered before cloning.
+			var bundled *registry.Registry
+			if includeRegistry {
+				bundled = sanitizeImportedRegistry(bundle.Registry)
+			}
+			return writeImportDiffTable(cmd, diffImportedRegistry(localRegistryBeforeMerge, cfg.Registry, bundled), cwd)
+		}
+		// A registry-only bundle keeps the local config, including where it
+		// stores its registry.
+		if !preserveRegistryPath && mode == importModeReplace && hasBundleConfig {
 			cfg.RegistryPath = ""
 		}
 
@@ -135,7 +196,7 @@ var importCmd = &cobra.Command{
 				entriesToClone = selectMergeCloneEntries(localRegistryBeforeMerge, bundle.Registry, onConflict)
 				importPlanRows = mergePolicyPreflightSkips(localRegistryBeforeMerge, bundle.Registry, onConflict, cwd, bundle.Root)
 			}
-			importPlan, err = planImportedEntries(&cfg, bundle, cwd, dangerouslyDeleteExisting, entriesToClone)
+			importPlan, err = planImportedEntries(cmd.Context(), &cfg, bundle, cwd, dangerouslyDeleteExisting, depth, entriesToClone)
 			if err != nil {
 				return err
 			}
@@ -179,11 +240,16 @@ var importCmd = &cobra.Command{
 func init() {
 	importCmd.Flags().Bool("force", false, "overwrite existing config file")
 	importCmd.Flags().String("mode", string(importModeMerge), "import mode: merge or replace")
-	importCmd.Flags().String("on-conflict", string(importConflictPolicyBundle), "when mode=merge and repo_id exists locally: skip, bundle, or local")
+	importCmd.Flags().String("on-conflict", string(importConflictPolicyBundle), "when mode=merge and repo_id exists locally: skip, bundle, local, or merge-metadata (keep the local entry, union labels and annotations with the bundle winning per key)")
+	importCmd.Flags().Bool("strict", false, "with --on-conflict merge-metadata, fail instead of saving when a label or annotation key differs between the local entry and the bundle")
 	importCmd.Flags().Bool("include-registry", true, "import bundled registry when present")
 	importCmd.Flags().Bool("preserve-registry-path", false, "keep bundled registry_path (resolved relative to imported config file)")
 	importCmd.Flags().Bool("dangerously-delete-existing", false, "dangerous: delete conflicting target repo paths before cloning")
 	importCmd.Flags().Bool("file-only", false, "import config file only (disable registry import and cloning)")
+	importCmd.Flags().String("repos-file", "", "clone each remote URL listed in this file (one per line, - for stdin) instead of importing a bundle")
+	importCmd.Flags().Bool("dry-run", false, "print the registry changes (or, with --repos-file, the planned clone targets) without cloning or saving")
+	importCmd.Flags().Int("depth", 0, importDepthUsage)
+	importCmd.Flags().Int("concurrency", 0, importConcurrencyUsage)
 
 	rootCmd.AddCommand(importCmd)
 }
@@ -207,8 +273,10 @@ func parseImportConflictPolicy(raw string) (importConflictPolicy, error) {
 		return importConflictPolicySkip, nil
 	case string(importConflictPolicyLocal):
 		return importConflictPolicyLocal, nil
+	case string(importConflictPolicyMergeMetadata):
+		return importConflictPolicyMergeMetadata, nil
 	default:
-		return "", fmt.Errorf("invalid --on-conflict %q (supported: skip,bundle,local)", raw)
+		return "", fmt.Errorf("invalid --on-conflict %q (supported: skip,bundle,local,merge-metadata)", raw)
 	}
 }
 
@@ -227,40 +295,74 @@ func loadExistingConfig(cfgPath string) (config.Config, bool, error) {
 	return *cfg, true, nil
 }
 
-func prepareImportedConfig(mode importMode, existing config.Config, hasExisting bool, bundled config.Config) config.Config {
+// prepareImportedConfig picks the base config the bundled registry is merged
+// into. A bundle without a config section never replaces local settings: the
+// existing config is kept, or defaults are used when there is none.
+func prepareImportedConfig(mode importMode, existing config.Config, hasExisting bool, bundled config.Config, hasBundled bool) config.Config {
 	if mode == importModeMerge && hasExisting {
 		return existing
 	}
+	if !hasBundled {
+		if hasExisting {
+			return existing
+		}
+		return config.DefaultConfig()
+	}
 	return bundled
 }
 
+// bundleHasConfigSection reports whether the raw bundle carries a non-empty
+// config mapping. Registry-only bundles omit it or leave it empty/null.
+func bundleHasConfigSection(data []byte) (bool, error) {
+	var raw struct {
+		Config yaml.Node `yaml:"config"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return false, err
+	}
+	switch raw.Config.Kind {
+	case 0:
+		return false, nil
+	case yaml.ScalarNode:
+		return raw.Config.Tag != "!!null", nil
+	case yaml.MappingNode:
+		return len(raw.Config.Content) > 0, nil
+	default:
+		return true, nil
+	}
+}
+
+// mergeImportedRegistry applies the bundled registry to cfg. With the
+// merge-metadata policy it returns the label and annotation keys that the
+// bundle overrode on conflicting entries.
 func mergeImportedRegistry(
 	cfg *config.Config,
 	mode importMode,
 	includeRegistry bool,
 	bundled *registry.Registry,
 	policy importConflictPolicy,
-) {
+) []registry.DedupeConflict {
 	if cfg == nil {
-		return
+		return nil
 	}
 	if !includeRegistry {
 		if mode == importModeReplace {
 			cfg.Registry = nil
 		}
-		return
+		return nil
 	}
 	bundled = sanitizeImportedRegistry(bundled)
 	if mode == importModeReplace {
 		cfg.Registry = cloneRegistry(bundled)
-		return
+		return nil
 	}
 	if cfg.Registry == nil {
 		cfg.Registry = &registry.Registry{}
 	}
 	if bundled == nil {
-		return
+		return nil
 	}
+	var conflicts []registry.DedupeConflict
 	for _, incoming := range bundled.Entries {
 		matchIndex, _ := mergeRegistryMatchIndex(cfg.Registry, incoming)
 		if matchIndex < 0 {
@@ -275,10 +377,15 @@ func mergeImportedRegistry(
 		switch policy {
 		case importConflictPolicyBundle:
 			cfg.Registry.Entries[matchIndex] = incoming
+		case importConflictPolicyMergeMetadata:
+			merged, keyConflicts := registry.MergeMetadata(existing, incoming)
+			cfg.Registry.Entries[matchIndex] = merged
+			conflicts = append(conflicts, keyConflicts...)
 		case importConflictPolicySkip, importConflictPolicyLocal:
 		}
 	}
 	cfg.Registry.UpdatedAt = time.Now()
+	return conflicts
 }
 
 func normalizeImportedBundle(bundle exportBundle) (exportBundle, error) {
@@ -306,6 +413,9 @@ func sanitizeImportedRegistry(reg *registry.Registry) *registry.Registry {
 		entry.RepoMetadataFile = ""
 		entry.RepoMetadataError = ""
 		entry.RepoMetadataFingerprint = ""
+		entry.LastInspect = ""
+		entry.Shallow = false
+		entry.MovedTo = ""
 		entry.RepoMetadata = nil
 		if strings.TrimSpace(entry.CheckoutID) == "" {
 			entry.CheckoutID = inferredCheckoutIDFromPath(entry.Path)
@@ -390,10 +500,12 @@ func stringMapsEqual(a, b map[string]string) bool {
 }
 
 func planImportedEntries(
+	ctx context.Context,
 	cfg *config.Config,
 	bundle exportBundle,
 	cwd string,
 	dangerouslyDeleteExisting bool,
+	depth int,
 	entries []registry.Entry,
 ) (engine.ImportClonePlan, error) {
 	if cfg == nil || cfg.Registry == nil {
@@ -401,11 +513,12 @@ func planImportedEntries(
 	}
 
 	eng := engine.New(cfg, cfg.Registry, vcs.NewGitAdapter(nil), vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
-	plan, err := eng.PlanImportClones(entries, engine.ImportCloneOptions{
+	plan, err := eng.PlanImportClones(ctx, entries, engine.ImportCloneOptions{
 		CWD:                       cwd,
 		BundleRoot:                bundle.Root,
 		DangerouslyDeleteExisting: dangerouslyDeleteExisting,
 		ResolveTargetRelativePath: importTargetRelativePath,
+		Depth:                     depth,
 	})
 	if err != nil {
 		return engine.ImportClonePlan{}, err
@@ -422,6 +535,12 @@ func executeImportClonePlanWithProgress(
 	if cfg == nil || cfg.Registry == nil {
 		return nil, nil
 	}
+	maxJobs, err := maxJobsOverride(cmd)
+	if err != nil {
+		return nil, err
+	}
+	plan.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+	plan.MaxJobs = maxJobs
 	eng := engine.New(cfg, cfg.Registry, vcs.NewGitAdapter(nil), vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
 
 	failures, err := eng.ExecuteImportClones(cmd.Context(), plan, engine.ImportCloneCallbacks{
@@ -471,6 +590,8 @@ func mergePolicyPreflightSkips(local, bundled *registry.Registry, policy importC
 		detail := ""
 		if !registryEntriesConflict(existing, incoming) {
 			detail = "unchanged local entry"
+		} else if policy == importConflictPolicyMergeMetadata {
+			detail = "conflict policy merges labels and annotations into local entry"
 		} else if policy != importConflictPolicyBundle {
 			detail = "conflict policy keeps local entry"
 		}
@@ -488,9 +609,16 @@ func mergePolicyPreflightSkips(local, bundled *registry.Registry, policy importC
 }
 
 func writeImportClonePlan(cmd *cobra.Command, plan engine.ImportClonePlan, extras []importClonePlanRow, cwd string) error {
-	planRows := make([]importClonePlanRow, 0, len(plan.Clones)+len(plan.Skipped)+len(extras))
+	planRows := make([]importClonePlanRow, 0, len(plan.Clones)+len(plan.Existing)+len(plan.Skipped)+len(extras))
 	for _, clone := range plan.Clones {
-		planRows = append(planRows, importClonePlanRow{Path: clone.Path, Status: "clone", Detail: "ready", RepoID: clone.Entry.RepoID})
+		detail := "ready"
+		if plan.Depth > 0 && clone.Entry.Type != "mirror" {
+			detail = fmt.Sprintf("ready (depth %d)", plan.Depth)
+		}
+		planRows = append(planRows, importClonePlanRow{Path: clone.Path, Status: "clone", Detail: detail, RepoID: clone.Entry.RepoID})
+	}
+	for _, existing := range plan.Existing {
+		planRows = append(planRows, importClonePlanRow{Path: existing.Path, Status: "existing", Detail: "already cloned", RepoID: existing.Entry.RepoID})
 	}
 	for _, skipped := range plan.Skipped {
 		detail := strings.TrimSpace(skipped.Reason)
@@ -234,6 +234,41 @@ var _ = Describe("mergeImportedRegistry", func() {
 				Expect(got).NotTo(BeNil())
 				Expect(got.Path).To(Equal("/local/r1"))
 			})
+
+			It("merge-metadata policy unions labels and annotations and keeps local path and remote", func() {
+				localEntry.RemoteURL = "git@gh/r1-fork.git"
+				localEntry.Labels = map[string]string{"team": "platform", "tier": "dev"}
+				localEntry.Annotations = map[string]string{"owner": "sre"}
+				incomingEntry.Labels = map[string]string{"tier": "prod", "env": "eu"}
+				incomingEntry.Annotations = map[string]string{"pager": "on"}
+				cfg := &config.Config{
+					Registry: &registry.Registry{Entries: []registry.Entry{localEntry}},
+				}
+				bundled := &registry.Registry{Entries: []registry.Entry{incomingEntry}}
+				conflicts := mergeImportedRegistry(cfg, importModeMerge, true, bundled, importConflictPolicyMergeMetadata)
+				got := cfg.Registry.FindByRepoID("r1")
+				Expect(got).NotTo(BeNil())
+				Expect(got.Path).To(Equal("/local/r1"))
+				Expect(got.RemoteURL).To(Equal("git@gh/r1-fork.git"))
+				Expect(got.Labels).To(Equal(map[string]string{"team": "platform", "tier": "prod", "env": "eu"}))
+				Expect(got.Annotations).To(Equal(map[string]string{"owner": "sre", "pager": "on"}))
+				Expect(conflicts).To(Equal([]registry.DedupeConflict{
+					{RepoID: "r1", Field: "labels", Key: "tier", Kept: "prod", Dropped: "dev"},
+				}))
+				Expect(localEntry.Labels).To(HaveKeyWithValue("tier", "dev"), "the local maps are not modified")
+			})
+
+			It("merge-metadata policy reports no conflicts for disjoint keys", func() {
+				localEntry.Labels = map[string]string{"team": "platform"}
+				incomingEntry.Labels = map[string]string{"env": "eu"}
+				cfg := &config.Config{
+					Registry: &registry.Registry{Entries: []registry.Entry{localEntry}},
+				}
+				bundled := &registry.Registry{Entries: []registry.Entry{incomingEntry}}
+				conflicts := mergeImportedRegistry(cfg, importModeMerge, true, bundled, importConflictPolicyMergeMetadata)
+				Expect(conflicts).To(BeEmpty())
+				Expect(cfg.Registry.FindByRepoID("r1").Labels).To(Equal(map[string]string{"team": "platform", "env": "eu"}))
+			})
 		})
 	})
 })
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+const (
+	importDiffAdded   = "added"
+	importDiffUpdated = "updated"
+	importDiffKept    = "kept"
+	importDiffSkipped = "skipped"
+	importDiffRemoved = "removed"
+)
+
+// importDiffRow is one line of the import --dry-run registry diff.
+type importDiffRow struct {
+	Change string
+	RepoID string
+	Path   string
+	Detail string
+}
+
+// diffImportedRegistry compares the local registry before an import with the
+// registry the import would save. Entries are paired with the import matching
+// rules via diffRegistries: new entries are added, changed ones updated, and
+// local entries the result no longer has (replace mode, ignored paths)
+// removed. A bundled entry that left its local match untouched is kept, and
+// one that did not make it into the result at all is skipped.
+func diffImportedRegistry(before, after, bundled *registry.Registry) []importDiffRow {
+	if before == nil {
+		before = &registry.Registry{}
+	}
+	if after == nil {
+		after = &registry.Registry{}
+	}
+	diff := diffRegistries(before, after)
+	rows := make([]importDiffRow, 0, len(diff.OnlyInA)+len(diff.OnlyInB)+len(diff.Changed))
+	for _, entry := range diff.OnlyInB {
+		rows = append(rows, importDiffRow{Change: importDiffAdded, RepoID: entry.RepoID, Path: entry.PathB})
+	}
+	for _, entry := range diff.Changed {
+		rows = append(rows, importDiffRow{Change: importDiffUpdated, RepoID: entry.RepoID, Path: entry.PathB, Detail: strings.Join(entry.Fields, ",")})
+	}
+	for _, entry := range diff.OnlyInA {
+		rows = append(rows, importDiffRow{Change: importDiffRemoved, RepoID: entry.RepoID, Path: entry.PathA})
+	}
+	if bundled != nil {
+		for _, incoming := range bundled.Entries {
+			afterIdx, _ := mergeRegistryMatchIndex(after, incoming)
+			if afterIdx < 0 {
+				rows = append(rows, importDiffRow{Change: importDiffSkipped, RepoID: incoming.RepoID, Path: incoming.Path, Detail: "path is ignored by local config"})
+				continue
+			}
+			beforeIdx, _ := mergeRegistryMatchIndex(before, incoming)
+			if beforeIdx < 0 || registryEntriesConflict(before.Entries[beforeIdx], after.Entries[afterIdx]) {
+				continue
+			}
+			detail := "unchanged local entry"
+			if registryEntriesConflict(before.Entries[beforeIdx], incoming) {
+				detail = "conflict policy keeps local entry"
+			}
+			rows = append(rows, importDiffRow{Change: importDiffKept, RepoID: incoming.RepoID, Path: before.Entries[beforeIdx].Path, Detail: detail})
+		}
+	}
+	order := map[string]int{importDiffAdded: 0, importDiffUpdated: 1, importDiffKept: 2, importDiffSkipped: 3, importDiffRemoved: 4}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Change != rows[j].Change {
+			return order[rows[i].Change] < order[rows[j].Change]
+		}
+		if rows[i].RepoID != rows[j].RepoID {
+			return rows[i].RepoID < rows[j].RepoID
+		}
+		return rows[i].Path < rows[j].Path
+	})
+	return rows
+}
+
+func writeImportDiffTable(cmd *cobra.Command, rows []importDiffRow, cwd string) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), "no registry changes")
+		return err
+	}
+	table := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		table = append(table, []string{row.Change, row.RepoID, dashIfEmpty(displayRepoPath(row.Path, cwd, nil)), dashIfEmpty(row.Detail)})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, false, []string{"CHANGE", "REPO", "PATH", "DETAIL"}, table)
+}
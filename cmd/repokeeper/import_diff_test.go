@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"go.yaml.in/yaml/v3"
+)
+
+func importDiffFixture() (*registry.Registry, *registry.Registry) {
+	local := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/api", CheckoutID: "api", Path: "/src/api", RemoteURL: "git@github.com:org/api.git", Labels: map[string]string{"team": "core"}},
+		{RepoID: "github.com/org/docs", CheckoutID: "docs", Path: "/src/docs", RemoteURL: "git@github.com:org/docs.git"},
+		{RepoID: "github.com/org/local-only", CheckoutID: "local-only", Path: "/src/local-only"},
+	}}
+	bundled := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/api", CheckoutID: "api", Path: "/src/api", RemoteURL: "git@github.com:org/api.git", Labels: map[string]string{"team": "platform"}},
+		{RepoID: "github.com/org/docs", CheckoutID: "docs", Path: "/src/docs", RemoteURL: "git@github.com:org/docs.git"},
+		{RepoID: "github.com/org/web", CheckoutID: "web", Path: "team/web", RemoteURL: "git@github.com:org/web.git"},
+	}}
+	return local, bundled
+}
+
+func importDiffChanges(rows []importDiffRow) []string {
+	changes := make([]string, 0, len(rows))
+	for _, row := range rows {
+		changes = append(changes, row.Change+" "+row.RepoID+" "+row.Detail)
+	}
+	return changes
+}
+
+func TestDiffImportedRegistryClassifiesEntries(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy importConflictPolicy
+		want   []string
+	}{
+		{
+			name:   "bundle policy",
+			policy: importConflictPolicyBundle,
+			want: []string{
+				"added github.com/org/web ",
+				"updated github.com/org/api labels",
+				"kept github.com/org/docs unchanged local entry",
+			},
+		},
+		{
+			name:   "skip policy",
+			policy: importConflictPolicySkip,
+			want: []string{
+				"added github.com/org/web ",
+				"kept github.com/org/api conflict policy keeps local entry",
+				"kept github.com/org/docs unchanged local entry",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			local, bundled := importDiffFixture()
+			cfg := &config.Config{Registry: cloneRegistry(local)}
+			mergeImportedRegistry(cfg, importModeMerge, true, bundled, tc.policy)
+
+			got := importDiffChanges(diffImportedRegistry(local, cfg.Registry, sanitizeImportedRegistry(bundled)))
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("unexpected diff:\n got %q\nwant %q", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("replace mode removes local-only entries", func(t *testing.T) {
+		local, bundled := importDiffFixture()
+		cfg := &config.Config{Registry: cloneRegistry(local)}
+		mergeImportedRegistry(cfg, importModeReplace, true, bundled, importConflictPolicyBundle)
+
+		got := importDiffChanges(diffImportedRegistry(local, cfg.Registry, sanitizeImportedRegistry(bundled)))
+		if !slices.Contains(got, "removed github.com/org/local-only ") {
+			t.Fatalf("expected the local-only entry to be removed, got %q", got)
+		}
+	})
+}
+
+func TestImportCommandRunEDryRunPrintsDiffWithoutSaving(t *testing.T) {
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	local, bundled := importDiffFixture()
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Registry = local
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	data, err := yaml.Marshal(&exportBundle{Version: 1, Config: config.DefaultConfig(), Registry: bundled})
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	importCmd.SetOut(out)
+	importCmd.SetErr(errOut)
+	importCmd.SetIn(bytes.NewReader(data))
+	importCmd.SetContext(context.Background())
+	defer importCmd.SetOut(os.Stdout)
+	defer importCmd.SetErr(os.Stderr)
+	_ = importCmd.Flags().Set("mode", "merge")
+	_ = importCmd.Flags().Set("on-conflict", "bundle")
+	_ = importCmd.Flags().Set("file-only", "false")
+	_ = importCmd.Flags().Set("include-registry", "true")
+	_ = importCmd.Flags().Set("dry-run", "true")
+	defer func() { _ = importCmd.Flags().Set("dry-run", "false") }()
+
+	if err := importCmd.RunE(importCmd, []string{"-"}); err != nil {
+		t.Fatalf("import --dry-run: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"CHANGE", "added", "github.com/org/web", "updated", "labels", "kept", "unchanged local entry"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in dry-run diff, got %q", want, got)
+		}
+	}
+	if strings.Contains(errOut.String(), "Planned import clone operations:") {
+		t.Fatalf("expected no clone planning in dry-run, got %q", errOut.String())
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatalf("expected dry-run to leave the config untouched")
+	}
+}
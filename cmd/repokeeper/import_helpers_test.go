@@ -30,7 +30,7 @@ func cloneImportedEntriesWithProgress(
 	entries []registry.Entry,
 	progress *syncProgressWriter,
 ) ([]engine.SyncResult, error) {
-	plan, err := planImportedEntries(cfg, bundle, cwd, dangerouslyDeleteExisting, entries)
+	plan, err := planImportedEntries(cmd.Context(), cfg, bundle, cwd, dangerouslyDeleteExisting, 0, entries)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+// repoListEntry is one clone URL from a --repos-file list.
+type repoListEntry struct {
+	Line      int
+	RemoteURL string
+}
+
+// parseRepoList reads newline-separated clone URLs. Blank lines and lines
+// starting with # are ignored; surrounding whitespace is trimmed.
+func parseRepoList(r io.Reader) ([]repoListEntry, error) {
+	var entries []repoListEntry
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.ContainsAny(line, " \t") {
+			return nil, fmt.Errorf("line %d: expected a single clone URL, got %q", lineNo, line)
+		}
+		entries = append(entries, repoListEntry{Line: lineNo, RemoteURL: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// repoListRegistryEntries turns the list into checkout entries whose relative
+// Path is the normalized repo ID (host/owner/repo). A URL repeated in the list
+// is cloned once; one already in reg is reported in skips instead.
+func repoListRegistryEntries(list []repoListEntry, reg *registry.Registry, cwd string) ([]registry.Entry, []importClonePlanRow, error) {
+	seen := make(map[string]bool, len(list))
+	var entries []registry.Entry
+	var skips []importClonePlanRow
+	for _, item := range list {
+		repoID := gitx.NormalizeURL(item.RemoteURL)
+		rel, ok := cleanRelativePath(repoID)
+		if !ok {
+			return nil, nil, fmt.Errorf("line %d: cannot derive a target path from %q", item.Line, item.RemoteURL)
+		}
+		if seen[repoID] {
+			continue
+		}
+		seen[repoID] = true
+		if reg != nil && len(reg.FindEntriesByRepoID(repoID)) > 0 {
+			skips = append(skips, importClonePlanRow{Path: resolveAbsoluteTargetPath(cwd, rel), Status: "skip", Detail: "already registered", RepoID: repoID})
+			continue
+		}
+		entries = append(entries, registry.Entry{
+			RepoID:    repoID,
+			Path:      rel,
+			RemoteURL: item.RemoteURL,
+			Type:      "checkout",
+			Status:    registry.StatusPresent,
+		})
+	}
+	return entries, skips, nil
+}
+
+// runImportReposFile clones every URL in path ("-" reads stdin) under the
+// current directory and registers the clones. It shares the bundle import's
+// planning, so targets escaping cwd, colliding targets, and existing paths are
+// rejected the same way.
+func runImportReposFile(cmd *cobra.Command, path string, dryRun, dangerouslyDeleteExisting bool, depth int) error {
+	var data io.Reader = cmd.InOrStdin()
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		data = file
+	}
+	list, err := parseRepoList(data)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("no clone URLs found in %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	cfgPath, err := config.InitConfigPath(configOverride(cmd), cwd)
+	if err != nil {
+		return err
+	}
+	cfg, hasExistingCfg, err := loadExistingConfig(cfgPath)
+	if err != nil {
+		return err
+	}
+	if !hasExistingCfg {
+		cfg = config.DefaultConfig()
+	}
+	if cfg.Registry == nil {
+		cfg.Registry = &registry.Registry{}
+	}
+	gitx.SetHostAliases(cfg.HostAliases)
+
+	entries, skips, err := repoListRegistryEntries(list, cfg.Registry, cwd)
+	if err != nil {
+		return err
+	}
+	var plan engine.ImportClonePlan
+	if len(entries) > 0 {
+		eng := engine.New(&cfg, cfg.Registry, vcs.NewGitAdapter(nil), vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
+		plan, err = eng.PlanImportClones(cmd.Context(), entries, engine.ImportCloneOptions{
+			CWD:                       cwd,
+			DangerouslyDeleteExisting: dangerouslyDeleteExisting,
+			AllowDefaultBranch:        true,
+			Depth:                     depth,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := writeImportClonePlan(cmd, plan, skips, cwd); err != nil {
+		return err
+	}
+	if dryRun || len(plan.Clones)+len(plan.Existing) == 0 {
+		if len(plan.Clones)+len(plan.Existing) == 0 {
+			infof(cmd, "no repos to clone")
+		}
+		return nil
+	}
+
+	if len(plan.Clones) > 0 && !assumeYes(cmd) {
+		confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Clone %d repos? [y/N]: ", len(plan.Clones)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			infof(cmd, "import cancelled")
+			return nil
+		}
+	}
+	progress := newSyncProgressWriter(cmd, cwd, nil)
+	failures, err := executeImportClonePlanWithProgress(cmd, &cfg, plan, progress)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		raiseExitCode(cmd, 2)
+		if err := writeImportCloneFailureSummary(cmd, failures, cwd); err != nil {
+			return err
+		}
+		infof(cmd, "import clone completed with %d failures", len(failures))
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		return err
+	}
+	infof(cmd, "imported %d repos to %s", len(plan.Clones)+len(plan.Existing)-len(failures), cfgPath)
+	return nil
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestParseRepoListSkipsCommentsAndBlankLines(t *testing.T) {
+	t.Parallel()
+
+	list, err := parseRepoList(strings.NewReader("# team repos\n\n  git@github.com:org/a.git  \n\t\nhttps://github.com/org/b\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(list) != 2 || list[0].RemoteURL != "git@github.com:org/a.git" || list[0].Line != 3 || list[1].Line != 5 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	if _, err := parseRepoList(strings.NewReader("git@github.com:org/a.git main\n")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected a line-numbered error for extra fields, got %v", err)
+	}
+}
+
+func TestRepoListRegistryEntriesUsesHostOwnerRepoLayout(t *testing.T) {
+	t.Parallel()
+
+	reg := &registry.Registry{Entries: []registry.Entry{{RepoID: "github.com/org/known", Path: "/elsewhere/known"}}}
+	entries, skips, err := repoListRegistryEntries([]repoListEntry{
+		{Line: 1, RemoteURL: "git@github.com:org/api.git"},
+		{Line: 2, RemoteURL: "https://github.com/org/api"},
+		{Line: 3, RemoteURL: "https://github.com/org/known.git"},
+	}, reg, "/work")
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "github.com/org/api" || entries[0].RemoteURL != "git@github.com:org/api.git" {
+		t.Fatalf("expected one deduplicated entry, got %+v", entries)
+	}
+	if len(skips) != 1 || skips[0].Detail != "already registered" || skips[0].Path != filepath.Clean("/work/github.com/org/known") {
+		t.Fatalf("expected the registered repo to be skipped, got %+v", skips)
+	}
+	if _, _, err := repoListRegistryEntries([]repoListEntry{{Line: 4, RemoteURL: "https://github.com/../../etc"}}, nil, "/work"); err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Fatalf("expected an escaping target to be rejected, got %v", err)
+	}
+}
+
+func TestImportCommandReposFileClonesAndRegisters(t *testing.T) {
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+	cwd := filepath.Dir(cfgPath)
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	seed := filepath.Join(t.TempDir(), "seed")
+	mustRunGit(t, filepath.Dir(remote), "init", "-q", "--bare", remote)
+	mustRunGit(t, filepath.Dir(seed), "clone", "-q", remote, seed)
+	if err := os.WriteFile(filepath.Join(seed, "file.txt"), []byte("base\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustRunGit(t, seed, "add", "file.txt")
+	mustRunGit(t, seed, "commit", "-q", "-m", "base")
+	mustRunGit(t, seed, "push", "-q", "origin", "HEAD")
+
+	remoteURL := "file://" + filepath.ToSlash(remote)
+	listPath := filepath.Join(t.TempDir(), "repos.txt")
+	if err := os.WriteFile(listPath, []byte("# local remote\n"+remoteURL+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(cwd, strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(remote), "/"), ".git"))
+
+	errOut := &bytes.Buffer{}
+	importCmd.SetErr(errOut)
+	importCmd.SetContext(context.Background())
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	_ = importCmd.Flags().Set("repos-file", listPath)
+	defer func() {
+		importCmd.SetErr(os.Stderr)
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = importCmd.Flags().Set("repos-file", "")
+		_ = importCmd.Flags().Set("dry-run", "false")
+	}()
+
+	_ = importCmd.Flags().Set("dry-run", "true")
+	if err := importCmd.RunE(importCmd, nil); err != nil {
+		t.Fatalf("import --repos-file --dry-run: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "Planned import clone operations:") || !strings.Contains(errOut.String(), "clone") {
+		t.Fatalf("expected a clone plan, got %q", errOut.String())
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("dry run must not clone, stat err=%v", err)
+	}
+
+	_ = importCmd.Flags().Set("dry-run", "false")
+	if err := importCmd.RunE(importCmd, nil); err != nil {
+		t.Fatalf("import --repos-file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(target, "file.txt")); err != nil {
+		t.Fatalf("expected clone at %s: %v", target, err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if len(cfg.Registry.Entries) != 1 || cfg.Registry.Entries[0].Path != target || cfg.Registry.Entries[0].Status != registry.StatusPresent {
+		t.Fatalf("expected the clone to be registered, got %+v", cfg.Registry.Entries)
+	}
+
+	// A second run finds the repo registered and clones nothing.
+	errOut.Reset()
+	if err := importCmd.RunE(importCmd, nil); err != nil {
+		t.Fatalf("second import --repos-file: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "already registered") {
+		t.Fatalf("expected the repo to be skipped as registered, got %q", errOut.String())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -755,6 +756,94 @@ func TestImportCommandRunEMergeIncludeRegistryFalseDoesNotCloneOrRegisterBundleR
 	}
 }
 
+func TestImportCommandRegistryOnlyBundleKeepsLocalConfig(t *testing.T) {
+	bundleYAML := []byte(`version: 2
+registry:
+  repos:
+    - repo_id: github.com/org/bundled
+      path: /source/root/bundled
+      status: missing
+`)
+	for _, tc := range []struct {
+		mode        string
+		wantRepoIDs []string
+		wantWarning bool
+	}{
+		{mode: "merge", wantRepoIDs: []string{"github.com/org/bundled", "github.com/org/local"}},
+		{mode: "replace", wantRepoIDs: []string{"github.com/org/bundled"}, wantWarning: true},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			tmp := t.TempDir()
+			cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+			local := config.DefaultConfig()
+			local.Exclude = []string{"**/scratch/**"}
+			local.Defaults.Concurrency = 3
+			local.Registry = &registry.Registry{Entries: []registry.Entry{
+				{RepoID: "github.com/org/local", Path: filepath.Join(tmp, "local"), Status: registry.StatusPresent},
+			}}
+			if err := config.Save(&local, cfgPath); err != nil {
+				t.Fatalf("save config: %v", err)
+			}
+			cleanup := withConfigAndCWD(t, cfgPath)
+			defer cleanup()
+
+			errOut := &bytes.Buffer{}
+			importCmd.SetErr(errOut)
+			defer importCmd.SetErr(os.Stderr)
+			importCmd.SetIn(bytes.NewReader(bundleYAML))
+			importCmd.SetContext(context.Background())
+			prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+			_ = rootCmd.PersistentFlags().Set("yes", "true")
+			defer func() { _ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes)) }()
+			_ = importCmd.Flags().Set("mode", tc.mode)
+			_ = importCmd.Flags().Set("force", "true")
+			_ = importCmd.Flags().Set("file-only", "false")
+			_ = importCmd.Flags().Set("include-registry", "true")
+			defer func() {
+				_ = importCmd.Flags().Set("mode", "merge")
+				_ = importCmd.Flags().Set("force", "false")
+			}()
+
+			if err := importCmd.RunE(importCmd, []string{"-"}); err != nil {
+				t.Fatalf("import: %v", err)
+			}
+
+			cfg, err := config.Load(cfgPath)
+			if err != nil {
+				t.Fatalf("reload config: %v", err)
+			}
+			if cfg.Defaults.Concurrency != 3 || len(cfg.Exclude) != 1 || cfg.Exclude[0] != "**/scratch/**" {
+				t.Fatalf("expected local config settings to survive, got concurrency=%d exclude=%v", cfg.Defaults.Concurrency, cfg.Exclude)
+			}
+			var got []string
+			for _, entry := range cfg.Registry.Entries {
+				got = append(got, entry.RepoID)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tc.wantRepoIDs, ",") {
+				t.Fatalf("expected registry %v, got %v", tc.wantRepoIDs, got)
+			}
+			if hasWarning := strings.Contains(errOut.String(), "bundle has no config section"); hasWarning != tc.wantWarning {
+				t.Fatalf("expected warning=%v, got stderr %q", tc.wantWarning, errOut.String())
+			}
+		})
+	}
+}
+
+func TestBundleHasConfigSection(t *testing.T) {
+	for raw, want := range map[string]bool{
+		"version: 2\n":                         false,
+		"version: 2\nconfig:\n":                false,
+		"version: 2\nconfig: {}\n":             false,
+		"version: 2\nconfig:\n  exclude: []\n": true,
+	} {
+		got, err := bundleHasConfigSection([]byte(raw))
+		if err != nil || got != want {
+			t.Fatalf("bundleHasConfigSection(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+}
+
 func TestImportCommandDefaultsToLocalConfigPath(t *testing.T) {
 	tmp := t.TempDir()
 	origWD, err := os.Getwd()
@@ -994,3 +1083,64 @@ func TestSelectMergeCloneEntriesPolicy(t *testing.T) {
 		t.Fatalf("expected bundle policy to clone new+conflicted repos, got %+v", bundlePolicy)
 	}
 }
+
+func TestImportCommandRunEMergeMetadataStrictRejectsKeyConflicts(t *testing.T) {
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	local := registry.Entry{RepoID: "github.com/org/repo-a", Path: "/local/repo-a", Status: registry.StatusPresent, Labels: map[string]string{"tier": "dev"}}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{local}}
+	if err := config.Save(cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	incoming := local
+	incoming.Path = "/source/root/repo-a"
+	incoming.Labels = map[string]string{"tier": "prod"}
+	data, err := yaml.Marshal(&exportBundle{Version: 1, Config: config.DefaultConfig(), Registry: &registry.Registry{Entries: []registry.Entry{incoming}}})
+	if err != nil {
+		t.Fatalf("marshal bundle: %v", err)
+	}
+
+	errOut := &bytes.Buffer{}
+	importCmd.SetErr(errOut)
+	defer importCmd.SetErr(os.Stderr)
+	importCmd.SetContext(context.Background())
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	defer func() { _ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes)) }()
+	_ = importCmd.Flags().Set("mode", "merge")
+	_ = importCmd.Flags().Set("file-only", "false")
+	_ = importCmd.Flags().Set("include-registry", "true")
+	_ = importCmd.Flags().Set("strict", "true")
+	defer func() {
+		_ = importCmd.Flags().Set("strict", "false")
+		_ = importCmd.Flags().Set("on-conflict", "bundle")
+	}()
+
+	_ = importCmd.Flags().Set("on-conflict", "bundle")
+	importCmd.SetIn(bytes.NewReader(data))
+	if err := importCmd.RunE(importCmd, []string{"-"}); err == nil || !strings.Contains(err.Error(), "--strict requires --on-conflict merge-metadata") {
+		t.Fatalf("expected --strict to require merge-metadata, got %v", err)
+	}
+
+	_ = importCmd.Flags().Set("on-conflict", "merge-metadata")
+	importCmd.SetIn(bytes.NewReader(data))
+	if err := importCmd.RunE(importCmd, []string{"-"}); err == nil || !strings.Contains(err.Error(), "1 label or annotation conflicts") {
+		t.Fatalf("expected the tier conflict to fail the import, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), `labels "tier" is "prod" in the bundle, replacing local "dev"`) {
+		t.Fatalf("expected the conflicting key to be reported, got %q", errOut.String())
+	}
+	reloaded, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if got := reloaded.Registry.FindByRepoID("github.com/org/repo-a"); got == nil || got.Labels["tier"] != "dev" {
+		t.Fatalf("expected the registry to be left unchanged, got %+v", reloaded.Registry.Entries)
+	}
+}
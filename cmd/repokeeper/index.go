@@ -43,7 +43,7 @@ Pass --write to save the proposal. Overwriting an existing file requires
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -148,7 +148,7 @@ Pass --write to save the proposals. Overwriting existing files requires
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -181,7 +181,7 @@ Pass --write to save the proposals. Overwriting existing files requires
 		if err != nil {
 			return err
 		}
-		selected = filterBulkIndexEntriesByLabels(selected, labelSelector, localLabelSelector)
+		selected = filterRegistryEntriesByLabels(selected, labelSelector, localLabelSelector)
 		if len(selected) == 0 {
 			return fmt.Errorf("no repositories matched the supplied selectors")
 		}
@@ -295,7 +295,7 @@ func selectIndexBulkEntries(cmd *cobra.Command, cfg *config.Config) ([]registry.
 	return entries, nil
 }
 
-func filterBulkIndexEntriesByLabels(entries []registry.Entry, sharedReqs, localReqs []selector.LabelRequirement) []registry.Entry {
+func filterRegistryEntriesByLabels(entries []registry.Entry, sharedReqs, localReqs []selector.LabelRequirement) []registry.Entry {
 	if len(sharedReqs) == 0 && len(localReqs) == 0 {
 		return entries
 	}
@@ -1,7 +1,11 @@
 // SPDX-License-Identifier: MIT
 package repokeeper
 
-import "github.com/spf13/cobra"
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
 
 var getCmd = &cobra.Command{
 	Use:   "get",
@@ -45,31 +49,67 @@ var repairUpstreamAliasCmd = &cobra.Command{
 func init() {
 	getCmd.Flags().String("roots", "", "additional roots to scan (optional)")
 	getCmd.Flags().String("registry", "", "override registry file path")
-	addFormatFlag(getCmd, "output format: table, wide, or json")
+	addFormatFlag(getCmd, statusFormatUsage)
+	getCmd.Flags().String("template", "", templateUsage)
 	addRepoFilterFlags(getCmd)
 	addLabelSelectorFlag(getCmd)
 	getCmd.Flags().String("local-selector", "", "filter repos by machine-local labels (key or key=value, comma-separated)")
-	getCmd.Flags().String("reconcile-remote-mismatch", "none", "optional reconcile mode for remote mismatch: none, registry, git")
+	getCmd.Flags().String("reconcile-remote-mismatch", "none", remoteReconcileUsage)
 	getCmd.Flags().Bool("dry-run", true, "preview reconcile actions without modifying registry or git remotes")
 	addNoHeadersFlag(getCmd)
 	getCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
+	getCmd.Flags().Bool("verify-ignored", false, verifyIgnoredUsage)
+	getCmd.Flags().Bool("fail-fast", false, failFastUsage)
+	getCmd.Flags().Bool("since-scan", false, sinceScanUsage)
+	getCmd.Flags().Bool("full", false, fullUsage)
+	getCmd.Flags().String("older-than", "", olderThanUsage)
+	getCmd.Flags().String("newer-than", "", newerThanUsage)
+	getCmd.Flags().Int("min-ahead", 0, minAheadUsage)
+	getCmd.Flags().Int("min-behind", 0, minBehindUsage)
+	getCmd.Flags().Bool("explain", false, explainUsage)
+	getCmd.Flags().Bool("severity", false, severityUsage)
+	getCmd.Flags().Int("threshold", 1, behindThresholdUsage)
+	getCmd.Flags().String("group-by", "", groupByUsage)
+	getCmd.Flags().Bool("count-only", false, countOnlyUsage)
+	getCmd.Flags().Duration("watch", 0, watchUsage)
+	getCmd.Flags().String("sort", "", statusSortUsage)
+	addNameOnlyFlags(getCmd)
 	addVCSFlag(getCmd)
 
 	getReposCmd.Flags().String("roots", "", "additional roots to scan (optional)")
 	getReposCmd.Flags().String("registry", "", "override registry file path")
-	addFormatFlag(getReposCmd, "output format: table, wide, or json")
+	addFormatFlag(getReposCmd, statusFormatUsage)
+	getReposCmd.Flags().String("template", "", templateUsage)
 	addRepoFilterFlags(getReposCmd)
 	addLabelSelectorFlag(getReposCmd)
 	getReposCmd.Flags().String("local-selector", "", "filter repos by machine-local labels (key or key=value, comma-separated)")
-	getReposCmd.Flags().String("reconcile-remote-mismatch", "none", "optional reconcile mode for remote mismatch: none, registry, git")
+	getReposCmd.Flags().String("reconcile-remote-mismatch", "none", remoteReconcileUsage)
 	getReposCmd.Flags().Bool("dry-run", true, "preview reconcile actions without modifying registry or git remotes")
 	addNoHeadersFlag(getReposCmd)
 	getReposCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
+	getReposCmd.Flags().Bool("verify-ignored", false, verifyIgnoredUsage)
+	getReposCmd.Flags().Bool("fail-fast", false, failFastUsage)
+	getReposCmd.Flags().Bool("since-scan", false, sinceScanUsage)
+	getReposCmd.Flags().Bool("full", false, fullUsage)
+	getReposCmd.Flags().String("older-than", "", olderThanUsage)
+	getReposCmd.Flags().String("newer-than", "", newerThanUsage)
+	getReposCmd.Flags().Int("min-ahead", 0, minAheadUsage)
+	getReposCmd.Flags().Int("min-behind", 0, minBehindUsage)
+	getReposCmd.Flags().Bool("explain", false, explainUsage)
+	getReposCmd.Flags().Bool("severity", false, severityUsage)
+	getReposCmd.Flags().Int("threshold", 1, behindThresholdUsage)
+	getReposCmd.Flags().String("group-by", "", groupByUsage)
+	getReposCmd.Flags().Bool("count-only", false, countOnlyUsage)
+	getReposCmd.Flags().Duration("watch", 0, watchUsage)
+	getReposCmd.Flags().String("sort", "", statusSortUsage)
+	addNameOnlyFlags(getReposCmd)
 	addVCSFlag(getReposCmd)
 	getCmd.AddCommand(getReposCmd)
 
 	addRepoFilterFlags(reconcileCmd)
 	reconcileCmd.Flags().Int("concurrency", 0, "max concurrent repo operations (default: min(8, NumCPU))")
+	reconcileCmd.Flags().Int("concurrency-per-host", 0, concurrencyPerHostUsage)
+	reconcileCmd.Flags().Bool("allow-oversubscribe", false, allowOversubscribeUsage)
 	reconcileCmd.Flags().Int("timeout", 0, "timeout in seconds per repo (0 uses config default)")
 	reconcileCmd.Flags().Bool("continue-on-error", true, "continue syncing remaining repos after a per-repo failure")
 	reconcileCmd.Flags().Bool("dry-run", false, "print intended operations without executing")
@@ -77,17 +117,44 @@ func init() {
 	reconcileCmd.Flags().Bool("update-local", false, "after fetch, run pull --rebase for the checked-out tracking branch when safe")
 	reconcileCmd.Flags().Bool("push-local", false, "when used with --update-local, push branches that are ahead of upstream")
 	reconcileCmd.Flags().Bool("rebase-dirty", false, "when used with --update-local, stash local changes before rebase and pop afterwards")
-	reconcileCmd.Flags().Bool("force", false, "when used with --update-local, allow rebase even when branch tracking state is diverged")
+	reconcileCmd.Flags().Bool("force", false, syncForceUsage)
 	reconcileCmd.Flags().String("protected-branches", "", "comma-separated branch patterns to protect from auto-rebase during --update-local (default: none)")
 	reconcileCmd.Flags().Bool("allow-protected-rebase", false, "when used with --update-local, allow rebase on branches matched by --protected-branches")
 	reconcileCmd.Flags().Bool("checkout-missing", false, "clone missing repos from registry remote_url back to their registered paths")
-	addFormatFlag(reconcileCmd, "output format: table, wide, or json")
+	reconcileCmd.Flags().String("remote-template", "", remoteTemplateUsage)
+	reconcileCmd.Flags().Int("depth", 0, cloneDepthUsage)
+	reconcileCmd.Flags().Int("deepen", 0, deepenUsage)
+	reconcileCmd.Flags().String("remote", "", fetchRemoteUsage)
+	reconcileCmd.Flags().Bool("no-prune-tags", false, noPruneTagsUsage)
+	reconcileCmd.Flags().String("backup-branch", "", backupBranchUsage)
+	reconcileCmd.Flags().Bool("prune-empty-dirs", false, pruneEmptyDirsUsage)
+	reconcileCmd.Flags().Bool("delete-gone-branches", false, deleteGoneBranchesUsage)
+	reconcileCmd.Flags().Bool("autostash-all", false, autostashAllUsage)
+	reconcileCmd.Flags().Bool("reset-hard", false, resetHardUsage)
+	reconcileCmd.Flags().Bool("lfs", false, lfsUsage)
+	reconcileCmd.Flags().Bool("update-submodules", false, updateSubmodulesUsage)
+	reconcileCmd.Flags().String("sort-by", "", syncSortByUsage)
+	reconcileCmd.Flags().String("report", "", syncReportUsage)
+	reconcileCmd.Flags().String("log-dir", "", syncLogDirUsage)
+	addNameOnlyFlags(reconcileCmd)
+	reconcileCmd.Flags().String("pre-run-command", "", preRunCommandUsage)
+	reconcileCmd.Flags().Bool("strict-hooks", false, strictHooksUsage)
+	reconcileCmd.Flags().Bool("summary", false, syncSummaryUsage)
+	reconcileCmd.Flags().Int("retries", 0, retriesUsage)
+	reconcileCmd.Flags().Duration("retry-backoff", time.Second, retryBackoffUsage)
+	reconcileCmd.Flags().Bool("plan-only", false, planOnlyUsage)
+	reconcileCmd.Flags().String("output", "", planOutputUsage)
+	reconcileCmd.Flags().Bool("from-last-run", false, fromLastRunUsage)
+	addFormatFlag(reconcileCmd, syncFormatUsage)
+	reconcileCmd.Flags().String("template", "", templateUsage)
 	addNoHeadersFlag(reconcileCmd)
 	reconcileCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
 	addVCSFlag(reconcileCmd)
 
 	addRepoFilterFlags(reconcileReposCmd)
 	reconcileReposCmd.Flags().Int("concurrency", 0, "max concurrent repo operations (default: min(8, NumCPU))")
+	reconcileReposCmd.Flags().Int("concurrency-per-host", 0, concurrencyPerHostUsage)
+	reconcileReposCmd.Flags().Bool("allow-oversubscribe", false, allowOversubscribeUsage)
 	reconcileReposCmd.Flags().Int("timeout", 0, "timeout in seconds per repo (0 uses config default)")
 	reconcileReposCmd.Flags().Bool("continue-on-error", true, "continue syncing remaining repos after a per-repo failure")
 	reconcileReposCmd.Flags().Bool("dry-run", false, "print intended operations without executing")
@@ -95,11 +162,36 @@ func init() {
 	reconcileReposCmd.Flags().Bool("update-local", false, "after fetch, run pull --rebase for the checked-out tracking branch when safe")
 	reconcileReposCmd.Flags().Bool("push-local", false, "when used with --update-local, push branches that are ahead of upstream")
 	reconcileReposCmd.Flags().Bool("rebase-dirty", false, "when used with --update-local, stash local changes before rebase and pop afterwards")
-	reconcileReposCmd.Flags().Bool("force", false, "when used with --update-local, allow rebase even when branch tracking state is diverged")
+	reconcileReposCmd.Flags().Bool("force", false, syncForceUsage)
 	reconcileReposCmd.Flags().String("protected-branches", "", "comma-separated branch patterns to protect from auto-rebase during --update-local (default: none)")
 	reconcileReposCmd.Flags().Bool("allow-protected-rebase", false, "when used with --update-local, allow rebase on branches matched by --protected-branches")
 	reconcileReposCmd.Flags().Bool("checkout-missing", false, "clone missing repos from registry remote_url back to their registered paths")
-	addFormatFlag(reconcileReposCmd, "output format: table, wide, or json")
+	reconcileReposCmd.Flags().String("remote-template", "", remoteTemplateUsage)
+	reconcileReposCmd.Flags().Int("depth", 0, cloneDepthUsage)
+	reconcileReposCmd.Flags().Int("deepen", 0, deepenUsage)
+	reconcileReposCmd.Flags().String("remote", "", fetchRemoteUsage)
+	reconcileReposCmd.Flags().Bool("no-prune-tags", false, noPruneTagsUsage)
+	reconcileReposCmd.Flags().String("backup-branch", "", backupBranchUsage)
+	reconcileReposCmd.Flags().Bool("prune-empty-dirs", false, pruneEmptyDirsUsage)
+	reconcileReposCmd.Flags().Bool("delete-gone-branches", false, deleteGoneBranchesUsage)
+	reconcileReposCmd.Flags().Bool("autostash-all", false, autostashAllUsage)
+	reconcileReposCmd.Flags().Bool("reset-hard", false, resetHardUsage)
+	reconcileReposCmd.Flags().Bool("lfs", false, lfsUsage)
+	reconcileReposCmd.Flags().Bool("update-submodules", false, updateSubmodulesUsage)
+	reconcileReposCmd.Flags().String("sort-by", "", syncSortByUsage)
+	reconcileReposCmd.Flags().String("report", "", syncReportUsage)
+	reconcileReposCmd.Flags().String("log-dir", "", syncLogDirUsage)
+	addNameOnlyFlags(reconcileReposCmd)
+	reconcileReposCmd.Flags().String("pre-run-command", "", preRunCommandUsage)
+	reconcileReposCmd.Flags().Bool("strict-hooks", false, strictHooksUsage)
+	reconcileReposCmd.Flags().Bool("summary", false, syncSummaryUsage)
+	reconcileReposCmd.Flags().Int("retries", 0, retriesUsage)
+	reconcileReposCmd.Flags().Duration("retry-backoff", time.Second, retryBackoffUsage)
+	reconcileReposCmd.Flags().Bool("plan-only", false, planOnlyUsage)
+	reconcileReposCmd.Flags().String("output", "", planOutputUsage)
+	reconcileReposCmd.Flags().Bool("from-last-run", false, fromLastRunUsage)
+	addFormatFlag(reconcileReposCmd, syncFormatUsage)
+	reconcileReposCmd.Flags().String("template", "", templateUsage)
 	addNoHeadersFlag(reconcileReposCmd)
 	reconcileReposCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
 	addVCSFlag(reconcileReposCmd)
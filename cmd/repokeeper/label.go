@@ -4,6 +4,7 @@ package repokeeper
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"sort"
 	"strings"
@@ -15,7 +16,7 @@ import (
 )
 
 var labelCmd = &cobra.Command{
-	Use:   "label <repo-id-or-path>",
+	Use:   "label <repo-id-or-path | pattern>",
 	Short: "View or update labels for a tracked repository",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -27,7 +28,7 @@ var labelCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -47,15 +48,6 @@ var labelCmd = &cobra.Command{
 			}
 		}
 
-		entry, err := selectRegistryEntryForDescribe(reg.Entries, args[0], cwd, []string{cfgRoot})
-		if err != nil {
-			return err
-		}
-		idx := findRegistryEntryIndex(reg.Entries, entry)
-		if idx < 0 {
-			return fmt.Errorf("entry not found for selector %q", args[0])
-		}
-
 		setInputs, _ := cmd.Flags().GetStringArray("set")
 		removeInputs, _ := cmd.Flags().GetStringArray("remove")
 		setValues, err := parseMetadataAssignments(setInputs, "--set")
@@ -66,19 +58,50 @@ var labelCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		format, _ := cmd.Flags().GetString("format")
 
-		if len(setValues) > 0 || len(removeKeys) > 0 {
-			entry.Labels = cloneMetadataMap(entry.Labels)
-			if entry.Labels == nil {
-				entry.Labels = make(map[string]string)
+		if matchMode, _ := cmd.Flags().GetString("match"); strings.TrimSpace(matchMode) != "" {
+			if len(setValues) == 0 && len(removeKeys) == 0 {
+				return fmt.Errorf("--match requires --set or --remove")
+			}
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format != "" && format != "table" && format != "json" {
+				return fmt.Errorf("unsupported format %q", format)
+			}
+			result, applied, err := applyBulkMetadataChange(cmd, reg, matchMode, args[0], "labels", func(entry *registry.Entry) bool {
+				updated := applyMetadataChanges(entry.Labels, setValues, removeKeys)
+				if maps.Equal(updated, entry.Labels) {
+					return false
+				}
+				entry.Labels = updated
+				return true
+			})
+			if err != nil {
+				return err
 			}
-			for key, value := range setValues {
-				entry.Labels[key] = value
+			if !applied {
+				infof(cmd, "label cancelled")
+				return nil
 			}
-			for _, key := range removeKeys {
-				delete(entry.Labels, key)
+			if result.Updated > 0 {
+				if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, reg); err != nil {
+					return err
+				}
 			}
-			entry.Labels = normalizeMetadataMap(entry.Labels)
+			return writeBulkMetadataResult(cmd, result, "labeled", format)
+		}
+
+		entry, err := selectRegistryEntryForDescribe(reg.Entries, args[0], cwd, []string{cfgRoot})
+		if err != nil {
+			return err
+		}
+		idx := findRegistryEntryIndex(reg.Entries, entry)
+		if idx < 0 {
+			return fmt.Errorf("entry not found for selector %q", args[0])
+		}
+
+		if len(setValues) > 0 || len(removeKeys) > 0 {
+			entry.Labels = applyMetadataChanges(entry.Labels, setValues, removeKeys)
 			entry.LastSeen = time.Now()
 			reg.Entries[idx] = entry
 			reg.UpdatedAt = time.Now()
@@ -95,7 +118,6 @@ var labelCmd = &cobra.Command{
 			}
 		}
 
-		format, _ := cmd.Flags().GetString("format")
 		switch strings.ToLower(strings.TrimSpace(format)) {
 		case "json":
 			payload := struct {
@@ -150,6 +172,7 @@ var labelCmd = &cobra.Command{
 
 func init() {
 	labelCmd.Flags().String("registry", "", "override registry file path")
+	labelCmd.Flags().String("match", "", matchUsage)
 	labelCmd.Flags().StringArray("set", nil, "set label key=value (repeatable)")
 	labelCmd.Flags().StringArray("remove", nil, "remove label key (repeatable)")
 	labelCmd.Flags().StringP("format", "o", "table", "output format: table or json")
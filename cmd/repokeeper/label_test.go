@@ -75,7 +75,7 @@ func TestLabelCommandSetAndRemove(t *testing.T) {
 	labelCmd.SetOut(out)
 	_ = labelCmd.Flags().Set("registry", "")
 	_ = labelCmd.Flags().Set("format", "json")
-	_ = labelCmd.Flags().Set("set", "owner=sre")
+	_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace([]string{"owner=sre"})
 	_ = labelCmd.Flags().Set("remove", "env")
 	defer labelCmd.SetOut(os.Stdout)
 	if err := labelCmd.RunE(labelCmd, []string{"github.com/org/repo-a"}); err != nil {
@@ -154,7 +154,7 @@ func TestLabelCommandUpdatesLocalLabelsOnly(t *testing.T) {
 	labelCmd.SetContext(context.Background())
 	_ = labelCmd.Flags().Set("registry", "")
 	_ = labelCmd.Flags().Set("format", "json")
-	_ = labelCmd.Flags().Set("set", "owner=sre")
+	_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace([]string{"owner=sre"})
 	_ = labelCmd.Flags().Set("remove", "env")
 	if err := labelCmd.RunE(labelCmd, []string{"github.com/org/repo-a"}); err != nil {
 		t.Fatalf("label command failed: %v", err)
@@ -233,3 +233,128 @@ func TestLabelCommandAcceptsAbsolutePathSelector(t *testing.T) {
 		t.Fatalf("expected team label after absolute path lookup, got %q", got)
 	}
 }
+
+func TestLabelCommandMatchUpdatesEveryMatchingRepo(t *testing.T) {
+	tmp := t.TempDir()
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{}
+	for _, id := range []string{"github.com/org/api", "github.com/org/web", "github.com/other/api", "gitlab.com/org/tools"} {
+		cfg.Registry.Entries = append(cfg.Registry.Entries, registry.Entry{RepoID: id, Path: filepath.Join(tmp, filepath.Base(id)+"-"+filepath.Base(filepath.Dir(id))), Status: registry.StatusPresent, LastSeen: time.Now()})
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	labelCmd.SetContext(context.Background())
+	_ = labelCmd.Flags().Set("registry", "")
+	_ = labelCmd.Flags().Set("format", "table")
+	// --set is a StringArray, so Replace rather than append to earlier values.
+	_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace([]string{"team=core"})
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = labelCmd.Flags().Set("match", "")
+		_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace(nil)
+		labelCmd.SetOut(os.Stdout)
+	}()
+
+	run := func(mode, pattern string) (string, error) {
+		out := &bytes.Buffer{}
+		labelCmd.SetOut(out)
+		_ = labelCmd.Flags().Set("match", mode)
+		err := labelCmd.RunE(labelCmd, []string{pattern})
+		return out.String(), err
+	}
+	labeled := func() []string {
+		loaded, err := config.Load(cfgPath)
+		if err != nil {
+			t.Fatalf("reload config: %v", err)
+		}
+		var ids []string
+		for _, entry := range loaded.Registry.Entries {
+			if entry.Labels["team"] == "core" {
+				ids = append(ids, entry.RepoID)
+			}
+		}
+		return ids
+	}
+
+	if _, err := run("glob", "github.com/nobody/*"); err == nil || !strings.Contains(err.Error(), "no registry entries match") {
+		t.Fatalf("expected zero matches to fail, got %v", err)
+	}
+	if got := labeled(); len(got) != 0 {
+		t.Fatalf("expected no labels after zero matches, got %v", got)
+	}
+
+	out, err := run("glob", "github.com/org/web")
+	if err != nil {
+		t.Fatalf("label --match glob (one): %v", err)
+	}
+	if !strings.Contains(out, "labeled 1 of 1 matched repos") {
+		t.Fatalf("unexpected summary: %q", out)
+	}
+
+	out, err = run("regex", `/api$`)
+	if err != nil {
+		t.Fatalf("label --match regex (many): %v", err)
+	}
+	if !strings.Contains(out, "labeled 2 of 2 matched repos") {
+		t.Fatalf("unexpected summary: %q", out)
+	}
+	if got := labeled(); strings.Join(got, ",") != "github.com/org/api,github.com/org/web,github.com/other/api" {
+		t.Fatalf("unexpected labeled repos: %v", got)
+	}
+
+	// A glob * does not cross /, and re-applying an existing label is a no-op.
+	out, err = run("glob", "github.com/*")
+	if err == nil {
+		t.Fatalf("expected github.com/* to match nothing, got %q", out)
+	}
+	out, err = run("glob", "github.com/*/*")
+	if err != nil {
+		t.Fatalf("label --match glob (unchanged): %v", err)
+	}
+	if !strings.Contains(out, "labeled 0 of 3 matched repos (3 unchanged)") {
+		t.Fatalf("unexpected summary: %q", out)
+	}
+}
+
+func TestLabelCommandMatchAsksForConfirmation(t *testing.T) {
+	cfgPath := writeLabelsTestConfig(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	labelCmd.SetContext(context.Background())
+	labelCmd.SetOut(&bytes.Buffer{})
+	errOut := &bytes.Buffer{}
+	labelCmd.SetErr(errOut)
+	labelCmd.SetIn(strings.NewReader("n\n"))
+	_ = labelCmd.Flags().Set("registry", "")
+	_ = labelCmd.Flags().Set("match", "regex")
+	_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace([]string{"owner=sre"})
+	defer func() {
+		_ = labelCmd.Flags().Set("match", "")
+		_ = labelCmd.Flags().Lookup("set").Value.(interface{ Replace([]string) error }).Replace(nil)
+		labelCmd.SetOut(os.Stdout)
+		labelCmd.SetErr(os.Stderr)
+		labelCmd.SetIn(os.Stdin)
+	}()
+
+	if err := labelCmd.RunE(labelCmd, []string{"repo-"}); err != nil {
+		t.Fatalf("label --match: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "github.com/org/repo-a") || !strings.Contains(errOut.String(), "Update labels on 1 repos?") {
+		t.Fatalf("expected the matches and a prompt on stderr, got %q", errOut.String())
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if _, ok := cfg.Registry.FindByRepoID("github.com/org/repo-a").Labels["owner"]; ok {
+		t.Fatal("declining the prompt must not change labels")
+	}
+}
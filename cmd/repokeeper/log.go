@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/urlutil"
+	"github.com/spf13/cobra"
+)
+
+// infof writes a progress or status message to stderr unless --quiet is set.
+func infof(cmd *cobra.Command, format string, args ...any) {
+	logf(cmd, slog.LevelInfo, format, args...)
+}
+
+// warnf writes a warning to stderr unless --quiet is set. Plain text output
+// prefixes it with "warning: "; JSON output records it at the warn level.
+func warnf(cmd *cobra.Command, format string, args ...any) {
+	logf(cmd, slog.LevelWarn, format, args...)
+}
+
+// debugf writes a diagnostic message to stderr when -v is set and --quiet is
+// not. String arguments have URL credentials redacted.
+func debugf(cmd *cobra.Command, format string, args ...any) {
+	if verbosity(cmd) <= 0 {
+		return
+	}
+	// Redact URLs with embedded credentials in debug output to prevent accidental credential leakage.
+	redactedArgs := make([]any, len(args))
+	for i, arg := range args {
+		if str, ok := arg.(string); ok {
+			redactedArgs[i] = urlutil.RedactCredentials(str)
+		} else {
+			redactedArgs[i] = arg
+		}
+	}
+	logf(cmd, slog.LevelDebug, format, redactedArgs...)
+}
+
+// logf is the single sink for the CLI's stderr messages. It applies --quiet
+// and writes either a plain line or, with --log-json, one JSON object per
+// event with time, level, and msg fields.
+func logf(cmd *cobra.Command, level slog.Level, format string, args ...any) {
+	if isQuiet(cmd) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	w := cmd.ErrOrStderr()
+	var err error
+	if isLogJSON(cmd) {
+		err = newJSONLogHandler(w).Handle(context.Background(), slog.NewRecord(time.Now(), level, msg, 0))
+	} else {
+		if level == slog.LevelWarn {
+			msg = "warning: " + msg
+		}
+		_, err = fmt.Fprintln(w, msg)
+	}
+	if err != nil {
+		if _, fallbackErr := fmt.Fprintf(os.Stderr, "repokeeper: output write failure (%s): %v\n", strings.ToLower(level.String()), err); fallbackErr != nil {
+			return
+		}
+	}
+}
+
+// newJSONLogHandler returns the handler behind --log-json. It accepts every
+// level because logf has already applied the quiet and verbose gates.
+func newJSONLogHandler(w io.Writer) slog.Handler {
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+const (
+	matchModeGlob  = "glob"
+	matchModeRegex = "regex"
+)
+
+// repoIDMatcher reports whether a registry repo ID matches a --match pattern.
+type repoIDMatcher func(repoID string) bool
+
+// parseRepoIDMatcher compiles pattern for the --match mode: glob uses
+// path.Match against the whole repo ID (so * stops at /), regex uses an
+// unanchored regexp.
+func parseRepoIDMatcher(mode, pattern string) (repoIDMatcher, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case matchModeGlob:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --match glob %q: %w", pattern, err)
+		}
+		return func(repoID string) bool {
+			ok, _ := path.Match(pattern, repoID)
+			return ok
+		}, nil
+	case matchModeRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --match regex %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unsupported --match %q (expected %s or %s)", mode, matchModeGlob, matchModeRegex)
+	}
+}
+
+// matchingRegistryEntryIndexes returns the indexes of entries whose repo ID
+// matches, in registry order.
+func matchingRegistryEntryIndexes(entries []registry.Entry, match repoIDMatcher) []int {
+	var idxs []int
+	for i, entry := range entries {
+		if match(entry.RepoID) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// bulkMetadataResult summarizes a --match mutation.
+type bulkMetadataResult struct {
+	Matched int      `json:"matched"`
+	Updated int      `json:"updated"`
+	RepoIDs []string `json:"repo_ids"`
+}
+
+// applyBulkMetadataChange applies change to every registry entry matching
+// pattern after listing them and asking for confirmation (skipped by --yes).
+// change returns false when it left the entry unchanged; Updated counts the
+// rest. The bool is false when the user declined. No match is an error so a
+// typo never passes silently.
+func applyBulkMetadataChange(cmd *cobra.Command, reg *registry.Registry, mode, pattern, noun string, change func(*registry.Entry) bool) (bulkMetadataResult, bool, error) {
+	match, err := parseRepoIDMatcher(mode, pattern)
+	if err != nil {
+		return bulkMetadataResult{}, false, err
+	}
+	idxs := matchingRegistryEntryIndexes(reg.Entries, match)
+	if len(idxs) == 0 {
+		return bulkMetadataResult{}, false, fmt.Errorf("no registry entries match %s %q", mode, pattern)
+	}
+	result := bulkMetadataResult{Matched: len(idxs), RepoIDs: make([]string, 0, len(idxs))}
+	for _, idx := range idxs {
+		result.RepoIDs = append(result.RepoIDs, reg.Entries[idx].RepoID)
+	}
+
+	if !assumeYes(cmd) {
+		for _, repoID := range result.RepoIDs {
+			if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", sanitizeForDisplay(repoID)); err != nil {
+				return result, false, err
+			}
+		}
+		confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Update %s on %d repos? [y/N]: ", noun, len(idxs)))
+		if err != nil {
+			return result, false, err
+		}
+		if !confirmed {
+			return result, false, nil
+		}
+	}
+
+	now := time.Now()
+	for _, idx := range idxs {
+		entry := reg.Entries[idx]
+		if !change(&entry) {
+			continue
+		}
+		entry.LastSeen = now
+		reg.Entries[idx] = entry
+		result.Updated++
+	}
+	if result.Updated > 0 {
+		reg.UpdatedAt = now
+	}
+	return result, true, nil
+}
+
+// writeBulkMetadataResult prints the affected-count summary for a --match run.
+func writeBulkMetadataResult(cmd *cobra.Command, result bulkMetadataResult, verb, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	_, err := fmt.Fprintf(cmd.OutOrStdout(), "%s %d of %d matched repos (%d unchanged)\n", verb, result.Updated, result.Matched, result.Matched-result.Updated)
+	return err
+}
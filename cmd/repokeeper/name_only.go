@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// nameOnlyOutput is the parsed --name-only/--null pair. When enabled, a command
+// prints just the repo paths of its filtered result set instead of any table
+// or JSON output.
+type nameOnlyOutput struct {
+	enabled bool
+	null    bool
+}
+
+func parseNameOnlyOutput(cmd *cobra.Command) (nameOnlyOutput, error) {
+	enabled, _ := cmd.Flags().GetBool("name-only")
+	null, _ := cmd.Flags().GetBool("null")
+	if null && !enabled {
+		return nameOnlyOutput{}, fmt.Errorf("--null requires --name-only")
+	}
+	return nameOnlyOutput{enabled: enabled, null: null}, nil
+}
+
+// writeRepoNames prints each path through displayRepoPath, one per line or
+// NUL-terminated with --null. Paths are written verbatim, without the
+// sanitizing the tables apply, so they stay usable as xargs arguments.
+func writeRepoNames(w io.Writer, paths []string, cwd string, roots []string, null bool) error {
+	sep := "\n"
+	if null {
+		sep = "\x00"
+	}
+	for _, path := range paths {
+		if _, err := io.WriteString(w, displayRepoPath(path, cwd, roots)+sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+func TestWriteRepoNames(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{"/work/a", "/work/b c", "/elsewhere/d"}
+	out := &bytes.Buffer{}
+	if err := writeRepoNames(out, paths, "/work", nil, false); err != nil {
+		t.Fatalf("write names: %v", err)
+	}
+	if got, want := out.String(), "a\nb c\n/elsewhere/d\n"; got != want {
+		t.Fatalf("writeRepoNames() = %q, want %q", got, want)
+	}
+	out.Reset()
+	if err := writeRepoNames(out, paths, "/work", nil, true); err != nil {
+		t.Fatalf("write names: %v", err)
+	}
+	if got, want := out.String(), "a\x00b c\x00/elsewhere/d\x00"; got != want {
+		t.Fatalf("writeRepoNames() with --null = %q, want %q", got, want)
+	}
+}
+
+func TestParseNameOnlyOutputRequiresNameOnlyForNull(t *testing.T) {
+	t.Parallel()
+
+	cmd := &cobra.Command{}
+	addNameOnlyFlags(cmd)
+	_ = cmd.Flags().Set("null", "true")
+	if _, err := parseNameOnlyOutput(cmd); err == nil {
+		t.Fatal("expected --null without --name-only to fail")
+	}
+	_ = cmd.Flags().Set("name-only", "true")
+	if got, err := parseNameOnlyOutput(cmd); err != nil || !got.enabled || !got.null {
+		t.Fatalf("expected name-only with null, got %+v, %v", got, err)
+	}
+}
+
+func TestStatusNameOnlyPrintsFilteredPaths(t *testing.T) {
+	tmp := t.TempDir()
+	clean := filepath.Join(tmp, "clean")
+	dirty := filepath.Join(tmp, "dirty")
+	for _, dir := range []string{clean, dirty} {
+		mustRunGit(t, tmp, "init", "-q", dir)
+	}
+	if err := os.WriteFile(filepath.Join(dirty, "untracked.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "local:clean", Path: clean, Status: registry.StatusPresent, LastSeen: time.Now()},
+		{RepoID: "local:dirty", Path: dirty, Status: registry.StatusPresent, LastSeen: time.Now()},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(context.Background())
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+
+	_ = statusCmd.Flags().Set("registry", "")
+	_ = statusCmd.Flags().Set("format", "json")
+	_ = statusCmd.Flags().Set("only", "dirty")
+	_ = statusCmd.Flags().Set("name-only", "true")
+	_ = statusCmd.Flags().Set("null", "true")
+	defer func() {
+		_ = statusCmd.Flags().Set("format", "table")
+		_ = statusCmd.Flags().Set("only", "all")
+		_ = statusCmd.Flags().Set("name-only", "false")
+		_ = statusCmd.Flags().Set("null", "false")
+	}()
+
+	if err := statusCmd.RunE(statusCmd, nil); err != nil {
+		t.Fatalf("status --name-only: %v", err)
+	}
+	if got, want := out.String(), "dirty\x00"; got != want {
+		t.Fatalf("expected only the dirty path, got %q", got)
+	}
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open <repo-id-or-path>",
+	Short: "Print or launch the web page of a repository's remote",
+	Long: "Resolves the repository like describe and converts its registry remote_url into the repo's web page, " +
+		"e.g. git@github.com:org/repo.git becomes https://github.com/org/repo.\n\n" +
+		"The URL is printed by default; --launch opens it in the default browser instead.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting open")
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+
+		registryOverride, _ := cmd.Flags().GetString("registry")
+		reg := cfg.Registry
+		if registryOverride != "" {
+			reg, err = registry.Load(registryOverride)
+			if err != nil {
+				return err
+			}
+		}
+		if reg == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+
+		entry, err := selectRegistryEntryForDescribe(reg.Entries, args[0], cwd, []string{config.EffectiveRoot(cfgPath)})
+		if err != nil {
+			return err
+		}
+		remoteURL := strings.TrimSpace(entry.RemoteURL)
+		if remoteURL == "" {
+			return fmt.Errorf("repo %q has no remote_url in the registry", entry.RepoID)
+		}
+		webURL, err := gitx.WebURL(remoteURL)
+		if err != nil {
+			return err
+		}
+
+		launch, _ := cmd.Flags().GetBool("launch")
+		if !launch {
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), webURL)
+			return err
+		}
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		debugf(cmd, "launching %s", webURL)
+		if err := launchBrowser(ctx, webURL); err != nil {
+			return fmt.Errorf("open %s: %w", webURL, err)
+		}
+		return nil
+	},
+}
+
+// launchBrowser opens url with the platform's default handler. Tests replace
+// it to avoid starting a browser.
+var launchBrowser = func(ctx context.Context, url string) error {
+	var run *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		run = exec.CommandContext(ctx, "open", url)
+	case "windows":
+		run = exec.CommandContext(ctx, "rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		run = exec.CommandContext(ctx, "xdg-open", url)
+	}
+	return run.Run()
+}
+
+func init() {
+	openCmd.Flags().String("registry", "", "override registry file path")
+	openCmd.Flags().Bool("launch", false, "open the URL in the default browser instead of printing it")
+	rootCmd.AddCommand(openCmd)
+}
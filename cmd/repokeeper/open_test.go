@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestOpenCommandPrintsOrLaunchesWebURL(t *testing.T) {
+	tmp := t.TempDir()
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: filepath.Join(tmp, "repo"), RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent},
+		{RepoID: "local/scratch", Path: filepath.Join(tmp, "scratch"), RemoteURL: "/srv/git/scratch.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	openCmd.SetOut(out)
+	openCmd.SetContext(context.Background())
+	defer openCmd.SetOut(nil)
+
+	if err := openCmd.RunE(openCmd, []string{"github.com/org/repo"}); err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "https://github.com/org/repo" {
+		t.Fatalf("expected the web URL printed, got %q", got)
+	}
+
+	prevLaunch := launchBrowser
+	var launched string
+	launchBrowser = func(_ context.Context, url string) error {
+		launched = url
+		return nil
+	}
+	defer func() { launchBrowser = prevLaunch }()
+	_ = openCmd.Flags().Set("launch", "true")
+	defer func() { _ = openCmd.Flags().Set("launch", "false") }()
+	out.Reset()
+	if err := openCmd.RunE(openCmd, []string{"github.com/org/repo"}); err != nil {
+		t.Fatalf("open --launch: %v", err)
+	}
+	if launched != "https://github.com/org/repo" || out.Len() != 0 {
+		t.Fatalf("expected the URL launched instead of printed, launched %q, printed %q", launched, out.String())
+	}
+
+	if err := openCmd.RunE(openCmd, []string{"local/scratch"}); err == nil || !strings.Contains(err.Error(), "no web page") {
+		t.Fatalf("expected a clear error for a local remote, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+)
+
+// withCSVOutputMode extends parse with `--format csv`. Only status and sync
+// have a fixed CSV column set, so other commands keep rejecting it.
+func withCSVOutputMode(parse func(string) (outputMode, error)) func(string) (outputMode, error) {
+	return func(format string) (outputMode, error) {
+		if strings.EqualFold(strings.TrimSpace(format), string(outputKindCSV)) {
+			return outputMode{kind: outputKindCSV}, nil
+		}
+		return parse(format)
+	}
+}
+
+// writeCSV writes headers (unless noHeaders) and rows as RFC 4180 CSV, so
+// values containing commas, quotes, or newlines survive a spreadsheet import.
+func writeCSV(w io.Writer, noHeaders bool, headers []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if !noHeaders {
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// statusCSVHeaders are the status -o csv columns. Values are raw: full paths,
+// no color, and empty cells where the table would print "-".
+var statusCSVHeaders = []string{
+	"path", "repo_id", "type", "branch", "detached", "dirty", "tracking",
+	"primary_remote", "upstream", "ahead", "behind", "last_commit", "error_class", "error",
+}
+
+// writeStatusCSV writes one row per repo, plus a trailing reason column when
+// reasons is non-nil (status --explain).
+func writeStatusCSV(w io.Writer, report *model.StatusReport, noHeaders bool, reasons map[string]string) error {
+	headers := statusCSVHeaders
+	if reasons != nil {
+		headers = append(append([]string(nil), statusCSVHeaders...), "reason")
+	}
+	rows := make([][]string, 0, len(report.Repos))
+	for _, repo := range report.Repos {
+		dirty := ""
+		if repo.Worktree != nil {
+			dirty = strconv.FormatBool(repo.Worktree.Dirty)
+		}
+		lastCommit := ""
+		if !repo.LastCommit.IsZero() {
+			lastCommit = repo.LastCommit.UTC().Format(time.RFC3339)
+		}
+		row := []string{
+			repo.Path,
+			repo.RepoID,
+			repo.Type,
+			repo.Head.Branch,
+			strconv.FormatBool(repo.Head.Detached),
+			dirty,
+			string(repo.Tracking.Status),
+			repo.PrimaryRemote,
+			repo.Tracking.Upstream,
+			csvCount(repo.Tracking.Ahead),
+			csvCount(repo.Tracking.Behind),
+			lastCommit,
+			repo.ErrorClass,
+			repo.Error,
+		}
+		if reasons != nil {
+			row = append(row, reasons[repo.Path])
+		}
+		rows = append(rows, row)
+	}
+	return writeCSV(w, noHeaders, headers, rows)
+}
+
+// syncCSVHeaders are the sync -o csv columns, taken from the JSON result shape.
+var syncCSVHeaders = []string{
+	"path", "repo_id", "action", "outcome", "ok", "planned", "skip_reason", "error", "warning", "duration_ms",
+}
+
+func writeSyncCSV(w io.Writer, results []engine.SyncResult, noHeaders bool) error {
+	rows := make([][]string, 0, len(results))
+	for _, res := range toSyncResultJSONs(results) {
+		rows = append(rows, []string{
+			res.Path,
+			res.RepoID,
+			res.Action,
+			res.Outcome,
+			strconv.FormatBool(res.OK),
+			strconv.FormatBool(res.Planned),
+			res.SkipReason,
+			res.Error,
+			res.Warning,
+			strconv.FormatInt(res.DurationMS, 10),
+		})
+	}
+	return writeCSV(w, noHeaders, syncCSVHeaders, rows)
+}
+
+func csvCount(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
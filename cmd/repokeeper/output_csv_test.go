@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+)
+
+func TestWithCSVOutputModeAcceptsCSVOnlyWhereWired(t *testing.T) {
+	mode, err := withCSVOutputMode(parseOutputMode)(" CSV ")
+	if err != nil || mode.kind != outputKindCSV {
+		t.Fatalf("expected csv mode, got %+v %v", mode, err)
+	}
+	if mode, err := withCSVOutputMode(parseOutputMode)("wide"); err != nil || mode.kind != outputKindWide {
+		t.Fatalf("expected other formats to pass through, got %+v %v", mode, err)
+	}
+	if _, err := parseOutputMode("csv"); err == nil {
+		t.Fatal("expected commands without csv support to keep rejecting it")
+	}
+}
+
+func TestWriteStatusCSVQuotesSpecialCharacters(t *testing.T) {
+	behind := 3
+	report := &model.StatusReport{Repos: []model.RepoStatus{
+		{
+			RepoID:   "github.com/org/api",
+			Path:     "/src/clients, acme/api",
+			Head:     model.Head{Branch: "main"},
+			Worktree: &model.Worktree{Dirty: true},
+			Tracking: model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main", Behind: &behind},
+		},
+		{
+			RepoID:     "github.com/org/broken",
+			Path:       "/src/broken",
+			ErrorClass: "network",
+			Error:      "fatal: unable to access \"https://example.com\"\nretry later",
+		},
+	}}
+	out := &bytes.Buffer{}
+	if err := writeStatusCSV(out, report, false, nil); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if !strings.Contains(out.String(), `"/src/clients, acme/api"`) || !strings.Contains(out.String(), `"fatal: unable to access ""https://example.com""`) {
+		t.Fatalf("expected quoted fields, got:\n%s", out.String())
+	}
+
+	records, err := csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatalf("read back csv: %v", err)
+	}
+	if len(records) != 3 || !slices.Equal(records[0], statusCSVHeaders) {
+		t.Fatalf("expected a header row and two repos, got %q", records)
+	}
+	if got := records[1]; got[0] != "/src/clients, acme/api" || got[5] != "true" || got[6] != "behind" || got[9] != "" || got[10] != "3" {
+		t.Fatalf("unexpected first row %q", got)
+	}
+	if got := records[2]; got[len(got)-1] != report.Repos[1].Error || got[5] != "" {
+		t.Fatalf("expected the multi-line error to round-trip, got %q", got)
+	}
+
+	out.Reset()
+	if err := writeStatusCSV(out, report, true, map[string]string{"/src/broken": "error: network"}); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	records, err = csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatalf("read back csv: %v", err)
+	}
+	if len(records) != 2 || records[1][len(records[1])-1] != "error: network" {
+		t.Fatalf("expected no header row and a reason column, got %q", records)
+	}
+}
+
+func TestWriteSyncCSVQuotesSpecialCharacters(t *testing.T) {
+	results := []engine.SyncResult{
+		{RepoID: "github.com/org/api", Path: "/src/a,b", Action: "git fetch --all --prune", Outcome: engine.SyncOutcomeFetched, OK: true},
+		{RepoID: "github.com/org/web", Path: "/src/web", Action: "git fetch --all --prune", Outcome: engine.SyncOutcomeFailedFetch, Error: "line one\nline \"two\""},
+	}
+	out := &bytes.Buffer{}
+	if err := writeSyncCSV(out, results, false); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	records, err := csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatalf("read back csv: %v", err)
+	}
+	if len(records) != 3 || !slices.Equal(records[0], syncCSVHeaders) {
+		t.Fatalf("expected a header row and two results, got %q", records)
+	}
+	if records[1][0] != "/src/a,b" || records[1][4] != "true" {
+		t.Fatalf("unexpected first row %q", records[1])
+	}
+	if records[2][7] != "line one\nline \"two\"" || records[2][4] != "false" {
+		t.Fatalf("expected the multi-line error to round-trip, got %q", records[2])
+	}
+
+	out.Reset()
+	if err := writeSyncCSV(out, results, true); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if strings.HasPrefix(out.String(), "path,") {
+		t.Fatalf("expected --no-headers to drop the header row, got:\n%s", out.String())
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/skaphos/repokeeper/internal/cliio"
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 type outputKind string
@@ -16,12 +18,18 @@ const (
 	outputKindTable         outputKind = "table"
 	outputKindWide          outputKind = "wide"
 	outputKindJSON          outputKind = "json"
+	outputKindYAML          outputKind = "yaml"
+	outputKindNDJSON        outputKind = "ndjson"
 	outputKindCustomColumns outputKind = "custom-columns"
+	outputKindTemplate      outputKind = "template"
+	outputKindCSV           outputKind = "csv"
 )
 
 type outputMode struct {
 	kind outputKind
 	expr string
+	// tmpl is the parsed --template for outputKindTemplate.
+	tmpl *template.Template
 }
 
 type customColumnSpec struct {
@@ -45,6 +53,8 @@ func parseOutputMode(format string) (outputMode, error) {
 		return outputMode{kind: outputKindWide}, nil
 	case lower == string(outputKindJSON):
 		return outputMode{kind: outputKindJSON}, nil
+	case lower == string(outputKindYAML), lower == "yml":
+		return outputMode{kind: outputKindYAML}, nil
 	default:
 		return outputMode{}, fmt.Errorf("unsupported format %q", format)
 	}
@@ -124,6 +134,37 @@ func rowsForCustomColumns(output any) []any {
 	}
 }
 
+// writeYAMLOutput renders output as YAML through its JSON encoding, so -o yaml
+// uses the same field names, omitempty rules, and null pointers as -o json
+// instead of a second set of yaml struct tags that could drift.
+func writeYAMLOutput(cmd *cobra.Command, output any) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	// JSON is valid YAML; decoding into a node keeps the JSON key order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetYAMLNodeStyle(&node)
+	rendered, err := yaml.Marshal(&node)
+	if err != nil {
+		return err
+	}
+	_, err = cmd.OutOrStdout().Write(rendered)
+	return err
+}
+
+// resetYAMLNodeStyle drops the flow/quoted styles inherited from the JSON
+// source so the encoder emits block YAML, quoting only where required.
+func resetYAMLNodeStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLNodeStyle(child)
+	}
+}
+
 func marshalToGeneric(input any) (any, error) {
 	data, err := json.Marshal(input)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 func TestParseOutputMode(t *testing.T) {
@@ -21,8 +22,10 @@ func TestParseOutputMode(t *testing.T) {
 		{name: "table", input: "table", kind: outputKindTable},
 		{name: "wide", input: "wide", kind: outputKindWide},
 		{name: "json", input: "json", kind: outputKindJSON},
+		{name: "yaml", input: "yaml", kind: outputKindYAML},
+		{name: "yml alias", input: "YML", kind: outputKindYAML},
 		{name: "custom columns", input: "custom-columns=REPO:.repo_id,TRACKING:.tracking.status", kind: outputKindCustomColumns, expr: "REPO:.repo_id,TRACKING:.tracking.status"},
-		{name: "invalid", input: "yaml", hasErr: true},
+		{name: "invalid", input: "xml", hasErr: true},
 		{name: "custom missing expr", input: "custom-columns=", hasErr: true},
 	}
 	for _, tc := range tests {
@@ -69,6 +72,39 @@ func TestWriteCustomColumnsOutput(t *testing.T) {
 	}
 }
 
+func TestWriteYAMLOutputUsesJSONFieldNames(t *testing.T) {
+	ahead := 2
+	repo := model.RepoStatus{
+		RepoID:   "github.com/org/repo-a",
+		Tracking: model.Tracking{Status: model.TrackingAhead, Upstream: "origin/main", Ahead: &ahead},
+	}
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+
+	if err := writeYAMLOutput(cmd, repo); err != nil {
+		t.Fatalf("writeYAMLOutput returned error: %v", err)
+	}
+	got := out.String()
+	if strings.Contains(got, "{\"") || strings.Contains(got, "\"repo_id\"") {
+		t.Fatalf("expected block-style YAML, got: %q", got)
+	}
+	var decoded map[string]any
+	if err := yaml.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid YAML: %v\n%s", err, got)
+	}
+	if decoded["repo_id"] != "github.com/org/repo-a" {
+		t.Fatalf("expected repo_id key, got %#v", decoded)
+	}
+	tracking, ok := decoded["tracking"].(map[string]any)
+	if !ok || tracking["ahead"] != 2 {
+		t.Fatalf("expected tracking.ahead=2, got %#v", decoded["tracking"])
+	}
+	if value, present := tracking["behind"]; !present || value != nil {
+		t.Fatalf("expected nil behind pointer to render as null, got %#v", tracking)
+	}
+}
+
 func TestParseCustomColumnsSpecValidation(t *testing.T) {
 	if _, err := parseCustomColumnsSpec("BROKEN"); err == nil {
 		t.Fatal("expected invalid custom-columns segment error")
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// parseTemplatedOutputMode extends parse with `--format template`, which
+// takes its Go template from --template. The template is parsed here so a
+// syntax error is reported before any repo is inspected.
+func parseTemplatedOutputMode(cmd *cobra.Command, format string, parse func(string) (outputMode, error)) (outputMode, error) {
+	text, _ := cmd.Flags().GetString("template")
+	if !strings.EqualFold(strings.TrimSpace(format), string(outputKindTemplate)) {
+		if cmd.Flags().Changed("template") {
+			return outputMode{}, fmt.Errorf("--template requires --format template")
+		}
+		return parse(format)
+	}
+	if strings.TrimSpace(text) == "" {
+		return outputMode{}, fmt.Errorf("--format template requires --template")
+	}
+	tmpl, err := template.New("output").Funcs(outputTemplateFuncs("", nil)).Parse(text)
+	if err != nil {
+		return outputMode{}, fmt.Errorf("invalid --template: %w", err)
+	}
+	return outputMode{kind: outputKindTemplate, tmpl: tmpl}, nil
+}
+
+// outputTemplateFuncs are the helpers available to --template. short renders
+// a repo path the way the table output does: relative to the cwd or a root.
+func outputTemplateFuncs(cwd string, roots []string) template.FuncMap {
+	return template.FuncMap{
+		"short": func(path string) string { return displayRepoPath(path, cwd, roots) },
+		"join":  strings.Join,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+}
+
+// writeTemplateOutput executes the --template once per item, ending each
+// item's output with a newline unless the template already does. Everything
+// is rendered before anything is written, so a template that references a
+// missing field fails without printing a partial result. Failures writing the
+// rendered output are logged like the other formats.
+func writeTemplateOutput[T any](cmd *cobra.Command, tmpl *template.Template, items []T, cwd string, roots []string) error {
+	// Rebinding the helpers to this run's cwd and roots is safe: Funcs only
+	// replaces the implementations of names the template was parsed with.
+	tmpl = template.Must(tmpl.Clone()).Funcs(outputTemplateFuncs(cwd, roots))
+	var buf bytes.Buffer
+	for _, item := range items {
+		start := buf.Len()
+		if err := tmpl.Execute(&buf, item); err != nil {
+			return fmt.Errorf("execute --template: %w", err)
+		}
+		if buf.Len() == start || buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	_, err := cmd.OutOrStdout().Write(buf.Bytes())
+	logOutputWriteFailure(cmd, "template", err)
+	return nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func newTemplateTestCommand(template string) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("template", "", templateUsage)
+	if template != "" {
+		_ = cmd.Flags().Set("template", template)
+	}
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	return cmd, out
+}
+
+func TestParseTemplatedOutputMode(t *testing.T) {
+	cmd, _ := newTemplateTestCommand("{{.RepoID}}")
+	mode, err := parseTemplatedOutputMode(cmd, "template", parseOutputMode)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if mode.kind != outputKindTemplate || mode.tmpl == nil {
+		t.Fatalf("expected a parsed template mode, got %+v", mode)
+	}
+
+	if _, err := parseTemplatedOutputMode(cmd, "json", parseOutputMode); err == nil || !strings.Contains(err.Error(), "--template requires --format template") {
+		t.Fatalf("expected --template without --format template to fail, got %v", err)
+	}
+
+	empty, _ := newTemplateTestCommand("")
+	if _, err := parseTemplatedOutputMode(empty, "template", parseOutputMode); err == nil || !strings.Contains(err.Error(), "requires --template") {
+		t.Fatalf("expected --format template without --template to fail, got %v", err)
+	}
+	if mode, err := parseTemplatedOutputMode(empty, "wide", parseOutputMode); err != nil || mode.kind != outputKindWide {
+		t.Fatalf("expected other formats to pass through, got %+v, %v", mode, err)
+	}
+
+	broken, _ := newTemplateTestCommand("{{.RepoID")
+	if _, err := parseTemplatedOutputMode(broken, "template", parseOutputMode); err == nil || !strings.Contains(err.Error(), "invalid --template") {
+		t.Fatalf("expected a template syntax error, got %v", err)
+	}
+}
+
+func TestWriteTemplateOutputRendersEachRepo(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "work")
+	cmd, out := newTemplateTestCommand(`{{.RepoID}} {{.Tracking.Status}} {{short .Path}}`)
+	mode, err := parseTemplatedOutputMode(cmd, "template", parseOutputMode)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	repos := []model.RepoStatus{
+		{RepoID: "github.com/org/a", Path: filepath.Join(root, "a"), Tracking: model.Tracking{Status: model.TrackingEqual}},
+		{RepoID: "github.com/org/b", Path: filepath.Join(root, "b"), Tracking: model.Tracking{Status: model.TrackingBehind}},
+	}
+	if err := writeTemplateOutput(cmd, mode.tmpl, repos, root, []string{root}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := "github.com/org/a equal a\ngithub.com/org/b behind b\n"
+	if out.String() != want {
+		t.Fatalf("template output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteTemplateOutputRejectsUnknownField(t *testing.T) {
+	cmd, out := newTemplateTestCommand(`{{.RepoID}} {{.NoSuchField}}`)
+	mode, err := parseTemplatedOutputMode(cmd, "template", parseOutputMode)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	err = writeTemplateOutput(cmd, mode.tmpl, []model.RepoStatus{{RepoID: "github.com/org/a"}}, "", nil)
+	if err == nil || !strings.Contains(err.Error(), "NoSuchField") {
+		t.Fatalf("expected an unknown field error, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected no partial output, got %q", out.String())
+	}
+}
+
+func TestWriteTemplateOutputRendersSyncResults(t *testing.T) {
+	cmd, out := newTemplateTestCommand("{{.RepoID}}: {{.Outcome}}\n")
+	mode, err := parseTemplatedOutputMode(cmd, "template", parseOutputMode)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	results := []engine.SyncResult{
+		{RepoID: "github.com/org/a", Outcome: engine.SyncOutcomeFetched},
+		{RepoID: "github.com/org/b", Outcome: engine.SyncOutcomeFailedFetch},
+	}
+	if err := reportSyncResults(cmd, results, syncReportOptions{mode: mode}); err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "github.com/org/a: fetched\ngithub.com/org/b: failed_fetch\n") {
+		t.Fatalf("unexpected sync template output %q", out.String())
+	}
+}
@@ -19,12 +19,19 @@ type exportBundle struct {
 
 const currentExportBundleVersion = 2
 
+// cloneRegistry copies reg deeply enough that export and import can edit
+// entries, labels, and annotations (including sync policy annotations such as
+// frozen or weight) without touching the live registry.
 func cloneRegistry(reg *registry.Registry) *registry.Registry {
 	if reg == nil {
 		return nil
 	}
 	clone := *reg
 	clone.Entries = append([]registry.Entry(nil), reg.Entries...)
+	for i := range clone.Entries {
+		clone.Entries[i].Labels = cloneMetadataMap(clone.Entries[i].Labels)
+		clone.Entries[i].Annotations = cloneMetadataMap(clone.Entries[i].Annotations)
+	}
 	return &clone
 }
 
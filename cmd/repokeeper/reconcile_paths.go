@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var reconcilePathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Update registry paths for repos that scan found at a new location",
+	Long: "Scan marks an entry moved when its repo turns up at a new path and the old path is gone. This command points " +
+		"each moved entry at its new path and marks it present again. Entries whose new path has disappeared since the scan " +
+		"are skipped.\n\n" +
+		"Prints a before/after table and asks for confirmation before saving unless --yes is set. --dry-run only prints the table.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting reconcile paths")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindTable && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+
+		registryOverride, _ := cmd.Flags().GetString("registry")
+		var reg *registry.Registry
+		if registryOverride != "" {
+			reg, err = registry.Load(registryOverride)
+			if err != nil {
+				return err
+			}
+		} else {
+			reg = cfg.Registry
+			if reg == nil {
+				return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+			}
+		}
+
+		moves := planPathReconcile(reg)
+		for _, entry := range reg.Entries {
+			if entry.Status == registry.StatusMoved && entry.MovedTo != "" && !pathExists(entry.MovedTo) {
+				warnf(cmd, "skipping %s: new path %s no longer exists (run repokeeper scan again)", entry.RepoID, entry.MovedTo)
+			}
+		}
+		if len(moves) == 0 {
+			infof(cmd, "no moved repos to reconcile")
+			return nil
+		}
+
+		switch mode.kind {
+		case outputKindJSON:
+			data, err := json.MarshalIndent(moves, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
+				return err
+			}
+		default:
+			if err := writePathReconcileTable(cmd, moves, noHeaders); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return nil
+		}
+		if !assumeYes(cmd) {
+			confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Update %d moved registry paths? [y/N]: ", len(moves)))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				infof(cmd, "reconcile paths cancelled")
+				return nil
+			}
+		}
+
+		applyPathReconcile(reg, moves, time.Now())
+		if registryOverride != "" {
+			if err := registry.Save(reg, registryOverride); err != nil {
+				return err
+			}
+		} else {
+			cfg.Registry = reg
+			if err := config.Save(cfg, cfgPath); err != nil {
+				return err
+			}
+		}
+		infof(cmd, "updated %d moved registry paths", len(moves))
+		return nil
+	},
+}
+
+// pathReconcile is one moved registry entry re-pointed by reconcile paths.
+// Index points into the registry entries the plan was built from.
+type pathReconcile struct {
+	Index   int    `json:"-"`
+	RepoID  string `json:"repo_id"`
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+// planPathReconcile lists the moved entries whose recorded new path still
+// exists. The registry itself is not modified.
+func planPathReconcile(reg *registry.Registry) []pathReconcile {
+	moves := []pathReconcile{}
+	for i, entry := range reg.Entries {
+		if entry.Status != registry.StatusMoved || entry.MovedTo == "" || !pathExists(entry.MovedTo) {
+			continue
+		}
+		moves = append(moves, pathReconcile{
+			Index:   i,
+			RepoID:  entry.RepoID,
+			OldPath: entry.Path,
+			NewPath: entry.MovedTo,
+		})
+	}
+	return moves
+}
+
+func applyPathReconcile(reg *registry.Registry, moves []pathReconcile, now time.Time) {
+	for _, move := range moves {
+		reg.Entries[move.Index].ApplyMove(now)
+	}
+	reg.UpdatedAt = now
+}
+
+func writePathReconcileTable(cmd *cobra.Command, moves []pathReconcile, noHeaders bool) error {
+	rows := make([][]string, 0, len(moves))
+	for _, move := range moves {
+		rows = append(rows, []string{move.RepoID, move.OldPath, move.NewPath})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"REPO", "OLD_PATH", "NEW_PATH"}, rows)
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func init() {
+	reconcilePathsCmd.Flags().Bool("dry-run", false, "print the moved paths without saving")
+	reconcilePathsCmd.Flags().String("registry", "", "override registry file path")
+	addFormatFlag(reconcilePathsCmd, "output format: table or json")
+	addNoHeadersFlag(reconcilePathsCmd)
+
+	reconcileCmd.AddCommand(reconcilePathsCmd)
+}
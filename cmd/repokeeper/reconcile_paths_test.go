@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestReconcilePathsAppliesMovesRecordedByScan(t *testing.T) {
+	tmp := t.TempDir()
+	oldPath := filepath.Join(tmp, "proj")
+	newPath := filepath.Join(tmp, "proj-renamed")
+	mustRunGit(t, tmp, "init", newPath)
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/proj", Path: oldPath, MovedTo: newPath, Status: registry.StatusMoved},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(tmp, "gone"), MovedTo: filepath.Join(tmp, "gone-again"), Status: registry.StatusMoved},
+		{RepoID: "github.com/org/missing", Path: filepath.Join(tmp, "missing"), Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	reconcilePathsCmd.SetOut(out)
+	reconcilePathsCmd.SetErr(errOut)
+	reconcilePathsCmd.SetContext(context.Background())
+	defer func() {
+		reconcilePathsCmd.SetOut(os.Stdout)
+		reconcilePathsCmd.SetErr(os.Stderr)
+		_ = reconcilePathsCmd.Flags().Set("dry-run", "false")
+	}()
+	_ = reconcilePathsCmd.Flags().Set("dry-run", "true")
+
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if err := reconcilePathsCmd.RunE(reconcilePathsCmd, nil); err != nil {
+		t.Fatalf("reconcile paths --dry-run: %v", err)
+	}
+	table := out.String()
+	for _, want := range []string{"OLD_PATH", "NEW_PATH", oldPath, newPath} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in dry-run table:\n%s", want, table)
+		}
+	}
+	if strings.Contains(table, "github.com/org/gone") || strings.Contains(table, "github.com/org/missing") {
+		t.Fatalf("expected only applicable moves in the table:\n%s", table)
+	}
+	if !strings.Contains(errOut.String(), "skipping github.com/org/gone") {
+		t.Fatalf("expected a warning for the vanished destination, got %q", errOut.String())
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected --dry-run not to modify the config")
+	}
+
+	_ = reconcilePathsCmd.Flags().Set("dry-run", "false")
+	restoreYes := withAssumeYes(t, true)
+	defer restoreYes()
+	if err := reconcilePathsCmd.RunE(reconcilePathsCmd, nil); err != nil {
+		t.Fatalf("reconcile paths: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	got := map[string]registry.Entry{}
+	for _, entry := range saved.Registry.Entries {
+		got[entry.RepoID] = entry
+	}
+	if entry := got["github.com/org/proj"]; entry.Path != newPath || entry.Status != registry.StatusPresent || entry.MovedTo != "" {
+		t.Fatalf("expected moved repo to be present at its new path, got %+v", entry)
+	}
+	if entry := got["github.com/org/gone"]; entry.Status != registry.StatusMoved || entry.Path != filepath.Join(tmp, "gone") {
+		t.Fatalf("expected skipped move to stay pending, got %+v", entry)
+	}
+}
+
+func TestReconcilePathsDeclinedPromptLeavesRegistryUntouched(t *testing.T) {
+	tmp := t.TempDir()
+	newPath := filepath.Join(tmp, "proj-renamed")
+	if err := os.MkdirAll(newPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/proj", Path: filepath.Join(tmp, "proj"), MovedTo: newPath, Status: registry.StatusMoved},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	restoreYes := withAssumeYes(t, false)
+	defer restoreYes()
+
+	reconcilePathsCmd.SetOut(&bytes.Buffer{})
+	reconcilePathsCmd.SetErr(&bytes.Buffer{})
+	reconcilePathsCmd.SetIn(strings.NewReader("n\n"))
+	reconcilePathsCmd.SetContext(context.Background())
+	defer func() {
+		reconcilePathsCmd.SetOut(os.Stdout)
+		reconcilePathsCmd.SetErr(os.Stderr)
+		reconcilePathsCmd.SetIn(os.Stdin)
+	}()
+
+	if err := reconcilePathsCmd.RunE(reconcilePathsCmd, nil); err != nil {
+		t.Fatalf("reconcile paths: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if entry := saved.Registry.Entries[0]; entry.Status != registry.StatusMoved || entry.MovedTo != newPath {
+		t.Fatalf("expected declined reconcile to keep the pending move, got %+v", entry)
+	}
+}
@@ -0,0 +1,611 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Inspect and maintain registry files",
+}
+
+var registryDiffCmd = &cobra.Command{
+	Use:   "diff <a> <b>",
+	Short: "Compare the repos recorded in two registry files",
+	Long: "Compares two registry files, or config files with an embedded registry, and reports repos only in A, only in B, " +
+		"and repos recorded in both whose path, remote URL, branch, type, labels, or annotations differ.\n\n" +
+		"Entries are paired the same way import merges them: by repo_id and checkout_id, then repo_id and path, then a unique repo_id.",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+
+		a, err := loadRegistryForDiff(args[0])
+		if err != nil {
+			return err
+		}
+		b, err := loadRegistryForDiff(args[1])
+		if err != nil {
+			return err
+		}
+		diff := diffRegistries(a, b)
+
+		switch mode.kind {
+		case outputKindTable:
+			return writeRegistryDiffTable(cmd, diff, noHeaders)
+		case outputKindJSON:
+			data, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return err
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+	},
+}
+
+var registryMigrateCmd = &cobra.Command{
+	Use:   "migrate --from <old-root> --to <new-root>",
+	Short: "Rewrite registry paths after moving a workspace to a new root",
+	Long: "Rewrites every registry path under --from to the same relative path under --to. A rewritten entry is marked present " +
+		"only when its new path holds a repository; otherwise it is marked missing. Entries outside --from are left alone.\n\n" +
+		"Prints a before/after table and asks for confirmation before saving unless --yes is set. --dry-run only prints the table.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting registry migrate")
+		fromRaw, _ := cmd.Flags().GetString("from")
+		toRaw, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindTable && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+		if strings.TrimSpace(fromRaw) == "" || strings.TrimSpace(toRaw) == "" {
+			return fmt.Errorf("--from and --to are required")
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		from := resolveAbsoluteTargetPath(cwd, strings.TrimSpace(fromRaw))
+		to := resolveAbsoluteTargetPath(cwd, strings.TrimSpace(toRaw))
+		if from == to {
+			return fmt.Errorf("--from and --to are the same path: %q", from)
+		}
+		target, err := loadRegistryTarget(cmd, cwd)
+		if err != nil {
+			return err
+		}
+		reg := target.reg
+
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		migrations := planRegistryMigration(cmd.Context(), adapter, reg, from, to)
+		if len(migrations) == 0 {
+			if root := registryPathsRoot(reg); root != "" {
+				infof(cmd, "no registry entries under %s (registry paths share the root %s)", from, root)
+			} else {
+				infof(cmd, "no registry entries under %s", from)
+			}
+			return nil
+		}
+
+		switch mode.kind {
+		case outputKindJSON:
+			data, err := json.MarshalIndent(migrations, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
+				return err
+			}
+		default:
+			if err := writeRegistryMigrationTable(cmd, migrations, noHeaders); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return nil
+		}
+		if !assumeYes(cmd) {
+			confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Rewrite %d registry paths from %s to %s? [y/N]: ", len(migrations), from, to))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				infof(cmd, "registry migrate cancelled")
+				return nil
+			}
+		}
+
+		applyRegistryMigration(reg, migrations, time.Now())
+		if err := target.save(); err != nil {
+			return err
+		}
+		infof(cmd, "migrated %d registry entries from %s to %s", len(migrations), from, to)
+		return nil
+	},
+}
+
+var registryDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Merge registry entries that share a repo ID",
+	Long: "Collapses registry entries that share a repo_id. Each entry's path is checked first: one that no longer holds a " +
+		"repository is treated as missing. Entries whose paths hold a repository are separate checkouts and are all kept; the " +
+		"others are dropped and their labels and annotations merged into the most recently seen present entry (or, when none is present, " +
+		"the most recently seen one). Later entries win a key that two entries set differently, and every such conflict is " +
+		"reported; --strict fails instead of saving.\n\n" +
+		"Prints the merges and asks for confirmation before saving unless --yes is set. --dry-run only prints them.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting registry dedupe")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		strict, _ := cmd.Flags().GetBool("strict")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindTable && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		target, err := loadRegistryTarget(cmd, cwd)
+		if err != nil {
+			return err
+		}
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		entries := refreshDuplicateStatuses(cmd.Context(), adapter, target.reg.Entries)
+		deduped, conflicts := registry.Dedupe(entries)
+		report := registryDedupeReport{
+			Merges:    registryDedupeMerges(entries, deduped),
+			Conflicts: conflicts,
+		}
+		if report.Conflicts == nil {
+			report.Conflicts = []registry.DedupeConflict{}
+		}
+		if len(report.Merges) == 0 {
+			infof(cmd, "no duplicate registry entries to merge")
+			return nil
+		}
+
+		switch mode.kind {
+		case outputKindJSON:
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
+				return err
+			}
+		default:
+			if err := writeRegistryDedupeTable(cmd, report.Merges, noHeaders); err != nil {
+				return err
+			}
+			for _, conflict := range report.Conflicts {
+				warnf(cmd, "%s: %s %q is %q, replacing %q", conflict.RepoID, conflict.Field, conflict.Key, conflict.Kept, conflict.Dropped)
+			}
+		}
+		if strict && len(report.Conflicts) > 0 {
+			return fmt.Errorf("%d label or annotation conflicts; resolve them or rerun without --strict", len(report.Conflicts))
+		}
+		if dryRun {
+			return nil
+		}
+		dropped := len(entries) - len(deduped)
+		if !assumeYes(cmd) {
+			confirmed, err := confirmWithPrompt(cmd, fmt.Sprintf("Drop %d duplicate registry entries? [y/N]: ", dropped))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				infof(cmd, "registry dedupe cancelled")
+				return nil
+			}
+		}
+
+		target.reg.Entries = deduped
+		target.reg.UpdatedAt = time.Now()
+		if err := target.save(); err != nil {
+			return err
+		}
+		infof(cmd, "dropped %d duplicate registry entries across %d repos", dropped, len(report.Merges))
+		return nil
+	},
+}
+
+// registryTarget is the registry a maintenance command edits, and where it is
+// saved: the --registry file when set, otherwise the config that embeds it.
+type registryTarget struct {
+	reg      *registry.Registry
+	cfg      *config.Config
+	cfgPath  string
+	override string
+}
+
+func loadRegistryTarget(cmd *cobra.Command, cwd string) (*registryTarget, error) {
+	cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfig(cmd, cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	debugf(cmd, "using config %s", cfgPath)
+
+	target := &registryTarget{cfg: cfg, cfgPath: cfgPath}
+	target.override, _ = cmd.Flags().GetString("registry")
+	if target.override != "" {
+		target.reg, err = registry.Load(target.override)
+		if err != nil {
+			return nil, err
+		}
+		return target, nil
+	}
+	target.reg = cfg.Registry
+	if target.reg == nil {
+		return nil, fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+	}
+	return target, nil
+}
+
+func (t *registryTarget) save() error {
+	if t.override != "" {
+		return registry.Save(t.reg, t.override)
+	}
+	t.cfg.Registry = t.reg
+	return config.Save(t.cfg, t.cfgPath)
+}
+
+// registryDedupeMerge is one repo_id whose duplicate entries were collapsed.
+type registryDedupeMerge struct {
+	RepoID  string   `json:"repo_id"`
+	Kept    []string `json:"kept"`
+	Dropped []string `json:"dropped"`
+}
+
+type registryDedupeReport struct {
+	Merges    []registryDedupeMerge     `json:"merges"`
+	Conflicts []registry.DedupeConflict `json:"conflicts"`
+}
+
+// refreshDuplicateStatuses returns a copy of entries where every entry sharing
+// its repo_id has its status checked against disk, so registry.Dedupe keeps
+// the checkouts that still exist. Moved entries are left for reconcile paths.
+func refreshDuplicateStatuses(ctx context.Context, adapter vcs.Adapter, entries []registry.Entry) []registry.Entry {
+	counts := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		counts[entry.RepoID]++
+	}
+	out := append([]registry.Entry(nil), entries...)
+	for i := range out {
+		if counts[out[i].RepoID] < 2 || out[i].Status == registry.StatusMoved {
+			continue
+		}
+		if isRepo, err := adapter.IsRepo(ctx, out[i].Path); err == nil && isRepo {
+			out[i].Status = registry.StatusPresent
+		} else {
+			out[i].Status = registry.StatusMissing
+		}
+	}
+	return out
+}
+
+// registryDedupeMerges pairs the entries registry.Dedupe dropped with the
+// entries it kept for the same repo_id.
+func registryDedupeMerges(before, after []registry.Entry) []registryDedupeMerge {
+	type entryKey struct{ repoID, checkoutID, path string }
+	kept := make(map[entryKey]bool, len(after))
+	for _, entry := range after {
+		kept[entryKey{entry.RepoID, entry.CheckoutID, entry.Path}] = true
+	}
+	byRepoID := map[string]*registryDedupeMerge{}
+	var order []string
+	for _, entry := range before {
+		if kept[entryKey{entry.RepoID, entry.CheckoutID, entry.Path}] {
+			continue
+		}
+		merge, ok := byRepoID[entry.RepoID]
+		if !ok {
+			merge = &registryDedupeMerge{RepoID: entry.RepoID}
+			byRepoID[entry.RepoID] = merge
+			order = append(order, entry.RepoID)
+		}
+		merge.Dropped = append(merge.Dropped, entry.Path)
+	}
+	for _, entry := range after {
+		if merge, ok := byRepoID[entry.RepoID]; ok {
+			merge.Kept = append(merge.Kept, entry.Path)
+		}
+	}
+	sort.Strings(order)
+	merges := make([]registryDedupeMerge, 0, len(order))
+	for _, repoID := range order {
+		merges = append(merges, *byRepoID[repoID])
+	}
+	return merges
+}
+
+func writeRegistryDedupeTable(cmd *cobra.Command, merges []registryDedupeMerge, noHeaders bool) error {
+	rows := make([][]string, 0, len(merges))
+	for _, merge := range merges {
+		rows = append(rows, []string{merge.RepoID, strings.Join(merge.Kept, ","), strings.Join(merge.Dropped, ",")})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"REPO", "KEPT", "DROPPED"}, rows)
+}
+
+// registryMigration is one registry entry rewritten by registry migrate.
+// Index points into the registry entries the migration was planned against.
+type registryMigration struct {
+	Index   int    `json:"-"`
+	RepoID  string `json:"repo_id"`
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+	Status  string `json:"status"`
+}
+
+// planRegistryMigration maps every entry at or under from to the same relative
+// path under to, and decides its new status by checking whether a repository
+// exists there. The registry itself is not modified.
+func planRegistryMigration(ctx context.Context, adapter vcs.Adapter, reg *registry.Registry, from, to string) []registryMigration {
+	migrations := []registryMigration{}
+	for i, entry := range reg.Entries {
+		newPath := ""
+		if filepath.Clean(entry.Path) == filepath.Clean(from) {
+			newPath = to
+		} else if rel, ok := relWithin(from, entry.Path); ok {
+			newPath = filepath.Join(to, filepath.FromSlash(rel))
+		} else {
+			continue
+		}
+		status := registry.StatusMissing
+		if isRepo, err := adapter.IsRepo(ctx, newPath); err == nil && isRepo {
+			status = registry.StatusPresent
+		}
+		migrations = append(migrations, registryMigration{
+			Index:   i,
+			RepoID:  entry.RepoID,
+			OldPath: entry.Path,
+			NewPath: newPath,
+			Status:  string(status),
+		})
+	}
+	return migrations
+}
+
+func applyRegistryMigration(reg *registry.Registry, migrations []registryMigration, now time.Time) {
+	for _, migration := range migrations {
+		entry := &reg.Entries[migration.Index]
+		entry.Path = migration.NewPath
+		entry.Status = registry.EntryStatus(migration.Status)
+		if entry.Status == registry.StatusPresent {
+			entry.LastSeen = now
+		}
+	}
+	reg.UpdatedAt = now
+}
+
+// registryPathsRoot returns the deepest directory shared by every registry
+// path, to hint at the right --from when nothing matched.
+func registryPathsRoot(reg *registry.Registry) string {
+	root := ""
+	for i, entry := range reg.Entries {
+		if i == 0 {
+			root = filepath.Dir(filepath.Clean(entry.Path))
+			continue
+		}
+		root = commonPathRoot(root, entry.Path)
+	}
+	return root
+}
+
+func writeRegistryMigrationTable(cmd *cobra.Command, migrations []registryMigration, noHeaders bool) error {
+	rows := make([][]string, 0, len(migrations))
+	for _, migration := range migrations {
+		rows = append(rows, []string{migration.RepoID, migration.OldPath, migration.NewPath, migration.Status})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"REPO", "OLD_PATH", "NEW_PATH", "STATUS"}, rows)
+}
+
+const (
+	registryDiffOnlyInA = "only_in_a"
+	registryDiffOnlyInB = "only_in_b"
+	registryDiffChanged = "changed"
+)
+
+// registryDiffEntry is one row of a registry diff. Path and RemoteURL fields
+// are populated for whichever side(s) recorded the repo.
+type registryDiffEntry struct {
+	RepoID     string   `json:"repo_id"`
+	Change     string   `json:"change"`
+	PathA      string   `json:"path_a,omitempty"`
+	PathB      string   `json:"path_b,omitempty"`
+	RemoteURLA string   `json:"remote_url_a,omitempty"`
+	RemoteURLB string   `json:"remote_url_b,omitempty"`
+	Fields     []string `json:"fields,omitempty"`
+}
+
+type registryDiff struct {
+	OnlyInA []registryDiffEntry `json:"only_in_a"`
+	OnlyInB []registryDiffEntry `json:"only_in_b"`
+	Changed []registryDiffEntry `json:"changed"`
+}
+
+// loadRegistryForDiff accepts either a standalone registry file or a config
+// file, so a registry embedded in another machine's .repokeeper.yaml can be
+// compared without extracting it first.
+func loadRegistryForDiff(path string) (*registry.Registry, error) {
+	reg, err := registry.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load registry %q: %w", path, err)
+	}
+	if len(reg.Entries) == 0 {
+		if cfg, cfgErr := config.Load(path); cfgErr == nil && cfg.Registry != nil {
+			return cfg.Registry, nil
+		}
+	}
+	return reg, nil
+}
+
+func diffRegistries(a, b *registry.Registry) registryDiff {
+	diff := registryDiff{
+		OnlyInA: []registryDiffEntry{},
+		OnlyInB: []registryDiffEntry{},
+		Changed: []registryDiffEntry{},
+	}
+	matchedA := make(map[int]bool, len(a.Entries))
+	for _, entryB := range b.Entries {
+		idx, _ := mergeRegistryMatchIndex(a, entryB)
+		if idx < 0 || matchedA[idx] {
+			diff.OnlyInB = append(diff.OnlyInB, registryDiffEntry{
+				RepoID:     entryB.RepoID,
+				Change:     registryDiffOnlyInB,
+				PathB:      entryB.Path,
+				RemoteURLB: entryB.RemoteURL,
+			})
+			continue
+		}
+		matchedA[idx] = true
+		entryA := a.Entries[idx]
+		if !registryEntriesConflict(entryA, entryB) {
+			continue
+		}
+		diff.Changed = append(diff.Changed, registryDiffEntry{
+			RepoID:     entryB.RepoID,
+			Change:     registryDiffChanged,
+			PathA:      entryA.Path,
+			PathB:      entryB.Path,
+			RemoteURLA: entryA.RemoteURL,
+			RemoteURLB: entryB.RemoteURL,
+			Fields:     registryEntryChangedFields(entryA, entryB),
+		})
+	}
+	for i, entryA := range a.Entries {
+		if matchedA[i] {
+			continue
+		}
+		diff.OnlyInA = append(diff.OnlyInA, registryDiffEntry{
+			RepoID:     entryA.RepoID,
+			Change:     registryDiffOnlyInA,
+			PathA:      entryA.Path,
+			RemoteURLA: entryA.RemoteURL,
+		})
+	}
+	for _, rows := range [][]registryDiffEntry{diff.OnlyInA, diff.OnlyInB, diff.Changed} {
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].RepoID != rows[j].RepoID {
+				return rows[i].RepoID < rows[j].RepoID
+			}
+			return rows[i].PathA+rows[i].PathB < rows[j].PathA+rows[j].PathB
+		})
+	}
+	return diff
+}
+
+// registryEntryChangedFields names the fields registryEntriesConflict compares
+// that differ between a and b.
+func registryEntryChangedFields(a, b registry.Entry) []string {
+	var fields []string
+	if strings.TrimSpace(a.Path) != strings.TrimSpace(b.Path) {
+		fields = append(fields, "path")
+	}
+	if strings.TrimSpace(a.RemoteURL) != strings.TrimSpace(b.RemoteURL) {
+		fields = append(fields, "remote_url")
+	}
+	if strings.TrimSpace(a.Branch) != strings.TrimSpace(b.Branch) {
+		fields = append(fields, "branch")
+	}
+	if strings.TrimSpace(a.Type) != strings.TrimSpace(b.Type) {
+		fields = append(fields, "type")
+	}
+	if !stringMapsEqual(a.Labels, b.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !stringMapsEqual(a.Annotations, b.Annotations) {
+		fields = append(fields, "annotations")
+	}
+	return fields
+}
+
+func writeRegistryDiffTable(cmd *cobra.Command, diff registryDiff, noHeaders bool) error {
+	rows := make([][]string, 0, len(diff.OnlyInA)+len(diff.OnlyInB)+len(diff.Changed))
+	for _, group := range [][]registryDiffEntry{diff.OnlyInA, diff.OnlyInB, diff.Changed} {
+		for _, entry := range group {
+			rows = append(rows, []string{
+				entry.Change,
+				entry.RepoID,
+				dashIfEmpty(entry.PathA),
+				dashIfEmpty(entry.PathB),
+				dashIfEmpty(strings.Join(entry.Fields, ",")),
+			})
+		}
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"CHANGE", "REPO", "PATH_A", "PATH_B", "FIELDS"}, rows)
+}
+
+func dashIfEmpty(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "-"
+	}
+	return value
+}
+
+func init() {
+	addFormatFlag(registryDiffCmd, "output format: table or json")
+	addNoHeadersFlag(registryDiffCmd)
+
+	registryMigrateCmd.Flags().String("from", "", "old workspace root to rewrite")
+	registryMigrateCmd.Flags().String("to", "", "new workspace root")
+	registryMigrateCmd.Flags().Bool("dry-run", false, "print the before/after paths without saving")
+	registryMigrateCmd.Flags().String("registry", "", "override registry file path")
+	addFormatFlag(registryMigrateCmd, "output format: table or json")
+	addNoHeadersFlag(registryMigrateCmd)
+
+	registryDedupeCmd.Flags().Bool("dry-run", false, "print the merges without saving")
+	registryDedupeCmd.Flags().Bool("strict", false, "fail instead of saving when merged entries set a label or annotation differently")
+	registryDedupeCmd.Flags().String("registry", "", "override registry file path")
+	addFormatFlag(registryDedupeCmd, "output format: table or json")
+	addNoHeadersFlag(registryDedupeCmd)
+
+	registryCmd.AddCommand(registryDedupeCmd)
+	registryCmd.AddCommand(registryDiffCmd)
+	registryCmd.AddCommand(registryMigrateCmd)
+	rootCmd.AddCommand(registryCmd)
+}
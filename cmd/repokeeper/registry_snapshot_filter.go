@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"slices"
+
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/selector"
+	"github.com/spf13/cobra"
+)
+
+// registrySnapshotFilter hides some registry entries from the engine. The
+// engine works on snapshot; merged puts the hidden entries back in their
+// original positions so saving the registry never drops them.
+type registrySnapshotFilter struct {
+	full     *registry.Registry
+	snapshot *registry.Registry
+	hidden   map[int]registry.Entry
+}
+
+// newRegistrySnapshotFilter reads --exclude-remote-host and
+// --include-archived and hides entries on excluded hosts, and archived entries
+// unless --include-archived, from reg.
+func newRegistrySnapshotFilter(cmd *cobra.Command, reg *registry.Registry) (*registrySnapshotFilter, error) {
+	raw, _ := cmd.Flags().GetStringArray("exclude-remote-host")
+	patterns, err := selector.ParseHostPatterns(raw, "--exclude-remote-host")
+	if err != nil {
+		return nil, err
+	}
+	includeArchived, _ := cmd.Flags().GetBool("include-archived")
+	return filterRegistrySnapshot(reg, hideArchived(includeArchived), hideRemoteHosts(patterns)), nil
+}
+
+// filterRegistrySnapshot splits reg into the entries no hide predicate
+// matches and the hidden rest. When nothing is hidden the snapshot is reg
+// itself.
+func filterRegistrySnapshot(reg *registry.Registry, hides ...func(registry.Entry) bool) *registrySnapshotFilter {
+	f := &registrySnapshotFilter{full: reg, snapshot: reg}
+	if reg == nil {
+		return f
+	}
+	hidden := make(map[int]registry.Entry)
+	kept := make([]registry.Entry, 0, len(reg.Entries))
+	for idx, entry := range reg.Entries {
+		if slices.ContainsFunc(hides, func(hide func(registry.Entry) bool) bool { return hide != nil && hide(entry) }) {
+			hidden[idx] = entry
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if len(hidden) == 0 {
+		return f
+	}
+	f.hidden = hidden
+	f.snapshot = &registry.Registry{UpdatedAt: reg.UpdatedAt, Entries: kept}
+	return f
+}
+
+// hideArchived hides archived entries unless includeArchived is set.
+func hideArchived(includeArchived bool) func(registry.Entry) bool {
+	if includeArchived {
+		return nil
+	}
+	return func(entry registry.Entry) bool { return entry.Archived }
+}
+
+// hideRemoteHosts hides entries whose remote host matches one of patterns.
+func hideRemoteHosts(patterns []string) func(registry.Entry) bool {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return func(entry registry.Entry) bool {
+		return selector.HostMatchesAny(selector.RemoteHost(entry.RepoID, entry.RemoteURL), patterns)
+	}
+}
+
+// merged returns the registry to persist: the snapshot, including any changes
+// the engine made to it, with the hidden entries reinserted.
+func (f *registrySnapshotFilter) merged() *registry.Registry {
+	if len(f.hidden) == 0 {
+		return f.snapshot
+	}
+	total := len(f.snapshot.Entries) + len(f.hidden)
+	entries := make([]registry.Entry, 0, total)
+	next := 0
+	for idx := 0; len(entries) < total; idx++ {
+		if entry, ok := f.hidden[idx]; ok {
+			entries = append(entries, entry)
+			continue
+		}
+		if next < len(f.snapshot.Entries) {
+			entries = append(entries, f.snapshot.Entries[next])
+			next++
+		}
+	}
+	f.full.Entries = entries
+	f.full.UpdatedAt = f.snapshot.UpdatedAt
+	return f.full
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestFilterRegistrySnapshotKeepsHiddenEntriesOnMerge(t *testing.T) {
+	t.Parallel()
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/work/a"},
+		{RepoID: "gitlab.com/org/b", Path: "/work/b", RemoteURL: "git@gitlab.com:org/b.git"},
+		{RepoID: "local:/work/c", Path: "/work/c"},
+		{RepoID: "github.com/org/d", Path: "/work/d", RemoteURL: "https://git.corp.example/org/d.git"},
+	}}
+	filter := filterRegistrySnapshot(reg, hideArchived(false), hideRemoteHosts([]string{"gitlab.com", "*.corp.example"}))
+	if got := entryPaths(filter.snapshot.Entries); got != "/work/a,/work/c" {
+		t.Fatalf("unexpected snapshot %s", got)
+	}
+
+	filter.snapshot.Entries[0].Status = registry.StatusMissing
+	filter.snapshot.Entries = append(filter.snapshot.Entries, registry.Entry{RepoID: "github.com/org/e", Path: "/work/e"})
+	merged := filter.merged()
+	if got := entryPaths(merged.Entries); got != "/work/a,/work/b,/work/c,/work/d,/work/e" {
+		t.Fatalf("expected hidden entries back in place, got %s", got)
+	}
+	if merged.Entries[0].Status != registry.StatusMissing {
+		t.Fatalf("expected snapshot changes to carry over, got %+v", merged.Entries[0])
+	}
+
+	if unfiltered := filterRegistrySnapshot(reg, hideArchived(false), hideRemoteHosts(nil)); unfiltered.snapshot != reg || unfiltered.merged() != reg {
+		t.Fatal("expected no patterns to use the registry as-is")
+	}
+}
+
+func entryPaths(entries []registry.Entry) string {
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		paths = append(paths, entry.Path)
+	}
+	return strings.Join(paths, ",")
+}
+
+func TestFilterRegistrySnapshotHidesArchivedUnlessIncluded(t *testing.T) {
+	t.Parallel()
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/work/a"},
+		{RepoID: "github.com/org/old", Path: "/work/old", Archived: true},
+		{RepoID: "gitlab.com/org/b", Path: "/work/b", RemoteURL: "git@gitlab.com:org/b.git"},
+	}}
+	filter := filterRegistrySnapshot(reg, hideArchived(false))
+	if got := entryPaths(filter.snapshot.Entries); got != "/work/a,/work/b" {
+		t.Fatalf("expected archived entry hidden by default, got %s", got)
+	}
+	if got := entryPaths(filter.merged().Entries); got != "/work/a,/work/old,/work/b" {
+		t.Fatalf("expected archived entry kept on merge, got %s", got)
+	}
+
+	included := filterRegistrySnapshot(reg, hideArchived(true), hideRemoteHosts([]string{"gitlab.com"}))
+	if got := entryPaths(included.snapshot.Entries); got != "/work/a,/work/old" {
+		t.Fatalf("expected archived entry with --include-archived, got %s", got)
+	}
+}
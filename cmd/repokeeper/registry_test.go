@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestDiffRegistriesReportsAddedRemovedAndChangedEntries(t *testing.T) {
+	a := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/same", Path: "/a/same", RemoteURL: "git@github.com:org/same.git"},
+		{RepoID: "github.com/org/removed", Path: "/a/removed", RemoteURL: "git@github.com:org/removed.git"},
+		{RepoID: "github.com/org/changed", Path: "/a/changed", RemoteURL: "git@github.com:org/changed.git", Branch: "main", Labels: map[string]string{"team": "a"}},
+	}}
+	b := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/same", Path: "/a/same", RemoteURL: "git@github.com:org/same.git"},
+		{RepoID: "github.com/org/changed", Path: "/b/changed", RemoteURL: "git@github.com:org/changed.git", Branch: "develop", Labels: map[string]string{"team": "b"}},
+		{RepoID: "github.com/org/added", Path: "/b/added", RemoteURL: "git@github.com:org/added.git"},
+	}}
+
+	diff := diffRegistries(a, b)
+	if len(diff.OnlyInA) != 1 || diff.OnlyInA[0].RepoID != "github.com/org/removed" {
+		t.Fatalf("unexpected only_in_a: %+v", diff.OnlyInA)
+	}
+	if len(diff.OnlyInB) != 1 || diff.OnlyInB[0].RepoID != "github.com/org/added" || diff.OnlyInB[0].PathB != "/b/added" {
+		t.Fatalf("unexpected only_in_b: %+v", diff.OnlyInB)
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("expected one changed entry, got %+v", diff.Changed)
+	}
+	changed := diff.Changed[0]
+	if changed.RepoID != "github.com/org/changed" || changed.PathA != "/a/changed" || changed.PathB != "/b/changed" {
+		t.Fatalf("unexpected changed entry: %+v", changed)
+	}
+	if got := strings.Join(changed.Fields, ","); got != "path,branch,labels" {
+		t.Fatalf("unexpected changed fields: %q", got)
+	}
+}
+
+func TestRegistryDiffCommandReadsRegistryAndConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	regPath := filepath.Join(dir, "registry.yaml")
+	if err := registry.Save(&registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/one", Path: "/repos/one", RemoteURL: "git@github.com:org/one.git", Status: registry.StatusPresent},
+	}}, regPath); err != nil {
+		t.Fatalf("save registry: %v", err)
+	}
+	cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/one", Path: "/repos/one", RemoteURL: "git@github.com:org/one.git", Type: "mirror", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/two", Path: "/repos/two", RemoteURL: "git@github.com:org/two.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	registryDiffCmd.SetOut(out)
+	registryDiffCmd.SetContext(context.Background())
+	defer registryDiffCmd.SetOut(os.Stdout)
+	_ = registryDiffCmd.Flags().Set("format", "json")
+	defer func() { _ = registryDiffCmd.Flags().Set("format", "table") }()
+
+	if err := registryDiffCmd.RunE(registryDiffCmd, []string{regPath, cfgPath}); err != nil {
+		t.Fatalf("registry diff: %v", err)
+	}
+	var diff registryDiff
+	if err := json.Unmarshal(out.Bytes(), &diff); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if len(diff.OnlyInA) != 0 || len(diff.OnlyInB) != 1 || diff.OnlyInB[0].RepoID != "github.com/org/two" {
+		t.Fatalf("unexpected added/removed entries: %+v", diff)
+	}
+	if len(diff.Changed) != 1 || strings.Join(diff.Changed[0].Fields, ",") != "type" {
+		t.Fatalf("unexpected changed entries: %+v", diff.Changed)
+	}
+
+	out.Reset()
+	_ = registryDiffCmd.Flags().Set("format", "table")
+	if err := registryDiffCmd.RunE(registryDiffCmd, []string{regPath, cfgPath}); err != nil {
+		t.Fatalf("registry diff table: %v", err)
+	}
+	if !strings.Contains(out.String(), "CHANGE") || !strings.Contains(out.String(), "only_in_b") || !strings.Contains(out.String(), "github.com/org/two") {
+		t.Fatalf("unexpected table output:\n%s", out.String())
+	}
+}
+
+func TestRegistryMigrateRewritesPathsUnderTheOldRoot(t *testing.T) {
+	tmp := t.TempDir()
+	oldRoot := filepath.Join(tmp, "old")
+	newRoot := filepath.Join(tmp, "new")
+	moved := filepath.Join(newRoot, "team", "moved")
+	mustRunGit(t, tmp, "init", moved)
+	outside := filepath.Join(tmp, "elsewhere", "repo")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/moved", Path: filepath.Join(oldRoot, "team", "moved"), Status: registry.StatusMissing},
+		{RepoID: "github.com/org/lost", Path: filepath.Join(oldRoot, "lost"), Status: registry.StatusMissing},
+		{RepoID: "github.com/org/outside", Path: outside, Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	registryMigrateCmd.SetOut(out)
+	registryMigrateCmd.SetContext(context.Background())
+	defer registryMigrateCmd.SetOut(os.Stdout)
+	_ = registryMigrateCmd.Flags().Set("from", oldRoot)
+	_ = registryMigrateCmd.Flags().Set("to", newRoot)
+	_ = registryMigrateCmd.Flags().Set("dry-run", "true")
+	defer func() {
+		_ = registryMigrateCmd.Flags().Set("from", "")
+		_ = registryMigrateCmd.Flags().Set("to", "")
+		_ = registryMigrateCmd.Flags().Set("dry-run", "false")
+	}()
+
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if err := registryMigrateCmd.RunE(registryMigrateCmd, nil); err != nil {
+		t.Fatalf("registry migrate --dry-run: %v", err)
+	}
+	table := out.String()
+	for _, want := range []string{"OLD_PATH", "NEW_PATH", moved, filepath.Join(newRoot, "lost")} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in dry-run table:\n%s", want, table)
+		}
+	}
+	if strings.Contains(table, "github.com/org/outside") {
+		t.Fatalf("expected entries outside --from to be left out:\n%s", table)
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected --dry-run not to modify the config")
+	}
+
+	_ = registryMigrateCmd.Flags().Set("dry-run", "false")
+	restoreYes := withAssumeYes(t, true)
+	defer restoreYes()
+	out.Reset()
+	if err := registryMigrateCmd.RunE(registryMigrateCmd, nil); err != nil {
+		t.Fatalf("registry migrate: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	got := map[string]registry.Entry{}
+	for _, entry := range saved.Registry.Entries {
+		got[entry.RepoID] = entry
+	}
+	if entry := got["github.com/org/moved"]; entry.Path != moved || entry.Status != registry.StatusPresent {
+		t.Fatalf("expected moved repo to be present at its new path, got %+v", entry)
+	}
+	if entry := got["github.com/org/lost"]; entry.Path != filepath.Join(newRoot, "lost") || entry.Status != registry.StatusMissing {
+		t.Fatalf("expected a rewritten path without a repo to stay missing, got %+v", entry)
+	}
+	if entry := got["github.com/org/outside"]; entry.Path != outside {
+		t.Fatalf("expected entry outside --from to keep its path, got %+v", entry)
+	}
+}
+
+func TestRegistryMigrateDeclinedPromptLeavesRegistryUntouched(t *testing.T) {
+	tmp := t.TempDir()
+	oldRoot := filepath.Join(tmp, "old")
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: filepath.Join(oldRoot, "repo"), Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	restoreYes := withAssumeYes(t, false)
+	defer restoreYes()
+
+	registryMigrateCmd.SetOut(&bytes.Buffer{})
+	registryMigrateCmd.SetErr(&bytes.Buffer{})
+	registryMigrateCmd.SetIn(strings.NewReader("n\n"))
+	registryMigrateCmd.SetContext(context.Background())
+	defer func() {
+		registryMigrateCmd.SetOut(os.Stdout)
+		registryMigrateCmd.SetErr(os.Stderr)
+		registryMigrateCmd.SetIn(os.Stdin)
+		_ = registryMigrateCmd.Flags().Set("from", "")
+		_ = registryMigrateCmd.Flags().Set("to", "")
+	}()
+	_ = registryMigrateCmd.Flags().Set("from", oldRoot)
+	_ = registryMigrateCmd.Flags().Set("to", filepath.Join(tmp, "new"))
+
+	if err := registryMigrateCmd.RunE(registryMigrateCmd, nil); err != nil {
+		t.Fatalf("registry migrate: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if saved.Registry.Entries[0].Path != filepath.Join(oldRoot, "repo") {
+		t.Fatalf("expected declined migration to keep the old path, got %+v", saved.Registry.Entries[0])
+	}
+
+	_ = registryMigrateCmd.Flags().Set("to", oldRoot)
+	if err := registryMigrateCmd.RunE(registryMigrateCmd, nil); err == nil {
+		t.Fatal("expected identical --from and --to to be rejected")
+	}
+}
+
+func TestRegistryDedupeDropsStaleDuplicates(t *testing.T) {
+	tmp := t.TempDir()
+	live := filepath.Join(tmp, "src", "repo")
+	mustRunGit(t, tmp, "init", live)
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	// The stale entry still claims to be present; dedupe checks the disk.
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: filepath.Join(tmp, "old", "repo"), Status: registry.StatusPresent, Labels: map[string]string{"team": "core"}},
+		{RepoID: "github.com/org/repo", Path: live, Status: registry.StatusPresent, Labels: map[string]string{"team": "infra"}},
+		{RepoID: "github.com/org/other", Path: filepath.Join(tmp, "other"), Status: registry.StatusMissing},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	registryDedupeCmd.SetOut(out)
+	registryDedupeCmd.SetErr(errOut)
+	registryDedupeCmd.SetContext(context.Background())
+	defer registryDedupeCmd.SetOut(os.Stdout)
+	defer registryDedupeCmd.SetErr(os.Stderr)
+	defer func() {
+		_ = registryDedupeCmd.Flags().Set("dry-run", "false")
+		_ = registryDedupeCmd.Flags().Set("strict", "false")
+	}()
+
+	_ = registryDedupeCmd.Flags().Set("dry-run", "true")
+	if err := registryDedupeCmd.RunE(registryDedupeCmd, nil); err != nil {
+		t.Fatalf("registry dedupe --dry-run: %v", err)
+	}
+	if !strings.Contains(out.String(), live) || !strings.Contains(out.String(), filepath.Join(tmp, "old", "repo")) {
+		t.Fatalf("expected kept and dropped paths in the table:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), `labels "team" is "infra", replacing "core"`) {
+		t.Fatalf("expected the label conflict to be reported, got %q", errOut.String())
+	}
+	if saved, _ := config.Load(cfgPath); len(saved.Registry.Entries) != 3 {
+		t.Fatalf("expected --dry-run not to save, got %+v", saved.Registry.Entries)
+	}
+
+	_ = registryDedupeCmd.Flags().Set("dry-run", "false")
+	_ = registryDedupeCmd.Flags().Set("strict", "true")
+	restoreYes := withAssumeYes(t, true)
+	defer restoreYes()
+	if err := registryDedupeCmd.RunE(registryDedupeCmd, nil); err == nil || !strings.Contains(err.Error(), "conflicts") {
+		t.Fatalf("expected --strict to fail on the label conflict, got %v", err)
+	}
+	if saved, _ := config.Load(cfgPath); len(saved.Registry.Entries) != 3 {
+		t.Fatalf("expected --strict not to save, got %+v", saved.Registry.Entries)
+	}
+
+	_ = registryDedupeCmd.Flags().Set("strict", "false")
+	if err := registryDedupeCmd.RunE(registryDedupeCmd, nil); err != nil {
+		t.Fatalf("registry dedupe: %v", err)
+	}
+	saved, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if len(saved.Registry.Entries) != 2 {
+		t.Fatalf("expected the stale duplicate to be dropped, got %+v", saved.Registry.Entries)
+	}
+	entry := saved.Registry.FindByRepoID("github.com/org/repo")
+	if entry == nil || entry.Path != live || entry.Labels["team"] != "infra" {
+		t.Fatalf("expected the live checkout to keep its labels, got %+v", entry)
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// registryProblemEmptyRepoID is the one registry validate check doctor does
+// not have; missing paths and repo ID mismatches share doctor's check names.
+const registryProblemEmptyRepoID = "empty_repo_id"
+
+// registryProblem is one inconsistency found by registry validate.
+type registryProblem struct {
+	Type    string `json:"type"`
+	RepoID  string `json:"repo_id"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type registryValidateReport struct {
+	Checked  int               `json:"checked"`
+	Problems []registryProblem `json:"problems"`
+}
+
+var registryValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check registry entries for missing paths and inconsistent repo IDs",
+	Long: "Checks every registry entry without changing anything: the path must exist, the repo_id must not be empty, and " +
+		"for entries with a remote URL the repo_id must be the normalized form of that URL. local: entries have no remote " +
+		"to derive their ID from and only get the path check.\n\n" +
+		"Unlike doctor, every missing path counts, including entries already marked missing, so the command suits scripts " +
+		"and CI that need a registry whose entries all resolve. Exits 1 when any problem is found.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting registry validate")
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		if mode.kind != outputKindTable && mode.kind != outputKindJSON {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		target, err := loadRegistryTarget(cmd, cwd)
+		if err != nil {
+			return err
+		}
+		problems, err := validateRegistry(target.reg)
+		if err != nil {
+			return err
+		}
+		report := registryValidateReport{Checked: len(target.reg.Entries), Problems: problems}
+
+		switch mode.kind {
+		case outputKindJSON:
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), string(data)); err != nil {
+				return err
+			}
+		default:
+			if len(problems) > 0 {
+				if err := writeRegistryProblemsTable(cmd, problems, noHeaders); err != nil {
+					return err
+				}
+			}
+		}
+		if len(problems) > 0 {
+			raiseExitCode(cmd, 1)
+			infof(cmd, "registry validate: %d problems in %d entries", len(problems), report.Checked)
+			return nil
+		}
+		infof(cmd, "registry is valid: %d entries checked", report.Checked)
+		return nil
+	},
+}
+
+// validateRegistry reports the problems in reg in entry order, repo ID
+// problems before path problems. Paths are checked with ValidatePaths on a
+// copy, so reg keeps the statuses it was loaded with.
+func validateRegistry(reg *registry.Registry) ([]registryProblem, error) {
+	checked := cloneRegistry(reg)
+	if err := checked.ValidatePaths(); err != nil {
+		return nil, err
+	}
+	problems := []registryProblem{}
+	for _, entry := range checked.Entries {
+		var found []registryProblem
+		repoID := strings.TrimSpace(entry.RepoID)
+		remoteURL := strings.TrimSpace(entry.RemoteURL)
+		switch {
+		case repoID == "":
+			found = append(found, registryProblem{Type: registryProblemEmptyRepoID, Message: "entry has no repo_id"})
+		case remoteURL != "" && !strings.HasPrefix(repoID, "local:"):
+			if normalized := gitx.NormalizeURL(remoteURL); normalized != repoID {
+				found = append(found, registryProblem{
+					Type:    doctorCheckRepoIDMismatch,
+					Message: fmt.Sprintf("remote_url %s normalizes to %q", remoteURL, normalized),
+				})
+			}
+		}
+		if entry.Status == registry.StatusMissing {
+			found = append(found, registryProblem{Type: doctorCheckMissingPath, Message: "path does not exist"})
+		}
+		for _, problem := range found {
+			problem.RepoID = entry.RepoID
+			problem.Path = entry.Path
+			problems = append(problems, problem)
+		}
+	}
+	return problems, nil
+}
+
+func writeRegistryProblemsTable(cmd *cobra.Command, problems []registryProblem, noHeaders bool) error {
+	rows := make([][]string, 0, len(problems))
+	for _, problem := range problems {
+		rows = append(rows, []string{problem.Type, dashIfEmpty(problem.RepoID), dashIfEmpty(problem.Path), problem.Message})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"TYPE", "REPO", "PATH", "MESSAGE"}, rows)
+}
+
+func init() {
+	registryValidateCmd.Flags().String("registry", "", "override registry file path")
+	addFormatFlag(registryValidateCmd, "output format: table or json")
+	addNoHeadersFlag(registryValidateCmd)
+	registryCmd.AddCommand(registryValidateCmd)
+}
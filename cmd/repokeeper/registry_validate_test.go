@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestValidateRegistryReportsEachProblemType(t *testing.T) {
+	tmp := t.TempDir()
+	present := filepath.Join(tmp, "present")
+	if err := os.MkdirAll(present, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	missing := filepath.Join(tmp, "missing")
+
+	valid := []registry.Entry{
+		{RepoID: "github.com/org/api", Path: present, RemoteURL: "git@github.com:org/api.git", Status: registry.StatusPresent},
+		{RepoID: "local:" + filepath.ToSlash(present), Path: present, RemoteURL: "https://github.com/org/elsewhere.git"},
+		{RepoID: "github.com/org/no-remote", Path: present},
+	}
+	problems, err := validateRegistry(&registry.Registry{Entries: valid})
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected a valid registry, got %+v", problems)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		entry registry.Entry
+		want  []string
+	}{
+		{name: "empty repo id", entry: registry.Entry{Path: present, RemoteURL: "git@github.com:org/api.git"}, want: []string{registryProblemEmptyRepoID}},
+		{name: "repo id mismatch", entry: registry.Entry{RepoID: "github.com/org/old", Path: present, RemoteURL: "git@github.com:org/new.git"}, want: []string{doctorCheckRepoIDMismatch}},
+		{name: "missing path", entry: registry.Entry{RepoID: "github.com/org/api", Path: missing, RemoteURL: "git@github.com:org/api.git", Status: registry.StatusPresent}, want: []string{doctorCheckMissingPath}},
+		{name: "several at once", entry: registry.Entry{RepoID: "github.com/org/old", Path: missing, RemoteURL: "git@github.com:org/new.git"}, want: []string{doctorCheckRepoIDMismatch, doctorCheckMissingPath}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := &registry.Registry{Entries: append(append([]registry.Entry(nil), valid...), tc.entry)}
+			problems, err := validateRegistry(reg)
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			var got []string
+			for _, problem := range problems {
+				if problem.Path != tc.entry.Path || problem.RepoID != tc.entry.RepoID {
+					t.Fatalf("expected the problem on the offending entry, got %+v", problem)
+				}
+				got = append(got, problem.Type)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("got problems %v, want %v", got, tc.want)
+			}
+			if reg.Entries[len(reg.Entries)-1].Status != tc.entry.Status {
+				t.Fatalf("expected validate to leave entry statuses alone, got %+v", reg.Entries)
+			}
+		})
+	}
+}
+
+func TestRegistryValidateCommandExitsNonZeroWithoutSaving(t *testing.T) {
+	tmp := t.TempDir()
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/old", Path: tmp, RemoteURL: "git@github.com:org/new.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+	before, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	registryValidateCmd.SetOut(out)
+	registryValidateCmd.SetErr(&bytes.Buffer{})
+	registryValidateCmd.SetContext(context.Background())
+	defer registryValidateCmd.SetOut(os.Stdout)
+	defer registryValidateCmd.SetErr(os.Stderr)
+	state := runtimeStateFor(registryValidateCmd)
+	prevExitCode := state.exitCode
+	defer func() { state.exitCode = prevExitCode }()
+
+	if err := registryValidateCmd.RunE(registryValidateCmd, nil); err != nil {
+		t.Fatalf("registry validate: %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "repo_id_mismatch") || !strings.Contains(got, `normalizes to "github.com/org/new"`) {
+		t.Fatalf("expected the mismatch in the table, got %q", got)
+	}
+	if state.exitCode != 1 {
+		t.Fatalf("expected exit code 1, got %d", state.exitCode)
+	}
+	after, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("re-read config: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Fatal("expected registry validate not to modify the config")
+	}
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var remotesCmd = &cobra.Command{
+	Use:   "remotes",
+	Short: "List the remotes configured in each registered repo",
+	Long: "Reads the remotes of every registered repo and lists each configured remote with its URL.\n\n" +
+		"--only mismatch keeps only repos where no remote normalizes to the same repo ID as the registry remote_url. " +
+		"It exits 1 when any such repo is found. remotes never modifies the registry or the repos.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting remotes")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		only, _ := cmd.Flags().GetString("only")
+		mismatchOnly, err := parseRemotesFilter(only)
+		if err != nil {
+			return err
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+		reg := cfg.Registry
+		if reg == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		rows := collectRepoRemotes(cmd.Context(), adapter, reg)
+		if mismatchOnly {
+			rows = filterRemotesMismatch(rows)
+			if len(rows) > 0 {
+				raiseExitCode(cmd, 1)
+			}
+		}
+
+		switch mode.kind {
+		case outputKindTable:
+			return writeRemotesTable(cmd, rows, noHeaders)
+		case outputKindJSON:
+			data, err := json.MarshalIndent(rows, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return err
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+	},
+}
+
+// repoRemotes is one repo in remotes output.
+type repoRemotes struct {
+	RepoID            string         `json:"repo_id"`
+	Path              string         `json:"path"`
+	RegistryRemoteURL string         `json:"registry_remote_url"`
+	PrimaryRemote     string         `json:"primary_remote"`
+	Remotes           []model.Remote `json:"remotes"`
+	Mismatch          bool           `json:"mismatch"`
+	Error             string         `json:"error,omitempty"`
+}
+
+func parseRemotesFilter(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "all":
+		return false, nil
+	case "mismatch":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported --only value %q (expected all or mismatch)", raw)
+	}
+}
+
+// collectRepoRemotes lists the remotes of every registry entry through the
+// adapter. It deliberately skips full status inspection, which would also probe
+// the network for stale remote-tracking refs.
+func collectRepoRemotes(ctx context.Context, adapter vcs.Adapter, reg *registry.Registry) []repoRemotes {
+	rows := make([]repoRemotes, 0, len(reg.Entries))
+	for _, entry := range reg.Entries {
+		row := repoRemotes{
+			RepoID:            entry.RepoID,
+			Path:              entry.Path,
+			RegistryRemoteURL: entry.RemoteURL,
+			Remotes:           []model.Remote{},
+		}
+		if entry.Status == registry.StatusMissing {
+			row.Error = "path missing"
+			rows = append(rows, row)
+			continue
+		}
+		remotes, err := adapter.Remotes(ctx, entry.Path)
+		if err != nil {
+			row.Error = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		if remotes != nil {
+			row.Remotes = remotes
+		}
+		names := make([]string, 0, len(remotes))
+		for _, remote := range remotes {
+			names = append(names, remote.Name)
+		}
+		row.PrimaryRemote = adapter.PrimaryRemote(names)
+		row.Mismatch = !remotesMatchRegistry(row.Remotes, entry.RemoteURL)
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].RepoID != rows[j].RepoID {
+			return rows[i].RepoID < rows[j].RepoID
+		}
+		return rows[i].Path < rows[j].Path
+	})
+	return rows
+}
+
+// remotesMatchRegistry reports whether any remote normalizes to the same repo
+// ID as registryURL. An empty registry URL has nothing to compare against.
+func remotesMatchRegistry(remotes []model.Remote, registryURL string) bool {
+	registryURL = strings.TrimSpace(registryURL)
+	if registryURL == "" {
+		return true
+	}
+	want := gitx.NormalizeURL(registryURL)
+	for _, remote := range remotes {
+		if gitx.NormalizeURL(remote.URL) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func filterRemotesMismatch(rows []repoRemotes) []repoRemotes {
+	filtered := make([]repoRemotes, 0, len(rows))
+	for _, row := range rows {
+		if row.Mismatch {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
+func writeRemotesTable(cmd *cobra.Command, rows []repoRemotes, noHeaders bool) error {
+	tableRows := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		if len(row.Remotes) == 0 {
+			tableRows = append(tableRows, []string{row.RepoID, "-", "-"})
+			continue
+		}
+		for _, remote := range row.Remotes {
+			tableRows = append(tableRows, []string{row.RepoID, remote.Name, remote.URL})
+		}
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"REPO", "REMOTE", "URL"}, tableRows)
+}
+
+func init() {
+	addFormatFlag(remotesCmd, "output format: table or json")
+	addNoHeadersFlag(remotesCmd)
+	remotesCmd.Flags().String("only", "all", "filter: all or mismatch")
+
+	rootCmd.AddCommand(remotesCmd)
+}
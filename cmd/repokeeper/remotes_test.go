@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestRemotesMatchRegistryNormalizesURLs(t *testing.T) {
+	remotes := []model.Remote{
+		{Name: "origin", URL: "https://github.com/me/fork.git"},
+		{Name: "upstream", URL: "https://github.com/org/repo.git"},
+	}
+	if !remotesMatchRegistry(remotes, "git@github.com:org/repo.git") {
+		t.Fatal("expected a non-primary remote to match after normalization")
+	}
+	if remotesMatchRegistry(remotes, "git@github.com:org/other.git") {
+		t.Fatal("did not expect an unrelated registry URL to match")
+	}
+	if !remotesMatchRegistry(nil, "") {
+		t.Fatal("expected an empty registry URL to never be a mismatch")
+	}
+	if _, err := parseRemotesFilter("bogus"); err == nil {
+		t.Fatal("expected an unknown --only value to be rejected")
+	}
+}
+
+func TestRemotesCommandListsRemotesAndFlagsMismatch(t *testing.T) {
+	tmp := t.TempDir()
+	runGit := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, string(out))
+		}
+	}
+	matching := filepath.Join(tmp, "matching")
+	drifted := filepath.Join(tmp, "drifted")
+	runGit("init", "-b", "main", matching)
+	runGit("-C", matching, "remote", "add", "origin", "https://github.com/me/repo.git")
+	runGit("-C", matching, "remote", "add", "upstream", "https://github.com/org/repo.git")
+	runGit("init", "-b", "main", drifted)
+	runGit("-C", drifted, "remote", "add", "origin", "https://github.com/org/renamed.git")
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: matching, RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/drifted", Path: drifted, RemoteURL: "git@github.com:org/drifted.git", Status: registry.StatusPresent},
+	}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExitCode }()
+	remotesCmd.SetOut(out)
+	remotesCmd.SetContext(context.Background())
+	defer remotesCmd.SetOut(os.Stdout)
+	defer func() {
+		_ = remotesCmd.Flags().Set("format", "table")
+		_ = remotesCmd.Flags().Set("only", "all")
+	}()
+
+	if err := remotesCmd.RunE(remotesCmd, nil); err != nil {
+		t.Fatalf("remotes: %v", err)
+	}
+	table := out.String()
+	for _, want := range []string{"REMOTE", "upstream", "https://github.com/org/repo.git", "https://github.com/org/renamed.git"} {
+		if !strings.Contains(table, want) {
+			t.Fatalf("expected %q in table output:\n%s", want, table)
+		}
+	}
+	if state.exitCode != 0 {
+		t.Fatalf("expected listing all remotes to leave exit code 0, got %d", state.exitCode)
+	}
+
+	out.Reset()
+	_ = remotesCmd.Flags().Set("format", "json")
+	_ = remotesCmd.Flags().Set("only", "mismatch")
+	if err := remotesCmd.RunE(remotesCmd, nil); err != nil {
+		t.Fatalf("remotes --only mismatch: %v", err)
+	}
+	var rows []repoRemotes
+	if err := json.Unmarshal(out.Bytes(), &rows); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if len(rows) != 1 || rows[0].RepoID != "github.com/org/drifted" || !rows[0].Mismatch {
+		t.Fatalf("expected only the drifted repo, got %+v", rows)
+	}
+	if len(rows[0].Remotes) != 1 || rows[0].Remotes[0].Name != "origin" {
+		t.Fatalf("expected the remotes array in JSON, got %+v", rows[0].Remotes)
+	}
+	if state.exitCode != 1 {
+		t.Fatalf("expected mismatch exit code 1, got %d", state.exitCode)
+	}
+}
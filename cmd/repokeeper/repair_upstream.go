@@ -42,7 +42,7 @@ var repairUpstreamCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -56,6 +56,10 @@ var repairUpstreamCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
 		format, _ := cmd.Flags().GetString("format")
 		mode, err := parseOutputMode(format)
 		if err != nil {
@@ -80,9 +84,11 @@ var repairUpstreamCmd = &cobra.Command{
 		classifier := vcs.NewGitErrorClassifier()
 		eng := engine.New(cfg, reg, adapter, classifier, vcs.NewGitURLNormalizer(), nil)
 		report, err := eng.Status(cmd.Context(), engine.StatusOptions{
-			Filter:      engine.FilterAll,
-			Concurrency: 0,
-			Timeout:     0,
+			Filter:          engine.FilterAll,
+			Concurrency:     0,
+			Timeout:         0,
+			MaxJobs:         maxJobs,
+			ProbeRemoteHead: only == "stale-upstream",
 		})
 		if err != nil {
 			return err
@@ -147,6 +153,11 @@ var repairUpstreamCmd = &cobra.Command{
 			}
 
 			targetBranch := resolveUpstreamTargetBranch(entry, repo, cfg)
+			if only == "stale-upstream" && repo.Tracking.StaleUpstream {
+				// The recorded branch is usually the old default, so follow
+				// the remote's HEAD instead.
+				targetBranch = repo.Tracking.RemoteDefault
+			}
 			if targetBranch == "" {
 				res.Action = "skip no branch"
 				results = append(results, res)
@@ -156,7 +167,11 @@ var repairUpstreamCmd = &cobra.Command{
 			res.TargetUpstream = targetUpstream
 
 			needsRepair := needsUpstreamRepair(repo, targetUpstream)
-			if !repairUpstreamMatchesFilter(res.CurrentUpstream, targetUpstream, only) {
+			matches := repairUpstreamMatchesFilter(res.CurrentUpstream, targetUpstream, only)
+			if only == "stale-upstream" {
+				matches = repo.Tracking.StaleUpstream
+			}
+			if !matches {
 				res.Action = "filtered"
 				results = append(results, res)
 				continue
@@ -281,13 +296,15 @@ func parseUpstreamRepairFilter(raw string) (string, error) {
 	switch normalized {
 	case "":
 		return "all", nil
-	case "all", "missing", "mismatch":
+	case "all", "missing", "mismatch", "stale-upstream":
 		return normalized, nil
 	default:
-		return "", fmt.Errorf("invalid --only %q (supported: all,missing,mismatch)", raw)
+		return "", fmt.Errorf("invalid --only %q (supported: all,missing,mismatch,stale-upstream)", raw)
 	}
 }
 
+// repairUpstreamMatchesFilter applies the upstream-only filters. stale-upstream
+// depends on the inspected status, so the caller checks it instead.
 func repairUpstreamMatchesFilter(current, target, filter string) bool {
 	switch strings.ToLower(strings.TrimSpace(filter)) {
 	case "", "all":
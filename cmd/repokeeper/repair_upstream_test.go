@@ -4,6 +4,7 @@ package repokeeper
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
@@ -78,6 +79,7 @@ func TestParseUpstreamRepairFilter(t *testing.T) {
 		{name: "all", in: "all", want: "all"},
 		{name: "missing", in: "missing", want: "missing"},
 		{name: "mismatch", in: "mismatch", want: "mismatch"},
+		{name: "stale-upstream", in: "stale-upstream", want: "stale-upstream"},
 		{name: "case-insensitive and trimmed", in: "  MISMATCH  ", want: "mismatch"},
 		{
 			// Regression: an unrecognized --only value must be rejected, not
@@ -448,3 +450,67 @@ func TestRepairUpstreamRunERepairedNonDryRun(t *testing.T) {
 		t.Fatalf("expected repaired action in output, got: %q", got)
 	}
 }
+
+func TestRepairUpstreamRunERetargetsStaleUpstreamToRenamedDefault(t *testing.T) {
+	tmp := t.TempDir()
+	remote := filepath.Join(tmp, "remote.git")
+	mustRunGit(t, tmp, "init", "--bare", remote)
+
+	work := filepath.Join(tmp, "work")
+	mustRunGit(t, tmp, "clone", remote, work)
+	mustRunGit(t, work, "checkout", "-b", "master")
+	mustRunGit(t, work, "commit", "--allow-empty", "-m", "init")
+	mustRunGit(t, work, "push", "-u", "origin", "master")
+	// The host renames master to main but keeps the old branch around.
+	mustRunGit(t, remote, "branch", "main", "master")
+	mustRunGit(t, remote, "symbolic-ref", "HEAD", "refs/heads/main")
+	mustRunGit(t, work, "fetch", "origin")
+
+	other := filepath.Join(tmp, "other")
+	mustRunGit(t, tmp, "clone", remote, other)
+
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/org/repo-stale", Path: work, RemoteURL: remote, Status: registry.StatusPresent, Branch: "master"},
+			{RepoID: "github.com/org/repo-current", Path: other, RemoteURL: remote, Status: registry.StatusPresent},
+		},
+	}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	repairUpstreamCmd.SetIn(bytes.NewBufferString("y\n"))
+	repairUpstreamCmd.SetOut(out)
+	repairUpstreamCmd.SetContext(context.Background())
+	defer repairUpstreamCmd.SetIn(nil)
+	defer repairUpstreamCmd.SetOut(nil)
+
+	_ = repairUpstreamCmd.Flags().Set("registry", "")
+	_ = repairUpstreamCmd.Flags().Set("dry-run", "false")
+	_ = repairUpstreamCmd.Flags().Set("only", "stale-upstream")
+	_ = repairUpstreamCmd.Flags().Set("format", "json")
+	defer func() { _ = repairUpstreamCmd.Flags().Set("only", "all") }()
+
+	if err := repairUpstreamCmd.RunE(repairUpstreamCmd, nil); err != nil {
+		t.Fatalf("repair-upstream --only stale-upstream failed: %v", err)
+	}
+	var results []repairUpstreamResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	actions := map[string]string{}
+	for _, res := range results {
+		actions[res.RepoID] = res.Action + " " + res.TargetUpstream
+	}
+	if actions["github.com/org/repo-stale"] != "repaired origin/main" || actions["github.com/org/repo-current"] != "filtered origin/main" {
+		t.Fatalf("expected only the stale repo retargeted to origin/main, got %v", actions)
+	}
+	if got := strings.TrimSpace(mustRunGit(t, work, "rev-parse", "--abbrev-ref", "master@{upstream}")); got != "origin/main" {
+		t.Fatalf("expected master to track origin/main, got %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/skaphos/repokeeper/internal/cliio"
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Read-only reports across registered repos",
+}
+
+var reportGoneBranchesCmd = &cobra.Command{
+	Use:   "gone-branches",
+	Short: "List local branches whose upstream was deleted, in every repo",
+	Long: "Lists every local branch, not just the checked-out one, whose upstream branch no longer exists on the remote. " +
+		"It reads the remote-tracking refs already on disk and runs no fetch, so run reconcile first to pick up recent deletions. " +
+		"Missing, bare, and mirror repos are skipped.\n\n" +
+		"Exits 1 when any gone branch is found and 2 when branches could not be listed for a repo.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		debugf(cmd, "starting report gone-branches")
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseOutputMode(format)
+		if err != nil {
+			return err
+		}
+		noHeaders, _ := cmd.Flags().GetBool("no-headers")
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if err != nil {
+			return err
+		}
+		cfg, err := loadConfig(cmd, cfgPath)
+		if err != nil {
+			return err
+		}
+		debugf(cmd, "using config %s", cfgPath)
+		if cfg.Registry == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+
+		adapter, err := selectedAdapterForCommand(cmd)
+		if err != nil {
+			return err
+		}
+		eng := engine.New(cfg, cfg.Registry, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), cmdLogger{cmd})
+		report, err := eng.GoneBranches(cmd.Context(), engine.GoneBranchesOptions{MaxJobs: maxJobs})
+		if err != nil {
+			return err
+		}
+
+		switch mode.kind {
+		case outputKindTable:
+			setColorOutputMode(cmd, string(mode.kind))
+			logOutputWriteFailure(cmd, "gone-branches table", writeGoneBranchesTable(cmd, report, cwd, []string{config.ConfigRoot(cfgPath)}, noHeaders))
+		case outputKindJSON:
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			logOutputWriteFailure(cmd, "gone-branches json", err)
+		default:
+			return fmt.Errorf("unsupported format %q", format)
+		}
+
+		raiseExitCode(cmd, goneBranchesExitCode(report))
+		infof(cmd, "report gone-branches completed: %d repos with gone branches or errors", len(report.Repos))
+		return nil
+	},
+}
+
+// writeGoneBranchesTable prints one row per gone branch. Repos whose branches
+// could not be listed are reported on stderr instead.
+func writeGoneBranchesTable(cmd *cobra.Command, report *model.GoneBranchesReport, cwd string, roots []string, noHeaders bool) error {
+	var rows [][]string
+	for _, repo := range report.Repos {
+		path := displayRepoPath(repo.Path, cwd, roots)
+		if repo.Error != "" {
+			warnf(cmd, "could not list branches in %s: %s", sanitizeForDisplay(path), sanitizeForDisplay(repo.Error))
+			continue
+		}
+		for _, branch := range repo.Branches {
+			rows = append(rows, []string{sanitizeForDisplay(path), sanitizeForDisplay(branch.Name), sanitizeForDisplay(dashIfEmpty(branch.Upstream))})
+		}
+	}
+	if len(rows) == 0 {
+		infof(cmd, "no gone branches found")
+		return nil
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"PATH", "BRANCH", "UPSTREAM"}, rows)
+}
+
+func goneBranchesExitCode(report *model.GoneBranchesReport) int {
+	code := 0
+	for _, repo := range report.Repos {
+		if repo.Error != "" {
+			return 2
+		}
+		if len(repo.Branches) > 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+func init() {
+	addFormatFlag(reportGoneBranchesCmd, "output format: table or json")
+	addNoHeadersFlag(reportGoneBranchesCmd)
+	addVCSFlag(reportGoneBranchesCmd)
+	reportCmd.AddCommand(reportGoneBranchesCmd)
+	rootCmd.AddCommand(reportCmd)
+}
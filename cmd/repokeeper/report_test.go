@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func TestWriteGoneBranchesTable(t *testing.T) {
+	t.Parallel()
+
+	report := &model.GoneBranchesReport{Repos: []model.GoneBranchesRepo{
+		{RepoID: "github.com/org/a", Path: "/work/a", Branches: []model.BranchTracking{
+			{Name: "feature/old", Upstream: "origin/feature/old", Status: model.TrackingGone},
+			{Name: "fix/done", Upstream: "origin/fix/done", Status: model.TrackingGone},
+		}},
+		{RepoID: "github.com/org/b", Path: "/work/b", Error: "for-each-ref failed"},
+	}}
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	if err := writeGoneBranchesTable(cmd, report, "/work", nil, false); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "PATH") || !strings.Contains(lines[1], "feature/old") || !strings.Contains(lines[2], "origin/fix/done") {
+		t.Fatalf("unexpected table:\n%s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "could not list branches in b: for-each-ref failed") {
+		t.Fatalf("expected error on stderr, got %q", errOut.String())
+	}
+	if code := goneBranchesExitCode(report); code != 2 {
+		t.Fatalf("expected exit code 2 with an error row, got %d", code)
+	}
+	if code := goneBranchesExitCode(&model.GoneBranchesReport{Repos: report.Repos[:1]}); code != 1 {
+		t.Fatalf("expected exit code 1 for gone branches, got %d", code)
+	}
+}
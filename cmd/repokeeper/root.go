@@ -5,14 +5,15 @@ package repokeeper
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 
 	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/gitx"
 	"github.com/skaphos/repokeeper/internal/tui"
-	"github.com/skaphos/repokeeper/internal/urlutil"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -35,24 +36,46 @@ var rootCmd = &cobra.Command{
 	Use:   "repokeeper",
 	Short: "Cross-platform multi-repo hygiene tool",
 	Long:  "RepoKeeper inventories repositories, reports drift and broken tracking, and performs safe sync actions (fetch/prune) without touching working trees or submodules.",
-	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-		// `NO_COLOR` is a standard opt-out and should behave like --no-color.
-		if strings.TrimSpace(os.Getenv("NO_COLOR")) != "" {
-			_ = cmd.Flags().Set("no-color", "true")
-		}
-	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().CountP("verbose", "v", "increase output verbosity (repeatable)")
 	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "suppress non-essential output")
 	rootCmd.PersistentFlags().String("config", "", "override config file path")
+	rootCmd.PersistentFlags().String("profile", "", profileUsage)
 	rootCmd.PersistentFlags().Bool("no-color", false, "disable colored output")
+	rootCmd.PersistentFlags().String("color", colorModeAuto, colorUsage)
 	rootCmd.PersistentFlags().Bool("yes", false, "accept mutating actions without interactive confirmation")
+	rootCmd.PersistentFlags().Int("jobs", 0, jobsUsage)
+	rootCmd.PersistentFlags().Bool("log-json", false, logJSONUsage)
 
-	// Register the no-args TUI entry point after rootCmd is fully initialized to
-	// avoid an initialization cycle (RunE references configOverride → rootCmd).
+	// Register the no-args TUI entry point and the pre-run hook after rootCmd is
+	// fully initialized to avoid an initialization cycle (both reach rootCmd
+	// through the flag helpers).
 	rootCmd.RunE = rootRunE
+	rootCmd.PersistentPreRunE = rootPersistentPreRunE
+}
+
+// rootPersistentPreRunE validates --color, applies NO_COLOR, and routes
+// package-level slog output through the JSON handler for --log-json before any
+// command runs.
+func rootPersistentPreRunE(cmd *cobra.Command, _ []string) error {
+	if isLogJSON(cmd) {
+		slog.SetDefault(slog.New(newJSONLogHandler(cmd.ErrOrStderr())))
+	}
+	mode, err := parseColorMode(getStringFlag(cmd, "color"))
+	if err != nil {
+		return err
+	}
+	if mode == colorModeAlways && cmd.Flags().Changed("no-color") && isNoColor(cmd) {
+		return fmt.Errorf("--color always cannot be combined with --no-color")
+	}
+	// `NO_COLOR` is a standard opt-out and should behave like --no-color,
+	// unless --color was given explicitly.
+	if strings.TrimSpace(os.Getenv("NO_COLOR")) != "" && !cmd.Flags().Changed("color") {
+		_ = cmd.Flags().Set("no-color", "true")
+	}
+	return nil
 }
 
 // rootRunE launches the interactive TUI when repokeeper is invoked with no
@@ -73,7 +96,7 @@ func rootRunE(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
-	cfg, err := config.Load(cfgPath)
+	cfg, err := loadConfig(cmd, cfgPath)
 	if err != nil {
 		return err
 	}
@@ -105,50 +128,40 @@ func ExecuteWithExitCode() int {
 }
 
 func raiseExitCode(cmd *cobra.Command, code int) {
-	// Keep the highest severity: 0 success, 1 warning, 2 error, 3 fatal.
+	// Keep the highest severity: 0 success, 1 warning, 2 error, 3 fatal or
+	// partial scan.
 	state := runtimeStateFor(cmd)
 	if code > state.exitCode {
 		state.exitCode = code
 	}
 }
 
-func infof(cmd *cobra.Command, format string, args ...any) {
-	if isQuiet(cmd) {
-		return
-	}
-	if _, err := fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", args...); err != nil {
-		if _, fallbackErr := fmt.Fprintf(os.Stderr, "repokeeper: output write failure (info): %v\n", err); fallbackErr != nil {
-			return
-		}
-	}
-}
+// cmdLogger adapts the CLI's stderr helpers to obs.Logger so engine warnings
+// honor --quiet and -v like the rest of the command output.
+type cmdLogger struct{ cmd *cobra.Command }
 
-func debugf(cmd *cobra.Command, format string, args ...any) {
-	if isQuiet(cmd) || verbosity(cmd) <= 0 {
-		return
-	}
-	// Redact URLs with embedded credentials in debug output to prevent accidental credential leakage.
-	redactedArgs := make([]any, len(args))
-	for i, arg := range args {
-		if str, ok := arg.(string); ok {
-			redactedArgs[i] = urlutil.RedactCredentials(str)
-		} else {
-			redactedArgs[i] = arg
-		}
-	}
-	if _, err := fmt.Fprintf(cmd.ErrOrStderr(), format+"\n", redactedArgs...); err != nil {
-		if _, fallbackErr := fmt.Fprintf(os.Stderr, "repokeeper: output write failure (debug): %v\n", err); fallbackErr != nil {
-			return
-		}
-	}
-}
+func (l cmdLogger) Infof(format string, args ...any)  { infof(l.cmd, format, args...) }
+func (l cmdLogger) Debugf(format string, args ...any) { debugf(l.cmd, format, args...) }
+func (l cmdLogger) Warnf(format string, args ...any)  { warnf(l.cmd, format, args...) }
 
 func setColorOutputMode(cmd *cobra.Command, format string) {
 	runtimeStateFor(cmd).colorOutputEnabled = shouldUseColorOutput(cmd, format)
 }
 
+// shouldUseColorOutput reports whether table output gets ANSI color. Only
+// table and wide output are ever colored; --color always then forces color
+// even into a pipe, and never or --no-color turn it off. Otherwise color
+// follows whether stdout is a terminal.
 func shouldUseColorOutput(cmd *cobra.Command, format string) bool {
-	if isNoColor(cmd) || !isTabularFormat(format) {
+	if !isTabularFormat(format) || isNoColor(cmd) {
+		return false
+	}
+	// The pre-run hook rejects unknown modes; treat them as auto here.
+	mode, _ := parseColorMode(getStringFlag(cmd, "color"))
+	switch mode {
+	case colorModeAlways:
+		return true
+	case colorModeNever:
 		return false
 	}
 	file, ok := cmd.OutOrStdout().(*os.File)
@@ -158,6 +171,23 @@ func shouldUseColorOutput(cmd *cobra.Command, format string) bool {
 	return isTerminalFD(int(file.Fd()))
 }
 
+const (
+	colorModeAuto   = "auto"
+	colorModeAlways = "always"
+	colorModeNever  = "never"
+)
+
+func parseColorMode(raw string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(raw)); mode {
+	case "", colorModeAuto:
+		return colorModeAuto, nil
+	case colorModeAlways, colorModeNever:
+		return mode, nil
+	default:
+		return colorModeAuto, fmt.Errorf("unsupported --color %q (expected auto, always, or never)", raw)
+	}
+}
+
 func isTabularFormat(format string) bool {
 	switch strings.ToLower(strings.TrimSpace(format)) {
 	case "table", "wide":
@@ -199,6 +229,33 @@ func configOverride(cmd *cobra.Command) string {
 	return strings.TrimSpace(getStringFlag(cmd, "config"))
 }
 
+// loadConfig loads the config at cfgPath with the --profile selection merged
+// over the base config.
+func loadConfig(cmd *cobra.Command, cfgPath string) (*config.Config, error) {
+	cfg, err := config.LoadWithProfile(cfgPath, strings.TrimSpace(getStringFlag(cmd, "profile")))
+	if err != nil {
+		return nil, err
+	}
+	// Repo IDs are computed in many places from the adapter's NormalizeURL,
+	// so the alias table is installed once for the whole process.
+	gitx.SetHostAliases(cfg.HostAliases)
+	return cfg, nil
+}
+
+// maxJobsOverride returns the --jobs value, or 0 when the flag is unset so the
+// engine falls back to defaults.max_jobs.
+func maxJobsOverride(cmd *cobra.Command) (int, error) {
+	jobs := getIntFlag(cmd, "jobs")
+	if jobs < 0 {
+		return 0, fmt.Errorf("--jobs must be at least 1, got %d", jobs)
+	}
+	return jobs, nil
+}
+
+func isLogJSON(cmd *cobra.Command) bool {
+	return getBoolFlag(cmd, "log-json")
+}
+
 func isNoColor(cmd *cobra.Command) bool {
 	return getBoolFlag(cmd, "no-color")
 }
@@ -237,6 +294,21 @@ func getCountFlag(cmd *cobra.Command, name string) int {
 	return v
 }
 
+func getIntFlag(cmd *cobra.Command, name string) int {
+	if cmd != nil {
+		if cmd.Flags().Lookup(name) != nil {
+			v, _ := cmd.Flags().GetInt(name)
+			return v
+		}
+		if root := cmd.Root(); root != nil && root.PersistentFlags().Lookup(name) != nil {
+			v, _ := root.PersistentFlags().GetInt(name)
+			return v
+		}
+	}
+	v, _ := rootCmd.PersistentFlags().GetInt(name)
+	return v
+}
+
 func getStringFlag(cmd *cobra.Command, name string) string {
 	if cmd != nil {
 		if cmd.Flags().Lookup(name) != nil {
@@ -2,9 +2,12 @@
 package repokeeper
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/skaphos/repokeeper/internal/cliio"
@@ -31,7 +34,7 @@ var scanCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -47,6 +50,30 @@ var scanCmd = &cobra.Command{
 		followSymlinks, _ := cmd.Flags().GetBool("follow-symlinks")
 		writeRegistry, _ := cmd.Flags().GetBool("write-registry")
 		pruneStale, _ := cmd.Flags().GetBool("prune-stale")
+		pruneRegistry, _ := cmd.Flags().GetString("prune-registry")
+		pruneMode := engine.PruneRegistryMode(strings.ToLower(strings.TrimSpace(pruneRegistry)))
+		switch pruneMode {
+		case engine.PruneRegistryOff, engine.PruneRegistryMark, engine.PruneRegistryRemove:
+		default:
+			return fmt.Errorf("--prune-registry must be mark or remove, got %q", pruneRegistry)
+		}
+		fromStdin, _ := cmd.Flags().GetBool("from-stdin")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		if fromStdin && roots != "" {
+			return fmt.Errorf("--from-stdin cannot be combined with --roots")
+		}
+		if concurrency < 0 {
+			return fmt.Errorf("--concurrency must not be negative, got %d", concurrency)
+		}
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+		registerOnly, _ := cmd.Flags().GetBool("register-only")
+		maxDepth, _ := cmd.Flags().GetInt("max-depth")
+		if maxDepth < 0 {
+			return fmt.Errorf("--max-depth must not be negative, got %d", maxDepth)
+		}
 		format, _ := cmd.Flags().GetString("format")
 		mode, err := parseOutputMode(format)
 		if err != nil {
@@ -59,20 +86,51 @@ var scanCmd = &cobra.Command{
 			return err
 		}
 		eng := engine.New(cfg, reg, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
+		var candidates []string
+		if fromStdin {
+			candidates, err = readCandidatePaths(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("read --from-stdin paths: %w", err)
+			}
+			if len(candidates) == 0 {
+				return fmt.Errorf("--from-stdin read no paths")
+			}
+		}
 		scanRoots := strutil.SplitCSV(roots)
-		if len(scanRoots) == 0 {
-			scanRoots = []string{config.EffectiveRoot(cfgPath)}
+		if len(scanRoots) == 0 && !fromStdin {
+			scanRoots = config.DefaultScanRoots(cfg, cfgPath)
 		}
 
-		statuses, err := eng.Scan(cmd.Context(), engine.ScanOptions{
+		report, err := eng.ScanWithReport(cmd.Context(), engine.ScanOptions{
 			Roots:          scanRoots,
 			Exclude:        strutil.SplitCSV(exclude),
+			RootExclude:    config.RootExcludes(cfg, cfgPath),
 			FollowSymlinks: followSymlinks,
+			Concurrency:    concurrency,
+			MaxJobs:        maxJobs,
+			MaxDepth:       maxDepth,
+			Paths:          candidates,
+			PruneRegistry:  pruneMode,
+			RegisterOnly:   registerOnly,
 		})
 		if err != nil {
 			return err
 		}
+		statuses := report.Repos
+		for _, walkErr := range report.WalkErrors {
+			warnf(cmd, "could not read %s: %v", walkErr.Path, walkErr.Err)
+		}
 
+		for _, entry := range report.Pruned {
+			debugf(cmd, "prune-registry: %s %s (%s)", pruneMode, entry.RepoID, entry.Path)
+		}
+		if len(report.Pruned) > 0 {
+			if pruneMode == engine.PruneRegistryRemove {
+				infof(cmd, "removed %d registry entries whose paths no longer exist", len(report.Pruned))
+			} else {
+				infof(cmd, "marked %d registry entries missing", len(report.Pruned))
+			}
+		}
 		if pruneStale {
 			reg.PruneStale(time.Duration(cfg.RegistryStaleDays) * 24 * time.Hour)
 		}
@@ -112,6 +170,11 @@ var scanCmd = &cobra.Command{
 			// Missing/moved entries are warning-level conditions for scan/status flows.
 			raiseExitCode(cmd, 1)
 		}
+		if len(report.WalkErrors) > 0 {
+			// Some directories were unreadable while the rest of the scan
+			// succeeded; the registry may be incomplete for those subtrees.
+			raiseExitCode(cmd, 3)
+		}
 		infof(cmd, "scan completed: %d repos", len(statuses))
 		return nil
 	},
@@ -129,6 +192,19 @@ func writeScanTable(cmd *cobra.Command, statuses []model.RepoStatus, noHeaders b
 	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, []string{"REPO", "PATH", "BARE", "PRIMARY_REMOTE"}, rows)
 }
 
+// readCandidatePaths reads one directory path per line for --from-stdin,
+// dropping blank lines and surrounding whitespace.
+func readCandidatePaths(r io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, scanner.Err()
+}
+
 func hasRegistryWarnings(reg *registry.Registry) bool {
 	for _, entry := range reg.Entries {
 		if entry.Status == registry.StatusMissing || entry.Status == registry.StatusMoved {
@@ -144,6 +220,12 @@ func init() {
 	scanCmd.Flags().Bool("follow-symlinks", false, "follow symbolic links during scan")
 	scanCmd.Flags().Bool("write-registry", true, "write discovered repos to registry")
 	scanCmd.Flags().Bool("prune-stale", false, "remove registry entries marked missing beyond stale threshold")
+	scanCmd.Flags().String("prune-registry", "", pruneRegistryUsage)
+	scanCmd.Flags().Lookup("prune-registry").NoOptDefVal = string(engine.PruneRegistryMark)
+	scanCmd.Flags().Int("concurrency", 0, scanConcurrencyUsage)
+	scanCmd.Flags().Bool("from-stdin", false, fromStdinUsage)
+	scanCmd.Flags().Int("max-depth", 0, maxDepthUsage)
+	scanCmd.Flags().Bool("register-only", false, registerOnlyUsage)
 	addFormatFlag(scanCmd, "output format: table or json")
 	addNoHeadersFlag(scanCmd)
 	addVCSFlag(scanCmd)
@@ -6,8 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
 )
 
 // TestScanJSONOutputEmptyResultSetIsEmptyArray guards a divergence from the
@@ -57,3 +61,154 @@ func TestScanJSONOutputEmptyResultSetIsEmptyArray(t *testing.T) {
 		t.Fatalf("expected zero elements, got %d", len(decoded))
 	}
 }
+
+func TestScanReportsUnreadableDirectoriesWithExitCode3(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits are not enforced for root")
+	}
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	root := filepath.Dir(cfgPath)
+	mustRunGit(t, root, "init", "-q", "repo")
+	locked := filepath.Join(root, "locked")
+	if err := os.Mkdir(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	state := runtimeStateFor(rootCmd)
+	prevExitCode := state.exitCode
+	state.exitCode = 0
+	defer func() { state.exitCode = prevExitCode }()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	scanCmd.SetOut(out)
+	scanCmd.SetErr(errOut)
+	scanCmd.SetContext(context.Background())
+	defer scanCmd.SetOut(os.Stdout)
+	defer scanCmd.SetErr(os.Stderr)
+
+	_ = scanCmd.Flags().Set("roots", "")
+	_ = scanCmd.Flags().Set("write-registry", "false")
+	_ = scanCmd.Flags().Set("format", "json")
+	defer func() { _ = scanCmd.Flags().Set("format", "table") }()
+
+	if err := scanCmd.RunE(scanCmd, nil); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	var decoded []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 1 {
+		t.Fatalf("expected the readable repo in output, got %q (%v)", out.String(), err)
+	}
+	if !strings.Contains(errOut.String(), "warning: could not read "+locked) {
+		t.Fatalf("expected unreadable directory on stderr, got %q", errOut.String())
+	}
+	if state.exitCode != 3 {
+		t.Fatalf("expected exit code 3 for a partial scan, got %d", state.exitCode)
+	}
+}
+
+func TestScanFromStdinRegistersOnlyRepoPaths(t *testing.T) {
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	root := filepath.Dir(cfgPath)
+	mustRunGit(t, root, "init", "-q", "repo-a")
+	mustRunGit(t, root, "init", "-q", filepath.Join("plain", "repo-b"))
+	stdin := strings.Join([]string{
+		filepath.Join(root, "repo-a"),
+		"",
+		filepath.Join(root, "plain"),
+		filepath.Join(root, "does-not-exist"),
+	}, "\n")
+
+	out := &bytes.Buffer{}
+	scanCmd.SetOut(out)
+	scanCmd.SetErr(&bytes.Buffer{})
+	scanCmd.SetIn(strings.NewReader(stdin))
+	scanCmd.SetContext(context.Background())
+	defer scanCmd.SetOut(os.Stdout)
+	defer scanCmd.SetErr(os.Stderr)
+	defer scanCmd.SetIn(os.Stdin)
+
+	_ = scanCmd.Flags().Set("roots", "")
+	_ = scanCmd.Flags().Set("write-registry", "true")
+	_ = scanCmd.Flags().Set("format", "json")
+	_ = scanCmd.Flags().Set("from-stdin", "true")
+	defer func() {
+		_ = scanCmd.Flags().Set("format", "table")
+		_ = scanCmd.Flags().Set("from-stdin", "false")
+	}()
+
+	if err := scanCmd.RunE(scanCmd, nil); err != nil {
+		t.Fatalf("scan --from-stdin: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if len(cfg.Registry.Entries) != 1 || filepath.Base(cfg.Registry.Entries[0].Path) != "repo-a" {
+		t.Fatalf("expected only repo-a registered, got %+v", cfg.Registry.Entries)
+	}
+	if cfg.Registry.Entries[0].Status != registry.StatusPresent {
+		t.Fatalf("expected repo-a present, got %s", cfg.Registry.Entries[0].Status)
+	}
+
+	scanCmd.SetIn(strings.NewReader("\n"))
+	if err := scanCmd.RunE(scanCmd, nil); err == nil {
+		t.Fatal("expected an error when stdin lists no paths")
+	}
+}
+
+func TestScanPruneRegistryRemoveDropsDeletedRepos(t *testing.T) {
+	cfgPath := writeEmptyConfig(t)
+	cleanup := withConfigAndCWD(t, cfgPath)
+	defer cleanup()
+
+	root := filepath.Dir(cfgPath)
+	mustRunGit(t, root, "init", "-q", "repo-a")
+	mustRunGit(t, root, "init", "-q", "repo-b")
+
+	scanCmd.SetOut(&bytes.Buffer{})
+	scanCmd.SetErr(&bytes.Buffer{})
+	scanCmd.SetContext(context.Background())
+	defer scanCmd.SetOut(os.Stdout)
+	defer scanCmd.SetErr(os.Stderr)
+	_ = scanCmd.Flags().Set("roots", root)
+	_ = scanCmd.Flags().Set("write-registry", "true")
+	defer func() {
+		_ = scanCmd.Flags().Set("roots", "")
+		_ = scanCmd.Flags().Set("prune-registry", "")
+	}()
+
+	if err := scanCmd.RunE(scanCmd, nil); err != nil {
+		t.Fatalf("initial scan: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "repo-b")); err != nil {
+		t.Fatalf("remove repo-b: %v", err)
+	}
+
+	_ = scanCmd.Flags().Set("prune-registry", "bogus")
+	if err := scanCmd.RunE(scanCmd, nil); err == nil || !strings.Contains(err.Error(), "--prune-registry") {
+		t.Fatalf("expected invalid --prune-registry error, got %v", err)
+	}
+
+	_ = scanCmd.Flags().Set("prune-registry", "remove")
+	if err := scanCmd.RunE(scanCmd, nil); err != nil {
+		t.Fatalf("scan --prune-registry=remove: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if len(cfg.Registry.Entries) != 1 || filepath.Base(cfg.Registry.Entries[0].Path) != "repo-a" {
+		t.Fatalf("expected only repo-a to remain registered, got %+v", cfg.Registry.Entries)
+	}
+}
@@ -2,11 +2,11 @@
 package repokeeper
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -17,6 +17,7 @@ import (
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
 	"github.com/skaphos/repokeeper/internal/selector"
+	"github.com/skaphos/repokeeper/internal/sortutil"
 	"github.com/skaphos/repokeeper/internal/strutil"
 	"github.com/skaphos/repokeeper/internal/tableutil"
 	"github.com/skaphos/repokeeper/internal/termstyle"
@@ -31,6 +32,8 @@ type divergedAdvice struct {
 	Upstream          string `json:"upstream"`
 	Reason            string `json:"reason"`
 	RecommendedAction string `json:"recommended_action"`
+	// Severity is the weighted risk score, set only under --severity.
+	Severity *float64 `json:"severity,omitempty"`
 }
 
 type remoteMismatchReconcileMode = engine.RemoteMismatchReconcileMode
@@ -47,6 +50,7 @@ type statusJSONRepo struct {
 	model.RepoStatus
 	LocalLabels              map[string]string `json:"local_labels,omitempty"`
 	RepairUpstreamSuggestion bool              `json:"repair_upstream_suggestion,omitempty"`
+	Reason                   string            `json:"reason,omitempty"`
 }
 
 // statusJSONAPIVersion identifies the schema of the `get`/`status -o json`
@@ -71,203 +75,473 @@ var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Report repo health for all registered repositories",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		debugf(cmd, "starting status")
-		cwd, err := os.Getwd()
-		if err != nil {
-			return err
+		watch, _ := cmd.Flags().GetDuration("watch")
+		if watch == 0 {
+			return runStatus(cmd)
+		}
+		if watch < 0 {
+			return fmt.Errorf("--watch must be positive, got %s", watch)
 		}
-		cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+		if reconcileModeRaw, _ := cmd.Flags().GetString("reconcile-remote-mismatch"); strings.TrimSpace(reconcileModeRaw) != string(remoteMismatchReconcileNone) {
+			return fmt.Errorf("--watch cannot be combined with --reconcile-remote-mismatch")
+		}
+		format, _ := cmd.Flags().GetString("format")
+		mode, err := parseStatusOutputMode(format)
+		lines := err == nil && (mode.kind == outputKindJSON || mode.kind == outputKindNDJSON)
+		return runStatusWatch(cmd, watch, lines, func() error { return runStatus(cmd) })
+	},
+}
+
+// runStatus runs one status report: load the registry, inspect the repos,
+// filter, and print.
+func runStatus(cmd *cobra.Command) error {
+	debugf(cmd, "starting status")
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	cfgPath, err := config.ResolveConfigPath(configOverride(cmd), cwd)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(cmd, cfgPath)
+	if err != nil {
+		return err
+	}
+	cfgRoot := config.EffectiveRoot(cfgPath)
+	debugf(cmd, "using config %s", cfgPath)
+
+	registryOverride, _ := cmd.Flags().GetString("registry")
+	var reg *registry.Registry
+	if registryOverride != "" {
+		reg, err = registry.Load(registryOverride)
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+	} else {
+		reg = cfg.Registry
+		if reg == nil {
+			return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+		}
+	}
+
+	roots, _ := cmd.Flags().GetString("roots")
+	format, _ := cmd.Flags().GetString("format")
+	mode, err := parseTemplatedOutputMode(cmd, format, withCSVOutputMode(parseStatusOutputMode))
+	if err != nil {
+		return err
+	}
+	only, _ := cmd.Flags().GetString("only")
+	fieldSelector, _ := cmd.Flags().GetString("field-selector")
+	labelSelectorRaw, _ := cmd.Flags().GetString("selector")
+	localLabelSelectorRaw, _ := cmd.Flags().GetString("local-selector")
+	noHeaders, _ := cmd.Flags().GetBool("no-headers")
+	reconcileModeRaw, _ := cmd.Flags().GetString("reconcile-remote-mismatch")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	verifyIgnored, _ := cmd.Flags().GetBool("verify-ignored")
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+	olderThanRaw, _ := cmd.Flags().GetString("older-than")
+	newerThanRaw, _ := cmd.Flags().GetString("newer-than")
+	rankBySeverity, _ := cmd.Flags().GetBool("severity")
+	behindThreshold, _ := cmd.Flags().GetInt("threshold")
+	groupByRaw, _ := cmd.Flags().GetString("group-by")
+	countOnly, _ := cmd.Flags().GetBool("count-only")
+	sortRaw, _ := cmd.Flags().GetString("sort")
+	minAhead, _ := cmd.Flags().GetInt("min-ahead")
+	minBehind, _ := cmd.Flags().GetInt("min-behind")
+	explain, _ := cmd.Flags().GetBool("explain")
+	filter, err := selector.ResolveRepoFilter(only, fieldSelector)
+	if err != nil {
+		return err
+	}
+	if rankBySeverity && filter != engine.FilterDiverged {
+		return fmt.Errorf("--severity requires --only diverged")
+	}
+	if cmd.Flags().Changed("threshold") && filter != engine.FilterBranchesBehindDefault {
+		return fmt.Errorf("--threshold requires --only branches-behind-default")
+	}
+	if behindThreshold < 1 {
+		return fmt.Errorf("--threshold must be at least 1")
+	}
+	maxJobs, err := maxJobsOverride(cmd)
+	if err != nil {
+		return err
+	}
+	nameOnly, err := parseNameOnlyOutput(cmd)
+	if err != nil {
+		return err
+	}
+	if nameOnly.enabled && mode.kind == outputKindNDJSON {
+		// --name-only replaces the output format, so don't stream.
+		mode.kind = outputKindTable
+	}
+	if mode.kind == outputKindNDJSON && rankBySeverity {
+		return fmt.Errorf("--severity is not supported with -o ndjson")
+	}
+	sortOrder, err := sortutil.ParseRepoStatusOrder(sortRaw)
+	if err != nil {
+		return fmt.Errorf("invalid --sort: %w", err)
+	}
+	if strings.TrimSpace(sortRaw) != "" {
+		switch {
+		case mode.kind == outputKindNDJSON:
+			return fmt.Errorf("--sort is not supported with -o ndjson")
+		case rankBySeverity:
+			return fmt.Errorf("--sort cannot be combined with --severity")
+		}
+	}
+	groupBy, err := parseStatusGroupBy(groupByRaw)
+	if err != nil {
+		return err
+	}
+	if groupBy.active() {
+		switch {
+		case mode.kind == outputKindNDJSON || mode.kind == outputKindCustomColumns || mode.kind == outputKindTemplate || mode.kind == outputKindCSV:
+			return fmt.Errorf("--group-by is not supported with -o %s", mode.kind)
+		case filter == engine.FilterDiverged:
+			return fmt.Errorf("--group-by is not supported with --only diverged")
+		}
+	}
+	if countOnly {
+		switch {
+		case nameOnly.enabled:
+			return fmt.Errorf("--count-only cannot be combined with --name-only")
+		case groupBy.active():
+			return fmt.Errorf("--count-only cannot be combined with --group-by")
+		case mode.kind == outputKindNDJSON || mode.kind == outputKindCustomColumns || mode.kind == outputKindTemplate:
+			return fmt.Errorf("--count-only is not supported with -o %s", mode.kind)
+		}
+	}
+	if explain {
+		switch {
+		case countOnly:
+			return fmt.Errorf("--explain cannot be combined with --count-only")
+		case nameOnly.enabled:
+			return fmt.Errorf("--explain cannot be combined with --name-only")
+		case groupBy.active():
+			return fmt.Errorf("--explain cannot be combined with --group-by")
+		case mode.kind == outputKindNDJSON || mode.kind == outputKindCustomColumns || mode.kind == outputKindTemplate:
+			return fmt.Errorf("--explain is not supported with -o %s", mode.kind)
+		case filter == engine.FilterDiverged && isTabularFormat(string(mode.kind)):
+			return fmt.Errorf("--explain is not supported with the --only diverged table, which already has a REASON column; use -o json")
+		}
+	}
+	ageFilter, err := parseLastCommitAgeFilter(olderThanRaw, newerThanRaw, time.Now())
+	if err != nil {
+		return err
+	}
+	countFilter, err := parseAheadBehindFilter(minAhead, minBehind)
+	if err != nil {
+		return err
+	}
+	labelSelector, err := selector.ParseLabelSelector(labelSelectorRaw)
+	if err != nil {
+		return err
+	}
+	localLabelSelector, err := selector.ParseLabelSelectorForFlag(localLabelSelectorRaw, "--local-selector")
+	if err != nil {
+		return err
+	}
+	snapshotFilter, err := newRegistrySnapshotFilter(cmd, reg)
+	if err != nil {
+		return err
+	}
+	reg = snapshotFilter.snapshot
+	reconcileMode, err := parseRemoteMismatchReconcileMode(reconcileModeRaw)
+	if err != nil {
+		return err
+	}
+	if mode.kind == outputKindNDJSON && reconcileMode != remoteMismatchReconcileNone {
+		return fmt.Errorf("--reconcile-remote-mismatch is not supported with -o ndjson")
+	}
+	if failFast && reconcileMode != remoteMismatchReconcileNone {
+		return fmt.Errorf("--fail-fast is not supported with --reconcile-remote-mismatch")
+	}
+
+	adapter, err := selectedAdapterForCommand(cmd)
+	if err != nil {
+		return err
+	}
+	eng := engine.New(cfg, reg, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), cmdLogger{cmd})
+
+	if roots != "" {
+		debugf(cmd, "rescanning roots override")
+		_, err := eng.Scan(cmd.Context(), engine.ScanOptions{
+			Roots:       strutil.SplitCSV(roots),
+			RootExclude: config.RootExcludes(cfg, cfgPath),
+			MaxJobs:     maxJobs,
+		})
 		if err != nil {
 			return err
 		}
-		cfgRoot := config.EffectiveRoot(cfgPath)
-		debugf(cmd, "using config %s", cfgPath)
-
-		registryOverride, _ := cmd.Flags().GetString("registry")
-		var reg *registry.Registry
 		if registryOverride != "" {
-			reg, err = registry.Load(registryOverride)
-			if err != nil {
+			if err := registry.Save(snapshotFilter.merged(), registryOverride); err != nil {
 				return err
 			}
 		} else {
-			reg = cfg.Registry
-			if reg == nil {
-				return fmt.Errorf("registry not found in %q (run repokeeper scan first)", cfgPath)
+			cfg.Registry = snapshotFilter.merged()
+			if err := config.Save(cfg, cfgPath); err != nil {
+				return err
 			}
 		}
+	}
 
-		roots, _ := cmd.Flags().GetString("roots")
-		format, _ := cmd.Flags().GetString("format")
-		mode, err := parseOutputMode(format)
-		if err != nil {
-			return err
-		}
-		only, _ := cmd.Flags().GetString("only")
-		fieldSelector, _ := cmd.Flags().GetString("field-selector")
-		labelSelectorRaw, _ := cmd.Flags().GetString("selector")
-		localLabelSelectorRaw, _ := cmd.Flags().GetString("local-selector")
-		noHeaders, _ := cmd.Flags().GetBool("no-headers")
-		reconcileModeRaw, _ := cmd.Flags().GetString("reconcile-remote-mismatch")
-		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		filter, err := selector.ResolveRepoFilter(only, fieldSelector)
-		if err != nil {
-			return err
-		}
-		labelSelector, err := selector.ParseLabelSelector(labelSelectorRaw)
-		if err != nil {
+	statusCachePath := config.StatusCachePath(cfgPath)
+	statusCache, err := statusCacheForRun(cmd, statusCachePath)
+	if err != nil {
+		return err
+	}
+
+	// The same post-engine filters decide what is streamed, what --fail-fast
+	// stops on, and what ends up in the report.
+	stream := statusStream{
+		reg:                reg,
+		labelOverlay:       cfg.LabelOverlay,
+		labelSelector:      labelSelector,
+		localLabelSelector: localLabelSelector,
+		ageFilter:          ageFilter,
+		countFilter:        countFilter,
+		verifyIgnored:      verifyIgnored,
+	}
+	var stopWhen func(model.RepoStatus) bool
+	if failFast {
+		stopWhen = stream.failFastPredicate()
+	}
+
+	if mode.kind == outputKindNDJSON {
+		setColorOutputMode(cmd, string(mode.kind))
+		if err := stream.run(cmd, eng, engine.StatusOptions{Filter: filter, VerifyIgnored: verifyIgnored, MaxJobs: maxJobs, BehindDefaultThreshold: behindThreshold, StopWhen: stopWhen, Cache: statusCache}); err != nil {
 			return err
 		}
-		localLabelSelector, err := selector.ParseLabelSelectorForFlag(localLabelSelectorRaw, "--local-selector")
-		if err != nil {
+		if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, snapshotFilter.merged()); err != nil {
 			return err
 		}
-		reconcileMode, err := parseRemoteMismatchReconcileMode(reconcileModeRaw)
-		if err != nil {
+		if err := saveStatusCache(cmd, statusCachePath, statusCache, snapshotFilter.merged()); err != nil {
 			return err
 		}
-
-		adapter, err := selectedAdapterForCommand(cmd)
-		if err != nil {
-			return err
+		if code := stream.exitCode(); code > 0 {
+			raiseExitCode(cmd, code)
 		}
-		eng := engine.New(cfg, reg, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
+		infof(cmd, "status completed: %d repos", stream.count)
+		return nil
+	}
 
-		if roots != "" {
-			debugf(cmd, "rescanning roots override")
-			_, err := eng.Scan(cmd.Context(), engine.ScanOptions{
-				Roots: strutil.SplitCSV(roots),
-			})
+	report, err := eng.Status(cmd.Context(), engine.StatusOptions{
+		Filter:                 filter,
+		Concurrency:            0,
+		Timeout:                0,
+		VerifyIgnored:          verifyIgnored,
+		MaxJobs:                maxJobs,
+		BehindDefaultThreshold: behindThreshold,
+		StopWhen:               stopWhen,
+		Cache:                  statusCache,
+	})
+	if err != nil {
+		return err
+	}
+	if report.Stopped {
+		infof(cmd, "status stopped early (--fail-fast) after %d matching repos", len(report.Repos))
+	}
+	if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, snapshotFilter.merged()); err != nil {
+		return err
+	}
+	if err := saveStatusCache(cmd, statusCachePath, statusCache, snapshotFilter.merged()); err != nil {
+		return err
+	}
+	enrichReportWithRegistryMetadata(report, reg)
+	overlayRepoLocalLabels(report, cfg.LabelOverlay)
+	report = filterStatusReportByLabels(report, labelSelector)
+	report = filterStatusReportByLocalLabels(report, localLabelSelector)
+	report = filterStatusReportByLastCommit(report, ageFilter)
+	report = filterStatusReportByAheadBehind(report, countFilter)
+	plans := eng.BuildRemoteMismatchPlans(report.Repos, reconcileMode)
+	if len(plans) > 0 {
+		logOutputWriteFailure(cmd, "status remote mismatch plan", writeRemoteMismatchPlan(cmd, plans, cwd, []string{cfgRoot}, dryRun || reconcileMode == remoteMismatchReconcileNone))
+	}
+	if reconcileMode != remoteMismatchReconcileNone && !dryRun {
+		if !assumeYes(cmd) {
+			confirmed, err := confirmWithPrompt(cmd, "Proceed with remote mismatch reconciliation? [y/N]: ")
 			if err != nil {
 				return err
 			}
+			if !confirmed {
+				infof(cmd, "remote mismatch reconcile cancelled")
+				return nil
+			}
+		}
+		if err := eng.ApplyRemoteMismatchPlans(cmd.Context(), plans, reconcileMode); err != nil {
+			return err
+		}
+		if reconcileMode == remoteMismatchReconcileRegistry {
 			if registryOverride != "" {
-				if err := registry.Save(reg, registryOverride); err != nil {
+				if err := registry.Save(snapshotFilter.merged(), registryOverride); err != nil {
 					return err
 				}
 			} else {
-				cfg.Registry = reg
+				cfg.Registry = snapshotFilter.merged()
 				if err := config.Save(cfg, cfgPath); err != nil {
 					return err
 				}
 			}
 		}
-
-		report, err := eng.Status(cmd.Context(), engine.StatusOptions{
-			Filter:      filter,
-			Concurrency: 0,
-			Timeout:     0,
+		report, err = eng.Status(cmd.Context(), engine.StatusOptions{
+			Filter:                 filter,
+			Concurrency:            0,
+			Timeout:                0,
+			VerifyIgnored:          verifyIgnored,
+			MaxJobs:                maxJobs,
+			BehindDefaultThreshold: behindThreshold,
 		})
 		if err != nil {
 			return err
 		}
-		if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, reg); err != nil {
+		if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, snapshotFilter.merged()); err != nil {
 			return err
 		}
 		enrichReportWithRegistryMetadata(report, reg)
+		overlayRepoLocalLabels(report, cfg.LabelOverlay)
 		report = filterStatusReportByLabels(report, labelSelector)
 		report = filterStatusReportByLocalLabels(report, localLabelSelector)
-		plans := eng.BuildRemoteMismatchPlans(report.Repos, reconcileMode)
-		if len(plans) > 0 {
-			logOutputWriteFailure(cmd, "status remote mismatch plan", writeRemoteMismatchPlan(cmd, plans, cwd, []string{cfgRoot}, dryRun || reconcileMode == remoteMismatchReconcileNone))
-		}
-		if reconcileMode != remoteMismatchReconcileNone && !dryRun {
-			if !assumeYes(cmd) {
-				confirmed, err := confirmWithPrompt(cmd, "Proceed with remote mismatch reconciliation? [y/N]: ")
-				if err != nil {
-					return err
-				}
-				if !confirmed {
-					infof(cmd, "remote mismatch reconcile cancelled")
-					return nil
-				}
-			}
-			if err := eng.ApplyRemoteMismatchPlans(cmd.Context(), plans, reconcileMode); err != nil {
-				return err
-			}
-			if reconcileMode == remoteMismatchReconcileRegistry {
-				if registryOverride != "" {
-					if err := registry.Save(reg, registryOverride); err != nil {
-						return err
-					}
-				} else {
-					cfg.Registry = reg
-					if err := config.Save(cfg, cfgPath); err != nil {
-						return err
-					}
-				}
-			}
-			report, err = eng.Status(cmd.Context(), engine.StatusOptions{
-				Filter:      filter,
-				Concurrency: 0,
-				Timeout:     0,
-			})
-			if err != nil {
-				return err
-			}
-			if err := persistStatusRegistrySnapshots(cfg, cfgPath, registryOverride, reg); err != nil {
-				return err
-			}
-			enrichReportWithRegistryMetadata(report, reg)
-			report = filterStatusReportByLabels(report, labelSelector)
-			report = filterStatusReportByLocalLabels(report, localLabelSelector)
-		}
+		report = filterStatusReportByLastCommit(report, ageFilter)
+		report = filterStatusReportByAheadBehind(report, countFilter)
+	}
+	sortutil.SortRepoStatusesBy(report.Repos, sortOrder)
+	var reasons map[string]string
+	if explain {
+		reasons = statusReasons(report, statusReasonFilters{
+			kind:               filter,
+			reg:                reg,
+			labelSelector:      labelSelector,
+			localLabelSelector: localLabelSelector,
+			ageFilter:          ageFilter,
+			countFilter:        countFilter,
+		})
+	}
+	var severity map[string]float64
+	if rankBySeverity {
+		severity = rankDivergedBySeverity(report, cfg.DivergedSeverity, time.Now())
+	}
 
-		output := any(report)
-		if filter == engine.FilterDiverged {
-			output = struct {
-				*model.StatusReport
-				Diverged []divergedAdvice `json:"diverged"`
-			}{
-				StatusReport: report,
-				Diverged:     buildDivergedAdvice(report.Repos),
-			}
-		}
-		switch mode.kind {
-		case outputKindJSON:
-			setColorOutputMode(cmd, string(mode.kind))
-			data, err := json.MarshalIndent(buildStatusJSONOutput(report, filter == engine.FilterDiverged), "", "  ")
-			if err != nil {
-				return err
-			}
-			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
-			logOutputWriteFailure(cmd, "status json", err)
-		case outputKindCustomColumns:
-			setColorOutputMode(cmd, string(mode.kind))
-			logOutputWriteFailure(cmd, "status custom-columns", writeCustomColumnsOutput(cmd, output, mode.expr, noHeaders))
-		case outputKindTable:
-			setColorOutputMode(cmd, string(mode.kind))
-			if filter == engine.FilterDiverged {
-				logOutputWriteFailure(cmd, "status diverged table", writeDivergedStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, false))
-				break
-			}
-			logOutputWriteFailure(cmd, "status table", writeStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, false))
-			logOutputWriteFailure(cmd, "status repair-upstream hint", writeRepairUpstreamHint(cmd, report))
-		case outputKindWide:
-			setColorOutputMode(cmd, string(mode.kind))
-			if filter == engine.FilterDiverged {
-				logOutputWriteFailure(cmd, "status diverged wide", writeDivergedStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, true))
-				break
-			}
-			logOutputWriteFailure(cmd, "status wide", writeStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, true))
-			logOutputWriteFailure(cmd, "status repair-upstream hint", writeRepairUpstreamHint(cmd, report))
-		default:
-			return fmt.Errorf("unsupported format %q", format)
+	if countOnly {
+		logOutputWriteFailure(cmd, "status counts", writeStatusSummary(cmd, mode.kind, engine.SummarizeStatus(report, reg), noHeaders))
+		if code := statusExitCode(report, reg); code > 0 {
+			raiseExitCode(cmd, code)
 		}
+		infof(cmd, "status completed: %d repos", len(report.Repos))
+		return nil
+	}
 
+	if nameOnly.enabled {
+		paths := make([]string, 0, len(report.Repos))
+		for _, repo := range report.Repos {
+			paths = append(paths, repo.Path)
+		}
+		logOutputWriteFailure(cmd, "status names", writeRepoNames(cmd.OutOrStdout(), paths, cwd, []string{cfgRoot}, nameOnly.null))
 		if code := statusExitCode(report, reg); code > 0 {
 			raiseExitCode(cmd, code)
 		}
 		infof(cmd, "status completed: %d repos", len(report.Repos))
 		return nil
-	},
+	}
+
+	output := any(report)
+	if filter == engine.FilterDiverged {
+		output = struct {
+			*model.StatusReport
+			Diverged []divergedAdvice `json:"diverged"`
+		}{
+			StatusReport: report,
+			Diverged:     buildDivergedAdviceWithSeverity(report.Repos, severity),
+		}
+	}
+	jsonOutput := statusJSONOutputFor(report, filter == engine.FilterDiverged, severity, reasons)
+	if groupBy.active() {
+		jsonOutput = groupedStatusJSONOutput(report, groupBy)
+	}
+	switch mode.kind {
+	case outputKindJSON:
+		setColorOutputMode(cmd, string(mode.kind))
+		data, err := marshalStatusJSON(cmd, jsonOutput)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		logOutputWriteFailure(cmd, "status json", err)
+	case outputKindYAML:
+		setColorOutputMode(cmd, string(mode.kind))
+		logOutputWriteFailure(cmd, "status yaml", writeYAMLOutput(cmd, jsonOutput))
+	case outputKindCustomColumns:
+		setColorOutputMode(cmd, string(mode.kind))
+		logOutputWriteFailure(cmd, "status custom-columns", writeCustomColumnsOutput(cmd, output, mode.expr, noHeaders))
+	case outputKindTemplate:
+		setColorOutputMode(cmd, string(mode.kind))
+		if err := writeTemplateOutput(cmd, mode.tmpl, report.Repos, cwd, []string{cfgRoot}); err != nil {
+			return err
+		}
+	case outputKindCSV:
+		setColorOutputMode(cmd, string(mode.kind))
+		logOutputWriteFailure(cmd, "status csv", writeStatusCSV(cmd.OutOrStdout(), report, noHeaders, reasons))
+	case outputKindTable:
+		setColorOutputMode(cmd, string(mode.kind))
+		if filter == engine.FilterDiverged {
+			logOutputWriteFailure(cmd, "status diverged table", writeDivergedStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, false, severity))
+			break
+		}
+		if groupBy.active() {
+			logOutputWriteFailure(cmd, "status grouped table", writeGroupedStatusTable(cmd, report, groupBy, cwd, []string{cfgRoot}, noHeaders, false))
+		} else {
+			logOutputWriteFailure(cmd, "status table", writeStatusTableWithReasons(cmd, report, cwd, []string{cfgRoot}, noHeaders, false, reasons))
+		}
+		logOutputWriteFailure(cmd, "status repair-upstream hint", writeRepairUpstreamHint(cmd, report))
+		if filter == engine.FilterStaleMetadata {
+			logOutputWriteFailure(cmd, "status stale-metadata hint", writeStaleMetadataHint(cmd, report))
+		}
+		if filter == engine.FilterBranchesBehindDefault {
+			logOutputWriteFailure(cmd, "status branches-behind-default hint", writeBranchesBehindDefaultHint(cmd, report, behindThreshold))
+		}
+	case outputKindWide:
+		setColorOutputMode(cmd, string(mode.kind))
+		if filter == engine.FilterDiverged {
+			logOutputWriteFailure(cmd, "status diverged wide", writeDivergedStatusTable(cmd, report, cwd, []string{cfgRoot}, noHeaders, true, severity))
+			break
+		}
+		if groupBy.active() {
+			logOutputWriteFailure(cmd, "status grouped wide", writeGroupedStatusTable(cmd, report, groupBy, cwd, []string{cfgRoot}, noHeaders, true))
+		} else {
+			logOutputWriteFailure(cmd, "status wide", writeStatusTableWithReasons(cmd, report, cwd, []string{cfgRoot}, noHeaders, true, reasons))
+		}
+		logOutputWriteFailure(cmd, "status repair-upstream hint", writeRepairUpstreamHint(cmd, report))
+		if filter == engine.FilterStaleMetadata {
+			logOutputWriteFailure(cmd, "status stale-metadata hint", writeStaleMetadataHint(cmd, report))
+		}
+		if filter == engine.FilterBranchesBehindDefault {
+			logOutputWriteFailure(cmd, "status branches-behind-default hint", writeBranchesBehindDefaultHint(cmd, report, behindThreshold))
+		}
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+	if verifyIgnored && isTabularFormat(string(mode.kind)) {
+		logOutputWriteFailure(cmd, "status ignored files", writeIgnoredFilesReport(cmd, report, cwd, []string{cfgRoot}))
+	}
+
+	if code := statusExitCode(report, reg); code > 0 {
+		raiseExitCode(cmd, code)
+	}
+	if verifyIgnored && len(reposWithIgnoredFiles(report)) > 0 {
+		raiseExitCode(cmd, 1)
+	}
+	infof(cmd, "status completed: %d repos", len(report.Repos))
+	return nil
 }
 
 func buildStatusJSONOutput(report *model.StatusReport, includeDiverged bool) any {
+	return statusJSONOutputFor(report, includeDiverged, nil, nil)
+}
+
+// statusJSONOutputFor builds the JSON/YAML document; severity, when non-nil,
+// adds each diverged repo's --severity score to its advice, and reasons adds
+// the --explain reason keyed by repo path.
+func statusJSONOutputFor(report *model.StatusReport, includeDiverged bool, severity map[string]float64, reasons map[string]string) any {
 	jsonReport := statusJSONReport{APIVersion: statusJSONAPIVersion}
 	var repos []model.RepoStatus
 	if report != nil {
@@ -279,6 +553,7 @@ func buildStatusJSONOutput(report *model.StatusReport, includeDiverged bool) any
 				RepoStatus:               repo,
 				LocalLabels:              cloneMetadataMap(repo.Labels),
 				RepairUpstreamSuggestion: repo.Tracking.Status == model.TrackingGone,
+				Reason:                   reasons[repo.Path],
 			})
 		}
 	}
@@ -289,21 +564,38 @@ func buildStatusJSONOutput(report *model.StatusReport, includeDiverged bool) any
 	// report yields an empty (non-nil) advice slice rather than panicking.
 	return divergedJSONOutput{
 		statusJSONReport: jsonReport,
-		Diverged:         buildDivergedAdvice(repos),
+		Diverged:         buildDivergedAdviceWithSeverity(repos, severity),
 	}
 }
 
 func init() {
 	statusCmd.Flags().String("roots", "", "additional roots to scan (optional)")
 	statusCmd.Flags().String("registry", "", "override registry file path")
-	addFormatFlag(statusCmd, "output format: table, wide, or json")
+	addFormatFlag(statusCmd, statusFormatUsage)
+	statusCmd.Flags().String("template", "", templateUsage)
 	addRepoFilterFlags(statusCmd)
 	addLabelSelectorFlag(statusCmd)
 	statusCmd.Flags().String("local-selector", "", "filter repos by machine-local labels (key or key=value, comma-separated)")
-	statusCmd.Flags().String("reconcile-remote-mismatch", "none", "optional reconcile mode for remote mismatch: none, registry, git")
+	statusCmd.Flags().String("reconcile-remote-mismatch", "none", remoteReconcileUsage)
 	statusCmd.Flags().Bool("dry-run", true, "preview reconcile actions without modifying registry or git remotes")
 	addNoHeadersFlag(statusCmd)
 	statusCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
+	statusCmd.Flags().Bool("verify-ignored", false, verifyIgnoredUsage)
+	statusCmd.Flags().Bool("fail-fast", false, failFastUsage)
+	statusCmd.Flags().Bool("since-scan", false, sinceScanUsage)
+	statusCmd.Flags().Bool("full", false, fullUsage)
+	statusCmd.Flags().String("older-than", "", olderThanUsage)
+	statusCmd.Flags().String("newer-than", "", newerThanUsage)
+	statusCmd.Flags().Int("min-ahead", 0, minAheadUsage)
+	statusCmd.Flags().Int("min-behind", 0, minBehindUsage)
+	statusCmd.Flags().Bool("severity", false, severityUsage)
+	statusCmd.Flags().Int("threshold", 1, behindThresholdUsage)
+	statusCmd.Flags().String("group-by", "", groupByUsage)
+	statusCmd.Flags().Bool("count-only", false, countOnlyUsage)
+	statusCmd.Flags().Duration("watch", 0, watchUsage)
+	statusCmd.Flags().String("sort", "", statusSortUsage)
+	statusCmd.Flags().Bool("explain", false, explainUsage)
+	addNameOnlyFlags(statusCmd)
 	addVCSFlag(statusCmd)
 
 }
@@ -323,6 +615,12 @@ func persistStatusRegistrySnapshots(cfg *config.Config, cfgPath, registryOverrid
 }
 
 func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string, roots []string, noHeaders bool, wide bool) error {
+	return writeStatusTableWithReasons(cmd, report, cwd, roots, noHeaders, wide, nil)
+}
+
+// writeStatusTableWithReasons is writeStatusTable with a trailing REASON
+// column when reasons is non-nil (status --explain).
+func writeStatusTableWithReasons(cmd *cobra.Command, report *model.StatusReport, cwd string, roots []string, noHeaders bool, wide bool, reasons map[string]string) error {
 	w := tableutil.New(cmd.OutOrStdout(), true)
 	showBranch := true
 	showDirty := true
@@ -345,7 +643,10 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 	}
 	headers += "\tTRACKING\tSTALE_REFS"
 	if wide {
-		headers = "PATH\tBRANCH\tDIRTY\tTRACKING\tSTALE_REFS\tPRIMARY_REMOTE\tUPSTREAM\tAHEAD\tBEHIND\tERROR_CLASS"
+		headers = "PATH\tBRANCH\tDIRTY\tTRACKING\tSTALE_REFS\tPRIMARY_REMOTE\tUPSTREAM\tAHEAD\tBEHIND\tSTASHES\tIN_PROGRESS\tERROR_CLASS"
+	}
+	if reasons != nil {
+		headers += "\tREASON"
 	}
 	if err := tableutil.PrintHeaders(w, noHeaders, headers); err != nil {
 		return err
@@ -353,6 +654,7 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 	wrap := getBoolFlag(cmd, "wrap")
 	pathMax := adaptiveCellLimit(cmd, 0, 48, 32)
 	branchMax := adaptiveCellLimit(cmd, 0, 24, 16)
+	reasonMax := adaptiveCellLimit(cmd, 0, 60, 36)
 	for _, repo := range report.Repos {
 		branch := repo.Head.Branch
 		if repo.Head.Detached {
@@ -362,6 +664,12 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 			branch = "-"
 		}
 		path := formatCell(displayRepoPath(repo.Path, cwd, roots), wrap, pathMax)
+		if repo.WorktreeRole == model.WorktreeRoleLinked {
+			path += " [linked]"
+		}
+		if repo.Archived {
+			path += " [archived]"
+		}
 		branch = formatCell(branch, wrap, branchMax)
 		colorEnabled := runtimeStateFor(cmd).colorOutputEnabled
 		dirty := "-"
@@ -386,6 +694,9 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 				row = append(row, dirty)
 			}
 			row = append(row, tracking, staleRefs)
+			if reasons != nil {
+				row = append(row, formatCell(dashIfEmpty(reasons[repo.Path]), wrap, reasonMax))
+			}
 			if _, err := fmt.Fprintf(w, "%s\n", strings.Join(row, "\t")); err != nil {
 				return err
 			}
@@ -399,9 +710,7 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 		if repo.Tracking.Behind != nil {
 			behind = fmt.Sprintf("%d", *repo.Tracking.Behind)
 		}
-		if _, err := fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		row := []string{
 			path,
 			branch,
 			dirty,
@@ -411,14 +720,29 @@ func writeStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string
 			repo.Tracking.Upstream,
 			ahead,
 			behind,
+			stashCountDisplay(repo),
+			dashIfEmpty(repo.InProgress),
 			repo.ErrorClass,
-		); err != nil {
+		}
+		if reasons != nil {
+			row = append(row, formatCell(dashIfEmpty(reasons[repo.Path]), wrap, reasonMax))
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", strings.Join(row, "\t")); err != nil {
 			return err
 		}
 	}
 	return w.Flush()
 }
 
+// stashCountDisplay renders "-" when there is no worktree to stash from, so a
+// bare repo is not mistaken for one with zero stashes.
+func stashCountDisplay(repo model.RepoStatus) string {
+	if repo.Worktree == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", repo.StashCount)
+}
+
 func remoteTrackingRefCountDisplay(status model.RemoteTrackingRefStatus) string {
 	if status.InspectionError != "" {
 		return "?"
@@ -438,6 +762,68 @@ func writeRepairUpstreamHint(cmd *cobra.Command, report *model.StatusReport) err
 	return err
 }
 
+// writeStaleMetadataHint is only called for --only stale-metadata, so every
+// repo left in the report has a registry branch or remote URL that drifted.
+func writeStaleMetadataHint(cmd *cobra.Command, report *model.StatusReport) error {
+	if isQuiet(cmd) || report == nil || len(report.Repos) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(cmd.ErrOrStderr(), "hint: %d repo(s) have stale registry metadata - run 'repokeeper scan' to refresh remote URLs, or 'repokeeper edit <repo>' to correct the recorded branch\n", len(report.Repos))
+	return err
+}
+
+// writeBranchesBehindDefaultHint is only called for --only
+// branches-behind-default and counts the matching branches, which the table
+// has no column for.
+func writeBranchesBehindDefaultHint(cmd *cobra.Command, report *model.StatusReport, threshold int) error {
+	if isQuiet(cmd) || report == nil || len(report.Repos) == 0 {
+		return nil
+	}
+	branches := 0
+	for _, repo := range report.Repos {
+		branches += repo.LocalBranches.BehindBaseCount
+	}
+	_, err := fmt.Fprintf(cmd.ErrOrStderr(), "hint: %d local branch(es) in %d repo(s) are at least %d commit(s) behind the default branch - rebase them onto it; -o json lists behind_base per branch\n", branches, len(report.Repos), threshold)
+	return err
+}
+
+func reposWithIgnoredFiles(report *model.StatusReport) []model.RepoStatus {
+	if report == nil {
+		return nil
+	}
+	var flagged []model.RepoStatus
+	for _, repo := range report.Repos {
+		if repo.Ignored != nil && (repo.Ignored.Count > 0 || repo.Ignored.InspectionError != "") {
+			flagged = append(flagged, repo)
+		}
+	}
+	return flagged
+}
+
+// writeIgnoredFilesReport lists repos whose worktree hides ignored files. A
+// "clean" repo can still carry real work masked by an overly broad .gitignore,
+// so this goes to stderr alongside the table rather than changing its columns.
+func writeIgnoredFilesReport(cmd *cobra.Command, report *model.StatusReport, cwd string, roots []string) error {
+	flagged := reposWithIgnoredFiles(report)
+	if len(flagged) == 0 {
+		return nil
+	}
+	w := cmd.ErrOrStderr()
+	if _, err := fmt.Fprintf(w, "warning: %d repo(s) have ignored files under the worktree:\n", len(flagged)); err != nil {
+		return err
+	}
+	for _, repo := range flagged {
+		detail := fmt.Sprintf("%d ignored: %s", repo.Ignored.Count, metadataListString(repo.Ignored.Paths))
+		if repo.Ignored.InspectionError != "" {
+			detail = "inspection error: " + sanitizeForDisplay(repo.Ignored.InspectionError)
+		}
+		if _, err := fmt.Fprintf(w, "  %s (%s)\n", displayRepoPath(repo.Path, cwd, roots), detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func countGoneRepos(report *model.StatusReport) int {
 	if report == nil {
 		return 0
@@ -451,9 +837,12 @@ func countGoneRepos(report *model.StatusReport) int {
 	return count
 }
 
-func writeDivergedStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string, roots []string, noHeaders bool, wide bool) error {
+// writeDivergedStatusTable renders the diverged advice view. A non-nil
+// severity map adds a leading SEVERITY column; rows follow report order, which
+// rankDivergedBySeverity has already sorted.
+func writeDivergedStatusTable(cmd *cobra.Command, report *model.StatusReport, cwd string, roots []string, noHeaders bool, wide bool, severity map[string]float64) error {
 	adviceByPath := make(map[string]divergedAdvice, len(report.Repos))
-	for _, advice := range buildDivergedAdvice(report.Repos) {
+	for _, advice := range buildDivergedAdviceWithSeverity(report.Repos, severity) {
 		adviceByPath[advice.Path] = advice
 	}
 
@@ -462,6 +851,9 @@ func writeDivergedStatusTable(cmd *cobra.Command, report *model.StatusReport, cw
 	if wide {
 		headers = "PATH\tBRANCH\tTRACKING\tPRIMARY_REMOTE\tUPSTREAM\tAHEAD\tBEHIND\tREASON\tRECOMMENDED_ACTION"
 	}
+	if severity != nil {
+		headers = "SEVERITY\t" + headers
+	}
 	if err := tableutil.PrintHeaders(w, noHeaders, headers); err != nil {
 		return err
 	}
@@ -484,6 +876,11 @@ func writeDivergedStatusTable(cmd *cobra.Command, report *model.StatusReport, cw
 		tracking := displayTrackingStatus(runtimeStateFor(cmd).colorOutputEnabled, repo.Tracking.Status)
 		reason := formatCell(advice.Reason, wrap, reasonMax)
 		action := formatCell(advice.RecommendedAction, wrap, actionMax)
+		if advice.Severity != nil {
+			if _, err := fmt.Fprintf(w, "%.1f\t", *advice.Severity); err != nil {
+				return err
+			}
+		}
 		if !wide {
 			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", path, branch, tracking, reason, action); err != nil {
 				return err
@@ -571,24 +968,71 @@ func truncateASCII(value string, max int) string {
 	return string(runes[:max-3]) + "..."
 }
 
+// writeStatusSummary prints --count-only output: one row of tallies for table
+// formats, or the summary object for JSON and YAML.
+func writeStatusSummary(cmd *cobra.Command, kind outputKind, summary engine.StatusSummary, noHeaders bool) error {
+	switch kind {
+	case outputKindJSON:
+		data, err := marshalStatusJSON(cmd, summary)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	case outputKindYAML:
+		return writeYAMLOutput(cmd, summary)
+	}
+	headers := []string{"TOTAL", "CLEAN", "DIRTY", "DIVERGED", "GONE", "MISSING", "ERRORS"}
+	row := []string{
+		strconv.Itoa(summary.Total),
+		strconv.Itoa(summary.Clean),
+		strconv.Itoa(summary.Dirty),
+		strconv.Itoa(summary.Diverged),
+		strconv.Itoa(summary.Gone),
+		strconv.Itoa(summary.Missing),
+		strconv.Itoa(summary.Errors),
+	}
+	if kind == outputKindCSV {
+		for i := range headers {
+			headers[i] = strings.ToLower(headers[i])
+		}
+		return writeCSV(cmd.OutOrStdout(), noHeaders, headers, [][]string{row})
+	}
+	return cliio.WriteTable(cmd.OutOrStdout(), false, noHeaders, headers, [][]string{row})
+}
+
 func statusExitCode(report *model.StatusReport, reg *registry.Registry) int {
 	code := 0
 	for _, repo := range report.Repos {
-		if repo.Error != "" {
-			code = 2
-		} else if (repo.Tracking.Status == model.TrackingGone || (repo.Worktree != nil && repo.Worktree.Dirty)) && code < 1 {
-			code = 1
-		}
+		code = max(code, repoStatusExitCode(repo))
 	}
-	if code < 2 && reg != nil {
-		for _, entry := range reg.Entries {
-			if entry.Status == registry.StatusMissing || entry.Status == registry.StatusMoved {
-				code = 1
-				break
-			}
+	return max(code, registryStatusExitCode(reg))
+}
+
+// repoStatusExitCode is the exit code one repo contributes to status: 2 for an
+// inspection error, 1 for a gone upstream or a dirty worktree.
+func repoStatusExitCode(repo model.RepoStatus) int {
+	switch {
+	case repo.Error != "":
+		return 2
+	case repo.Tracking.Status == model.TrackingGone, repo.Worktree != nil && repo.Worktree.Dirty:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// registryStatusExitCode returns 1 when any registry entry is missing or moved.
+func registryStatusExitCode(reg *registry.Registry) int {
+	if reg == nil {
+		return 0
+	}
+	for _, entry := range reg.Entries {
+		if entry.Status == registry.StatusMissing || entry.Status == registry.StatusMoved {
+			return 1
 		}
 	}
-	return code
+	return 0
 }
 
 // sanitizeForDisplay strips ANSI/CSI/OSC escape sequences and other C0
@@ -724,6 +1168,19 @@ func writeStatusDetails(cmd *cobra.Command, repo model.RepoStatus, cwd string, r
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "BARE: %t\n", repo.Bare); err != nil {
 		return err
 	}
+	if repo.Archived {
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), "ARCHIVED: true"); err != nil {
+			return err
+		}
+	}
+	if repo.WorktreeRole != "" {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "WORKTREE_ROLE: %s\n", repo.WorktreeRole); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "GIT_COMMON_DIR: %s\n", repo.GitCommonDir); err != nil {
+			return err
+		}
+	}
 	branch := repo.Head.Branch
 	if repo.Head.Detached {
 		branch = "detached:" + branch
@@ -734,6 +1191,11 @@ func writeStatusDetails(cmd *cobra.Command, repo model.RepoStatus, cwd string, r
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "BRANCH: %s\n", branch); err != nil {
 		return err
 	}
+	if repo.InProgress != "" {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "IN_PROGRESS: %s\n", repo.InProgress); err != nil {
+			return err
+		}
+	}
 	dirty := "-"
 	if repo.Worktree != nil {
 		if repo.Worktree.Dirty {
@@ -745,6 +1207,9 @@ func writeStatusDetails(cmd *cobra.Command, repo model.RepoStatus, cwd string, r
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "DIRTY: %s\n", dirty); err != nil {
 		return err
 	}
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "STASHES: %s\n", stashCountDisplay(repo)); err != nil {
+		return err
+	}
 	tracking := displayTrackingStatusNoColor(repo.Tracking.Status)
 	if repo.Type == "mirror" {
 		tracking = "mirror"
@@ -755,6 +1220,11 @@ func writeStatusDetails(cmd *cobra.Command, repo model.RepoStatus, cwd string, r
 	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "UPSTREAM: %s\n", repo.Tracking.Upstream); err != nil {
 		return err
 	}
+	if repo.Tracking.StaleUpstream {
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "STALE_UPSTREAM: remote default is %s\n", repo.Tracking.RemoteDefault); err != nil {
+			return err
+		}
+	}
 	if repo.RemoteTrackingRefs.StaleCount > 0 || repo.RemoteTrackingRefs.InspectionError != "" {
 		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "STALE_REMOTE_TRACKING_REF_COUNT: %s\n", remoteTrackingRefCountDisplay(repo.RemoteTrackingRefs)); err != nil {
 			return err
@@ -861,6 +1331,10 @@ func writeStatusDetails(cmd *cobra.Command, repo model.RepoStatus, cwd string, r
 }
 
 func buildDivergedAdvice(repos []model.RepoStatus) []divergedAdvice {
+	return buildDivergedAdviceWithSeverity(repos, nil)
+}
+
+func buildDivergedAdviceWithSeverity(repos []model.RepoStatus, severity map[string]float64) []divergedAdvice {
 	advice := make([]divergedAdvice, 0, len(repos))
 	for _, repo := range repos {
 		if repo.Tracking.Status != model.TrackingDiverged {
@@ -875,10 +1349,49 @@ func buildDivergedAdvice(repos []model.RepoStatus) []divergedAdvice {
 			Reason:            reason,
 			RecommendedAction: action,
 		})
+		if score, ok := severity[repo.Path]; ok {
+			advice[len(advice)-1].Severity = &score
+		}
 	}
 	return advice
 }
 
+// divergedSeverityScore combines how far behind, how dirty, and how stale a
+// diverged repo is using the configured weights. Unknown behind counts and
+// missing commit dates contribute nothing.
+func divergedSeverityScore(repo model.RepoStatus, weights config.DivergedSeverity, now time.Time) float64 {
+	score := 0.0
+	if repo.Tracking.Behind != nil {
+		score += weights.BehindWeight * float64(*repo.Tracking.Behind)
+	}
+	if repo.Worktree != nil && repo.Worktree.Dirty {
+		score += weights.DirtyWeight
+	}
+	if !repo.LastCommit.IsZero() && now.After(repo.LastCommit) {
+		score += weights.StaleDayWeight * now.Sub(repo.LastCommit).Hours() / 24
+	}
+	return score
+}
+
+// rankDivergedBySeverity sorts the diverged repos in report by descending
+// severity score, keeping registry order for ties, and returns the scores by
+// path.
+func rankDivergedBySeverity(report *model.StatusReport, weights config.DivergedSeverity, now time.Time) map[string]float64 {
+	scores := make(map[string]float64)
+	if report == nil {
+		return scores
+	}
+	for _, repo := range report.Repos {
+		if repo.Tracking.Status == model.TrackingDiverged {
+			scores[repo.Path] = divergedSeverityScore(repo, weights, now)
+		}
+	}
+	sort.SliceStable(report.Repos, func(i, j int) bool {
+		return scores[report.Repos[i].Path] > scores[report.Repos[j].Path]
+	})
+	return scores
+}
+
 func divergedReasonAndAction(repo model.RepoStatus) (string, string) {
 	if repo.Tracking.Status != model.TrackingDiverged {
 		return "", ""
@@ -966,20 +1479,58 @@ func enrichReportWithRegistryMetadata(report *model.StatusReport, reg *registry.
 	if report == nil || reg == nil {
 		return
 	}
+	byPath := registryEntriesByPath(reg)
+	for i := range report.Repos {
+		enrichRepoWithRegistryMetadata(&report.Repos[i], reg, byPath)
+	}
+}
+
+// registryEntriesByPath indexes reg by path for findRegistryMetadataEntry.
+func registryEntriesByPath(reg *registry.Registry) map[string]registry.Entry {
 	byPath := make(map[string]registry.Entry, len(reg.Entries))
 	for _, entry := range reg.Entries {
 		if strings.TrimSpace(entry.Path) != "" {
 			byPath[entry.Path] = entry
 		}
 	}
+	return byPath
+}
+
+func enrichRepoWithRegistryMetadata(repo *model.RepoStatus, reg *registry.Registry, byPath map[string]registry.Entry) {
+	entry := findRegistryMetadataEntry(reg, byPath, *repo)
+	if entry == nil {
+		return
+	}
+	repo.Labels = cloneMetadataMap(entry.Labels)
+	repo.Annotations = cloneMetadataMap(entry.Annotations)
+	repo.Archived = entry.Archived
+}
+
+// overlayRepoLocalLabels merges labels from each repo's .repokeeper-repo.yaml
+// into its machine-local labels when the config enables it, so
+// --local-selector can match repo-local taxonomy the registry never recorded.
+// Conflicting keys keep the registry value unless precedence is "repo".
+func overlayRepoLocalLabels(report *model.StatusReport, overlay config.LabelOverlay) {
+	if report == nil || !overlay.Enabled {
+		return
+	}
+	repoWins := strings.TrimSpace(overlay.Precedence) == config.LabelOverlayPrecedenceRepo
 	for i := range report.Repos {
 		repo := &report.Repos[i]
-		entry := findRegistryMetadataEntry(reg, byPath, *repo)
-		if entry == nil {
+		if repo.RepoMetadata == nil || len(repo.RepoMetadata.Labels) == 0 {
 			continue
 		}
-		repo.Labels = cloneMetadataMap(entry.Labels)
-		repo.Annotations = cloneMetadataMap(entry.Annotations)
+		merged := cloneMetadataMap(repo.Labels)
+		if merged == nil {
+			merged = make(map[string]string, len(repo.RepoMetadata.Labels))
+		}
+		for key, value := range repo.RepoMetadata.Labels {
+			if _, exists := merged[key]; exists && !repoWins {
+				continue
+			}
+			merged[key] = value
+		}
+		repo.Labels = merged
 	}
 }
 
@@ -1009,11 +1560,7 @@ func filterStatusReportByLabels(report *model.StatusReport, reqs []selector.Labe
 	}
 	filtered := make([]model.RepoStatus, 0, len(report.Repos))
 	for _, repo := range report.Repos {
-		sharedLabels := map[string]string(nil)
-		if repo.RepoMetadata != nil {
-			sharedLabels = repo.RepoMetadata.Labels
-		}
-		if selector.LabelsMatchSelector(sharedLabels, reqs) {
+		if selector.LabelsMatchSelector(sharedRepoLabels(repo), reqs) {
 			filtered = append(filtered, repo)
 		}
 	}
@@ -1021,6 +1568,14 @@ func filterStatusReportByLabels(report *model.StatusReport, reqs []selector.Labe
 	return report
 }
 
+// sharedRepoLabels returns the registry labels --selector matches against.
+func sharedRepoLabels(repo model.RepoStatus) map[string]string {
+	if repo.RepoMetadata == nil {
+		return nil
+	}
+	return repo.RepoMetadata.Labels
+}
+
 func filterStatusReportByLocalLabels(report *model.StatusReport, reqs []selector.LabelRequirement) *model.StatusReport {
 	if report == nil || len(reqs) == 0 {
 		return report
@@ -1035,6 +1590,202 @@ func filterStatusReportByLocalLabels(report *model.StatusReport, reqs []selector
 	return report
 }
 
+// lastCommitAgeFilter keeps repos whose HEAD commit falls inside an age window
+// measured back from now. A zero bound is not applied.
+type lastCommitAgeFilter struct {
+	now       time.Time
+	olderThan time.Duration
+	newerThan time.Duration
+}
+
+func (f lastCommitAgeFilter) active() bool {
+	return f.olderThan > 0 || f.newerThan > 0
+}
+
+func parseLastCommitAgeFilter(olderThanRaw, newerThanRaw string, now time.Time) (lastCommitAgeFilter, error) {
+	filter := lastCommitAgeFilter{now: now}
+	var err error
+	if filter.olderThan, err = parseAgeDuration(olderThanRaw, "--older-than"); err != nil {
+		return lastCommitAgeFilter{}, err
+	}
+	if filter.newerThan, err = parseAgeDuration(newerThanRaw, "--newer-than"); err != nil {
+		return lastCommitAgeFilter{}, err
+	}
+	if filter.olderThan > 0 && filter.newerThan > 0 && filter.newerThan <= filter.olderThan {
+		return lastCommitAgeFilter{}, fmt.Errorf("--newer-than (%s) must be longer than --older-than (%s) to select a window", newerThanRaw, olderThanRaw)
+	}
+	return filter, nil
+}
+
+// parseAgeDuration accepts Go durations plus whole-day (d) and whole-week (w)
+// suffixes, since commit ages are usually thought of in days or months.
+func parseAgeDuration(raw, flag string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(raw, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(raw, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(raw[:len(raw)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s %q: expected a positive number of days or weeks, e.g. 90d or 12w", flag, raw)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a positive duration such as 90d, 12w, or 720h", flag, raw)
+	}
+	return d, nil
+}
+
+// filterStatusReportByLastCommit applies the age window. Repos without a known
+// commit date (bare repos, unborn branches) are dropped whenever a bound is
+// set, rather than being treated as infinitely old.
+func filterStatusReportByLastCommit(report *model.StatusReport, filter lastCommitAgeFilter) *model.StatusReport {
+	if report == nil || !filter.active() {
+		return report
+	}
+	filtered := make([]model.RepoStatus, 0, len(report.Repos))
+	for _, repo := range report.Repos {
+		if ok, _ := filter.explain(repo); ok {
+			filtered = append(filtered, repo)
+		}
+	}
+	report.Repos = filtered
+	return report
+}
+
+// explain reports whether repo falls inside the age window, with the HEAD
+// commit's age in whole days as the reason.
+func (f lastCommitAgeFilter) explain(repo model.RepoStatus) (bool, string) {
+	if repo.Bare || repo.LastCommit.IsZero() {
+		return false, ""
+	}
+	age := f.now.Sub(repo.LastCommit)
+	if f.olderThan > 0 && age < f.olderThan {
+		return false, ""
+	}
+	if f.newerThan > 0 && age > f.newerThan {
+		return false, ""
+	}
+	return true, fmt.Sprintf("last commit %dd ago", int(age.Hours()/24))
+}
+
+// aheadBehindFilter keeps repos at least minAhead commits ahead of and at
+// least minBehind commits behind their upstream. A zero bound is not applied.
+type aheadBehindFilter struct {
+	minAhead  int
+	minBehind int
+}
+
+func (f aheadBehindFilter) active() bool {
+	return f.minAhead > 0 || f.minBehind > 0
+}
+
+func parseAheadBehindFilter(minAhead, minBehind int) (aheadBehindFilter, error) {
+	if minAhead < 0 {
+		return aheadBehindFilter{}, fmt.Errorf("--min-ahead must not be negative, got %d", minAhead)
+	}
+	if minBehind < 0 {
+		return aheadBehindFilter{}, fmt.Errorf("--min-behind must not be negative, got %d", minBehind)
+	}
+	return aheadBehindFilter{minAhead: minAhead, minBehind: minBehind}, nil
+}
+
+// filterStatusReportByAheadBehind applies the inclusive ahead/behind
+// thresholds. Unknown counts (no upstream, gone upstream) count as zero, so
+// such repos are dropped whenever a bound is set.
+func filterStatusReportByAheadBehind(report *model.StatusReport, filter aheadBehindFilter) *model.StatusReport {
+	if report == nil || !filter.active() {
+		return report
+	}
+	filtered := make([]model.RepoStatus, 0, len(report.Repos))
+	for _, repo := range report.Repos {
+		if ok, _ := filter.explain(repo); ok {
+			filtered = append(filtered, repo)
+		}
+	}
+	report.Repos = filtered
+	return report
+}
+
+// explain reports whether repo meets the thresholds, naming each applied
+// bound next to the count that met it.
+func (f aheadBehindFilter) explain(repo model.RepoStatus) (bool, string) {
+	count := func(n *int) int {
+		if n == nil {
+			return 0
+		}
+		return *n
+	}
+	ahead, behind := count(repo.Tracking.Ahead), count(repo.Tracking.Behind)
+	if ahead < f.minAhead || behind < f.minBehind {
+		return false, ""
+	}
+	var parts []string
+	if f.minAhead > 0 {
+		parts = append(parts, fmt.Sprintf("%d ahead (min %d)", ahead, f.minAhead))
+	}
+	if f.minBehind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind (min %d)", behind, f.minBehind))
+	}
+	return true, strings.Join(parts, ", ")
+}
+
+// statusReasonFilters are the status filters --explain reports on.
+type statusReasonFilters struct {
+	kind               engine.FilterKind
+	reg                *registry.Registry
+	labelSelector      []selector.LabelRequirement
+	localLabelSelector []selector.LabelRequirement
+	ageFilter          lastCommitAgeFilter
+	countFilter        aheadBehindFilter
+}
+
+// statusReasons explains, per repo path, why each repo in report passed the
+// status filters. Reasons come from the same predicates that did the
+// filtering and are joined in the order the filters run; a repo no filter
+// had anything to say about gets an empty reason.
+func statusReasons(report *model.StatusReport, filters statusReasonFilters) map[string]string {
+	reasons := make(map[string]string, len(report.Repos))
+	normalizer := vcs.NewGitURLNormalizer()
+	for _, repo := range report.Repos {
+		var parts []string
+		if _, reason := engine.ExplainFilter(filters.kind, repo, filters.reg, normalizer); reason != "" {
+			parts = append(parts, reason)
+		}
+		if len(filters.labelSelector) > 0 {
+			if _, reason := selector.ExplainLabelSelector(sharedRepoLabels(repo), filters.labelSelector); reason != "" {
+				parts = append(parts, "matched selector "+reason)
+			}
+		}
+		if len(filters.localLabelSelector) > 0 {
+			if _, reason := selector.ExplainLabelSelector(repo.Labels, filters.localLabelSelector); reason != "" {
+				parts = append(parts, "matched local selector "+reason)
+			}
+		}
+		if filters.ageFilter.active() {
+			if _, reason := filters.ageFilter.explain(repo); reason != "" {
+				parts = append(parts, reason)
+			}
+		}
+		if filters.countFilter.active() {
+			if _, reason := filters.countFilter.explain(repo); reason != "" {
+				parts = append(parts, reason)
+			}
+		}
+		reasons[repo.Path] = strings.Join(parts, "; ")
+	}
+	return reasons
+}
+
 func parseRemoteMismatchReconcileMode(raw string) (remoteMismatchReconcileMode, error) {
 	return engine.ParseRemoteMismatchReconcileMode(raw)
 }
@@ -1052,10 +1803,15 @@ func writeRemoteMismatchPlan(cmd *cobra.Command, plans []remoteMismatchPlan, cwd
 	}
 	rows := make([][]string, 0, len(plans))
 	for _, plan := range plans {
+		remote := plan.Remote
+		if remote == "" {
+			remote = plan.PrimaryRemote
+		}
 		rows = append(rows, []string{
 			displayRepoPath(plan.Path, cwd, roots),
+			plan.Verb,
+			remote,
 			plan.Action,
-			plan.PrimaryRemote,
 			plan.RepoRemoteURL,
 			plan.RegistryURL,
 			plan.RepoID,
@@ -1065,7 +1821,7 @@ func writeRemoteMismatchPlan(cmd *cobra.Command, plans []remoteMismatchPlan, cwd
 		cmd.ErrOrStderr(),
 		false,
 		false,
-		[]string{"PATH", "ACTION", "PRIMARY_REMOTE", "GIT_REMOTE_URL", "REGISTRY_REMOTE_URL", "REPO"},
+		[]string{"PATH", "VERB", "REMOTE", "ACTION", "GIT_REMOTE_URL", "REGISTRY_REMOTE_URL", "REPO"},
 		rows,
 	)
 }
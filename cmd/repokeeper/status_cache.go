@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// statusCacheForRun returns the status cache for a run with --since-scan or
+// --full, or nil when neither is set. --full inspects every repo and only
+// rebuilds the cache; --since-scan alone reuses it.
+func statusCacheForRun(cmd *cobra.Command, path string) (*engine.StatusCache, error) {
+	sinceScan, _ := cmd.Flags().GetBool("since-scan")
+	full, _ := cmd.Flags().GetBool("full")
+	if !sinceScan && !full {
+		return nil, nil
+	}
+	cache, err := loadStatusCache(cmd, path)
+	if err != nil {
+		return nil, err
+	}
+	cache.Reuse = sinceScan && !full
+	return cache, nil
+}
+
+// loadStatusCache reads the cache at path. A missing cache is empty; so is an
+// unreadable or outdated one, since it is rebuilt by the run anyway.
+func loadStatusCache(cmd *cobra.Command, path string) (*engine.StatusCache, error) {
+	empty := &engine.StatusCache{Version: engine.StatusCacheVersion}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cache engine.StatusCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		debugf(cmd, "ignoring invalid status cache %q: %v", path, err)
+		return empty, nil
+	}
+	if cache.Version != engine.StatusCacheVersion {
+		debugf(cmd, "ignoring status cache %q with unsupported version %d", path, cache.Version)
+		return empty, nil
+	}
+	return &cache, nil
+}
+
+func writeStatusCache(path string, cache *engine.StatusCache) error {
+	if cache == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// saveStatusCache prunes repos that left reg, the full registry including
+// entries hidden from the run, and writes the cache refreshed by the run.
+func saveStatusCache(cmd *cobra.Command, path string, cache *engine.StatusCache, reg *registry.Registry) error {
+	if cache == nil {
+		return nil
+	}
+	cache.Prune(reg)
+	debugf(cmd, "status cache: reused %d repos", cache.Reused)
+	return writeStatusCache(path, cache)
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+func newStatusCacheFlagsCmd(sinceScan, full bool) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("since-scan", sinceScan, "")
+	cmd.Flags().Bool("full", full, "")
+	return cmd
+}
+
+func TestStatusCacheForRunFlags(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".repokeeper-status-cache.json")
+	if cache, err := statusCacheForRun(newStatusCacheFlagsCmd(false, false), path); err != nil || cache != nil {
+		t.Fatalf("expected no cache without --since-scan or --full, got %+v (err %v)", cache, err)
+	}
+	cache, err := statusCacheForRun(newStatusCacheFlagsCmd(true, false), path)
+	if err != nil || cache == nil || !cache.Reuse {
+		t.Fatalf("expected a reusable cache for --since-scan, got %+v (err %v)", cache, err)
+	}
+	cache, err = statusCacheForRun(newStatusCacheFlagsCmd(true, true), path)
+	if err != nil || cache == nil || cache.Reuse {
+		t.Fatalf("expected --full to rebuild without reuse, got %+v (err %v)", cache, err)
+	}
+}
+
+func TestStatusCacheRoundTripPrunesRemovedRepos(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".repokeeper-status-cache.json")
+	cache := &engine.StatusCache{Version: engine.StatusCacheVersion, Repos: map[string]engine.StatusCacheEntry{
+		"/work/a":    {Fingerprint: "a1", Status: model.RepoStatus{RepoID: "github.com/org/a", Path: "/work/a"}},
+		"/work/gone": {Fingerprint: "g1", Status: model.RepoStatus{RepoID: "github.com/org/gone", Path: "/work/gone"}},
+		"/work/old":  {Fingerprint: "o1", Status: model.RepoStatus{RepoID: "github.com/org/old", Path: "/work/old"}},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/work/a"},
+		{RepoID: "github.com/org/old", Path: "/work/old", Archived: true},
+	}}
+	// Entries hidden from the run are still registered, so their cache
+	// entries survive.
+	filter := filterRegistrySnapshot(reg, hideArchived(false))
+	if err := saveStatusCache(&cobra.Command{}, path, cache, filter.merged()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := loadStatusCache(&cobra.Command{}, path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded.Repos) != 2 || loaded.Repos["/work/a"].Fingerprint != "a1" || loaded.Repos["/work/old"].Fingerprint != "o1" {
+		t.Fatalf("expected only the registered repos cached, got %+v", loaded.Repos)
+	}
+
+	// A corrupt cache is rebuilt rather than failing the run.
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = loadStatusCache(&cobra.Command{}, path)
+	if err != nil || len(loaded.Repos) != 0 {
+		t.Fatalf("expected an empty cache for a corrupt file, got %+v (err %v)", loaded, err)
+	}
+}
@@ -148,6 +148,7 @@ var _ = Describe("writeStatusDetails", func() {
 				"BARE: false\n" +
 				"BRANCH: \n" +
 				"DIRTY: -\n" +
+				"STASHES: -\n" +
 				"TRACKING: \n" +
 				"UPSTREAM: \n" +
 				"LABELS: -\n" +
@@ -210,6 +211,7 @@ var _ = Describe("writeStatusDetails", func() {
 				"BARE: false\n" +
 				"BRANCH: detached:main\n" +
 				"DIRTY: yes\n" +
+				"STASHES: 0\n" +
 				"TRACKING: up to date\n" +
 				"UPSTREAM: origin/main\n" +
 				"LABELS: -\n" +
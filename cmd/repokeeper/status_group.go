@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// ungroupedStatusGroup collects repos that lack the --group-by key.
+const ungroupedStatusGroup = "(ungrouped)"
+
+// statusGroupBy is a parsed --group-by value. The zero value disables grouping.
+type statusGroupBy struct {
+	host     bool
+	labelKey string
+}
+
+func (g statusGroupBy) active() bool {
+	return g.host || g.labelKey != ""
+}
+
+// parseStatusGroupBy parses --group-by: "host" or "label:<key>".
+func parseStatusGroupBy(raw string) (statusGroupBy, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return statusGroupBy{}, nil
+	}
+	if raw == "host" {
+		return statusGroupBy{host: true}, nil
+	}
+	if key, ok := strings.CutPrefix(raw, "label:"); ok {
+		key = strings.TrimSpace(key)
+		if err := validateMetadataKey(key, "--group-by label"); err != nil {
+			return statusGroupBy{}, err
+		}
+		return statusGroupBy{labelKey: key}, nil
+	}
+	return statusGroupBy{}, fmt.Errorf("unsupported --group-by %q (expected host or label:<key>)", raw)
+}
+
+// statusGroupKey returns the group repo belongs to. Host groups use the host
+// part of the normalized repo ID, so local-only repos are ungrouped. Label
+// groups read repo.Labels, which carry the registry labels once the report
+// has been enriched.
+func statusGroupKey(repo model.RepoStatus, groupBy statusGroupBy) string {
+	var key string
+	switch {
+	case groupBy.host:
+		repoID := strings.TrimSpace(repo.RepoID)
+		if host, _, ok := strings.Cut(repoID, "/"); ok && !strings.HasPrefix(repoID, "local:") {
+			key = host
+		}
+	case groupBy.labelKey != "":
+		key = strings.TrimSpace(repo.Labels[groupBy.labelKey])
+	}
+	if key == "" {
+		return ungroupedStatusGroup
+	}
+	return key
+}
+
+// groupStatusRepos buckets repos by statusGroupKey, keeping report order within
+// each group. Group names are sorted with the ungrouped bucket last.
+func groupStatusRepos(repos []model.RepoStatus, groupBy statusGroupBy) ([]string, map[string][]model.RepoStatus) {
+	groups := make(map[string][]model.RepoStatus)
+	for _, repo := range repos {
+		key := statusGroupKey(repo, groupBy)
+		groups[key] = append(groups[key], repo)
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == ungroupedStatusGroup) != (names[j] == ungroupedStatusGroup) {
+			return names[j] == ungroupedStatusGroup
+		}
+		return names[i] < names[j]
+	})
+	return names, groups
+}
+
+// statusGroupCounts tallies a group for its section header. A repo can count
+// in several columns, for example dirty and gone.
+type statusGroupCounts struct {
+	Clean int
+	Dirty int
+	Gone  int
+	Error int
+}
+
+func countStatusGroup(repos []model.RepoStatus) statusGroupCounts {
+	var counts statusGroupCounts
+	for _, repo := range repos {
+		if repo.Error != "" {
+			counts.Error++
+		}
+		if repo.Tracking.Status == model.TrackingGone {
+			counts.Gone++
+		}
+		if repo.Worktree != nil {
+			if repo.Worktree.Dirty {
+				counts.Dirty++
+			} else {
+				counts.Clean++
+			}
+		}
+	}
+	return counts
+}
+
+// writeGroupedStatusTable prints one status table per group, each under a
+// header with the group's repo count and clean/dirty/gone/error tallies.
+func writeGroupedStatusTable(cmd *cobra.Command, report *model.StatusReport, groupBy statusGroupBy, cwd string, roots []string, noHeaders bool, wide bool) error {
+	names, groups := groupStatusRepos(report.Repos, groupBy)
+	for i, name := range names {
+		repos := groups[name]
+		counts := countStatusGroup(repos)
+		if i > 0 {
+			if _, err := fmt.Fprintln(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(cmd.OutOrStdout(), "== %s (%d repos: %d clean, %d dirty, %d gone, %d error) ==\n",
+			sanitizeForDisplay(name), len(repos), counts.Clean, counts.Dirty, counts.Gone, counts.Error); err != nil {
+			return err
+		}
+		sub := &model.StatusReport{GeneratedAt: report.GeneratedAt, Repos: repos}
+		if err := writeStatusTable(cmd, sub, cwd, roots, noHeaders, wide); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusGroupedJSONReport is the -o json/yaml shape under --group-by: the
+// repos keyed by group name instead of a flat list.
+type statusGroupedJSONReport struct {
+	APIVersion  string                      `json:"apiVersion"`
+	GeneratedAt time.Time                   `json:"generated_at"`
+	Groups      map[string][]statusJSONRepo `json:"groups"`
+}
+
+func groupedStatusJSONOutput(report *model.StatusReport, groupBy statusGroupBy) statusGroupedJSONReport {
+	flat := statusJSONOutputFor(report, false, nil, nil).(statusJSONReport)
+	out := statusGroupedJSONReport{
+		APIVersion:  flat.APIVersion,
+		GeneratedAt: flat.GeneratedAt,
+		Groups:      make(map[string][]statusJSONRepo),
+	}
+	for _, repo := range flat.Repos {
+		key := statusGroupKey(repo.RepoStatus, groupBy)
+		out.Groups[key] = append(out.Groups[key], repo)
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/spf13/cobra"
+)
+
+func TestParseStatusGroupBy(t *testing.T) {
+	t.Parallel()
+
+	if got, err := parseStatusGroupBy(""); err != nil || got.active() {
+		t.Fatalf("expected inactive grouping for empty value, got %+v, %v", got, err)
+	}
+	if got, err := parseStatusGroupBy("host"); err != nil || !got.host {
+		t.Fatalf("expected host grouping, got %+v, %v", got, err)
+	}
+	if got, err := parseStatusGroupBy("label:team"); err != nil || got.labelKey != "team" {
+		t.Fatalf("expected label grouping on team, got %+v, %v", got, err)
+	}
+	for _, raw := range []string{"owner", "label:", "label:a=b"} {
+		if _, err := parseStatusGroupBy(raw); err == nil {
+			t.Fatalf("expected error for --group-by %q", raw)
+		}
+	}
+}
+
+func TestStatusGroupKey(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		repo    model.RepoStatus
+		groupBy statusGroupBy
+		want    string
+	}{
+		{name: "host from repo id", repo: model.RepoStatus{RepoID: "github.com/org/repo"}, groupBy: statusGroupBy{host: true}, want: "github.com"},
+		{name: "local repo is ungrouped", repo: model.RepoStatus{RepoID: "local:/work/repo"}, groupBy: statusGroupBy{host: true}, want: ungroupedStatusGroup},
+		{name: "empty repo id is ungrouped", repo: model.RepoStatus{}, groupBy: statusGroupBy{host: true}, want: ungroupedStatusGroup},
+		{name: "label value", repo: model.RepoStatus{Labels: map[string]string{"team": "infra"}}, groupBy: statusGroupBy{labelKey: "team"}, want: "infra"},
+		{name: "missing label is ungrouped", repo: model.RepoStatus{Labels: map[string]string{"tier": "1"}}, groupBy: statusGroupBy{labelKey: "team"}, want: ungroupedStatusGroup},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if got := statusGroupKey(tc.repo, tc.groupBy); got != tc.want {
+				t.Fatalf("statusGroupKey() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func groupTestReport() *model.StatusReport {
+	return &model.StatusReport{Repos: []model.RepoStatus{
+		{RepoID: "local:/work/scratch", Path: "/work/scratch", Error: "boom"},
+		{RepoID: "gitlab.com/org/b", Path: "/work/b", Worktree: &model.Worktree{Dirty: true}, Tracking: model.Tracking{Status: model.TrackingGone}},
+		{RepoID: "github.com/org/a", Path: "/work/a", Worktree: &model.Worktree{}},
+		{RepoID: "github.com/org/c", Path: "/work/c", Worktree: &model.Worktree{Dirty: true}},
+	}}
+}
+
+func TestGroupStatusReposOrdersUngroupedLast(t *testing.T) {
+	t.Parallel()
+
+	names, groups := groupStatusRepos(groupTestReport().Repos, statusGroupBy{host: true})
+	want := []string{"github.com", "gitlab.com", ungroupedStatusGroup}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected groups %v, got %v", want, names)
+	}
+	if len(groups["github.com"]) != 2 || groups["github.com"][0].Path != "/work/a" {
+		t.Fatalf("expected github.com group in report order, got %+v", groups["github.com"])
+	}
+	counts := countStatusGroup(groups["github.com"])
+	if counts != (statusGroupCounts{Clean: 1, Dirty: 1}) {
+		t.Fatalf("unexpected github.com counts %+v", counts)
+	}
+	counts = countStatusGroup(groups["gitlab.com"])
+	if counts != (statusGroupCounts{Dirty: 1, Gone: 1}) {
+		t.Fatalf("unexpected gitlab.com counts %+v", counts)
+	}
+}
+
+func TestWriteGroupedStatusTable(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	if err := writeGroupedStatusTable(cmd, groupTestReport(), statusGroupBy{host: true}, "/work", nil, false, false); err != nil {
+		t.Fatalf("write grouped table: %v", err)
+	}
+	got := out.String()
+	for _, header := range []string{
+		"== github.com (2 repos: 1 clean, 1 dirty, 0 gone, 0 error) ==",
+		"== gitlab.com (1 repos: 0 clean, 1 dirty, 1 gone, 0 error) ==",
+		"== (ungrouped) (1 repos: 0 clean, 0 dirty, 0 gone, 1 error) ==",
+	} {
+		if !strings.Contains(got, header) {
+			t.Fatalf("expected header %q in output:\n%s", header, got)
+		}
+	}
+	if strings.Index(got, "github.com") > strings.Index(got, "(ungrouped)") {
+		t.Fatalf("expected ungrouped section last:\n%s", got)
+	}
+	if strings.Count(got, "TRACKING") != 3 {
+		t.Fatalf("expected a table header per group:\n%s", got)
+	}
+}
+
+func TestGroupedStatusJSONOutput(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(groupedStatusJSONOutput(groupTestReport(), statusGroupBy{host: true}))
+	if err != nil {
+		t.Fatalf("marshal grouped output: %v", err)
+	}
+	var decoded struct {
+		APIVersion string                       `json:"apiVersion"`
+		Groups     map[string][]json.RawMessage `json:"groups"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal grouped output: %v", err)
+	}
+	if decoded.APIVersion != statusJSONAPIVersion {
+		t.Fatalf("expected apiVersion %q, got %q", statusJSONAPIVersion, decoded.APIVersion)
+	}
+	if len(decoded.Groups["github.com"]) != 2 || len(decoded.Groups["gitlab.com"]) != 1 || len(decoded.Groups[ungroupedStatusGroup]) != 1 {
+		t.Fatalf("unexpected grouped repos: %s", data)
+	}
+}
@@ -10,7 +10,11 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
 	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/selector"
 	"github.com/spf13/cobra"
 )
 
@@ -409,6 +413,32 @@ func TestWriteRepairUpstreamHint(t *testing.T) {
 	})
 }
 
+func TestWriteStaleMetadataHint(t *testing.T) {
+	t.Parallel()
+
+	errOut := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.Flags().Bool("quiet", false, "")
+	cmd.SetErr(errOut)
+
+	report := &model.StatusReport{Repos: []model.RepoStatus{{RepoID: "github.com/org/a"}, {RepoID: "github.com/org/b"}}}
+	if err := writeStaleMetadataHint(cmd, report); err != nil {
+		t.Fatalf("write hint: %v", err)
+	}
+	got := errOut.String()
+	if !strings.Contains(got, "hint: 2 repo(s) have stale registry metadata") || !strings.Contains(got, "repokeeper scan") {
+		t.Fatalf("unexpected stale-metadata hint %q", got)
+	}
+
+	errOut.Reset()
+	if err := writeStaleMetadataHint(cmd, &model.StatusReport{}); err != nil {
+		t.Fatalf("write hint: %v", err)
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("expected no hint for an empty report, got %q", errOut.String())
+	}
+}
+
 // TestStatusJSONOutputNilReportIsSafe guards the nil-report path: building the
 // diverged envelope from a nil report must not panic and must still carry the
 // apiVersion (regression for the buildDivergedAdvice nil-deref).
@@ -462,3 +492,314 @@ func TestDesignDocNamesStatusJSONAPIVersion(t *testing.T) {
 		t.Fatalf("%s §6.3 does not name the current statusJSONAPIVersion %q; update the Status JSON schema section", docPath, statusJSONAPIVersion)
 	}
 }
+
+func TestOverlayRepoLocalLabelsMakesRepoLabelsSelectable(t *testing.T) {
+	newReport := func() *model.StatusReport {
+		return &model.StatusReport{Repos: []model.RepoStatus{
+			{
+				RepoID:       "github.com/org/with-meta",
+				Labels:       map[string]string{"team": "platform"},
+				RepoMetadata: &model.RepoMetadata{Labels: map[string]string{"team": "payments", "tier": "core"}},
+			},
+			{RepoID: "github.com/org/plain", Labels: map[string]string{"team": "platform"}},
+		}}
+	}
+	tierCore, err := selector.ParseLabelSelector("tier=core")
+	if err != nil {
+		t.Fatalf("parse selector: %v", err)
+	}
+
+	disabled := filterStatusReportByLocalLabels(func() *model.StatusReport {
+		report := newReport()
+		overlayRepoLocalLabels(report, config.LabelOverlay{})
+		return report
+	}(), tierCore)
+	if len(disabled.Repos) != 0 {
+		t.Fatalf("expected no matches with overlay disabled, got %+v", disabled.Repos)
+	}
+
+	report := newReport()
+	overlayRepoLocalLabels(report, config.LabelOverlay{Enabled: true})
+	if got := report.Repos[0].Labels["team"]; got != "platform" {
+		t.Fatalf("expected registry label to win by default, got %q", got)
+	}
+	matched := filterStatusReportByLocalLabels(report, tierCore)
+	if len(matched.Repos) != 1 || matched.Repos[0].RepoID != "github.com/org/with-meta" {
+		t.Fatalf("expected repo-local label to be selectable, got %+v", matched.Repos)
+	}
+
+	repoWins := newReport()
+	overlayRepoLocalLabels(repoWins, config.LabelOverlay{Enabled: true, Precedence: config.LabelOverlayPrecedenceRepo})
+	if got := repoWins.Repos[0].Labels["team"]; got != "payments" {
+		t.Fatalf("expected repo-local label to win with repo precedence, got %q", got)
+	}
+	if got := repoWins.Repos[1].Labels["team"]; got != "platform" {
+		t.Fatalf("expected repo without metadata to keep registry labels, got %q", got)
+	}
+}
+
+func TestFilterStatusReportByLastCommitExcludesUnknownAges(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	newReport := func() *model.StatusReport {
+		return &model.StatusReport{Repos: []model.RepoStatus{
+			{RepoID: "recent", LastCommit: now.Add(-5 * 24 * time.Hour)},
+			{RepoID: "dormant", LastCommit: now.Add(-200 * 24 * time.Hour)},
+			{RepoID: "ancient", LastCommit: now.Add(-800 * 24 * time.Hour)},
+			{RepoID: "bare", Bare: true, LastCommit: now.Add(-900 * 24 * time.Hour)},
+			{RepoID: "unborn"},
+		}}
+	}
+	repoIDs := func(report *model.StatusReport) string {
+		ids := make([]string, 0, len(report.Repos))
+		for _, repo := range report.Repos {
+			ids = append(ids, repo.RepoID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	cases := []struct {
+		name, olderThan, newerThan, want string
+	}{
+		{name: "no bounds keeps everything", want: "recent,dormant,ancient,bare,unborn"},
+		{name: "older than", olderThan: "90d", want: "dormant,ancient"},
+		{name: "newer than", newerThan: "4w", want: "recent"},
+		{name: "window", olderThan: "90d", newerThan: "365d", want: "dormant"},
+	}
+	for _, tc := range cases {
+		filter, err := parseLastCommitAgeFilter(tc.olderThan, tc.newerThan, now)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tc.name, err)
+		}
+		if got := repoIDs(filterStatusReportByLastCommit(newReport(), filter)); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseLastCommitAgeFilterRejectsInvalidInput(t *testing.T) {
+	for _, tc := range []struct{ olderThan, newerThan string }{
+		{olderThan: "soon"},
+		{olderThan: "-3d"},
+		{newerThan: "0w"},
+		{olderThan: "30d", newerThan: "7d"},
+	} {
+		if _, err := parseLastCommitAgeFilter(tc.olderThan, tc.newerThan, time.Now()); err == nil {
+			t.Fatalf("expected error for older=%q newer=%q", tc.olderThan, tc.newerThan)
+		}
+	}
+	filter, err := parseLastCommitAgeFilter("720h", "", time.Now())
+	if err != nil || filter.olderThan != 30*24*time.Hour {
+		t.Fatalf("expected Go duration syntax to be accepted, got %+v (%v)", filter, err)
+	}
+}
+
+func TestFilterStatusReportByAheadBehindThresholdsAreInclusive(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	newReport := func() *model.StatusReport {
+		return &model.StatusReport{Repos: []model.RepoStatus{
+			{RepoID: "equal", Tracking: model.Tracking{Ahead: intPtr(0), Behind: intPtr(0)}},
+			{RepoID: "behind-4", Tracking: model.Tracking{Ahead: intPtr(0), Behind: intPtr(4)}},
+			{RepoID: "behind-5", Tracking: model.Tracking{Ahead: intPtr(0), Behind: intPtr(5)}},
+			{RepoID: "behind-40", Tracking: model.Tracking{Ahead: intPtr(0), Behind: intPtr(40)}},
+			{RepoID: "ahead-2", Tracking: model.Tracking{Ahead: intPtr(2), Behind: intPtr(0)}},
+			{RepoID: "diverged", Tracking: model.Tracking{Ahead: intPtr(3), Behind: intPtr(12)}},
+			{RepoID: "no-upstream", Tracking: model.Tracking{Status: model.TrackingNone}},
+		}}
+	}
+	repoIDs := func(report *model.StatusReport) string {
+		ids := make([]string, 0, len(report.Repos))
+		for _, repo := range report.Repos {
+			ids = append(ids, repo.RepoID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	cases := []struct {
+		name                string
+		minAhead, minBehind int
+		want                string
+	}{
+		{name: "no bounds keeps everything", want: "equal,behind-4,behind-5,behind-40,ahead-2,diverged,no-upstream"},
+		{name: "min behind is inclusive", minBehind: 5, want: "behind-5,behind-40,diverged"},
+		{name: "min ahead is inclusive", minAhead: 2, want: "ahead-2,diverged"},
+		{name: "both bounds must hold", minAhead: 1, minBehind: 5, want: "diverged"},
+		{name: "above every count", minBehind: 41, want: ""},
+	}
+	for _, tc := range cases {
+		filter, err := parseAheadBehindFilter(tc.minAhead, tc.minBehind)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tc.name, err)
+		}
+		if got := repoIDs(filterStatusReportByAheadBehind(newReport(), filter)); got != tc.want {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if _, err := parseAheadBehindFilter(0, -1); err == nil || !strings.Contains(err.Error(), "--min-behind") {
+		t.Fatalf("expected negative --min-behind to be rejected, got %v", err)
+	}
+	if _, err := parseAheadBehindFilter(-1, 0); err == nil || !strings.Contains(err.Error(), "--min-ahead") {
+		t.Fatalf("expected negative --min-ahead to be rejected, got %v", err)
+	}
+}
+
+func TestRankDivergedBySeverityOrdersRiskiestFirst(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	intPtr := func(v int) *int { return &v }
+	newReport := func() *model.StatusReport {
+		return &model.StatusReport{Repos: []model.RepoStatus{
+			{
+				Path:       "/repos/clean",
+				Worktree:   &model.Worktree{},
+				Tracking:   model.Tracking{Status: model.TrackingDiverged, Ahead: intPtr(1), Behind: intPtr(3)},
+				LastCommit: now.Add(-24 * time.Hour),
+			},
+			{
+				Path:       "/repos/dirty",
+				Worktree:   &model.Worktree{Dirty: true},
+				Tracking:   model.Tracking{Status: model.TrackingDiverged, Ahead: intPtr(1), Behind: intPtr(1)},
+				LastCommit: now.Add(-24 * time.Hour),
+			},
+		}}
+	}
+
+	report := newReport()
+	scores := rankDivergedBySeverity(report, config.DefaultConfig().DivergedSeverity, now)
+	if report.Repos[0].Path != "/repos/dirty" || report.Repos[1].Path != "/repos/clean" {
+		t.Fatalf("expected dirty diverged repo first, got %s, %s", report.Repos[0].Path, report.Repos[1].Path)
+	}
+	if scores["/repos/dirty"] <= scores["/repos/clean"] {
+		t.Fatalf("expected dirty score above clean, got %v", scores)
+	}
+	advice := buildDivergedAdviceWithSeverity(report.Repos, scores)
+	if advice[0].Severity == nil || *advice[0].Severity != scores["/repos/dirty"] {
+		t.Fatalf("expected advice to carry the severity score, got %#v", advice[0])
+	}
+
+	report = newReport()
+	rankDivergedBySeverity(report, config.DivergedSeverity{BehindWeight: 10, DirtyWeight: 5}, now)
+	if report.Repos[0].Path != "/repos/clean" {
+		t.Fatalf("expected behind-heavy weights to rank the further-behind repo first, got %s", report.Repos[0].Path)
+	}
+}
+
+func TestWriteDivergedStatusTableSeverityColumn(t *testing.T) {
+	behind := 2
+	report := &model.StatusReport{Repos: []model.RepoStatus{
+		{Path: "/repos/a", Tracking: model.Tracking{Status: model.TrackingDiverged, Behind: &behind}},
+	}}
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.Flags().Bool("wrap", false, "")
+	if err := writeDivergedStatusTable(cmd, report, "/", nil, false, false, map[string]float64{"/repos/a": 2}); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "SEVERITY") || !strings.HasPrefix(lines[1], "2.0") {
+		t.Fatalf("unexpected severity table:\n%s", out.String())
+	}
+}
+
+func TestStatusFailFastPredicateHonorsFilters(t *testing.T) {
+	t.Parallel()
+
+	labels, err := selector.ParseLabelSelector("team=infra")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := statusStream{
+		reg: &registry.Registry{Entries: []registry.Entry{
+			{RepoID: "github.com/org/infra", Path: "/work/infra", Labels: map[string]string{"team": "infra"}},
+			{RepoID: "github.com/org/web", Path: "/work/web", Labels: map[string]string{"team": "web"}},
+		}},
+		localLabelSelector: labels,
+	}
+	stop := stream.failFastPredicate()
+	dirty := &model.Worktree{Dirty: true}
+
+	if stop(model.RepoStatus{RepoID: "github.com/org/web", Path: "/work/web", Worktree: dirty}) {
+		t.Fatal("a dirty repo excluded by --local-selector must not stop the run")
+	}
+	if stop(model.RepoStatus{RepoID: "github.com/org/infra", Path: "/work/infra", Worktree: &model.Worktree{}}) {
+		t.Fatal("a clean repo must not stop the run")
+	}
+	if !stop(model.RepoStatus{RepoID: "github.com/org/infra", Path: "/work/infra", Worktree: dirty}) {
+		t.Fatal("a dirty matching repo must stop the run")
+	}
+	if !stop(model.RepoStatus{RepoID: "github.com/org/infra", Path: "/work/infra", Error: "boom"}) {
+		t.Fatal("an inspection error on a matching repo must stop the run")
+	}
+}
+
+func TestStatusReasonsExplainEachFilterKind(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	diverged := model.RepoStatus{
+		RepoID:       "github.com/org/api",
+		Path:         "/repos/api",
+		RepoMetadata: &model.RepoMetadata{Labels: map[string]string{"tier": "prod"}},
+		Labels:       map[string]string{"team": "core"},
+		LastCommit:   now.Add(-100 * 24 * time.Hour),
+		Tracking:     model.Tracking{Status: model.TrackingDiverged, Ahead: intPtr(2), Behind: intPtr(3)},
+	}
+	dirty := model.RepoStatus{RepoID: "github.com/org/web", Path: "/repos/web", Worktree: &model.Worktree{Dirty: true, Unstaged: 4}}
+	labelSelector, _ := selector.ParseLabelSelector("tier=prod")
+	localSelector, _ := selector.ParseLabelSelector("team")
+
+	for _, tc := range []struct {
+		name    string
+		repo    model.RepoStatus
+		filters statusReasonFilters
+		want    string
+	}{
+		{name: "tracking filter", repo: diverged, filters: statusReasonFilters{kind: engine.FilterDiverged}, want: "diverged: 2 ahead, 3 behind"},
+		{name: "worktree filter", repo: dirty, filters: statusReasonFilters{kind: engine.FilterDirty}, want: "dirty: 0 staged, 4 unstaged, 0 untracked"},
+		{name: "selector", repo: diverged, filters: statusReasonFilters{kind: engine.FilterAll, labelSelector: labelSelector}, want: "matched selector tier=prod"},
+		{name: "local selector", repo: diverged, filters: statusReasonFilters{localLabelSelector: localSelector}, want: "matched local selector team=core"},
+		{
+			name:    "age and thresholds",
+			repo:    diverged,
+			filters: statusReasonFilters{ageFilter: lastCommitAgeFilter{now: now, olderThan: 90 * 24 * time.Hour}, countFilter: aheadBehindFilter{minBehind: 3}},
+			want:    "last commit 100d ago; 3 behind (min 3)",
+		},
+		{name: "no filter", repo: dirty, filters: statusReasonFilters{kind: engine.FilterAll}, want: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reasons := statusReasons(&model.StatusReport{Repos: []model.RepoStatus{tc.repo}}, tc.filters)
+			if got := reasons[tc.repo.Path]; got != tc.want {
+				t.Fatalf("got reason %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStatusExplainOutputAddsReason(t *testing.T) {
+	report := &model.StatusReport{Repos: []model.RepoStatus{
+		{RepoID: "github.com/org/api", Path: "/repos/api", Tracking: model.Tracking{Status: model.TrackingGone, Upstream: "origin/main"}},
+		{RepoID: "github.com/org/web", Path: "/repos/web"},
+	}}
+	reasons := map[string]string{"/repos/api": "gone: upstream origin/main no longer exists", "/repos/web": ""}
+
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.Flags().Bool("wrap", true, "")
+	if err := writeStatusTableWithReasons(cmd, report, "/", nil, false, false, reasons); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(strings.TrimSpace(lines[0]), "REASON") {
+		t.Fatalf("expected a trailing REASON column, got:\n%s", out.String())
+	}
+	if !strings.HasSuffix(lines[1], "gone: upstream origin/main no longer exists") || !strings.HasSuffix(strings.TrimSpace(lines[2]), "-") {
+		t.Fatalf("expected per-repo reasons with a dash for none, got:\n%s", out.String())
+	}
+
+	data, err := json.Marshal(statusJSONOutputFor(report, false, nil, reasons))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"reason":"gone: upstream origin/main no longer exists"`) || strings.Count(string(data), `"reason"`) != 1 {
+		t.Fatalf("expected one reason field in JSON, got %s", data)
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/selector"
+	"github.com/spf13/cobra"
+)
+
+// parseStatusOutputMode extends parseOutputMode with ndjson (alias jsonl),
+// which only status can stream. Other commands keep rejecting it up front.
+func parseStatusOutputMode(format string) (outputMode, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case string(outputKindNDJSON), "jsonl":
+		return outputMode{kind: outputKindNDJSON}, nil
+	}
+	return parseOutputMode(format)
+}
+
+// statusStream writes `status -o ndjson` output: one statusJSONRepo object per
+// line, in the order inspections complete. Each repo goes through the same
+// metadata enrichment and label, age, and ahead/behind filters as the
+// collected report, and the exit code is accumulated as lines are written so
+// it matches statusExitCode.
+type statusStream struct {
+	reg                *registry.Registry
+	labelOverlay       config.LabelOverlay
+	labelSelector      []selector.LabelRequirement
+	localLabelSelector []selector.LabelRequirement
+	ageFilter          lastCommitAgeFilter
+	countFilter        aheadBehindFilter
+	verifyIgnored      bool
+
+	count       int
+	code        int
+	ignoredSeen bool
+}
+
+func (s *statusStream) run(cmd *cobra.Command, eng *engine.Engine, opts engine.StatusOptions) error {
+	byPath := registryEntriesByPath(s.reg)
+	out := cmd.OutOrStdout()
+	var writeErr error
+	err := eng.StatusStream(cmd.Context(), opts, func(repo model.RepoStatus) {
+		repo, ok := s.keep(repo, byPath)
+		if !ok {
+			return
+		}
+		s.count++
+		s.code = max(s.code, repoStatusExitCode(repo))
+		if s.verifyIgnored && len(reposWithIgnoredFiles(&model.StatusReport{Repos: []model.RepoStatus{repo}})) > 0 {
+			s.ignoredSeen = true
+		}
+		if writeErr != nil {
+			return
+		}
+		data, err := json.Marshal(statusJSONRepo{
+			RepoStatus:               repo,
+			LocalLabels:              cloneMetadataMap(repo.Labels),
+			RepairUpstreamSuggestion: repo.Tracking.Status == model.TrackingGone,
+		})
+		if err != nil {
+			writeErr = err
+			return
+		}
+		_, writeErr = fmt.Fprintln(out, string(data))
+	})
+	if err != nil {
+		return err
+	}
+	// Keep inspecting after a write failure so registry snapshots and the exit
+	// code still cover every repo; the failure itself is only logged, like the
+	// other status formats.
+	logOutputWriteFailure(cmd, "status ndjson", writeErr)
+	return nil
+}
+
+// keep applies the per-repo part of the status pipeline to one streamed repo.
+func (s *statusStream) keep(repo model.RepoStatus, byPath map[string]registry.Entry) (model.RepoStatus, bool) {
+	enrichRepoWithRegistryMetadata(&repo, s.reg, byPath)
+	report := &model.StatusReport{Repos: []model.RepoStatus{repo}}
+	overlayRepoLocalLabels(report, s.labelOverlay)
+	report = filterStatusReportByLabels(report, s.labelSelector)
+	report = filterStatusReportByLocalLabels(report, s.localLabelSelector)
+	report = filterStatusReportByLastCommit(report, s.ageFilter)
+	report = filterStatusReportByAheadBehind(report, s.countFilter)
+	if len(report.Repos) == 0 {
+		return model.RepoStatus{}, false
+	}
+	return report.Repos[0], true
+}
+
+// failFastPredicate returns the --fail-fast StopWhen check: true for the
+// first repo that survives the stream's filters and would raise the exit code.
+func (s *statusStream) failFastPredicate() func(model.RepoStatus) bool {
+	byPath := registryEntriesByPath(s.reg)
+	return func(repo model.RepoStatus) bool {
+		repo, ok := s.keep(repo, byPath)
+		if !ok {
+			return false
+		}
+		if repoStatusExitCode(repo) > 0 {
+			return true
+		}
+		return s.verifyIgnored && len(reposWithIgnoredFiles(&model.StatusReport{Repos: []model.RepoStatus{repo}})) > 0
+	}
+}
+
+func (s *statusStream) exitCode() int {
+	code := max(s.code, registryStatusExitCode(s.reg))
+	if s.ignoredSeen {
+		code = max(code, 1)
+	}
+	return code
+}
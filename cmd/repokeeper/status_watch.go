@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// clearScreen moves the cursor home and erases the display.
+const clearScreen = "\x1b[H\x1b[2J"
+
+// runStatusWatch calls runOnce every interval until the command context is
+// cancelled (Ctrl-C), which ends the watch without an error. On a terminal
+// the screen is cleared before each report and a watch(1)-style header is
+// printed; other output gets a timestamp line before each report so
+// successive reports stay apart in a log. When lines is set (-o json and
+// -o ndjson) nothing is written between reports, so the output stays one
+// JSON object per line. Each report starts from exit code 0, so the exit code
+// reflects the last complete report. The engine stays single-shot: every
+// cycle reloads the config and registry and re-runs the full status pipeline.
+func runStatusWatch(cmd *cobra.Command, interval time.Duration, lines bool, runOnce func() error) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	out := cmd.OutOrStdout()
+	clear := false
+	if file, ok := out.(*os.File); ok {
+		clear = isTerminalFD(int(file.Fd()))
+	}
+	state := runtimeStateFor(cmd)
+	for {
+		now := time.Now().Format(time.RFC3339)
+		var err error
+		switch {
+		case lines:
+			// A header would break the one-object-per-line stream.
+		case clear:
+			_, err = fmt.Fprintf(out, "%sEvery %s: repokeeper %s\t%s\n\n", clearScreen, interval, cmd.Name(), now)
+		default:
+			_, err = fmt.Fprintf(out, "--- %s ---\n", now)
+		}
+		logOutputWriteFailure(cmd, "status watch header", err)
+
+		previous := state.exitCode
+		state.exitCode = 0
+		if err := runOnce(); err != nil {
+			if ctx.Err() != nil {
+				state.exitCode = previous
+				return nil
+			}
+			return err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// marshalStatusJSON encodes a status -o json document: indented for a single
+// report, compact under --watch so each report is one NDJSON line.
+func marshalStatusJSON(cmd *cobra.Command, v any) ([]byte, error) {
+	if watch, _ := cmd.Flags().GetDuration("watch"); watch > 0 {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
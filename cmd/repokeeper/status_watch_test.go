@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatusWatchRunsUntilCancelled(t *testing.T) {
+	cfgPath, regPath := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(ctx)
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	defer statusCmd.SetContext(context.Background())
+
+	_ = statusCmd.Flags().Set("registry", regPath)
+	_ = statusCmd.Flags().Set("format", "table")
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("selector", "")
+
+	cycles := 0
+	err := runStatusWatch(statusCmd, 10*time.Millisecond, false, func() error {
+		cycles++
+		err := runStatus(statusCmd)
+		if cycles == 2 {
+			cancel()
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if cycles != 2 {
+		t.Fatalf("expected two cycles before cancellation, got %d", cycles)
+	}
+	got := out.String()
+	if n := strings.Count(got, "--- "); n != 2 {
+		t.Fatalf("expected a timestamp separator per report, got %d in %q", n, got)
+	}
+	if n := strings.Count(got, "missing-repo"); n != 2 {
+		t.Fatalf("expected the repo in both reports, got %d in %q", n, got)
+	}
+	if strings.Contains(got, clearScreen) {
+		t.Fatalf("expected no screen clearing for non-terminal output, got %q", got)
+	}
+}
+
+func TestStatusWatchJSONWritesOneObjectPerLine(t *testing.T) {
+	cfgPath, regPath := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &bytes.Buffer{}
+	statusCmd.SetOut(out)
+	statusCmd.SetErr(&bytes.Buffer{})
+	statusCmd.SetContext(ctx)
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	defer statusCmd.SetContext(context.Background())
+
+	_ = statusCmd.Flags().Set("registry", regPath)
+	_ = statusCmd.Flags().Set("format", "json")
+	_ = statusCmd.Flags().Set("only", "all")
+	_ = statusCmd.Flags().Set("selector", "")
+	_ = statusCmd.Flags().Set("watch", "10ms")
+	defer func() {
+		_ = statusCmd.Flags().Set("format", "table")
+		_ = statusCmd.Flags().Set("watch", "0s")
+	}()
+
+	cycles := 0
+	err := runStatusWatch(statusCmd, 10*time.Millisecond, true, func() error {
+		cycles++
+		err := runStatus(statusCmd)
+		if cycles == 2 {
+			cancel()
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per report, got %d in %q", len(lines), out.String())
+	}
+	for _, line := range lines {
+		var report map[string]any
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Fatalf("expected a JSON object per line, got %q: %v", line, err)
+		}
+		if _, ok := report["repos"]; !ok {
+			t.Fatalf("expected a status report, got %q", line)
+		}
+	}
+}
+
+func TestStatusWatchFlagValidation(t *testing.T) {
+	cfgPath, regPath := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	statusCmd.SetOut(&bytes.Buffer{})
+	statusCmd.SetErr(&bytes.Buffer{})
+	defer statusCmd.SetOut(os.Stdout)
+	defer statusCmd.SetErr(os.Stderr)
+	_ = statusCmd.Flags().Set("registry", regPath)
+
+	_ = statusCmd.Flags().Set("watch", "-1s")
+	err := statusCmd.RunE(statusCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--watch must be positive") {
+		t.Fatalf("expected negative watch error, got %v", err)
+	}
+
+	_ = statusCmd.Flags().Set("watch", "1s")
+	_ = statusCmd.Flags().Set("reconcile-remote-mismatch", "registry")
+	err = statusCmd.RunE(statusCmd, nil)
+	_ = statusCmd.Flags().Set("watch", "0s")
+	_ = statusCmd.Flags().Set("reconcile-remote-mismatch", "none")
+	if err == nil || !strings.Contains(err.Error(), "--watch cannot be combined with --reconcile-remote-mismatch") {
+		t.Fatalf("expected reconcile validation error, got %v", err)
+	}
+}
@@ -2,9 +2,13 @@
 package repokeeper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -12,8 +16,10 @@ import (
 
 	"github.com/skaphos/repokeeper/internal/cliio"
 	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/discovery"
 	"github.com/skaphos/repokeeper/internal/engine"
 	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
 	"github.com/skaphos/repokeeper/internal/selector"
 	"github.com/skaphos/repokeeper/internal/strutil"
 	"github.com/skaphos/repokeeper/internal/tableutil"
@@ -35,7 +41,7 @@ var syncCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		cfg, err := config.Load(cfgPath)
+		cfg, err := loadConfig(cmd, cfgPath)
 		if err != nil {
 			return err
 		}
@@ -50,6 +56,7 @@ var syncCmd = &cobra.Command{
 		only, _ := cmd.Flags().GetString("only")
 		fieldSelector, _ := cmd.Flags().GetString("field-selector")
 		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		perHostConcurrency, _ := cmd.Flags().GetInt("concurrency-per-host")
 		timeout, _ := cmd.Flags().GetInt("timeout")
 		continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
@@ -61,18 +68,68 @@ var syncCmd = &cobra.Command{
 		protectedBranchesRaw, _ := cmd.Flags().GetString("protected-branches")
 		allowProtectedRebase, _ := cmd.Flags().GetBool("allow-protected-rebase")
 		checkoutMissing, _ := cmd.Flags().GetBool("checkout-missing")
+		remoteTemplate, _ := cmd.Flags().GetString("remote-template")
+		cloneDepth, _ := cmd.Flags().GetInt("depth")
+		preRunCommand, _ := cmd.Flags().GetString("pre-run-command")
+		strictHooks, _ := cmd.Flags().GetBool("strict-hooks")
+		summary, _ := cmd.Flags().GetBool("summary")
+		retries, _ := cmd.Flags().GetInt("retries")
+		retryBackoff, _ := cmd.Flags().GetDuration("retry-backoff")
+		allowOversubscribe, _ := cmd.Flags().GetBool("allow-oversubscribe")
+		deepen, _ := cmd.Flags().GetInt("deepen")
+		fetchRemote, _ := cmd.Flags().GetString("remote")
+		noPruneTags, _ := cmd.Flags().GetBool("no-prune-tags")
+		backupBranch, _ := cmd.Flags().GetString("backup-branch")
+		pruneEmptyDirs, _ := cmd.Flags().GetBool("prune-empty-dirs")
+		deleteGoneBranches, _ := cmd.Flags().GetBool("delete-gone-branches")
+		autostashAll, _ := cmd.Flags().GetBool("autostash-all")
+		resetHard, _ := cmd.Flags().GetBool("reset-hard")
+		lfs, _ := cmd.Flags().GetBool("lfs")
+		updateSubmodules, _ := cmd.Flags().GetBool("update-submodules")
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		reportPath, _ := cmd.Flags().GetString("report")
+		logDir, _ := cmd.Flags().GetString("log-dir")
+		reportPath = strings.TrimSpace(reportPath)
+		planOnly, _ := cmd.Flags().GetBool("plan-only")
+		planOutput, _ := cmd.Flags().GetString("output")
+		fromLastRun, _ := cmd.Flags().GetBool("from-last-run")
 		format, _ := cmd.Flags().GetString("format")
-		mode, err := parseOutputMode(format)
+		mode, err := parseTemplatedOutputMode(cmd, format, withCSVOutputMode(parseOutputMode))
 		if err != nil {
 			return err
 		}
 		noHeaders, _ := cmd.Flags().GetBool("no-headers")
 		wrap, _ := cmd.Flags().GetBool("wrap")
-		if concurrency > 0 && concurrency > 64 {
-			return fmt.Errorf("--concurrency must be <= 64, got %d", concurrency)
+		nameOnly, err := parseNameOnlyOutput(cmd)
+		if err != nil {
+			return err
+		}
+		if err := validateSyncExecutionFlags(timeout, retries, retryBackoff); err != nil {
+			return err
+		}
+		if perHostConcurrency < 0 {
+			return fmt.Errorf("--concurrency-per-host must not be negative, got %d", perHostConcurrency)
+		}
+		maxJobs, err := maxJobsOverride(cmd)
+		if err != nil {
+			return err
+		}
+		sortBy = strings.ToLower(strings.TrimSpace(sortBy))
+		if sortBy != "" && sortBy != syncSortByDuration {
+			return fmt.Errorf("unsupported --sort-by %q (expected %s)", sortBy, syncSortByDuration)
+		}
+		if deepen < 0 {
+			return fmt.Errorf("--deepen must not be negative, got %d", deepen)
+		}
+		fetchRemote = strings.TrimSpace(fetchRemote)
+		if strings.HasPrefix(fetchRemote, "-") || strings.ContainsAny(fetchRemote, " \t") {
+			return fmt.Errorf("--remote must be a remote name, got %q", fetchRemote)
 		}
-		if timeout > 0 && timeout > 600 {
-			return fmt.Errorf("--timeout must be <= 600, got %d", timeout)
+		if planOutput != "" && !planOnly {
+			return fmt.Errorf("--output requires --plan-only")
+		}
+		if planOnly && strings.TrimSpace(planOutput) == "" {
+			return fmt.Errorf("--plan-only requires --output")
 		}
 		if rebaseDirty && !updateLocal {
 			return fmt.Errorf("--rebase-dirty requires --update-local")
@@ -80,19 +137,68 @@ var syncCmd = &cobra.Command{
 		if pushLocal && !updateLocal {
 			return fmt.Errorf("--push-local requires --update-local")
 		}
+		remoteTemplate = strings.TrimSpace(remoteTemplate)
+		if remoteTemplate != "" && !checkoutMissing {
+			return fmt.Errorf("--remote-template requires --checkout-missing")
+		}
+		if cloneDepth < 0 {
+			return fmt.Errorf("--depth must not be negative, got %d", cloneDepth)
+		}
+		if cloneDepth > 0 && !checkoutMissing {
+			return fmt.Errorf("--depth requires --checkout-missing")
+		}
+		if remoteTemplate != "" && !strings.Contains(remoteTemplate, "{name}") {
+			return fmt.Errorf("--remote-template must contain {name}, got %q", remoteTemplate)
+		}
+		if resetHard && updateLocal {
+			return fmt.Errorf("--reset-hard cannot be combined with --update-local")
+		}
+		if resetHard && autostashAll {
+			return fmt.Errorf("--reset-hard cannot be combined with --autostash-all")
+		}
+		if updateSubmodules && !updateLocal {
+			return fmt.Errorf("--update-submodules requires --update-local")
+		}
+		backupBranch = strings.TrimSpace(backupBranch)
+		if backupBranch != "" && !updateLocal {
+			return fmt.Errorf("--backup-branch requires --update-local")
+		}
+		if strings.HasPrefix(backupBranch, "-") || strings.ContainsAny(backupBranch, " \t") {
+			return fmt.Errorf("--backup-branch must be a branch name template, got %q", backupBranch)
+		}
 		filter, err := selector.ResolveRepoFilter(only, fieldSelector)
 		if err != nil {
 			return err
 		}
+		if filter == engine.FilterBranchesBehindDefault {
+			return fmt.Errorf("--only %s is only supported by get", filter)
+		}
+		snapshotFilter, err := newRegistrySnapshotFilter(cmd, reg)
+		if err != nil {
+			return err
+		}
+		var replayPaths []string
+		if fromLastRun {
+			replayPaths, err = loadLastSyncFailures(config.LastSyncPath(cfgPath))
+			if err != nil {
+				return err
+			}
+			if len(replayPaths) == 0 {
+				infof(cmd, "last sync run recorded no failures; nothing to replay")
+				return nil
+			}
+			debugf(cmd, "replaying %d failures from the last sync run", len(replayPaths))
+		}
 
 		adapter, err := selectedAdapterForCommand(cmd)
 		if err != nil {
 			return err
 		}
-		eng := engine.New(cfg, reg, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
-		plan, err := eng.Sync(cmd.Context(), engine.SyncOptions{
+		eng := engine.New(cfg, snapshotFilter.snapshot, adapter, vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), cmdLogger{cmd})
+		planOpts := engine.SyncOptions{
 			Filter:               filter,
 			Concurrency:          concurrency,
+			PerHostConcurrency:   perHostConcurrency,
 			Timeout:              timeout,
 			ContinueOnError:      continueOnError,
 			DryRun:               true,
@@ -103,24 +209,44 @@ var syncCmd = &cobra.Command{
 			ProtectedBranches:    strutil.SplitCSV(protectedBranchesRaw),
 			AllowProtectedRebase: allowProtectedRebase,
 			CheckoutMissing:      checkoutMissing,
-		})
+			CloneDepth:           cloneDepth,
+			RemoteTemplate:       remoteTemplate,
+			RetryAttempts:        retries,
+			RetryBackoff:         retryBackoff,
+			AllowOversubscribe:   allowOversubscribe,
+			MaxJobs:              maxJobs,
+			AutostashAll:         autostashAll,
+			LFS:                  lfs,
+			UpdateSubmodules:     updateSubmodules,
+			Deepen:               deepen,
+			FetchRemote:          fetchRemote,
+			Paths:                replayPaths,
+			NoPruneTags:          noPruneTags,
+			BackupBranch:         backupBranch,
+			ResetHard:            resetHard,
+		}
+		plan, err := eng.Sync(cmd.Context(), planOpts)
 		if err != nil {
 			return err
 		}
 		// Keep sync output stable across runs regardless of goroutine completion order.
-		sort.SliceStable(plan, func(i, j int) bool {
-			if plan[i].RepoID == plan[j].RepoID {
-				return plan[i].Action < plan[j].Action
-			}
-			return plan[i].RepoID < plan[j].RepoID
-		})
+		sortSyncResultsByRepo(plan)
 		logOutputWriteFailure(cmd, "sync plan", writeSyncPlan(cmd, plan, cwd, []string{cfgRoot}))
+		if planOnly {
+			if err := writeSavedSyncPlan(planOutput, plan); err != nil {
+				return err
+			}
+			infof(cmd, "saved sync plan for %d repos to %s", len(plan), planOutput)
+			// A saved plan is applied later with `repokeeper apply`; nothing
+			// runs now, so the rest of this invocation behaves like --dry-run.
+			dryRun = true
+		}
 		// --dry-run never applies any of the planned operations, so there is
 		// nothing to confirm. Prompting anyway means a non-interactive dry-run
 		// (e.g. piped stdin, -o json in CI) hits EOF/decline on the prompt and
 		// exits early without printing the plan output callers expect.
 		if !dryRun && !yes && syncPlanNeedsConfirmation(plan) {
-			confirmed, err := confirmSyncExecution(cmd)
+			confirmed, err := confirmSyncExecution(cmd, plan)
 			if err != nil {
 				return err
 			}
@@ -131,105 +257,170 @@ var syncCmd = &cobra.Command{
 		}
 
 		results := plan
-		streamResults := shouldStreamSyncResults(cmd, dryRun, mode.kind)
+		// Streamed rows are printed as repos finish, so a sorted run buffers
+		// them and prints the table once at the end instead.
+		streamResults := sortBy == "" && !nameOnly.enabled && shouldStreamSyncResults(cmd, dryRun, mode.kind)
 		if !dryRun {
-			var streamWriter *syncProgressWriter
-			if streamResults {
-				streamWriter = newSyncProgressWriter(cmd, cwd, []string{cfgRoot})
+			if err := runSyncPreRunCommand(cmd, preRunCommand); err != nil {
+				return err
 			}
-
-			results, err = eng.ExecuteSyncPlanWithCallbacks(cmd.Context(), plan, engine.SyncOptions{
-				Concurrency:     concurrency,
-				Timeout:         timeout,
-				ContinueOnError: continueOnError,
-			}, func(res engine.SyncResult) {
-				if streamWriter == nil {
-					return
-				}
-				if streamErr := streamWriter.StartResult(res); streamErr != nil {
-					logOutputWriteFailure(cmd, "sync stream start", streamErr)
-				}
-			}, func(res engine.SyncResult) {
-				if streamWriter == nil {
-					return
-				}
-				if streamErr := streamWriter.WriteResult(res); streamErr != nil {
-					logOutputWriteFailure(cmd, "sync stream row", streamErr)
-				}
-			})
+			hook, err := postSyncHook(cfg.Hooks.PostSync)
+			if err != nil {
+				return err
+			}
+			results, err = executeSyncPlan(cmd, eng, plan, engine.SyncOptions{
+				Concurrency:        concurrency,
+				PerHostConcurrency: perHostConcurrency,
+				Timeout:            timeout,
+				ContinueOnError:    continueOnError,
+				RetryAttempts:      retries,
+				RetryBackoff:       retryBackoff,
+				AllowOversubscribe: allowOversubscribe,
+				MaxJobs:            maxJobs,
+				PostSync:           hook,
+				StrictHooks:        strictHooks,
+				CaptureOutput:      strings.TrimSpace(logDir) != "",
+			}, cwd, []string{cfgRoot}, streamResults)
 			if err != nil {
 				return err
 			}
-			sort.SliceStable(results, func(i, j int) bool {
-				if results[i].RepoID == results[j].RepoID {
-					return results[i].Action < results[j].Action
+			// Like the run record below, the logs only help debugging after the
+			// fact, so failing to write them warns instead of failing the sync.
+			if strings.TrimSpace(logDir) != "" {
+				written, err := writeSyncLogs(logDir, results)
+				if err != nil {
+					warnf(cmd, "could not write sync logs to %s: %v", logDir, err)
+				} else {
+					debugf(cmd, "wrote %d sync logs to %s", written, logDir)
 				}
-				return results[i].RepoID < results[j].RepoID
-			})
+			}
+			cfg.Registry = snapshotFilter.merged()
 			if err := persistSyncRegistryAfterCheckoutMissing(cfg, cfgPath, results); err != nil {
 				return err
 			}
+			// The repos have already been touched, so a failure to record the
+			// run only costs the next --from-last-run; warn instead of failing.
+			if err := writeSavedSyncPlan(config.LastSyncPath(cfgPath), results); err != nil {
+				warnf(cmd, "could not record sync run: %v", err)
+			}
+			if sortBy == syncSortByDuration {
+				sortSyncResultsByDuration(results)
+			}
+		}
+		if reportPath != "" {
+			planOpts.DryRun = dryRun
+			logOutputWriteFailure(cmd, "sync report", writeSyncRunReport(reportPath, planOpts, results, time.Now()))
 		}
 
-		switch mode.kind {
-		case outputKindJSON:
-			setColorOutputMode(cmd, string(mode.kind))
-			data, err := json.MarshalIndent(toSyncResultJSONs(results), "", "  ")
-			if err != nil {
+		if err := reportSyncResults(cmd, results, syncReportOptions{
+			mode:          mode,
+			format:        format,
+			cwd:           cwd,
+			roots:         []string{cfgRoot},
+			noHeaders:     noHeaders,
+			wrap:          wrap,
+			streamResults: streamResults,
+			summary:       summary,
+			dryRun:        dryRun,
+			nameOnly:      nameOnly,
+		}); err != nil {
+			return err
+		}
+		if pruneEmptyDirs {
+			if err := pruneEmptyRootDirs(cmd, cfg, cfgPath, snapshotFilter.merged(), dryRun); err != nil {
 				return err
 			}
-			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
-			logOutputWriteFailure(cmd, "sync json", err)
-		case outputKindCustomColumns:
-			setColorOutputMode(cmd, string(mode.kind))
-			logOutputWriteFailure(cmd, "sync custom-columns", writeCustomColumnsOutput(cmd, results, mode.expr, noHeaders))
-		case outputKindTable:
-			setColorOutputMode(cmd, string(mode.kind))
-			if !streamResults {
-				logOutputWriteFailure(cmd, "sync table", writeSyncTable(cmd, results, nil, cwd, []string{cfgRoot}, wrap, noHeaders, false))
-			}
-		case outputKindWide:
-			setColorOutputMode(cmd, string(mode.kind))
-			if !streamResults {
-				logOutputWriteFailure(cmd, "sync wide", writeSyncTable(cmd, results, nil, cwd, []string{cfgRoot}, wrap, noHeaders, true))
-			}
-		default:
-			return fmt.Errorf("unsupported format %q", format)
 		}
-		for _, res := range results {
-			if !res.OK {
-				// Missing repos are warning-level; operational failures are error-level.
-				if res.Error == engine.SyncErrorMissing {
-					raiseExitCode(cmd, 1)
-					continue
-				}
-				raiseExitCode(cmd, 2)
-				continue
-			}
-			if _, skippedLocalUpdate := syncLocalUpdateSkipReason(res); skippedLocalUpdate {
-				raiseExitCode(cmd, 1)
+		if deleteGoneBranches {
+			if err := deleteGoneBranchesAfterSync(cmd, eng, results, goneBranchCleanupOptions{
+				engine: engine.GoneBranchesOptions{
+					Concurrency: concurrency,
+					Timeout:     timeout,
+					MaxJobs:     maxJobs,
+					Force:       force,
+				},
+				dryRun:    dryRun,
+				yes:       yes,
+				table:     mode.kind == outputKindTable && !nameOnly.enabled,
+				cwd:       cwd,
+				roots:     []string{cfgRoot},
+				noHeaders: noHeaders,
+			}); err != nil {
+				return err
 			}
 		}
-		logOutputWriteFailure(cmd, "sync failure summary", writeSyncFailureSummary(cmd, results, cwd, []string{cfgRoot}))
 		infof(cmd, "sync completed: %d repos", len(results))
 		return nil
 	},
 }
 
+// pruneEmptyRootDirs removes directories under the configured roots that
+// moved or deleted repos left empty. Registered paths are kept even when
+// empty, so no tracked checkout location is ever removed, and the configured
+// exclude patterns are honoured as they are by scan.
+func pruneEmptyRootDirs(cmd *cobra.Command, cfg *config.Config, cfgPath string, reg *registry.Registry, dryRun bool) error {
+	keep := make([]string, 0, len(reg.Entries))
+	for _, entry := range reg.Entries {
+		keep = append(keep, entry.Path)
+	}
+	removed, err := discovery.PruneEmptyDirs(discovery.PruneOptions{
+		Roots:       config.DefaultScanRoots(cfg, cfgPath),
+		Keep:        keep,
+		Exclude:     cfg.Exclude,
+		RootExclude: config.RootExcludes(cfg, cfgPath),
+		DryRun:      dryRun,
+	})
+	for _, dir := range removed {
+		if dryRun {
+			infof(cmd, "would remove empty directory %s", dir)
+		} else {
+			infof(cmd, "removed empty directory %s", dir)
+		}
+	}
+	return err
+}
+
 func init() {
 	addRepoFilterFlags(syncCmd)
 	syncCmd.Flags().Int("concurrency", 0, "max concurrent repo operations (default: min(8, NumCPU))")
+	syncCmd.Flags().Int("concurrency-per-host", 0, concurrencyPerHostUsage)
+	syncCmd.Flags().Bool("allow-oversubscribe", false, allowOversubscribeUsage)
 	syncCmd.Flags().Int("timeout", 0, "timeout in seconds per repo (0 uses config default)")
 	syncCmd.Flags().Bool("continue-on-error", true, "continue syncing remaining repos after a per-repo failure")
 	syncCmd.Flags().Bool("dry-run", false, "print intended operations without executing")
 	syncCmd.Flags().Bool("update-local", false, "after fetch, run pull --rebase for the checked-out tracking branch when safe")
 	syncCmd.Flags().Bool("push-local", false, "when used with --update-local, push branches that are ahead of upstream")
 	syncCmd.Flags().Bool("rebase-dirty", false, "when used with --update-local, stash local changes before rebase and pop afterwards")
-	syncCmd.Flags().Bool("force", false, "when used with --update-local, allow rebase even when branch tracking state is diverged")
+	syncCmd.Flags().Bool("force", false, syncForceUsage)
 	syncCmd.Flags().String("protected-branches", "", "comma-separated branch patterns to protect from auto-rebase during --update-local (default: none)")
 	syncCmd.Flags().Bool("allow-protected-rebase", false, "when used with --update-local, allow rebase on branches matched by --protected-branches")
 	syncCmd.Flags().Bool("checkout-missing", false, "clone missing repos from registry remote_url back to their registered paths")
-	addFormatFlag(syncCmd, "output format: table, wide, or json")
+	syncCmd.Flags().String("remote-template", "", remoteTemplateUsage)
+	syncCmd.Flags().Int("depth", 0, cloneDepthUsage)
+	syncCmd.Flags().Int("deepen", 0, deepenUsage)
+	syncCmd.Flags().String("remote", "", fetchRemoteUsage)
+	syncCmd.Flags().Bool("no-prune-tags", false, noPruneTagsUsage)
+	syncCmd.Flags().String("backup-branch", "", backupBranchUsage)
+	syncCmd.Flags().Bool("prune-empty-dirs", false, pruneEmptyDirsUsage)
+	syncCmd.Flags().Bool("delete-gone-branches", false, deleteGoneBranchesUsage)
+	syncCmd.Flags().Bool("autostash-all", false, autostashAllUsage)
+	syncCmd.Flags().Bool("reset-hard", false, resetHardUsage)
+	syncCmd.Flags().Bool("lfs", false, lfsUsage)
+	syncCmd.Flags().Bool("update-submodules", false, updateSubmodulesUsage)
+	syncCmd.Flags().String("sort-by", "", syncSortByUsage)
+	syncCmd.Flags().String("report", "", syncReportUsage)
+	syncCmd.Flags().String("log-dir", "", syncLogDirUsage)
+	addNameOnlyFlags(syncCmd)
+	syncCmd.Flags().String("pre-run-command", "", preRunCommandUsage)
+	syncCmd.Flags().Bool("strict-hooks", false, strictHooksUsage)
+	syncCmd.Flags().Bool("summary", false, syncSummaryUsage)
+	syncCmd.Flags().Int("retries", 0, retriesUsage)
+	syncCmd.Flags().Duration("retry-backoff", time.Second, retryBackoffUsage)
+	syncCmd.Flags().Bool("plan-only", false, planOnlyUsage)
+	syncCmd.Flags().String("output", "", planOutputUsage)
+	syncCmd.Flags().Bool("from-last-run", false, fromLastRunUsage)
+	addFormatFlag(syncCmd, syncFormatUsage)
+	syncCmd.Flags().String("template", "", templateUsage)
 	addNoHeadersFlag(syncCmd)
 	syncCmd.Flags().Bool("wrap", false, "allow table columns to wrap instead of truncating")
 	addVCSFlag(syncCmd)
@@ -252,6 +443,13 @@ type syncResultJSON struct {
 	Error              string                        `json:"error,omitempty"`
 	SkipReason         string                        `json:"skip_reason,omitempty"`
 	RemoteTrackingRefs model.RemoteTrackingRefStatus `json:"remote_tracking_refs"`
+	Attempts           int                           `json:"attempts,omitempty"`
+	TagsPruned         int                           `json:"tags_pruned,omitempty"`
+	BackupBranch       string                        `json:"backup_branch,omitempty"`
+	Autostashed        bool                          `json:"autostashed,omitempty"`
+	AutostashRestored  bool                          `json:"autostash_restored,omitempty"`
+	Warning            string                        `json:"warning,omitempty"`
+	DurationMS         int64                         `json:"duration_ms,omitempty"`
 }
 
 func toSyncResultJSON(res engine.SyncResult) syncResultJSON {
@@ -259,7 +457,7 @@ func toSyncResultJSON(res engine.SyncResult) syncResultJSON {
 	// engine encodes as a planned_* outcome. res.Planned is unreliable here: the
 	// execute path copies the plan item without clearing the flag, so executed
 	// results (e.g. outcome "fetched") would otherwise still report planned=true.
-	planned := strings.HasPrefix(string(res.Outcome), "planned_")
+	planned := engine.IsPlannedOutcome(res.Outcome)
 
 	// Planned entries carry a dry-run sentinel in Error rather than a real
 	// failure or skip reason, so drop the error field for them. This matches the
@@ -279,6 +477,13 @@ func toSyncResultJSON(res engine.SyncResult) syncResultJSON {
 		Error:              errText,
 		SkipReason:         res.SkipReason,
 		RemoteTrackingRefs: res.RemoteTrackingRefs,
+		Attempts:           res.Attempts,
+		TagsPruned:         res.TagsPruned,
+		BackupBranch:       res.BackupBranch,
+		Autostashed:        res.Autostashed,
+		AutostashRestored:  res.AutostashRestored,
+		Warning:            res.Warning,
+		DurationMS:         res.Duration.Milliseconds(),
 	}
 }
 
@@ -290,6 +495,236 @@ func toSyncResultJSONs(results []engine.SyncResult) []syncResultJSON {
 	return out
 }
 
+// syncSummaryJSON is the aggregate emitted by --summary. Planned and skipped
+// outcomes are counted apart from applied ones so a dry-run summary can never
+// be mistaken for work that actually happened.
+type syncSummaryJSON struct {
+	DryRun  bool           `json:"dry_run"`
+	Total   int            `json:"total"`
+	OK      int            `json:"ok"`
+	Applied map[string]int `json:"applied"`
+	Planned map[string]int `json:"planned"`
+	Skipped map[string]int `json:"skipped"`
+}
+
+func buildSyncSummary(results []engine.SyncResult, dryRun bool) syncSummaryJSON {
+	var applied, planned, skipped []engine.SyncResult
+	summary := syncSummaryJSON{DryRun: dryRun, Total: len(results)}
+	for _, res := range results {
+		if res.OK {
+			summary.OK++
+		}
+		switch {
+		case engine.IsPlannedOutcome(res.Outcome):
+			planned = append(planned, res)
+		case engine.IsSkippedOutcome(res.Outcome):
+			skipped = append(skipped, res)
+		default:
+			applied = append(applied, res)
+		}
+	}
+	summary.Applied = outcomeCountsJSON(engine.SummarizeResults(applied))
+	summary.Planned = outcomeCountsJSON(engine.SummarizeResults(planned))
+	summary.Skipped = outcomeCountsJSON(engine.SummarizeResults(skipped))
+	return summary
+}
+
+func outcomeCountsJSON(counts map[engine.OutcomeKind]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for outcome, count := range counts {
+		out[string(outcome)] = count
+	}
+	return out
+}
+
+// writeSyncSummary keeps stdout machine-readable: JSON output appends the summary
+// to stdout, while table modes send it to stderr next to the human output.
+func writeSyncSummary(cmd *cobra.Command, results []engine.SyncResult, dryRun bool, kind outputKind) error {
+	data, err := json.Marshal(buildSyncSummary(results, dryRun))
+	if err != nil {
+		return err
+	}
+	w := cmd.ErrOrStderr()
+	if kind == outputKindJSON {
+		w = cmd.OutOrStdout()
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// validateSyncExecutionFlags checks the execution tuning flags shared by sync
+// and apply.
+// High --concurrency values are left to the engine, which clamps them unless
+// --allow-oversubscribe is set.
+func validateSyncExecutionFlags(timeout, retries int, retryBackoff time.Duration) error {
+	if timeout > 0 && timeout > 600 {
+		return fmt.Errorf("--timeout must be <= 600, got %d", timeout)
+	}
+	if retries < 0 || retries > 10 {
+		return fmt.Errorf("--retries must be between 0 and 10, got %d", retries)
+	}
+	if retryBackoff <= 0 {
+		return fmt.Errorf("--retry-backoff must be positive, got %s", retryBackoff)
+	}
+	return nil
+}
+
+// executeSyncPlan applies plan through the engine, streaming progress rows when
+// requested, and returns the results in stable repo order.
+func executeSyncPlan(cmd *cobra.Command, eng *engine.Engine, plan []engine.SyncResult, opts engine.SyncOptions, cwd string, roots []string, streamResults bool) ([]engine.SyncResult, error) {
+	var streamWriter *syncProgressWriter
+	if streamResults {
+		streamWriter = newSyncProgressWriter(cmd, cwd, roots)
+	}
+
+	results, err := eng.ExecuteSyncPlanWithCallbacks(cmd.Context(), plan, opts, func(res engine.SyncResult) {
+		if streamWriter == nil {
+			return
+		}
+		if streamErr := streamWriter.StartResult(res); streamErr != nil {
+			logOutputWriteFailure(cmd, "sync stream start", streamErr)
+		}
+	}, func(res engine.SyncResult) {
+		if streamWriter == nil {
+			return
+		}
+		if streamErr := streamWriter.WriteResult(res); streamErr != nil {
+			logOutputWriteFailure(cmd, "sync stream row", streamErr)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].RepoID == results[j].RepoID {
+			return results[i].Action < results[j].Action
+		}
+		return results[i].RepoID < results[j].RepoID
+	})
+	return results, nil
+}
+
+type syncReportOptions struct {
+	mode          outputMode
+	format        string
+	cwd           string
+	roots         []string
+	noHeaders     bool
+	wrap          bool
+	streamResults bool
+	summary       bool
+	dryRun        bool
+	nameOnly      nameOnlyOutput
+}
+
+// reportSyncResults renders sync results in the requested format, raises the
+// exit code for failures and skips, and prints the failure summary.
+func reportSyncResults(cmd *cobra.Command, results []engine.SyncResult, opts syncReportOptions) error {
+	switch {
+	case opts.nameOnly.enabled:
+		paths := make([]string, 0, len(results))
+		for _, res := range results {
+			paths = append(paths, res.Path)
+		}
+		logOutputWriteFailure(cmd, "sync names", writeRepoNames(cmd.OutOrStdout(), paths, opts.cwd, opts.roots, opts.nameOnly.null))
+	case opts.mode.kind == outputKindJSON:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		data, err := json.MarshalIndent(toSyncResultJSONs(results), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		logOutputWriteFailure(cmd, "sync json", err)
+	case opts.mode.kind == outputKindYAML:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		logOutputWriteFailure(cmd, "sync yaml", writeYAMLOutput(cmd, toSyncResultJSONs(results)))
+	case opts.mode.kind == outputKindCustomColumns:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		logOutputWriteFailure(cmd, "sync custom-columns", writeCustomColumnsOutput(cmd, results, opts.mode.expr, opts.noHeaders))
+	case opts.mode.kind == outputKindTemplate:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		if err := writeTemplateOutput(cmd, opts.mode.tmpl, results, opts.cwd, opts.roots); err != nil {
+			return err
+		}
+	case opts.mode.kind == outputKindCSV:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		logOutputWriteFailure(cmd, "sync csv", writeSyncCSV(cmd.OutOrStdout(), results, opts.noHeaders))
+	case opts.mode.kind == outputKindTable:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		if !opts.streamResults {
+			logOutputWriteFailure(cmd, "sync table", writeSyncTable(cmd, results, nil, opts.cwd, opts.roots, opts.wrap, opts.noHeaders, false))
+		}
+	case opts.mode.kind == outputKindWide:
+		setColorOutputMode(cmd, string(opts.mode.kind))
+		if !opts.streamResults {
+			logOutputWriteFailure(cmd, "sync wide", writeSyncTable(cmd, results, nil, opts.cwd, opts.roots, opts.wrap, opts.noHeaders, true))
+		}
+	default:
+		return fmt.Errorf("unsupported format %q", opts.format)
+	}
+	if opts.summary {
+		logOutputWriteFailure(cmd, "sync summary", writeSyncSummary(cmd, results, opts.dryRun, opts.mode.kind))
+	}
+	for _, res := range results {
+		if !res.OK {
+			// Missing repos are warning-level; operational failures are error-level.
+			if res.Error == engine.SyncErrorMissing {
+				raiseExitCode(cmd, 1)
+				continue
+			}
+			raiseExitCode(cmd, 2)
+			continue
+		}
+		if _, skippedLocalUpdate := syncLocalUpdateSkipReason(res); skippedLocalUpdate {
+			raiseExitCode(cmd, 1)
+		}
+	}
+	logOutputWriteFailure(cmd, "sync failure summary", writeSyncFailureSummary(cmd, results, opts.cwd, opts.roots))
+	return nil
+}
+
+// writeSavedSyncPlan writes plan to path in the format `repokeeper apply --plan`
+// reads back.
+func writeSavedSyncPlan(path string, plan []engine.SyncResult) error {
+	data, err := json.MarshalIndent(engine.NewSavedSyncPlan(plan, time.Now()), "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// loadLastSyncFailures returns the paths of repos that did not finish OK in the
+// run recorded at path. The record uses the saved-plan format, but only the
+// outcomes are read, so it never has to be a valid plan to apply.
+func loadLastSyncFailures(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recorded sync run at %q (run repokeeper sync first)", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved engine.SavedSyncPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("invalid sync run record %q: %w", path, err)
+	}
+	if saved.Version != engine.SavedSyncPlanVersion {
+		return nil, fmt.Errorf("invalid sync run record %q: unsupported version %d", path, saved.Version)
+	}
+	var paths []string
+	for _, item := range saved.Items {
+		if !item.OK && !slices.Contains(paths, item.Path) {
+			paths = append(paths, item.Path)
+		}
+	}
+	return paths, nil
+}
+
 func writeSyncPlan(cmd *cobra.Command, plan []engine.SyncResult, cwd string, roots []string) error {
 	if _, err := fmt.Fprintln(cmd.ErrOrStderr(), "Planned sync operations:"); err != nil {
 		return err
@@ -305,7 +740,43 @@ func writeSyncPlan(cmd *cobra.Command, plan []engine.SyncResult, cwd string, roo
 	return cliio.WriteTable(cmd.ErrOrStderr(), false, false, []string{"PATH", "ACTION", "REPO"}, rows)
 }
 
-func confirmSyncExecution(cmd *cobra.Command) (bool, error) {
+// runSyncPreRunCommand runs the optional --pre-run-command once before any
+// sync worker starts. The command is split with shell quoting rules but run
+// without a shell, so a VPN check or token refresh behaves the same on every
+// platform. A nonzero exit aborts the whole run rather than letting every repo
+// fail its fetch individually.
+func runSyncPreRunCommand(cmd *cobra.Command, command string) error {
+	parts, err := parseCommandLine("--pre-run-command", command)
+	if err != nil || parts == nil {
+		return err
+	}
+	command = strings.TrimSpace(command)
+	debugf(cmd, "running pre-run command %q", command)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	run := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	run.Stdout = cmd.ErrOrStderr()
+	run.Stderr = cmd.ErrOrStderr()
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("pre-run command %q failed, aborting sync: %w", command, err)
+	}
+	return nil
+}
+
+// confirmSyncExecution asks before a plan with local updates runs. Plans that
+// reset repos to upstream get a prompt naming what will be discarded.
+func confirmSyncExecution(cmd *cobra.Command, plan []engine.SyncResult) (bool, error) {
+	resets := 0
+	for _, res := range plan {
+		if res.Planned && res.ResetTarget != "" {
+			resets++
+		}
+	}
+	if resets > 0 {
+		return confirmWithPrompt(cmd, fmt.Sprintf("Discard all local commits, changes, and untracked files in %d repos (git reset --hard, git clean -fdx)? [y/N]: ", resets))
+	}
 	return confirmWithPrompt(cmd, "Proceed with local updates? [y/N]: ")
 }
 
@@ -326,6 +797,9 @@ func syncResultNeedsConfirmation(res engine.SyncResult) bool {
 	// Confirmation is reserved for operations that mutate local state or a
 	// remote (a --push-local push writes to the remote just as much as a
 	// rebase/stash/clone writes to the local checkout).
+	if res.ResetTarget != "" {
+		return true
+	}
 	action := strings.ToLower(strings.TrimSpace(res.Action))
 	if strings.Contains(action, "pull --rebase") || strings.Contains(action, "stash push") {
 		return true
@@ -566,7 +1040,7 @@ func syncTableHeaders(mode syncTableMode, wide bool) string {
 		headers = "PATH\tACTION\tOK\tERROR"
 	}
 	if wide {
-		headers += "\tPRIMARY_REMOTE\tUPSTREAM\tAHEAD\tBEHIND"
+		headers += "\tPRIMARY_REMOTE\tUPSTREAM\tAHEAD\tBEHIND\tTAGS_PRUNED\tDURATION"
 	}
 	return headers
 }
@@ -678,7 +1152,7 @@ func writeSyncTable(cmd *cobra.Command, results []engine.SyncResult, report *mod
 			primaryRemote = repo.PrimaryRemote
 			upstream = repo.Tracking.Upstream
 		}
-		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
 			path,
 			action,
 			branch,
@@ -692,13 +1166,38 @@ func writeSyncTable(cmd *cobra.Command, results []engine.SyncResult, report *mod
 			primaryRemote,
 			upstream,
 			ahead,
-			behind); err != nil {
+			behind,
+			res.TagsPruned,
+			syncDurationDisplay(res.Duration)); err != nil {
 			return err
 		}
 	}
 	return w.Flush()
 }
 
+// syncSortByDuration is the --sort-by value that orders results slowest first.
+const syncSortByDuration = "duration"
+
+// sortSyncResultsByDuration orders results by descending Duration so the
+// slowest repos come first. Ties keep the engine's repo ID order.
+func sortSyncResultsByDuration(results []engine.SyncResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Duration > results[j].Duration
+	})
+}
+
+// syncDurationDisplay renders a sync duration for the wide table: "-" when
+// nothing ran, milliseconds below one second, and tenths of a second above.
+func syncDurationDisplay(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
 func describeSyncAction(res engine.SyncResult) string {
 	action := strings.TrimSpace(res.Action)
 
@@ -718,6 +1217,9 @@ func describeSyncAction(res engine.SyncResult) string {
 	if res.Error == engine.SyncErrorSkipped {
 		return "skip"
 	}
+	if reason, ok := strings.CutPrefix(res.Error, engine.SyncErrorSkippedNoRemotePrefix); ok {
+		return "skip (" + reason + ")"
+	}
 	if res.Error == engine.SyncErrorMissing {
 		return "skip missing"
 	}
@@ -729,15 +1231,17 @@ func describeSyncAction(res engine.SyncResult) string {
 		return "stash & rebase"
 	case strings.Contains(normalized, "hg pull"):
 		return "fetch"
-	case strings.Contains(normalized, "fetch --all") && strings.Contains(normalized, "git push"):
+	case strings.Contains(normalized, "git fetch ") && strings.Contains(normalized, "git push"):
 		return "fetch + push"
-	case strings.Contains(normalized, "fetch --all") && strings.Contains(normalized, "pull --rebase"):
+	case strings.Contains(normalized, "git fetch ") && strings.Contains(normalized, "pull --rebase"):
 		return "fetch + rebase"
 	case strings.Contains(normalized, "git push"):
 		return "push"
 	case strings.Contains(normalized, "pull --rebase"):
 		return "rebase"
-	case strings.Contains(normalized, "fetch --all"):
+	case strings.Contains(normalized, "git fetch ") && strings.Contains(normalized, "git lfs fetch"):
+		return "fetch + lfs"
+	case strings.Contains(normalized, "git fetch "):
 		return "fetch"
 	case strings.Contains(normalized, "git clone --mirror"):
 		return "checkout missing (mirror)"
@@ -127,3 +127,52 @@ var _ = Describe("sync -o json result shape", func() {
 		Expect(obj).NotTo(HaveKey("planned"))
 	})
 })
+
+var _ = Describe("sync -o json attempts", func() {
+	It("reports attempts only when a fetch or clone ran", func() {
+		data, err := json.Marshal(toSyncResultJSONs([]engine.SyncResult{
+			{RepoID: "github.com/org/retried", Outcome: engine.SyncOutcomeFetched, OK: true, Attempts: 3},
+			{RepoID: "github.com/org/missing", Outcome: engine.SyncOutcomeSkippedMissing},
+		}))
+		Expect(err).NotTo(HaveOccurred())
+
+		var arr []map[string]any
+		Expect(json.Unmarshal(data, &arr)).To(Succeed())
+		Expect(arr[0]).To(HaveKeyWithValue("attempts", float64(3)))
+		Expect(arr[1]).NotTo(HaveKey("attempts"))
+	})
+})
+
+var _ = Describe("sync --summary shape", func() {
+	It("counts planned and skipped outcomes apart from applied ones", func() {
+		summary := buildSyncSummary([]engine.SyncResult{
+			{Outcome: engine.SyncOutcomePlannedFetch, OK: true, Planned: true},
+			{Outcome: engine.SyncOutcomePlannedFetch, OK: true, Planned: true},
+			{Outcome: engine.SyncOutcomeSkippedMissing, OK: false},
+		}, true)
+
+		Expect(summary.DryRun).To(BeTrue())
+		Expect(summary.Total).To(Equal(3))
+		Expect(summary.OK).To(Equal(2))
+		Expect(summary.Planned).To(Equal(map[string]int{"planned_fetch": 2}))
+		Expect(summary.Skipped).To(Equal(map[string]int{"skipped_missing": 1}))
+		Expect(summary.Applied).To(BeEmpty())
+	})
+
+	It("emits stable snake_case keys", func() {
+		data, err := json.Marshal(buildSyncSummary([]engine.SyncResult{
+			{Outcome: engine.SyncOutcomeFetched, OK: true, Planned: true},
+			{Outcome: engine.SyncOutcomeFailedFetch},
+		}, false))
+		Expect(err).NotTo(HaveOccurred())
+
+		var obj map[string]any
+		Expect(json.Unmarshal(data, &obj)).To(Succeed())
+		Expect(obj).To(HaveKeyWithValue("dry_run", false))
+		Expect(obj).To(HaveKeyWithValue("total", float64(2)))
+		Expect(obj).To(HaveKeyWithValue("ok", float64(1)))
+		Expect(obj).To(HaveKeyWithValue("applied", map[string]any{"fetched": float64(1), "failed_fetch": float64(1)}))
+		Expect(obj).To(HaveKeyWithValue("planned", map[string]any{}))
+		Expect(obj).To(HaveKeyWithValue("skipped", map[string]any{}))
+	})
+})
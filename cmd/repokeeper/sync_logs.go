@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+)
+
+// syncLogFileName turns a repo ID into a flat file name: every character
+// outside [A-Za-z0-9._-] becomes "_", so github.com/org/api maps to
+// github.com_org_api.log. Entries without a repo ID fall back to the base of
+// their path.
+func syncLogFileName(res engine.SyncResult) string {
+	name := strings.TrimSpace(res.RepoID)
+	if name == "" {
+		name = filepath.Base(res.Path)
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	name = strings.Trim(name, ".")
+	if name == "" {
+		name = "repo"
+	}
+	return name
+}
+
+// writeSyncLogs writes the raw command output of each executed repo to
+// dir/<sanitized-repo-id>.log, replacing logs from earlier runs. Repos that
+// ran no command get no file. Checkouts sharing a repo ID get -2, -3, ...
+// suffixes in result order. Logs can hold remote output and paths, so the
+// files are readable by the owner only. It returns how many files were
+// written.
+func writeSyncLogs(dir string, results []engine.SyncResult) (int, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return 0, err
+	}
+	used := make(map[string]int, len(results))
+	written := 0
+	for _, res := range results {
+		if res.RawOutput == "" {
+			continue
+		}
+		base := syncLogFileName(res)
+		used[base]++
+		if n := used[base]; n > 1 {
+			base = fmt.Sprintf("%s-%d", base, n)
+		}
+		header := fmt.Sprintf("# repo: %s\n# path: %s\n# outcome: %s\n", res.RepoID, res.Path, res.Outcome)
+		if err := os.WriteFile(filepath.Join(dir, base+".log"), []byte(header+res.RawOutput), 0o600); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
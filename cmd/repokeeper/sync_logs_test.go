@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestSyncLogFileNameSanitizesRepoID(t *testing.T) {
+	for _, tc := range []struct {
+		res  engine.SyncResult
+		want string
+	}{
+		{res: engine.SyncResult{RepoID: "github.com/org/api"}, want: "github.com_org_api"},
+		{res: engine.SyncResult{RepoID: "local:/home/me/src/x y"}, want: "local__home_me_src_x_y"},
+		{res: engine.SyncResult{Path: "/src/untracked"}, want: "untracked"},
+		{res: engine.SyncResult{RepoID: ".."}, want: "repo"},
+	} {
+		if got := syncLogFileName(tc.res); got != tc.want {
+			t.Fatalf("syncLogFileName(%+v) = %q, want %q", tc.res, got, tc.want)
+		}
+	}
+}
+
+func TestWriteSyncLogsSuffixesSharedRepoIDsAndSkipsReposWithoutOutput(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	written, err := writeSyncLogs(dir, []engine.SyncResult{
+		{RepoID: "github.com/org/api", Path: "/a", RawOutput: "$ git fetch\n"},
+		{RepoID: "github.com/org/api", Path: "/b", RawOutput: "$ git fetch\n"},
+		{RepoID: "github.com/org/skipped", Path: "/c"},
+	})
+	if err != nil {
+		t.Fatalf("write logs: %v", err)
+	}
+	if written != 2 {
+		t.Fatalf("expected 2 logs, wrote %d", written)
+	}
+	for _, name := range []string{"github.com_org_api.log", "github.com_org_api-2.log"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Fatalf("expected %s to be owner-only, got %v", name, perm)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "github.com_org_skipped.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected no log for a repo that ran nothing, stat err: %v", err)
+	}
+}
+
+func TestSyncRunELogDirWritesGitErrorForFailingRepo(t *testing.T) {
+	tmp := t.TempDir()
+	missingRemote := filepath.Join(tmp, "no-such-remote.git")
+	cfgPath := filepath.Join(tmp, ".repokeeper.yaml")
+	cfg := config.DefaultConfig()
+	cfg.Registry = &registry.Registry{Entries: []registry.Entry{{
+		RepoID:    "github.com/org/broken",
+		Path:      filepath.Join(tmp, "broken"),
+		RemoteURL: missingRemote,
+		Branch:    "main",
+		Status:    registry.StatusMissing,
+		LastSeen:  time.Now(),
+	}}}
+	if err := config.Save(&cfg, cfgPath); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	logDir := filepath.Join(tmp, "logs")
+	syncCmd.SetOut(&bytes.Buffer{})
+	syncCmd.SetErr(&bytes.Buffer{})
+	syncCmd.SetContext(context.Background())
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+	prevYes, _ := rootCmd.PersistentFlags().GetBool("yes")
+	prevOnly, _ := syncCmd.Flags().GetString("only")
+	prevDryRun, _ := syncCmd.Flags().GetBool("dry-run")
+	prevFormat, _ := syncCmd.Flags().GetString("format")
+	_ = rootCmd.PersistentFlags().Set("yes", "true")
+	_ = syncCmd.Flags().Set("only", "all")
+	_ = syncCmd.Flags().Set("dry-run", "false")
+	_ = syncCmd.Flags().Set("checkout-missing", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	_ = syncCmd.Flags().Set("log-dir", logDir)
+	defer func() {
+		_ = rootCmd.PersistentFlags().Set("yes", boolToFlag(prevYes))
+		_ = syncCmd.Flags().Set("only", prevOnly)
+		_ = syncCmd.Flags().Set("dry-run", boolToFlag(prevDryRun))
+		_ = syncCmd.Flags().Set("format", prevFormat)
+		_ = syncCmd.Flags().Set("checkout-missing", "false")
+		_ = syncCmd.Flags().Set("log-dir", "")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(logDir, "github.com_org_broken.log"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{"# repo: github.com/org/broken", "clone", missingRemote, "does not exist", "error: exit status"} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("expected %q in log, got:\n%s", want, log)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+)
+
+// syncRunReport is the document `sync --report` writes for CI: when the run
+// happened, the options it ran with, and every result in the same
+// snake_case shape as `sync -o json`.
+type syncRunReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Options     syncRunReportOptions `json:"options"`
+	Results     []syncResultJSON     `json:"results"`
+}
+
+// syncRunReportOptions records the effective sync options. Durations are
+// written in Go duration syntax.
+type syncRunReportOptions struct {
+	Filter               string   `json:"filter,omitempty"`
+	DryRun               bool     `json:"dry_run"`
+	Concurrency          int      `json:"concurrency,omitempty"`
+	PerHostConcurrency   int      `json:"concurrency_per_host,omitempty"`
+	TimeoutSeconds       int      `json:"timeout_seconds,omitempty"`
+	ContinueOnError      bool     `json:"continue_on_error"`
+	UpdateLocal          bool     `json:"update_local"`
+	PushLocal            bool     `json:"push_local"`
+	RebaseDirty          bool     `json:"rebase_dirty"`
+	Force                bool     `json:"force"`
+	ProtectedBranches    []string `json:"protected_branches,omitempty"`
+	AllowProtectedRebase bool     `json:"allow_protected_rebase"`
+	CheckoutMissing      bool     `json:"checkout_missing"`
+	RemoteTemplate       string   `json:"remote_template,omitempty"`
+	Retries              int      `json:"retries,omitempty"`
+	RetryBackoff         string   `json:"retry_backoff,omitempty"`
+	MaxJobs              int      `json:"max_jobs,omitempty"`
+	AutostashAll         bool     `json:"autostash_all"`
+	ResetHard            bool     `json:"reset_hard,omitempty"`
+	LFS                  bool     `json:"lfs"`
+	UpdateSubmodules     bool     `json:"update_submodules"`
+	Deepen               int      `json:"deepen,omitempty"`
+	FetchRemote          string   `json:"remote,omitempty"`
+	PruneTags            bool     `json:"prune_tags"`
+	BackupBranch         string   `json:"backup_branch,omitempty"`
+	FromLastRun          bool     `json:"from_last_run"`
+}
+
+func newSyncRunReport(opts engine.SyncOptions, results []engine.SyncResult, now time.Time) syncRunReport {
+	backoff := ""
+	if opts.RetryBackoff > 0 {
+		backoff = opts.RetryBackoff.String()
+	}
+	sorted := append([]engine.SyncResult(nil), results...)
+	sortSyncResultsByRepo(sorted)
+	return syncRunReport{
+		GeneratedAt: now,
+		Options: syncRunReportOptions{
+			Filter:               string(opts.Filter),
+			DryRun:               opts.DryRun,
+			Concurrency:          opts.Concurrency,
+			PerHostConcurrency:   opts.PerHostConcurrency,
+			TimeoutSeconds:       opts.Timeout,
+			ContinueOnError:      opts.ContinueOnError,
+			UpdateLocal:          opts.UpdateLocal,
+			PushLocal:            opts.PushLocal,
+			RebaseDirty:          opts.RebaseDirty,
+			Force:                opts.Force,
+			ProtectedBranches:    opts.ProtectedBranches,
+			AllowProtectedRebase: opts.AllowProtectedRebase,
+			CheckoutMissing:      opts.CheckoutMissing,
+			RemoteTemplate:       opts.RemoteTemplate,
+			Retries:              opts.RetryAttempts,
+			RetryBackoff:         backoff,
+			MaxJobs:              opts.MaxJobs,
+			AutostashAll:         opts.AutostashAll,
+			ResetHard:            opts.ResetHard,
+			LFS:                  opts.LFS,
+			UpdateSubmodules:     opts.UpdateSubmodules,
+			Deepen:               opts.Deepen,
+			FetchRemote:          opts.FetchRemote,
+			PruneTags:            !opts.NoPruneTags,
+			BackupBranch:         opts.BackupBranch,
+			FromLastRun:          len(opts.Paths) > 0,
+		},
+		Results: toSyncResultJSONs(sorted),
+	}
+}
+
+// writeSyncRunReport writes the --report document to path, creating the
+// parent directory when needed. The results are written in repo order
+// whatever --sort-by chose for the terminal, so reports diff cleanly.
+func writeSyncRunReport(path string, opts engine.SyncOptions, results []engine.SyncResult, now time.Time) error {
+	data, err := json.MarshalIndent(newSyncRunReport(opts, results, now), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// sortSyncResultsByRepo orders results by repo ID, then action, so output
+// does not depend on goroutine completion order.
+func sortSyncResultsByRepo(results []engine.SyncResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].RepoID == results[j].RepoID {
+			return results[i].Action < results[j].Action
+		}
+		return results[i].RepoID < results[j].RepoID
+	})
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+package repokeeper
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/engine"
+)
+
+func TestWriteSyncRunReportIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "reports", "nested", "sync.json")
+	opts := engine.SyncOptions{Filter: engine.FilterAll, UpdateLocal: true, RetryBackoff: 2 * time.Second}
+	results := []engine.SyncResult{
+		{RepoID: "github.com/org/b", Path: "/b", OK: true, Outcome: engine.SyncOutcomeFetched, Duration: 3 * time.Second},
+		{RepoID: "github.com/org/a", Path: "/a", OK: false, Outcome: engine.SyncOutcomeFailedFetch, Error: "boom"},
+	}
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	if err := writeSyncRunReport(path, opts, results, now); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	results[0], results[1] = results[1], results[0]
+	if err := writeSyncRunReport(path, opts, results, now); err != nil {
+		t.Fatalf("rewrite report: %v", err)
+	}
+	second, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("re-read report: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("expected identical reports regardless of result order:\n%s\n---\n%s", first, second)
+	}
+
+	var report syncRunReport
+	if err := json.Unmarshal(first, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if !report.GeneratedAt.Equal(now) || report.Options.Filter != "all" || !report.Options.UpdateLocal || report.Options.RetryBackoff != "2s" {
+		t.Fatalf("unexpected report header: %+v", report)
+	}
+	if len(report.Results) != 2 || report.Results[0].RepoID != "github.com/org/a" || report.Results[1].DurationMS != 3000 {
+		t.Fatalf("unexpected report results: %+v", report.Results)
+	}
+}
+
+func TestSyncRunEReportFailureDoesNotFailSync(t *testing.T) {
+	cfgPath, _ := writeTestConfigAndRegistry(t)
+	cleanup := withTestConfig(t, cfgPath)
+	defer cleanup()
+
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	syncCmd.SetOut(out)
+	syncCmd.SetErr(errOut)
+	defer syncCmd.SetOut(os.Stdout)
+	defer syncCmd.SetErr(os.Stderr)
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	prevOnly, _ := syncCmd.Flags().GetString("only")
+	prevDryRun, _ := syncCmd.Flags().GetBool("dry-run")
+	prevFormat, _ := syncCmd.Flags().GetString("format")
+	_ = syncCmd.Flags().Set("only", "missing")
+	_ = syncCmd.Flags().Set("dry-run", "true")
+	_ = syncCmd.Flags().Set("format", "json")
+	_ = syncCmd.Flags().Set("report", filepath.Join(blocker, "report.json"))
+	defer func() {
+		_ = syncCmd.Flags().Set("only", prevOnly)
+		_ = syncCmd.Flags().Set("dry-run", boolToFlag(prevDryRun))
+		_ = syncCmd.Flags().Set("format", prevFormat)
+		_ = syncCmd.Flags().Set("report", "")
+	}()
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("expected sync to succeed despite the report failure, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "ignored output write failure (sync report)") {
+		t.Fatalf("expected the report failure to be logged, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), "github.com/org/repo-missing") {
+		t.Fatalf("expected normal sync output, got %q", out.String())
+	}
+}
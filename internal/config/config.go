@@ -20,6 +20,10 @@ import (
 const (
 	// LocalConfigFilename is the per-directory RepoKeeper config file.
 	LocalConfigFilename = ".repokeeper.yaml"
+	// LastSyncFilename records the results of the most recent sync run.
+	LastSyncFilename = ".repokeeper-last-sync.json"
+	// StatusCacheFilename holds the statuses reused by status --since-scan.
+	StatusCacheFilename = ".repokeeper-status-cache.json"
 	// LegacyConfigAPIVersion is the implicit schema version used when legacy
 	// configs omit apiVersion/kind.
 	LegacyConfigAPIVersion = "skaphos.io/repokeeper/v1alpha1"
@@ -35,6 +39,13 @@ type Defaults struct {
 	MainBranch     string `yaml:"main_branch"`
 	Concurrency    int    `yaml:"concurrency"`
 	TimeoutSeconds int    `yaml:"timeout_seconds"`
+	// MaxJobs caps the worker count of every scan, sync, and status run,
+	// whatever their own concurrency setting. 0 means no cap; --jobs
+	// overrides.
+	MaxJobs int `yaml:"max_jobs,omitempty"`
+	// StashMessage labels the stash sync --rebase-dirty creates before a
+	// pull --rebase.
+	StashMessage string `yaml:"stash_message"`
 }
 
 // BranchPolicy configures branch retention and protection for prune-safety
@@ -57,10 +68,78 @@ type BranchPolicy struct {
 	RequireMerged bool `yaml:"require_merged"`
 }
 
+// Label overlay precedence values for LabelOverlay.Precedence.
+const (
+	LabelOverlayPrecedenceRegistry = "registry"
+	LabelOverlayPrecedenceRepo     = "repo"
+)
+
+// LabelOverlay controls whether get/status merge labels from a repo's
+// .repokeeper-repo.yaml into the machine-local labels read from the registry.
+type LabelOverlay struct {
+	// Enabled turns the overlay on. Off by default so registry labels remain
+	// the only machine-local label source unless the operator opts in.
+	Enabled bool `yaml:"enabled"`
+	// Precedence decides which side wins when both define the same key:
+	// "registry" (default) or "repo".
+	Precedence string `yaml:"precedence,omitempty"`
+}
+
+// DivergedSeverity weights the score `status --only diverged --severity` ranks
+// diverged repos by. The score is the weighted sum of commits behind upstream,
+// a dirty worktree, and days since the last commit.
+type DivergedSeverity struct {
+	// BehindWeight is added once per commit the branch is behind upstream.
+	BehindWeight float64 `yaml:"behind_weight"`
+	// DirtyWeight is added when the worktree has uncommitted changes.
+	DirtyWeight float64 `yaml:"dirty_weight"`
+	// StaleDayWeight is added once per day since the last commit on HEAD.
+	StaleDayWeight float64 `yaml:"stale_day_weight"`
+}
+
+// Hooks holds commands run around sync. Each is split with shell quoting
+// rules and run without a shell, in the repo's directory.
+type Hooks struct {
+	// PostSync runs after each repo that sync or apply updated successfully.
+	// Skipped repos are not hooked. The repo is described through the
+	// REPOKEEPER_PATH, REPOKEEPER_REPO_ID, and REPOKEEPER_OUTCOME environment
+	// variables.
+	PostSync string `yaml:"post_sync,omitempty"`
+}
+
+// ScanRoot is one entry of the optional roots: list. Exclude patterns are
+// relative to Path and apply only beneath it, in addition to the top-level
+// exclude list.
+type ScanRoot struct {
+	Path    string   `yaml:"path"`
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// UnmarshalYAML accepts a bare string as shorthand for {path: <string>}, so
+// flat `roots: [".", "~/work"]` lists keep loading.
+func (r *ScanRoot) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var p string
+		if err := node.Decode(&p); err != nil {
+			return err
+		}
+		*r = ScanRoot{Path: p}
+		return nil
+	}
+	type plain ScanRoot
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*r = ScanRoot(decoded)
+	return nil
+}
+
 // Config represents the machine-level RepoKeeper configuration.
 type Config struct {
 	APIVersion        string             `yaml:"apiVersion"`
 	Kind              string             `yaml:"kind"`
+	Roots             []ScanRoot         `yaml:"roots,omitempty"`
 	Exclude           []string           `yaml:"exclude"`
 	IgnoredPaths      []string           `yaml:"ignored_paths,omitempty"`
 	RegistryPath      string             `yaml:"registry_path,omitempty"`
@@ -68,6 +147,29 @@ type Config struct {
 	RegistryStaleDays int                `yaml:"registry_stale_days"`
 	Defaults          Defaults           `yaml:"defaults"`
 	BranchPolicy      BranchPolicy       `yaml:"branch_policy"`
+	LabelOverlay      LabelOverlay       `yaml:"label_overlay"`
+	DivergedSeverity  DivergedSeverity   `yaml:"diverged_severity"`
+	Profiles          map[string]Profile `yaml:"profiles,omitempty"`
+	// HostAliases maps remote hostnames that are only aliases (SSH config
+	// Host entries such as github-work, or a self-hosted host:port) to the
+	// canonical host used in repo IDs.
+	HostAliases map[string]string `yaml:"host_aliases,omitempty"`
+	Hooks       Hooks             `yaml:"hooks,omitempty"`
+
+	// profile is the profile LoadWithProfile applied, and base holds the
+	// values it replaced, so Save writes the base config and the profile
+	// back separately.
+	profile string
+	base    Profile
+
+	// unexpanded maps each path ExpandPaths rewrote back to the value written
+	// in the file, so Save keeps $VARS and ~ instead of the expansion.
+	unexpanded map[string]string
+
+	// sources maps the dotted key path of every value Load did not take
+	// straight from the file to where it came from: profile:<name>, env, or
+	// default for a zero value it backfilled.
+	sources map[string]string
 }
 
 // DefaultConfig returns a Config with sensible defaults applied.
@@ -82,12 +184,18 @@ func DefaultConfig() Config {
 			MainBranch:     "main",
 			Concurrency:    8,
 			TimeoutSeconds: 60,
+			StashMessage:   "repokeeper: pre-rebase stash",
 		},
 		BranchPolicy: BranchPolicy{
 			ProtectedPatterns: []string{"main", "master", "release/*"},
 			StaleDays:         0,
 			RequireMerged:     true,
 		},
+		DivergedSeverity: DivergedSeverity{
+			BehindWeight:   1,
+			DirtyWeight:    25,
+			StaleDayWeight: 0.1,
+		},
 	}
 }
 
@@ -259,6 +367,13 @@ func isSharedDir(dir string) bool {
 
 // Load reads the config file from the given path.
 func Load(path string) (*Config, error) {
+	return LoadWithProfile(path, "")
+}
+
+// LoadWithProfile reads the config file like Load and merges the named
+// profile over the base config. An empty name loads the base config; a name
+// the file does not define is an error.
+func LoadWithProfile(path, profile string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -276,6 +391,19 @@ func Load(path string) (*Config, error) {
 	if err := validateBranchPolicy(&cfg); err != nil {
 		return nil, err
 	}
+	if err := validateLabelOverlay(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateDivergedSeverity(&cfg); err != nil {
+		return nil, err
+	}
+	if err := validateHostAliases(&cfg); err != nil {
+		return nil, err
+	}
+	if err := applyProfile(&cfg, profile); err != nil {
+		return nil, err
+	}
+	ExpandPaths(&cfg)
 
 	if cfg.Registry == nil && cfg.RegistryPath != "" {
 		// A missing registry file is not fatal for first-run/new-config flows.
@@ -291,20 +419,148 @@ func Load(path string) (*Config, error) {
 	if cfg.Defaults.Concurrency == 0 {
 		// Persisted zero-values are treated as "unset" and backfilled from defaults.
 		cfg.Defaults.Concurrency = DefaultConfig().Defaults.Concurrency
+		cfg.setSource("defaults.concurrency", SourceDefault)
 	}
 	if cfg.Defaults.TimeoutSeconds == 0 {
 		cfg.Defaults.TimeoutSeconds = DefaultConfig().Defaults.TimeoutSeconds
+		cfg.setSource("defaults.timeout_seconds", SourceDefault)
 	}
 	if cfg.Defaults.RemoteName == "" {
 		cfg.Defaults.RemoteName = DefaultConfig().Defaults.RemoteName
+		cfg.setSource("defaults.remote_name", SourceDefault)
 	}
 	if cfg.Defaults.MainBranch == "" {
 		cfg.Defaults.MainBranch = DefaultConfig().Defaults.MainBranch
+		cfg.setSource("defaults.main_branch", SourceDefault)
+	}
+	if strings.TrimSpace(cfg.Defaults.StashMessage) == "" {
+		cfg.Defaults.StashMessage = DefaultConfig().Defaults.StashMessage
+		cfg.setSource("defaults.stash_message", SourceDefault)
 	}
 
 	return &cfg, nil
 }
 
+// Value sources ValueSource reports for values Load did not read straight
+// from the config file. A profile source is SourceProfilePrefix followed by
+// the profile name.
+const (
+	SourceProfilePrefix = "profile:"
+	SourceEnv           = "env"
+	SourceDefault       = "default"
+)
+
+// ValueSource returns where Load took the value at key, a dotted yaml key
+// path such as defaults.concurrency or roots, when that was not the config
+// file: a profile, environment expansion, or a backfilled default. It
+// returns "" for values read from the file as written and for values Load
+// did not touch.
+func (c *Config) ValueSource(key string) string {
+	if c == nil {
+		return ""
+	}
+	return c.sources[key]
+}
+
+func (c *Config) setSource(key, source string) {
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+	c.sources[key] = source
+}
+
+// ExpandPaths expands environment variables ($VAR and ${VAR}, via
+// os.ExpandEnv) and a leading ~ in the path-like config fields: roots and
+// their excludes, exclude, ignored_paths, and registry_path. Unset variables
+// expand to the empty string. Load calls it before the config is used; Save
+// writes the unexpanded values back. A key whose value came from the active
+// profile keeps the profile as its source.
+func ExpandPaths(cfg *Config) {
+	if cfg == nil {
+		return
+	}
+	expanded := map[string]bool{}
+	expand := func(key, value string) string {
+		out := expandPath(value)
+		if out != value {
+			if cfg.unexpanded == nil {
+				cfg.unexpanded = make(map[string]string)
+			}
+			cfg.unexpanded[out] = value
+			expanded[key] = true
+		}
+		return out
+	}
+	for i := range cfg.Roots {
+		cfg.Roots[i].Path = expand("roots", cfg.Roots[i].Path)
+		for j := range cfg.Roots[i].Exclude {
+			cfg.Roots[i].Exclude[j] = expand("roots", cfg.Roots[i].Exclude[j])
+		}
+	}
+	for i := range cfg.Exclude {
+		cfg.Exclude[i] = expand("exclude", cfg.Exclude[i])
+	}
+	for i := range cfg.IgnoredPaths {
+		cfg.IgnoredPaths[i] = expand("ignored_paths", cfg.IgnoredPaths[i])
+	}
+	cfg.RegistryPath = expand("registry_path", cfg.RegistryPath)
+	for key := range expanded {
+		if !strings.HasPrefix(cfg.ValueSource(key), SourceProfilePrefix) {
+			cfg.setSource(key, SourceEnv)
+		}
+	}
+}
+
+// expandPath expands a leading ~ (alone or followed by a separator) to the
+// home directory, then environment variables. A path without either is
+// returned unchanged.
+func expandPath(value string) string {
+	if value == "~" || strings.HasPrefix(value, "~/") || strings.HasPrefix(value, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			value = home + value[1:]
+		}
+	}
+	if strings.Contains(value, "$") {
+		value = os.ExpandEnv(value)
+	}
+	return value
+}
+
+// unexpandPaths returns a copy of cfg's path fields with every value that
+// ExpandPaths produced replaced by its original text. Values added or changed
+// since Load, and values that expanded to nothing, are kept as they are.
+func unexpandPaths(cfg Config) Config {
+	if len(cfg.unexpanded) == 0 {
+		return cfg
+	}
+	restore := func(values []string) []string {
+		if values == nil {
+			return nil
+		}
+		out := make([]string, len(values))
+		for i, value := range values {
+			out[i] = value
+			if raw, ok := cfg.unexpanded[value]; ok && value != "" {
+				out[i] = raw
+			}
+		}
+		return out
+	}
+	roots := make([]ScanRoot, len(cfg.Roots))
+	for i, root := range cfg.Roots {
+		roots[i] = ScanRoot{Path: restore([]string{root.Path})[0], Exclude: restore(root.Exclude)}
+	}
+	if cfg.Roots != nil {
+		cfg.Roots = roots
+	}
+	cfg.Exclude = restore(cfg.Exclude)
+	cfg.IgnoredPaths = restore(cfg.IgnoredPaths)
+	if raw, ok := cfg.unexpanded[cfg.RegistryPath]; ok && cfg.RegistryPath != "" {
+		cfg.RegistryPath = raw
+	}
+	return cfg
+}
+
 // ResolveRegistryPath resolves registry_path against the config file location.
 // Absolute paths are returned unchanged; relative paths are joined to the
 // directory containing configPath.
@@ -318,6 +574,18 @@ func ResolveRegistryPath(configPath, registryPath string) string {
 	return filepath.Clean(filepath.Join(filepath.Dir(configPath), registryPath))
 }
 
+// LastSyncPath returns where sync records its most recent run for
+// `sync --from-last-run`: a dotfile next to the config file.
+func LastSyncPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), LastSyncFilename)
+}
+
+// StatusCachePath returns where status keeps the cache used by --since-scan
+// for the config at configPath.
+func StatusCachePath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), StatusCacheFilename)
+}
+
 // ConfigRoot returns the effective default root for a config file path.
 func ConfigRoot(configPath string) string {
 	if strings.TrimSpace(configPath) == "" {
@@ -333,6 +601,51 @@ func EffectiveRoot(configPath string) string {
 	return ConfigRoot(configPath)
 }
 
+// DefaultScanRoots returns the configured roots: paths resolved against the
+// config directory, falling back to EffectiveRoot when none are configured.
+func DefaultScanRoots(cfg *Config, configPath string) []string {
+	var roots []string
+	if cfg != nil {
+		for _, root := range cfg.Roots {
+			if p := resolveScanRootPath(configPath, root.Path); p != "" {
+				roots = append(roots, p)
+			}
+		}
+	}
+	if len(roots) == 0 {
+		return []string{EffectiveRoot(configPath)}
+	}
+	return roots
+}
+
+// RootExcludes maps each configured root path, resolved like DefaultScanRoots,
+// to its per-root exclude patterns. Roots without patterns are omitted.
+func RootExcludes(cfg *Config, configPath string) map[string][]string {
+	if cfg == nil {
+		return nil
+	}
+	out := make(map[string][]string)
+	for _, root := range cfg.Roots {
+		p := resolveScanRootPath(configPath, root.Path)
+		if p == "" || len(root.Exclude) == 0 {
+			continue
+		}
+		out[p] = append(out[p], root.Exclude...)
+	}
+	return out
+}
+
+func resolveScanRootPath(configPath, rootPath string) string {
+	rootPath = strings.TrimSpace(rootPath)
+	if rootPath == "" {
+		return ""
+	}
+	if filepath.IsAbs(rootPath) || strings.TrimSpace(configPath) == "" {
+		return filepath.Clean(rootPath)
+	}
+	return filepath.Clean(filepath.Join(ConfigRoot(configPath), rootPath))
+}
+
 // Save writes the config to the given path.
 //
 // When RegistryPath is set, the registry is persisted to that external file
@@ -350,6 +663,9 @@ func Save(cfg *Config, path string) error {
 	if err := validateBranchPolicy(cfg); err != nil {
 		return err
 	}
+	if err := validateLabelOverlay(cfg); err != nil {
+		return err
+	}
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
@@ -370,6 +686,7 @@ func Save(cfg *Config, path string) error {
 		}
 		toWrite.Registry = nil
 	}
+	toWrite = foldProfile(unexpandPaths(toWrite))
 
 	data, err := yaml.Marshal(&toWrite)
 	if err != nil {
@@ -478,3 +795,49 @@ func validateBranchPolicy(cfg *Config) error {
 	}
 	return nil
 }
+
+func validateLabelOverlay(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+	switch strings.TrimSpace(cfg.LabelOverlay.Precedence) {
+	case "", LabelOverlayPrecedenceRegistry, LabelOverlayPrecedenceRepo:
+		return nil
+	default:
+		return fmt.Errorf("label_overlay.precedence must be %q or %q, got %q", LabelOverlayPrecedenceRegistry, LabelOverlayPrecedenceRepo, cfg.LabelOverlay.Precedence)
+	}
+}
+
+func validateDivergedSeverity(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+	weights := cfg.DivergedSeverity
+	for _, w := range []struct {
+		name  string
+		value float64
+	}{
+		{"behind_weight", weights.BehindWeight},
+		{"dirty_weight", weights.DirtyWeight},
+		{"stale_day_weight", weights.StaleDayWeight},
+	} {
+		if w.value < 0 {
+			return fmt.Errorf("diverged_severity.%s must not be negative, got %g", w.name, w.value)
+		}
+	}
+	return nil
+}
+
+func validateHostAliases(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("config is nil")
+	}
+	for alias, canonical := range cfg.HostAliases {
+		for _, host := range []string{alias, canonical} {
+			if strings.TrimSpace(host) == "" || strings.ContainsAny(host, "/@ \t") {
+				return fmt.Errorf("host_aliases entry %q: %q must be a bare hostname (optionally with :port)", alias, host)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	t.Setenv("RK_TEST_WORKSPACE", "/srv/work")
+	t.Setenv("RK_TEST_UNSET", "")
+	if err := os.Unsetenv("RK_TEST_UNSET"); err != nil {
+		t.Fatalf("unsetenv: %v", err)
+	}
+
+	cfg := &Config{
+		Roots:        []ScanRoot{{Path: "${RK_TEST_WORKSPACE}/src", Exclude: []string{"$RK_TEST_UNSET/tmp"}}, {Path: "/abs/root"}},
+		Exclude:      []string{"**/node_modules/**", "~/scratch/**"},
+		IgnoredPaths: []string{"$RK_TEST_UNSET", "/abs/ignored"},
+		RegistryPath: "~/repokeeper/registry.yaml",
+	}
+	ExpandPaths(cfg)
+
+	if cfg.Roots[0].Path != "/srv/work/src" || cfg.Roots[1].Path != "/abs/root" {
+		t.Fatalf("unexpected roots: %+v", cfg.Roots)
+	}
+	if cfg.Roots[0].Exclude[0] != "/tmp" {
+		t.Fatalf("expected unset variable to expand to empty, got %q", cfg.Roots[0].Exclude[0])
+	}
+	if cfg.Exclude[0] != "**/node_modules/**" || cfg.Exclude[1] != filepath.Join(home, "scratch/**") {
+		t.Fatalf("unexpected excludes: %v", cfg.Exclude)
+	}
+	if cfg.IgnoredPaths[0] != "" || cfg.IgnoredPaths[1] != "/abs/ignored" {
+		t.Fatalf("unexpected ignored paths: %q", cfg.IgnoredPaths)
+	}
+	if cfg.RegistryPath != filepath.Join(home, "repokeeper/registry.yaml") {
+		t.Fatalf("expected ~ expanded in registry_path, got %q", cfg.RegistryPath)
+	}
+	if got := expandPath("~user/src"); got != "~user/src" {
+		t.Fatalf("expected ~user to be left alone, got %q", got)
+	}
+}
+
+func TestLoadExpandsPathsAndSaveKeepsOriginals(t *testing.T) {
+	t.Setenv("RK_TEST_WORKSPACE", t.TempDir())
+	dir := t.TempDir()
+	path := filepath.Join(dir, LocalConfigFilename)
+	raw := "apiVersion: " + ConfigAPIVersion + "\nkind: " + ConfigKind + "\n" +
+		"roots:\n  - $RK_TEST_WORKSPACE/src\nignored_paths:\n  - ${RK_TEST_WORKSPACE}/skip\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	workspace := os.Getenv("RK_TEST_WORKSPACE")
+	if roots := DefaultScanRoots(cfg, path); len(roots) != 1 || roots[0] != filepath.Join(workspace, "src") {
+		t.Fatalf("expected expanded scan root, got %v", roots)
+	}
+	cfg.IgnoredPaths = append(cfg.IgnoredPaths, "/abs/new")
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read saved config: %v", err)
+	}
+	for _, want := range []string{"$RK_TEST_WORKSPACE/src", "${RK_TEST_WORKSPACE}/skip", "/abs/new"} {
+		if !strings.Contains(string(saved), want) {
+			t.Fatalf("expected %q in saved config:\n%s", want, saved)
+		}
+	}
+	if cfg.IgnoredPaths[0] != filepath.Join(workspace, "skip") {
+		t.Fatalf("expected Save not to modify the loaded config, got %q", cfg.IgnoredPaths)
+	}
+}
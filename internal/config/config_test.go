@@ -105,6 +105,7 @@ var _ = Describe("Config", func() {
 		Expect(loaded.Kind).To(Equal(config.ConfigKind))
 		Expect(loaded.Defaults.RemoteName).To(Equal("origin"))
 		Expect(loaded.Defaults.MainBranch).To(Equal("main"))
+		Expect(loaded.Defaults.StashMessage).To(Equal("repokeeper: pre-rebase stash"))
 	})
 
 	It("defaults missing gvk when loading legacy config", func() {
@@ -217,4 +218,67 @@ var _ = Describe("Config", func() {
 		Expect(config.EffectiveRoot(cfgPath)).To(Equal(filepath.Clean(filepath.Join("/tmp", "workspace"))))
 	})
 
+	It("loads structured and flat roots with per-root excludes", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+		data := "roots:\n  - path: work\n    exclude: [\"vendor\"]\n  - " + filepath.ToSlash(filepath.Join(dir, "oss")) + "\n"
+		Expect(os.WriteFile(cfgPath, []byte(data), 0o644)).To(Succeed())
+
+		loaded, err := config.Load(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Roots).To(HaveLen(2))
+		Expect(config.DefaultScanRoots(loaded, cfgPath)).To(Equal([]string{
+			filepath.Join(dir, "work"),
+			filepath.Join(dir, "oss"),
+		}))
+		Expect(config.RootExcludes(loaded, cfgPath)).To(Equal(map[string][]string{
+			filepath.Join(dir, "work"): {"vendor"},
+		}))
+	})
+
+	It("loads diverged_severity weights and rejects negative ones", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+		Expect(os.WriteFile(cfgPath, []byte("diverged_severity:\n  dirty_weight: 0\n"), 0o644)).To(Succeed())
+
+		loaded, err := config.Load(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.DivergedSeverity.DirtyWeight).To(BeZero())
+		Expect(loaded.DivergedSeverity.BehindWeight).To(Equal(config.DefaultConfig().DivergedSeverity.BehindWeight))
+
+		Expect(os.WriteFile(cfgPath, []byte("diverged_severity:\n  behind_weight: -1\n"), 0o644)).To(Succeed())
+		_, err = config.Load(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("diverged_severity.behind_weight")))
+	})
+
+	It("loads host_aliases and rejects entries that are not hostnames", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+		Expect(os.WriteFile(cfgPath, []byte("host_aliases:\n  github-work: github.com\n"), 0o644)).To(Succeed())
+
+		loaded, err := config.Load(cfgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.HostAliases).To(Equal(map[string]string{"github-work": "github.com"}))
+
+		Expect(os.WriteFile(cfgPath, []byte("host_aliases:\n  github-work: github.com/org\n"), 0o644)).To(Succeed())
+		_, err = config.Load(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("host_aliases")))
+	})
+
+	It("rejects an unknown label_overlay precedence", func() {
+		dir := GinkgoT().TempDir()
+		cfgPath := filepath.Join(dir, ".repokeeper.yaml")
+		Expect(os.WriteFile(cfgPath, []byte("label_overlay:\n  enabled: true\n  precedence: newest\n"), 0o644)).To(Succeed())
+
+		_, err := config.Load(cfgPath)
+		Expect(err).To(MatchError(ContainSubstring("label_overlay.precedence")))
+	})
+
+	It("falls back to the effective root when no roots are configured", func() {
+		cfg := config.DefaultConfig()
+		cfgPath := filepath.Join("/tmp", "workspace", ".repokeeper.yaml")
+		Expect(config.DefaultScanRoots(&cfg, cfgPath)).To(Equal([]string{config.EffectiveRoot(cfgPath)}))
+		Expect(config.RootExcludes(&cfg, cfgPath)).To(BeEmpty())
+	})
+
 })
@@ -0,0 +1,226 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Lint finding types reported by LintKeys.
+const (
+	// LintUnknownKey is a key the config schema does not define. YAML
+	// unmarshaling drops these silently, so a typo'd key just has no effect.
+	LintUnknownKey = "unknown_key"
+	// LintDeprecatedAPIVersion is a config without apiVersion and kind, which
+	// loads as the legacy v1alpha1 schema.
+	LintDeprecatedAPIVersion = "deprecated_api_version"
+)
+
+// LintFinding is one problem LintKeys found in a config file.
+type LintFinding struct {
+	Type string `json:"type"`
+	// Key is the dotted path of the key, with list indexes in brackets, e.g.
+	// defaults.timeout_secs or roots[1].excludes.
+	Key  string `json:"key"`
+	Line int    `json:"line,omitempty"`
+	// Suggestion is the closest key the schema defines at the same level, if
+	// any is close enough to be a likely typo.
+	Suggestion string `json:"suggestion,omitempty"`
+	Message    string `json:"message"`
+}
+
+// schemaNode describes which keys are valid at one level of the config file.
+// A node with no fields and no elem accepts anything: scalars, free-form
+// maps such as labels, and types that decode themselves such as time.Time.
+type schemaNode struct {
+	fields map[string]*schemaNode
+	// elem is the schema of each list element, or of each map value when
+	// keyed is set.
+	elem  *schemaNode
+	keyed bool
+}
+
+func (n *schemaNode) isLeaf() bool {
+	return n == nil || (n.fields == nil && n.elem == nil)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// configSchema builds the key schema for t from its yaml tags, the same way
+// yaml.v3 maps keys to fields: the tag name, or the lowercased field name.
+func configSchema(t reflect.Type) *schemaNode {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return configSchema(t.Elem())
+	case reflect.Slice, reflect.Array:
+		if elem := configSchema(t.Elem()); !elem.isLeaf() {
+			return &schemaNode{elem: elem}
+		}
+		return &schemaNode{}
+	case reflect.Map:
+		if elem := configSchema(t.Elem()); !elem.isLeaf() {
+			return &schemaNode{elem: elem, keyed: true}
+		}
+		return &schemaNode{}
+	case reflect.Struct:
+		if t == timeType {
+			return &schemaNode{}
+		}
+		node := &schemaNode{fields: map[string]*schemaNode{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			child := configSchema(field.Type)
+			if strings.Contains(opts, "inline") {
+				for key, value := range child.fields {
+					node.fields[key] = value
+				}
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			node.fields[name] = child
+		}
+		return node
+	default:
+		return &schemaNode{}
+	}
+}
+
+// LintKeys reports every key in raw, a config file as stored, that Config
+// does not define, including keys nested under defaults, roots, profiles,
+// and the embedded registry. A config without apiVersion and kind is
+// reported as deprecated. Values are not checked; Load reports bad values.
+func LintKeys(raw []byte) ([]LintFinding, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	findings := []LintFinding{}
+	if len(doc.Content) == 0 {
+		return findings, nil
+	}
+	root := doc.Content[0]
+	lintNode(root, configSchema(reflect.TypeOf(Config{})), "", &findings)
+	if root.Kind == yaml.MappingNode && mappingValue(root, "apiVersion") == nil && mappingValue(root, "kind") == nil {
+		findings = append(findings, LintFinding{
+			Type:    LintDeprecatedAPIVersion,
+			Key:     "apiVersion",
+			Line:    root.Line,
+			Message: fmt.Sprintf("no apiVersion or kind: loaded as %s, and the next save rewrites it as %s", LegacyConfigAPIVersion, ConfigAPIVersion),
+		})
+	}
+	return findings, nil
+}
+
+func lintNode(node *yaml.Node, schema *schemaNode, path string, findings *[]LintFinding) {
+	if schema.isLeaf() {
+		return
+	}
+	switch node.Kind {
+	case yaml.AliasNode:
+		lintNode(node.Alias, schema, path, findings)
+	case yaml.SequenceNode:
+		if schema.elem == nil || schema.keyed {
+			return
+		}
+		for i, item := range node.Content {
+			lintNode(item, schema.elem, fmt.Sprintf("%s[%d]", path, i), findings)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				lintNode(value, schema, path, findings)
+				continue
+			}
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			if schema.keyed {
+				lintNode(value, schema.elem, keyPath, findings)
+				continue
+			}
+			if schema.fields == nil {
+				continue
+			}
+			child, ok := schema.fields[key.Value]
+			if !ok {
+				*findings = append(*findings, unknownKeyFinding(keyPath, key, schema))
+				continue
+			}
+			lintNode(value, child, keyPath, findings)
+		}
+	}
+}
+
+func unknownKeyFinding(keyPath string, key *yaml.Node, schema *schemaNode) LintFinding {
+	finding := LintFinding{Type: LintUnknownKey, Key: keyPath, Line: key.Line, Message: "unknown key"}
+	known := make([]string, 0, len(schema.fields))
+	for name := range schema.fields {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	if suggestion := closestKey(key.Value, known); suggestion != "" {
+		finding.Suggestion = suggestion
+		finding.Message = fmt.Sprintf("unknown key (did you mean %q?)", suggestion)
+	}
+	return finding
+}
+
+// closestKey returns the candidate with the smallest edit distance to key,
+// if that distance is small enough for a typo: at most a third of the key's
+// length, and never more than 3.
+func closestKey(key string, candidates []string) string {
+	limit := min(max(len(key)/3, 1), 3)
+	best, bestDist := "", limit+1
+	for _, candidate := range candidates {
+		if dist := editDistance(strings.ToLower(key), candidate); dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/skaphos/repokeeper/internal/config"
+)
+
+var _ = Describe("LintKeys", func() {
+	It("accepts a config that only uses schema keys", func() {
+		raw := []byte(`apiVersion: skaphos.io/repokeeper/v1beta1
+kind: RepoKeeperConfig
+exclude: [node_modules]
+roots:
+  - /src
+  - path: /work
+    exclude: [vendor]
+defaults:
+  concurrency: 4
+profiles:
+  work:
+    roots: [/work]
+registry:
+  repos:
+    - repo_id: github.com/org/api
+      path: /src/api
+      labels:
+        team: core
+      last_seen: 2026-01-02T03:04:05Z
+`)
+		findings, err := config.LintKeys(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("reports unknown keys with the closest valid key", func() {
+		raw := []byte(`apiVersion: skaphos.io/repokeeper/v1beta1
+kind: RepoKeeperConfig
+excludes: [node_modules]
+roots:
+  - path: /work
+    excludes: [vendor]
+defaults:
+  timeout_secs: 30
+profiles:
+  work:
+    ignore_paths: [/work/tmp]
+frobnicate: true
+`)
+		findings, err := config.LintKeys(raw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(Equal([]config.LintFinding{
+			{Type: config.LintUnknownKey, Key: "excludes", Line: 3, Suggestion: "exclude", Message: `unknown key (did you mean "exclude"?)`},
+			{Type: config.LintUnknownKey, Key: "roots[0].excludes", Line: 6, Suggestion: "exclude", Message: `unknown key (did you mean "exclude"?)`},
+			{Type: config.LintUnknownKey, Key: "defaults.timeout_secs", Line: 8, Suggestion: "timeout_seconds", Message: `unknown key (did you mean "timeout_seconds"?)`},
+			{Type: config.LintUnknownKey, Key: "profiles.work.ignore_paths", Line: 11, Suggestion: "ignored_paths", Message: `unknown key (did you mean "ignored_paths"?)`},
+			{Type: config.LintUnknownKey, Key: "frobnicate", Line: 12, Message: "unknown key"},
+		}))
+	})
+
+	It("reports a config without apiVersion and kind as deprecated", func() {
+		findings, err := config.LintKeys([]byte("exclude: [vendor]\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Type).To(Equal(config.LintDeprecatedAPIVersion))
+		Expect(findings[0].Message).To(ContainSubstring(config.LegacyConfigAPIVersion))
+	})
+})
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of path settings selected with --profile. Each field
+// that is set replaces the base config's value while the profile is active;
+// an unset field keeps the base value.
+type Profile struct {
+	Roots        []ScanRoot `yaml:"roots,omitempty"`
+	Exclude      []string   `yaml:"exclude,omitempty"`
+	IgnoredPaths []string   `yaml:"ignored_paths,omitempty"`
+}
+
+// ActiveProfile returns the profile LoadWithProfile applied, or "" for the
+// base config.
+func (c *Config) ActiveProfile() string {
+	if c == nil {
+		return ""
+	}
+	return c.profile
+}
+
+// applyProfile merges the named profile over cfg. The profile's lists are
+// copied so that expanding and editing the active config never touches the
+// profile as written in the file.
+func applyProfile(cfg *Config, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		if len(cfg.Profiles) == 0 {
+			return fmt.Errorf("profile %q not found: config defines no profiles", name)
+		}
+		names := slices.Collect(maps.Keys(cfg.Profiles))
+		sort.Strings(names)
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	cfg.profile = name
+	cfg.base = Profile{Roots: cfg.Roots, Exclude: cfg.Exclude, IgnoredPaths: cfg.IgnoredPaths}
+	if profile.Roots != nil {
+		cfg.Roots = make([]ScanRoot, len(profile.Roots))
+		for i, root := range profile.Roots {
+			cfg.Roots[i] = ScanRoot{Path: root.Path, Exclude: slices.Clone(root.Exclude)}
+		}
+		cfg.setSource("roots", SourceProfilePrefix+name)
+	}
+	if profile.Exclude != nil {
+		cfg.Exclude = slices.Clone(profile.Exclude)
+		cfg.setSource("exclude", SourceProfilePrefix+name)
+	}
+	if profile.IgnoredPaths != nil {
+		cfg.IgnoredPaths = slices.Clone(profile.IgnoredPaths)
+		cfg.setSource("ignored_paths", SourceProfilePrefix+name)
+	}
+	return nil
+}
+
+// foldProfile undoes applyProfile for writing: the fields the active profile
+// overrides are stored back into that profile, including any changes made
+// since Load, and the base values are restored.
+func foldProfile(cfg Config) Config {
+	if cfg.profile == "" {
+		return cfg
+	}
+	profile := cfg.Profiles[cfg.profile]
+	if profile.Roots != nil {
+		profile.Roots, cfg.Roots = cfg.Roots, cfg.base.Roots
+	}
+	if profile.Exclude != nil {
+		profile.Exclude, cfg.Exclude = cfg.Exclude, cfg.base.Exclude
+	}
+	if profile.IgnoredPaths != nil {
+		profile.IgnoredPaths, cfg.IgnoredPaths = cfg.IgnoredPaths, cfg.base.IgnoredPaths
+	}
+	cfg.Profiles = maps.Clone(cfg.Profiles)
+	cfg.Profiles[cfg.profile] = profile
+	return cfg
+}
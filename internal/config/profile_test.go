@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const profileTestConfig = `apiVersion: "skaphos.io/repokeeper/v1beta1"
+kind: "RepoKeeperConfig"
+roots: ["/base/root"]
+exclude: ["**/vendor/**"]
+ignored_paths: ["/base/ignored"]
+profiles:
+  work:
+    roots:
+      - path: "$RK_PROFILE_WORK/src"
+        exclude: ["archive"]
+    ignored_paths: ["/work/ignored"]
+  personal:
+    exclude: []
+`
+
+func writeProfileTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(profileTestConfig), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadWithProfileBaseOnly(t *testing.T) {
+	cfg, err := LoadWithProfile(writeProfileTestConfig(t), "")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.ActiveProfile() != "" || len(cfg.Roots) != 1 || cfg.Roots[0].Path != "/base/root" {
+		t.Fatalf("expected base roots, got %q %+v", cfg.ActiveProfile(), cfg.Roots)
+	}
+	if len(cfg.Profiles) != 2 || cfg.IgnoredPaths[0] != "/base/ignored" {
+		t.Fatalf("unexpected base config: %+v", cfg)
+	}
+}
+
+func TestLoadWithProfileOverridesAndSaveKeepsBase(t *testing.T) {
+	t.Setenv("RK_PROFILE_WORK", "/srv/work")
+	path := writeProfileTestConfig(t)
+
+	cfg, err := LoadWithProfile(path, "work")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.ActiveProfile() != "work" || len(cfg.Roots) != 1 || cfg.Roots[0].Path != "/srv/work/src" || cfg.Roots[0].Exclude[0] != "archive" {
+		t.Fatalf("expected work roots, got %+v", cfg.Roots)
+	}
+	if cfg.IgnoredPaths[0] != "/work/ignored" || cfg.Exclude[0] != "**/vendor/**" {
+		t.Fatalf("expected overridden ignored_paths and base exclude, got %v %v", cfg.IgnoredPaths, cfg.Exclude)
+	}
+
+	personal, err := LoadWithProfile(path, "personal")
+	if err != nil {
+		t.Fatalf("load personal: %v", err)
+	}
+	if len(personal.Exclude) != 0 || personal.Roots[0].Path != "/base/root" {
+		t.Fatalf("expected an empty exclude override over base roots, got %+v", personal)
+	}
+
+	cfg.IgnoredPaths = append(cfg.IgnoredPaths, "/work/also-ignored")
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("parse saved config: %v", err)
+	}
+	if len(saved.Roots) != 1 || saved.Roots[0].Path != "/base/root" || strings.Join(saved.IgnoredPaths, ",") != "/base/ignored" {
+		t.Fatalf("expected base values on disk, got %+v", saved)
+	}
+	work := saved.Profiles["work"]
+	if work.Roots[0].Path != "$RK_PROFILE_WORK/src" || strings.Join(work.IgnoredPaths, ",") != "/work/ignored,/work/also-ignored" {
+		t.Fatalf("expected edits to land in the work profile unexpanded, got %+v", work)
+	}
+}
+
+func TestLoadWithProfileUnknownProfile(t *testing.T) {
+	_, err := LoadWithProfile(writeProfileTestConfig(t), "travel")
+	if err == nil || !strings.Contains(err.Error(), `profile "travel" not found (available: personal, work)`) {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("roots: [\"/base\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := LoadWithProfile(path, "work"); err == nil || !strings.Contains(err.Error(), "defines no profiles") {
+		t.Fatalf("expected no-profiles error, got %v", err)
+	}
+}
+
+func TestLoadWithProfileRecordsValueSources(t *testing.T) {
+	t.Setenv("RK_PROFILE_WORK", "/srv/work")
+	cfg, err := LoadWithProfile(writeProfileTestConfig(t), "work")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for key, want := range map[string]string{
+		"roots":                "profile:work",
+		"ignored_paths":        "profile:work",
+		"exclude":              "",
+		"defaults.concurrency": "",
+		"defaults.remote_name": "",
+	} {
+		if got := cfg.ValueSource(key); got != want {
+			t.Fatalf("expected %s source %q, got %q", key, want, got)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	raw := "apiVersion: skaphos.io/repokeeper/v1beta1\nkind: RepoKeeperConfig\nexclude: [\"$RK_PROFILE_WORK/tmp\"]\ndefaults:\n  concurrency: 0\n"
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err = Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := cfg.ValueSource("exclude"); got != SourceEnv {
+		t.Fatalf("expected expanded exclude from env, got %q", got)
+	}
+	if got := cfg.ValueSource("defaults.concurrency"); got != SourceDefault {
+		t.Fatalf("expected backfilled concurrency from default, got %q", got)
+	}
+}
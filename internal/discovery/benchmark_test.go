@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/discovery/discoverytest"
+)
+
+// BenchmarkScan measures discovery when each IsRepo probe costs about as much
+// as forking git, comparing a single worker with a pool of eight.
+func BenchmarkScan(b *testing.B) {
+	roots := discoverytest.ScanTree(b, 4, 8, 8)
+	adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+		time.Sleep(200 * time.Microsecond)
+		return discoverytest.IsMarkedRepo(dir), nil
+	}}
+	for _, workers := range []int{1, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				results, err := Scan(context.Background(), Options{Roots: roots, Adapter: adapter, Concurrency: workers})
+				if err != nil {
+					b.Fatalf("scan: %v", err)
+				}
+				if len(results) != 4*8*8 {
+					b.Fatalf("expected %d repos, got %d", 4*8*8, len(results))
+				}
+			}
+		})
+	}
+}
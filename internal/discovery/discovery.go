@@ -5,14 +5,18 @@ package discovery
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/skaphos/repokeeper/internal/gitx"
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/vcs"
 )
@@ -27,22 +31,75 @@ type Result struct {
 	Bare          bool // true if bare repo
 }
 
+// WalkError is a directory the scan could not read. Its subtree is skipped
+// and the rest of the walk carries on.
+type WalkError struct {
+	Path string
+	Err  error
+}
+
+func (e WalkError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e WalkError) Unwrap() error {
+	return e.Err
+}
+
+// ScanResult is what ScanWithErrors found: the repos, plus the directories
+// that were skipped because they could not be read.
+type ScanResult struct {
+	Repos      []Result
+	WalkErrors []WalkError
+}
+
 // Options configures the discovery scan.
 type Options struct {
-	Roots          []string
-	Exclude        []string // glob patterns to skip
+	Roots   []string
+	Exclude []string // glob patterns to skip
+	// RootExclude holds extra patterns keyed by root path. Relative patterns
+	// are anchored at that root, so they never match under any other root.
+	RootExclude    map[string][]string
 	FollowSymlinks bool
 	Adapter        vcs.Adapter
+	// Concurrency bounds how many directories are probed at once. Zero or
+	// less uses runtime.NumCPU().
+	Concurrency int
+	// MaxDepth stops the walk from descending more than this many levels
+	// below each root; a repo exactly MaxDepth levels down is still found.
+	// Zero or less means unlimited.
+	MaxDepth int
+	// SkipRemotes leaves Remotes, PrimaryRemote, RemoteURL, and RepoID empty
+	// instead of reading each repo's remotes.
+	SkipRemotes bool
 }
 
-// Scan walks all roots and returns discovered repos.
-// It skips directories matching exclude patterns and does not recurse
-// into .git directories or matched exclusions.
+// Scan walks all roots and returns discovered repos. Unreadable directories
+// are skipped; use ScanWithErrors to learn which.
 func Scan(ctx context.Context, opts Options) ([]Result, error) {
+	res, err := ScanWithErrors(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return res.Repos, nil
+}
+
+// ScanWithErrors walks all roots and returns the discovered repos along with
+// the directories it could not read (permission denied). It skips
+// directories matching exclude patterns and does not recurse into .git
+// directories or matched exclusions. Directories are probed concurrently;
+// repos and walk errors are returned in path order. When no root could be
+// read at all, the walk errors are returned as an error instead.
+func ScanWithErrors(ctx context.Context, opts Options) (ScanResult, error) {
 	if opts.Adapter == nil {
 		opts.Adapter = vcs.NewGitAdapter(nil)
 	}
 
+	rootExclude, err := anchorRootExcludes(opts.RootExclude)
+	if err != nil {
+		return ScanResult{}, err
+	}
+	opts.Exclude = append(append([]string(nil), opts.Exclude...), rootExclude...)
 	warnInvalidExcludePatterns(opts.Exclude)
 
 	var absRoots []string
@@ -52,7 +109,7 @@ func Scan(ctx context.Context, opts Options) ([]Result, error) {
 		}
 		absRoot, err := filepath.Abs(root)
 		if err != nil {
-			return nil, err
+			return ScanResult{}, err
 		}
 		absRoots = append(absRoots, absRoot)
 	}
@@ -63,21 +120,84 @@ func Scan(ctx context.Context, opts Options) ([]Result, error) {
 	// from being walked twice and producing duplicate results.
 	sort.Strings(absRoots)
 
-	visited := make(map[string]struct{})
-	skipDirs := make(map[string]struct{})
+	w := newWalker(ctx, opts)
 	var acceptedRoots []string
-	var results []Result
-
 	for _, absRoot := range absRoots {
 		if rootCovered(absRoot, acceptedRoots) {
 			continue
 		}
 		acceptedRoots = append(acceptedRoots, absRoot)
-		if err := walkRoot(ctx, absRoot, opts, visited, skipDirs, &results); err != nil {
-			return nil, err
+		if err := w.addRoot(absRoot, 0); err != nil {
+			return ScanResult{}, err
+		}
+	}
+	repos, err := w.run()
+	if err != nil {
+		return ScanResult{}, err
+	}
+	walkErrors := w.walkErrors
+	sort.Slice(walkErrors, func(i, j int) bool {
+		return walkOrderLess(walkErrors[i].Path, walkErrors[j].Path)
+	})
+	if len(walkErrors) > 0 && w.rootsRead == 0 {
+		errs := make([]error, len(walkErrors))
+		for i := range walkErrors {
+			errs[i] = walkErrors[i]
 		}
+		return ScanResult{}, fmt.Errorf("no scan root could be read: %w", errors.Join(errs...))
 	}
+	return ScanResult{Repos: repos, WalkErrors: walkErrors}, nil
+}
 
+// FromPaths probes each path as a candidate repo directory, without walking
+// below it, and returns the ones that are repos in path order. Blank and
+// duplicate paths are ignored, and paths that do not exist or are not
+// directories are skipped with a warning. skipRemotes works like
+// Options.SkipRemotes.
+func FromPaths(ctx context.Context, paths []string, adapter vcs.Adapter, skipRemotes bool) ([]Result, error) {
+	if adapter == nil {
+		adapter = vcs.NewGitAdapter(nil)
+	}
+	seen := make(map[string]struct{}, len(paths))
+	var results []Result
+	for _, raw := range paths {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		abs, err := filepath.Abs(raw)
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Clean(abs)
+		if _, ok := seen[dir]; ok {
+			continue
+		}
+		seen[dir] = struct{}{}
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			slog.Warn("discovery: skipping candidate that is not a directory", "path", dir, "error", err)
+			continue
+		}
+		isRepoRoot, bare, _, err := detectRepo(ctx, adapter, dir)
+		if err != nil {
+			return nil, err
+		}
+		if !isRepoRoot {
+			continue
+		}
+		result, err := buildResult(ctx, adapter, dir, bare, skipRemotes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return walkOrderLess(results[i].Path, results[j].Path)
+	})
 	return results, nil
 }
 
@@ -103,6 +223,39 @@ func rootCovered(path string, accepted []string) bool {
 	return false
 }
 
+// anchorRootExcludes rewrites per-root patterns into absolute patterns rooted
+// at their root, e.g. "vendor" under /src/a becomes /src/a/vendor, so they can
+// be matched alongside the global patterns against absolute paths.
+func anchorRootExcludes(rootExclude map[string][]string) ([]string, error) {
+	roots := make([]string, 0, len(rootExclude))
+	for root := range rootExclude {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	var anchored []string
+	for _, root := range roots {
+		if strings.TrimSpace(root) == "" {
+			continue
+		}
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		for _, pattern := range rootExclude[root] {
+			if strings.TrimSpace(pattern) == "" {
+				continue
+			}
+			if filepath.IsAbs(pattern) {
+				anchored = append(anchored, pattern)
+				continue
+			}
+			anchored = append(anchored, filepath.Join(absRoot, pattern))
+		}
+	}
+	return anchored, nil
+}
+
 // warnInvalidExcludePatterns logs a warning for every --exclude pattern that
 // fails to parse, so a typoed pattern does not silently disable the
 // exclusion it was meant to apply. Patterns are validated once up front,
@@ -138,107 +291,301 @@ func MatchesExclude(path string, patterns []string) bool {
 	return false
 }
 
-func walkRoot(ctx context.Context, root string, opts Options, visited map[string]struct{}, skipDirs map[string]struct{}, results *[]Result) error {
+// walker runs the discovery walk on a bounded pool of workers. Each queued
+// directory is probed with detectRepo, which shells out to the adapter and
+// dominates scan time; only directories that are not repo roots are listed
+// and their subdirectories queued.
+type walker struct {
+	ctx         context.Context
+	opts        Options
+	concurrency int
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	queue    []walkJob
+	pending  int
+	err      error
+	visited  map[string]struct{}
+	skipDirs map[string]struct{}
+	results  []Result
+	// walkErrors lists unreadable directories; rootsRead counts roots that
+	// were read, so a scan where every root failed can be told apart.
+	walkErrors []WalkError
+	rootsRead  int
+}
+
+// walkJob is one directory to visit. realRoot is the resolved root of the
+// tree it belongs to, used to avoid re-walking that tree through a symlink.
+// depth counts levels below the configured root, including any followed
+// symlinks on the way.
+type walkJob struct {
+	path     string
+	realRoot string
+	root     bool
+	depth    int
+}
+
+func newWalker(ctx context.Context, opts Options) *walker {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	w := &walker{
+		ctx:         ctx,
+		opts:        opts,
+		concurrency: concurrency,
+		visited:     make(map[string]struct{}),
+		skipDirs:    make(map[string]struct{}),
+	}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// run drains the queue with the worker pool and returns the results in a
+// deterministic order regardless of which worker found them.
+func (w *walker) run() ([]Result, error) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := w.next()
+				if !ok {
+					return
+				}
+				w.done(w.visit(job))
+			}
+		}()
+	}
+	wg.Wait()
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	// Linked worktrees can share a gitdir outside the repo path. The walk
+	// order is no longer fixed, so drop anything at or under such a gitdir
+	// once every worktree has been seen.
+	results := w.results[:0]
+	for _, result := range w.results {
+		if !underSkipDir(result.Path, w.skipDirs) {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return walkOrderLess(results[i].Path, results[j].Path)
+	})
+	return results, nil
+}
+
+func (w *walker) next() (walkJob, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) == 0 && w.pending > 0 && w.err == nil {
+		w.cond.Wait()
+	}
+	if w.err != nil || len(w.queue) == 0 {
+		return walkJob{}, false
+	}
+	job := w.queue[len(w.queue)-1]
+	w.queue = w.queue[:len(w.queue)-1]
+	return job, true
+}
+
+func (w *walker) enqueue(job walkJob) {
+	w.mu.Lock()
+	w.queue = append(w.queue, job)
+	w.pending++
+	w.mu.Unlock()
+	w.cond.Signal()
+}
+
+// done marks one job finished, recording the first error. Waking every worker
+// lets them exit once the queue is drained or the walk has failed.
+func (w *walker) done(err error) {
+	w.mu.Lock()
+	w.pending--
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	finished := w.pending == 0 || w.err != nil
+	w.mu.Unlock()
+	if finished {
+		w.cond.Broadcast()
+	}
+}
+
+// addRoot queues root for walking unless its resolved tree was already
+// queued, which also stops symlink cycles when following symlinks. depth is
+// zero for configured roots and the link's depth for followed symlinks.
+func (w *walker) addRoot(root string, depth int) error {
 	realRoot := root
 	if resolved, err := filepath.EvalSymlinks(root); err == nil {
 		realRoot = resolved
 	}
-	// Track resolved roots so the same tree is not walked twice via different
-	// symlink paths, and so following symlinks below cannot recurse forever
-	// on a cycle.
-	if _, ok := visited[realRoot]; ok {
+	w.mu.Lock()
+	_, seen := w.visited[realRoot]
+	w.visited[realRoot] = struct{}{}
+	w.mu.Unlock()
+	if seen {
 		return nil
 	}
-	visited[realRoot] = struct{}{}
 
 	// Walk the root as the caller supplied it so discovered paths keep the
 	// caller's path form; a symlinked ancestor (e.g. macOS /var -> /private/var)
 	// must not rewrite every returned path. Only when the root's final component
-	// is itself a symlink do we walk the resolved target, because WalkDir lstats
-	// the root and would otherwise treat a symlinked directory as a
-	// non-directory and never descend into it.
+	// is itself a symlink do we walk the resolved target.
 	walkTarget := root
-	if fi, err := os.Lstat(root); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+	info, err := os.Lstat(root)
+	if err == nil && info.Mode()&os.ModeSymlink != 0 {
 		walkTarget = realRoot
+		info, err = os.Lstat(walkTarget)
 	}
-	return filepath.WalkDir(walkTarget, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
-				// Transient per-directory failures (permission denied, or a
-				// directory removed mid-scan) should not abort the whole
-				// scan; skip just that subtree and keep going.
-				slog.Warn("discovery: skipping path after walk error", "path", path, "error", err)
-				return fs.SkipDir
-			}
-			return err
-		}
-
-		if d.Type()&os.ModeSymlink != 0 {
-			// filepath.WalkDir never follows symlinks on its own: a symlinked
-			// directory is reported with Type()==ModeSymlink and
-			// IsDir()==false, so it must be detected and, when enabled,
-			// resolved and recursed into explicitly here.
-			if !opts.FollowSymlinks {
-				return nil
-			}
-			target, err := filepath.EvalSymlinks(path)
-			if err != nil {
-				return nil
-			}
-			info, err := os.Stat(target)
-			if err != nil || !info.IsDir() {
-				return nil
-			}
-			// If the symlink resolves back inside the tree WalkDir is already
-			// walking, descending it as a new root would visit that subtree
-			// twice and duplicate results. The visited set only tracks roots,
-			// not every directory WalkDir descends into, so guard explicitly.
-			if target == realRoot || strings.HasPrefix(target, realRoot+string(filepath.Separator)) {
-				return nil
-			}
-			// Recurse into the symlink target as its own root; the visited
-			// set above guards against symlink cycles. Returning nil (not
-			// SkipDir) is required here because a symlink is a non-directory
-			// entry from WalkDir's point of view, and SkipDir on a
-			// non-directory entry skips the remaining siblings too.
-			return walkRoot(ctx, target, opts, visited, skipDirs, results)
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			w.recordWalkError(walkTarget, err)
+			return nil
 		}
-
-		if !d.IsDir() {
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("discovery: skipping path after walk error", "path", walkTarget, "error", err)
 			return nil
 		}
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	w.enqueue(walkJob{path: walkTarget, realRoot: realRoot, root: depth == 0, depth: depth})
+	return nil
+}
+
+func (w *walker) visit(job walkJob) error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	path := job.path
+	w.mu.Lock()
+	_, skip := w.skipDirs[path]
+	w.mu.Unlock()
+	if skip {
+		return nil
+	}
+	if name := filepath.Base(path); name == ".git" || name == ".hg" {
+		// Never recurse through VCS internals during root discovery.
+		return nil
+	}
+	if MatchesExclude(path, w.opts.Exclude) {
+		return nil
+	}
 
-		if _, ok := skipDirs[path]; ok {
-			return fs.SkipDir
+	isRepoRoot, bare, gitdir, err := detectRepo(w.ctx, w.opts.Adapter, path)
+	if err != nil {
+		return err
+	}
+	if isRepoRoot {
+		result, err := buildResult(w.ctx, w.opts.Adapter, path, bare, w.opts.SkipRemotes)
+		if err != nil {
+			return err
 		}
-		if d.Name() == ".git" {
-			// Never recurse through git internals during root discovery.
-			return fs.SkipDir
+		w.mu.Lock()
+		if gitdir != "" {
+			// Linked worktrees can share a gitdir outside the repo path; mark it
+			// skipped so we do not treat it as an independent repository later.
+			w.skipDirs[gitdir] = struct{}{}
 		}
-		if MatchesExclude(path, opts.Exclude) {
-			return fs.SkipDir
+		w.results = append(w.results, result)
+		if job.root {
+			w.rootsRead++
 		}
+		w.mu.Unlock()
+		return nil
+	}
+	if w.opts.MaxDepth > 0 && job.depth >= w.opts.MaxDepth {
+		return nil
+	}
 
-		isRepoRoot, bare, gitdir, err := detectRepo(ctx, opts.Adapter, path)
-		if err != nil {
-			return err
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		// Per-directory failures should not abort the whole scan; skip just
+		// that subtree and keep going. Unreadable directories are reported
+		// to the caller, while a directory removed mid-scan is only logged.
+		if errors.Is(err, fs.ErrPermission) {
+			w.recordWalkError(path, err)
+			return nil
 		}
-		if isRepoRoot {
-			if gitdir != "" {
-				// Linked worktrees can share a gitdir outside the repo path; mark it
-				// skipped so we do not treat it as an independent repository later.
-				skipDirs[gitdir] = struct{}{}
-			}
-			result, err := buildResult(ctx, opts.Adapter, path, bare)
-			if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("discovery: skipping path after walk error", "path", path, "error", err)
+			return nil
+		}
+		return err
+	}
+	if job.root {
+		w.mu.Lock()
+		w.rootsRead++
+		w.mu.Unlock()
+	}
+	// The queue is a stack; pushing children in reverse lets a single worker
+	// visit them in lexical order, like a sequential depth-first walk.
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		child := filepath.Join(path, entry.Name())
+		if entry.Type()&os.ModeSymlink != 0 {
+			if err := w.followSymlink(child, job.realRoot, job.depth+1); err != nil {
 				return err
 			}
-			*results = append(*results, result)
-			return fs.SkipDir
+			continue
+		}
+		if entry.IsDir() {
+			w.enqueue(walkJob{path: child, realRoot: job.realRoot, depth: job.depth + 1})
 		}
+	}
+	return nil
+}
 
+func (w *walker) recordWalkError(path string, err error) {
+	w.mu.Lock()
+	w.walkErrors = append(w.walkErrors, WalkError{Path: path, Err: err})
+	w.mu.Unlock()
+}
+
+// followSymlink queues a symlinked directory as its own root when
+// FollowSymlinks is set.
+func (w *walker) followSymlink(path, realRoot string, depth int) error {
+	if !w.opts.FollowSymlinks {
 		return nil
-	})
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil
+	}
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+	// A symlink that resolves back inside the tree being walked would visit
+	// that subtree twice and duplicate results. The visited set only tracks
+	// roots, not every directory, so guard explicitly.
+	if target == realRoot || strings.HasPrefix(target, realRoot+string(filepath.Separator)) {
+		return nil
+	}
+	return w.addRoot(target, depth)
+}
+
+// underSkipDir reports whether path is, or is nested under, a skipped gitdir.
+func underSkipDir(path string, skipDirs map[string]struct{}) bool {
+	for dir := range skipDirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkOrderLess orders paths component by component, the order a sequential
+// lexical depth-first walk would find them in.
+func walkOrderLess(a, b string) bool {
+	sep := string(filepath.Separator)
+	return strings.ReplaceAll(a, sep, "\x00") < strings.ReplaceAll(b, sep, "\x00")
 }
 
 func detectRepo(ctx context.Context, adapter vcs.Adapter, dir string) (bool, bool, string, error) {
@@ -246,7 +593,7 @@ func detectRepo(ctx context.Context, adapter vcs.Adapter, dir string) (bool, boo
 	if info, err := os.Stat(gitPath); err == nil {
 		if info.Mode().IsRegular() {
 			// A file-based .git indicates a linked worktree with an external gitdir.
-			if gitdir, ok := gitdirFromFile(gitPath); ok {
+			if gitdir, ok := gitx.GitDirFromFile(gitPath); ok {
 				bare, _ := adapter.IsBare(ctx, dir)
 				return true, bare, gitdir, nil
 			}
@@ -265,6 +612,16 @@ func detectRepo(ctx context.Context, adapter vcs.Adapter, dir string) (bool, boo
 		}
 	}
 
+	// Mercurial keeps its store in .hg and has no bare layout; only treat the
+	// directory as a repo root when the adapter can actually operate on it.
+	if info, err := os.Stat(filepath.Join(dir, ".hg")); err == nil && info.IsDir() {
+		ok, err := adapter.IsRepo(ctx, dir)
+		if err != nil {
+			return false, false, "", err
+		}
+		return ok, false, "", nil
+	}
+
 	ok, err := adapter.IsRepo(ctx, dir)
 	if err != nil {
 		return false, false, "", err
@@ -276,26 +633,10 @@ func detectRepo(ctx context.Context, adapter vcs.Adapter, dir string) (bool, boo
 	return false, false, "", nil
 }
 
-func gitdirFromFile(path string) (string, bool) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", false
+func buildResult(ctx context.Context, adapter vcs.Adapter, dir string, bare, skipRemotes bool) (Result, error) {
+	if skipRemotes {
+		return Result{Path: dir, Bare: bare}, nil
 	}
-	content := strings.TrimSpace(string(data))
-	if !strings.HasPrefix(content, "gitdir:") {
-		return "", false
-	}
-	raw := strings.TrimSpace(strings.TrimPrefix(content, "gitdir:"))
-	if raw == "" {
-		return "", false
-	}
-	if filepath.IsAbs(raw) {
-		return filepath.Clean(raw), true
-	}
-	return filepath.Clean(filepath.Join(filepath.Dir(path), raw)), true
-}
-
-func buildResult(ctx context.Context, adapter vcs.Adapter, dir string, bare bool) (Result, error) {
 	remotes, err := adapter.Remotes(ctx, dir)
 	if err != nil {
 		return Result{}, err
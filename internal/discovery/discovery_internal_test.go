@@ -5,12 +5,15 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/model"
 )
@@ -79,42 +82,6 @@ func (s *stubAdapter) PrimaryRemote(remoteNames []string) string {
 	return s.primaryRemoteFn(remoteNames)
 }
 
-func TestGitdirFromFile(t *testing.T) {
-	tmp := t.TempDir()
-	if _, ok := gitdirFromFile(filepath.Join(tmp, "missing")); ok {
-		t.Fatal("expected missing file to return false")
-	}
-
-	invalid := filepath.Join(tmp, ".git.invalid")
-	if err := os.WriteFile(invalid, []byte("not-gitdir"), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	if _, ok := gitdirFromFile(invalid); ok {
-		t.Fatal("expected invalid content to return false")
-	}
-
-	empty := filepath.Join(tmp, ".git.empty")
-	if err := os.WriteFile(empty, []byte("gitdir:   "), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	if _, ok := gitdirFromFile(empty); ok {
-		t.Fatal("expected empty gitdir to return false")
-	}
-
-	relative := filepath.Join(tmp, ".git.rel")
-	if err := os.WriteFile(relative, []byte("gitdir: ../actual.git"), 0o644); err != nil {
-		t.Fatal(err)
-	}
-	got, ok := gitdirFromFile(relative)
-	if !ok {
-		t.Fatal("expected relative gitdir to parse")
-	}
-	want := filepath.Clean(filepath.Join(filepath.Dir(relative), "../actual.git"))
-	if got != want {
-		t.Fatalf("unexpected relative gitdir: got %q want %q", got, want)
-	}
-}
-
 func TestDetectRepoBranches(t *testing.T) {
 	ctx := context.Background()
 
@@ -191,6 +158,37 @@ func TestDetectRepoBranches(t *testing.T) {
 		}
 	})
 
+	t.Run("dothg-dir-confirmed-by-adapter", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, ".hg"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		ok, bare, gitdir, err := detectRepo(ctx, &stubAdapter{
+			isRepoFn: func(context.Context, string) (bool, error) { return true, nil },
+			isBareFn: func(context.Context, string) (bool, error) { return true, nil },
+		}, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || bare || gitdir != "" {
+			t.Fatalf("unexpected detect result: ok=%v bare=%v gitdir=%q", ok, bare, gitdir)
+		}
+	})
+
+	t.Run("dothg-dir-rejected-by-adapter", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, ".hg"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		ok, _, _, err := detectRepo(ctx, &stubAdapter{}, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			t.Fatal("expected .hg directory to be ignored when the adapter does not recognize it")
+		}
+	})
+
 	t.Run("adapter-isrepo-error", func(t *testing.T) {
 		dir := t.TempDir()
 		_, _, _, err := detectRepo(ctx, &stubAdapter{
@@ -210,7 +208,7 @@ func TestBuildResultBranches(t *testing.T) {
 		remotesFn: func(context.Context, string) ([]model.Remote, error) {
 			return nil, errors.New("remote fail")
 		},
-	}, dir, false)
+	}, dir, false, false)
 	if err == nil {
 		t.Fatal("expected remote error")
 	}
@@ -224,7 +222,7 @@ func TestBuildResultBranches(t *testing.T) {
 		},
 		primaryRemoteFn: func(names []string) string { return "origin" },
 		normalizeURLFn:  func(raw string) string { return "norm:" + raw },
-	}, dir, true)
+	}, dir, true, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -238,13 +236,26 @@ func TestBuildResultBranches(t *testing.T) {
 		},
 		primaryRemoteFn: func([]string) string { return "origin" },
 		normalizeURLFn:  func(raw string) string { return "norm:" + raw },
-	}, dir, false)
+	}, dir, false, false)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if res.RemoteURL != "" || res.RepoID != "norm:" {
 		t.Fatalf("unexpected missing-primary behavior: %+v", res)
 	}
+
+	res, err = buildResult(ctx, &stubAdapter{
+		remotesFn: func(context.Context, string) ([]model.Remote, error) {
+			t.Fatal("remotes read with skipRemotes")
+			return nil, nil
+		},
+	}, dir, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Path != dir || !res.Bare || res.RepoID != "" || res.RemoteURL != "" || res.Remotes != nil {
+		t.Fatalf("expected only path and bare with skipRemotes, got %+v", res)
+	}
 }
 
 func TestScanDefaultsAndEmptyRoots(t *testing.T) {
@@ -381,8 +392,9 @@ func TestScanToleratesDirectoryRemovedMidScan(t *testing.T) {
 	}
 
 	results, err := Scan(ctx, Options{
-		Roots:   []string{root},
-		Adapter: adapter,
+		Roots:       []string{root},
+		Adapter:     adapter,
+		Concurrency: 1, // keep the lexical visit order the removal relies on
 	})
 	if err != nil {
 		t.Fatalf("expected Scan to tolerate a directory removed mid-scan, got error: %v", err)
@@ -392,6 +404,113 @@ func TestScanToleratesDirectoryRemovedMidScan(t *testing.T) {
 	}
 }
 
+func TestScanWithErrorsReportsUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits are not enforced for root")
+	}
+	ctx := context.Background()
+	root := t.TempDir()
+	repo := filepath.Join(root, "ok", "repo")
+	locked := filepath.Join(root, "locked")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(locked, "repo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+		return filepath.Base(dir) == "repo", nil
+	}}
+	res, err := ScanWithErrors(ctx, Options{Roots: []string{root}, Adapter: adapter})
+	if err != nil {
+		t.Fatalf("expected partial scan to succeed, got %v", err)
+	}
+	if len(res.Repos) != 1 || res.Repos[0].Path != repo {
+		t.Fatalf("unexpected repos: %+v", res.Repos)
+	}
+	if len(res.WalkErrors) != 1 || res.WalkErrors[0].Path != locked || !errors.Is(res.WalkErrors[0], os.ErrPermission) {
+		t.Fatalf("expected one permission walk error for %s, got %+v", locked, res.WalkErrors)
+	}
+
+	// With every root unreadable there is nothing to report; that is an error.
+	if _, err := ScanWithErrors(ctx, Options{Roots: []string{locked}, Adapter: adapter}); err == nil || !strings.Contains(err.Error(), "no scan root could be read") {
+		t.Fatalf("expected error when no root is readable, got %v", err)
+	}
+}
+
+func TestFromPathsProbesOnlyTheGivenDirectories(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	plain := filepath.Join(root, "plain")
+	nested := filepath.Join(plain, "nested-repo")
+	for _, dir := range []string{repo, nested} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+		return dir == repo || dir == nested, nil
+	}}
+
+	results, err := FromPaths(context.Background(), []string{plain, "", repo, repo + "/", filepath.Join(root, "missing")}, adapter, false)
+	if err != nil {
+		t.Fatalf("FromPaths: %v", err)
+	}
+	// plain is not walked, so nested-repo below it is not found.
+	if len(results) != 1 || results[0].Path != repo {
+		t.Fatalf("expected only %s, got %+v", repo, results)
+	}
+}
+
+func TestScanMaxDepthCountsLevelsBelowEachRoot(t *testing.T) {
+	root := t.TempDir()
+	other := t.TempDir()
+	for _, rel := range []string{"top", "top/inner/repo", "a/repo", "a/b/c/repo"} {
+		if err := os.MkdirAll(filepath.Join(root, rel), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(other, "x", "repo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+		return filepath.Base(dir) == "repo" || filepath.Base(dir) == "top", nil
+	}}
+	paths := func(maxDepth int) []string {
+		results, err := Scan(context.Background(), Options{Roots: []string{root, other}, Adapter: adapter, MaxDepth: maxDepth})
+		if err != nil {
+			t.Fatalf("scan with max depth %d: %v", maxDepth, err)
+		}
+		var out []string
+		for _, res := range results {
+			base := root
+			if strings.HasPrefix(res.Path, other) {
+				base = other
+			}
+			rel, _ := filepath.Rel(base, res.Path)
+			out = append(out, filepath.ToSlash(rel))
+		}
+		return out
+	}
+
+	// top is a repo, so top/inner/repo is never reached whatever the depth.
+	if got := strings.Join(paths(0), ","); got != "a/b/c/repo,a/repo,top,x/repo" {
+		t.Fatalf("unexpected unlimited scan results: %s", got)
+	}
+	// Depth is counted per root: x/repo is two levels below its own root.
+	if got := strings.Join(paths(2), ","); got != "a/repo,top,x/repo" {
+		t.Fatalf("unexpected depth-2 scan results: %s", got)
+	}
+	if got := strings.Join(paths(1), ","); got != "top" {
+		t.Fatalf("unexpected depth-1 scan results: %s", got)
+	}
+}
+
 func TestMatchesExcludeWithInvalidPattern(t *testing.T) {
 	// Test that MatchesExclude gracefully handles invalid glob patterns
 	// by continuing to the next pattern instead of failing.
@@ -412,3 +531,74 @@ func TestMatchesExcludeWithInvalidPattern(t *testing.T) {
 		t.Fatalf("expected MatchesExclude to return true when a valid pattern matches")
 	}
 }
+
+func TestScanResultsDoNotDependOnConcurrency(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{"b/repo", "a/repo", "a-b/repo", "c/deep/repo", "a/zz/repo"} {
+		if err := os.MkdirAll(filepath.Join(root, rel), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+		return filepath.Base(dir) == "repo", nil
+	}}
+
+	var want []string
+	for _, workers := range []int{1, 2, 8} {
+		results, err := Scan(context.Background(), Options{Roots: []string{root}, Adapter: adapter, Concurrency: workers})
+		if err != nil {
+			t.Fatalf("scan with %d workers: %v", workers, err)
+		}
+		got := make([]string, 0, len(results))
+		for _, result := range results {
+			rel, _ := filepath.Rel(root, result.Path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if want == nil {
+			want = got
+			if strings.Join(want, ",") != "a/repo,a/zz/repo,a-b/repo,b/repo,c/deep/repo" {
+				t.Fatalf("unexpected walk order: %v", want)
+			}
+			continue
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("expected %d workers to return %v, got %v", workers, want, got)
+		}
+	}
+}
+
+func TestScanHonorsConcurrencyBound(t *testing.T) {
+	root := t.TempDir()
+	for g := 0; g < 4; g++ {
+		for r := 0; r < 6; r++ {
+			if err := os.MkdirAll(filepath.Join(root, fmt.Sprintf("g%d", g), fmt.Sprintf("repo%d", r)), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, workers := range []int{1, 3} {
+		var inFlight, peak atomic.Int32
+		adapter := &stubAdapter{isRepoFn: func(_ context.Context, dir string) (bool, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := peak.Load()
+				if n <= seen || peak.CompareAndSwap(seen, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return strings.HasPrefix(filepath.Base(dir), "repo"), nil
+		}}
+		results, err := Scan(context.Background(), Options{Roots: []string{root}, Adapter: adapter, Concurrency: workers})
+		if err != nil {
+			t.Fatalf("scan with %d workers: %v", workers, err)
+		}
+		if len(results) != 24 {
+			t.Fatalf("expected 24 repos with %d workers, got %d", workers, len(results))
+		}
+		if got := peak.Load(); got > int32(workers) {
+			t.Fatalf("expected at most %d concurrent probes, saw %d", workers, got)
+		}
+	}
+}
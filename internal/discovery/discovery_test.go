@@ -49,6 +49,28 @@ var _ = Describe("Discovery", func() {
 		Expect(results).To(BeEmpty())
 	})
 
+	It("applies per-root excludes only beneath their own root", func() {
+		base := GinkgoT().TempDir()
+		rootA := filepath.Join(base, "a")
+		rootB := filepath.Join(base, "b")
+		Expect(exec.Command("git", "init", filepath.Join(rootA, "vendor", "dep")).Run()).To(Succeed())
+		Expect(exec.Command("git", "init", filepath.Join(rootA, "app")).Run()).To(Succeed())
+		vendoredB := filepath.Join(rootB, "vendor", "dep")
+		Expect(exec.Command("git", "init", vendoredB).Run()).To(Succeed())
+
+		results, err := discovery.Scan(context.Background(), discovery.Options{
+			Roots:       []string{rootA, rootB},
+			RootExclude: map[string][]string{rootA: {"vendor"}},
+			Adapter:     vcs.NewGitAdapter(nil),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		paths := make([]string, 0, len(results))
+		for _, res := range results {
+			paths = append(paths, res.Path)
+		}
+		Expect(paths).To(ConsistOf(filepath.Join(rootA, "app"), vendoredB))
+	})
+
 	It("detects linked .git directories", func() {
 		root := GinkgoT().TempDir()
 		repo := filepath.Join(root, "repo3")
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+
+// Package discoverytest builds directory trees for discovery tests and
+// benchmarks.
+package discoverytest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Marker is the file ScanTree writes into each leaf directory. Fake adapters
+// treat a directory holding it as a repo.
+const Marker = "REPO"
+
+// ScanTree lays out roots x groups x repos directories under a temp dir and
+// marks each leaf as a repo with a Marker file. It returns the root paths.
+func ScanTree(tb testing.TB, roots, groups, repos int) []string {
+	tb.Helper()
+	base := tb.TempDir()
+	rootPaths := make([]string, 0, roots)
+	for r := 0; r < roots; r++ {
+		root := filepath.Join(base, fmt.Sprintf("root-%d", r))
+		rootPaths = append(rootPaths, root)
+		for g := 0; g < groups; g++ {
+			for i := 0; i < repos; i++ {
+				dir := filepath.Join(root, fmt.Sprintf("group-%d", g), fmt.Sprintf("repo-%d", i))
+				if err := os.MkdirAll(dir, 0o755); err != nil {
+					tb.Fatalf("mkdir: %v", err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, Marker), nil, 0o644); err != nil {
+					tb.Fatalf("write marker: %v", err)
+				}
+			}
+		}
+	}
+	return rootPaths
+}
+
+// IsMarkedRepo reports whether dir holds a Marker file.
+func IsMarkedRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, Marker))
+	return err == nil
+}
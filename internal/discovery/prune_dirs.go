@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PruneOptions configures PruneEmptyDirs.
+type PruneOptions struct {
+	Roots []string
+	// Keep lists paths that are never removed or descended into, such as
+	// registered checkout locations.
+	Keep []string
+	// Exclude and RootExclude are matched exactly as scan matches them;
+	// matching directories are left alone along with everything below them.
+	Exclude     []string
+	RootExclude map[string][]string
+	DryRun      bool
+}
+
+// PruneEmptyDirs removes directories under opts.Roots that contain nothing but
+// other empty directories, deepest first, and returns the removed paths. The
+// roots themselves are never removed. The walk does not descend into
+// repositories (worktrees, bare repos, or Mercurial repos), kept or excluded
+// paths, dot-directories, or symlinks, so empty directories inside a checkout
+// or a tool's hidden state are left alone. Unreadable directories count as
+// non-empty. With DryRun nothing is removed and the paths that would be
+// removed are returned.
+func PruneEmptyDirs(opts PruneOptions) ([]string, error) {
+	kept := make(map[string]struct{}, len(opts.Keep))
+	for _, path := range opts.Keep {
+		if abs, err := filepath.Abs(path); err == nil {
+			kept[filepath.Clean(abs)] = struct{}{}
+		}
+	}
+	rootExclude, err := anchorRootExcludes(opts.RootExclude)
+	if err != nil {
+		return nil, err
+	}
+	exclude := append(append([]string(nil), opts.Exclude...), rootExclude...)
+	var absRoots []string
+	for _, root := range opts.Roots {
+		if root == "" {
+			continue
+		}
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		absRoots = append(absRoots, filepath.Clean(abs))
+	}
+	sort.Strings(absRoots)
+
+	// A nested root is walked as part of its outer root, but must survive
+	// that walk like any other root.
+	rootSet := make(map[string]struct{}, len(absRoots))
+	var accepted []string
+	for _, root := range absRoots {
+		rootSet[root] = struct{}{}
+		if !rootCovered(root, accepted) {
+			accepted = append(accepted, root)
+		}
+	}
+	p := &emptyDirPruner{keep: kept, exclude: exclude, roots: rootSet, dryRun: opts.DryRun}
+	for _, root := range accepted {
+		if _, err := p.prune(root); err != nil {
+			return p.removed, err
+		}
+	}
+	return p.removed, nil
+}
+
+type emptyDirPruner struct {
+	keep    map[string]struct{}
+	exclude []string
+	roots   map[string]struct{}
+	dryRun  bool
+	removed []string
+}
+
+// prune removes the empty subdirectories of dir and reports whether dir is
+// now empty itself.
+func (p *emptyDirPruner) prune(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || looksLikeRepo(dir, entries) {
+		return false, nil
+	}
+	empty := true
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			empty = false
+			continue
+		}
+		child := filepath.Join(dir, entry.Name())
+		if p.skip(child, entry.Name()) {
+			empty = false
+			continue
+		}
+		childEmpty, err := p.prune(child)
+		if err != nil {
+			return false, err
+		}
+		if _, isRoot := p.roots[child]; !childEmpty || isRoot {
+			empty = false
+			continue
+		}
+		if !p.dryRun {
+			if err := os.Remove(child); err != nil {
+				return false, fmt.Errorf("remove empty directory %s: %w", child, err)
+			}
+		}
+		p.removed = append(p.removed, child)
+	}
+	return empty, nil
+}
+
+// skip reports whether child is left alone: kept, excluded, or hidden.
+func (p *emptyDirPruner) skip(child, name string) bool {
+	if _, ok := p.keep[child]; ok {
+		return true
+	}
+	return strings.HasPrefix(name, ".") || MatchesExclude(child, p.exclude)
+}
+
+// looksLikeRepo reports whether dir is a repository root: a worktree with a
+// .git entry, a Mercurial .hg directory, or a bare git repo (HEAD plus
+// objects/).
+func looksLikeRepo(dir string, entries []os.DirEntry) bool {
+	hasHead, hasObjects := false, false
+	for _, entry := range entries {
+		switch entry.Name() {
+		case ".git", ".hg":
+			return true
+		case "HEAD":
+			hasHead = !entry.IsDir()
+		case "objects":
+			hasObjects = entry.IsDir()
+		}
+	}
+	return hasHead && hasObjects
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MIT
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPruneEmptyDirsRemovesEmptiedParentsOnly(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(rel string) string {
+		t.Helper()
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+		return path
+	}
+	emptied := mkdir("github.com/old-org/moved-away")
+	repo := mkdir("github.com/org/repo")
+	mkdir("github.com/org/repo/.git")
+	repoBuild := mkdir("github.com/org/repo/build")
+	tracked := mkdir("github.com/org/tracked-but-empty")
+	notes := mkdir("notes")
+	if err := os.WriteFile(filepath.Join(notes, "todo.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	preview, err := PruneEmptyDirs(PruneOptions{Roots: []string{root}, Keep: []string{tracked}, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run prune: %v", err)
+	}
+	want := []string{emptied, filepath.Dir(emptied)}
+	if !slices.Equal(preview, want) {
+		t.Fatalf("expected dry-run to list %v, got %v", want, preview)
+	}
+	if _, err := os.Stat(emptied); err != nil {
+		t.Fatalf("expected dry-run to leave directories in place: %v", err)
+	}
+
+	removed, err := PruneEmptyDirs(PruneOptions{Roots: []string{root}, Keep: []string{tracked}})
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if !slices.Equal(removed, want) {
+		t.Fatalf("expected %v removed, got %v", want, removed)
+	}
+	for _, gone := range want {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, stat err %v", gone, err)
+		}
+	}
+	for _, kept := range []string{root, repo, repoBuild, tracked, notes} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Fatalf("expected %s preserved: %v", kept, err)
+		}
+	}
+}
+
+func TestPruneEmptyDirsNeverRemovesRoots(t *testing.T) {
+	outer := t.TempDir()
+	inner := filepath.Join(outer, "inner")
+	if err := os.MkdirAll(filepath.Join(inner, "empty"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	removed, err := PruneEmptyDirs(PruneOptions{Roots: []string{inner, outer}})
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != filepath.Join(inner, "empty") {
+		t.Fatalf("expected only the empty child removed, got %v", removed)
+	}
+	if _, err := os.Stat(inner); err != nil {
+		t.Fatalf("expected nested root preserved: %v", err)
+	}
+}
+
+func TestPruneEmptyDirsSkipsExcludedAndHiddenDirs(t *testing.T) {
+	root := t.TempDir()
+	other := t.TempDir()
+	for _, rel := range []string{"app/node_modules/cache", "scratch/empty", ".cache/empty", "pruned/empty"} {
+		if err := os.MkdirAll(filepath.Join(root, rel), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+	}
+	removed, err := PruneEmptyDirs(PruneOptions{
+		Roots:       []string{root},
+		Exclude:     []string{"**/node_modules/**"},
+		RootExclude: map[string][]string{root: {"scratch"}, other: {"pruned"}},
+	})
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	want := []string{filepath.Join(root, "pruned", "empty"), filepath.Join(root, "pruned")}
+	if !slices.Equal(removed, want) {
+		t.Fatalf("expected only %v removed, got %v", want, removed)
+	}
+	for _, rel := range []string{"app/node_modules/cache", "scratch/empty", ".cache/empty"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+			t.Fatalf("expected %s preserved: %v", rel, err)
+		}
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/discovery/discoverytest"
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
 )
 
 type benchAdapter struct{}
@@ -101,3 +104,112 @@ func BenchmarkStatusReport(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkStatusURLNormalization runs the inspection and remote-mismatch
+// steps of a status run, which normalize the same remote URLs, with and
+// without the per-run normalization cache.
+func BenchmarkStatusURLNormalization(b *testing.B) {
+	eng := benchmarkEngineWithRepos(100)
+	ctx := context.Background()
+	entries := eng.loadStatusEntries()
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%t", cached), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var urls vcs.URLNormalizer = vcs.NewGitURLNormalizer()
+				if cached {
+					urls = vcs.NewCachingURLNormalizer(urls)
+				}
+				for _, entry := range entries {
+					status, err := eng.inspectRepo(ctx, entry.Path, urls, InspectFull)
+					if err != nil {
+						b.Fatalf("inspect failed: %v", err)
+					}
+					filterStatus(FilterRemoteMismatch, *status, eng.registry, urls)
+				}
+			}
+		})
+	}
+}
+
+// gitCostBenchAdapter charges each inspection call about what forking git
+// costs, so skipped calls show up in the timings.
+type gitCostBenchAdapter struct {
+	benchAdapter
+}
+
+func (g *gitCostBenchAdapter) Remotes(ctx context.Context, dir string) ([]model.Remote, error) {
+	time.Sleep(200 * time.Microsecond)
+	return g.benchAdapter.Remotes(ctx, dir)
+}
+
+func (g *gitCostBenchAdapter) Head(ctx context.Context, dir string) (model.Head, error) {
+	time.Sleep(200 * time.Microsecond)
+	return g.benchAdapter.Head(ctx, dir)
+}
+
+func (g *gitCostBenchAdapter) WorktreeStatus(ctx context.Context, dir string) (*model.Worktree, error) {
+	time.Sleep(200 * time.Microsecond)
+	return g.benchAdapter.WorktreeStatus(ctx, dir)
+}
+
+func (g *gitCostBenchAdapter) TrackingStatus(ctx context.Context, dir string) (model.Tracking, error) {
+	time.Sleep(400 * time.Microsecond)
+	return g.benchAdapter.TrackingStatus(ctx, dir)
+}
+
+func (g *gitCostBenchAdapter) HasSubmodules(ctx context.Context, dir string) (bool, error) {
+	time.Sleep(200 * time.Microsecond)
+	return g.benchAdapter.HasSubmodules(ctx, dir)
+}
+
+// BenchmarkStatusDirtyFilter compares a dirty-filtered status run over clean
+// repos with and without the worktree-only first pass.
+func BenchmarkStatusDirtyFilter(b *testing.B) {
+	eng := benchmarkEngineWithRepos(100)
+	eng.adapter = &gitCostBenchAdapter{}
+	ctx := context.Background()
+	for _, scope := range []InspectScope{InspectFull, InspectWorktree} {
+		b.Run(fmt.Sprintf("worktree_only=%t", scope == InspectWorktree), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, entry := range eng.loadStatusEntries() {
+					status, err := eng.InspectRepoScope(ctx, entry.Path, scope)
+					if err != nil {
+						b.Fatalf("inspect failed: %v", err)
+					}
+					filterStatus(FilterDirty, *status, eng.registry, eng.adapter)
+				}
+			}
+		})
+	}
+}
+
+// scanBenchAdapter treats directories holding a discoverytest marker as repos and
+// charges each probe about what forking git costs.
+type scanBenchAdapter struct {
+	benchAdapter
+}
+
+func (s *scanBenchAdapter) IsRepo(_ context.Context, dir string) (bool, error) {
+	time.Sleep(200 * time.Microsecond)
+	return discoverytest.IsMarkedRepo(dir), nil
+}
+
+func BenchmarkScanConcurrency(b *testing.B) {
+	roots := discoverytest.ScanTree(b, 1, 8, 16)
+	for _, workers := range []int{1, 4, 0} {
+		b.Run(fmt.Sprintf("concurrency=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				eng := New(&config.Config{}, &registry.Registry{}, &scanBenchAdapter{}, nil, nil, nil)
+				results, err := eng.Scan(context.Background(), ScanOptions{Roots: roots, Concurrency: workers})
+				if err != nil {
+					b.Fatalf("scan failed: %v", err)
+				}
+				if len(results) != 8*16 {
+					b.Fatalf("unexpected repo count: got=%d want=%d", len(results), 8*16)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// branchTrackingAdapter reports a clean worktree on head, behind upstream, and
+// answers remote default branch lookups from defaults.
+type branchTrackingAdapter struct {
+	*planAdapter
+	head     string
+	upstream string
+	defaults map[string]string
+}
+
+func (a *branchTrackingAdapter) Head(context.Context, string) (model.Head, error) {
+	return model.Head{Branch: a.head}, nil
+}
+
+func (a *branchTrackingAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: model.TrackingBehind, Upstream: a.upstream}, nil
+}
+
+func (a *branchTrackingAdapter) RemoteDefaultBranch(_ context.Context, dir, remote string) (string, error) {
+	if branch, ok := a.defaults[dir+":"+remote]; ok {
+		return branch, nil
+	}
+	return "", errors.New("remote HEAD not set")
+}
+
+func TestDefaultBranchForPrecedence(t *testing.T) {
+	eng := newPlanExecEngine(&planAdapter{})
+	eng.cfg = &config.Config{Defaults: config.Defaults{MainBranch: "trunk"}}
+	if got := eng.defaultBranchFor(registry.Entry{DefaultBranch: "develop"}); got != "develop" {
+		t.Fatalf("expected the entry default to win, got %q", got)
+	}
+	if got := eng.defaultBranchFor(registry.Entry{}); got != "trunk" {
+		t.Fatalf("expected the config default next, got %q", got)
+	}
+	eng.cfg = &config.Config{}
+	if got := eng.defaultBranchFor(registry.Entry{DefaultBranch: "  "}); got != "main" {
+		t.Fatalf("expected main as the last resort, got %q", got)
+	}
+}
+
+func TestPullRebaseSkipReasonRequiresMainBranchTrackItself(t *testing.T) {
+	status := &model.RepoStatus{
+		Head:     model.Head{Branch: "develop"},
+		Worktree: &model.Worktree{},
+		Tracking: model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main"},
+	}
+	if got := pullRebaseSkipReason(status, PullRebasePolicyOptions{MainBranch: "develop"}); got != `upstream "origin/main" is not develop` {
+		t.Fatalf("expected a default branch tracking another branch to skip, got %q", got)
+	}
+	// Other branches may track whatever they like.
+	if got := pullRebaseSkipReason(status, PullRebasePolicyOptions{MainBranch: "main"}); got != "" {
+		t.Fatalf("expected a non-default branch to rebase, got %q", got)
+	}
+	status.Tracking.Upstream = "origin/develop"
+	if got := pullRebaseSkipReason(status, PullRebasePolicyOptions{MainBranch: "develop"}); got != "" {
+		t.Fatalf("expected develop tracking origin/develop to rebase, got %q", got)
+	}
+}
+
+func TestUpdateLocalUsesPerRepoDefaultBranch(t *testing.T) {
+	adapter := &branchTrackingAdapter{planAdapter: &planAdapter{}, head: "develop", upstream: "origin/main"}
+	eng := newPlanExecEngine(adapter)
+	eng.cfg = &config.Config{Defaults: config.Defaults{MainBranch: "main"}}
+
+	// With the config default, develop is an ordinary branch and rebases.
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+	_, executed := eng.planAndExecute(t, entry, SyncOptions{UpdateLocal: true})
+	if executed.Outcome != SyncOutcomeRebased {
+		t.Fatalf("expected a rebase, got %#v", executed)
+	}
+
+	// Once the repo's default is develop, develop tracking origin/main is skipped.
+	entry.DefaultBranch = "develop"
+	plan, executed := eng.planAndExecute(t, entry, SyncOptions{UpdateLocal: true})
+	want := `upstream "origin/main" is not develop`
+	if plan.Outcome != SyncOutcomeSkippedLocalUpdate || plan.SkipReason != want {
+		t.Fatalf("expected a skip for the per-repo default, got %#v", plan)
+	}
+	if executed.Outcome != SyncOutcomeSkippedLocalUpdate || executed.SkipReason != want {
+		t.Fatalf("expected the executed result to keep the skip, got %#v", executed)
+	}
+}
+
+func TestRemoteDefaultBranchUsesPrimaryRemote(t *testing.T) {
+	adapter := &branchTrackingAdapter{planAdapter: &planAdapter{}, defaults: map[string]string{"/repo:upstream": "develop"}}
+	eng := newPlanExecEngine(adapter)
+	if got := eng.remoteDefaultBranch(context.Background(), "/repo", "upstream"); got != "develop" {
+		t.Fatalf("expected develop, got %q", got)
+	}
+	if got := eng.remoteDefaultBranch(context.Background(), "/repo", "origin"); got != "" {
+		t.Fatalf("expected an unknown remote HEAD to resolve empty, got %q", got)
+	}
+	if got := eng.remoteDefaultBranch(context.Background(), "/repo", ""); got != "" {
+		t.Fatalf("expected a repo without remotes to resolve empty, got %q", got)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -40,23 +44,31 @@ const (
 	FilterEqual          FilterKind = "equal"
 	FilterRemoteMismatch FilterKind = "remote-mismatch"
 	FilterMissing        FilterKind = "missing"
+	FilterStaleMetadata  FilterKind = "stale-metadata"
+	FilterStaleUpstream  FilterKind = "stale-upstream"
+	// FilterBranchesBehindDefault matches repos with local branches behind the
+	// base branch. It is status-only: sync never updates non-current branches.
+	FilterBranchesBehindDefault FilterKind = "branches-behind-default"
 )
 
 // knownFilterKinds is the set of filter values the engine understands. It backs
 // both up-front validation (ParseFilterKind) and the internal fail-closed
 // defense so an unrecognized filter never silently matches every repository.
 var knownFilterKinds = map[FilterKind]struct{}{
-	FilterAll:            {},
-	FilterErrors:         {},
-	FilterDirty:          {},
-	FilterClean:          {},
-	FilterGone:           {},
-	FilterDiverged:       {},
-	FilterBehind:         {},
-	FilterAhead:          {},
-	FilterEqual:          {},
-	FilterRemoteMismatch: {},
-	FilterMissing:        {},
+	FilterAll:                   {},
+	FilterErrors:                {},
+	FilterDirty:                 {},
+	FilterClean:                 {},
+	FilterGone:                  {},
+	FilterDiverged:              {},
+	FilterBehind:                {},
+	FilterAhead:                 {},
+	FilterEqual:                 {},
+	FilterRemoteMismatch:        {},
+	FilterMissing:               {},
+	FilterStaleMetadata:         {},
+	FilterStaleUpstream:         {},
+	FilterBranchesBehindDefault: {},
 }
 
 // isKnownFilterKind reports whether kind is a recognized filter value. An empty
@@ -98,6 +110,9 @@ type Engine struct {
 	logger     obs.Logger
 
 	registryMu sync.Mutex
+	// oversubscribeWarn keeps the clamping warning to one line per engine,
+	// since a single sync plans and executes with the same options.
+	oversubscribeWarn sync.Once
 }
 
 // New creates a new Engine with the given configuration.
@@ -135,20 +150,96 @@ func (e *Engine) Adapter() vcs.Adapter { return e.adapter }
 
 // ScanOptions configures a scan operation.
 type ScanOptions struct {
-	Roots          []string
-	Exclude        []string
+	Roots   []string
+	Exclude []string
+	// RootExclude holds per-root patterns keyed by root path, applied on top
+	// of Exclude only beneath the matching root.
+	RootExclude    map[string][]string
 	FollowSymlinks bool
+	// Concurrency bounds how many directories discovery probes at once. It
+	// governs local filesystem and VCS probing only; scan makes no network
+	// calls. Zero or less uses runtime.NumCPU().
+	Concurrency int
+	// MaxJobs is the --jobs cap on worker count; see Engine.maxJobs.
+	MaxJobs int
+	// MaxDepth limits how many levels below each root discovery descends.
+	// Zero means unlimited.
+	MaxDepth int
+	// Paths, when set, are probed as candidate repo directories instead of
+	// walking Roots. Nothing is walked, so no entry is marked missing.
+	Paths []string
+	// PruneRegistry handles entries that were not rediscovered and whose path
+	// no longer exists, wherever they are; see PruneRegistryMode.
+	PruneRegistry PruneRegistryMode
+	// RegisterOnly skips reading remotes as well. A repo already registered
+	// at the same path keeps its repo ID and remote URL; a new one is
+	// recorded as local:<path> with an empty remote URL until the first
+	// status run resolves it.
+	RegisterOnly bool
+}
+
+// PruneRegistryMode selects what scan does with registry entries that were
+// not rediscovered and whose path is gone.
+type PruneRegistryMode string
+
+const (
+	// PruneRegistryOff leaves such entries to the root-based missing check.
+	PruneRegistryOff PruneRegistryMode = ""
+	// PruneRegistryMark marks them missing, including outside the roots.
+	PruneRegistryMark PruneRegistryMode = "mark"
+	// PruneRegistryRemove drops them from the registry.
+	PruneRegistryRemove PruneRegistryMode = "remove"
+)
+
+// scanConcurrency sizes the discovery worker pool. It is independent of the
+// sync/status concurrency default but shares the job cap and the ceiling.
+func (e *Engine) scanConcurrency(requested, maxJobs int) int {
+	if requested <= 0 {
+		requested = runtime.NumCPU()
+	}
+	if jobs := e.maxJobs(maxJobs); jobs > 0 {
+		requested = min(requested, jobs)
+	}
+	return e.effectiveConcurrency(requested, false)
+}
+
+// ScanReport is the result of ScanWithReport: the discovered repos, plus the
+// directories discovery skipped because they could not be read. Pruned lists
+// the entries ScanOptions.PruneRegistry marked missing or removed.
+type ScanReport struct {
+	Repos      []model.RepoStatus
+	WalkErrors []discovery.WalkError
+	Pruned     []registry.Entry
 }
 
-// Scan discovers repos and updates the registry.
+// Scan discovers repos and updates the registry. Unreadable directories are
+// logged as warnings and otherwise ignored.
 func (e *Engine) Scan(ctx context.Context, opts ScanOptions) ([]model.RepoStatus, error) {
+	report, err := e.ScanWithReport(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, walkErr := range report.WalkErrors {
+		e.logger.Warnf("scan: could not read %s: %v", walkErr.Path, walkErr.Err)
+	}
+	return report.Repos, nil
+}
+
+// ScanWithReport discovers repos and updates the registry, returning the
+// directories that could not be read alongside the repos. Registry entries
+// under an unreadable directory keep their status, since the scan could not
+// tell whether they are still there. It fails only when no root could be
+// read at all.
+func (e *Engine) ScanWithReport(ctx context.Context, opts ScanOptions) (ScanReport, error) {
 	if e.registry == nil {
 		e.registry = &registry.Registry{}
 	}
 
 	roots := opts.Roots
-	if len(roots) == 0 {
-		return nil, errors.New("no scan roots provided")
+	if len(opts.Paths) > 0 {
+		roots = nil
+	} else if len(roots) == 0 {
+		return ScanReport{}, errors.New("no scan roots provided")
 	}
 	exclude := opts.Exclude
 	if len(exclude) == 0 {
@@ -156,21 +247,38 @@ func (e *Engine) Scan(ctx context.Context, opts ScanOptions) ([]model.RepoStatus
 	}
 
 	if err := e.registry.ValidatePaths(); err != nil {
-		return nil, err
+		return ScanReport{}, err
 	}
 	ignoredPaths := ignoredPathSet(e.cfg)
 	if len(ignoredPaths) > 0 {
 		e.registry.Entries = filterRegistryEntriesByIgnoredPaths(e.registry.Entries, ignoredPaths)
 	}
 
-	results, err := discovery.Scan(ctx, discovery.Options{
-		Roots:          roots,
-		Exclude:        exclude,
-		FollowSymlinks: opts.FollowSymlinks,
-		Adapter:        e.adapter,
-	})
+	var (
+		scanned discovery.ScanResult
+		err     error
+	)
+	if len(opts.Paths) > 0 {
+		scanned.Repos, err = discovery.FromPaths(ctx, opts.Paths, e.adapter, opts.RegisterOnly)
+	} else {
+		scanned, err = discovery.ScanWithErrors(ctx, discovery.Options{
+			Roots:          roots,
+			Exclude:        exclude,
+			RootExclude:    opts.RootExclude,
+			FollowSymlinks: opts.FollowSymlinks,
+			Adapter:        e.adapter,
+			Concurrency:    e.scanConcurrency(opts.Concurrency, opts.MaxJobs),
+			MaxDepth:       opts.MaxDepth,
+			SkipRemotes:    opts.RegisterOnly,
+		})
+	}
 	if err != nil {
-		return nil, err
+		return ScanReport{}, err
+	}
+	results := scanned.Repos
+	unreadable := make([]string, 0, len(scanned.WalkErrors))
+	for _, walkErr := range scanned.WalkErrors {
+		unreadable = append(unreadable, walkErr.Path)
 	}
 
 	now := time.Now()
@@ -184,9 +292,18 @@ func (e *Engine) Scan(ctx context.Context, opts ScanOptions) ([]model.RepoStatus
 		}
 		discoveredPaths[discoveredPath] = struct{}{}
 		repoID := res.RepoID
+		remoteURL := res.RemoteURL
+		if opts.RegisterOnly {
+			if existing := e.registryEntryAtPath(res.Path); existing != nil {
+				repoID, remoteURL = existing.RepoID, existing.RemoteURL
+			}
+		}
 		if repoID == "" {
 			repoID = "local:" + filepath.ToSlash(res.Path)
 		}
+		// A registered repo whose old path is gone is flagged as moved rather
+		// than re-homed, so the path change is applied by reconcile paths.
+		moved := e.markRegistryEntryMoved(repoID, res.Path, now)
 		seedEntry := registry.Entry{}
 		if existing := e.registry.FindEntry(repoID, res.Path); existing != nil {
 			seedEntry = *existing
@@ -205,12 +322,18 @@ func (e *Engine) Scan(ctx context.Context, opts ScanOptions) ([]model.RepoStatus
 		entry := registry.Entry{
 			RepoID:    repoID,
 			Path:      res.Path,
-			RemoteURL: res.RemoteURL,
+			RemoteURL: remoteURL,
 			LastSeen:  now,
 			Status:    registry.StatusPresent,
+			// Empty when the remote HEAD is unknown; Upsert then keeps the
+			// previously recorded default branch.
+			DefaultBranch: e.remoteDefaultBranch(ctx, res.Path, res.PrimaryRemote),
+			Shallow:       e.inspectShallow(ctx, res.Path),
 		}
 		registry.StoreRepoMetadataStatus(&entry, status)
-		e.upsertRegistryEntry(entry)
+		if !moved {
+			e.upsertRegistryEntry(entry)
+		}
 		checkoutID := ""
 		if entry := e.registry.FindEntry(repoID, res.Path); entry != nil {
 			checkoutID = entry.CheckoutID
@@ -224,17 +347,82 @@ func (e *Engine) Scan(ctx context.Context, opts ScanOptions) ([]model.RepoStatus
 		if _, ok := discoveredPaths[entryPath]; ok {
 			continue
 		}
-		if !pathUnderAnyRoot(entryPath, roots) {
+		if !pathUnderAnyRoot(entryPath, roots) || pathUnderAnyRoot(entryPath, unreadable) {
+			continue
+		}
+		if e.registry.Entries[i].Status == registry.StatusMoved {
 			continue
 		}
 		// Any registry entry under scanned roots that was not rediscovered is
 		// treated as absent/missing after this scan.
 		e.registry.Entries[i].Status = registry.StatusMissing
 	}
+	pruned := e.pruneUndiscoveredEntries(discoveredPaths, opts.PruneRegistry)
 	sortRepoStatuses(statuses)
 	e.setRegistryUpdatedAt(now)
 
-	return statuses, nil
+	return ScanReport{Repos: statuses, WalkErrors: scanned.WalkErrors, Pruned: pruned}, nil
+}
+
+// pruneUndiscoveredEntries applies mode to every entry scan did not
+// rediscover whose path no longer exists, and returns those entries. Moved
+// entries are kept for reconcile paths, and entries whose path cannot be
+// checked (for example, permission denied) are left alone.
+func (e *Engine) pruneUndiscoveredEntries(discovered map[string]struct{}, mode PruneRegistryMode) []registry.Entry {
+	if mode == PruneRegistryOff {
+		return nil
+	}
+	var pruned []registry.Entry
+	kept := e.registry.Entries[:0]
+	for _, entry := range e.registry.Entries {
+		_, found := discovered[filepath.Clean(entry.Path)]
+		if found || entry.Status == registry.StatusMoved {
+			kept = append(kept, entry)
+			continue
+		}
+		if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+			kept = append(kept, entry)
+			continue
+		}
+		entry.Status = registry.StatusMissing
+		pruned = append(pruned, entry)
+		if mode != PruneRegistryRemove {
+			kept = append(kept, entry)
+		}
+	}
+	e.registry.Entries = kept
+	return pruned
+}
+
+// remoteDefaultBranch reads the branch remote's HEAD points at, or "" when the
+// adapter cannot tell (no remote, HEAD symref never recorded, non-Git backend).
+func (e *Engine) remoteDefaultBranch(ctx context.Context, dir, remote string) string {
+	reader, ok := e.adapter.(vcs.RemoteDefaultBranchReader)
+	if !ok || strings.TrimSpace(remote) == "" {
+		return ""
+	}
+	branch, err := reader.RemoteDefaultBranch(ctx, dir, remote)
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
+// remoteHeadBranch asks remote which branch its HEAD points at now, or ""
+// when the adapter cannot tell (unreachable remote, non-Git backend).
+func (e *Engine) remoteHeadBranch(ctx context.Context, dir, remote string) string {
+	reader, ok := e.adapter.(vcs.RemoteHeadReader)
+	if !ok || strings.TrimSpace(remote) == "" {
+		return ""
+	}
+	branch, err := reader.RemoteHeadBranch(ctx, dir, remote)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Debugf("remote HEAD lookup failed for %s (%s): %v", dir, remote, err)
+		}
+		return ""
+	}
+	return branch
 }
 
 func pathUnderAnyRoot(path string, roots []string) bool {
@@ -282,6 +470,30 @@ type StatusOptions struct {
 	Filter      FilterKind
 	Concurrency int
 	Timeout     int // seconds per repo
+	// VerifyIgnored lists ignored worktree files for each repo, bounded by the
+	// same per-repo timeout as the rest of the inspection.
+	VerifyIgnored bool
+	// AllowOversubscribe disables clamping Concurrency to the CPU ceiling.
+	AllowOversubscribe bool
+	// MaxJobs is the --jobs cap on worker count; see Engine.maxJobs.
+	MaxJobs int
+	// BehindDefaultThreshold is the minimum number of commits a local branch
+	// must be behind the base branch to count for FilterBranchesBehindDefault.
+	// Values below 1 mean 1.
+	BehindDefaultThreshold int
+	// ProbeRemoteHead asks each remote for its live HEAD when checking for a
+	// stale upstream instead of reading the locally recorded
+	// refs/remotes/<remote>/HEAD. FilterStaleUpstream implies it.
+	ProbeRemoteHead bool
+	// StopWhen, when set, is called with each inspected repo that passes
+	// Filter, in completion order. The first true result cancels the rest of
+	// the run: no further repos are started, in-flight inspections are
+	// cancelled and discarded, and the report is marked Stopped.
+	StopWhen func(model.RepoStatus) bool
+	// Cache, when set, skips inspecting repos whose inspect fingerprint is
+	// unchanged since the cached run (if Cache.Reuse) and is refreshed with
+	// this run's results; see StatusCache.
+	Cache *StatusCache
 }
 
 // Status inspects all registered repos and returns their status.
@@ -289,23 +501,55 @@ func (e *Engine) Status(ctx context.Context, opts StatusOptions) (*model.StatusR
 	if e.registry == nil {
 		return nil, errors.New("registry not loaded")
 	}
+	concurrency, timeoutSeconds := e.statusLimits(opts)
+	entries := e.loadStatusEntries()
+	allResults, results, stopped := e.collectStatusResults(ctx, entries, concurrency, timeoutSeconds, opts, nil)
+	e.writeRepoMetadataSnapshots(allResults)
+	report := e.buildStatusReport(results)
+	report.Stopped = stopped
+	return report, nil
+}
+
+// StatusStream inspects all registered repos like Status but hands each result
+// that passes the filter to fn as soon as its inspection completes, instead of
+// collecting a sorted report. Results arrive in completion order and are not
+// retained: only the repo metadata fields written back to the registry are
+// kept until the run ends (and full results when opts.Cache is set, which
+// stores them anyway). fn runs on the coordinator goroutine, so callers can
+// write output without additional synchronization.
+func (e *Engine) StatusStream(ctx context.Context, opts StatusOptions, fn func(model.RepoStatus)) error {
+	if e.registry == nil {
+		return errors.New("registry not loaded")
+	}
+	if fn == nil {
+		return errors.New("status stream callback is nil")
+	}
+	concurrency, timeoutSeconds := e.statusLimits(opts)
+	entries := e.loadStatusEntries()
+	allResults, _, _ := e.collectStatusResults(ctx, entries, concurrency, timeoutSeconds, opts, fn)
+	e.writeRepoMetadataSnapshots(allResults)
+	return nil
+}
 
+// statusLimits resolves the worker count and per-repo timeout for a status run
+// from opts and the configured defaults. The worker count is opts.Concurrency,
+// then defaults.concurrency, then 4, capped by capJobs and the CPU ceiling.
+func (e *Engine) statusLimits(opts StatusOptions) (int, int) {
 	concurrency := opts.Concurrency
+	builtin := false
 	if concurrency <= 0 {
 		concurrency = e.cfg.Defaults.Concurrency
 		if concurrency <= 0 {
 			concurrency = 4
+			builtin = true
 		}
 	}
+	concurrency = e.effectiveConcurrency(e.capJobs(concurrency, builtin, opts.MaxJobs), opts.AllowOversubscribe)
 	timeoutSeconds := opts.Timeout
 	if timeoutSeconds <= 0 {
 		timeoutSeconds = e.cfg.Defaults.TimeoutSeconds
 	}
-
-	entries := e.loadStatusEntries()
-	allResults, results := e.collectStatusResults(ctx, entries, concurrency, timeoutSeconds, opts.Filter)
-	e.writeRepoMetadataSnapshots(allResults)
-	return e.buildStatusReport(results), nil
+	return concurrency, timeoutSeconds
 }
 
 // loadStatusEntries snapshots the registry entries to decouple worker scheduling
@@ -315,40 +559,97 @@ func (e *Engine) loadStatusEntries() []registry.Entry {
 }
 
 // collectStatusResults runs all repo inspections concurrently using the semaphore+channel
-// pattern, drains results, and applies the filter. The concurrency model is preserved
-// exactly: semaphore controls parallelism, out channel buffers worker output.
-func (e *Engine) collectStatusResults(ctx context.Context, entries []registry.Entry, concurrency, timeoutSeconds int, filter FilterKind) ([]model.RepoStatus, []model.RepoStatus) {
-	type result struct {
-		status model.RepoStatus
+// pattern, drains results, and applies the filter. The semaphore controls
+// parallelism and the out channel buffers worker output; workers are started
+// from a separate goroutine so results are drained (and opts.StopWhen checked)
+// while later repos are still waiting for a slot. When emit is non-nil,
+// filtered results are passed to it as they are drained rather than retained,
+// results is nil, and allResults holds only each repo's metadataSnapshot.
+// stopped reports whether opts.StopWhen ended the run early.
+func (e *Engine) collectStatusResults(ctx context.Context, entries []registry.Entry, concurrency, timeoutSeconds int, opts StatusOptions, emit func(model.RepoStatus)) (allResults, results []model.RepoStatus, stopped bool) {
+	allResults = make([]model.RepoStatus, 0, len(entries))
+	if emit == nil {
+		results = make([]model.RepoStatus, 0, len(entries))
+	}
+	// One normalization cache per run: inspection and remote-mismatch
+	// filtering see the same URLs, and a fresh cache cannot go stale.
+	urls := vcs.NewCachingURLNormalizer(e.adapter)
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if opts.StopWhen != nil {
+		runCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
 	}
-
-	allResults := make([]model.RepoStatus, 0, len(entries))
-	results := make([]model.RepoStatus, 0, len(entries))
 	sem := make(chan struct{}, concurrency)
-	out := make(chan result, workerChannelBufferSize(len(entries), concurrency))
-	spawned := 0
-
-	for _, entry := range entries {
-		sem <- struct{}{}
-		spawned++
-		go func(entry registry.Entry) {
-			status := e.statusWorker(ctx, entry, timeoutSeconds)
-			<-sem // release before writing to out to prevent deadlock when out is full
-			out <- result{status: status}
-		}(entry)
-	}
-
-	for i := 0; i < spawned; i++ {
-		res := <-out
-		allResults = append(allResults, res.status)
-		if filterStatus(filter, res.status, e.registry) {
+	out := make(chan statusResult, workerChannelBufferSize(len(entries), concurrency))
+	// stop is closed by StopWhen; unlike a caller cancellation, which still
+	// reports every repo with its context error, it also ends spawning.
+	stop := make(chan struct{})
+	spawnedCh := make(chan int, 1)
+	var cacheResults []statusResult
+
+	go func() {
+		spawned := 0
+		defer func() { spawnedCh <- spawned }()
+		for _, entry := range entries {
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case <-stop:
+				<-sem
+				return
+			default:
+			}
+			spawned++
+			go func(entry registry.Entry) {
+				res := e.cachedStatusWorker(runCtx, entry, timeoutSeconds, opts, urls)
+				<-sem // release before writing to out to prevent deadlock when out is full
+				out <- res
+			}(entry)
+		}
+	}()
+
+	for received, total := 0, -1; total < 0 || received < total; {
+		select {
+		case n := <-spawnedCh:
+			total = n
+			continue
+		case res := <-out:
+			received++
+			if stopped {
+				// Cancelled by StopWhen mid-inspection; not a real result.
+				continue
+			}
+			if emit != nil {
+				allResults = append(allResults, metadataSnapshot(res.status))
+			} else {
+				allResults = append(allResults, res.status)
+			}
+			if opts.Cache != nil {
+				cacheResults = append(cacheResults, res)
+			}
+			if !filterStatus(opts.Filter, res.status, e.registry, urls) {
+				continue
+			}
+			if opts.StopWhen != nil && opts.StopWhen(res.status) {
+				stopped = true
+				close(stop)
+				cancel()
+			}
+			if emit != nil {
+				emit(res.status)
+				continue
+			}
 			results = append(results, res.status)
 		}
 	}
-	return allResults, results
+	e.updateStatusCache(opts.Cache, entries, cacheResults)
+	return allResults, results, stopped
 }
 
-func (e *Engine) statusWorker(ctx context.Context, entry registry.Entry, timeoutSeconds int) model.RepoStatus {
+func (e *Engine) statusWorker(ctx context.Context, entry registry.Entry, timeoutSeconds int, opts StatusOptions, urls vcs.URLNormalizer) model.RepoStatus {
 	if entry.Status == registry.StatusMissing {
 		missing := model.RepoStatus{
 			RepoID:     entry.RepoID,
@@ -363,12 +664,17 @@ func (e *Engine) statusWorker(ctx context.Context, entry registry.Entry, timeout
 		return missing
 	}
 	repoCtx := ctx
+	timeoutSeconds = repoTimeout(entry, timeoutSeconds)
 	if timeoutSeconds > 0 {
 		var cancel context.CancelFunc
 		repoCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
 		defer cancel()
 	}
-	status, err := e.InspectRepo(repoCtx, entry.Path)
+	scope := statusInspectScope(opts)
+	status, err := e.inspectRepo(repoCtx, entry.Path, urls, scope)
+	if err == nil && scope == InspectWorktree && filterStatus(opts.Filter, *status, e.registry, urls) {
+		err = e.inspectRepoDetails(repoCtx, status)
+	}
 	if err != nil {
 		// Preserve partial results: represent per-repo inspect failures in-band
 		// instead of aborting the full status run.
@@ -394,9 +700,54 @@ func (e *Engine) statusWorker(ctx context.Context, entry registry.Entry, timeout
 	if entry.Type != "" {
 		status.Type = entry.Type
 	}
+	if opts.VerifyIgnored && !status.Bare {
+		status.Ignored = e.inspectIgnoredFiles(repoCtx, entry.Path)
+	}
+	if opts.Filter == FilterBranchesBehindDefault {
+		e.countBranchesBehindBase(repoCtx, status, opts.BehindDefaultThreshold)
+	}
+	if opts.probeRemoteHead() {
+		e.probeStaleUpstream(repoCtx, status)
+	}
 	return *status
 }
 
+// probeRemoteHead reports whether the stale-upstream check should query each
+// remote's live HEAD rather than the locally recorded one.
+func (opts StatusOptions) probeRemoteHead() bool {
+	return opts.ProbeRemoteHead || opts.Filter == FilterStaleUpstream
+}
+
+func (e *Engine) inspectIgnoredFiles(ctx context.Context, path string) *model.IgnoredFileStatus {
+	inspector, ok := e.adapter.(vcs.IgnoredFileInspector)
+	if !ok {
+		return nil
+	}
+	paths, err := inspector.IgnoredFiles(ctx, path)
+	if err != nil {
+		return &model.IgnoredFileStatus{InspectionError: err.Error()}
+	}
+	return &model.IgnoredFileStatus{Count: len(paths), Paths: paths}
+}
+
+// metadataSnapshot returns the part of status that writeRepoMetadataSnapshots
+// reads, so a streamed run can defer the registry write-back without holding
+// every full result.
+func metadataSnapshot(status model.RepoStatus) model.RepoStatus {
+	return model.RepoStatus{
+		RepoID:                  status.RepoID,
+		Path:                    status.Path,
+		Error:                   status.Error,
+		PrimaryRemote:           status.PrimaryRemote,
+		Remotes:                 status.Remotes,
+		Shallow:                 status.Shallow,
+		RepoMetadataFile:        status.RepoMetadataFile,
+		RepoMetadataError:       status.RepoMetadataError,
+		RepoMetadataFingerprint: status.RepoMetadataFingerprint,
+		RepoMetadata:            status.RepoMetadata,
+	}
+}
+
 func (e *Engine) writeRepoMetadataSnapshots(statuses []model.RepoStatus) {
 	if len(statuses) == 0 {
 		return
@@ -407,16 +758,42 @@ func (e *Engine) writeRepoMetadataSnapshots(statuses []model.RepoStatus) {
 		return
 	}
 	for _, status := range statuses {
+		e.resolveDeferredRemote(status)
 		idx := e.registry.FindEntryIndex(status.RepoID, status.Path)
 		if idx < 0 {
 			continue
 		}
 		entry := e.registry.Entries[idx]
 		registry.StoreRepoMetadataStatus(&entry, status)
+		// A failed inspection never read the shallow state; keep the recorded one.
+		if status.Error == "" {
+			entry.Shallow = status.Shallow
+		}
 		e.registry.Entries[idx] = entry
 	}
 }
 
+// resolveDeferredRemote fills in the repo ID and remote URL of an entry that
+// scan --register-only recorded without reading remotes (a local:<path> ID and
+// no remote URL), once an inspection of the same path found a primary remote.
+// The caller holds registryMu.
+func (e *Engine) resolveDeferredRemote(status model.RepoStatus) {
+	remoteURL := primaryRemoteURL(status)
+	if status.Error != "" || remoteURL == "" || strings.HasPrefix(status.RepoID, "local:") {
+		return
+	}
+	placeholder := "local:" + filepath.ToSlash(status.Path)
+	for i := range e.registry.Entries {
+		entry := &e.registry.Entries[i]
+		if entry.RepoID != placeholder || strings.TrimSpace(entry.RemoteURL) != "" || entry.Path != status.Path {
+			continue
+		}
+		entry.RepoID = status.RepoID
+		entry.RemoteURL = remoteURL
+		return
+	}
+}
+
 func (e *Engine) buildStatusReport(results []model.RepoStatus) *model.StatusReport {
 	sortRepoStatuses(results)
 	return &model.StatusReport{
@@ -427,8 +804,12 @@ func (e *Engine) buildStatusReport(results []model.RepoStatus) *model.StatusRepo
 
 // SyncOptions configures a sync operation.
 type SyncOptions struct {
-	Filter               FilterKind
-	Concurrency          int
+	Filter      FilterKind
+	Concurrency int
+	// PerHostConcurrency caps the workers syncing repos on one Git host (the
+	// host of the normalized remote URL) at a time, within Concurrency. 0 or
+	// less means no per-host cap. Local remotes are never capped.
+	PerHostConcurrency   int
 	Timeout              int // seconds per repo
 	ContinueOnError      bool
 	DryRun               bool
@@ -439,8 +820,73 @@ type SyncOptions struct {
 	ProtectedBranches    []string
 	AllowProtectedRebase bool
 	CheckoutMissing      bool
+	// CloneDepth, when positive, makes checkout-missing clones shallow: only
+	// that many commits of the entry's branch are cloned. Mirror clones
+	// always copy full history.
+	CloneDepth int
+	// RemoteTemplate rebuilds the clone URL for a missing entry with no
+	// remote_url from its repo ID; see remoteURLFromTemplate.
+	RemoteTemplate string
+	// RetryAttempts is how many extra times a fetch or clone is retried after a
+	// network or timeout failure.
+	RetryAttempts int
+	// RetryBackoff is the wait before the first retry; it doubles on each retry.
+	RetryBackoff time.Duration
+	// AllowOversubscribe disables clamping Concurrency to the CPU ceiling.
+	AllowOversubscribe bool
+	// MaxJobs is the --jobs cap on worker count; see Engine.maxJobs.
+	MaxJobs int
+	// AutostashAll stashes a dirty worktree (including untracked files) before
+	// any sync work on the repo and pops it afterwards, independent of
+	// RebaseDirty. A dirty worktree then no longer blocks a local update.
+	AutostashAll bool
+	// LFS runs git lfs fetch after the rest of the sync for repos whose
+	// .gitattributes route paths through the lfs filter. Repos are only probed
+	// for LFS when it is set.
+	LFS bool
+	// UpdateSubmodules runs git submodule update --init --recursive after a
+	// successful pull --rebase in repos with submodules, so their working trees
+	// follow the new superproject commit.
+	UpdateSubmodules bool
+	// Deepen, when positive, fetches shallow repos with --deepen so each sync
+	// backfills that many commits of history. Full clones fetch normally.
+	Deepen int
+	// FetchRemote, when set, fetches only this named remote instead of --all.
+	// Repos without a remote of that name are skipped.
+	FetchRemote string
+	// Paths, when non-empty, limits sync to registry entries recorded at one of
+	// these paths. Filter still applies to the entries that remain.
+	Paths []string
+	// NoPruneTags drops --prune-tags from the fetch so local tags missing on
+	// the remote are kept.
+	NoPruneTags bool
+	// BackupBranch, when set, is a branch name template. Before rebasing a
+	// diverged branch, sync creates a local branch from it at the pre-rebase
+	// tip. {branch} expands to the current branch and {timestamp} to the UTC
+	// time the plan was built.
+	BackupBranch string
+	// ResetHard, after the fetch, moves the checked-out branch to its upstream
+	// with git reset --hard and removes every untracked and ignored file with
+	// git clean -fdx. It is meant for throwaway clones and cannot be combined
+	// with UpdateLocal or AutostashAll. Bare, mirror, detached, and
+	// untracked repos are skipped, and so are repos on a branch matching
+	// ProtectedBranches, regardless of AllowProtectedRebase.
+	ResetHard bool
+	// PostSync, when set, runs after each planned repo that executed
+	// successfully and was not skipped; see applyPostSyncHook.
+	PostSync PostSyncHook
+	// StrictHooks fails a repo whose PostSync hook returns an error instead
+	// of recording the error as a warning.
+	StrictHooks bool
+	// CaptureOutput records every command an executed repo runs, with its raw
+	// output, in SyncResult.RawOutput, on both the Sync and the
+	// ExecuteSyncPlan paths. Dry runs execute nothing and capture nothing.
+	CaptureOutput bool
 }
 
+// PostSyncHook is called with a repo's executed sync result.
+type PostSyncHook func(ctx context.Context, res SyncResult) error
+
 // SyncResult records the outcome for a single repo sync.
 type SyncResult struct {
 	// RepoID is the stable repository identity from the registry/status model.
@@ -463,6 +909,45 @@ type SyncResult struct {
 	SkipReason string
 	// RemoteTrackingRefs describes refs that the planned fetch would prune.
 	RemoteTrackingRefs model.RemoteTrackingRefStatus
+	// Attempts is how many times the fetch or clone ran, including retries.
+	// Zero means no fetch or clone was attempted.
+	Attempts int
+	// Deepen is the --deepen depth the fetch step uses for a shallow repo; zero
+	// means a plain fetch.
+	Deepen int
+	// FetchRemote is the single remote the fetch step targets; empty means --all.
+	FetchRemote string
+	// NoPruneTags records that the fetch step runs without --prune-tags.
+	NoPruneTags bool
+	// TagsPruned is how many local tags the executed fetch deleted.
+	TagsPruned int
+	// BackupBranch is the local branch created at the pre-rebase tip, or the
+	// one the plan will create. Empty when no backup applies.
+	BackupBranch string
+	// ResetTarget is the upstream ref a --reset-hard plan resets the branch
+	// to. Empty when no reset applies.
+	ResetTarget string
+	// CloneURL is the URL a checkout-missing clone uses when the registry
+	// entry has none, rebuilt from RemoteTemplate. It is saved to the entry
+	// once the clone succeeds.
+	CloneURL string
+	// CloneDepth is the --depth a checkout-missing clone uses; zero means a
+	// full clone.
+	CloneDepth int
+	// Autostashed records that AutostashAll created a stash for this repo.
+	Autostashed bool
+	// AutostashRestored records that the autostash was popped again.
+	AutostashRestored bool
+	// Warning describes a problem that did not fail the repo, such as an
+	// autostash left in place because its pop failed.
+	Warning string
+	// Duration is the wall-clock time spent running this repo's VCS
+	// operations. Zero for plan entries and results that ran nothing.
+	Duration time.Duration
+	// RawOutput is the transcript of the commands this repo ran: each command
+	// line followed by its untrimmed stdout and stderr. Only populated with
+	// SyncOptions.CaptureOutput.
+	RawOutput string
 	// steps is the ordered list of typed VCS operations an executor performs for
 	// this planned item. Execution dispatches on these steps rather than parsing
 	// the human-readable Action string, so non-git backends and skip-with-fetch
@@ -476,18 +961,52 @@ type SyncResult struct {
 type syncStep string
 
 const (
-	syncStepClone      syncStep = "clone"
-	syncStepFetch      syncStep = "fetch"
-	syncStepStashPush  syncStep = "stash_push"
-	syncStepPullRebase syncStep = "pull_rebase"
-	syncStepStashPop   syncStep = "stash_pop"
-	syncStepPush       syncStep = "push"
+	syncStepClone        syncStep = "clone"
+	syncStepFetch        syncStep = "fetch"
+	syncStepBackupBranch syncStep = "backup_branch"
+	syncStepStashPush    syncStep = "stash_push"
+	syncStepPullRebase   syncStep = "pull_rebase"
+	syncStepStashPop     syncStep = "stash_pop"
+	syncStepPush         syncStep = "push"
+	// syncStepAutostash and syncStepAutostashPop wrap every other step when
+	// AutostashAll applies to a dirty repo.
+	syncStepAutostash    syncStep = "autostash"
+	syncStepAutostashPop syncStep = "autostash_pop"
+	syncStepLFSFetch     syncStep = "lfs_fetch"
+	// syncStepSubmoduleUpdate follows the pull --rebase with UpdateSubmodules.
+	syncStepSubmoduleUpdate syncStep = "submodule_update"
+	// syncStepMirrorUpdate replaces fetch (and everything after it) for
+	// --mirror clones.
+	syncStepMirrorUpdate syncStep = "mirror_update"
+	// syncStepResetHard and syncStepCleanAll follow the fetch for ResetHard.
+	syncStepResetHard syncStep = "reset_hard"
+	syncStepCleanAll  syncStep = "clean_all"
 )
 
+// mirrorUpdateAction is the display form of the mirror refresh.
+const mirrorUpdateAction = "git remote update --prune"
+
+// lfsFetchAction is the display form of the LFS object download.
+const lfsFetchAction = "git lfs fetch"
+
+// submoduleUpdateAction is the display form of the submodule update.
+const submoduleUpdateAction = "git submodule update --init --recursive"
+
+// cleanAllAction is the display form of the untracked file removal that
+// follows a reset to upstream.
+const cleanAllAction = "git clean -fdx"
+
+// pullRebaseAction is the display form of the pull --rebase local update.
+const pullRebaseAction = "git pull --rebase --no-recurse-submodules"
+
 // preRebaseStashMessage is the stash message used when auto-stashing a dirty
-// worktree before a pull --rebase during local update.
+// worktree before a pull --rebase during local update and the config does not
+// set defaults.stash_message.
 const preRebaseStashMessage = "repokeeper: pre-rebase stash"
 
+// autostashMessage labels the stash sync --autostash-all creates.
+const autostashMessage = "repokeeper: autostash"
+
 // SyncResultCallback is invoked for each sync result as it is produced.
 // Callbacks run on the coordinator goroutine, so callers can safely write
 // terminal output without additional synchronization.
@@ -505,6 +1024,8 @@ const (
 	SyncOutcomeCheckoutMissing       OutcomeKind = "checkout_missing"
 	SyncOutcomeFailedFetch           OutcomeKind = "failed_fetch"
 	SyncOutcomeFetched               OutcomeKind = "fetched"
+	SyncOutcomeMirrorUpdated         OutcomeKind = "mirror_updated"
+	SyncOutcomeFailedBackupBranch    OutcomeKind = "failed_backup_branch"
 	SyncOutcomeFailedStash           OutcomeKind = "failed_stash"
 	SyncOutcomeFailedRebase          OutcomeKind = "failed_rebase"
 	SyncOutcomeFailedStashPop        OutcomeKind = "failed_stash_pop"
@@ -520,6 +1041,11 @@ const (
 	SyncOutcomeRebased               OutcomeKind = "rebased"
 	SyncOutcomeStashedRebased        OutcomeKind = "stashed_rebased"
 	SyncOutcomeFailedInspect         OutcomeKind = "failed_inspect"
+	SyncOutcomeFailedLFS             OutcomeKind = "failed_lfs"
+	SyncOutcomeFailedSubmodules      OutcomeKind = "failed_submodules"
+	SyncOutcomeResetHard             OutcomeKind = "reset_hard"
+	SyncOutcomeFailedReset           OutcomeKind = "failed_reset"
+	SyncOutcomeFailedHook            OutcomeKind = "failed_hook"
 
 	// Deprecated: use SyncResult.Planned instead of Error == SyncErrorDryRun.
 	SyncErrorDryRun                   = "dry-run"
@@ -528,16 +1054,24 @@ const (
 	SyncErrorSkippedNoUpstream        = "skipped-no-upstream"
 	SyncErrorMissingRemoteForCheckout = "missing remote_url for checkout"
 	SyncErrorSkippedLocalUpdatePrefix = "skipped-local-update: "
+	SyncErrorSkippedNoRemotePrefix    = "skipped-no-remote: "
 	SyncErrorFetchFailed              = "sync-fetch-failed"
 	SyncErrorFetchAuth                = "sync-fetch-auth"
+	SyncErrorFetchHostKey             = "sync-fetch-host-key"
 	SyncErrorFetchNetwork             = "sync-fetch-network"
 	SyncErrorFetchTimeout             = "sync-fetch-timeout"
 	SyncErrorFetchCorrupt             = "sync-fetch-corrupt"
 	SyncErrorFetchMissingRemote       = "sync-fetch-missing-remote"
+	SyncErrorFetchDiskFull            = "sync-fetch-disk-full"
+	SyncErrorFetchFSPermission        = "sync-fetch-fs-permission"
+	SyncErrorLFSFetchFailed           = "sync-lfs-fetch-failed"
+	SyncErrorSubmoduleUpdateFailed    = "sync-submodule-update-failed"
+	SyncErrorPostSyncHookFailed       = "sync-post-sync-hook-failed"
 
 	// Skip reasons for pull/rebase policy checks
 	SyncReasonUnknownStatus               = "unknown status"
 	SyncReasonBareRepository              = "bare repository"
+	SyncReasonOperationInProgress         = "operation in progress"
 	SyncReasonDetachedHead                = "detached HEAD"
 	SyncReasonDirtyStateUnknown           = "dirty state unknown"
 	SyncReasonDirtyWorkingTree            = "dirty working tree"
@@ -545,6 +1079,7 @@ const (
 	SyncReasonBranchNotTrackingUpstream   = "branch is not tracking an upstream"
 	SyncReasonBranchHasLocalCommitsToPush = "branch has local commits to push"
 	SyncReasonAlreadyUpToDate             = "already up to date"
+	SyncReasonMirrorRepository            = "mirror repository"
 )
 
 // ExecuteSyncPlanWithCallbacks executes a planned sync and invokes onStart
@@ -578,7 +1113,8 @@ func (e *Engine) executeSyncPlanSequential(ctx context.Context, plan []SyncResul
 			continue
 		}
 
-		executed := e.executePlannedSyncItem(ctx, item)
+		executed := e.executeCapturedSyncItem(ctx, item, opts, syncRetryPolicyFor(opts))
+		executed = e.applyPostSyncHook(ctx, executed, opts)
 		e.logSyncFailureHint(executed)
 		results = append(results, executed)
 		if onComplete != nil {
@@ -595,12 +1131,19 @@ func (e *Engine) executeSyncPlanSequential(ctx context.Context, plan []SyncResul
 
 func (e *Engine) executeSyncPlanConcurrent(ctx context.Context, plan []SyncResult, opts SyncOptions, onStart SyncStartCallback, onComplete SyncResultCallback) []SyncResult {
 	concurrency, timeoutSeconds := e.syncRuntime(opts)
+	retry := syncRetryPolicyFor(opts)
 	sem := make(chan struct{}, concurrency)
+	hosts := newHostLimiter(opts.PerHostConcurrency)
+	limits := e.syncItemLimits(plan, timeoutSeconds, hosts != nil)
+	if hosts != nil {
+		limits = interleaveByHost(limits, func(limit syncItemLimit) string { return limit.host })
+	}
 	out := make(chan SyncResult, workerChannelBufferSize(len(plan), concurrency))
 	spawned := 0
 	results := make([]SyncResult, 0, len(plan))
 
-	for _, item := range plan {
+	for _, limit := range limits {
+		item := limit.item
 		if onStart != nil {
 			onStart(item)
 		}
@@ -612,22 +1155,27 @@ func (e *Engine) executeSyncPlanConcurrent(ctx context.Context, plan []SyncResul
 			}
 			continue
 		}
+		// Take the host slot before the global one so a global slot never
+		// sits idle while the repo waits for its host.
+		releaseHost := hosts.acquire(limit.host)
 		sem <- struct{}{}
 		spawned++
-		go func(item SyncResult) {
+		go func(item SyncResult, timeoutSeconds int) {
 			repoCtx := ctx
 			var cancel context.CancelFunc
 			if timeoutSeconds > 0 {
 				repoCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
 			}
-			res := e.executePlannedSyncItem(repoCtx, item)
+			res := e.executeCapturedSyncItem(repoCtx, item, opts, retry)
 			if cancel != nil {
 				cancel()
 			}
+			res = e.applyPostSyncHook(ctx, res, opts)
 			e.logSyncFailureHint(res)
 			<-sem
+			releaseHost()
 			out <- res
-		}(item)
+		}(item, limit.timeoutSeconds)
 	}
 
 	for i := 0; i < spawned; i++ {
@@ -641,11 +1189,52 @@ func (e *Engine) executeSyncPlanConcurrent(ctx context.Context, plan []SyncResul
 	return results
 }
 
+// applyPostSyncHook runs opts.PostSync for an executed repo that succeeded
+// without being skipped. A hook error becomes a warning on the result, or a
+// failed_hook failure with opts.StrictHooks. The hook is not bound by the
+// per-repo timeout, which covers only the VCS work.
+func (e *Engine) applyPostSyncHook(ctx context.Context, res SyncResult, opts SyncOptions) SyncResult {
+	if opts.PostSync == nil || !res.OK || res.Outcome == SyncOutcomeSkippedLocalUpdate || res.ErrorClass == "skipped" {
+		return res
+	}
+	err := opts.PostSync(ctx, res)
+	if err == nil {
+		return res
+	}
+	if opts.StrictHooks {
+		res.OK = false
+		res.Outcome = SyncOutcomeFailedHook
+		res.ErrorClass = "hook"
+		res.Error = SyncErrorPostSyncHookFailed + ": " + err.Error()
+		return res
+	}
+	warning := "post-sync hook failed: " + err.Error()
+	if res.Warning != "" {
+		warning = res.Warning + "; " + warning
+	}
+	res.Warning = warning
+	e.logger.Warnf("%s: %s", res.Path, warning)
+	return res
+}
+
 func shouldStopSyncExecution(result SyncResult, opts SyncOptions) bool {
 	return !result.OK && !opts.ContinueOnError
 }
 
-func (e *Engine) executePlannedSyncItem(ctx context.Context, item SyncResult) SyncResult {
+// executeCapturedSyncItem runs executePlannedSyncItem, collecting the raw
+// command output into RawOutput when opts.CaptureOutput is set. Each repo gets
+// its own buffer, and its steps run one at a time, so no locking is needed.
+func (e *Engine) executeCapturedSyncItem(ctx context.Context, item SyncResult, opts SyncOptions, retry syncRetryPolicy) SyncResult {
+	if !opts.CaptureOutput {
+		return e.executePlannedSyncItem(ctx, item, retry)
+	}
+	var output strings.Builder
+	res := e.executePlannedSyncItem(vcs.WithCommandOutput(ctx, &output), item, retry)
+	res.RawOutput = output.String()
+	return res
+}
+
+func (e *Engine) executePlannedSyncItem(ctx context.Context, item SyncResult, retry syncRetryPolicy) SyncResult {
 	executed := item
 	executed.Error = ""
 	executed.ErrorClass = ""
@@ -662,10 +1251,14 @@ func (e *Engine) executePlannedSyncItem(ctx context.Context, item SyncResult) Sy
 		return executed
 	}
 
+	start := time.Now()
 	if item.steps[0] == syncStepClone {
-		return e.executePlannedClone(ctx, executed)
+		result := e.executePlannedClone(ctx, executed, retry)
+		result.Duration = time.Since(start)
+		return result
 	}
-	result := e.executePlannedNonClone(ctx, executed)
+	result := e.executePlannedNonClone(ctx, executed, retry)
+	result.Duration = time.Since(start)
 	// A successfully executed skip-local-update item is still a skip: its fetch
 	// step ran, but no local update was applied. Restore the planner's
 	// user-facing skip message/class that we cleared above so the sync table's
@@ -678,8 +1271,16 @@ func (e *Engine) executePlannedSyncItem(ctx context.Context, item SyncResult) Sy
 	return result
 }
 
-func (e *Engine) executePlannedClone(ctx context.Context, executed SyncResult) SyncResult {
-	entry := findRegistryEntryForSyncResult(e.registry, executed)
+func (e *Engine) executePlannedClone(ctx context.Context, executed SyncResult, retry syncRetryPolicy) SyncResult {
+	// Copy the entry under the lock: other clone workers replace entries
+	// concurrently.
+	e.registryMu.Lock()
+	var entry *registry.Entry
+	if found := findRegistryEntryForSyncResult(e.registry, executed); found != nil {
+		copied := *found
+		entry = &copied
+	}
+	e.registryMu.Unlock()
 	if entry == nil {
 		executed.OK = false
 		executed.Outcome = SyncOutcomeFailedInvalid
@@ -687,7 +1288,15 @@ func (e *Engine) executePlannedClone(ctx context.Context, executed SyncResult) S
 		executed.ErrorClass = "invalid"
 		return executed
 	}
-	if err := e.adapter.Clone(ctx, strings.TrimSpace(entry.RemoteURL), entry.Path, strings.TrimSpace(entry.Branch), entry.Type == "mirror"); err != nil {
+	remoteURL := strings.TrimSpace(entry.RemoteURL)
+	if remoteURL == "" {
+		remoteURL = executed.CloneURL
+	}
+	attempts, err := e.withRetry(ctx, retry, func() error {
+		return e.cloneRepo(ctx, remoteURL, entry.Path, strings.TrimSpace(entry.Branch), entry.Type == "mirror", executed.CloneDepth)
+	})
+	executed.Attempts = attempts
+	if err != nil {
 		executed.OK = false
 		executed.Outcome = SyncOutcomeFailedCheckoutMissing
 		executed.Error = err.Error()
@@ -696,22 +1305,50 @@ func (e *Engine) executePlannedClone(ctx context.Context, executed SyncResult) S
 	}
 	executed.OK = true
 	executed.Outcome = SyncOutcomeCheckoutMissing
+	entry.RemoteURL = remoteURL
 	entry.Status = registry.StatusPresent
 	entry.LastSeen = time.Now()
 	e.replaceRegistryEntry(*entry)
 	return executed
 }
 
-func (e *Engine) executePlannedNonClone(ctx context.Context, executed SyncResult) SyncResult {
+func (e *Engine) executePlannedNonClone(ctx context.Context, executed SyncResult, retry syncRetryPolicy) SyncResult {
+	executed = e.executePlannedSteps(ctx, executed, retry)
+	if executed.Autostashed {
+		executed = e.restoreAutostash(ctx, executed)
+	}
+	return executed
+}
+
+func (e *Engine) executePlannedSteps(ctx context.Context, executed SyncResult, retry syncRetryPolicy) SyncResult {
 	stashed := false
 	for _, step := range executed.steps {
 		switch step {
 		case syncStepFetch:
-			if err := e.adapter.Fetch(ctx, executed.Path); err != nil {
+			attempts, err := e.withRetry(ctx, retry, func() error {
+				tagsPruned, fetchErr := e.syncFetch(ctx, executed.Path, executed.FetchRemote, executed.Deepen, executed.NoPruneTags)
+				executed.TagsPruned = tagsPruned
+				return fetchErr
+			})
+			executed.Attempts = attempts
+			if err != nil {
 				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedFetch, err)
 			}
+		case syncStepBackupBranch:
+			if err := e.createBackupBranch(ctx, executed.Path, executed.BackupBranch); err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedBackupBranch, err)
+			}
+		case syncStepAutostash:
+			created, err := e.adapter.StashPush(ctx, executed.Path, autostashMessage)
+			if err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedStash, err)
+			}
+			executed.Autostashed = created
+		case syncStepAutostashPop:
+			// executePlannedNonClone pops the autostash once the steps have
+			// run, including after a failed fetch or push.
 		case syncStepStashPush:
-			created, err := e.adapter.StashPush(ctx, executed.Path, preRebaseStashMessage)
+			created, err := e.adapter.StashPush(ctx, executed.Path, e.stashMessage())
 			if err != nil {
 				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedStash, err)
 			}
@@ -732,6 +1369,30 @@ func (e *Engine) executePlannedNonClone(ctx context.Context, executed SyncResult
 			if err := e.adapter.Push(ctx, executed.Path); err != nil {
 				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedPush, err)
 			}
+		case syncStepLFSFetch:
+			if err := e.lfsFetch(ctx, executed.Path); err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedLFS, err)
+			}
+		case syncStepSubmoduleUpdate:
+			if err := e.submoduleUpdate(ctx, executed.Path); err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedSubmodules, err)
+			}
+		case syncStepMirrorUpdate:
+			attempts, err := e.withRetry(ctx, retry, func() error {
+				return e.mirrorUpdate(ctx, executed.Path)
+			})
+			executed.Attempts = attempts
+			if err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedFetch, err)
+			}
+		case syncStepResetHard:
+			if err := e.resetHardTo(ctx, executed.Path, executed.ResetTarget); err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedReset, err)
+			}
+		case syncStepCleanAll:
+			if err := e.cleanAll(ctx, executed.Path); err != nil {
+				return e.failedPlannedSyncResult(executed, SyncOutcomeFailedReset, err)
+			}
 		default:
 			// An unrecognized step means a corrupt plan or a new step type added
 			// without executor support. Fail fast rather than silently skipping
@@ -762,15 +1423,188 @@ func executedNonCloneOutcome(item SyncResult, stashed bool) OutcomeKind {
 		switch s {
 		case syncStepFetch:
 			outcome = SyncOutcomeFetched
+		case syncStepMirrorUpdate:
+			outcome = SyncOutcomeMirrorUpdated
 		case syncStepPullRebase:
 			outcome = outcomeForRebase(stashed)
 		case syncStepPush:
 			outcome = SyncOutcomePushed
+		case syncStepResetHard:
+			outcome = SyncOutcomeResetHard
 		}
 	}
 	return outcome
 }
 
+// restoreAutostash pops the --autostash-all stash after the repo's sync work.
+// A failed rebase can leave the worktree mid-rebase, so the stash is left alone
+// then, and a failed pop leaves it too. In both cases the result keeps its
+// outcome and carries a warning that the changes are still stashed.
+func (e *Engine) restoreAutostash(ctx context.Context, res SyncResult) SyncResult {
+	if res.Outcome == SyncOutcomeFailedRebase {
+		res.Warning = "autostash left in place after the failed rebase; run git stash pop once the rebase is resolved"
+	} else if err := e.adapter.StashPop(ctx, res.Path); err != nil {
+		res.Warning = fmt.Sprintf("autostash pop failed; changes are still in the stash %q: %v", autostashMessage, err)
+	} else {
+		res.AutostashRestored = true
+		return res
+	}
+	e.logger.Warnf("%s: %s", res.Path, res.Warning)
+	return res
+}
+
+// withLFSFetchStep appends the git lfs fetch step to a planned result.
+func withLFSFetchStep(res SyncResult) SyncResult {
+	if !res.Planned || len(res.steps) == 0 {
+		return res
+	}
+	res.steps = append(res.steps, syncStepLFSFetch)
+	res.Action += " && " + lfsFetchAction
+	return res
+}
+
+// detectLFS probes dir for LFS attributes and records the answer on status
+// when one is given. Adapters without LFS support, and failed probes, report
+// false.
+func (e *Engine) detectLFS(ctx context.Context, dir string, status *model.RepoStatus) bool {
+	fetcher, ok := e.adapter.(vcs.LFSFetcher)
+	if !ok {
+		return false
+	}
+	hasLFS, err := fetcher.HasLFS(ctx, dir)
+	if err != nil {
+		e.logger.Warnf("HasLFS check failed for %s: %v", dir, err)
+		return false
+	}
+	if status != nil {
+		status.HasLFS = hasLFS
+	}
+	return hasLFS
+}
+
+// mirrorUpdate refreshes a --mirror clone, using the adapter's mirror support
+// when it has any and a plain fetch otherwise.
+func (e *Engine) mirrorUpdate(ctx context.Context, dir string) error {
+	if updater, ok := e.adapter.(vcs.MirrorUpdater); ok {
+		return updater.MirrorUpdate(ctx, dir)
+	}
+	return e.adapter.Fetch(ctx, dir)
+}
+
+// planMirrorUpdate plans the sync of a mirror entry. Mirrors have no worktree
+// or upstream branch, so local update, autostash, LFS, deepen, and
+// --fetch-remote do not apply.
+func planMirrorUpdate(entry registry.Entry) SyncResult {
+	return SyncResult{
+		RepoID:  entry.RepoID,
+		Path:    entry.Path,
+		Outcome: SyncOutcomePlannedFetch,
+		OK:      true,
+		Error:   SyncErrorDryRun,
+		Action:  mirrorUpdateAction,
+		Planned: true,
+		steps:   []syncStep{syncStepMirrorUpdate},
+	}
+}
+
+// runMirrorUpdate is runSyncApply for a mirror entry.
+func (e *Engine) runMirrorUpdate(ctx context.Context, entry registry.Entry, opts SyncOptions) SyncResult {
+	attempts, err := e.withRetry(ctx, syncRetryPolicyFor(opts), func() error {
+		return e.mirrorUpdate(ctx, entry.Path)
+	})
+	if err != nil {
+		class := e.classifier.ClassifyError(err)
+		return SyncResult{
+			RepoID:     entry.RepoID,
+			Path:       entry.Path,
+			Outcome:    SyncOutcomeFailedFetch,
+			OK:         false,
+			Error:      syncFailureMessage(SyncOutcomeFailedFetch, class, err),
+			ErrorClass: class,
+			Action:     mirrorUpdateAction,
+			Attempts:   attempts,
+		}
+	}
+	return SyncResult{
+		RepoID:   entry.RepoID,
+		Path:     entry.Path,
+		Outcome:  SyncOutcomeMirrorUpdated,
+		OK:       true,
+		Action:   mirrorUpdateAction,
+		Attempts: attempts,
+	}
+}
+
+// lfsFetch downloads LFS objects through the adapter, failing when the
+// backend has no LFS support.
+func (e *Engine) lfsFetch(ctx context.Context, dir string) error {
+	fetcher, ok := e.adapter.(vcs.LFSFetcher)
+	if !ok {
+		return fmt.Errorf("%s does not support git lfs", e.adapter.Name())
+	}
+	return fetcher.LFSFetch(ctx, dir)
+}
+
+// applyLFSFetch runs git lfs fetch after a successful direct sync of an LFS
+// repo.
+func (e *Engine) applyLFSFetch(ctx context.Context, entry registry.Entry, res SyncResult) SyncResult {
+	if !res.OK || !e.detectLFS(ctx, entry.Path, nil) {
+		return res
+	}
+	if res.Action != "" {
+		res.Action += " && " + lfsFetchAction
+	} else {
+		res.Action = lfsFetchAction
+	}
+	if err := e.lfsFetch(ctx, entry.Path); err != nil {
+		res.OK = false
+		res.Outcome = SyncOutcomeFailedLFS
+		res.ErrorClass = e.classifier.ClassifyError(err)
+		res.Error = syncFailureMessage(SyncOutcomeFailedLFS, res.ErrorClass, err)
+	}
+	return res
+}
+
+// submoduleUpdate updates submodule working trees through the adapter,
+// failing when the backend has no submodule support.
+func (e *Engine) submoduleUpdate(ctx context.Context, dir string) error {
+	updater, ok := e.adapter.(vcs.SubmoduleUpdater)
+	if !ok {
+		return fmt.Errorf("%s does not support submodules", e.adapter.Name())
+	}
+	return updater.SubmoduleUpdate(ctx, dir)
+}
+
+// applySubmoduleUpdate runs the submodule update after a successful direct
+// pull --rebase. Skipped and failed rebases leave the submodules alone.
+func (e *Engine) applySubmoduleUpdate(ctx context.Context, entry registry.Entry, res SyncResult) SyncResult {
+	if !res.OK || (res.Outcome != SyncOutcomeRebased && res.Outcome != SyncOutcomeStashedRebased) {
+		return res
+	}
+	res.Action += " && " + submoduleUpdateAction
+	if err := e.submoduleUpdate(ctx, entry.Path); err != nil {
+		res.OK = false
+		res.Outcome = SyncOutcomeFailedSubmodules
+		res.ErrorClass = e.classifier.ClassifyError(err)
+		res.Error = syncFailureMessage(SyncOutcomeFailedSubmodules, res.ErrorClass, err)
+	}
+	return res
+}
+
+// withAutostashSteps wraps a planned result's steps and action in the
+// --autostash-all stash push and pop.
+func withAutostashSteps(res SyncResult) SyncResult {
+	if !res.Planned || len(res.steps) == 0 {
+		return res
+	}
+	steps := make([]syncStep, 0, len(res.steps)+2)
+	steps = append(steps, syncStepAutostash)
+	steps = append(steps, res.steps...)
+	res.steps = append(steps, syncStepAutostashPop)
+	res.Action = stashPushAction(autostashMessage) + " && " + res.Action + " && git stash pop"
+	return res
+}
+
 func (e *Engine) failedPlannedSyncResult(executed SyncResult, outcome OutcomeKind, err error) SyncResult {
 	executed.OK = false
 	executed.Outcome = outcome
@@ -784,6 +1618,8 @@ func syncFailureMessage(outcome OutcomeKind, class string, err error) string {
 		switch class {
 		case "auth":
 			return SyncErrorFetchAuth
+		case "host_key":
+			return SyncErrorFetchHostKey
 		case "network":
 			return SyncErrorFetchNetwork
 		case "timeout":
@@ -792,10 +1628,20 @@ func syncFailureMessage(outcome OutcomeKind, class string, err error) string {
 			return SyncErrorFetchCorrupt
 		case "missing_remote":
 			return SyncErrorFetchMissingRemote
+		case "disk_full":
+			return SyncErrorFetchDiskFull
+		case "fs_permission":
+			return SyncErrorFetchFSPermission
 		default:
 			return SyncErrorFetchFailed
 		}
 	}
+	if outcome == SyncOutcomeFailedLFS {
+		return SyncErrorLFSFetchFailed + ": " + err.Error()
+	}
+	if outcome == SyncOutcomeFailedSubmodules {
+		return SyncErrorSubmoduleUpdateFailed + ": " + err.Error()
+	}
 	return err.Error()
 }
 
@@ -813,14 +1659,21 @@ func supportsLocalUpdate(ctx context.Context, adapter vcs.Adapter, dir string) (
 	return capable.SupportsLocalUpdate(ctx, dir)
 }
 
-func syncFetchAction(ctx context.Context, adapter vcs.Adapter, dir string) string {
+func syncFetchAction(ctx context.Context, adapter vcs.Adapter, dir, remote string, pruneTags bool) string {
+	if remote != "" {
+		return vcs.GitFetchAction(remote, pruneTags)
+	}
 	provider, ok := adapter.(localUpdateCapable)
 	if !ok {
-		return "git fetch --all --prune --prune-tags --no-recurse-submodules"
+		return vcs.GitFetchAction("", pruneTags)
 	}
 	action, err := provider.FetchAction(ctx, dir)
 	if err != nil || strings.TrimSpace(action) == "" {
-		return "git fetch --all --prune --prune-tags --no-recurse-submodules"
+		return vcs.GitFetchAction("", pruneTags)
+	}
+	if !pruneTags {
+		// Adapters describe their default fetch, which prunes tags.
+		action = strings.Replace(action, " --prune-tags", "", 1)
 	}
 	return action
 }
@@ -830,6 +1683,9 @@ func (e *Engine) Sync(ctx context.Context, opts SyncOptions) ([]SyncResult, erro
 	if e.registry == nil {
 		return nil, errors.New("registry not loaded")
 	}
+	if opts.ResetHard && (opts.UpdateLocal || opts.AutostashAll) {
+		return nil, errors.New("reset hard cannot be combined with a local update or autostash")
+	}
 
 	concurrency, timeoutSeconds := e.syncRuntime(opts)
 	// Snapshot entries so concurrent sync workers do not race on shared slices.
@@ -839,6 +1695,12 @@ func (e *Engine) Sync(ctx context.Context, opts SyncOptions) ([]SyncResult, erro
 	}
 
 	sem := make(chan struct{}, concurrency)
+	hosts := newHostLimiter(opts.PerHostConcurrency)
+	if hosts != nil {
+		entries = interleaveByHost(entries, func(entry registry.Entry) string {
+			return e.syncHost(entry.RepoID, entry.RemoteURL)
+		})
+	}
 	out := make(chan SyncResult, workerChannelBufferSize(len(entries), concurrency))
 	spawned := 0
 	results := make([]SyncResult, 0, len(entries))
@@ -851,11 +1713,15 @@ func (e *Engine) Sync(ctx context.Context, opts SyncOptions) ([]SyncResult, erro
 		if !queue {
 			continue
 		}
+		// Take the host slot before the global one so a global slot never
+		// sits idle while the repo waits for its host.
+		releaseHost := hosts.acquire(e.syncHost(entry.RepoID, entry.RemoteURL))
 		sem <- struct{}{}
 		spawned++
 		go func(entry registry.Entry, cached *model.RepoStatus) {
 			res := e.runSyncEntry(ctx, entry, opts, timeoutSeconds, cached)
 			<-sem
+			releaseHost()
 			out <- res
 		}(entry, cached)
 	}
@@ -881,20 +1747,26 @@ func workerChannelBufferSize(entryCount, concurrency int) int {
 	return min(entryCount, cap)
 }
 
+// syncRuntime resolves the worker count and per-repo timeout for a sync run
+// with the same precedence as statusLimits: opts.Concurrency, then
+// defaults.concurrency, capped by capJobs and the CPU ceiling.
 func (e *Engine) syncRuntime(opts SyncOptions) (int, int) {
 	defaults := config.DefaultConfig().Defaults
 
 	concurrency := opts.Concurrency
+	builtin := false
 	if concurrency <= 0 {
 		if e.cfg != nil && e.cfg.Defaults.Concurrency > 0 {
 			concurrency = e.cfg.Defaults.Concurrency
 		} else {
 			concurrency = defaults.Concurrency
+			builtin = true
 		}
 		if concurrency <= 0 {
 			concurrency = 4
 		}
 	}
+	concurrency = e.effectiveConcurrency(e.capJobs(concurrency, builtin, opts.MaxJobs), opts.AllowOversubscribe)
 	timeoutSeconds := opts.Timeout
 	if timeoutSeconds <= 0 {
 		if e.cfg != nil && e.cfg.Defaults.TimeoutSeconds > 0 {
@@ -906,6 +1778,70 @@ func (e *Engine) syncRuntime(opts SyncOptions) (int, int) {
 	return concurrency, timeoutSeconds
 }
 
+// repoTimeout resolves the timeout for one repo. The entry's own
+// timeout_seconds wins over timeoutSeconds, the run-wide value already
+// resolved from options and then config.
+func repoTimeout(entry registry.Entry, timeoutSeconds int) int {
+	if entry.TimeoutSeconds > 0 {
+		return entry.TimeoutSeconds
+	}
+	return timeoutSeconds
+}
+
+// maxJobs resolves the global worker cap that scan, sync, and status clamp
+// their concurrency to: the --jobs flag (requested), then defaults.max_jobs in
+// config. It returns 0 when neither is set.
+func (e *Engine) maxJobs(requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if e.cfg != nil && e.cfg.Defaults.MaxJobs > 0 {
+		return e.cfg.Defaults.MaxJobs
+	}
+	return 0
+}
+
+// capJobs applies the job cap to a resolved worker count. A --jobs or
+// defaults.max_jobs cap applies to any count. Without one, only the built-in
+// default (builtin) is capped, at min(8, NumCPU); a count the user asked for
+// is left to the 8x NumCPU ceiling, which --allow-oversubscribe lifts.
+func (e *Engine) capJobs(concurrency int, builtin bool, requested int) int {
+	if jobs := e.maxJobs(requested); jobs > 0 {
+		return min(concurrency, jobs)
+	}
+	if builtin {
+		return min(concurrency, 8, runtime.NumCPU())
+	}
+	return concurrency
+}
+
+// concurrencyCeiling is the largest worker count used without
+// AllowOversubscribe. Repo operations are mostly waiting on git subprocesses and
+// the network, so the limit is generous; it exists to catch typos like 5000.
+func concurrencyCeiling() int {
+	return 8 * runtime.NumCPU()
+}
+
+// clampConcurrency caps requested at concurrencyCeiling unless allow is set,
+// and reports whether the value was reduced.
+func clampConcurrency(requested int, allow bool) (int, bool) {
+	ceiling := concurrencyCeiling()
+	if allow || requested <= ceiling {
+		return requested, false
+	}
+	return ceiling, true
+}
+
+func (e *Engine) effectiveConcurrency(requested int, allow bool) int {
+	concurrency, clamped := clampConcurrency(requested, allow)
+	if clamped {
+		e.oversubscribeWarn.Do(func() {
+			e.logger.Warnf("concurrency %d exceeds %d (8x NumCPU); using %d (pass --allow-oversubscribe to keep it)", requested, concurrency, concurrency)
+		})
+	}
+	return concurrency
+}
+
 func (e *Engine) syncSequentialStopOnError(ctx context.Context, opts SyncOptions, entries []registry.Entry) ([]SyncResult, error) {
 	// Preserve deterministic "stop on first failure" semantics with direct
 	// per-entry execution (no goroutines/channels in this path).
@@ -940,6 +1876,9 @@ func (e *Engine) syncSequentialStopOnError(ctx context.Context, opts SyncOptions
 // (otherwise nil) so runSyncEntry can reuse it instead of inspecting the repo a
 // second time.
 func (e *Engine) prepareSyncEntry(ctx context.Context, entry registry.Entry, opts SyncOptions, timeoutSeconds int) (bool, *model.RepoStatus, *SyncResult) {
+	if len(opts.Paths) > 0 && !slices.Contains(opts.Paths, entry.Path) {
+		return false, nil, nil
+	}
 	if opts.Filter == FilterMissing && entry.Status != registry.StatusMissing {
 		return false, nil, nil
 	}
@@ -951,6 +1890,7 @@ func (e *Engine) prepareSyncEntry(ctx context.Context, entry registry.Entry, opt
 		return false, nil, nil
 	}
 	inspectCtx := ctx
+	timeoutSeconds = repoTimeout(entry, timeoutSeconds)
 	if timeoutSeconds > 0 {
 		var cancel context.CancelFunc
 		inspectCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
@@ -984,20 +1924,32 @@ func (e *Engine) prepareSyncEntry(ctx context.Context, entry registry.Entry, opt
 func (e *Engine) syncEntryMatchesInspectFilter(ctx context.Context, entry registry.Entry, opts SyncOptions) (bool, *model.RepoStatus, *SyncResult) {
 	if !filterRequiresInspect(opts.Filter) {
 		// Non-inspect filters (all/errors/missing) match without a live inspect,
-		// but an unknown filter must fail closed rather than matching every repo.
-		if !isKnownFilterKind(opts.Filter) {
+		// but an unknown filter must fail closed rather than matching every repo,
+		// and so must the status-only branches-behind-default filter.
+		if !isKnownFilterKind(opts.Filter) || opts.Filter == FilterBranchesBehindDefault {
 			return false, nil, nil
 		}
 		return true, nil, nil
 	}
-	status, err := e.InspectRepo(ctx, entry.Path)
+	scope := syncInspectScope(opts.Filter)
+	status, err := e.InspectRepoScope(ctx, entry.Path, scope)
 	if err != nil {
 		failure := inspectFailureResult(entry, err, e.classifier)
 		return false, nil, &failure
 	}
 	switch opts.Filter {
 	case FilterDirty:
-		return status.Worktree != nil && status.Worktree.Dirty, status, nil
+		if status.Worktree == nil || !status.Worktree.Dirty {
+			return false, nil, nil
+		}
+		// Sync reuses the inspection, so a matching repo gets the rest of it.
+		if scope != InspectFull {
+			if err := e.inspectRepoDetails(ctx, status); err != nil {
+				failure := inspectFailureResult(entry, err, e.classifier)
+				return false, nil, &failure
+			}
+		}
+		return true, status, nil
 	case FilterClean:
 		// Aligned with filterStatus: a repo with no worktree (e.g. bare) is not
 		// reported as clean, so both filter paths agree on the same repo set.
@@ -1014,6 +1966,11 @@ func (e *Engine) syncEntryMatchesInspectFilter(ctx context.Context, entry regist
 		return status.Tracking.Status == model.TrackingEqual, status, nil
 	case FilterRemoteMismatch:
 		return hasRemoteMismatch(*status, entry, e.normalizer), status, nil
+	case FilterStaleMetadata:
+		return hasStaleMetadata(*status, entry), status, nil
+	case FilterStaleUpstream:
+		e.probeStaleUpstream(ctx, status)
+		return status.Tracking.StaleUpstream, status, nil
 	default:
 		// Fail closed: an unknown inspect filter must not match every repo.
 		return false, status, nil
@@ -1023,7 +1980,7 @@ func (e *Engine) syncEntryMatchesInspectFilter(ctx context.Context, entry regist
 func filterRequiresInspect(kind FilterKind) bool {
 	switch kind {
 	case FilterDirty, FilterClean, FilterGone, FilterDiverged,
-		FilterBehind, FilterAhead, FilterEqual, FilterRemoteMismatch:
+		FilterBehind, FilterAhead, FilterEqual, FilterRemoteMismatch, FilterStaleMetadata, FilterStaleUpstream:
 		return true
 	default:
 		return false
@@ -1037,6 +1994,11 @@ func (e *Engine) handleMissingSyncEntry(ctx context.Context, entry registry.Entr
 	// Missing entries are recoverable only when we have enough material to
 	// perform a fresh clone into the recorded path.
 	remoteURL := strings.TrimSpace(entry.RemoteURL)
+	cloneURL := ""
+	if remoteURL == "" {
+		remoteURL, _ = remoteURLFromTemplate(opts.RemoteTemplate, entry.RepoID)
+		cloneURL = remoteURL
+	}
 	if remoteURL == "" {
 		return SyncResult{
 			RepoID:     entry.RepoID,
@@ -1059,27 +2021,30 @@ func (e *Engine) handleMissingSyncEntry(ctx context.Context, entry registry.Entr
 			Error:      SyncErrorSkippedNoUpstream,
 		}
 	}
-	action := "git clone"
-	if mirror {
-		action += " --mirror"
-	} else if branch != "" {
-		action += " --branch " + branch + " --single-branch"
+	depth := 0
+	if !mirror {
+		depth = opts.CloneDepth
 	}
-	action += " " + remoteURL + " " + entry.Path
+	action := cloneAction(remoteURL, entry.Path, branch, mirror, depth)
 	if opts.DryRun {
 		// Dry-run reports the exact git action string that a live run would execute.
 		return SyncResult{
-			RepoID:  entry.RepoID,
-			Path:    entry.Path,
-			Outcome: SyncOutcomePlannedCheckout,
-			OK:      true,
-			Error:   SyncErrorDryRun,
-			Action:  action,
-			Planned: true,
-			steps:   []syncStep{syncStepClone},
+			RepoID:     entry.RepoID,
+			Path:       entry.Path,
+			Outcome:    SyncOutcomePlannedCheckout,
+			OK:         true,
+			Error:      SyncErrorDryRun,
+			Action:     action,
+			Planned:    true,
+			CloneURL:   cloneURL,
+			CloneDepth: depth,
+			steps:      []syncStep{syncStepClone},
 		}
 	}
-	if err := e.adapter.Clone(ctx, remoteURL, entry.Path, branch, mirror); err != nil {
+	attempts, err := e.withRetry(ctx, syncRetryPolicyFor(opts), func() error {
+		return e.cloneRepo(ctx, remoteURL, entry.Path, branch, mirror, depth)
+	})
+	if err != nil {
 		return SyncResult{
 			RepoID:     entry.RepoID,
 			Path:       entry.Path,
@@ -1088,12 +2053,75 @@ func (e *Engine) handleMissingSyncEntry(ctx context.Context, entry registry.Entr
 			Error:      err.Error(),
 			ErrorClass: e.classifier.ClassifyError(err),
 			Action:     action,
+			Attempts:   attempts,
+			CloneURL:   cloneURL,
 		}
 	}
+	entry.RemoteURL = remoteURL
 	entry.Status = registry.StatusPresent
 	entry.LastSeen = time.Now()
 	e.replaceRegistryEntry(entry)
-	return SyncResult{RepoID: entry.RepoID, Path: entry.Path, Outcome: SyncOutcomeCheckoutMissing, OK: true, Action: action}
+	return SyncResult{RepoID: entry.RepoID, Path: entry.Path, Outcome: SyncOutcomeCheckoutMissing, OK: true, Action: action, Attempts: attempts, CloneURL: cloneURL, CloneDepth: depth}
+}
+
+// cloneAction renders the git clone command a checkout would run. Mirror
+// clones never carry a depth.
+func cloneAction(remoteURL, targetPath, branch string, mirror bool, depth int) string {
+	action := "git clone"
+	if mirror {
+		return action + " --mirror " + remoteURL + " " + targetPath
+	}
+	if depth > 0 {
+		action += " --depth " + strconv.Itoa(depth)
+	}
+	if branch != "" {
+		action += " --branch " + branch
+	}
+	if branch != "" || depth > 0 {
+		action += " --single-branch"
+	}
+	return action + " " + remoteURL + " " + targetPath
+}
+
+// cloneRepo clones remoteURL into targetPath, shallowly when depth is positive
+// and the clone is not a mirror.
+func (e *Engine) cloneRepo(ctx context.Context, remoteURL, targetPath, branch string, mirror bool, depth int) error {
+	if depth <= 0 || mirror {
+		return e.adapter.Clone(ctx, remoteURL, targetPath, branch, mirror)
+	}
+	cloner, ok := e.adapter.(vcs.ShallowCloner)
+	if !ok {
+		return fmt.Errorf("%s does not support shallow clones", e.adapter.Name())
+	}
+	return cloner.CloneShallow(ctx, remoteURL, targetPath, branch, depth)
+}
+
+// remoteURLFromTemplate rebuilds a clone URL from a forge-style repo ID
+// (host/owner/name, or host/group/subgroup/name) by expanding {host}, {owner},
+// and {name} in template, e.g. git@{host}:{owner}/{name}.git. It reports false
+// when template is empty or the ID does not look like a forge path: local:
+// IDs, IDs with fewer than three segments, empty segments, and hosts without
+// a dot.
+func remoteURLFromTemplate(template, repoID string) (string, bool) {
+	template = strings.TrimSpace(template)
+	repoID = strings.TrimSpace(repoID)
+	if template == "" || repoID == "" || strings.HasPrefix(repoID, "local:") {
+		return "", false
+	}
+	parts := strings.Split(repoID, "/")
+	if len(parts) < 3 || !strings.Contains(parts[0], ".") {
+		return "", false
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, " \t:@") {
+			return "", false
+		}
+	}
+	return strings.NewReplacer(
+		"{host}", parts[0],
+		"{owner}", strings.Join(parts[1:len(parts)-1], "/"),
+		"{name}", parts[len(parts)-1],
+	).Replace(template), true
 }
 
 // runSyncEntry executes (or plans) sync work for entry. cached carries the
@@ -1101,6 +2129,7 @@ func (e *Engine) handleMissingSyncEntry(ctx context.Context, entry registry.Entr
 // downstream paths reuse it instead of inspecting the same repo again.
 func (e *Engine) runSyncEntry(ctx context.Context, entry registry.Entry, opts SyncOptions, timeoutSeconds int, cached *model.RepoStatus) SyncResult {
 	repoCtx := ctx
+	timeoutSeconds = repoTimeout(entry, timeoutSeconds)
 	if timeoutSeconds > 0 {
 		var cancel context.CancelFunc
 		repoCtx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
@@ -1109,13 +2138,43 @@ func (e *Engine) runSyncEntry(ctx context.Context, entry registry.Entry, opts Sy
 	if opts.DryRun {
 		return e.runSyncDryRun(repoCtx, entry, opts, cached)
 	}
-	return e.runSyncApply(repoCtx, entry, opts, cached)
+	if !opts.CaptureOutput {
+		return e.runSyncApply(repoCtx, entry, opts, cached)
+	}
+	// Like executeCapturedSyncItem: one buffer per repo, whose commands run
+	// one at a time.
+	var output strings.Builder
+	res := e.runSyncApply(vcs.WithCommandOutput(repoCtx, &output), entry, opts, cached)
+	res.RawOutput = output.String()
+	return res
 }
 
 func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts SyncOptions, cached *model.RepoStatus) SyncResult {
-	fetchAction := syncFetchAction(ctx, e.adapter, entry.Path)
+	if entry.Type == "mirror" {
+		return skipResetForMirror(planMirrorUpdate(entry), opts)
+	}
+	if skipped := e.syncFetchRemoteSkip(ctx, entry, opts.FetchRemote, cached); skipped != nil {
+		return *skipped
+	}
+	autostash := false
+	if opts.AutostashAll {
+		if cached == nil {
+			status, err := e.InspectRepo(ctx, entry.Path)
+			if err != nil {
+				return inspectFailureResult(entry, err, e.classifier)
+			}
+			cached = status
+		}
+		autostash = cached.Worktree != nil && cached.Worktree.Dirty
+	}
+	lfs := opts.LFS && e.detectLFS(ctx, entry.Path, cached)
+	fetchAction := syncFetchAction(ctx, e.adapter, entry.Path, opts.FetchRemote, !opts.NoPruneTags)
+	deepen := e.syncDeepenFor(ctx, entry.Path, opts, cached)
+	if deepen > 0 {
+		fetchAction += " --deepen " + strconv.Itoa(deepen)
+	}
 	remoteTrackingRefs := model.RemoteTrackingRefStatus{}
-	if !opts.UpdateLocal {
+	if !opts.UpdateLocal && !opts.ResetHard {
 		// Reuse the inspection an inspect-based filter already ran for this repo
 		// rather than issuing a second `git remote prune --dry-run` round.
 		if cached != nil {
@@ -1124,8 +2183,17 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 			remoteTrackingRefs = e.inspectRemoteTrackingRefs(ctx, entry.Path, nil)
 		}
 	}
-	withRemoteTrackingRefs := func(result SyncResult) SyncResult {
+	withFetchDetails := func(result SyncResult) SyncResult {
 		result.RemoteTrackingRefs = remoteTrackingRefs
+		result.Deepen = deepen
+		result.FetchRemote = opts.FetchRemote
+		result.NoPruneTags = opts.NoPruneTags
+		if lfs {
+			result = withLFSFetchStep(result)
+		}
+		if autostash {
+			result = withAutostashSteps(result)
+		}
 		return result
 	}
 
@@ -1134,7 +2202,7 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 	// --update-local never fetches fewer repos than a plain sync, while the
 	// reported outcome preserves the typed skip reason.
 	skippedLocalUpdate := func(reason string) SyncResult {
-		return withRemoteTrackingRefs(SyncResult{
+		return withFetchDetails(SyncResult{
 			RepoID:     entry.RepoID,
 			Path:       entry.Path,
 			Outcome:    SyncOutcomeSkippedLocalUpdate,
@@ -1148,8 +2216,38 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 		})
 	}
 
+	if opts.ResetHard {
+		status := cached
+		if status == nil {
+			var err error
+			status, err = e.InspectRepo(ctx, entry.Path)
+			if err != nil {
+				return inspectFailureResult(entry, err, e.classifier)
+			}
+		}
+		remoteTrackingRefs = status.RemoteTrackingRefs
+		reason, err := e.resetHardSkipReason(ctx, entry, status, opts.ProtectedBranches)
+		if err != nil {
+			return inspectFailureResult(entry, err, e.classifier)
+		}
+		if reason != "" {
+			return skippedLocalUpdate(reason)
+		}
+		return withFetchDetails(SyncResult{
+			RepoID:      entry.RepoID,
+			Path:        entry.Path,
+			Outcome:     SyncOutcomePlannedFetch,
+			OK:          true,
+			Error:       SyncErrorDryRun,
+			Action:      fetchAction + " && " + resetHardAction(status.Tracking.Upstream),
+			Planned:     true,
+			ResetTarget: status.Tracking.Upstream,
+			steps:       []syncStep{syncStepFetch, syncStepResetHard, syncStepCleanAll},
+		})
+	}
+
 	if !opts.UpdateLocal {
-		return withRemoteTrackingRefs(SyncResult{
+		return withFetchDetails(SyncResult{
 			RepoID:  entry.RepoID,
 			Path:    entry.Path,
 			Outcome: SyncOutcomePlannedFetch,
@@ -1181,7 +2279,7 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 	}
 	remoteTrackingRefs = status.RemoteTrackingRefs
 	if opts.PushLocal && status.Tracking.Status == model.TrackingAhead {
-		return withRemoteTrackingRefs(SyncResult{
+		return withFetchDetails(SyncResult{
 			RepoID:  entry.RepoID,
 			Path:    entry.Path,
 			Outcome: SyncOutcomePlannedPush,
@@ -1192,11 +2290,13 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 			steps:   []syncStep{syncStepFetch, syncStepPush},
 		})
 	}
+	// The autostash leaves the worktree clean before the rebase runs.
 	if reason := pullRebaseSkipReason(status, PullRebasePolicyOptions{
-		RebaseDirty:          opts.RebaseDirty,
+		RebaseDirty:          opts.RebaseDirty || autostash,
 		Force:                opts.Force,
 		ProtectedBranches:    opts.ProtectedBranches,
 		AllowProtectedRebase: opts.AllowProtectedRebase,
+		MainBranch:           e.defaultBranchFor(entry),
 	}); reason != "" {
 		return skippedLocalUpdate(reason)
 	}
@@ -1207,10 +2307,15 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 	// them (git pull --rebase has no built-in autostash here).
 	steps := []syncStep{syncStepFetch}
 	action := fetchAction
-	stashPlanned := opts.RebaseDirty && status.Worktree != nil && status.Worktree.Dirty
+	backupBranch := backupBranchFor(opts.BackupBranch, status, time.Now())
+	if backupBranch != "" {
+		steps = append(steps, syncStepBackupBranch)
+		action += " && " + backupBranchAction(backupBranch)
+	}
+	stashPlanned := opts.RebaseDirty && !autostash && status.Worktree != nil && status.Worktree.Dirty
 	if stashPlanned {
 		steps = append(steps, syncStepStashPush)
-		action += " && git stash push -u -m \"" + preRebaseStashMessage + "\""
+		action += " && " + stashPushAction(e.stashMessage())
 	}
 	steps = append(steps, syncStepPullRebase)
 	action += " && git pull --rebase --no-recurse-submodules"
@@ -1218,19 +2323,87 @@ func (e *Engine) runSyncDryRun(ctx context.Context, entry registry.Entry, opts S
 		steps = append(steps, syncStepStashPop)
 		action += " && git stash pop"
 	}
-	return withRemoteTrackingRefs(SyncResult{
-		RepoID:  entry.RepoID,
-		Path:    entry.Path,
-		Outcome: SyncOutcomePlannedFetch,
-		OK:      true,
-		Error:   SyncErrorDryRun,
-		Action:  action,
-		Planned: true,
-		steps:   steps,
+	if opts.UpdateSubmodules && status.Submodules.HasSubmodules {
+		steps = append(steps, syncStepSubmoduleUpdate)
+		action += " && " + submoduleUpdateAction
+	}
+	return withFetchDetails(SyncResult{
+		RepoID:       entry.RepoID,
+		Path:         entry.Path,
+		Outcome:      SyncOutcomePlannedFetch,
+		OK:           true,
+		Error:        SyncErrorDryRun,
+		Action:       action,
+		Planned:      true,
+		BackupBranch: backupBranch,
+		steps:        steps,
 	})
 }
 
+// runSyncApplyAutostashed is runSyncApply for AutostashAll: a dirty worktree
+// is stashed first and popped after the rest of the sync has run.
+func (e *Engine) runSyncApplyAutostashed(ctx context.Context, entry registry.Entry, opts SyncOptions, cached *model.RepoStatus) SyncResult {
+	opts.AutostashAll = false
+	status := cached
+	if status == nil {
+		var err error
+		status, err = e.InspectRepo(ctx, entry.Path)
+		if err != nil {
+			return inspectFailureResult(entry, err, e.classifier)
+		}
+	}
+	if status.Worktree == nil || !status.Worktree.Dirty {
+		return e.runSyncApply(ctx, entry, opts, status)
+	}
+	created, err := e.adapter.StashPush(ctx, entry.Path, autostashMessage)
+	if err != nil {
+		return SyncResult{
+			RepoID:     entry.RepoID,
+			Path:       entry.Path,
+			Outcome:    SyncOutcomeFailedStash,
+			OK:         false,
+			Error:      err.Error(),
+			ErrorClass: e.classifier.ClassifyError(err),
+			Action:     stashPushAction(autostashMessage),
+		}
+	}
+	res := e.runSyncApply(ctx, entry, opts, status)
+	if !created {
+		return res
+	}
+	res.Autostashed = true
+	res = e.restoreAutostash(ctx, res)
+	action := stashPushAction(autostashMessage)
+	if res.Action != "" {
+		action += " && " + res.Action
+	}
+	res.Action = action
+	if res.AutostashRestored {
+		res.Action += " && git stash pop"
+	}
+	return res
+}
+
+// runSyncApply syncs one repo directly (without a plan) and records how long
+// its VCS operations took in the result's Duration.
 func (e *Engine) runSyncApply(ctx context.Context, entry registry.Entry, opts SyncOptions, cached *model.RepoStatus) SyncResult {
+	start := time.Now()
+	res := e.runSyncApplySteps(ctx, entry, opts, cached)
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (e *Engine) runSyncApplySteps(ctx context.Context, entry registry.Entry, opts SyncOptions, cached *model.RepoStatus) SyncResult {
+	if entry.Type == "mirror" {
+		res := e.runMirrorUpdate(ctx, entry, opts)
+		if res.OK {
+			res = skipResetForMirror(res, opts)
+		}
+		return res
+	}
+	if opts.AutostashAll {
+		return e.runSyncApplyAutostashed(ctx, entry, opts, cached)
+	}
 	if opts.Filter == FilterGone {
 		// The gone filter already inspected this repo during prepareSyncEntry;
 		// reuse that result instead of inspecting a second time.
@@ -1246,16 +2419,48 @@ func (e *Engine) runSyncApply(ctx context.Context, entry registry.Entry, opts Sy
 			return SyncResult{RepoID: entry.RepoID, Path: entry.Path, Outcome: SyncOutcomeSkipped, OK: true, Error: SyncErrorSkipped}
 		}
 	}
-	if err := e.adapter.Fetch(ctx, entry.Path); err != nil {
+	if skipped := e.syncFetchRemoteSkip(ctx, entry, opts.FetchRemote, cached); skipped != nil {
+		return *skipped
+	}
+	deepen := e.syncDeepenFor(ctx, entry.Path, opts, cached)
+	tagsPruned := 0
+	attempts, err := e.withRetry(ctx, syncRetryPolicyFor(opts), func() error {
+		var fetchErr error
+		tagsPruned, fetchErr = e.syncFetch(ctx, entry.Path, opts.FetchRemote, deepen, opts.NoPruneTags)
+		return fetchErr
+	})
+	if err != nil {
 		class := e.classifier.ClassifyError(err)
 		return SyncResult{
-			RepoID:     entry.RepoID,
-			Path:       entry.Path,
-			Outcome:    SyncOutcomeFailedFetch,
-			OK:         false,
-			Error:      syncFailureMessage(SyncOutcomeFailedFetch, class, err),
-			ErrorClass: class,
-		}
+			RepoID:      entry.RepoID,
+			Path:        entry.Path,
+			Outcome:     SyncOutcomeFailedFetch,
+			OK:          false,
+			Error:       syncFailureMessage(SyncOutcomeFailedFetch, class, err),
+			ErrorClass:  class,
+			Attempts:    attempts,
+			Deepen:      deepen,
+			FetchRemote: opts.FetchRemote,
+			NoPruneTags: opts.NoPruneTags,
+		}
+	}
+	res := e.runSyncApplyAfterFetch(ctx, entry, opts)
+	if opts.LFS {
+		res = e.applyLFSFetch(ctx, entry, res)
+	}
+	res.Attempts = attempts
+	res.Deepen = deepen
+	res.FetchRemote = opts.FetchRemote
+	res.NoPruneTags = opts.NoPruneTags
+	res.TagsPruned = tagsPruned
+	return res
+}
+
+// runSyncApplyAfterFetch applies the optional local update once the fetch
+// step has succeeded.
+func (e *Engine) runSyncApplyAfterFetch(ctx context.Context, entry registry.Entry, opts SyncOptions) SyncResult {
+	if opts.ResetHard {
+		return e.runResetHardAfterFetch(ctx, entry, opts)
 	}
 	if !opts.UpdateLocal {
 		return SyncResult{RepoID: entry.RepoID, Path: entry.Path, Outcome: SyncOutcomeFetched, OK: true}
@@ -1304,6 +2509,7 @@ func (e *Engine) runSyncApply(ctx context.Context, entry registry.Entry, opts Sy
 		Force:                opts.Force,
 		ProtectedBranches:    opts.ProtectedBranches,
 		AllowProtectedRebase: opts.AllowProtectedRebase,
+		MainBranch:           e.defaultBranchFor(entry),
 	}); reason != "" {
 		return SyncResult{
 			RepoID:     entry.RepoID,
@@ -1315,62 +2521,140 @@ func (e *Engine) runSyncApply(ctx context.Context, entry registry.Entry, opts Sy
 			SkipReason: reason,
 		}
 	}
-	return e.runSyncRebaseApply(ctx, entry, status, opts.RebaseDirty)
+	res := e.runSyncRebaseApply(ctx, entry, status, opts.RebaseDirty, backupBranchFor(opts.BackupBranch, status, time.Now()))
+	if opts.UpdateSubmodules && status.Submodules.HasSubmodules {
+		res = e.applySubmoduleUpdate(ctx, entry, res)
+	}
+	return res
 }
 
-func (e *Engine) runSyncRebaseApply(ctx context.Context, entry registry.Entry, status *model.RepoStatus, rebaseDirty bool) SyncResult {
-	action := "git pull --rebase --no-recurse-submodules"
+// backupBranchFor expands template into the backup branch name for a rebase of
+// status. Only diverged branches get a backup: a behind-only rebase is a fast
+// forward that rewrites nothing.
+func backupBranchFor(template string, status *model.RepoStatus, now time.Time) string {
+	template = strings.TrimSpace(template)
+	if template == "" || status == nil || status.Tracking.Status != model.TrackingDiverged {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{branch}", status.Head.Branch,
+		"{timestamp}", now.UTC().Format("20060102-150405"),
+	).Replace(template)
+}
+
+// backupBranchAction renders the backup branch creation for display.
+func backupBranchAction(name string) string {
+	return "git branch --no-track " + name + " HEAD"
+}
+
+// createBackupBranch creates the pre-rebase backup branch through the adapter,
+// failing when the backend cannot create branches.
+func (e *Engine) createBackupBranch(ctx context.Context, dir, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("backup branch step has no branch name")
+	}
+	creator, ok := e.adapter.(vcs.BranchCreator)
+	if !ok {
+		return fmt.Errorf("%s does not support creating branches", e.adapter.Name())
+	}
+	return creator.CreateBranch(ctx, dir, name)
+}
+
+// stashMessage returns the configured pre-rebase stash message.
+func (e *Engine) stashMessage() string {
+	if e.cfg != nil && strings.TrimSpace(e.cfg.Defaults.StashMessage) != "" {
+		return e.cfg.Defaults.StashMessage
+	}
+	return preRebaseStashMessage
+}
+
+// stashPushAction renders the stash push for display. The message is passed to
+// git as a single argument, so quoting here only affects the Action string.
+func stashPushAction(message string) string {
+	return "git stash push -u -m " + strconv.Quote(message)
+}
+
+// runSyncRebaseApply applies the pull --rebase local update, timing it the
+// same way as runSyncApply.
+func (e *Engine) runSyncRebaseApply(ctx context.Context, entry registry.Entry, status *model.RepoStatus, rebaseDirty bool, backupBranch string) SyncResult {
+	start := time.Now()
+	res := e.runSyncRebaseSteps(ctx, entry, status, rebaseDirty, backupBranch)
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (e *Engine) runSyncRebaseSteps(ctx context.Context, entry registry.Entry, status *model.RepoStatus, rebaseDirty bool, backupBranch string) SyncResult {
+	action := pullRebaseAction
 	stashed := false
 	var err error
+	if backupBranch != "" {
+		if err := e.createBackupBranch(ctx, entry.Path, backupBranch); err != nil {
+			return SyncResult{
+				RepoID:       entry.RepoID,
+				Path:         entry.Path,
+				Outcome:      SyncOutcomeFailedBackupBranch,
+				OK:           false,
+				Error:        err.Error(),
+				ErrorClass:   e.classifier.ClassifyError(err),
+				Action:       backupBranchAction(backupBranch),
+				BackupBranch: backupBranch,
+			}
+		}
+		action = backupBranchAction(backupBranch) + " && " + action
+	}
 	if rebaseDirty && status.Worktree != nil && status.Worktree.Dirty {
 		// Stash only when needed so we do not create unnecessary stash entries.
-		stashed, err = e.adapter.StashPush(ctx, entry.Path, "repokeeper: pre-rebase stash")
+		stashed, err = e.adapter.StashPush(ctx, entry.Path, e.stashMessage())
 		if err != nil {
 			return SyncResult{
-				RepoID:     entry.RepoID,
-				Path:       entry.Path,
-				Outcome:    SyncOutcomeFailedStash,
-				OK:         false,
-				Error:      err.Error(),
-				ErrorClass: e.classifier.ClassifyError(err),
-				Action:     "git stash push -u -m \"repokeeper: pre-rebase stash\"",
+				RepoID:       entry.RepoID,
+				Path:         entry.Path,
+				Outcome:      SyncOutcomeFailedStash,
+				OK:           false,
+				Error:        err.Error(),
+				ErrorClass:   e.classifier.ClassifyError(err),
+				Action:       stashPushAction(e.stashMessage()),
+				BackupBranch: backupBranch,
 			}
 		}
 		if stashed {
-			action = "git stash push -u -m \"repokeeper: pre-rebase stash\" && " + action
+			action = strings.TrimSuffix(action, pullRebaseAction) + stashPushAction(e.stashMessage()) + " && " + pullRebaseAction
 		}
 	}
 	if err := e.adapter.PullRebase(ctx, entry.Path); err != nil {
 		return SyncResult{
-			RepoID:     entry.RepoID,
-			Path:       entry.Path,
-			Outcome:    SyncOutcomeFailedRebase,
-			OK:         false,
-			Error:      err.Error(),
-			ErrorClass: e.classifier.ClassifyError(err),
-			Action:     action,
+			RepoID:       entry.RepoID,
+			Path:         entry.Path,
+			Outcome:      SyncOutcomeFailedRebase,
+			OK:           false,
+			Error:        err.Error(),
+			ErrorClass:   e.classifier.ClassifyError(err),
+			Action:       action,
+			BackupBranch: backupBranch,
 		}
 	}
 	if stashed {
 		if err := e.adapter.StashPop(ctx, entry.Path); err != nil {
 			return SyncResult{
-				RepoID:     entry.RepoID,
-				Path:       entry.Path,
-				Outcome:    SyncOutcomeFailedStashPop,
-				OK:         false,
-				Error:      err.Error(),
-				ErrorClass: e.classifier.ClassifyError(err),
-				Action:     action + " && git stash pop",
+				RepoID:       entry.RepoID,
+				Path:         entry.Path,
+				Outcome:      SyncOutcomeFailedStashPop,
+				OK:           false,
+				Error:        err.Error(),
+				ErrorClass:   e.classifier.ClassifyError(err),
+				Action:       action + " && git stash pop",
+				BackupBranch: backupBranch,
 			}
 		}
 		action += " && git stash pop"
 	}
 	return SyncResult{
-		RepoID:  entry.RepoID,
-		Path:    entry.Path,
-		Outcome: outcomeForRebase(stashed),
-		OK:      true,
-		Action:  action,
+		RepoID:       entry.RepoID,
+		Path:         entry.Path,
+		Outcome:      outcomeForRebase(stashed),
+		OK:           true,
+		Action:       action,
+		BackupBranch: backupBranch,
 	}
 }
 
@@ -1394,6 +2678,10 @@ type PullRebasePolicyOptions struct {
 	Force                bool
 	ProtectedBranches    []string
 	AllowProtectedRebase bool
+	// MainBranch is the repo's resolved default branch (see
+	// Engine.defaultBranchFor). When it is checked out, its upstream must be
+	// the same branch, so main is never rebased onto some other branch.
+	MainBranch string
 }
 
 func pullRebaseSkipReason(status *model.RepoStatus, opts PullRebasePolicyOptions) string {
@@ -1405,6 +2693,11 @@ func pullRebaseSkipReason(status *model.RepoStatus, opts PullRebasePolicyOptions
 	if status.Bare {
 		return SyncReasonBareRepository
 	}
+	// A rebase or bisect also detaches HEAD, so this comes first to name the
+	// actual problem.
+	if status.InProgress != "" {
+		return SyncReasonOperationInProgress
+	}
 	if status.Head.Detached {
 		return SyncReasonDetachedHead
 	}
@@ -1423,6 +2716,9 @@ func pullRebaseSkipReason(status *model.RepoStatus, opts PullRebasePolicyOptions
 	if status.Tracking.Upstream == "" || status.Tracking.Status == model.TrackingNone {
 		return SyncReasonBranchNotTrackingUpstream
 	}
+	if main := strings.TrimSpace(opts.MainBranch); main != "" && status.Head.Branch == main && upstreamBranchName(status.Tracking.Upstream) != main {
+		return fmt.Sprintf("upstream %q is not %s", status.Tracking.Upstream, main)
+	}
 	if status.Tracking.Status == model.TrackingAhead {
 		return SyncReasonBranchHasLocalCommitsToPush
 	}
@@ -1435,6 +2731,28 @@ func pullRebaseSkipReason(status *model.RepoStatus, opts PullRebasePolicyOptions
 	return ""
 }
 
+// upstreamBranchName strips the remote from an upstream like origin/main.
+func upstreamBranchName(upstream string) string {
+	if _, branch, ok := strings.Cut(strings.TrimSpace(upstream), "/"); ok {
+		return branch
+	}
+	return strings.TrimSpace(upstream)
+}
+
+// defaultBranchFor resolves a repo's default branch: the registry entry's
+// default_branch, then defaults.main_branch, then main.
+func (e *Engine) defaultBranchFor(entry registry.Entry) string {
+	if b := strings.TrimSpace(entry.DefaultBranch); b != "" {
+		return b
+	}
+	if e.cfg != nil {
+		if b := strings.TrimSpace(e.cfg.Defaults.MainBranch); b != "" {
+			return b
+		}
+	}
+	return "main"
+}
+
 func matchesProtectedBranch(branch string, patterns []string) bool {
 	branch = strings.TrimSpace(branch)
 	if branch == "" {
@@ -1459,12 +2777,49 @@ func outcomeForRebase(stashed bool) OutcomeKind {
 	return SyncOutcomeRebased
 }
 
+// FetchRepo fetches a single registry entry, remote branches and tags alike,
+// and returns its freshly inspected status. Mirrors are refreshed as sync
+// refreshes them. The fetch runs under the entry's own timeout, falling back
+// to timeoutSeconds and then the config default. A failed fetch is returned as
+// the error without inspecting the repo.
+func (e *Engine) FetchRepo(ctx context.Context, entry registry.Entry, timeoutSeconds int) (*model.RepoStatus, error) {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = e.cfg.Defaults.TimeoutSeconds
+	}
+	fetchCtx, cancel := goneBranchContext(ctx, repoTimeout(entry, timeoutSeconds))
+	defer cancel()
+	fetch := e.adapter.Fetch
+	if entry.Type == "mirror" {
+		fetch = e.mirrorUpdate
+	}
+	if err := fetch(fetchCtx, entry.Path); err != nil {
+		return nil, err
+	}
+	return e.InspectRepo(ctx, entry.Path)
+}
+
 // InspectRepo gathers the full status for a single repository path.
 func (e *Engine) InspectRepo(ctx context.Context, path string) (*model.RepoStatus, error) {
-	status, err := e.inspectRepoCore(ctx, path)
+	return e.inspectRepo(ctx, path, e.adapter, InspectFull)
+}
+
+// InspectRepoScope is InspectRepo limited to scope.
+func (e *Engine) InspectRepoScope(ctx context.Context, path string, scope InspectScope) (*model.RepoStatus, error) {
+	return e.inspectRepo(ctx, path, e.adapter, scope)
+}
+
+// inspectRepo is InspectRepoScope with the repo ID derived through urls,
+// which status runs share with remote-mismatch filtering.
+func (e *Engine) inspectRepo(ctx context.Context, path string, urls vcs.URLNormalizer, scope InspectScope) (*model.RepoStatus, error) {
+	status, err := e.inspectRepoWorktree(ctx, path, urls)
 	if err != nil {
 		return nil, err
 	}
+	if scope == InspectFull {
+		if err := e.inspectRepoDetails(ctx, status); err != nil {
+			return nil, err
+		}
+	}
 	if e.registry != nil {
 		if entry := e.registry.FindEntry(status.RepoID, status.Path); entry != nil {
 			registry.SeedRepoMetadataStatus(*entry, status)
@@ -1474,7 +2829,10 @@ func (e *Engine) InspectRepo(ctx context.Context, path string) (*model.RepoStatu
 	return status, nil
 }
 
-func (e *Engine) inspectRepoCore(ctx context.Context, path string) (*model.RepoStatus, error) {
+// inspectRepoWorktree gathers what InspectWorktree promises: identity,
+// remotes, HEAD, worktree status, and the shallow state. Tracking is left as
+// none.
+func (e *Engine) inspectRepoWorktree(ctx context.Context, path string, urls vcs.URLNormalizer) (*model.RepoStatus, error) {
 	bare, _ := e.adapter.IsBare(ctx, path)
 
 	remotes, err := e.adapter.Remotes(ctx, path)
@@ -1485,7 +2843,6 @@ func (e *Engine) inspectRepoCore(ctx context.Context, path string) (*model.RepoS
 	for _, r := range remotes {
 		remoteNames = append(remoteNames, r.Name)
 	}
-	remoteTrackingRefs := e.inspectRemoteTrackingRefs(ctx, path, remoteNames)
 	primary := e.adapter.PrimaryRemote(remoteNames)
 	var remoteURL string
 	for _, r := range remotes {
@@ -1494,7 +2851,7 @@ func (e *Engine) inspectRepoCore(ctx context.Context, path string) (*model.RepoS
 			break
 		}
 	}
-	repoID := e.adapter.NormalizeURL(remoteURL)
+	repoID := urls.NormalizeURL(remoteURL)
 	if repoID == "" {
 		repoID = "local:" + filepath.ToSlash(path)
 	}
@@ -1510,34 +2867,317 @@ func (e *Engine) inspectRepoCore(ctx context.Context, path string) (*model.RepoS
 			return nil, err
 		}
 	}
+
+	return &model.RepoStatus{
+		RepoID:        repoID,
+		Path:          path,
+		Bare:          bare,
+		Remotes:       remotes,
+		PrimaryRemote: primary,
+		Head:          head,
+		Worktree:      worktree,
+		Tracking:      model.Tracking{Status: model.TrackingNone},
+		Shallow:       e.inspectShallow(ctx, path),
+	}, nil
+}
+
+// inspectRepoDetails fills in the rest of a full inspection on a status from
+// inspectRepoWorktree: tracking, submodules, branches, and the cheaper probes.
+func (e *Engine) inspectRepoDetails(ctx context.Context, status *model.RepoStatus) error {
+	path := status.Path
+	var remoteNames []string
+	for _, r := range status.Remotes {
+		remoteNames = append(remoteNames, r.Name)
+	}
+	remoteTrackingRefs := e.inspectRemoteTrackingRefs(ctx, path, remoteNames)
 	tracking := model.Tracking{Status: model.TrackingNone}
-	if !bare {
+	if !status.Bare {
+		var err error
 		tracking, err = e.adapter.TrackingStatus(ctx, path)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		e.inspectStaleUpstream(ctx, path, status.Remotes, &tracking, e.remoteDefaultBranch)
 	}
 	hasSubmodules, subErr := e.adapter.HasSubmodules(ctx, path)
 	if subErr != nil {
 		e.logger.Warnf("HasSubmodules check failed for %s: %v", path, subErr)
 	}
 
-	localBranches := e.inspectLocalBranches(ctx, path, primary, repoID, head, tracking, bare)
+	localBranches := e.inspectLocalBranches(ctx, path, status.PrimaryRemote, status.RepoID, status.Head, tracking, status.Bare)
+	stashCount := 0
+	var lastCommit time.Time
+	var worktreeRole, commonDir, inProgress string
+	if !status.Bare {
+		stashCount = e.inspectStashCount(ctx, path)
+		lastCommit = e.inspectLastCommit(ctx, path)
+		worktreeRole, commonDir = e.inspectWorktreeRole(ctx, path)
+		inProgress = e.inspectInProgress(ctx, path)
+	}
+
+	status.WorktreeRole = worktreeRole
+	status.GitCommonDir = commonDir
+	status.InProgress = inProgress
+	status.Tracking = tracking
+	status.Submodules = model.Submodules{HasSubmodules: hasSubmodules}
+	status.StashCount = stashCount
+	status.LastCommit = lastCommit
+	status.RemoteTrackingRefs = remoteTrackingRefs
+	status.LocalBranches = localBranches
+	return nil
+}
 
-	status := &model.RepoStatus{
-		RepoID:             repoID,
-		Path:               path,
-		Bare:               bare,
-		Remotes:            remotes,
-		PrimaryRemote:      primary,
-		Head:               head,
-		Worktree:           worktree,
-		Tracking:           tracking,
-		Submodules:         model.Submodules{HasSubmodules: hasSubmodules},
-		RemoteTrackingRefs: remoteTrackingRefs,
-		LocalBranches:      localBranches,
+// legacyMainlineBranches are the branch names a host default branch is
+// commonly renamed from or to. Only these, plus defaults.main_branch, are
+// checked for a stale upstream, so feature branches that merely are not the
+// remote default are never flagged.
+var legacyMainlineBranches = []string{"main", "master", "trunk"}
+
+// inspectStaleUpstream sets tracking.StaleUpstream when the branch tracks a
+// mainline branch that still exists on its remote while remoteHead reports
+// the remote's HEAD at a different branch. Gone upstreams are already
+// reported as gone.
+func (e *Engine) inspectStaleUpstream(ctx context.Context, path string, remotes []model.Remote, tracking *model.Tracking, remoteHead func(ctx context.Context, dir, remote string) string) {
+	if tracking.Status == model.TrackingGone || tracking.Status == model.TrackingNone {
+		return
+	}
+	remote, branch := splitUpstream(tracking.Upstream, remotes)
+	if remote == "" || !e.isMainlineBranch(branch) {
+		return
+	}
+	remoteDefault := remoteHead(ctx, path, remote)
+	if remoteDefault == "" || remoteDefault == branch {
+		return
+	}
+	tracking.StaleUpstream = true
+	tracking.RemoteDefault = remoteDefault
+}
+
+// probeStaleUpstream redoes the stale-upstream check on a fully inspected
+// status against the remote's live HEAD, which costs a network round trip,
+// so it only runs when the caller is looking for stale upstreams.
+func (e *Engine) probeStaleUpstream(ctx context.Context, status *model.RepoStatus) {
+	if status.Bare {
+		return
+	}
+	status.Tracking.StaleUpstream = false
+	status.Tracking.RemoteDefault = ""
+	e.inspectStaleUpstream(ctx, status.Path, status.Remotes, &status.Tracking, e.remoteHeadBranch)
+}
+
+func (e *Engine) isMainlineBranch(branch string) bool {
+	if e.cfg != nil && branch == strings.TrimSpace(e.cfg.Defaults.MainBranch) {
+		return true
+	}
+	return slices.Contains(legacyMainlineBranches, branch)
+}
+
+// splitUpstream splits an upstream like origin/main into its remote and
+// branch, matching the longest configured remote name since remote names may
+// contain slashes. It returns empty strings when no remote matches.
+func splitUpstream(upstream string, remotes []model.Remote) (string, string) {
+	upstream = strings.TrimSpace(upstream)
+	remote, branch := "", ""
+	for _, r := range remotes {
+		if rest, ok := strings.CutPrefix(upstream, r.Name+"/"); ok && rest != "" && len(r.Name) > len(remote) {
+			remote, branch = r.Name, rest
+		}
+	}
+	return remote, branch
+}
+
+// inspectStashCount reports forgotten stashes. Failures are logged and count as
+// zero so a broken stash ref never aborts a status run.
+func (e *Engine) inspectStashCount(ctx context.Context, path string) int {
+	inspector, ok := e.adapter.(vcs.StashInspector)
+	if !ok {
+		return 0
+	}
+	count, err := inspector.StashList(ctx, path)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Warnf("stash list failed for %s: %v", path, err)
+		}
+		return 0
+	}
+	return count
+}
+
+// inspectWorktreeRole reports whether path is a main or linked worktree and
+// the common git dir. Failures are logged and leave the role empty.
+func (e *Engine) inspectWorktreeRole(ctx context.Context, path string) (string, string) {
+	inspector, ok := e.adapter.(vcs.WorktreeRoleInspector)
+	if !ok {
+		return "", ""
+	}
+	role, commonDir, err := inspector.WorktreeRole(ctx, path)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Debugf("worktree role check failed for %s: %v", path, err)
+		}
+		return "", ""
+	}
+	return role, commonDir
+}
+
+// inspectInProgress reports an unfinished rebase, merge, or bisect. Failures
+// are logged and reported as nothing in progress.
+func (e *Engine) inspectInProgress(ctx context.Context, path string) string {
+	inspector, ok := e.adapter.(vcs.InProgressInspector)
+	if !ok {
+		return ""
+	}
+	op, err := inspector.InProgressOperation(ctx, path)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Debugf("in-progress operation check failed for %s: %v", path, err)
+		}
+		return ""
+	}
+	return op
+}
+
+// inspectShallow reports whether path is a shallow clone. Detection failures
+// are treated as full history so they never trigger a --deepen fetch.
+func (e *Engine) inspectShallow(ctx context.Context, path string) bool {
+	inspector, ok := e.adapter.(vcs.ShallowInspector)
+	if !ok {
+		return false
+	}
+	shallow, err := inspector.IsShallow(ctx, path)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Debugf("shallow check failed for %s: %v", path, err)
+		}
+		return false
+	}
+	return shallow
+}
+
+// syncDeepenFor returns the --deepen depth to use for path: opts.Deepen when
+// the repo is shallow, otherwise zero. cached is reused when an inspect-based
+// filter already looked at the repo.
+func (e *Engine) syncDeepenFor(ctx context.Context, path string, opts SyncOptions, cached *model.RepoStatus) int {
+	if opts.Deepen <= 0 {
+		return 0
+	}
+	shallow := false
+	if cached != nil {
+		shallow = cached.Shallow
+	} else {
+		shallow = e.inspectShallow(ctx, path)
+	}
+	if !shallow {
+		return 0
+	}
+	return opts.Deepen
+}
+
+// syncFetchRemoteSkip returns a skip result when remote is set but the repo has
+// no remote of that name. It reuses the remotes a filter inspection already
+// collected and otherwise only lists remotes, which needs no network.
+func (e *Engine) syncFetchRemoteSkip(ctx context.Context, entry registry.Entry, remote string, cached *model.RepoStatus) *SyncResult {
+	if remote == "" {
+		return nil
+	}
+	var remotes []model.Remote
+	if cached != nil {
+		remotes = cached.Remotes
+	} else {
+		var err error
+		remotes, err = e.adapter.Remotes(ctx, entry.Path)
+		if err != nil {
+			failure := inspectFailureResult(entry, err, e.classifier)
+			return &failure
+		}
+	}
+	for _, candidate := range remotes {
+		if candidate.Name == remote {
+			return nil
+		}
+	}
+	return &SyncResult{
+		RepoID:      entry.RepoID,
+		Path:        entry.Path,
+		Outcome:     SyncOutcomeSkipped,
+		OK:          true,
+		ErrorClass:  "skipped",
+		Error:       SyncErrorSkippedNoRemotePrefix + fmt.Sprintf("remote %q is not configured", remote),
+		FetchRemote: remote,
 	}
-	return status, nil
+}
+
+// syncFetch runs the sync fetch step against remote (every remote when empty),
+// deepening shallow history when deepen is positive and keeping local-only
+// tags when noPruneTags is set, and returns how many local tags were pruned.
+// Adapters without OptionFetcher only support their default fetch. A
+// successful fetch also refreshes the fetched remotes' recorded HEAD.
+func (e *Engine) syncFetch(ctx context.Context, path, remote string, deepen int, noPruneTags bool) (int, error) {
+	if fetcher, ok := e.adapter.(vcs.OptionFetcher); ok {
+		res, err := fetcher.FetchWithOptions(ctx, path, vcs.FetchOptions{Remote: remote, Depth: deepen, NoPruneTags: noPruneTags})
+		if err == nil {
+			e.updateRemoteHeads(ctx, path, remote)
+		}
+		return res.TagsPruned, err
+	}
+	if remote != "" {
+		return 0, fmt.Errorf("%s does not support fetching a single remote", e.adapter.Name())
+	}
+	if deepen > 0 {
+		return 0, fmt.Errorf("%s does not support deepening shallow history", e.adapter.Name())
+	}
+	if err := e.adapter.Fetch(ctx, path); err != nil {
+		return 0, err
+	}
+	e.updateRemoteHeads(ctx, path, remote)
+	return 0, nil
+}
+
+// updateRemoteHeads refreshes the recorded HEAD of remote (every remote when
+// empty) so the stale-upstream check of later inspections follows a renamed
+// remote default branch without querying the remote itself. Failures only
+// leave the previous record in place, so they are logged and ignored.
+func (e *Engine) updateRemoteHeads(ctx context.Context, path, remote string) {
+	updater, ok := e.adapter.(vcs.RemoteHeadUpdater)
+	if !ok {
+		return
+	}
+	names := []string{remote}
+	if remote == "" {
+		remotes, err := e.adapter.Remotes(ctx, path)
+		if err != nil {
+			if e.logger != nil {
+				e.logger.Debugf("remote listing failed for %s: %v", path, err)
+			}
+			return
+		}
+		names = names[:0]
+		for _, r := range remotes {
+			names = append(names, r.Name)
+		}
+	}
+	for _, name := range names {
+		if err := updater.UpdateRemoteHead(ctx, path, name); err != nil && e.logger != nil {
+			e.logger.Debugf("remote HEAD update failed for %s (%s): %v", path, name, err)
+		}
+	}
+}
+
+// inspectLastCommit reads the HEAD commit date. An unborn branch makes git log
+// fail, which is expected and reported as the zero time.
+func (e *Engine) inspectLastCommit(ctx context.Context, path string) time.Time {
+	inspector, ok := e.adapter.(vcs.LastCommitInspector)
+	if !ok {
+		return time.Time{}
+	}
+	at, err := inspector.LastCommit(ctx, path)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Debugf("last commit lookup failed for %s: %v", path, err)
+		}
+		return time.Time{}
+	}
+	return at
 }
 
 func (e *Engine) inspectRemoteTrackingRefs(ctx context.Context, path string, remoteNames []string) model.RemoteTrackingRefStatus {
@@ -1574,6 +3214,29 @@ func (e *Engine) upsertRegistryEntry(entry registry.Entry) {
 	e.registry.Upsert(entry)
 }
 
+// registryEntryAtPath returns a copy of the registry entry recorded at path,
+// or nil when there is none.
+func (e *Engine) registryEntryAtPath(path string) *registry.Entry {
+	e.registryMu.Lock()
+	defer e.registryMu.Unlock()
+	if e.registry == nil {
+		return nil
+	}
+	path = filepath.Clean(path)
+	for _, entry := range e.registry.Entries {
+		if filepath.Clean(entry.Path) == path {
+			return &entry
+		}
+	}
+	return nil
+}
+
+func (e *Engine) markRegistryEntryMoved(repoID, path string, now time.Time) bool {
+	e.registryMu.Lock()
+	defer e.registryMu.Unlock()
+	return e.registry.MarkMoved(repoID, path, now)
+}
+
 func (e *Engine) replaceRegistryEntry(entry registry.Entry) {
 	e.registryMu.Lock()
 	defer e.registryMu.Unlock()
@@ -1592,46 +3255,120 @@ func (e *Engine) setRegistryUpdatedAt(ts time.Time) {
 	e.registry.UpdatedAt = ts
 }
 
-func filterStatus(kind FilterKind, status model.RepoStatus, reg *registry.Registry) bool {
+// filterStatus reports whether status passes kind. normalizer is used for
+// remote-mismatch checks; nil falls back to git URL normalization.
+func filterStatus(kind FilterKind, status model.RepoStatus, reg *registry.Registry, normalizer vcs.URLNormalizer) bool {
+	matched, _ := ExplainFilter(kind, status, reg, normalizer)
+	return matched
+}
+
+// ExplainFilter is filterStatus with a human-readable reason for a match, such
+// as "diverged: 2 ahead, 3 behind". The reason is empty when the repo does not
+// match and for FilterAll, which matches without a reason.
+func ExplainFilter(kind FilterKind, status model.RepoStatus, reg *registry.Registry, normalizer vcs.URLNormalizer) (bool, string) {
 	switch kind {
 	case FilterAll, "":
 		// An empty kind is the conventional "no filter" and matches all repos.
-		return true
+		return true, ""
 	case FilterMissing:
 		if reg == nil {
-			return false
+			return false, ""
 		}
 		entry := findRegistryEntryForStatus(reg, status)
-		return entry != nil && entry.Status == registry.StatusMissing
+		if entry == nil || entry.Status != registry.StatusMissing {
+			return false, ""
+		}
+		return true, "missing: path not found on disk"
 	case FilterDirty:
-		return status.Worktree != nil && status.Worktree.Dirty
+		if status.Worktree == nil || !status.Worktree.Dirty {
+			return false, ""
+		}
+		return true, fmt.Sprintf("dirty: %d staged, %d unstaged, %d untracked", status.Worktree.Staged, status.Worktree.Unstaged, status.Worktree.Untracked)
 	case FilterClean:
-		return status.Worktree != nil && !status.Worktree.Dirty
+		if status.Worktree == nil || status.Worktree.Dirty {
+			return false, ""
+		}
+		return true, "clean worktree"
 	case FilterGone:
-		return status.Tracking.Status == model.TrackingGone
+		if status.Tracking.Status != model.TrackingGone {
+			return false, ""
+		}
+		return true, "gone: " + upstreamName(status.Tracking.Upstream) + " no longer exists"
 	case FilterDiverged:
-		return status.Tracking.Status == model.TrackingDiverged
+		if status.Tracking.Status != model.TrackingDiverged {
+			return false, ""
+		}
+		return true, fmt.Sprintf("diverged: %d ahead, %d behind", countOrZero(status.Tracking.Ahead), countOrZero(status.Tracking.Behind))
 	case FilterBehind:
-		return status.Tracking.Status == model.TrackingBehind
+		if status.Tracking.Status != model.TrackingBehind {
+			return false, ""
+		}
+		return true, fmt.Sprintf("behind: %d behind %s", countOrZero(status.Tracking.Behind), upstreamName(status.Tracking.Upstream))
 	case FilterAhead:
-		return status.Tracking.Status == model.TrackingAhead
+		if status.Tracking.Status != model.TrackingAhead {
+			return false, ""
+		}
+		return true, fmt.Sprintf("ahead: %d ahead of %s", countOrZero(status.Tracking.Ahead), upstreamName(status.Tracking.Upstream))
 	case FilterEqual:
-		return status.Tracking.Status == model.TrackingEqual
+		if status.Tracking.Status != model.TrackingEqual {
+			return false, ""
+		}
+		return true, fmt.Sprintf("equal: up to date with %s", upstreamName(status.Tracking.Upstream))
 	case FilterRemoteMismatch:
 		if reg == nil {
-			return false
+			return false, ""
+		}
+		entry := findRegistryEntryForStatus(reg, status)
+		if entry == nil || !hasRemoteMismatch(status, *entry, normalizer) {
+			return false, ""
+		}
+		return true, fmt.Sprintf("remote mismatch: registry has %s, live remote is %s", strings.TrimSpace(entry.RemoteURL), status.RepoID)
+	case FilterStaleMetadata:
+		if reg == nil {
+			return false, ""
 		}
 		entry := findRegistryEntryForStatus(reg, status)
 		if entry == nil {
-			return false
+			return false, ""
+		}
+		return explainStaleMetadata(status, *entry)
+	case FilterStaleUpstream:
+		if !status.Tracking.StaleUpstream {
+			return false, ""
+		}
+		return true, fmt.Sprintf("stale upstream: tracks %s, remote default is %s", status.Tracking.Upstream, status.Tracking.RemoteDefault)
+	case FilterBranchesBehindDefault:
+		if status.LocalBranches.BehindBaseCount <= 0 {
+			return false, ""
 		}
-		return hasRemoteMismatch(status, *entry, nil)
+		return true, fmt.Sprintf("%d branches behind default", status.LocalBranches.BehindBaseCount)
 	case FilterErrors:
-		return status.Error != ""
+		if status.Error == "" {
+			return false, ""
+		}
+		if status.ErrorClass != "" {
+			return true, "error: " + status.ErrorClass
+		}
+		return true, "error: " + status.Error
 	default:
 		// Fail closed: an unknown filter must not match every repository.
-		return false
+		return false, ""
+	}
+}
+
+// upstreamName names the tracked upstream in a filter reason.
+func upstreamName(upstream string) string {
+	if upstream = strings.TrimSpace(upstream); upstream != "" {
+		return "upstream " + upstream
 	}
+	return "upstream"
+}
+
+func countOrZero(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
 }
 
 func findRegistryEntryForStatus(reg *registry.Registry, status model.RepoStatus) *registry.Entry {
@@ -1660,6 +3397,40 @@ func hasRemoteMismatch(status model.RepoStatus, entry registry.Entry, normalizer
 	return normalizedRegistry != normalizedStatus
 }
 
+// hasStaleMetadata reports whether the branch or remote URL recorded in entry
+// no longer matches what the live inspection found. Unlike hasRemoteMismatch it
+// compares the raw URL, so an ssh-to-https switch that keeps the same repo ID
+// still counts. Empty recorded values and a detached HEAD are not compared.
+func hasStaleMetadata(status model.RepoStatus, entry registry.Entry) bool {
+	stale, _ := explainStaleMetadata(status, entry)
+	return stale
+}
+
+// explainStaleMetadata is hasStaleMetadata with the mismatching field spelled
+// out, branch first.
+func explainStaleMetadata(status model.RepoStatus, entry registry.Entry) (bool, string) {
+	recordedBranch := strings.TrimSpace(entry.Branch)
+	liveBranch := strings.TrimSpace(status.Head.Branch)
+	if recordedBranch != "" && !status.Head.Detached && liveBranch != "" && recordedBranch != liveBranch {
+		return true, fmt.Sprintf("stale metadata: registry branch %s, live branch %s", recordedBranch, liveBranch)
+	}
+	recordedURL := strings.TrimSpace(entry.RemoteURL)
+	liveURL := primaryRemoteURL(status)
+	if recordedURL != "" && liveURL != "" && recordedURL != liveURL {
+		return true, fmt.Sprintf("stale metadata: registry remote %s, live remote %s", recordedURL, liveURL)
+	}
+	return false, ""
+}
+
+func primaryRemoteURL(status model.RepoStatus) string {
+	for _, remote := range status.Remotes {
+		if remote.Name == status.PrimaryRemote {
+			return strings.TrimSpace(remote.URL)
+		}
+	}
+	return ""
+}
+
 func sortRepoStatuses(statuses []model.RepoStatus) {
 	sortutil.SortRepoStatuses(statuses)
 }
@@ -5,9 +5,13 @@ import (
 	"context"
 	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/model"
@@ -19,7 +23,7 @@ import (
 func TestPlanImportClonesGuardsAndErrors(t *testing.T) {
 	t.Run("nil registry returns empty plan", func(t *testing.T) {
 		eng := &Engine{cfg: &config.Config{}, adapter: &planAdapter{}, classifier: vcs.NewGitErrorClassifier()}
-		plan, err := eng.PlanImportClones(nil, ImportCloneOptions{CWD: t.TempDir()})
+		plan, err := eng.PlanImportClones(context.Background(), nil, ImportCloneOptions{CWD: t.TempDir()})
 		if err != nil {
 			t.Fatalf("plan import clones: %v", err)
 		}
@@ -30,7 +34,7 @@ func TestPlanImportClonesGuardsAndErrors(t *testing.T) {
 
 	t.Run("empty entries returns empty plan", func(t *testing.T) {
 		eng := &Engine{cfg: &config.Config{}, registry: &registry.Registry{}, adapter: &planAdapter{}, classifier: vcs.NewGitErrorClassifier()}
-		plan, err := eng.PlanImportClones(nil, ImportCloneOptions{CWD: t.TempDir()})
+		plan, err := eng.PlanImportClones(context.Background(), nil, ImportCloneOptions{CWD: t.TempDir()})
 		if err != nil {
 			t.Fatalf("plan import clones: %v", err)
 		}
@@ -41,7 +45,7 @@ func TestPlanImportClonesGuardsAndErrors(t *testing.T) {
 
 	t.Run("rejects targets outside cwd", func(t *testing.T) {
 		eng := &Engine{registry: &registry.Registry{}, adapter: &planAdapter{}, classifier: vcs.NewGitErrorClassifier()}
-		_, err := eng.PlanImportClones([]registry.Entry{{
+		_, err := eng.PlanImportClones(context.Background(), []registry.Entry{{
 			RepoID:    "repo",
 			Path:      "ignored",
 			RemoteURL: "git@github.com:org/repo.git",
@@ -63,7 +67,7 @@ func TestPlanImportClonesGuardsAndErrors(t *testing.T) {
 			{RepoID: "a", Path: "a", RemoteURL: "git@github.com:org/a.git", Branch: "main"},
 			{RepoID: "b", Path: "b", RemoteURL: "git@github.com:org/b.git", Branch: "main"},
 		}
-		_, err := eng.PlanImportClones(entries, ImportCloneOptions{
+		_, err := eng.PlanImportClones(context.Background(), entries, ImportCloneOptions{
 			CWD: t.TempDir(),
 			ResolveTargetRelativePath: func(_ registry.Entry, _ string) string {
 				return "same/path"
@@ -81,7 +85,7 @@ func TestPlanImportClonesGuardsAndErrors(t *testing.T) {
 			t.Fatalf("mkdir target: %v", err)
 		}
 		eng := &Engine{registry: &registry.Registry{}, adapter: &planAdapter{}, classifier: vcs.NewGitErrorClassifier()}
-		_, err := eng.PlanImportClones([]registry.Entry{{
+		_, err := eng.PlanImportClones(context.Background(), []registry.Entry{{
 			RepoID:    "repo",
 			Path:      "repo",
 			RemoteURL: "git@github.com:org/repo.git",
@@ -110,7 +114,7 @@ func TestPlanImportClonesSkipsAndSuccess(t *testing.T) {
 		{RepoID: "bundle-missing", Path: "gone/repo", RemoteURL: "git@github.com:org/gone.git", Branch: "main", Status: registry.StatusMissing},
 	}
 
-	plan, err := eng.PlanImportClones(entries, ImportCloneOptions{CWD: cwd})
+	plan, err := eng.PlanImportClones(context.Background(), entries, ImportCloneOptions{CWD: cwd})
 	if err != nil {
 		t.Fatalf("plan import clones: %v", err)
 	}
@@ -142,6 +146,120 @@ func TestPlanImportClonesSkipsAndSuccess(t *testing.T) {
 	}
 }
 
+func TestPlanImportClonesAllowDefaultBranch(t *testing.T) {
+	eng := &Engine{registry: &registry.Registry{}, adapter: &planAdapter{}}
+	plan, err := eng.PlanImportClones(context.Background(), []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: "github.com/org/repo", RemoteURL: "git@github.com:org/repo.git"},
+	}, ImportCloneOptions{CWD: t.TempDir(), AllowDefaultBranch: true})
+	if err != nil {
+		t.Fatalf("plan import clones: %v", err)
+	}
+	if len(plan.Clones) != 1 || len(plan.Skipped) != 0 {
+		t.Fatalf("expected the branchless entry to be cloned, got %+v", plan)
+	}
+}
+
+func TestPlanImportClonesResumesInterruptedImport(t *testing.T) {
+	runGit := func(t *testing.T, args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	// gitInit leaves dir as a clone killed after setting up its remote but
+	// before fetching anything: a repo with the remote and no commits.
+	gitInit := func(t *testing.T, dir, remote string) {
+		t.Helper()
+		runGit(t, "init", dir)
+		runGit(t, "-C", dir, "remote", "add", "origin", remote)
+	}
+	gitClone := func(t *testing.T, dir, remote string) {
+		t.Helper()
+		gitInit(t, dir, remote)
+		runGit(t, "-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init")
+	}
+	newEngine := func() *Engine {
+		return New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(nil), vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
+	}
+	entry := registry.Entry{RepoID: "github.com/org/repo", Path: "github.com/org/repo", RemoteURL: "https://github.com/org/repo.git", Branch: "main"}
+
+	t.Run("already cloned for the expected remote is registered without cloning", func(t *testing.T) {
+		cwd := t.TempDir()
+		target := filepath.Join(cwd, "github.com/org/repo")
+		gitClone(t, target, "git@github.com:org/repo.git")
+		eng := newEngine()
+		plan, err := eng.PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd})
+		if err != nil {
+			t.Fatalf("plan import clones: %v", err)
+		}
+		if len(plan.Clones) != 0 || len(plan.Existing) != 1 || plan.Existing[0].Path != target {
+			t.Fatalf("expected the existing clone to be reused, got %+v", plan)
+		}
+
+		var completed []SyncResult
+		failures, err := eng.ExecuteImportClones(context.Background(), plan, ImportCloneCallbacks{
+			OnComplete: func(result SyncResult) { completed = append(completed, result) },
+		})
+		if err != nil || len(failures) != 0 {
+			t.Fatalf("execute import clones: %v %+v", err, failures)
+		}
+		if len(completed) != 1 || !completed[0].OK || completed[0].Action != "already cloned" {
+			t.Fatalf("unexpected completion results: %+v", completed)
+		}
+		got := eng.registry.FindByRepoID(entry.RepoID)
+		if got == nil || got.Path != target || got.Status != registry.StatusPresent {
+			t.Fatalf("expected entry present at %q, got %+v", target, got)
+		}
+	})
+
+	t.Run("clone of a different remote is a conflict", func(t *testing.T) {
+		cwd := t.TempDir()
+		gitClone(t, filepath.Join(cwd, "github.com/org/repo"), "git@github.com:org/other.git")
+		_, err := newEngine().PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd})
+		if err == nil || !strings.Contains(err.Error(), "import target conflicts detected") {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+	})
+
+	t.Run("partial clone of the expected remote is a conflict unless deleting existing", func(t *testing.T) {
+		cwd := t.TempDir()
+		gitInit(t, filepath.Join(cwd, "github.com/org/repo"), "git@github.com:org/repo.git")
+		_, err := newEngine().PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd})
+		if err == nil || !strings.Contains(err.Error(), "import target conflicts detected") {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+		plan, err := newEngine().PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd, DangerouslyDeleteExisting: true})
+		if err != nil {
+			t.Fatalf("plan import clones: %v", err)
+		}
+		if len(plan.Clones) != 1 || len(plan.Existing) != 0 {
+			t.Fatalf("expected the partial clone to be re-cloned, got %+v", plan)
+		}
+	})
+
+	t.Run("directory that is not a repo is a conflict unless deleting existing", func(t *testing.T) {
+		cwd := t.TempDir()
+		target := filepath.Join(cwd, "github.com/org/repo")
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "partial"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := newEngine().PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd})
+		if err == nil || !strings.Contains(err.Error(), "import target conflicts detected") {
+			t.Fatalf("expected conflict error, got %v", err)
+		}
+		plan, err := newEngine().PlanImportClones(context.Background(), []registry.Entry{entry}, ImportCloneOptions{CWD: cwd, DangerouslyDeleteExisting: true})
+		if err != nil {
+			t.Fatalf("plan import clones: %v", err)
+		}
+		if len(plan.Clones) != 1 || len(plan.Existing) != 0 {
+			t.Fatalf("expected the partial directory to be re-cloned, got %+v", plan)
+		}
+	})
+}
+
 func TestExecuteImportClonesSuccessFailureAndSkips(t *testing.T) {
 	t.Run("successful clone with dangerous delete updates registry and callbacks", func(t *testing.T) {
 		cwd := t.TempDir()
@@ -319,14 +437,94 @@ func TestExecuteImportClonesSuccessFailureAndSkips(t *testing.T) {
 	})
 }
 
+// inFlightCloneAdapter records the most clones it saw running at once.
+type inFlightCloneAdapter struct {
+	planAdapter
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (a *inFlightCloneAdapter) Clone(ctx context.Context, remoteURL, targetPath, branch string, mirror bool) error {
+	n := a.inFlight.Add(1)
+	defer a.inFlight.Add(-1)
+	for {
+		peak := a.maxInFlight.Load()
+		if n <= peak || a.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return a.planAdapter.Clone(ctx, remoteURL, targetPath, branch, mirror)
+}
+
+func TestExecuteImportClonesRunsConcurrently(t *testing.T) {
+	cwd := t.TempDir()
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	adapter := &inFlightCloneAdapter{planAdapter: planAdapter{cloneErrByDir: map[string]error{
+		filepath.Join(cwd, "f"): errors.New("could not resolve host"),
+		filepath.Join(cwd, "b"): errors.New("Permission denied (publickey)"),
+	}}}
+	reg := &registry.Registry{}
+	eng := &Engine{cfg: &config.Config{}, registry: reg, adapter: adapter, classifier: vcs.NewGitErrorClassifier()}
+
+	plan := ImportClonePlan{Concurrency: 3, MaxJobs: 3}
+	for _, name := range names {
+		plan.Clones = append(plan.Clones, ImportCloneTarget{
+			Path:  filepath.Join(cwd, name),
+			Entry: registry.Entry{RepoID: "github.com/org/" + name, RemoteURL: "git@github.com:org/" + name + ".git", Branch: "main"},
+		})
+	}
+
+	var mu sync.Mutex
+	completed := map[string]bool{}
+	failures, err := eng.ExecuteImportClones(context.Background(), plan, ImportCloneCallbacks{
+		OnComplete: func(res SyncResult) {
+			mu.Lock()
+			completed[res.RepoID] = true
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("execute import clones: %v", err)
+	}
+	if len(completed) != len(names) {
+		t.Fatalf("expected a completion callback for every clone, got %v", completed)
+	}
+	if peak := adapter.maxInFlight.Load(); peak < 2 || peak > 3 {
+		t.Fatalf("expected clones to overlap within the limit of 3, peak was %d", peak)
+	}
+	if len(failures) != 2 || failures[0].Path != filepath.Join(cwd, "b") || failures[1].Path != filepath.Join(cwd, "f") {
+		t.Fatalf("expected failures sorted by path, got %+v", failures)
+	}
+	if failures[0].ErrorClass != "auth" || failures[1].ErrorClass != "network" {
+		t.Fatalf("expected classified failures, got %+v", failures)
+	}
+	if len(reg.Entries) != len(names) {
+		t.Fatalf("expected every clone registered, got %+v", reg.Entries)
+	}
+	for i, name := range names {
+		entry := reg.Entries[i]
+		want := registry.StatusPresent
+		if name == "b" || name == "f" {
+			want = registry.StatusMissing
+		}
+		if entry.RepoID != "github.com/org/"+name || entry.Status != want {
+			t.Fatalf("expected registry entry %d to be %s (%s) in plan order, got %+v", i, name, want, entry)
+		}
+	}
+}
+
 func TestImportCloneHelperFunctions(t *testing.T) {
 	t.Run("import clone failure messages", func(t *testing.T) {
 		cases := map[string]string{
 			"auth":           "import-clone-auth",
+			"host_key":       "import-clone-host-key",
 			"network":        "import-clone-network",
 			"timeout":        "import-clone-timeout",
 			"corrupt":        "import-clone-corrupt",
 			"missing_remote": "import-clone-missing-remote",
+			"disk_full":      "import-clone-disk-full",
+			"fs_permission":  "import-clone-fs-permission",
 			"other":          "import-clone-failed",
 		}
 		for class, want := range cases {
@@ -345,15 +543,20 @@ func TestImportCloneHelperFunctions(t *testing.T) {
 			"a": {Path: existingPath, Entry: registry.Entry{RepoID: "a"}},
 			"b": {Path: filepath.Join(t.TempDir(), "missing"), Entry: registry.Entry{RepoID: "b"}},
 		}
-		conflicts := findImportCloneConflicts(targets, map[string]ImportCloneSkip{})
+		conflicts := findImportCloneConflicts(targets, map[string]ImportCloneSkip{}, nil)
 		if len(conflicts) != 1 || conflicts[0].entry.RepoID != "a" {
 			t.Fatalf("unexpected conflicts: %+v", conflicts)
 		}
 
-		skipped := findImportCloneConflicts(targets, map[string]ImportCloneSkip{"a": {Reason: "skip"}})
+		skipped := findImportCloneConflicts(targets, map[string]ImportCloneSkip{"a": {Reason: "skip"}}, nil)
 		if len(skipped) != 0 {
 			t.Fatalf("expected skipped target not to conflict, got %+v", skipped)
 		}
+
+		cloned := findImportCloneConflicts(targets, map[string]ImportCloneSkip{}, map[string]bool{"a": true})
+		if len(cloned) != 0 {
+			t.Fatalf("expected an existing clone not to conflict, got %+v", cloned)
+		}
 	})
 
 	t.Run("ignored import path set", func(t *testing.T) {
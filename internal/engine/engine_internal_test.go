@@ -4,11 +4,17 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/model"
@@ -62,6 +68,103 @@ func TestScanUpdatesRegistry(t *testing.T) {
 	}
 }
 
+func TestScanMarksRenamedRepoAsMoved(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "proj")
+	for _, args := range [][]string{
+		{"init", repo},
+		{"-C", repo, "remote", "add", "origin", "https://github.com/acme/proj.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v %s", args, err, string(out))
+		}
+	}
+
+	reg := &registry.Registry{}
+	eng := New(&config.Config{Exclude: []string{}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+	if _, err := eng.Scan(context.Background(), ScanOptions{Roots: []string{root}}); err != nil {
+		t.Fatalf("first scan failed: %v", err)
+	}
+	renamed := filepath.Join(root, "proj-renamed")
+	if err := os.Rename(repo, renamed); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	statuses, err := eng.Scan(context.Background(), ScanOptions{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("second scan failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Path != renamed {
+		t.Fatalf("expected the renamed repo to be reported, got %+v", statuses)
+	}
+	if len(reg.Entries) != 1 {
+		t.Fatalf("expected the move to keep a single entry, got %+v", reg.Entries)
+	}
+	entry := reg.Entries[0]
+	if entry.Status != registry.StatusMoved || entry.Path != repo || entry.MovedTo != renamed {
+		t.Fatalf("expected entry marked moved from %q to %q, got %+v", repo, renamed, entry)
+	}
+
+	// Scanning again before reconciling keeps the move pending.
+	if _, err := eng.Scan(context.Background(), ScanOptions{Roots: []string{root}}); err != nil {
+		t.Fatalf("third scan failed: %v", err)
+	}
+	if len(reg.Entries) != 1 || reg.Entries[0].Status != registry.StatusMoved || reg.Entries[0].MovedTo != renamed {
+		t.Fatalf("expected the move to stay pending, got %+v", reg.Entries)
+	}
+}
+
+func TestScanPruneRegistryHandlesDeletedRepos(t *testing.T) {
+	root := t.TempDir()
+	kept := filepath.Join(root, "kept")
+	if out, err := exec.Command("git", "init", kept).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v %s", err, string(out))
+	}
+	outside := t.TempDir()
+	newRegistry := func() *registry.Registry {
+		return &registry.Registry{Entries: []registry.Entry{
+			{RepoID: "local:deleted", Path: filepath.Join(root, "deleted"), Status: registry.StatusPresent},
+			{RepoID: "local:elsewhere", Path: filepath.Join(outside, "gone"), Status: registry.StatusPresent},
+			{RepoID: "local:outside", Path: outside, Status: registry.StatusPresent},
+		}}
+	}
+
+	for _, tc := range []struct {
+		mode     PruneRegistryMode
+		wantRepo []string
+	}{
+		{mode: PruneRegistryMark, wantRepo: []string{"local:deleted", "local:elsewhere", "local:outside", "local:" + filepath.ToSlash(kept)}},
+		{mode: PruneRegistryRemove, wantRepo: []string{"local:outside", "local:" + filepath.ToSlash(kept)}},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			reg := newRegistry()
+			eng := New(&config.Config{Exclude: []string{}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+			report, err := eng.ScanWithReport(context.Background(), ScanOptions{Roots: []string{root}, PruneRegistry: tc.mode})
+			if err != nil {
+				t.Fatalf("scan failed: %v", err)
+			}
+			if len(report.Pruned) != 2 || report.Pruned[0].RepoID != "local:deleted" || report.Pruned[1].RepoID != "local:elsewhere" {
+				t.Fatalf("expected the two deleted entries to be pruned, got %+v", report.Pruned)
+			}
+			var got []string
+			for _, entry := range reg.Entries {
+				got = append(got, entry.RepoID)
+				wantStatus := registry.StatusPresent
+				if entry.RepoID == "local:deleted" || entry.RepoID == "local:elsewhere" {
+					wantStatus = registry.StatusMissing
+				}
+				if entry.Status != wantStatus {
+					t.Fatalf("%s: expected status %q, got %q", entry.RepoID, wantStatus, entry.Status)
+				}
+			}
+			slices.Sort(got)
+			slices.Sort(tc.wantRepo)
+			if !slices.Equal(got, tc.wantRepo) {
+				t.Fatalf("expected registry %v, got %v", tc.wantRepo, got)
+			}
+		})
+	}
+}
+
 func TestStatusWorkerPropagatesCheckoutID(t *testing.T) {
 	runner := &testRunner{responses: map[string]testResponse{
 		"/repo-ok:rev-parse --is-bare-repository":    {out: "false"},
@@ -85,7 +188,7 @@ func TestStatusWorkerPropagatesCheckoutID(t *testing.T) {
 		CheckoutID: "checkout-missing",
 		Path:       "/repo-missing",
 		Status:     registry.StatusMissing,
-	}, 0)
+	}, 0, StatusOptions{}, eng.adapter)
 	if missingStatus.CheckoutID != "checkout-missing" {
 		t.Fatalf("expected missing status checkout id propagated, got %q", missingStatus.CheckoutID)
 	}
@@ -95,7 +198,7 @@ func TestStatusWorkerPropagatesCheckoutID(t *testing.T) {
 		CheckoutID: "checkout-error",
 		Path:       "/repo-error",
 		Status:     registry.StatusPresent,
-	}, 0)
+	}, 0, StatusOptions{}, eng.adapter)
 	if errorStatus.CheckoutID != "checkout-error" {
 		t.Fatalf("expected error status checkout id propagated, got %q", errorStatus.CheckoutID)
 	}
@@ -108,12 +211,54 @@ func TestStatusWorkerPropagatesCheckoutID(t *testing.T) {
 		CheckoutID: "checkout-ok",
 		Path:       "/repo-ok",
 		Status:     registry.StatusPresent,
-	}, 0)
+	}, 0, StatusOptions{}, eng.adapter)
 	if okStatus.CheckoutID != "checkout-ok" {
 		t.Fatalf("expected successful status checkout id propagated, got %q", okStatus.CheckoutID)
 	}
 }
 
+func TestScanWithReportKeepsEntriesUnderUnreadableDirectories(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permission bits are not enforced for root")
+	}
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	if out, err := exec.Command("git", "init", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v %s", err, string(out))
+	}
+	locked := filepath.Join(root, "locked")
+	hidden := filepath.Join(locked, "hidden")
+	if err := os.MkdirAll(hidden, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0o755) })
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/hidden", Path: hidden, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(root, "gone"), Status: registry.StatusPresent},
+	}}
+	eng := New(&config.Config{Exclude: []string{}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+	report, err := eng.ScanWithReport(context.Background(), ScanOptions{Roots: []string{root}})
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(report.Repos) != 1 || len(report.WalkErrors) != 1 || report.WalkErrors[0].Path != locked {
+		t.Fatalf("unexpected scan report: %+v", report)
+	}
+	for _, entry := range reg.Entries {
+		want := registry.StatusPresent
+		if entry.RepoID == "github.com/org/gone" {
+			want = registry.StatusMissing
+		}
+		if entry.Status != want {
+			t.Fatalf("expected %s to be %s, got %s", entry.RepoID, want, entry.Status)
+		}
+	}
+}
+
 func TestScanSkipsIgnoredPaths(t *testing.T) {
 	root := t.TempDir()
 	repo := filepath.Join(root, "repo")
@@ -205,17 +350,78 @@ func TestStatusWritesBackRefreshedRepoMetadataSnapshotSerially(t *testing.T) {
 	}
 }
 
+func TestStatusStreamEmitsFilteredResultsThroughCallback(t *testing.T) {
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "present", Path: "/present", Status: registry.StatusPresent},
+		{RepoID: "gone-a", Path: "/gone-a", Status: registry.StatusMissing},
+		{RepoID: "gone-b", Path: "/gone-b", Status: registry.StatusMissing},
+	}}
+	eng := New(&config.Config{}, reg, &planAdapter{}, nil, nil, nil)
+
+	var streamed []string
+	err := eng.StatusStream(context.Background(), StatusOptions{Filter: FilterMissing, Concurrency: 1}, func(repo model.RepoStatus) {
+		streamed = append(streamed, repo.RepoID)
+	})
+	if err != nil {
+		t.Fatalf("status stream failed: %v", err)
+	}
+	sort.Strings(streamed)
+	if strings.Join(streamed, ",") != "gone-a,gone-b" {
+		t.Fatalf("expected only the missing repos to be streamed, got %v", streamed)
+	}
+	if err := eng.StatusStream(context.Background(), StatusOptions{}, nil); err == nil {
+		t.Fatal("expected a nil callback to be rejected")
+	}
+}
+
+func TestStatusStreamWritesBackRepoMetadataWithoutFullResults(t *testing.T) {
+	runner := &testRunner{responses: map[string]testResponse{
+		"/repo-error:rev-parse --is-bare-repository": {out: "false"},
+		"/repo-error:remote":                         {err: errors.New("permission denied")},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{{
+		RepoID:                  "repo-error",
+		Path:                    "/repo-error",
+		Status:                  registry.StatusPresent,
+		RepoMetadataFile:        "/repo-error/.repokeeper-repo.yaml",
+		RepoMetadataFingerprint: "file:/repo-error/.repokeeper-repo.yaml:1:1",
+		RepoMetadata:            &model.RepoMetadata{Name: "Cached"},
+	}}}
+	eng := New(&config.Config{}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+
+	streamed := 0
+	if err := eng.StatusStream(context.Background(), StatusOptions{Filter: FilterAll}, func(model.RepoStatus) { streamed++ }); err != nil {
+		t.Fatalf("status stream failed: %v", err)
+	}
+	if streamed != 1 {
+		t.Fatalf("expected one streamed repo, got %d", streamed)
+	}
+	if reg.Entries[0].RepoMetadataFile != "" || reg.Entries[0].RepoMetadataFingerprint != "" || reg.Entries[0].RepoMetadata != nil {
+		t.Fatalf("expected the streamed run to write back the refreshed metadata snapshot, got %+v", reg.Entries[0])
+	}
+
+	snapshot := metadataSnapshot(model.RepoStatus{
+		RepoID:       "r",
+		Path:         "/r",
+		Worktree:     &model.Worktree{Dirty: true},
+		RepoMetadata: &model.RepoMetadata{Name: "Kept"},
+	})
+	if snapshot.Worktree != nil || snapshot.RepoMetadata == nil || snapshot.RepoID != "r" {
+		t.Fatalf("expected only the write-back fields kept, got %+v", snapshot)
+	}
+}
+
 func TestFilterAndSortHelpers(t *testing.T) {
 	reg := &registry.Registry{
 		Entries: []registry.Entry{{RepoID: "r1", Status: registry.StatusMissing}},
 	}
-	if !filterStatus(FilterMissing, model.RepoStatus{RepoID: "r1"}, reg) {
+	if !filterStatus(FilterMissing, model.RepoStatus{RepoID: "r1"}, reg, nil) {
 		t.Fatal("expected missing filter match")
 	}
-	if !filterStatus(FilterErrors, model.RepoStatus{Error: "boom"}, reg) {
+	if !filterStatus(FilterErrors, model.RepoStatus{Error: "boom"}, reg, nil) {
 		t.Fatal("expected errors filter match")
 	}
-	if !filterStatus(FilterDiverged, model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingDiverged}}, reg) {
+	if !filterStatus(FilterDiverged, model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingDiverged}}, reg, nil) {
 		t.Fatal("expected diverged filter match")
 	}
 	reg = &registry.Registry{
@@ -229,6 +435,7 @@ func TestFilterAndSortHelpers(t *testing.T) {
 		FilterRemoteMismatch,
 		model.RepoStatus{RepoID: "github.com/org/repo", Path: "/repo"},
 		reg,
+		nil,
 	) {
 		t.Fatal("expected remote mismatch filter match")
 	}
@@ -252,6 +459,53 @@ func TestFilterAndSortHelpers(t *testing.T) {
 	}
 }
 
+func TestStaleMetadataFilterComparesRegistryToLiveState(t *testing.T) {
+	reg := &registry.Registry{Entries: []registry.Entry{{
+		RepoID:    "github.com/org/repo",
+		Path:      "/repo",
+		RemoteURL: "git@github.com:org/repo.git",
+		Branch:    "main",
+	}}}
+	live := model.RepoStatus{
+		RepoID:        "github.com/org/repo",
+		Path:          "/repo",
+		PrimaryRemote: "origin",
+		Remotes:       []model.Remote{{Name: "origin", URL: "git@github.com:org/repo.git"}},
+		Head:          model.Head{Branch: "main"},
+	}
+	if filterStatus(FilterStaleMetadata, live, reg, nil) {
+		t.Fatal("did not expect matching registry metadata to be stale")
+	}
+
+	branchMoved := live
+	branchMoved.Head = model.Head{Branch: "trunk"}
+	if !filterStatus(FilterStaleMetadata, branchMoved, reg, nil) {
+		t.Fatal("expected a differing head branch to be stale")
+	}
+
+	detached := live
+	detached.Head = model.Head{Detached: true}
+	if filterStatus(FilterStaleMetadata, detached, reg, nil) {
+		t.Fatal("did not expect a detached head to be compared against the registry branch")
+	}
+
+	urlMoved := live
+	urlMoved.Remotes = []model.Remote{{Name: "origin", URL: "https://github.com/org/repo.git"}}
+	if !filterStatus(FilterStaleMetadata, urlMoved, reg, nil) {
+		t.Fatal("expected a differing remote URL to be stale even when the repo ID matches")
+	}
+	if filterStatus(FilterRemoteMismatch, urlMoved, reg, nil) {
+		t.Fatal("did not expect a same-ID URL change to be a remote mismatch")
+	}
+
+	if filterStatus(FilterStaleMetadata, branchMoved, nil, nil) {
+		t.Fatal("did not expect stale-metadata to match without a registry")
+	}
+	if !filterRequiresInspect(FilterStaleMetadata) {
+		t.Fatal("expected stale-metadata to require a live inspection")
+	}
+}
+
 func TestSyncRuntime(t *testing.T) {
 	eng := New(&config.Config{Defaults: config.Defaults{
 		Concurrency:    3,
@@ -269,6 +523,116 @@ func TestSyncRuntime(t *testing.T) {
 	}
 }
 
+type warnRecorder struct {
+	obs.Logger
+	warnings []string
+}
+
+func (w *warnRecorder) Warnf(format string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestSyncRuntimeClampsConcurrencyAboveCeiling(t *testing.T) {
+	ceiling := 8 * runtime.NumCPU()
+	logger := &warnRecorder{Logger: obs.NopLogger()}
+	eng := New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, logger)
+
+	concurrency, _ := eng.syncRuntime(SyncOptions{Concurrency: ceiling})
+	if concurrency != ceiling || len(logger.warnings) != 0 {
+		t.Fatalf("expected ceiling to pass through unwarned, got %d %v", concurrency, logger.warnings)
+	}
+
+	concurrency, _ = eng.syncRuntime(SyncOptions{Concurrency: ceiling + 5})
+	if concurrency != ceiling {
+		t.Fatalf("expected clamp to %d, got %d", ceiling, concurrency)
+	}
+	_, _ = eng.syncRuntime(SyncOptions{Concurrency: ceiling + 5})
+	if len(logger.warnings) != 1 || !strings.Contains(logger.warnings[0], "--allow-oversubscribe") {
+		t.Fatalf("expected a single oversubscribe warning, got %v", logger.warnings)
+	}
+
+	concurrency, _ = eng.syncRuntime(SyncOptions{Concurrency: ceiling + 5, AllowOversubscribe: true})
+	if concurrency != ceiling+5 {
+		t.Fatalf("expected --allow-oversubscribe to bypass clamp, got %d", concurrency)
+	}
+}
+
+func TestClampConcurrencyAppliesToConfigDefaults(t *testing.T) {
+	ceiling := 8 * runtime.NumCPU()
+	if got, clamped := clampConcurrency(ceiling*2, false); got != ceiling || !clamped {
+		t.Fatalf("expected clamp to %d, got %d (clamped=%v)", ceiling, got, clamped)
+	}
+	if got, clamped := clampConcurrency(ceiling*2, true); got != ceiling*2 || clamped {
+		t.Fatalf("expected bypass, got %d (clamped=%v)", got, clamped)
+	}
+
+	eng := New(&config.Config{Defaults: config.Defaults{Concurrency: ceiling * 2}}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+	if concurrency, _ := eng.syncRuntime(SyncOptions{}); concurrency != ceiling {
+		t.Fatalf("expected config default to be clamped to %d, got %d", ceiling, concurrency)
+	}
+}
+
+func TestMaxJobsClampsExplicitConcurrency(t *testing.T) {
+	eng := New(&config.Config{Defaults: config.Defaults{Concurrency: 8, MaxJobs: 6}}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+
+	if concurrency, _ := eng.syncRuntime(SyncOptions{Concurrency: 16, MaxJobs: 2}); concurrency != 2 {
+		t.Fatalf("expected sync --concurrency 16 to be clamped to --jobs 2, got %d", concurrency)
+	}
+	if concurrency, _ := eng.statusLimits(StatusOptions{Concurrency: 16, MaxJobs: 2}); concurrency != 2 {
+		t.Fatalf("expected status concurrency 16 to be clamped to --jobs 2, got %d", concurrency)
+	}
+	if concurrency, _ := eng.syncRuntime(SyncOptions{Concurrency: 16}); concurrency != 6 {
+		t.Fatalf("expected defaults.max_jobs to cap sync at 6, got %d", concurrency)
+	}
+	if concurrency, _ := eng.statusLimits(StatusOptions{}); concurrency != 6 {
+		t.Fatalf("expected defaults.max_jobs to cap status at 6, got %d", concurrency)
+	}
+	if concurrency, _ := eng.syncRuntime(SyncOptions{Concurrency: 3, MaxJobs: 12}); concurrency != 3 {
+		t.Fatalf("expected concurrency below --jobs to pass through, got %d", concurrency)
+	}
+	if concurrency, _ := eng.syncRuntime(SyncOptions{AllowOversubscribe: true, Concurrency: 16, MaxJobs: 2}); concurrency != 2 {
+		t.Fatalf("expected --allow-oversubscribe to leave the --jobs cap in place, got %d", concurrency)
+	}
+}
+
+func TestMaxJobsCapsOnlyTheBuiltinDefaultWhenUnset(t *testing.T) {
+	eng := New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+	if got := eng.maxJobs(0); got != 0 {
+		t.Fatalf("expected no cap without --jobs or max_jobs, got %d", got)
+	}
+	if got := eng.maxJobs(5); got != 5 {
+		t.Fatalf("expected --jobs to win, got %d", got)
+	}
+	want := min(4, runtime.NumCPU())
+	if concurrency, _ := eng.statusLimits(StatusOptions{}); concurrency != want {
+		t.Fatalf("expected the built-in status default capped to %d, got %d", want, concurrency)
+	}
+	if concurrency, _ := New(nil, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil).syncRuntime(SyncOptions{}); concurrency != min(8, runtime.NumCPU()) {
+		t.Fatalf("expected the built-in sync default capped to min(8, NumCPU), got %d", concurrency)
+	}
+	explicit := 8*runtime.NumCPU() + 5
+	if concurrency, _ := eng.syncRuntime(SyncOptions{Concurrency: explicit, AllowOversubscribe: true}); concurrency != explicit {
+		t.Fatalf("expected an explicit --concurrency with --allow-oversubscribe to pass through, got %d", concurrency)
+	}
+}
+
+func TestScanConcurrencyHonoursJobs(t *testing.T) {
+	eng := New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+	if got := eng.scanConcurrency(0, 2); got != min(2, runtime.NumCPU()) {
+		t.Fatalf("expected --jobs 2 to cap the scan default, got %d", got)
+	}
+	if got := eng.scanConcurrency(6, 2); got != 2 {
+		t.Fatalf("expected --jobs 2 to cap scan --concurrency 6, got %d", got)
+	}
+	if got := eng.scanConcurrency(3, 0); got != 3 {
+		t.Fatalf("expected scan --concurrency to pass through without a cap, got %d", got)
+	}
+	capped := New(&config.Config{Defaults: config.Defaults{MaxJobs: 1}}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+	if got := capped.scanConcurrency(4, 0); got != 1 {
+		t.Fatalf("expected defaults.max_jobs to cap scan, got %d", got)
+	}
+}
+
 func TestPrepareSyncEntryBranches(t *testing.T) {
 	eng := New(&config.Config{Defaults: config.Defaults{MainBranch: "main"}}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
 
@@ -289,6 +653,15 @@ func TestPrepareSyncEntryBranches(t *testing.T) {
 		t.Fatalf("expected missing-filter skip, got queue=%v immediate=%+v", queue, immediate)
 	}
 
+	queue, _, immediate = eng.prepareSyncEntry(context.Background(), present, SyncOptions{Paths: []string{"/other"}}, 0)
+	if queue || immediate != nil {
+		t.Fatalf("expected entry outside Paths to be skipped, got queue=%v immediate=%+v", queue, immediate)
+	}
+	queue, _, _ = eng.prepareSyncEntry(context.Background(), present, SyncOptions{Paths: []string{"/other", "/repo"}}, 0)
+	if !queue {
+		t.Fatal("expected entry listed in Paths to be queued")
+	}
+
 	missing := present
 	missing.Status = registry.StatusMissing
 	queue, _, immediate = eng.prepareSyncEntry(context.Background(), missing, SyncOptions{CheckoutMissing: false}, 0)
@@ -412,7 +785,7 @@ func TestRunSyncRebaseApply(t *testing.T) {
 	entry := registry.Entry{RepoID: "repo", Path: "/repo"}
 	status := &model.RepoStatus{Worktree: &model.Worktree{Dirty: true}}
 
-	res := eng.runSyncRebaseApply(context.Background(), entry, status, true)
+	res := eng.runSyncRebaseApply(context.Background(), entry, status, true, "")
 	if !res.OK || res.Outcome != "stashed_rebased" {
 		t.Fatalf("unexpected rebase apply result: %+v", res)
 	}
@@ -553,6 +926,131 @@ func TestExecuteSyncPlanCloneAction(t *testing.T) {
 	}
 }
 
+func TestRemoteURLFromTemplate(t *testing.T) {
+	const template = "git@{host}:{owner}/{name}.git"
+	cases := []struct {
+		repoID string
+		want   string
+		ok     bool
+	}{
+		{repoID: "github.com/owner/name", want: "git@github.com:owner/name.git", ok: true},
+		{repoID: "gitlab.com/group/sub/name", want: "git@gitlab.com:group/sub/name.git", ok: true},
+		{repoID: "local:/work/scratch"},
+		{repoID: "github.com/name"},
+		{repoID: "localhost/owner/name"},
+		{repoID: "github.com//name"},
+	}
+	for _, tc := range cases {
+		got, ok := remoteURLFromTemplate(template, tc.repoID)
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("remoteURLFromTemplate(%q) = %q, %v; want %q, %v", tc.repoID, got, ok, tc.want, tc.ok)
+		}
+	}
+	if _, ok := remoteURLFromTemplate("", "github.com/owner/name"); ok {
+		t.Fatal("expected empty template to be skipped")
+	}
+}
+
+func TestSyncCheckoutMissingUsesRemoteTemplate(t *testing.T) {
+	runner := &testRunner{responses: map[string]testResponse{
+		":clone --branch main --single-branch git@github.com:org/missing.git /rk-missing-template": {out: ""},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/missing", Path: "/rk-missing-template", Branch: "main", Status: registry.StatusMissing},
+		{RepoID: "local:/rk-missing-local", Path: "/rk-missing-local", Status: registry.StatusMissing},
+	}}
+	eng := New(&config.Config{}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+	opts := SyncOptions{
+		Filter:          FilterMissing,
+		DryRun:          true,
+		ContinueOnError: true,
+		CheckoutMissing: true,
+		RemoteTemplate:  "git@{host}:{owner}/{name}.git",
+	}
+
+	plan, err := eng.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("sync dry-run failed: %v", err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("expected two missing results, got %+v", plan)
+	}
+	byID := make(map[string]SyncResult, len(plan))
+	for _, res := range plan {
+		byID[res.RepoID] = res
+	}
+	planned := byID["github.com/org/missing"]
+	if planned.Outcome != SyncOutcomePlannedCheckout || planned.CloneURL != "git@github.com:org/missing.git" {
+		t.Fatalf("expected templated clone plan, got %+v", planned)
+	}
+	if skipped := byID["local:/rk-missing-local"]; skipped.OK || skipped.Outcome != SyncOutcomeFailedInvalid {
+		t.Fatalf("expected local: entry to be skipped as invalid, got %+v", skipped)
+	}
+
+	opts.DryRun = false
+	results, err := eng.ExecuteSyncPlanWithCallbacks(context.Background(), []SyncResult{planned}, opts, nil, nil)
+	if err != nil {
+		t.Fatalf("execute templated clone failed: %v", err)
+	}
+	if len(results) != 1 || !results[0].OK || results[0].Outcome != SyncOutcomeCheckoutMissing {
+		t.Fatalf("unexpected templated clone result: %+v", results)
+	}
+	if reg.Entries[0].RemoteURL != "git@github.com:org/missing.git" || reg.Entries[0].Status != registry.StatusPresent {
+		t.Fatalf("expected clone URL recorded on entry, got %+v", reg.Entries[0])
+	}
+}
+
+func TestSyncCheckoutMissingCloneDepth(t *testing.T) {
+	runner := &testRunner{responses: map[string]testResponse{
+		":clone --depth 1 --branch main --single-branch git@github.com:org/shallow.git /rk-missing-shallow": {out: ""},
+		":clone --mirror git@github.com:org/mirror.git /rk-missing-mirror":                                  {out: ""},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/shallow", Path: "/rk-missing-shallow", RemoteURL: "git@github.com:org/shallow.git", Branch: "main", Status: registry.StatusMissing},
+		{RepoID: "github.com/org/mirror", Path: "/rk-missing-mirror", RemoteURL: "git@github.com:org/mirror.git", Type: "mirror", Status: registry.StatusMissing},
+	}}
+	eng := New(&config.Config{}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+	opts := SyncOptions{
+		Filter:          FilterMissing,
+		DryRun:          true,
+		ContinueOnError: true,
+		CheckoutMissing: true,
+		CloneDepth:      1,
+	}
+
+	plan, err := eng.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("sync dry-run failed: %v", err)
+	}
+	byID := make(map[string]SyncResult, len(plan))
+	for _, res := range plan {
+		byID[res.RepoID] = res
+	}
+	shallow := byID["github.com/org/shallow"]
+	if shallow.Action != "git clone --depth 1 --branch main --single-branch git@github.com:org/shallow.git /rk-missing-shallow" || shallow.CloneDepth != 1 {
+		t.Fatalf("expected a shallow clone plan, got %+v", shallow)
+	}
+	mirror := byID["github.com/org/mirror"]
+	if strings.Contains(mirror.Action, "--depth") || mirror.CloneDepth != 0 {
+		t.Fatalf("expected the mirror clone to ignore depth, got %+v", mirror)
+	}
+
+	restored, err := NewSavedSyncPlan(plan, time.Now()).SyncResults()
+	if err != nil {
+		t.Fatalf("restore saved plan: %v", err)
+	}
+	opts.DryRun = false
+	results, err := eng.ExecuteSyncPlanWithCallbacks(context.Background(), restored, opts, nil, nil)
+	if err != nil {
+		t.Fatalf("execute clone plan failed: %v", err)
+	}
+	for _, res := range results {
+		if !res.OK || res.Outcome != SyncOutcomeCheckoutMissing {
+			t.Fatalf("unexpected clone result: %+v", res)
+		}
+	}
+}
+
 func TestExecuteSyncPlanWithCallbackInvokesPerResult(t *testing.T) {
 	runner := &testRunner{responses: map[string]testResponse{
 		"/repo:-c fetch.recurseSubmodules=false fetch --all --prune --prune-tags --no-recurse-submodules": {out: ""},
@@ -591,13 +1089,13 @@ func TestExecuteSyncPlanWithCallbackInvokesPerResult(t *testing.T) {
 }
 
 func TestFilterAndLookupEdgeBranches(t *testing.T) {
-	if filterStatus(FilterMissing, model.RepoStatus{RepoID: "r1"}, nil) {
+	if filterStatus(FilterMissing, model.RepoStatus{RepoID: "r1"}, nil, nil) {
 		t.Fatal("expected missing filter false without registry")
 	}
-	if filterStatus(FilterRemoteMismatch, model.RepoStatus{RepoID: "r1"}, nil) {
+	if filterStatus(FilterRemoteMismatch, model.RepoStatus{RepoID: "r1"}, nil, nil) {
 		t.Fatal("expected remote mismatch false without registry")
 	}
-	if filterStatus(FilterKind("unknown"), model.RepoStatus{}, nil) {
+	if filterStatus(FilterKind("unknown"), model.RepoStatus{}, nil, nil) {
 		t.Fatal("expected unknown filter to fail closed (match nothing)")
 	}
 
@@ -780,3 +1278,43 @@ func TestWorkerChannelBufferSize(t *testing.T) {
 		})
 	}
 }
+
+// countingNormalizeAdapter counts NormalizeURL calls per raw URL.
+type countingNormalizeAdapter struct {
+	benchAdapter
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *countingNormalizeAdapter) NormalizeURL(rawURL string) string {
+	c.mu.Lock()
+	c.calls[rawURL]++
+	c.mu.Unlock()
+	return rawURL
+}
+
+func TestStatusNormalizesEachRemoteURLOnce(t *testing.T) {
+	adapter := &countingNormalizeAdapter{calls: map[string]int{}}
+	entries := make([]registry.Entry, 0, 20)
+	for i := 0; i < 20; i++ {
+		entries = append(entries, registry.Entry{
+			RepoID:    "git@github.com:org/repo.git",
+			Path:      fmt.Sprintf("/repos/repo-%d", i),
+			RemoteURL: "git@github.com:org/other.git",
+			Status:    registry.StatusPresent,
+		})
+	}
+	eng := New(&config.Config{}, &registry.Registry{Entries: entries}, adapter, nil, nil, nil)
+
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterRemoteMismatch, Concurrency: 4})
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if len(report.Repos) != 20 {
+		t.Fatalf("expected 20 remote-mismatch repos, got %d", len(report.Repos))
+	}
+	want := map[string]int{"git@github.com:org/repo.git": 1, "git@github.com:org/other.git": 1}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint(want) {
+		t.Fatalf("expected each distinct URL normalized once, got %v", adapter.calls)
+	}
+}
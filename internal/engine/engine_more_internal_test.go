@@ -104,6 +104,11 @@ func TestPullRebaseSkipReasonTable(t *testing.T) {
 		{name: "nil", status: nil, want: "unknown status"},
 		{name: "bare", status: &model.RepoStatus{Bare: true}, want: "bare repository"},
 		{name: "detached", status: &model.RepoStatus{Head: model.Head{Detached: true}}, want: "detached HEAD"},
+		{
+			name:   "rebase in progress",
+			status: &model.RepoStatus{Head: model.Head{Detached: true}, InProgress: model.InProgressRebase},
+			want:   "operation in progress",
+		},
 		{
 			name:   "protected",
 			status: &model.RepoStatus{Head: model.Head{Branch: "main"}},
@@ -211,7 +216,7 @@ func TestPullRebaseSkipReasonTable(t *testing.T) {
 }
 
 func TestFilterStatusDefaultKind(t *testing.T) {
-	if filterStatus(FilterKind("something-new"), model.RepoStatus{}, nil) {
+	if filterStatus(FilterKind("something-new"), model.RepoStatus{}, nil, nil) {
 		t.Fatal("expected unknown filter kind to fail closed (match nothing)")
 	}
 }
@@ -310,46 +315,46 @@ func TestFilterStatusKindsAndSorts(t *testing.T) {
 	ahead := model.RepoStatus{RepoID: "ahead", Path: "/repos/ahead", Tracking: model.Tracking{Status: model.TrackingAhead}}
 	equal := model.RepoStatus{RepoID: "equal", Path: "/repos/equal", Tracking: model.Tracking{Status: model.TrackingEqual}}
 
-	if !filterStatus(FilterAll, clean, reg) {
+	if !filterStatus(FilterAll, clean, reg, nil) {
 		t.Fatal("expected all filter to include repo")
 	}
-	if !filterStatus(FilterErrors, dirty, reg) {
+	if !filterStatus(FilterErrors, dirty, reg, nil) {
 		t.Fatal("expected errors filter to include repo with error")
 	}
-	if !filterStatus(FilterDirty, dirty, reg) {
+	if !filterStatus(FilterDirty, dirty, reg, nil) {
 		t.Fatal("expected dirty filter to include dirty repo")
 	}
-	if !filterStatus(FilterClean, clean, reg) {
+	if !filterStatus(FilterClean, clean, reg, nil) {
 		t.Fatal("expected clean filter to include clean repo")
 	}
-	if !filterStatus(FilterMissing, missing, reg) {
+	if !filterStatus(FilterMissing, missing, reg, nil) {
 		t.Fatal("expected missing filter to include missing repo")
 	}
-	if !filterStatus(FilterGone, gone, reg) {
+	if !filterStatus(FilterGone, gone, reg, nil) {
 		t.Fatal("expected gone filter to include gone repo")
 	}
-	if !filterStatus(FilterDiverged, dirty, reg) {
+	if !filterStatus(FilterDiverged, dirty, reg, nil) {
 		t.Fatal("expected diverged filter to include diverged repo")
 	}
-	if !filterStatus(FilterRemoteMismatch, dirty, reg) {
+	if !filterStatus(FilterRemoteMismatch, dirty, reg, nil) {
 		t.Fatal("expected remote mismatch filter to include mismatched repo")
 	}
-	if !filterStatus(FilterBehind, behind, reg) {
+	if !filterStatus(FilterBehind, behind, reg, nil) {
 		t.Fatal("expected behind filter to include behind repo")
 	}
-	if filterStatus(FilterBehind, ahead, reg) {
+	if filterStatus(FilterBehind, ahead, reg, nil) {
 		t.Fatal("expected behind filter to exclude non-behind repo")
 	}
-	if !filterStatus(FilterAhead, ahead, reg) {
+	if !filterStatus(FilterAhead, ahead, reg, nil) {
 		t.Fatal("expected ahead filter to include ahead repo")
 	}
-	if filterStatus(FilterAhead, behind, reg) {
+	if filterStatus(FilterAhead, behind, reg, nil) {
 		t.Fatal("expected ahead filter to exclude non-ahead repo")
 	}
-	if !filterStatus(FilterEqual, equal, reg) {
+	if !filterStatus(FilterEqual, equal, reg, nil) {
 		t.Fatal("expected equal filter to include equal repo")
 	}
-	if filterStatus(FilterEqual, behind, reg) {
+	if filterStatus(FilterEqual, behind, reg, nil) {
 		t.Fatal("expected equal filter to exclude non-equal repo")
 	}
 
@@ -462,10 +467,13 @@ func TestSyncFailureMessageFetchClasses(t *testing.T) {
 		want  string
 	}{
 		{class: "auth", want: SyncErrorFetchAuth},
+		{class: "host_key", want: SyncErrorFetchHostKey},
 		{class: "network", want: SyncErrorFetchNetwork},
 		{class: "timeout", want: SyncErrorFetchTimeout},
 		{class: "corrupt", want: SyncErrorFetchCorrupt},
 		{class: "missing_remote", want: SyncErrorFetchMissingRemote},
+		{class: "disk_full", want: SyncErrorFetchDiskFull},
+		{class: "fs_permission", want: SyncErrorFetchFSPermission},
 		{class: "unknown", want: SyncErrorFetchFailed},
 	}
 	for _, tc := range cases {
@@ -484,3 +492,110 @@ func TestNewInitializesDefaultAdapter(t *testing.T) {
 		t.Fatal("expected engine.New to set default adapter when nil")
 	}
 }
+
+func TestFetchRepoFetchesThenReinspectsOneEntry(t *testing.T) {
+	adapter := &planAdapter{fetchErrByDir: map[string]error{"/repos/offline": errors.New("could not resolve host")}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/repos/a", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/b", Path: "/repos/b", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/offline", Path: "/repos/offline", Status: registry.StatusPresent},
+	}}
+	eng := New(&config.Config{}, reg, adapter, vcs.NewGitErrorClassifier(), nil, nil)
+
+	status, err := eng.FetchRepo(context.Background(), reg.Entries[0], 5)
+	if err != nil {
+		t.Fatalf("fetch repo: %v", err)
+	}
+	if status == nil || status.Path != "/repos/a" {
+		t.Fatalf("expected the refreshed status of /repos/a, got %+v", status)
+	}
+	if len(adapter.calls) != 1 || adapter.calls[0] != "fetch:/repos/a" {
+		t.Fatalf("expected only /repos/a to be fetched, got %v", adapter.calls)
+	}
+
+	status, err = eng.FetchRepo(context.Background(), reg.Entries[2], 5)
+	if err == nil || status != nil {
+		t.Fatalf("expected the fetch failure without a status, got %+v, %v", status, err)
+	}
+	if class := vcs.NewGitErrorClassifier().ClassifyError(err); class != "network" {
+		t.Fatalf("expected a network error class, got %q", class)
+	}
+}
+
+func TestExplainFilterReasons(t *testing.T) {
+	two, three := 2, 3
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/moved", Path: "/repos/moved", Branch: "main"},
+		{RepoID: "github.com/org/new", Path: "/repos/renamed", RemoteURL: "git@github.com:org/old.git"},
+		{RepoID: "missing", Path: "/repos/missing", Status: registry.StatusMissing},
+	}}
+	for _, tc := range []struct {
+		name   string
+		kind   FilterKind
+		status model.RepoStatus
+		want   string
+	}{
+		{
+			name:   "diverged",
+			kind:   FilterDiverged,
+			status: model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingDiverged, Ahead: &two, Behind: &three}},
+			want:   "diverged: 2 ahead, 3 behind",
+		},
+		{
+			name:   "behind",
+			kind:   FilterBehind,
+			status: model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main", Behind: &three}},
+			want:   "behind: 3 behind upstream origin/main",
+		},
+		{
+			name:   "gone without upstream name",
+			kind:   FilterGone,
+			status: model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingGone}},
+			want:   "gone: upstream no longer exists",
+		},
+		{
+			name:   "dirty",
+			kind:   FilterDirty,
+			status: model.RepoStatus{Worktree: &model.Worktree{Dirty: true, Staged: 1, Untracked: 2}},
+			want:   "dirty: 1 staged, 0 unstaged, 2 untracked",
+		},
+		{
+			name:   "missing",
+			kind:   FilterMissing,
+			status: model.RepoStatus{RepoID: "missing", Path: "/repos/missing"},
+			want:   "missing: path not found on disk",
+		},
+		{
+			name:   "remote mismatch",
+			kind:   FilterRemoteMismatch,
+			status: model.RepoStatus{RepoID: "github.com/org/new", Path: "/repos/renamed"},
+			want:   "remote mismatch: registry has git@github.com:org/old.git, live remote is github.com/org/new",
+		},
+		{
+			name:   "stale branch",
+			kind:   FilterStaleMetadata,
+			status: model.RepoStatus{RepoID: "github.com/org/moved", Path: "/repos/moved", Head: model.Head{Branch: "dev"}},
+			want:   "stale metadata: registry branch main, live branch dev",
+		},
+		{
+			name:   "errors prefer the class",
+			kind:   FilterErrors,
+			status: model.RepoStatus{Error: "fatal: could not read", ErrorClass: "auth"},
+			want:   "error: auth",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			matched, reason := ExplainFilter(tc.kind, tc.status, reg, nil)
+			if !matched || reason != tc.want {
+				t.Fatalf("expected match with reason %q, got %v %q", tc.want, matched, reason)
+			}
+		})
+	}
+
+	if matched, reason := ExplainFilter(FilterAll, model.RepoStatus{}, reg, nil); !matched || reason != "" {
+		t.Fatalf("expected the all filter to match without a reason, got %v %q", matched, reason)
+	}
+	if matched, reason := ExplainFilter(FilterDiverged, model.RepoStatus{Tracking: model.Tracking{Status: model.TrackingEqual}}, reg, nil); matched || reason != "" {
+		t.Fatalf("expected no match and no reason, got %v %q", matched, reason)
+	}
+}
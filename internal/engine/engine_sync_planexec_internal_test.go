@@ -3,7 +3,10 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/model"
@@ -27,6 +30,207 @@ func (a *dirtyBehindAdapter) TrackingStatus(context.Context, string) (model.Trac
 	return model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main"}, nil
 }
 
+// shallowAdapter reports the dirs in shallow as shallow clones and records
+// --deepen fetches alongside plain ones.
+type shallowAdapter struct {
+	*planAdapter
+	shallow map[string]bool
+}
+
+func (a *shallowAdapter) IsShallow(_ context.Context, dir string) (bool, error) {
+	return a.shallow[dir], nil
+}
+
+func (a *shallowAdapter) FetchWithOptions(ctx context.Context, dir string, opts vcs.FetchOptions) (vcs.FetchResult, error) {
+	if opts.Depth <= 0 {
+		return vcs.FetchResult{}, a.planAdapter.Fetch(ctx, dir)
+	}
+	a.mu.Lock()
+	a.calls = append(a.calls, fmt.Sprintf("fetch-deepen-%d:%s", opts.Depth, dir))
+	a.mu.Unlock()
+	return vcs.FetchResult{}, nil
+}
+
+// inProgressAdapter reports a clean branch behind its upstream with op left
+// unfinished in the worktree.
+type inProgressAdapter struct {
+	*planAdapter
+	op string
+}
+
+func (a *inProgressAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main"}, nil
+}
+
+func (a *inProgressAdapter) Head(context.Context, string) (model.Head, error) {
+	return model.Head{Branch: "main"}, nil
+}
+
+func (a *inProgressAdapter) InProgressOperation(context.Context, string) (string, error) {
+	return a.op, nil
+}
+
+// renamedDefaultAdapter reports a branch tracking upstream on origin, whose
+// HEAD points at remoteDefault, as after a host renames its default branch.
+// recorded is the locally recorded origin/HEAD, which UpdateRemoteHead
+// refreshes from remoteDefault; liveLookups counts live HEAD queries.
+type renamedDefaultAdapter struct {
+	*planAdapter
+	upstream      string
+	tracking      model.TrackingStatus
+	remoteDefault string
+	recorded      string
+	liveLookups   int
+}
+
+func (a *renamedDefaultAdapter) Remotes(context.Context, string) ([]model.Remote, error) {
+	return []model.Remote{{Name: "origin", URL: "git@github.com:org/repo.git"}}, nil
+}
+
+func (a *renamedDefaultAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: a.tracking, Upstream: a.upstream}, nil
+}
+
+func (a *renamedDefaultAdapter) RemoteHeadBranch(_ context.Context, _, remote string) (string, error) {
+	a.mu.Lock()
+	a.liveLookups++
+	a.mu.Unlock()
+	if remote != "origin" || a.remoteDefault == "" {
+		return "", fmt.Errorf("remote unreachable")
+	}
+	return a.remoteDefault, nil
+}
+
+func (a *renamedDefaultAdapter) RemoteDefaultBranch(_ context.Context, _, remote string) (string, error) {
+	if remote != "origin" || a.recorded == "" {
+		return "", fmt.Errorf("origin/HEAD not recorded")
+	}
+	return a.recorded, nil
+}
+
+func (a *renamedDefaultAdapter) UpdateRemoteHead(_ context.Context, dir, remote string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, "set-head:"+dir+":"+remote)
+	if remote != "origin" || a.remoteDefault == "" {
+		return fmt.Errorf("remote unreachable")
+	}
+	a.recorded = a.remoteDefault
+	return nil
+}
+
+// lfsAdapter reports the dirs in lfs as LFS repos and records LFS probes and
+// fetches.
+type lfsAdapter struct {
+	*planAdapter
+	lfs      map[string]bool
+	lfsErr   error
+	probeLog []string
+}
+
+func (a *lfsAdapter) HasLFS(_ context.Context, dir string) (bool, error) {
+	a.mu.Lock()
+	a.probeLog = append(a.probeLog, dir)
+	a.mu.Unlock()
+	return a.lfs[dir], nil
+}
+
+func (a *lfsAdapter) LFSFetch(_ context.Context, dir string) error {
+	a.mu.Lock()
+	a.calls = append(a.calls, "lfs-fetch:"+dir)
+	a.mu.Unlock()
+	return a.lfsErr
+}
+
+// submoduleAdapter reports a clean branch behind its upstream whose repo has
+// submodules, and records submodule updates.
+type submoduleAdapter struct {
+	*planAdapter
+	hasSubmodules bool
+	updateErr     error
+}
+
+func (a *submoduleAdapter) Head(context.Context, string) (model.Head, error) {
+	return model.Head{Branch: "main"}, nil
+}
+
+func (a *submoduleAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: model.TrackingBehind, Upstream: "origin/main"}, nil
+}
+
+func (a *submoduleAdapter) HasSubmodules(context.Context, string) (bool, error) {
+	return a.hasSubmodules, nil
+}
+
+func (a *submoduleAdapter) SubmoduleUpdate(_ context.Context, dir string) error {
+	a.mu.Lock()
+	a.calls = append(a.calls, "submodule-update:"+dir)
+	a.mu.Unlock()
+	return a.updateErr
+}
+
+// mirrorAdapter records mirror updates alongside the plan adapter's calls.
+type mirrorAdapter struct {
+	*planAdapter
+	mirrorErr error
+}
+
+func (a *mirrorAdapter) MirrorUpdate(_ context.Context, dir string) error {
+	a.mu.Lock()
+	a.calls = append(a.calls, "mirror-update:"+dir)
+	a.mu.Unlock()
+	return a.mirrorErr
+}
+
+// slowFetchAdapter delays every fetch so sync durations are measurable.
+type slowFetchAdapter struct {
+	*planAdapter
+	delay time.Duration
+}
+
+func (a *slowFetchAdapter) Fetch(ctx context.Context, dir string) error {
+	time.Sleep(a.delay)
+	return a.planAdapter.Fetch(ctx, dir)
+}
+
+// namedRemoteAdapter lists the remotes in remotes for every dir and records
+// single-remote fetches.
+type namedRemoteAdapter struct {
+	*planAdapter
+	remotes map[string][]model.Remote
+}
+
+func (a *namedRemoteAdapter) Remotes(_ context.Context, dir string) ([]model.Remote, error) {
+	return a.remotes[dir], nil
+}
+
+func (a *namedRemoteAdapter) FetchWithOptions(ctx context.Context, dir string, opts vcs.FetchOptions) (vcs.FetchResult, error) {
+	if opts.Remote == "" {
+		return vcs.FetchResult{}, a.planAdapter.Fetch(ctx, dir)
+	}
+	a.mu.Lock()
+	a.calls = append(a.calls, fmt.Sprintf("fetch-remote-%s-%d:%s", opts.Remote, opts.Depth, dir))
+	a.mu.Unlock()
+	return vcs.FetchResult{}, nil
+}
+
+// optionFetchAdapter records the options each OptionFetcher fetch ran with and
+// reports pruned tags from tagsPruned.
+type optionFetchAdapter struct {
+	*planAdapter
+	tagsPruned int
+}
+
+func (a *optionFetchAdapter) FetchWithOptions(_ context.Context, dir string, opts vcs.FetchOptions) (vcs.FetchResult, error) {
+	a.mu.Lock()
+	a.calls = append(a.calls, fmt.Sprintf("fetch-options-%s-%d-%t:%s", opts.Remote, opts.Depth, opts.NoPruneTags, dir))
+	a.mu.Unlock()
+	if opts.NoPruneTags {
+		return vcs.FetchResult{}, nil
+	}
+	return vcs.FetchResult{TagsPruned: a.tagsPruned}, nil
+}
+
 func newPlanExecEngine(adapter vcs.Adapter) *Engine {
 	return &Engine{
 		cfg:        &config.Config{},
@@ -154,6 +358,45 @@ func TestUpdateLocalRebaseDirtyStashesInExecutePath(t *testing.T) {
 	}
 }
 
+// stashMessageAdapter records the message each stash push was given.
+type stashMessageAdapter struct {
+	*dirtyBehindAdapter
+	messages []string
+}
+
+func (a *stashMessageAdapter) StashPush(ctx context.Context, dir, message string) (bool, error) {
+	a.messages = append(a.messages, message)
+	return a.dirtyBehindAdapter.StashPush(ctx, dir, message)
+}
+
+// A configured stash message with spaces and quotes reaches git verbatim and is
+// quoted in the displayed action; the pop still follows the created stash.
+func TestRebaseDirtyUsesConfiguredStashMessage(t *testing.T) {
+	const message = `audit: "pre-rebase" stash for ops`
+	adapter := &stashMessageAdapter{dirtyBehindAdapter: &dirtyBehindAdapter{planAdapter: &planAdapter{stashCreated: true}}}
+	eng := newPlanExecEngine(adapter)
+	eng.cfg.Defaults.StashMessage = message
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+
+	plan, executed := eng.planAndExecute(t, entry, SyncOptions{UpdateLocal: true, RebaseDirty: true})
+
+	if want := `git stash push -u -m "audit: \"pre-rebase\" stash for ops"`; !strings.Contains(plan.Action, want) {
+		t.Fatalf("expected action to contain %s, got %q", want, plan.Action)
+	}
+	if len(adapter.messages) != 1 || adapter.messages[0] != message {
+		t.Fatalf("expected stash push with configured message, got %q", adapter.messages)
+	}
+	if !strings.Contains(strings.Join(adapter.calls, ","), "stash-pop:/repo") || executed.Outcome != SyncOutcomeStashedRebased {
+		t.Fatalf("expected stash pop after rebase, got calls %v outcome %q", adapter.calls, executed.Outcome)
+	}
+
+	eng.cfg.Defaults.StashMessage = ""
+	result := eng.runSyncRebaseApply(context.Background(), entry, &model.RepoStatus{Worktree: &model.Worktree{Dirty: true}}, true, "")
+	if !strings.Contains(result.Action, `"repokeeper: pre-rebase stash"`) || adapter.messages[1] != preRebaseStashMessage {
+		t.Fatalf("expected default stash message when unset, got action %q messages %q", result.Action, adapter.messages)
+	}
+}
+
 // Finding 5: ApplyRemoteMismatchPlans must use the engine's injected adapter, not
 // a hardcoded git adapter, so custom/test adapters and --vcs git,hg are honored.
 func TestApplyRemoteMismatchPlansUsesInjectedAdapter(t *testing.T) {
@@ -234,7 +477,7 @@ func TestExecutePlannedSyncItemEmptyStepsFailsInvalid(t *testing.T) {
 	eng := newPlanExecEngine(adapter)
 
 	item := SyncResult{Path: "/repo", Planned: true, Outcome: SyncOutcomeFetched}
-	got := eng.executePlannedSyncItem(context.Background(), item)
+	got := eng.executePlannedSyncItem(context.Background(), item, syncRetryPolicy{})
 
 	if got.OK {
 		t.Fatalf("expected empty-steps item to fail, got OK=true (outcome=%v)", got.Outcome)
@@ -255,7 +498,7 @@ func TestExecutePlannedNonCloneUnknownStepFailsInvalid(t *testing.T) {
 	eng := newPlanExecEngine(adapter)
 
 	item := SyncResult{Path: "/repo", Planned: true, steps: []syncStep{syncStep("bogus")}}
-	got := eng.executePlannedSyncItem(context.Background(), item)
+	got := eng.executePlannedSyncItem(context.Background(), item, syncRetryPolicy{})
 
 	if got.OK {
 		t.Fatalf("expected unknown-step item to fail, got OK=true (outcome=%v)", got.Outcome)
@@ -267,3 +510,551 @@ func TestExecutePlannedNonCloneUnknownStepFailsInvalid(t *testing.T) {
 		t.Fatalf("expected no adapter calls before hitting the unknown step, got %v", adapter.calls)
 	}
 }
+
+// --deepen only changes the fetch for shallow clones; full clones keep the
+// plain fetch in both the plan action and the executed step.
+func TestSyncDeepenOnlyFetchesShallowReposWithDeepen(t *testing.T) {
+	adapter := &shallowAdapter{planAdapter: &planAdapter{}, shallow: map[string]bool{"/shallow": true}}
+	eng := newPlanExecEngine(adapter)
+
+	shallowPlan, shallowRun := eng.planAndExecute(t, registry.Entry{RepoID: "shallow", Path: "/shallow", Status: registry.StatusPresent}, SyncOptions{Deepen: 5})
+	if shallowPlan.Deepen != 5 || !strings.Contains(shallowPlan.Action, "--deepen 5") {
+		t.Fatalf("expected shallow plan to deepen by 5, got deepen=%d action=%q", shallowPlan.Deepen, shallowPlan.Action)
+	}
+	if !shallowRun.OK {
+		t.Fatalf("expected shallow sync to succeed: %+v", shallowRun)
+	}
+
+	fullPlan, fullRun := eng.planAndExecute(t, registry.Entry{RepoID: "full", Path: "/full", Status: registry.StatusPresent}, SyncOptions{Deepen: 5})
+	if fullPlan.Deepen != 0 || strings.Contains(fullPlan.Action, "--deepen") {
+		t.Fatalf("expected full clone plan to fetch normally, got deepen=%d action=%q", fullPlan.Deepen, fullPlan.Action)
+	}
+	if !fullRun.OK {
+		t.Fatalf("expected full clone sync to succeed: %+v", fullRun)
+	}
+
+	want := []string{"fetch-deepen-5:/shallow", "fetch:/full"}
+	if strings.Join(adapter.calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected calls %v, got %v", want, adapter.calls)
+	}
+}
+
+func TestStatusRecordsShallowStateOnRegistryEntries(t *testing.T) {
+	adapter := &shallowAdapter{planAdapter: &planAdapter{}, shallow: map[string]bool{"/shallow": true}}
+	eng := newPlanExecEngine(adapter)
+	eng.registry.Entries = []registry.Entry{
+		{RepoID: "local:/shallow", Path: "/shallow", Status: registry.StatusPresent},
+		{RepoID: "local:/full", Path: "/full", Status: registry.StatusPresent, Shallow: true},
+	}
+
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterDirty})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(report.Repos) != 0 {
+		t.Fatalf("expected the dirty filter to reject clean repos, got %+v", report.Repos)
+	}
+	for _, entry := range eng.registry.Entries {
+		if want := entry.Path == "/shallow"; entry.Shallow != want {
+			t.Fatalf("expected %s shallow=%t, got %t", entry.Path, want, entry.Shallow)
+		}
+	}
+}
+
+func TestInspectRepoReportsInProgressOperationAndSkipsRebase(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		op   string
+		want string
+	}{
+		{name: "rebase", op: model.InProgressRebase, want: SyncReasonOperationInProgress},
+		{name: "merge", op: model.InProgressMerge, want: SyncReasonOperationInProgress},
+		{name: "bisect", op: model.InProgressBisect, want: SyncReasonOperationInProgress},
+		{name: "none", op: "", want: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := newPlanExecEngine(&inProgressAdapter{planAdapter: &planAdapter{}, op: tc.op})
+			status, err := eng.InspectRepo(context.Background(), "/repo")
+			if err != nil {
+				t.Fatalf("inspect: %v", err)
+			}
+			if status.InProgress != tc.op {
+				t.Fatalf("expected in-progress %q, got %q", tc.op, status.InProgress)
+			}
+			if got := pullRebaseSkipReason(status, PullRebasePolicyOptions{}); got != tc.want {
+				t.Fatalf("expected skip reason %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSyncFetchRemoteFetchesOnlyThatRemoteAndSkipsReposWithoutIt(t *testing.T) {
+	adapter := &namedRemoteAdapter{planAdapter: &planAdapter{}, remotes: map[string][]model.Remote{
+		"/both":   {{Name: "origin", URL: "https://example.com/a.git"}, {Name: "upstream", URL: "https://example.com/b.git"}},
+		"/origin": {{Name: "origin", URL: "https://example.com/c.git"}},
+	}}
+	eng := newPlanExecEngine(adapter)
+
+	plan, run := eng.planAndExecute(t, registry.Entry{RepoID: "both", Path: "/both", Status: registry.StatusPresent}, SyncOptions{FetchRemote: "upstream"})
+	if plan.FetchRemote != "upstream" || plan.Action != "git fetch upstream --prune --prune-tags --no-recurse-submodules" {
+		t.Fatalf("expected a named-remote fetch plan, got remote=%q action=%q", plan.FetchRemote, plan.Action)
+	}
+	if !run.OK {
+		t.Fatalf("expected named-remote sync to succeed: %+v", run)
+	}
+
+	skipped := eng.runSyncDryRun(context.Background(), registry.Entry{RepoID: "origin", Path: "/origin", Status: registry.StatusPresent}, SyncOptions{DryRun: true, FetchRemote: "upstream"}, nil)
+	if skipped.Outcome != SyncOutcomeSkipped || !skipped.OK || skipped.Error != SyncErrorSkippedNoRemotePrefix+`remote "upstream" is not configured` {
+		t.Fatalf("expected a skip for the missing remote, got %+v", skipped)
+	}
+
+	_, allRun := eng.planAndExecute(t, registry.Entry{RepoID: "origin", Path: "/origin", Status: registry.StatusPresent}, SyncOptions{})
+	if !allRun.OK {
+		t.Fatalf("expected default sync to succeed: %+v", allRun)
+	}
+
+	want := []string{"fetch-remote-upstream-0:/both", "fetch:/origin"}
+	if strings.Join(adapter.calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected calls %v, got %v", want, adapter.calls)
+	}
+}
+
+func TestSyncPruneTagsKeepsPlanAndExecutionInStep(t *testing.T) {
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+	for _, pruneTags := range []bool{true, false} {
+		adapter := &optionFetchAdapter{planAdapter: &planAdapter{}, tagsPruned: 2}
+		eng := newPlanExecEngine(adapter)
+
+		plan, run := eng.planAndExecute(t, entry, SyncOptions{NoPruneTags: !pruneTags})
+		applied := eng.runSyncApply(context.Background(), entry, SyncOptions{NoPruneTags: !pruneTags}, nil)
+		want := vcs.GitFetchAction("", pruneTags)
+		if plan.Action != want || run.Action != want {
+			t.Fatalf("prune-tags=%t: expected plan and execution action %q, got %q and %q", pruneTags, want, plan.Action, run.Action)
+		}
+		if plan.NoPruneTags == pruneTags {
+			t.Fatalf("prune-tags=%t: unexpected NoPruneTags on plan %+v", pruneTags, plan)
+		}
+		wantCall := fmt.Sprintf("fetch-options--0-%t:/repo", !pruneTags)
+		for i, call := range adapter.calls {
+			if call != wantCall {
+				t.Fatalf("prune-tags=%t: call %d was %q, want %q", pruneTags, i, call, wantCall)
+			}
+		}
+		wantPruned := 0
+		if pruneTags {
+			wantPruned = 2
+		}
+		if run.TagsPruned != wantPruned || applied.TagsPruned != wantPruned {
+			t.Fatalf("prune-tags=%t: expected %d pruned tags, got %d (plan) and %d (apply)", pruneTags, wantPruned, run.TagsPruned, applied.TagsPruned)
+		}
+	}
+	if got := vcs.GitFetchAction("upstream", false); got != "git fetch upstream --prune --no-recurse-submodules" {
+		t.Fatalf("unexpected named-remote action without tag pruning: %q", got)
+	}
+}
+
+// divergedBranchAdapter reports a clean branch that has diverged from its
+// upstream and records backup branch creation alongside the other calls.
+type divergedBranchAdapter struct {
+	*planAdapter
+}
+
+func (a *divergedBranchAdapter) Head(context.Context, string) (model.Head, error) {
+	return model.Head{Branch: "feature"}, nil
+}
+
+func (a *divergedBranchAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: model.TrackingDiverged, Upstream: "origin/feature"}, nil
+}
+
+func (a *divergedBranchAdapter) CreateBranch(_ context.Context, dir, name string) error {
+	a.mu.Lock()
+	a.calls = append(a.calls, "create-branch-"+name+":"+dir)
+	a.mu.Unlock()
+	return nil
+}
+
+func TestSyncBackupBranchIsCreatedBeforeRebase(t *testing.T) {
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+	opts := SyncOptions{UpdateLocal: true, Force: true, BackupBranch: "backup/{branch}-{timestamp}"}
+
+	adapter := &divergedBranchAdapter{planAdapter: &planAdapter{}}
+	eng := newPlanExecEngine(adapter)
+	plan, run := eng.planAndExecute(t, entry, opts)
+	if got := fmt.Sprint(plan.steps); got != "[fetch backup_branch pull_rebase]" {
+		t.Fatalf("expected the backup step between fetch and rebase, got %s", got)
+	}
+	if !strings.HasPrefix(plan.BackupBranch, "backup/feature-") || strings.Contains(plan.BackupBranch, "{") {
+		t.Fatalf("expected an expanded backup branch name, got %q", plan.BackupBranch)
+	}
+	if !strings.Contains(plan.Action, backupBranchAction(plan.BackupBranch)+" && git pull --rebase") {
+		t.Fatalf("expected the backup in the planned action, got %q", plan.Action)
+	}
+	want := []string{"fetch:/repo", "create-branch-" + plan.BackupBranch + ":/repo", "pull:/repo"}
+	if strings.Join(adapter.calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected calls %v, got %v", want, adapter.calls)
+	}
+	if !run.OK || run.Outcome != SyncOutcomeRebased || run.BackupBranch != plan.BackupBranch {
+		t.Fatalf("expected a rebase recording the backup branch, got %+v", run)
+	}
+
+	direct := &divergedBranchAdapter{planAdapter: &planAdapter{}}
+	applied := newPlanExecEngine(direct).runSyncApply(context.Background(), entry, opts, nil)
+	if !applied.OK || !strings.HasPrefix(applied.BackupBranch, "backup/feature-") {
+		t.Fatalf("expected the direct apply path to record the backup branch, got %+v", applied)
+	}
+	if len(direct.calls) != 3 || !strings.HasPrefix(direct.calls[1], "create-branch-") || direct.calls[2] != "pull:/repo" {
+		t.Fatalf("expected the backup branch before the rebase on the direct path, got %v", direct.calls)
+	}
+
+	behind := &dirtyBehindAdapter{planAdapter: &planAdapter{}}
+	opts.RebaseDirty = true
+	plan, _ = newPlanExecEngine(behind).planAndExecute(t, entry, opts)
+	if plan.BackupBranch != "" || strings.Contains(fmt.Sprint(plan.steps), string(syncStepBackupBranch)) {
+		t.Fatalf("expected no backup for a behind-only rebase, got %+v", plan)
+	}
+}
+
+// --autostash-all wraps the whole plan in a stash push and pop, so a dirty
+// worktree no longer blocks the local update and no rebase-dirty stash is added.
+func TestAutostashAllStashesAroundSyncAndPops(t *testing.T) {
+	adapter := &dirtyBehindAdapter{planAdapter: &planAdapter{stashCreated: true}}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+
+	plan, executed := eng.planAndExecute(t, entry, SyncOptions{Filter: FilterDirty, UpdateLocal: true, AutostashAll: true})
+
+	wantSteps := []syncStep{syncStepAutostash, syncStepFetch, syncStepPullRebase, syncStepAutostashPop}
+	if fmt.Sprint(plan.steps) != fmt.Sprint(wantSteps) {
+		t.Fatalf("expected autostash steps %v, got %v", wantSteps, plan.steps)
+	}
+	if !strings.HasPrefix(plan.Action, `git stash push -u -m "repokeeper: autostash" && `) || !strings.HasSuffix(plan.Action, " && git stash pop") {
+		t.Fatalf("expected action wrapped in stash push/pop, got %q", plan.Action)
+	}
+	wantCalls := []string{"stash-push:/repo", "fetch:/repo", "pull:/repo", "stash-pop:/repo"}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint(wantCalls) {
+		t.Fatalf("expected calls %v, got %v", wantCalls, adapter.calls)
+	}
+	if !executed.OK || executed.Outcome != SyncOutcomeRebased || !executed.Autostashed || !executed.AutostashRestored || executed.Warning != "" {
+		t.Fatalf("expected rebased result with restored autostash, got %+v", executed)
+	}
+}
+
+// A failed pop leaves the stash in place: the repo still succeeds, and the
+// result and log carry a warning instead. A failed fetch still restores.
+func TestAutostashAllPopFailureIsWarning(t *testing.T) {
+	adapter := &dirtyBehindAdapter{planAdapter: &planAdapter{
+		stashCreated: true,
+		popErrByDir:  map[string]error{"/repo": fmt.Errorf("conflict in README.md")},
+	}}
+	eng := newPlanExecEngine(adapter)
+	logger := &warnRecorder{Logger: obs.NopLogger()}
+	eng.logger = logger
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+
+	_, executed := eng.planAndExecute(t, entry, SyncOptions{AutostashAll: true})
+
+	if !executed.OK || executed.Outcome != SyncOutcomeFetched || !executed.Autostashed || executed.AutostashRestored {
+		t.Fatalf("expected fetched result with unrestored autostash, got %+v", executed)
+	}
+	if !strings.Contains(executed.Warning, "still in the stash") || !strings.Contains(executed.Warning, "conflict in README.md") {
+		t.Fatalf("expected pop failure warning, got %q", executed.Warning)
+	}
+	if len(logger.warnings) != 1 || !strings.Contains(logger.warnings[0], "/repo") {
+		t.Fatalf("expected one logged warning, got %v", logger.warnings)
+	}
+
+	failing := &dirtyBehindAdapter{planAdapter: &planAdapter{
+		stashCreated:  true,
+		fetchErrByDir: map[string]error{"/repo": fmt.Errorf("network unreachable")},
+	}}
+	_, executed = newPlanExecEngine(failing).planAndExecute(t, entry, SyncOptions{AutostashAll: true})
+	if executed.OK || !executed.AutostashRestored {
+		t.Fatalf("expected failed fetch with restored autostash, got %+v", executed)
+	}
+	if got := failing.calls[len(failing.calls)-1]; got != "stash-pop:/repo" {
+		t.Fatalf("expected stash pop after failed fetch, got %v", failing.calls)
+	}
+}
+
+// A clean worktree plans no autostash at all.
+func TestAutostashAllSkipsCleanWorktree(t *testing.T) {
+	adapter := &planAdapter{stashCreated: true}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+
+	plan, executed := eng.planAndExecute(t, entry, SyncOptions{AutostashAll: true})
+	if fmt.Sprint(plan.steps) != fmt.Sprint([]syncStep{syncStepFetch}) || executed.Autostashed {
+		t.Fatalf("expected plain fetch for clean worktree, got steps %v result %+v", plan.steps, executed)
+	}
+	if fmt.Sprint(adapter.calls) != "[fetch:/repo]" {
+		t.Fatalf("expected only a fetch, got %v", adapter.calls)
+	}
+}
+
+func TestLFSFetchAppendedForLFSRepos(t *testing.T) {
+	adapter := &lfsAdapter{planAdapter: &planAdapter{}, lfs: map[string]bool{"/lfs": true}}
+	eng := newPlanExecEngine(adapter)
+	lfsEntry := registry.Entry{RepoID: "lfs", Path: "/lfs", RemoteURL: "git@github.com:org/lfs.git", Status: registry.StatusPresent}
+	plainEntry := registry.Entry{RepoID: "plain", Path: "/plain", RemoteURL: "git@github.com:org/plain.git", Status: registry.StatusPresent}
+
+	plan, executed := eng.planAndExecute(t, lfsEntry, SyncOptions{LFS: true})
+	if fmt.Sprint(plan.steps) != fmt.Sprint([]syncStep{syncStepFetch, syncStepLFSFetch}) || !strings.HasSuffix(plan.Action, " && git lfs fetch") {
+		t.Fatalf("expected lfs fetch appended to plan, got steps %v action %q", plan.steps, plan.Action)
+	}
+	if !executed.OK || executed.Outcome != SyncOutcomeFetched {
+		t.Fatalf("expected fetched result, got %+v", executed)
+	}
+
+	plan, _ = eng.planAndExecute(t, plainEntry, SyncOptions{LFS: true})
+	if fmt.Sprint(plan.steps) != fmt.Sprint([]syncStep{syncStepFetch}) {
+		t.Fatalf("expected plain fetch for non-LFS repo, got %v", plan.steps)
+	}
+	wantCalls := []string{"fetch:/lfs", "lfs-fetch:/lfs", "fetch:/plain"}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint(wantCalls) {
+		t.Fatalf("expected calls %v, got %v", wantCalls, adapter.calls)
+	}
+}
+
+func TestLFSFetchFailureOutcome(t *testing.T) {
+	adapter := &lfsAdapter{
+		planAdapter: &planAdapter{},
+		lfs:         map[string]bool{"/lfs": true},
+		lfsErr:      fmt.Errorf("git: 'lfs' is not a git command"),
+	}
+	entry := registry.Entry{RepoID: "lfs", Path: "/lfs", RemoteURL: "git@github.com:org/lfs.git", Status: registry.StatusPresent}
+
+	_, executed := newPlanExecEngine(adapter).planAndExecute(t, entry, SyncOptions{LFS: true})
+	if executed.OK || executed.Outcome != SyncOutcomeFailedLFS {
+		t.Fatalf("expected failed_lfs outcome, got %+v", executed)
+	}
+	if !strings.HasPrefix(executed.Error, SyncErrorLFSFetchFailed+": ") || !strings.Contains(executed.Error, "not a git command") {
+		t.Fatalf("expected lfs fetch failure message, got %q", executed.Error)
+	}
+}
+
+// Without --lfs, repos are never probed for LFS.
+func TestLFSOffSkipsProbe(t *testing.T) {
+	adapter := &lfsAdapter{planAdapter: &planAdapter{}, lfs: map[string]bool{"/lfs": true}}
+	entry := registry.Entry{RepoID: "lfs", Path: "/lfs", RemoteURL: "git@github.com:org/lfs.git", Status: registry.StatusPresent}
+
+	plan, _ := newPlanExecEngine(adapter).planAndExecute(t, entry, SyncOptions{})
+	if len(adapter.probeLog) != 0 || fmt.Sprint(plan.steps) != fmt.Sprint([]syncStep{syncStepFetch}) {
+		t.Fatalf("expected no LFS probe or step, got probes %v steps %v", adapter.probeLog, plan.steps)
+	}
+}
+
+func TestUpdateSubmodulesFollowsRebase(t *testing.T) {
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+	opts := SyncOptions{UpdateLocal: true, UpdateSubmodules: true}
+
+	adapter := &submoduleAdapter{planAdapter: &planAdapter{}, hasSubmodules: true}
+	plan, executed := newPlanExecEngine(adapter).planAndExecute(t, entry, opts)
+	if got := fmt.Sprint(plan.steps); got != "[fetch pull_rebase submodule_update]" || !strings.HasSuffix(plan.Action, " && "+submoduleUpdateAction) {
+		t.Fatalf("expected the submodule update after the rebase, got steps %s action %q", got, plan.Action)
+	}
+	if !executed.OK || executed.Outcome != SyncOutcomeRebased {
+		t.Fatalf("expected rebased result, got %+v", executed)
+	}
+	if got := strings.Join(adapter.calls, ","); got != "fetch:/repo,pull:/repo,submodule-update:/repo" {
+		t.Fatalf("unexpected calls %s", got)
+	}
+
+	direct := &submoduleAdapter{planAdapter: &planAdapter{}, hasSubmodules: true}
+	applied := newPlanExecEngine(direct).runSyncApply(context.Background(), entry, opts, nil)
+	if !applied.OK || applied.Outcome != SyncOutcomeRebased || !strings.HasSuffix(applied.Action, " && "+submoduleUpdateAction) {
+		t.Fatalf("expected the direct path to update submodules, got %+v", applied)
+	}
+
+	// Repos without submodules, and fetch-only syncs, skip the step.
+	plain := &submoduleAdapter{planAdapter: &planAdapter{}}
+	plan, _ = newPlanExecEngine(plain).planAndExecute(t, entry, opts)
+	if got := fmt.Sprint(plan.steps); got != "[fetch pull_rebase]" {
+		t.Fatalf("expected no submodule step without submodules, got %s", got)
+	}
+	fetchOnly := &submoduleAdapter{planAdapter: &planAdapter{}, hasSubmodules: true}
+	newPlanExecEngine(fetchOnly).runSyncApply(context.Background(), entry, SyncOptions{UpdateSubmodules: true}, nil)
+	if got := strings.Join(fetchOnly.calls, ","); got != "fetch:/repo" {
+		t.Fatalf("expected only a fetch without --update-local, got %s", got)
+	}
+}
+
+func TestUpdateSubmodulesFailureOutcome(t *testing.T) {
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+	opts := SyncOptions{UpdateLocal: true, UpdateSubmodules: true}
+	newAdapter := func() *submoduleAdapter {
+		return &submoduleAdapter{planAdapter: &planAdapter{}, hasSubmodules: true, updateErr: fmt.Errorf("fatal: could not read from remote repository")}
+	}
+
+	_, executed := newPlanExecEngine(newAdapter()).planAndExecute(t, entry, opts)
+	applied := newPlanExecEngine(newAdapter()).runSyncApply(context.Background(), entry, opts, nil)
+	for _, res := range []SyncResult{executed, applied} {
+		if res.OK || res.Outcome != SyncOutcomeFailedSubmodules || !strings.HasPrefix(res.Error, SyncErrorSubmoduleUpdateFailed+": ") {
+			t.Fatalf("expected failed_submodules outcome, got %+v", res)
+		}
+	}
+}
+
+func TestSyncResultRecordsDuration(t *testing.T) {
+	adapter := &slowFetchAdapter{planAdapter: &planAdapter{}, delay: 5 * time.Millisecond}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "slow", Path: "/slow", RemoteURL: "git@github.com:org/slow.git", Status: registry.StatusPresent}
+
+	plan, executed := eng.planAndExecute(t, entry, SyncOptions{})
+	if plan.Duration != 0 {
+		t.Fatalf("expected no duration on the plan entry, got %v", plan.Duration)
+	}
+	if !executed.OK || executed.Duration < adapter.delay {
+		t.Fatalf("expected executed duration of at least %v, got %+v", adapter.delay, executed)
+	}
+
+	direct := eng.runSyncApply(context.Background(), entry, SyncOptions{}, nil)
+	if !direct.OK || direct.Duration < adapter.delay {
+		t.Fatalf("expected direct sync duration of at least %v, got %+v", adapter.delay, direct)
+	}
+}
+
+func TestMirrorEntriesUseRemoteUpdateAndIgnoreUpdateLocal(t *testing.T) {
+	adapter := &mirrorAdapter{planAdapter: &planAdapter{}}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "mirror", Path: "/mirror", Type: "mirror", RemoteURL: "git@github.com:org/mirror.git", Status: registry.StatusPresent}
+	opts := SyncOptions{UpdateLocal: true, PushLocal: true, RebaseDirty: true, AutostashAll: true, LFS: true}
+
+	plan, executed := eng.planAndExecute(t, entry, opts)
+	if fmt.Sprint(plan.steps) != fmt.Sprint([]syncStep{syncStepMirrorUpdate}) || plan.Action != mirrorUpdateAction {
+		t.Fatalf("expected a mirror update plan, got steps %v action %q", plan.steps, plan.Action)
+	}
+	if !executed.OK || executed.Outcome != SyncOutcomeMirrorUpdated {
+		t.Fatalf("expected mirror_updated outcome, got %+v", executed)
+	}
+
+	direct := eng.runSyncApply(context.Background(), entry, opts, nil)
+	if !direct.OK || direct.Outcome != SyncOutcomeMirrorUpdated || direct.Action != mirrorUpdateAction {
+		t.Fatalf("expected direct mirror update, got %+v", direct)
+	}
+	wantCalls := []string{"mirror-update:/mirror", "mirror-update:/mirror"}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint(wantCalls) {
+		t.Fatalf("expected only mirror updates, got %v", adapter.calls)
+	}
+
+	adapter.mirrorErr = fmt.Errorf("fatal: could not read from remote repository")
+	_, failed := eng.planAndExecute(t, entry, SyncOptions{})
+	if failed.OK || failed.Outcome != SyncOutcomeFailedFetch {
+		t.Fatalf("expected failed_fetch for a failed mirror update, got %+v", failed)
+	}
+}
+
+// Adapters without mirror support fall back to a plain fetch.
+func TestMirrorUpdateFallsBackToFetch(t *testing.T) {
+	adapter := &planAdapter{}
+	entry := registry.Entry{RepoID: "mirror", Path: "/mirror", Type: "mirror", RemoteURL: "git@github.com:org/mirror.git", Status: registry.StatusPresent}
+
+	_, executed := newPlanExecEngine(adapter).planAndExecute(t, entry, SyncOptions{})
+	if !executed.OK || executed.Outcome != SyncOutcomeMirrorUpdated {
+		t.Fatalf("expected mirror_updated outcome, got %+v", executed)
+	}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint([]string{"fetch:/mirror"}) {
+		t.Fatalf("expected a fetch fallback, got %v", adapter.calls)
+	}
+}
+
+func TestInspectRepoFlagsUpstreamLeftOnRenamedDefaultBranch(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		upstream      string
+		tracking      model.TrackingStatus
+		remoteDefault string
+		wantStale     bool
+	}{
+		{name: "master after rename to main", upstream: "origin/master", tracking: model.TrackingEqual, remoteDefault: "main", wantStale: true},
+		{name: "already on the default", upstream: "origin/main", tracking: model.TrackingBehind, remoteDefault: "main"},
+		{name: "feature branch", upstream: "origin/feature/x", tracking: model.TrackingAhead, remoteDefault: "main"},
+		{name: "old default deleted", upstream: "origin/master", tracking: model.TrackingGone, remoteDefault: "main"},
+		{name: "remote HEAD unknown", upstream: "origin/master", tracking: model.TrackingEqual},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			adapter := &renamedDefaultAdapter{planAdapter: &planAdapter{}, upstream: tc.upstream, tracking: tc.tracking, remoteDefault: tc.remoteDefault, recorded: tc.remoteDefault}
+			eng := newPlanExecEngine(adapter)
+			status, err := eng.InspectRepo(context.Background(), "/repo")
+			if err != nil {
+				t.Fatalf("inspect: %v", err)
+			}
+			if status.Tracking.StaleUpstream != tc.wantStale {
+				t.Fatalf("expected stale upstream %v, got %+v", tc.wantStale, status.Tracking)
+			}
+			if adapter.liveLookups != 0 {
+				t.Fatalf("expected a plain inspection to read the recorded remote HEAD only, got %d live lookups", adapter.liveLookups)
+			}
+			matched, reason := ExplainFilter(FilterStaleUpstream, *status, nil, nil)
+			if matched != tc.wantStale {
+				t.Fatalf("expected filter match %v, got %v", tc.wantStale, matched)
+			}
+			if tc.wantStale && reason != "stale upstream: tracks origin/master, remote default is main" {
+				t.Fatalf("unexpected reason %q", reason)
+			}
+
+			entry := registry.Entry{RepoID: "github.com/org/repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+			matched, _, failure := eng.syncEntryMatchesInspectFilter(context.Background(), entry, SyncOptions{Filter: FilterStaleUpstream})
+			if failure != nil || matched != tc.wantStale {
+				t.Fatalf("expected sync filter match %v, got %v (failure %+v)", tc.wantStale, matched, failure)
+			}
+		})
+	}
+}
+
+// The recorded origin/HEAD goes stale until a fetch refreshes it; only the
+// stale-upstream filter asks the remote itself.
+func TestStaleUpstreamProbesLiveRemoteHeadOnlyWhenFiltering(t *testing.T) {
+	adapter := &renamedDefaultAdapter{planAdapter: &planAdapter{}, upstream: "origin/master", tracking: model.TrackingEqual, remoteDefault: "main", recorded: "master"}
+	eng := newPlanExecEngine(adapter)
+	eng.registry = &registry.Registry{Entries: []registry.Entry{{RepoID: "github.com/org/repo", Path: "/repo", RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}}}
+
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(report.Repos) != 1 || report.Repos[0].Tracking.StaleUpstream || adapter.liveLookups != 0 {
+		t.Fatalf("expected a plain status to trust the recorded HEAD without live lookups, got %+v (%d lookups)", report.Repos, adapter.liveLookups)
+	}
+
+	for _, opts := range []StatusOptions{{Filter: FilterStaleUpstream}, {Filter: FilterAll, ProbeRemoteHead: true}} {
+		report, err = eng.Status(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("status %+v: %v", opts, err)
+		}
+		if len(report.Repos) != 1 || !report.Repos[0].Tracking.StaleUpstream || report.Repos[0].Tracking.RemoteDefault != "main" {
+			t.Fatalf("expected status %+v to probe the live HEAD, got %+v", opts, report.Repos)
+		}
+	}
+	if adapter.liveLookups != 2 {
+		t.Fatalf("expected one live lookup per probing run, got %d", adapter.liveLookups)
+	}
+}
+
+func TestSyncFetchRefreshesRecordedRemoteHead(t *testing.T) {
+	adapter := &renamedDefaultAdapter{planAdapter: &planAdapter{}, upstream: "origin/master", tracking: model.TrackingEqual, remoteDefault: "main", recorded: "master"}
+	eng := newPlanExecEngine(adapter)
+	if _, err := eng.syncFetch(context.Background(), "/repo", "", 0, false); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint([]string{"fetch:/repo", "set-head:/repo:origin"}) {
+		t.Fatalf("expected the fetch to refresh origin/HEAD, got %v", adapter.calls)
+	}
+
+	status, err := eng.InspectRepo(context.Background(), "/repo")
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if !status.Tracking.StaleUpstream || status.Tracking.RemoteDefault != "main" || adapter.liveLookups != 0 {
+		t.Fatalf("expected the refreshed record to flag the stale upstream offline, got %+v (%d lookups)", status.Tracking, adapter.liveLookups)
+	}
+
+	adapter.fetchErrByDir = map[string]error{"/repo": fmt.Errorf("fatal: could not read from remote repository")}
+	adapter.calls = nil
+	if _, err := eng.syncFetch(context.Background(), "/repo", "", 0, false); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+	if fmt.Sprint(adapter.calls) != fmt.Sprint([]string{"fetch:/repo"}) {
+		t.Fatalf("expected no HEAD refresh after a failed fetch, got %v", adapter.calls)
+	}
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/engine"
+	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
 	"github.com/skaphos/repokeeper/internal/vcs"
 )
@@ -94,6 +95,31 @@ func (b *blockingRunner) Run(ctx context.Context, _ string, _ ...string) (string
 	}
 }
 
+// pathBlockingRunner fails immediately for fastDir and blocks every other
+// repo until its context is cancelled, recording which repos were started.
+type pathBlockingRunner struct {
+	fastDir string
+	mu      sync.Mutex
+	started map[string]bool
+}
+
+func (p *pathBlockingRunner) Run(ctx context.Context, dir string, _ ...string) (string, error) {
+	p.mu.Lock()
+	p.started[dir] = true
+	p.mu.Unlock()
+	if dir == p.fastDir {
+		return "", errors.New("not a git repository")
+	}
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (p *pathBlockingRunner) wasStarted(dir string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.started[dir]
+}
+
 var _ = Describe("Engine", func() {
 	It("inspects repo status", func() {
 		runner := &mockRunner{responses: map[string]mockResponse{
@@ -108,6 +134,8 @@ var _ = Describe("Engine", func() {
 			},
 			"/repo:rev-list --left-right --count main...origin/main": {out: "0\t0"},
 			"/repo:config --file .gitmodules --get-regexp submodule": {err: errors.New("none")},
+			"/repo:stash list":          {out: "stash@{0}: WIP on main: abc123 wip\nstash@{1}: On main: spike\n"},
+			"/repo:log -1 --format=%cI": {out: "2026-01-02T03:04:05Z\n"},
 		}}
 		eng := engine.New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(runner), nil, nil, nil)
 		status, err := eng.InspectRepo(context.Background(), "/repo")
@@ -115,6 +143,8 @@ var _ = Describe("Engine", func() {
 		Expect(status.RepoID).To(Equal("github.com/org/repo"))
 		Expect(status.Worktree).NotTo(BeNil())
 		Expect(status.Worktree.Dirty).To(BeTrue())
+		Expect(status.StashCount).To(Equal(2))
+		Expect(status.LastCommit).To(Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
 		Expect(status.RemoteTrackingRefs.StaleCount).To(Equal(1))
 		Expect(status.RemoteTrackingRefs.Stale).To(Equal([]string{"origin/merged"}))
 	})
@@ -188,6 +218,38 @@ var _ = Describe("Engine", func() {
 		Expect(status.RemoteTrackingRefs.InspectionError).To(ContainSubstring("network unavailable"))
 	})
 
+	It("treats a failed stash listing as zero stashes and never lists stashes for bare repos", func() {
+		runner := &countingRunner{responses: map[string]mockResponse{
+			"/repo:rev-parse --is-bare-repository":    {out: "false"},
+			"/repo:remote":                            {out: "origin"},
+			"/repo:remote get-url origin":             {out: "git@github.com:org/repo.git"},
+			"/repo:remote prune --dry-run -- origin":  {out: ""},
+			"/repo:symbolic-ref --quiet --short HEAD": {out: "main"},
+			"/repo:status --porcelain=v1":             {out: ""},
+			"/repo:for-each-ref --format=%(refname:short)|%(upstream:short)|%(upstream:track)|%(upstream:trackshort) refs/heads": {
+				out: "main|origin/main||=",
+			},
+			"/repo:rev-list --left-right --count main...origin/main": {out: "0\t0"},
+			"/repo:config --file .gitmodules --get-regexp submodule": {err: errors.New("none")},
+			"/repo:stash list":                                       {err: errors.New("fatal: bad revision 'refs/stash'")},
+			"/bare:rev-parse --is-bare-repository":                   {out: "true"},
+			"/bare:remote":                                           {out: ""},
+			"/bare:symbolic-ref --quiet --short HEAD":                {out: "main"},
+			"/bare:config --file .gitmodules --get-regexp submodule": {err: errors.New("none")},
+		}}
+		eng := engine.New(&config.Config{}, &registry.Registry{}, vcs.NewGitAdapter(runner), nil, nil, nil)
+
+		status, err := eng.InspectRepo(context.Background(), "/repo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.StashCount).To(BeZero())
+
+		status, err = eng.InspectRepo(context.Background(), "/bare")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Bare).To(BeTrue())
+		Expect(status.StashCount).To(BeZero())
+		Expect(runner.count("/bare:stash list")).To(BeZero())
+	})
+
 	It("syncs repositories with dry-run", func() {
 		reg := &registry.Registry{
 			Entries: []registry.Entry{
@@ -424,6 +486,39 @@ var _ = Describe("Engine", func() {
 		Expect(results).To(HaveLen(3))
 	})
 
+	It("cancels the remaining status work once StopWhen matches", func() {
+		reg := &registry.Registry{}
+		for _, path := range []string{"/fast", "/slow1", "/slow2", "/slow3", "/slow4"} {
+			reg.Entries = append(reg.Entries, registry.Entry{RepoID: path, Path: path, Status: registry.StatusPresent})
+		}
+		runner := &pathBlockingRunner{fastDir: "/fast", started: map[string]bool{}}
+		// No per-repo timeout: only the StopWhen cancellation can unblock /slow1.
+		eng := engine.New(&config.Config{}, reg, vcs.NewGitAdapter(runner), vcs.NewGitErrorClassifier(), nil, nil)
+
+		done := make(chan *model.StatusReport, 1)
+		go func() {
+			defer GinkgoRecover()
+			report, err := eng.Status(context.Background(), engine.StatusOptions{
+				Filter:      engine.FilterAll,
+				Concurrency: 2,
+				StopWhen:    func(repo model.RepoStatus) bool { return repo.Path == "/fast" },
+			})
+			Expect(err).NotTo(HaveOccurred())
+			done <- report
+		}()
+
+		var report *model.StatusReport
+		Eventually(done, 5*time.Second).Should(Receive(&report))
+		Expect(report.Stopped).To(BeTrue())
+		Expect(report.Repos).To(HaveLen(1))
+		Expect(report.Repos[0].Path).To(Equal("/fast"))
+		// /slow2 may take the slot /fast frees before the stop is seen; with
+		// /slow1 and /slow2 blocked, nothing later can start until after it.
+		for _, path := range []string{"/slow3", "/slow4"} {
+			Expect(runner.wasStarted(path)).To(BeFalse(), "%s should not have been inspected", path)
+		}
+	})
+
 	It("times out long-running git operations", func() {
 		blocker := &blockingRunner{
 			started: make(chan struct{}, 1),
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// FsckOptions configures Fsck. Concurrency, Timeout, and MaxJobs resolve
+// exactly as they do for Status.
+type FsckOptions struct {
+	Concurrency int
+	Timeout     int
+	MaxJobs     int
+}
+
+// Fsck checks the object store of every present registered repo, bare and
+// mirror clones included, and reports the repos where problems were found or
+// the check could not run. It is read-only.
+func (e *Engine) Fsck(ctx context.Context, opts FsckOptions) (*model.FsckReport, error) {
+	if e.registry == nil {
+		return nil, errors.New("registry not loaded")
+	}
+	limits := StatusOptions{Concurrency: opts.Concurrency, Timeout: opts.Timeout, MaxJobs: opts.MaxJobs}
+	repos := collectEntryResults(e, ctx, limits, func(entry registry.Entry) bool {
+		return entry.Status == registry.StatusPresent
+	}, e.fsckWorker)
+	report := &model.FsckReport{GeneratedAt: time.Now(), Checked: len(repos), Repos: []model.FsckRepo{}}
+	for _, repo := range repos {
+		if len(repo.Issues) > 0 || repo.Error != "" {
+			report.Repos = append(report.Repos, repo)
+		}
+	}
+	sort.Slice(report.Repos, func(i, j int) bool {
+		return report.Repos[i].Path < report.Repos[j].Path
+	})
+	return report, nil
+}
+
+func (e *Engine) fsckWorker(ctx context.Context, entry registry.Entry, timeoutSeconds int) model.FsckRepo {
+	repo := model.FsckRepo{RepoID: entry.RepoID, Path: entry.Path}
+	checker, ok := e.adapter.(vcs.IntegrityChecker)
+	if !ok {
+		repo.Error = "adapter does not support integrity checks"
+		return repo
+	}
+	repoCtx, cancel := goneBranchContext(ctx, repoTimeout(entry, timeoutSeconds))
+	defer cancel()
+	issues, err := checker.Fsck(repoCtx, entry.Path)
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+	repo.Issues = issues
+	return repo
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// fsckExitError is a git run that exited with the given status.
+type fsckExitError int
+
+func (e fsckExitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func (e fsckExitError) ExitCode() int { return int(e) }
+
+func TestFsckReportsOnlyReposWithIssues(t *testing.T) {
+	root := t.TempDir()
+	clean, broken, mirror := filepath.Join(root, "clean"), filepath.Join(root, "broken"), filepath.Join(root, "mirror")
+	for _, dir := range []string{filepath.Join(clean, ".git"), filepath.Join(broken, ".git"), mirror} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(mirror, "HEAD"), []byte("ref: refs/heads/main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runner := &testRunner{responses: map[string]testResponse{
+		clean + ":fsck --no-dangling": {},
+		broken + ":fsck --no-dangling": {
+			out: "missing blob 4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			err: fmt.Errorf("error: object file .git/objects/4b/825dc6 is empty: %w", fsckExitError(2)),
+		},
+		mirror + ":fsck --no-dangling": {},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/clean", Path: clean, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/broken", Path: broken, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/mirror", Path: mirror, Status: registry.StatusPresent, Type: "mirror"},
+		{RepoID: "github.com/org/gone", Path: filepath.Join(root, "gone"), Status: registry.StatusMissing},
+		{RepoID: "github.com/org/moved", Path: filepath.Join(root, "moved"), Status: registry.StatusMoved},
+	}}
+	eng := New(&config.Config{}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+
+	report, err := eng.Fsck(context.Background(), FsckOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("fsck: %v", err)
+	}
+	if report.Checked != 3 {
+		t.Fatalf("expected three repos checked (missing and moved skipped, mirror included), got %d", report.Checked)
+	}
+	if len(report.Repos) != 1 || report.Repos[0].RepoID != "github.com/org/broken" {
+		t.Fatalf("expected only the broken repo to be reported, got %+v", report.Repos)
+	}
+	want := []string{
+		"missing blob 4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		"error: object file .git/objects/4b/825dc6 is empty",
+	}
+	if got := report.Repos[0].Issues; !slices.Equal(got, want) {
+		t.Fatalf("unexpected issues:\n got %q\nwant %q", got, want)
+	}
+	if report.Repos[0].Error != "" {
+		t.Fatalf("expected corruption to be reported as issues, got error %q", report.Repos[0].Error)
+	}
+}
+
+func TestFsckRecordsAdaptersWithoutIntegrityChecks(t *testing.T) {
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/repos/a", Status: registry.StatusPresent},
+	}}
+	eng := New(&config.Config{}, reg, &goneBranchAdapter{}, nil, nil, nil)
+
+	report, err := eng.Fsck(context.Background(), FsckOptions{})
+	if err != nil {
+		t.Fatalf("fsck: %v", err)
+	}
+	if len(report.Repos) != 1 || report.Repos[0].Error == "" {
+		t.Fatalf("expected an error for the unsupported adapter, got %+v", report.Repos)
+	}
+}
+
+func TestFsckReportsRepoThatCannotBeCheckedAsError(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runner := &testRunner{responses: map[string]testResponse{
+		repo + ":fsck --no-dangling": {err: fmt.Errorf("fatal: not a git repository: %w", fsckExitError(128))},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/fatal", Path: repo, Status: registry.StatusPresent},
+		{RepoID: "github.com/org/deleted", Path: filepath.Join(repo, "deleted"), Status: registry.StatusPresent},
+	}}
+	eng := New(&config.Config{}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+
+	report, err := eng.Fsck(context.Background(), FsckOptions{})
+	if err != nil {
+		t.Fatalf("fsck: %v", err)
+	}
+	if len(report.Repos) != 2 {
+		t.Fatalf("expected both repos reported, got %+v", report.Repos)
+	}
+	for _, repo := range report.Repos {
+		if repo.Error == "" || len(repo.Issues) != 0 {
+			t.Fatalf("expected %s to be reported as an error, not issues, got %+v", repo.RepoID, repo)
+		}
+	}
+}
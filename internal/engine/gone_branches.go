@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/prune"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// GoneBranchesOptions configures GoneBranches and PlanGoneBranchDeletions.
+// Concurrency, Timeout, and MaxJobs resolve exactly as they do for Status.
+type GoneBranchesOptions struct {
+	Concurrency int
+	Timeout     int
+	MaxJobs     int
+	// Force plans unmerged gone branches for `git branch -D` instead of
+	// skipping them. Only PlanGoneBranchDeletions reads it.
+	Force bool
+}
+
+// Gone-branch deletion actions.
+const (
+	GoneBranchDelete      = "delete"
+	GoneBranchForceDelete = "force-delete"
+	GoneBranchSkip        = "skip"
+)
+
+// GoneBranchDeletion is one planned (and, once applied, attempted) deletion of
+// a local branch whose upstream is gone. Reason explains a skip; Error records
+// a failed delete.
+type GoneBranchDeletion struct {
+	RepoID   string `json:"repo_id"`
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream,omitempty"`
+	Merged   bool   `json:"merged"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason,omitempty"`
+	Deleted  bool   `json:"deleted"`
+	Error    string `json:"error,omitempty"`
+}
+
+// GoneBranches lists, for every registered repo, the local branches whose
+// upstream has been deleted, not just the checked-out one. It is read-only and
+// runs no fetch, so it reflects the remote-tracking refs from the last fetch
+// with pruning. Missing, bare, and mirror repos are skipped, as are backends
+// that cannot enumerate branches.
+func (e *Engine) GoneBranches(ctx context.Context, opts GoneBranchesOptions) (*model.GoneBranchesReport, error) {
+	if e.registry == nil {
+		return nil, errors.New("registry not loaded")
+	}
+	repos := collectGoneBranchResults(e, ctx, opts, e.goneBranchesWorker)
+	report := &model.GoneBranchesReport{GeneratedAt: time.Now(), Repos: []model.GoneBranchesRepo{}}
+	for _, repo := range repos {
+		if len(repo.Branches) > 0 || repo.Error != "" {
+			report.Repos = append(report.Repos, repo)
+		}
+	}
+	sort.Slice(report.Repos, func(i, j int) bool {
+		return report.Repos[i].Path < report.Repos[j].Path
+	})
+	return report, nil
+}
+
+// PlanGoneBranchDeletions plans deleting every gone local branch that is fully
+// merged into the repo's base branch (resolved as for prune classification).
+// The checked-out branch, the base branch, branches checked out in another
+// worktree, and protected branches are always skipped. Unmerged branches are
+// skipped unless opts.Force is set. Nothing is deleted here.
+func (e *Engine) PlanGoneBranchDeletions(ctx context.Context, opts GoneBranchesOptions) ([]GoneBranchDeletion, error) {
+	if e.registry == nil {
+		return nil, errors.New("registry not loaded")
+	}
+	perRepo := collectGoneBranchResults(e, ctx, opts, func(ctx context.Context, entry registry.Entry, timeoutSeconds int) []GoneBranchDeletion {
+		return e.planGoneBranchDeletionsForRepo(ctx, entry, timeoutSeconds, opts.Force)
+	})
+	var plans []GoneBranchDeletion
+	for _, repoPlans := range perRepo {
+		plans = append(plans, repoPlans...)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		if plans[i].Path != plans[j].Path {
+			return plans[i].Path < plans[j].Path
+		}
+		return plans[i].Branch < plans[j].Branch
+	})
+	return plans, nil
+}
+
+// DeleteGoneBranches runs the delete and force-delete entries of plans in
+// order and returns them with Deleted or Error filled in. Skipped entries are
+// returned unchanged. A failed delete does not stop the rest.
+func (e *Engine) DeleteGoneBranches(ctx context.Context, plans []GoneBranchDeletion) []GoneBranchDeletion {
+	results := append([]GoneBranchDeletion(nil), plans...)
+	deleter, ok := e.adapter.(vcs.BranchDeleter)
+	for i := range results {
+		res := &results[i]
+		if res.Action != GoneBranchDelete && res.Action != GoneBranchForceDelete {
+			continue
+		}
+		if !ok {
+			res.Error = "adapter does not support deleting branches"
+			continue
+		}
+		if err := deleter.DeleteBranch(ctx, res.Path, res.Branch, res.Action == GoneBranchForceDelete); err != nil {
+			res.Error = err.Error()
+			continue
+		}
+		res.Deleted = true
+	}
+	return results
+}
+
+// collectGoneBranchResults runs fn for every present, non-mirror registry
+// entry on the same semaphore worker pool Status uses and returns the results
+// in completion order.
+func collectGoneBranchResults[T any](e *Engine, ctx context.Context, opts GoneBranchesOptions, fn func(context.Context, registry.Entry, int) T) []T {
+	limits := StatusOptions{Concurrency: opts.Concurrency, Timeout: opts.Timeout, MaxJobs: opts.MaxJobs}
+	return collectEntryResults(e, ctx, limits, func(entry registry.Entry) bool {
+		return entry.Status != registry.StatusMissing && entry.Type != "mirror"
+	}, fn)
+}
+
+// collectEntryResults runs fn for every registry entry include accepts on the
+// same semaphore worker pool Status uses, with concurrency and timeout resolved
+// from limits, and returns the results in completion order.
+func collectEntryResults[T any](e *Engine, ctx context.Context, limits StatusOptions, include func(registry.Entry) bool, fn func(context.Context, registry.Entry, int) T) []T {
+	concurrency, timeoutSeconds := e.statusLimits(limits)
+	entries := e.loadStatusEntries()
+
+	sem := make(chan struct{}, concurrency)
+	out := make(chan T, workerChannelBufferSize(len(entries), concurrency))
+	spawned := 0
+	for _, entry := range entries {
+		if !include(entry) {
+			continue
+		}
+		sem <- struct{}{}
+		spawned++
+		go func(entry registry.Entry) {
+			res := fn(ctx, entry, timeoutSeconds)
+			<-sem // release before writing to out to prevent deadlock when out is full
+			out <- res
+		}(entry)
+	}
+
+	results := make([]T, 0, spawned)
+	for i := 0; i < spawned; i++ {
+		results = append(results, <-out)
+	}
+	return results
+}
+
+// goneBranchSignals returns the repo's local branch signals, or nil when the
+// repo is bare or the backend cannot enumerate branches. base is passed to
+// the inspector for the merged check; an empty base skips it.
+func (e *Engine) goneBranchSignals(ctx context.Context, path, base string) ([]vcs.LocalBranchSignal, error) {
+	inspector, ok := e.adapter.(vcs.LocalBranchInspector)
+	if !ok {
+		return nil, nil
+	}
+	if bare, err := e.adapter.IsBare(ctx, path); err == nil && bare {
+		return nil, nil
+	}
+	return inspector.InspectLocalBranches(ctx, path, base, false)
+}
+
+func goneBranchContext(ctx context.Context, timeoutSeconds int) (context.Context, context.CancelFunc) {
+	if timeoutSeconds > 0 {
+		return context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	}
+	return ctx, func() {}
+}
+
+func (e *Engine) goneBranchesWorker(ctx context.Context, entry registry.Entry, timeoutSeconds int) model.GoneBranchesRepo {
+	repo := model.GoneBranchesRepo{RepoID: entry.RepoID, Path: entry.Path}
+	repoCtx, cancel := goneBranchContext(ctx, repoTimeout(entry, timeoutSeconds))
+	defer cancel()
+	// No base: only enumeration and tracking state are needed, so skip the
+	// integration checks entirely.
+	signals, err := e.goneBranchSignals(repoCtx, entry.Path, "")
+	if err != nil {
+		repo.Error = err.Error()
+		return repo
+	}
+	for _, s := range signals {
+		if status := upstreamStatusFromSignal(s); status == model.TrackingGone {
+			repo.Branches = append(repo.Branches, model.BranchTracking{Name: s.Name, Upstream: s.Upstream, Status: status})
+		}
+	}
+	return repo
+}
+
+func (e *Engine) planGoneBranchDeletionsForRepo(ctx context.Context, entry registry.Entry, timeoutSeconds int, force bool) []GoneBranchDeletion {
+	repoCtx, cancel := goneBranchContext(ctx, repoTimeout(entry, timeoutSeconds))
+	defer cancel()
+	if _, ok := e.adapter.(vcs.LocalBranchInspector); !ok {
+		return nil
+	}
+	if bare, err := e.adapter.IsBare(repoCtx, entry.Path); err == nil && bare {
+		return nil
+	}
+	inspectErr := func(err error) []GoneBranchDeletion {
+		return []GoneBranchDeletion{{RepoID: entry.RepoID, Path: entry.Path, Action: GoneBranchSkip, Reason: "could not inspect branches", Error: err.Error()}}
+	}
+	head, err := e.adapter.Head(repoCtx, entry.Path)
+	if err != nil {
+		return inspectErr(err)
+	}
+	tracking, _ := e.adapter.TrackingStatus(repoCtx, entry.Path)
+	primary := ""
+	if remotes, err := e.adapter.Remotes(repoCtx, entry.Path); err == nil {
+		names := make([]string, 0, len(remotes))
+		for _, remote := range remotes {
+			names = append(names, remote.Name)
+		}
+		primary = e.adapter.PrimaryRemote(names)
+	}
+	localBase, queryBase := e.localBranchBases(entry.RepoID, entry.Path, primary, tracking)
+	signals, err := e.goneBranchSignals(repoCtx, entry.Path, queryBase)
+	if err != nil {
+		return inspectErr(err)
+	}
+	policy := e.branchPolicy(localBase)
+
+	var plans []GoneBranchDeletion
+	for _, s := range signals {
+		if upstreamStatusFromSignal(s) != model.TrackingGone {
+			continue
+		}
+		plan := GoneBranchDeletion{
+			RepoID:   entry.RepoID,
+			Path:     entry.Path,
+			Branch:   s.Name,
+			Upstream: s.Upstream,
+			Merged:   s.MergedIntoBase != nil && *s.MergedIntoBase,
+			Action:   GoneBranchDelete,
+		}
+		protected, _ := prune.MatchesProtected(s.Name, policy.ProtectedPatterns)
+		switch {
+		case !head.Detached && s.Name == head.Branch:
+			plan.Action, plan.Reason = GoneBranchSkip, "checked out"
+		case s.Name == localBase:
+			plan.Action, plan.Reason = GoneBranchSkip, "base branch"
+		case s.WorktreePath != "":
+			plan.Action, plan.Reason = GoneBranchSkip, "checked out in another worktree"
+		case protected:
+			plan.Action, plan.Reason = GoneBranchSkip, "protected"
+		case !plan.Merged && force:
+			plan.Action = GoneBranchForceDelete
+		case !plan.Merged && queryBase == "":
+			plan.Action, plan.Reason = GoneBranchSkip, "base branch unresolved"
+		case !plan.Merged:
+			plan.Action, plan.Reason = GoneBranchSkip, "not merged into "+queryBase
+		}
+		plans = append(plans, plan)
+	}
+	return plans
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// goneBranchAdapter serves canned branch signals per repo path. Paths in bare
+// report as bare repos; paths in errs fail branch enumeration.
+type goneBranchAdapter struct {
+	vcs.Adapter
+	signals   map[string][]vcs.LocalBranchSignal
+	bare      map[string]bool
+	errs      map[string]error
+	mu        sync.Mutex
+	inspected map[string]bool
+}
+
+func (a *goneBranchAdapter) IsBare(_ context.Context, dir string) (bool, error) {
+	return a.bare[dir], nil
+}
+
+func (a *goneBranchAdapter) InspectLocalBranches(_ context.Context, dir, base string, patchEquivalence bool) ([]vcs.LocalBranchSignal, error) {
+	if base != "" || patchEquivalence {
+		return nil, errors.New("gone-branches should not request integration checks")
+	}
+	a.mu.Lock()
+	a.inspected[dir] = true
+	a.mu.Unlock()
+	return a.signals[dir], a.errs[dir]
+}
+
+func TestGoneBranchesListsOnlyGoneUpstreams(t *testing.T) {
+	adapter := &goneBranchAdapter{
+		signals: map[string][]vcs.LocalBranchSignal{
+			"/repos/a": {
+				{Name: "main", Upstream: "origin/main", TrackShort: "="},
+				{Name: "feature/old", Upstream: "origin/feature/old", Track: "[gone]"},
+				{Name: "scratch"},
+			},
+			"/repos/clean": {{Name: "main", Upstream: "origin/main", TrackShort: "="}},
+			"/repos/bare":  {{Name: "x", Upstream: "origin/x", Track: "[gone]"}},
+		},
+		bare:      map[string]bool{"/repos/bare": true},
+		errs:      map[string]error{"/repos/broken": errors.New("for-each-ref failed")},
+		inspected: map[string]bool{},
+	}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/clean", Path: "/repos/clean", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/a", Path: "/repos/a", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/bare", Path: "/repos/bare", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/mirror", Path: "/repos/mirror", Status: registry.StatusPresent, Type: "mirror"},
+		{RepoID: "github.com/org/missing", Path: "/repos/missing", Status: registry.StatusMissing},
+		{RepoID: "github.com/org/broken", Path: "/repos/broken", Status: registry.StatusPresent},
+	}}
+	eng := New(&config.Config{}, reg, adapter, nil, nil, nil)
+
+	report, err := eng.GoneBranches(context.Background(), GoneBranchesOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("gone branches: %v", err)
+	}
+	if len(report.Repos) != 2 {
+		t.Fatalf("expected the repo with a gone branch and the broken repo, got %+v", report.Repos)
+	}
+	got := report.Repos[0]
+	want := model.BranchTracking{Name: "feature/old", Upstream: "origin/feature/old", Status: model.TrackingGone}
+	if got.Path != "/repos/a" || len(got.Branches) != 1 || got.Branches[0] != want {
+		t.Fatalf("unexpected gone branches for /repos/a: %+v", got)
+	}
+	if broken := report.Repos[1]; broken.Path != "/repos/broken" || broken.Error == "" {
+		t.Fatalf("expected an error row for /repos/broken, got %+v", broken)
+	}
+	for _, skipped := range []string{"/repos/bare", "/repos/mirror", "/repos/missing"} {
+		if adapter.inspected[skipped] {
+			t.Fatalf("expected %s to be skipped", skipped)
+		}
+	}
+}
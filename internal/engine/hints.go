@@ -4,10 +4,13 @@ package engine
 // errorHints maps error class strings to operator-facing remediation advice.
 var errorHints = map[string]string{
 	"auth":           "check SSH keys or credentials for this remote",
+	"host_key":       "verify the host key and add it to known_hosts (for example with ssh-keyscan)",
 	"network":        "verify network connectivity and remote host availability",
 	"timeout":        "try increasing --timeout or check network latency",
 	"corrupt":        "consider running 'git fsck' in the repository",
 	"missing_remote": "verify the remote URL in registry matches the actual remote",
+	"disk_full":      "free disk space on the volume holding the repository",
+	"fs_permission":  "check ownership and write permission of the repository's .git directory",
 }
 
 // hintForErrorClass returns a remediation hint for the given error class, or empty string if none.
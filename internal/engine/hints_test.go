@@ -6,7 +6,7 @@ import (
 )
 
 func TestHintForErrorClass_KnownClasses(t *testing.T) {
-	known := []string{"auth", "network", "timeout", "corrupt", "missing_remote"}
+	known := []string{"auth", "host_key", "network", "timeout", "corrupt", "missing_remote", "disk_full", "fs_permission"}
 	for _, class := range known {
 		hint := hintForErrorClass(class)
 		if hint == "" {
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// hostLimiter caps how many sync workers talk to one Git host at a time, on
+// top of the global worker limit. A nil limiter imposes no cap.
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	sems  map[string]chan struct{}
+}
+
+// newHostLimiter returns a limiter allowing limit workers per host, or nil
+// when limit is 0 or less (unlimited).
+func newHostLimiter(limit int) *hostLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostLimiter{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+// acquire blocks until host has a free slot and returns its release func.
+// Repos without a host (local remotes) are never limited.
+func (l *hostLimiter) acquire(host string) func() {
+	if l == nil || host == "" {
+		return func() {}
+	}
+	l.mu.Lock()
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.sems[host] = sem
+	}
+	l.mu.Unlock()
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// syncHost returns the lowercased host a sync of the repo talks to: the host
+// of the normalized remote URL, else of the repo ID. Local remotes and
+// local-only repos return "".
+func (e *Engine) syncHost(repoID, remoteURL string) string {
+	normalized := strings.TrimSpace(repoID)
+	if remoteURL = strings.TrimSpace(remoteURL); remoteURL != "" {
+		if isLocalRemote(remoteURL) {
+			return ""
+		}
+		normalized = e.normalizer.NormalizeURL(remoteURL)
+	}
+	if normalized == "" || strings.HasPrefix(normalized, "local:") {
+		return ""
+	}
+	host, _, ok := strings.Cut(normalized, "/")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(host)
+}
+
+// syncItemLimit is the host and per-repo timeout of one planned sync item.
+type syncItemLimit struct {
+	item           SyncResult
+	host           string
+	timeoutSeconds int
+}
+
+// syncItemLimits resolves each planned item's registry entry once, under
+// registryMu and before any worker starts, since clone workers replace
+// entries while the plan runs. Hosts are only resolved when withHosts is set.
+func (e *Engine) syncItemLimits(plan []SyncResult, timeoutSeconds int, withHosts bool) []syncItemLimit {
+	e.registryMu.Lock()
+	defer e.registryMu.Unlock()
+	limits := make([]syncItemLimit, 0, len(plan))
+	for _, item := range plan {
+		limit := syncItemLimit{item: item, timeoutSeconds: timeoutSeconds}
+		entry := findRegistryEntryForSyncResult(e.registry, item)
+		if entry != nil {
+			limit.timeoutSeconds = repoTimeout(*entry, timeoutSeconds)
+		}
+		if withHosts {
+			if entry != nil {
+				limit.host = e.syncHost(entry.RepoID, entry.RemoteURL)
+			} else {
+				limit.host = e.syncHost(item.RepoID, "")
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits
+}
+
+func isLocalRemote(remoteURL string) bool {
+	return strings.HasPrefix(remoteURL, "file:") ||
+		strings.HasPrefix(remoteURL, ".") ||
+		filepath.IsAbs(remoteURL) ||
+		filepath.VolumeName(remoteURL) != ""
+}
+
+// interleaveByHost reorders items round-robin across hosts, keeping the
+// relative order within each host, so a per-host cap does not stall the
+// scheduler behind a run of repos on one busy host.
+func interleaveByHost[T any](items []T, host func(T) string) []T {
+	var hosts []string
+	byHost := make(map[string][]T)
+	for _, item := range items {
+		h := host(item)
+		if _, ok := byHost[h]; !ok {
+			hosts = append(hosts, h)
+		}
+		byHost[h] = append(byHost[h], item)
+	}
+	out := make([]T, 0, len(items))
+	for len(out) < len(items) {
+		for _, h := range hosts {
+			if queue := byHost[h]; len(queue) > 0 {
+				out = append(out, queue[0])
+				byHost[h] = queue[1:]
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// hostBlockingAdapter holds every fetch until release is closed and tracks
+// how many fetches run at once per host (the first path element of dir).
+type hostBlockingAdapter struct {
+	*planAdapter
+	release chan struct{}
+	mu      sync.Mutex
+	active  map[string]int
+	peak    map[string]int
+	total   int
+}
+
+func (a *hostBlockingAdapter) Fetch(_ context.Context, dir string) error {
+	host := strings.Split(strings.TrimPrefix(dir, "/"), "/")[0]
+	a.mu.Lock()
+	a.active[host]++
+	a.total++
+	a.peak[host] = max(a.peak[host], a.active[host])
+	a.mu.Unlock()
+	<-a.release
+	a.mu.Lock()
+	a.active[host]--
+	a.total--
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *hostBlockingAdapter) running() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+func TestSyncPerHostConcurrencyCapsEachHost(t *testing.T) {
+	reg := &registry.Registry{}
+	for i := range 6 {
+		reg.Entries = append(reg.Entries, registry.Entry{
+			RepoID:    fmt.Sprintf("github.com/org/r%d", i),
+			Path:      fmt.Sprintf("/github.com/r%d", i),
+			RemoteURL: fmt.Sprintf("git@github.com:org/r%d.git", i),
+			Status:    registry.StatusPresent,
+		})
+	}
+	for i := range 2 {
+		reg.Entries = append(reg.Entries, registry.Entry{
+			RepoID:    fmt.Sprintf("gitlab.com/org/r%d", i),
+			Path:      fmt.Sprintf("/gitlab.com/r%d", i),
+			RemoteURL: fmt.Sprintf("https://gitlab.com/org/r%d.git", i),
+			Status:    registry.StatusPresent,
+		})
+	}
+	adapter := &hostBlockingAdapter{
+		planAdapter: &planAdapter{},
+		release:     make(chan struct{}),
+		active:      map[string]int{},
+		peak:        map[string]int{},
+	}
+	eng := New(&config.Config{}, reg, adapter, nil, nil, nil)
+
+	done := make(chan []SyncResult, 1)
+	go func() {
+		results, err := eng.Sync(context.Background(), SyncOptions{
+			Filter:             FilterAll,
+			Concurrency:        6,
+			MaxJobs:            6,
+			ContinueOnError:    true,
+			PerHostConcurrency: 2,
+		})
+		if err != nil {
+			t.Errorf("sync: %v", err)
+		}
+		done <- results
+	}()
+
+	// Two github.com and both gitlab.com fetches can start; the third
+	// github.com repo must wait even though global slots are free.
+	deadline := time.Now().Add(5 * time.Second)
+	for adapter.running() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := adapter.running(); got != 4 {
+		t.Fatalf("expected 4 fetches in flight (2 per host), got %d", got)
+	}
+	close(adapter.release)
+	results := <-done
+
+	if len(results) != 8 {
+		t.Fatalf("expected 8 results, got %d", len(results))
+	}
+	if adapter.peak["github.com"] != 2 || adapter.peak["gitlab.com"] != 2 {
+		t.Fatalf("expected at most 2 concurrent fetches per host, got %v", adapter.peak)
+	}
+}
+
+func TestSyncHostIgnoresLocalRemotes(t *testing.T) {
+	eng := newPlanExecEngine(&planAdapter{})
+	cases := map[string]string{
+		"git@GitHub.com:org/repo.git":     "github.com",
+		"https://gitlab.com/org/repo.git": "gitlab.com",
+		"/srv/git/repo.git":               "",
+		"./repo.git":                      "",
+		"file:///srv/git/repo.git":        "",
+	}
+	for remote, want := range cases {
+		if got := eng.syncHost("", remote); got != want {
+			t.Fatalf("syncHost(%q) = %q, want %q", remote, got, want)
+		}
+	}
+	if got := eng.syncHost("local:/work/repo", ""); got != "" {
+		t.Fatalf("expected local-only repos to have no host, got %q", got)
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	items := []string{"a/1", "a/2", "a/3", "b/1", "c/1", "b/2"}
+	got := interleaveByHost(items, func(item string) string { return strings.Split(item, "/")[0] })
+	want := []string{"a/1", "b/1", "c/1", "a/2", "b/2", "a/3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("interleaveByHost() = %v, want %v", got, want)
+	}
+}
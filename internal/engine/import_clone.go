@@ -7,12 +7,15 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/skaphos/repokeeper/internal/config"
 	"github.com/skaphos/repokeeper/internal/pathutil"
 	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
 )
 
 type ImportCloneOptions struct {
@@ -20,6 +23,12 @@ type ImportCloneOptions struct {
 	BundleRoot                string
 	DangerouslyDeleteExisting bool
 	ResolveTargetRelativePath func(entry registry.Entry, root string) string
+	// AllowDefaultBranch clones checkouts without a configured branch at the
+	// remote's default branch instead of skipping them.
+	AllowDefaultBranch bool
+	// Depth, when positive, clones checkouts shallowly with that many commits
+	// of a single branch. Mirrors are always cloned in full.
+	Depth int
 }
 
 type ImportCloneTarget struct {
@@ -36,8 +45,20 @@ type ImportCloneSkip struct {
 type ImportClonePlan struct {
 	CWD                       string
 	DangerouslyDeleteExisting bool
-	Clones                    []ImportCloneTarget
-	Skipped                   []ImportCloneSkip
+	// Depth is the shallow-clone depth for checkout targets; zero clones full
+	// history.
+	Depth  int
+	Clones []ImportCloneTarget
+	// Existing holds targets that already hold a clone of the entry's remote,
+	// typically left by an interrupted import. They are registered as present
+	// without cloning, so re-running an import resumes it.
+	Existing []ImportCloneTarget
+	Skipped  []ImportCloneSkip
+	// Concurrency and MaxJobs bound how many clones ExecuteImportClones runs
+	// at once. They resolve as they do for sync, so zero values use the
+	// config defaults.
+	Concurrency int
+	MaxJobs     int
 }
 
 type ImportCloneCallbacks struct {
@@ -45,7 +66,7 @@ type ImportCloneCallbacks struct {
 	OnComplete SyncResultCallback
 }
 
-func (e *Engine) PlanImportClones(entries []registry.Entry, opts ImportCloneOptions) (ImportClonePlan, error) {
+func (e *Engine) PlanImportClones(ctx context.Context, entries []registry.Entry, opts ImportCloneOptions) (ImportClonePlan, error) {
 	if e.registry == nil {
 		return ImportClonePlan{}, nil
 	}
@@ -64,6 +85,7 @@ func (e *Engine) PlanImportClones(entries []registry.Entry, opts ImportCloneOpti
 	ignored := ignoredImportPathSet(e.cfg)
 	targets := make(map[string]ImportCloneTarget, len(entries))
 	skipped := make(map[string]ImportCloneSkip)
+	existing := make(map[string]bool)
 
 	for _, entry := range entries {
 		targetRel := strings.TrimSpace(entry.Path)
@@ -100,14 +122,18 @@ func (e *Engine) PlanImportClones(entries []registry.Entry, opts ImportCloneOpti
 			continue
 		}
 
-		if entry.Type != "mirror" && strings.TrimSpace(entry.Branch) == "" {
+		if entry.Type != "mirror" && strings.TrimSpace(entry.Branch) == "" && !opts.AllowDefaultBranch {
 			skipped[targetKey] = ImportCloneSkip{Path: target, Entry: entry, Reason: "no upstream branch configured"}
 			continue
 		}
+
+		if e.importTargetAlreadyCloned(ctx, target, entry.RemoteURL) {
+			existing[targetKey] = true
+		}
 	}
 
 	if !opts.DangerouslyDeleteExisting {
-		conflicts := findImportCloneConflicts(targets, skipped)
+		conflicts := findImportCloneConflicts(targets, skipped, existing)
 		if len(conflicts) > 0 {
 			lines := make([]string, 0, len(conflicts))
 			for _, conflict := range conflicts {
@@ -130,7 +156,9 @@ func (e *Engine) PlanImportClones(entries []registry.Entry, opts ImportCloneOpti
 	plan := ImportClonePlan{
 		CWD:                       cwd,
 		DangerouslyDeleteExisting: opts.DangerouslyDeleteExisting,
-		Clones:                    make([]ImportCloneTarget, 0, len(targets)-len(skipped)),
+		Depth:                     opts.Depth,
+		Clones:                    make([]ImportCloneTarget, 0, len(targets)-len(skipped)-len(existing)),
+		Existing:                  make([]ImportCloneTarget, 0, len(existing)),
 		Skipped:                   make([]ImportCloneSkip, 0, len(skipped)),
 	}
 	for _, key := range keys {
@@ -138,21 +166,25 @@ func (e *Engine) PlanImportClones(entries []registry.Entry, opts ImportCloneOpti
 			plan.Skipped = append(plan.Skipped, skip)
 			continue
 		}
+		if existing[key] {
+			plan.Existing = append(plan.Existing, targets[key])
+			continue
+		}
 		plan.Clones = append(plan.Clones, targets[key])
 	}
 
 	return plan, nil
 }
 
+// ExecuteImportClones clones plan.Clones on a bounded worker pool and then
+// registers the existing and skipped targets. Target paths are prepared in
+// plan order before any clone starts, and registry entries are written in plan
+// order once every clone has finished, so the resulting registry does not
+// depend on which clone completes first. Failures are returned sorted by path.
 func (e *Engine) ExecuteImportClones(ctx context.Context, plan ImportClonePlan, callbacks ImportCloneCallbacks) ([]SyncResult, error) {
 	failures := make([]SyncResult, 0)
 
 	for _, target := range plan.Clones {
-		entry := target.Entry
-		result := SyncResult{RepoID: entry.RepoID, Path: target.Path, Action: "git clone"}
-		if callbacks.OnStart != nil {
-			callbacks.OnStart(result)
-		}
 		if err := os.MkdirAll(filepath.Dir(target.Path), 0o755); err != nil {
 			return failures, err
 		}
@@ -166,29 +198,51 @@ func (e *Engine) ExecuteImportClones(ctx context.Context, plan ImportClonePlan,
 		} else if !os.IsNotExist(err) {
 			return failures, err
 		}
+	}
 
-		if err := e.adapter.Clone(ctx, strings.TrimSpace(entry.RemoteURL), target.Path, strings.TrimSpace(entry.Branch), entry.Type == "mirror"); err != nil {
-			result.OK = false
-			result.ErrorClass = e.classifier.ClassifyError(err)
-			result.Error = importCloneFailureMessage(result.ErrorClass)
-			identity := entry
-			entry.Path = target.Path
+	concurrency, _ := e.syncRuntime(SyncOptions{Concurrency: plan.Concurrency, MaxJobs: plan.MaxJobs})
+	results := make([]SyncResult, len(plan.Clones))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range plan.Clones {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, target ImportCloneTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.cloneImportTarget(ctx, target, plan.Depth, callbacks)
+		}(i, target)
+	}
+	wg.Wait()
+
+	for i, target := range plan.Clones {
+		result := results[i]
+		identity := target.Entry
+		entry := target.Entry
+		entry.Path = target.Path
+		entry.Status = registry.StatusPresent
+		if !result.OK {
 			entry.Status = registry.StatusMissing
-			entry.LastSeen = time.Now()
-			e.setImportRegistryEntry(identity, entry)
 			failures = append(failures, result)
-			if callbacks.OnComplete != nil {
-				callbacks.OnComplete(result)
-			}
-			continue
 		}
+		entry.LastSeen = time.Now()
+		e.setImportRegistryEntry(identity, entry)
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].Path < failures[j].Path
+	})
 
+	for _, target := range plan.Existing {
+		entry := target.Entry
+		result := SyncResult{RepoID: entry.RepoID, Path: target.Path, Action: "already cloned", OK: true}
+		if callbacks.OnStart != nil {
+			callbacks.OnStart(result)
+		}
 		identity := entry
 		entry.Path = target.Path
 		entry.Status = registry.StatusPresent
 		entry.LastSeen = time.Now()
 		e.setImportRegistryEntry(identity, entry)
-		result.OK = true
 		if callbacks.OnComplete != nil {
 			callbacks.OnComplete(result)
 		}
@@ -212,15 +266,43 @@ func (e *Engine) ExecuteImportClones(ctx context.Context, plan ImportClonePlan,
 	return failures, nil
 }
 
+// cloneImportTarget clones one planned target and reports it through
+// callbacks. It leaves the registry alone; ExecuteImportClones records every
+// outcome once all clones are done.
+func (e *Engine) cloneImportTarget(ctx context.Context, target ImportCloneTarget, depth int, callbacks ImportCloneCallbacks) SyncResult {
+	entry := target.Entry
+	mirror := entry.Type == "mirror"
+	if mirror {
+		depth = 0
+	}
+	result := SyncResult{RepoID: entry.RepoID, Path: target.Path, Action: "git clone", CloneDepth: depth}
+	if depth > 0 {
+		result.Action += " --depth " + strconv.Itoa(depth)
+	}
+	if callbacks.OnStart != nil {
+		callbacks.OnStart(result)
+	}
+	if err := e.cloneRepo(ctx, strings.TrimSpace(entry.RemoteURL), target.Path, strings.TrimSpace(entry.Branch), mirror, depth); err != nil {
+		result.ErrorClass = e.classifier.ClassifyError(err)
+		result.Error = importCloneFailureMessage(result.ErrorClass)
+	} else {
+		result.OK = true
+	}
+	if callbacks.OnComplete != nil {
+		callbacks.OnComplete(result)
+	}
+	return result
+}
+
 type importCloneConflict struct {
 	target string
 	entry  registry.Entry
 }
 
-func findImportCloneConflicts(targets map[string]ImportCloneTarget, skipped map[string]ImportCloneSkip) []importCloneConflict {
+func findImportCloneConflicts(targets map[string]ImportCloneTarget, skipped map[string]ImportCloneSkip, existing map[string]bool) []importCloneConflict {
 	conflicts := make([]importCloneConflict, 0)
 	for key, plan := range targets {
-		if _, skip := skipped[key]; skip {
+		if _, skip := skipped[key]; skip || existing[key] {
 			continue
 		}
 		if _, err := os.Stat(plan.Path); err == nil {
@@ -233,6 +315,47 @@ func findImportCloneConflicts(targets map[string]ImportCloneTarget, skipped map[
 	return conflicts
 }
 
+// importTargetAlreadyCloned reports whether target is already a repository
+// with a remote pointing at remoteURL and a HEAD that resolves to a commit.
+// Remote URLs are compared by their normalized repo identity, so an SSH clone
+// satisfies an HTTPS bundle entry. A partial or unrelated directory is not a
+// match and stays a conflict; that includes a clone killed before its first
+// checkout, which already has the remote configured but no commits.
+func (e *Engine) importTargetAlreadyCloned(ctx context.Context, target, remoteURL string) bool {
+	if _, err := os.Stat(target); err != nil {
+		return false
+	}
+	if ok, err := e.adapter.IsRepo(ctx, target); err != nil || !ok {
+		return false
+	}
+	inspector, ok := e.adapter.(vcs.LastCommitInspector)
+	if !ok {
+		return false
+	}
+	if _, err := inspector.LastCommit(ctx, target); err != nil {
+		return false
+	}
+	remotes, err := e.adapter.Remotes(ctx, target)
+	if err != nil {
+		return false
+	}
+	want := e.importRemoteIdentity(remoteURL)
+	for _, remote := range remotes {
+		if e.importRemoteIdentity(remote.URL) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Engine) importRemoteIdentity(url string) string {
+	url = strings.TrimSpace(url)
+	if e.normalizer == nil || url == "" {
+		return url
+	}
+	return e.normalizer.NormalizeURL(url)
+}
+
 func ignoredImportPathSet(cfg *config.Config) map[string]bool {
 	if cfg == nil {
 		return make(map[string]bool)
@@ -249,6 +372,8 @@ func importCloneFailureMessage(errorClass string) string {
 	switch strings.TrimSpace(errorClass) {
 	case "auth":
 		return "import-clone-auth"
+	case "host_key":
+		return "import-clone-host-key"
 	case "network":
 		return "import-clone-network"
 	case "timeout":
@@ -257,6 +382,10 @@ func importCloneFailureMessage(errorClass string) string {
 		return "import-clone-corrupt"
 	case "missing_remote":
 		return "import-clone-missing-remote"
+	case "disk_full":
+		return "import-clone-disk-full"
+	case "fs_permission":
+		return "import-clone-fs-permission"
 	default:
 		return "import-clone-failed"
 	}
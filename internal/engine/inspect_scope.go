@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+// InspectScope selects how much of a repo an inspection gathers.
+type InspectScope int
+
+const (
+	// InspectFull gathers the complete status: tracking, submodules, local
+	// branches, stashes, and the rest of model.RepoStatus.
+	InspectFull InspectScope = iota
+	// InspectWorktree stops after identity, remotes, HEAD, worktree status,
+	// and the shallow state, which is read from the git dir. Tracking is
+	// reported as none. It is enough to decide the dirty filter and skips the
+	// git calls that dominate a full inspection.
+	InspectWorktree
+)
+
+// statusInspectScope is the scope a status worker inspects with first. The
+// dirty filter only needs the worktree, so repos it rejects are never fully
+// inspected; matching repos are completed before they are reported. Cached
+// runs always inspect fully because the cache stores what was inspected.
+func statusInspectScope(opts StatusOptions) InspectScope {
+	if opts.Filter == FilterDirty && opts.Cache == nil {
+		return InspectWorktree
+	}
+	return InspectFull
+}
+
+// syncInspectScope is the scope the sync filter inspects with first; see
+// statusInspectScope.
+func syncInspectScope(filter FilterKind) InspectScope {
+	if filter == FilterDirty {
+		return InspectWorktree
+	}
+	return InspectFull
+}
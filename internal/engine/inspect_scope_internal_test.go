@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// dirtyCountingAdapter reports repos under /dirty as dirty and counts
+// TrackingStatus calls per path.
+type dirtyCountingAdapter struct {
+	benchAdapter
+	mu       sync.Mutex
+	tracking map[string]int
+}
+
+func (a *dirtyCountingAdapter) WorktreeStatus(_ context.Context, dir string) (*model.Worktree, error) {
+	return &model.Worktree{Dirty: dir == "/dirty"}, nil
+}
+
+func (a *dirtyCountingAdapter) TrackingStatus(ctx context.Context, dir string) (model.Tracking, error) {
+	a.mu.Lock()
+	a.tracking[dir]++
+	a.mu.Unlock()
+	return a.benchAdapter.TrackingStatus(ctx, dir)
+}
+
+func newDirtyCountingEngine() (*Engine, *dirtyCountingAdapter) {
+	adapter := &dirtyCountingAdapter{tracking: map[string]int{}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "clean", Path: "/clean", Status: registry.StatusPresent},
+		{RepoID: "dirty", Path: "/dirty", Status: registry.StatusPresent},
+	}}
+	return New(&config.Config{}, reg, adapter, nil, nil, nil), adapter
+}
+
+func TestInspectRepoScopeWorktreeSkipsTracking(t *testing.T) {
+	eng, adapter := newDirtyCountingEngine()
+	status, err := eng.InspectRepoScope(context.Background(), "/dirty", InspectWorktree)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if status.Worktree == nil || !status.Worktree.Dirty || status.Head.Branch != "main" {
+		t.Fatalf("expected worktree and head, got %+v", status)
+	}
+	if status.Tracking.Status != model.TrackingNone || adapter.tracking["/dirty"] != 0 {
+		t.Fatalf("expected tracking to be skipped, got %+v after %d calls", status.Tracking, adapter.tracking["/dirty"])
+	}
+}
+
+func TestStatusDirtyFilterInspectsOnlyMatchingReposFully(t *testing.T) {
+	eng, adapter := newDirtyCountingEngine()
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterDirty})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if len(report.Repos) != 1 || report.Repos[0].Path != "/dirty" {
+		t.Fatalf("expected only the dirty repo, got %+v", report.Repos)
+	}
+	if report.Repos[0].Tracking.Status != model.TrackingEqual {
+		t.Fatalf("expected the reported repo to carry tracking, got %+v", report.Repos[0].Tracking)
+	}
+	if adapter.tracking["/clean"] != 0 || adapter.tracking["/dirty"] != 1 {
+		t.Fatalf("unexpected tracking calls: %+v", adapter.tracking)
+	}
+
+	adapter.tracking = map[string]int{}
+	if _, err := eng.Status(context.Background(), StatusOptions{Filter: FilterDirty, Cache: &StatusCache{}}); err != nil {
+		t.Fatalf("cached status: %v", err)
+	}
+	if adapter.tracking["/clean"] != 1 {
+		t.Fatalf("expected cached runs to inspect fully, got %+v", adapter.tracking)
+	}
+}
+
+func TestSyncDirtyFilterInspectsOnlyMatchingReposFully(t *testing.T) {
+	eng, adapter := newDirtyCountingEngine()
+	for _, path := range []string{"/clean", "/dirty"} {
+		entry := registry.Entry{RepoID: path, Path: path, RemoteURL: "git@github.com:org/repo.git", Status: registry.StatusPresent}
+		matches, status, failure := eng.syncEntryMatchesInspectFilter(context.Background(), entry, SyncOptions{Filter: FilterDirty})
+		if failure != nil {
+			t.Fatalf("%s: unexpected failure %+v", path, failure)
+		}
+		if matches != (path == "/dirty") {
+			t.Fatalf("%s: matches=%t", path, matches)
+		}
+		if matches && status.Tracking.Status != model.TrackingEqual {
+			t.Fatalf("expected the reused status to carry tracking, got %+v", status.Tracking)
+		}
+	}
+	if adapter.tracking["/clean"] != 0 || adapter.tracking["/dirty"] != 1 {
+		t.Fatalf("unexpected tracking calls: %+v", adapter.tracking)
+	}
+}
@@ -61,6 +61,37 @@ var _ = Describe("Engine integration", func() {
 		Expect(status.Tracking.Status).To(Equal(model.TrackingNone))
 	})
 
+	It("marks linked worktrees and their shared git dir", func() {
+		base, err := filepath.EvalSymlinks(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		main := filepath.Join(base, "main")
+		linked := filepath.Join(base, "linked")
+
+		runGit("", "init", main)
+		runGit(main, "config", "user.email", "test@example.com")
+		runGit(main, "config", "user.name", "RepoKeeper Test")
+		writeFile(filepath.Join(main, "file.txt"), "initial\n")
+		runGit(main, "add", "file.txt")
+		runGit(main, "commit", "-m", "init")
+		runGit(main, "worktree", "add", "-b", "feature", linked)
+
+		eng := engine.New(&config.Config{Defaults: config.Defaults{TimeoutSeconds: 5, Concurrency: 1}}, &registry.Registry{}, vcs.NewGitAdapter(nil), nil, nil, nil)
+		mainStatus, err := eng.InspectRepo(context.Background(), main)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mainStatus.WorktreeRole).To(Equal(model.WorktreeRoleMain))
+		Expect(mainStatus.GitCommonDir).To(Equal(filepath.Join(main, ".git")))
+
+		linkedStatus, err := eng.InspectRepo(context.Background(), linked)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(linkedStatus.WorktreeRole).To(Equal(model.WorktreeRoleLinked))
+		Expect(linkedStatus.GitCommonDir).To(Equal(mainStatus.GitCommonDir))
+		Expect(linkedStatus.Head.Branch).To(Equal("feature"))
+
+		results, err := eng.Scan(context.Background(), engine.ScanOptions{Roots: []string{base}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+	})
+
 	It("reports missing registry entries in status output", func() {
 		base := GinkgoT().TempDir()
 		missing := filepath.Join(base, "missing-repo")
@@ -112,6 +143,30 @@ var _ = Describe("Engine integration", func() {
 		Expect(status).To(ContainSubstring("file.txt"))
 	})
 
+	It("captures raw command output on the direct sync path", func() {
+		base := GinkgoT().TempDir()
+		remote := filepath.Join(base, "remote.git")
+		work := filepath.Join(base, "work")
+
+		runGit("", "init", "--bare", remote)
+		runGit("", "clone", remote, work)
+
+		reg := &registry.Registry{
+			Entries: []registry.Entry{
+				{RepoID: "repo1", Path: work, RemoteURL: remote, Status: registry.StatusPresent},
+			},
+		}
+		eng := engine.New(&config.Config{Defaults: config.Defaults{TimeoutSeconds: 5, Concurrency: 1}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+		results, err := eng.Sync(context.Background(), engine.SyncOptions{Concurrency: 1, Timeout: 5, CaptureOutput: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].RawOutput).To(ContainSubstring(" fetch "))
+
+		results, err = eng.Sync(context.Background(), engine.SyncOptions{Concurrency: 1, Timeout: 5})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results[0].RawOutput).To(BeEmpty())
+	})
+
 	It("prunes stale remote-tracking branches", func() {
 		base := GinkgoT().TempDir()
 		remote := filepath.Join(base, "remote.git")
@@ -163,6 +218,99 @@ var _ = Describe("Engine integration", func() {
 		Expect(status.RemoteTrackingRefs.StaleCount).To(BeZero())
 	})
 
+	It("keeps local tags with NoPruneTags and counts pruned tags without it", func() {
+		base := GinkgoT().TempDir()
+		remote := filepath.Join(base, "remote.git")
+		work := filepath.Join(base, "work")
+		other := filepath.Join(base, "other")
+
+		runGit("", "init", "--bare", remote)
+		runGit("", "clone", remote, other)
+		runGit(other, "config", "user.email", "test@example.com")
+		runGit(other, "config", "user.name", "RepoKeeper Test")
+		writeFile(filepath.Join(other, "file.txt"), "base\n")
+		runGit(other, "add", "file.txt")
+		runGit(other, "commit", "-m", "base")
+		runGit(other, "branch", "-M", "main")
+		runGit(other, "tag", "v1")
+		runGit(other, "push", "origin", "main", "v1")
+		runGit("", "clone", remote, work)
+		runGit(work, "tag", "local-only", "origin/main")
+		runGit(other, "push", "origin", "--delete", "v1")
+
+		reg := &registry.Registry{
+			Entries: []registry.Entry{
+				{RepoID: "repo1", Path: work, RemoteURL: remote, Status: registry.StatusPresent},
+			},
+		}
+		eng := engine.New(&config.Config{Defaults: config.Defaults{TimeoutSeconds: 5, Concurrency: 1}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+
+		results, err := eng.Sync(context.Background(), engine.SyncOptions{Concurrency: 1, Timeout: 5, NoPruneTags: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].TagsPruned).To(BeZero())
+		Expect(strings.Fields(runGit(work, "tag", "--list"))).To(ConsistOf("local-only", "v1"))
+
+		results, err = eng.Sync(context.Background(), engine.SyncOptions{Concurrency: 1, Timeout: 5})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results[0].TagsPruned).To(Equal(2))
+		Expect(strings.TrimSpace(runGit(work, "tag", "--list"))).To(BeEmpty())
+	})
+
+	It("creates the backup branch at the pre-rebase tip before rebasing a diverged branch", func() {
+		base := GinkgoT().TempDir()
+		remote := filepath.Join(base, "remote.git")
+		work := filepath.Join(base, "work")
+		other := filepath.Join(base, "other")
+
+		runGit("", "init", "--bare", remote)
+		runGit("", "clone", remote, other)
+		runGit(other, "config", "user.email", "test@example.com")
+		runGit(other, "config", "user.name", "RepoKeeper Test")
+		writeFile(filepath.Join(other, "file.txt"), "base\n")
+		runGit(other, "add", "file.txt")
+		runGit(other, "commit", "-m", "base")
+		runGit(other, "branch", "-M", "main")
+		runGit(other, "push", "-u", "origin", "main")
+		runGit("", "clone", "--branch", "main", remote, work)
+		runGit(work, "config", "user.email", "test@example.com")
+		runGit(work, "config", "user.name", "RepoKeeper Test")
+
+		writeFile(filepath.Join(work, "local.txt"), "local\n")
+		runGit(work, "add", "local.txt")
+		runGit(work, "commit", "-m", "local")
+		writeFile(filepath.Join(other, "remote.txt"), "remote\n")
+		runGit(other, "add", "remote.txt")
+		runGit(other, "commit", "-m", "remote")
+		runGit(other, "push", "origin", "main")
+		runGit(work, "fetch", "origin")
+		preRebase := strings.TrimSpace(runGit(work, "rev-parse", "HEAD"))
+
+		reg := &registry.Registry{
+			Entries: []registry.Entry{
+				{RepoID: "repo1", Path: work, RemoteURL: remote, Status: registry.StatusPresent},
+			},
+		}
+		eng := engine.New(&config.Config{Defaults: config.Defaults{TimeoutSeconds: 5, Concurrency: 1}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+
+		results, err := eng.Sync(context.Background(), engine.SyncOptions{
+			Concurrency:  1,
+			Timeout:      5,
+			UpdateLocal:  true,
+			Force:        true,
+			BackupBranch: "backup/{branch}-{timestamp}",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].OK).To(BeTrue(), results[0].Error)
+		Expect(results[0].Outcome).To(Equal(engine.SyncOutcomeRebased), results[0].SkipReason)
+		Expect(results[0].BackupBranch).To(HavePrefix("backup/main-"))
+
+		Expect(strings.TrimSpace(runGit(work, "rev-parse", "refs/heads/"+results[0].BackupBranch))).To(Equal(preRebase))
+		Expect(strings.TrimSpace(runGit(work, "rev-parse", "HEAD"))).NotTo(Equal(preRebase))
+		Expect(strings.TrimSpace(runGit(work, "rev-parse", "--abbrev-ref", "HEAD"))).To(Equal("main"))
+	})
+
 	It("reports tracking gone after upstream branch is deleted", func() {
 		base := GinkgoT().TempDir()
 		remote := filepath.Join(base, "remote.git")
@@ -206,6 +354,87 @@ var _ = Describe("Engine integration", func() {
 		Expect(report.Repos[0].Tracking.Status).To(Equal(model.TrackingGone))
 	})
 
+	It("deletes only merged gone branches and never the checked-out branch", func() {
+		base := GinkgoT().TempDir()
+		remote := filepath.Join(base, "remote.git")
+		work := filepath.Join(base, "work")
+		runGit("", "init", "--bare", remote)
+		runGit("", "clone", remote, work)
+		runGit(work, "config", "user.email", "test@example.com")
+		runGit(work, "config", "user.name", "RepoKeeper Test")
+		writeFile(filepath.Join(work, "file.txt"), "base\n")
+		runGit(work, "add", "file.txt")
+		runGit(work, "commit", "-m", "base")
+		runGit(work, "branch", "-M", "main")
+		runGit(work, "push", "-u", "origin", "main")
+		runGit("", "--git-dir", remote, "symbolic-ref", "HEAD", "refs/heads/main")
+
+		// merged: pushed, merged into main, then deleted on the remote.
+		runGit(work, "checkout", "-b", "merged")
+		writeFile(filepath.Join(work, "merged.txt"), "merged\n")
+		runGit(work, "add", "merged.txt")
+		runGit(work, "commit", "-m", "merged work")
+		runGit(work, "push", "-u", "origin", "merged")
+		runGit(work, "checkout", "main")
+		runGit(work, "merge", "--no-ff", "-m", "merge merged", "merged")
+		runGit(work, "push", "origin", "main")
+
+		// unmerged: pushed and deleted on the remote without ever landing.
+		runGit(work, "checkout", "-b", "unmerged")
+		writeFile(filepath.Join(work, "unmerged.txt"), "unmerged\n")
+		runGit(work, "add", "unmerged.txt")
+		runGit(work, "commit", "-m", "unmerged work")
+		runGit(work, "push", "-u", "origin", "unmerged")
+		runGit(work, "checkout", "main")
+
+		runGit(work, "push", "origin", "--delete", "merged", "unmerged")
+		runGit(work, "fetch", "--prune", "origin")
+
+		reg := &registry.Registry{
+			Entries: []registry.Entry{
+				{RepoID: "repo1", Path: work, RemoteURL: remote, Status: registry.StatusPresent},
+			},
+		}
+		eng := engine.New(&config.Config{Defaults: config.Defaults{TimeoutSeconds: 5, Concurrency: 1}}, reg, vcs.NewGitAdapter(nil), nil, nil, nil)
+		opts := engine.GoneBranchesOptions{Concurrency: 1, Timeout: 5}
+
+		forced, err := eng.PlanGoneBranchDeletions(context.Background(), engine.GoneBranchesOptions{Concurrency: 1, Timeout: 5, Force: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(forced).To(HaveLen(2))
+		Expect(forced[1].Branch).To(Equal("unmerged"))
+		Expect(forced[1].Action).To(Equal(engine.GoneBranchForceDelete))
+
+		plans, err := eng.PlanGoneBranchDeletions(context.Background(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plans).To(HaveLen(2))
+		Expect(plans[0].Branch).To(Equal("merged"))
+		Expect(plans[0].Merged).To(BeTrue())
+		Expect(plans[0].Action).To(Equal(engine.GoneBranchDelete))
+		Expect(plans[1].Branch).To(Equal("unmerged"))
+		Expect(plans[1].Merged).To(BeFalse())
+		Expect(plans[1].Action).To(Equal(engine.GoneBranchSkip))
+		Expect(plans[1].Reason).To(Equal("not merged into origin/main"))
+
+		// Planning alone deletes nothing.
+		Expect(runGit(work, "branch", "--list", "merged")).NotTo(BeEmpty())
+
+		results := eng.DeleteGoneBranches(context.Background(), plans)
+		Expect(results[0].Deleted).To(BeTrue())
+		Expect(results[0].Error).To(BeEmpty())
+		Expect(results[1].Deleted).To(BeFalse())
+		Expect(runGit(work, "branch", "--list", "merged")).To(BeEmpty())
+		Expect(runGit(work, "branch", "--list", "unmerged")).NotTo(BeEmpty())
+		Expect(strings.TrimSpace(runGit(work, "branch", "--show-current"))).To(Equal("main"))
+
+		// A gone branch that is checked out is never planned for deletion.
+		runGit(work, "checkout", "unmerged")
+		plans, err = eng.PlanGoneBranchDeletions(context.Background(), engine.GoneBranchesOptions{Concurrency: 1, Timeout: 5, Force: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plans).To(HaveLen(1))
+		Expect(plans[0].Action).To(Equal(engine.GoneBranchSkip))
+		Expect(plans[0].Reason).To(Equal("checked out"))
+	})
+
 	It("marks deleted repository paths as missing after re-scan", func() {
 		base := GinkgoT().TempDir()
 		repoPath := filepath.Join(base, "repo")
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SavedSyncPlanVersion is the schema version written to saved plan files.
+// Bump it when a field changes meaning so older plans are rejected on load.
+const SavedSyncPlanVersion = 1
+
+// SavedSyncPlan is the portable form of a dry-run sync plan. It carries the
+// typed execution steps alongside the display fields so a reviewed plan can be
+// applied later without re-inspecting every repository.
+type SavedSyncPlan struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Items     []SavedSyncItem `json:"items"`
+}
+
+// SavedSyncItem is one repository entry in a SavedSyncPlan.
+type SavedSyncItem struct {
+	RepoID       string   `json:"repo_id"`
+	Path         string   `json:"path"`
+	Action       string   `json:"action"`
+	Outcome      string   `json:"outcome"`
+	OK           bool     `json:"ok"`
+	Planned      bool     `json:"planned"`
+	Error        string   `json:"error,omitempty"`
+	ErrorClass   string   `json:"error_class,omitempty"`
+	SkipReason   string   `json:"skip_reason,omitempty"`
+	Deepen       int      `json:"deepen,omitempty"`
+	FetchRemote  string   `json:"fetch_remote,omitempty"`
+	NoPruneTags  bool     `json:"no_prune_tags,omitempty"`
+	BackupBranch string   `json:"backup_branch,omitempty"`
+	CloneURL     string   `json:"clone_url,omitempty"`
+	CloneDepth   int      `json:"clone_depth,omitempty"`
+	ResetTarget  string   `json:"reset_target,omitempty"`
+	Steps        []string `json:"steps,omitempty"`
+}
+
+// NewSavedSyncPlan converts a plan returned by Sync into its portable form.
+func NewSavedSyncPlan(plan []SyncResult, createdAt time.Time) SavedSyncPlan {
+	saved := SavedSyncPlan{
+		Version:   SavedSyncPlanVersion,
+		CreatedAt: createdAt.UTC(),
+		Items:     make([]SavedSyncItem, 0, len(plan)),
+	}
+	for _, item := range plan {
+		steps := make([]string, 0, len(item.steps))
+		for _, step := range item.steps {
+			steps = append(steps, string(step))
+		}
+		saved.Items = append(saved.Items, SavedSyncItem{
+			RepoID:       item.RepoID,
+			Path:         item.Path,
+			Action:       item.Action,
+			Outcome:      string(item.Outcome),
+			OK:           item.OK,
+			Planned:      item.Planned,
+			Error:        item.Error,
+			ErrorClass:   item.ErrorClass,
+			SkipReason:   item.SkipReason,
+			Deepen:       item.Deepen,
+			FetchRemote:  item.FetchRemote,
+			NoPruneTags:  item.NoPruneTags,
+			BackupBranch: item.BackupBranch,
+			CloneURL:     item.CloneURL,
+			CloneDepth:   item.CloneDepth,
+			ResetTarget:  item.ResetTarget,
+			Steps:        steps,
+		})
+	}
+	return saved
+}
+
+// SyncResults rebuilds an executable plan from a saved plan. Unknown steps and
+// planned items without steps are rejected here rather than at execution time,
+// so a hand-edited or truncated file fails before anything runs.
+func (p SavedSyncPlan) SyncResults() ([]SyncResult, error) {
+	if p.Version != SavedSyncPlanVersion {
+		return nil, fmt.Errorf("unsupported sync plan version %d (expected %d)", p.Version, SavedSyncPlanVersion)
+	}
+	plan := make([]SyncResult, 0, len(p.Items))
+	for _, item := range p.Items {
+		res := SyncResult{
+			RepoID:       item.RepoID,
+			Path:         item.Path,
+			Action:       item.Action,
+			Outcome:      OutcomeKind(item.Outcome),
+			OK:           item.OK,
+			Planned:      item.Planned,
+			Error:        item.Error,
+			ErrorClass:   item.ErrorClass,
+			SkipReason:   item.SkipReason,
+			Deepen:       item.Deepen,
+			FetchRemote:  item.FetchRemote,
+			NoPruneTags:  item.NoPruneTags,
+			BackupBranch: item.BackupBranch,
+			CloneURL:     item.CloneURL,
+			CloneDepth:   item.CloneDepth,
+			ResetTarget:  item.ResetTarget,
+		}
+		if res.Deepen < 0 {
+			return nil, fmt.Errorf("repo %q: negative deepen %d", item.RepoID, res.Deepen)
+		}
+		if res.CloneDepth < 0 {
+			return nil, fmt.Errorf("repo %q: negative clone depth %d", item.RepoID, res.CloneDepth)
+		}
+		for _, raw := range item.Steps {
+			step, ok := parseSyncStep(raw)
+			if !ok {
+				return nil, fmt.Errorf("repo %q: unknown sync step %q", item.RepoID, raw)
+			}
+			res.steps = append(res.steps, step)
+		}
+		if res.Planned && len(res.steps) == 0 {
+			return nil, fmt.Errorf("repo %q: planned item has no steps", item.RepoID)
+		}
+		plan = append(plan, res)
+	}
+	return plan, nil
+}
+
+func parseSyncStep(raw string) (syncStep, bool) {
+	switch step := syncStep(raw); step {
+	case syncStepClone, syncStepFetch, syncStepBackupBranch, syncStepStashPush, syncStepPullRebase, syncStepStashPop, syncStepPush, syncStepAutostash, syncStepAutostashPop, syncStepLFSFetch, syncStepSubmoduleUpdate, syncStepMirrorUpdate, syncStepResetHard, syncStepCleanAll:
+		return step, true
+	}
+	return "", false
+}
+
+// ValidateSyncPlan checks that every repo in a saved plan still exists in the
+// loaded registry at the same path. A plan recorded against a different or
+// since-edited registry must not be applied to whatever now lives at its paths.
+func (e *Engine) ValidateSyncPlan(plan []SyncResult) error {
+	if e.registry == nil {
+		return errors.New("registry not loaded")
+	}
+	var unknown []string
+	for _, item := range plan {
+		entry := e.registry.FindEntry(item.RepoID, item.Path)
+		if entry == nil || entry.RepoID != item.RepoID || entry.Path != item.Path {
+			unknown = append(unknown, item.RepoID)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("sync plan does not match the current registry: %s", strings.Join(unknown, ", "))
+}
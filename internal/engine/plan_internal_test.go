@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestSavedSyncPlanRoundTripPreservesSteps(t *testing.T) {
+	plan := []SyncResult{
+		{
+			RepoID:      "github.com/org/repo",
+			Path:        "/repos/repo",
+			Action:      "git fetch --all --prune --prune-tags && git pull --rebase",
+			Outcome:     SyncOutcomePlannedFetch,
+			OK:          true,
+			Planned:     true,
+			Deepen:      10,
+			FetchRemote: "upstream",
+			steps:       []syncStep{syncStepFetch, syncStepStashPush, syncStepPullRebase, syncStepStashPop},
+		},
+		{
+			RepoID:     "github.com/org/skipped",
+			Path:       "/repos/skipped",
+			Outcome:    SyncOutcomeSkippedNoUpstream,
+			OK:         true,
+			SkipReason: "no_upstream",
+		},
+	}
+
+	data, err := json.Marshal(NewSavedSyncPlan(plan, time.Unix(0, 0)))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var saved SavedSyncPlan
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	got, err := saved.SyncResults()
+	if err != nil {
+		t.Fatalf("SyncResults: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(got))
+	}
+	if len(got[0].steps) != 4 || got[0].steps[3] != syncStepStashPop {
+		t.Fatalf("expected steps to survive round trip, got %v", got[0].steps)
+	}
+	if got[0].Deepen != 10 || got[0].FetchRemote != "upstream" {
+		t.Fatalf("expected deepen and fetch remote to survive round trip, got %d %q", got[0].Deepen, got[0].FetchRemote)
+	}
+	if got[1].Planned || got[1].SkipReason != "no_upstream" || len(got[1].steps) != 0 {
+		t.Fatalf("unexpected skipped item: %+v", got[1])
+	}
+}
+
+func TestSavedSyncPlanRejectsInvalidItems(t *testing.T) {
+	cases := map[string]SavedSyncPlan{
+		"version":       {Version: SavedSyncPlanVersion + 1},
+		"unknown step":  {Version: SavedSyncPlanVersion, Items: []SavedSyncItem{{RepoID: "a", Planned: true, Steps: []string{"force_push"}}}},
+		"missing steps": {Version: SavedSyncPlanVersion, Items: []SavedSyncItem{{RepoID: "a", Planned: true}}},
+	}
+	for name, saved := range cases {
+		if _, err := saved.SyncResults(); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestValidateSyncPlanRequiresMatchingRegistryEntries(t *testing.T) {
+	eng := newPlanExecEngine(nil)
+	eng.registry = &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/repo", Path: "/repos/repo"},
+	}}
+
+	if err := eng.ValidateSyncPlan([]SyncResult{{RepoID: "github.com/org/repo", Path: "/repos/repo"}}); err != nil {
+		t.Fatalf("expected matching plan to validate, got %v", err)
+	}
+	err := eng.ValidateSyncPlan([]SyncResult{
+		{RepoID: "github.com/org/repo", Path: "/elsewhere/repo"},
+		{RepoID: "github.com/org/gone", Path: "/repos/gone"},
+	})
+	if err == nil {
+		t.Fatal("expected mismatched plan to be rejected")
+	}
+	if !strings.Contains(err.Error(), "github.com/org/gone") || !strings.Contains(err.Error(), "github.com/org/repo") {
+		t.Fatalf("expected both repo IDs in error, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestApplyPostSyncHookSkipsSkippedAndFailedRepos(t *testing.T) {
+	eng := New(&config.Config{}, &registry.Registry{}, &planAdapter{}, nil, nil, nil)
+	var hooked []string
+	opts := SyncOptions{PostSync: func(_ context.Context, res SyncResult) error {
+		hooked = append(hooked, res.RepoID)
+		return nil
+	}}
+
+	for _, res := range []SyncResult{
+		{RepoID: "fetched", OK: true, Outcome: SyncOutcomeFetched},
+		{RepoID: "skipped", OK: true, Outcome: SyncOutcomeSkippedLocalUpdate, ErrorClass: "skipped"},
+		{RepoID: "failed", Outcome: SyncOutcomeFailedFetch, ErrorClass: "network"},
+	} {
+		eng.applyPostSyncHook(context.Background(), res, opts)
+	}
+	if len(hooked) != 1 || hooked[0] != "fetched" {
+		t.Fatalf("expected only the fetched repo to be hooked, got %v", hooked)
+	}
+
+	opts.PostSync = func(context.Context, SyncResult) error { return errors.New("exit status 3") }
+	res := eng.applyPostSyncHook(context.Background(), SyncResult{RepoID: "fetched", OK: true, Outcome: SyncOutcomeFetched, Warning: "autostash kept"}, opts)
+	if !res.OK || res.Warning != "autostash kept; post-sync hook failed: exit status 3" {
+		t.Fatalf("expected the hook error as a warning, got %+v", res)
+	}
+	opts.StrictHooks = true
+	res = eng.applyPostSyncHook(context.Background(), SyncResult{RepoID: "fetched", OK: true, Outcome: SyncOutcomeFetched}, opts)
+	if res.OK || res.Outcome != SyncOutcomeFailedHook || res.ErrorClass != "hook" {
+		t.Fatalf("expected a failed_hook result, got %+v", res)
+	}
+}
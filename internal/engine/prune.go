@@ -25,34 +25,7 @@ func (e *Engine) inspectLocalBranches(ctx context.Context, path, primary, repoID
 		return model.LocalBranchStatus{}
 	}
 
-	// baseName is normally an unqualified local branch name, but the
-	// branch_policy.base_branch override may be remote-qualified (e.g.
-	// "origin/main"). Separate the local name — used for classification, so the
-	// base branch is recognized by isBaseBranch — from the remote-tracking ref
-	// used for git reachability/patch queries, so a stale local base does not
-	// yield false "not merged" (ADR-0015) and we never double-prefix
-	// ("origin/origin/main").
-	baseName := e.resolveBaseBranchName(repoID, path, tracking)
-	primaryRemote := strings.TrimSpace(primary)
-	if primaryRemote == "" {
-		// Fall back to the remote implied by the upstream (e.g. "origin/main" ->
-		// "origin") so a remote-qualified base override still normalizes when the
-		// adapter reports no primary remote.
-		if up := strings.TrimSpace(tracking.Upstream); up != "" {
-			if i := strings.Index(up, "/"); i > 0 {
-				primaryRemote = up[:i]
-			}
-		}
-	}
-	localBase, queryBase := baseName, baseName
-	if primaryRemote != "" && baseName != "" {
-		if strings.HasPrefix(baseName, primaryRemote+"/") {
-			localBase = strings.TrimPrefix(baseName, primaryRemote+"/")
-		} else {
-			queryBase = primaryRemote + "/" + baseName
-		}
-	}
-
+	localBase, queryBase := e.localBranchBases(repoID, path, primary, tracking)
 	policy := e.branchPolicy(localBase)
 	// Patch-equivalence is a per-branch git cherry; it only changes
 	// classification when require_merged is disabled, so skip it otherwise to
@@ -97,21 +70,95 @@ func (e *Engine) inspectLocalBranches(ctx context.Context, path, primary, repoID
 	return model.LocalBranchStatus{Branches: branches}
 }
 
+// localBranchBases resolves the base branch for local-branch checks as two
+// names. The base is normally an unqualified local branch name, but the
+// branch_policy.base_branch override may be remote-qualified (e.g.
+// "origin/main"). The local name is used for classification, so the base branch
+// is recognized by isBaseBranch; the remote-tracking ref is used for git
+// reachability/patch queries, so a stale local base does not yield false "not
+// merged" (ADR-0015) and we never double-prefix ("origin/origin/main").
+func (e *Engine) localBranchBases(repoID, path, primary string, tracking model.Tracking) (localBase, queryBase string) {
+	baseName := e.resolveBaseBranchName(repoID, path, tracking)
+	primaryRemote := strings.TrimSpace(primary)
+	if primaryRemote == "" {
+		// Fall back to the remote implied by the upstream (e.g. "origin/main" ->
+		// "origin") so a remote-qualified base override still normalizes when the
+		// adapter reports no primary remote.
+		if up := strings.TrimSpace(tracking.Upstream); up != "" {
+			if i := strings.Index(up, "/"); i > 0 {
+				primaryRemote = up[:i]
+			}
+		}
+	}
+	localBase, queryBase = baseName, baseName
+	if primaryRemote != "" && baseName != "" {
+		if strings.HasPrefix(baseName, primaryRemote+"/") {
+			localBase = strings.TrimPrefix(baseName, primaryRemote+"/")
+		} else {
+			queryBase = primaryRemote + "/" + baseName
+		}
+	}
+	return localBase, queryBase
+}
+
+// countBranchesBehindBase records on each local branch how many commits it is
+// behind the base branch and counts the branches at least threshold behind.
+// The base branch itself is skipped, and merged branches are not counted:
+// they are prune candidates, not rebase candidates. It is read-only; a failed
+// count leaves that branch's BehindBase nil.
+func (e *Engine) countBranchesBehindBase(ctx context.Context, status *model.RepoStatus, threshold int) {
+	if status.Bare || len(status.LocalBranches.Branches) == 0 {
+		return
+	}
+	counter, ok := e.adapter.(vcs.BranchBehindCounter)
+	if !ok {
+		return
+	}
+	localBase, queryBase := e.localBranchBases(status.RepoID, status.Path, status.PrimaryRemote, status.Tracking)
+	if queryBase == "" {
+		return
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	for i := range status.LocalBranches.Branches {
+		branch := &status.LocalBranches.Branches[i]
+		if branch.Name == localBase {
+			continue
+		}
+		behind, err := counter.CommitsBehind(ctx, status.Path, queryBase, branch.Name)
+		if err != nil {
+			if e.logger != nil {
+				e.logger.Warnf("behind-base count failed for %s %s: %v", status.Path, branch.Name, err)
+			}
+			continue
+		}
+		branch.BehindBase = &behind
+		merged := branch.MergedIntoBase != nil && *branch.MergedIntoBase
+		if !merged && behind >= threshold {
+			status.LocalBranches.BehindBaseCount++
+		}
+	}
+}
+
 // resolveBaseBranchName resolves the merge-into-base reference for a repository,
 // mirroring repairResolveTargetBranch: an explicit config override wins, then the
 // registry's recorded branch, then the upstream-derived branch, then the
-// workspace default. Returns "" when nothing resolves.
+// entry's default_branch, then the workspace default. Returns "" when nothing
+// resolves.
 func (e *Engine) resolveBaseBranchName(repoID, path string, tracking model.Tracking) string {
 	if e.cfg != nil {
 		if b := strings.TrimSpace(e.cfg.BranchPolicy.BaseBranch); b != "" {
 			return b
 		}
 	}
+	defaultBranch := ""
 	if e.registry != nil {
 		if entry := e.registry.FindEntry(repoID, path); entry != nil {
 			if b := strings.TrimSpace(entry.Branch); b != "" {
 				return b
 			}
+			defaultBranch = strings.TrimSpace(entry.DefaultBranch)
 		}
 	}
 	if up := strings.TrimSpace(tracking.Upstream); up != "" {
@@ -119,6 +166,9 @@ func (e *Engine) resolveBaseBranchName(repoID, path string, tracking model.Track
 			return parts[1]
 		}
 	}
+	if defaultBranch != "" {
+		return defaultBranch
+	}
 	if e.cfg != nil {
 		if b := strings.TrimSpace(e.cfg.Defaults.MainBranch); b != "" {
 			return b
@@ -21,6 +21,8 @@ const (
 	RemoteMismatchReconcileRegistry = remotemismatch.ReconcileRegistry
 	// RemoteMismatchReconcileGit updates the git remote to match the registry.
 	RemoteMismatchReconcileGit = remotemismatch.ReconcileGit
+	// RemoteMismatchReconcileAddRemote adds the registry URL as an extra git remote.
+	RemoteMismatchReconcileAddRemote = remotemismatch.ReconcileAddRemote
 )
 
 // ParseRemoteMismatchReconcileMode validates and parses a reconcile mode flag value.
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// blockingAdapter blocks Head (status) or Fetch (sync) until the repo's
+// context is done, recording the time left on that context's deadline when
+// the call starts. The test cancels the run once every repo is blocked, so
+// no call waits for its deadline to pass.
+type blockingAdapter struct {
+	*planAdapter
+	blockHead bool
+	blocked   chan struct{}
+	mu        sync.Mutex
+	left      map[string]time.Duration
+}
+
+func (a *blockingAdapter) block(ctx context.Context, dir string) error {
+	left := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		left = time.Until(deadline)
+	}
+	a.mu.Lock()
+	a.left[dir] = left
+	a.mu.Unlock()
+	select {
+	case a.blocked <- struct{}{}:
+	case <-ctx.Done():
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (a *blockingAdapter) Head(ctx context.Context, dir string) (model.Head, error) {
+	if a.blockHead {
+		return model.Head{}, a.block(ctx, dir)
+	}
+	return model.Head{Branch: "main"}, nil
+}
+
+func (a *blockingAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
+	return model.Tracking{Status: model.TrackingEqual, Upstream: "origin/main"}, nil
+}
+
+func (a *blockingAdapter) Fetch(ctx context.Context, dir string) error {
+	return a.block(ctx, dir)
+}
+
+func TestRepoTimeoutOverridesRunTimeout(t *testing.T) {
+	entries := []registry.Entry{
+		{RepoID: "github.com/org/small", Path: "/small", RemoteURL: "https://github.com/org/small.git", Branch: "main", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/huge", Path: "/huge", RemoteURL: "https://github.com/org/huge.git", Branch: "main", Status: registry.StatusPresent, TimeoutSeconds: 600},
+	}
+	// cancelWhenBlocked cancels the run once both repos are blocked.
+	cancelWhenBlocked := func(adapter *blockingAdapter) context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for range entries {
+				<-adapter.blocked
+			}
+			cancel()
+		}()
+		return ctx
+	}
+	assertDeadlines := func(t *testing.T, left map[string]time.Duration) {
+		t.Helper()
+		if got := left["/small"]; got <= 0 || got > 30*time.Second {
+			t.Fatalf("expected /small to use the 30s run timeout, %s left", got)
+		}
+		if got := left["/huge"]; got <= 30*time.Second || got > 600*time.Second {
+			t.Fatalf("expected /huge to use its own 600s timeout, %s left", got)
+		}
+	}
+
+	t.Run("status", func(t *testing.T) {
+		adapter := &blockingAdapter{planAdapter: &planAdapter{}, blockHead: true, blocked: make(chan struct{}), left: map[string]time.Duration{}}
+		eng := New(&config.Config{}, &registry.Registry{Entries: entries}, adapter, nil, nil, nil)
+		if _, err := eng.Status(cancelWhenBlocked(adapter), StatusOptions{Concurrency: 2, Timeout: 30}); err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		assertDeadlines(t, adapter.left)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		adapter := &blockingAdapter{planAdapter: &planAdapter{}, blocked: make(chan struct{}), left: map[string]time.Duration{}}
+		eng := New(&config.Config{}, &registry.Registry{Entries: entries}, adapter, nil, nil, nil)
+		results, err := eng.Sync(cancelWhenBlocked(adapter), SyncOptions{Concurrency: 2, Timeout: 30, ContinueOnError: true})
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		for _, res := range results {
+			if res.OK {
+				t.Fatalf("expected the cancelled fetch to fail, got %+v", res)
+			}
+		}
+		assertDeadlines(t, adapter.left)
+	})
+
+	t.Run("entry override wins over the run timeout", func(t *testing.T) {
+		if got := repoTimeout(entries[0], 7); got != 7 {
+			t.Fatalf("expected the run timeout, got %d", got)
+		}
+		if got := repoTimeout(entries[1], 7); got != 600 {
+			t.Fatalf("expected the entry timeout, got %d", got)
+		}
+	})
+}
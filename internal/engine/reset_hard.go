@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// resetHardAction renders the --reset-hard steps that follow the fetch.
+func resetHardAction(upstream string) string {
+	return "git reset --hard " + upstream + " && " + cleanAllAction
+}
+
+// resetHardSkipReason reports why entry must not be reset to its upstream, or
+// "" when the reset may run. Unlike pullRebaseSkipReason, a protected branch
+// is always refused: there is no opt-in for discarding work on one.
+func (e *Engine) resetHardSkipReason(ctx context.Context, entry registry.Entry, status *model.RepoStatus, protected []string) (string, error) {
+	if entry.Type == "mirror" {
+		return SyncReasonMirrorRepository, nil
+	}
+	if status == nil {
+		return SyncReasonUnknownStatus, nil
+	}
+	if status.Bare {
+		return SyncReasonBareRepository, nil
+	}
+	if status.Head.Detached {
+		return SyncReasonDetachedHead, nil
+	}
+	if matchesProtectedBranch(status.Head.Branch, protected) {
+		return fmt.Sprintf("branch %q is protected", status.Head.Branch), nil
+	}
+	if status.Tracking.Status == model.TrackingGone {
+		return SyncReasonUpstreamNoLongerExists, nil
+	}
+	if strings.TrimSpace(status.Tracking.Upstream) == "" || status.Tracking.Status == model.TrackingNone {
+		return SyncReasonBranchNotTrackingUpstream, nil
+	}
+	supported, reason, err := supportsLocalUpdate(ctx, e.adapter, entry.Path)
+	if err != nil {
+		return "", err
+	}
+	if !supported {
+		return reason, nil
+	}
+	if _, ok := e.adapter.(vcs.UpstreamResetter); !ok {
+		return fmt.Sprintf("reset to upstream unsupported for vcs %s", e.adapter.Name()), nil
+	}
+	return "", nil
+}
+
+// skipResetForMirror marks a successful mirror result as having skipped the
+// reset when ResetHard is set. The mirror is still refreshed.
+func skipResetForMirror(res SyncResult, opts SyncOptions) SyncResult {
+	if !opts.ResetHard {
+		return res
+	}
+	res.Outcome = SyncOutcomeSkippedLocalUpdate
+	res.ErrorClass = "skipped"
+	res.Error = SyncErrorSkippedLocalUpdatePrefix + SyncReasonMirrorRepository
+	res.SkipReason = SyncReasonMirrorRepository
+	return res
+}
+
+// runResetHardAfterFetch is runSyncApplyAfterFetch for ResetHard.
+func (e *Engine) runResetHardAfterFetch(ctx context.Context, entry registry.Entry, opts SyncOptions) SyncResult {
+	status, err := e.InspectRepo(ctx, entry.Path)
+	if err != nil {
+		return inspectFailureResult(entry, err, e.classifier)
+	}
+	reason, err := e.resetHardSkipReason(ctx, entry, status, opts.ProtectedBranches)
+	if err != nil {
+		return inspectFailureResult(entry, err, e.classifier)
+	}
+	if reason != "" {
+		return SyncResult{
+			RepoID:     entry.RepoID,
+			Path:       entry.Path,
+			Outcome:    SyncOutcomeSkippedLocalUpdate,
+			OK:         true,
+			ErrorClass: "skipped",
+			Error:      SyncErrorSkippedLocalUpdatePrefix + reason,
+			SkipReason: reason,
+		}
+	}
+	target := status.Tracking.Upstream
+	res := SyncResult{
+		RepoID:      entry.RepoID,
+		Path:        entry.Path,
+		Action:      resetHardAction(target),
+		ResetTarget: target,
+	}
+	err = e.resetHardTo(ctx, entry.Path, target)
+	if err == nil {
+		err = e.cleanAll(ctx, entry.Path)
+	}
+	if err != nil {
+		res.Outcome = SyncOutcomeFailedReset
+		res.Error = err.Error()
+		res.ErrorClass = e.classifier.ClassifyError(err)
+		return res
+	}
+	res.Outcome = SyncOutcomeResetHard
+	res.OK = true
+	return res
+}
+
+// resetHardTo resets dir to ref through the adapter, failing when the backend
+// cannot reset to an upstream.
+func (e *Engine) resetHardTo(ctx context.Context, dir, ref string) error {
+	if strings.TrimSpace(ref) == "" {
+		return errors.New("reset step has no upstream")
+	}
+	resetter, ok := e.adapter.(vcs.UpstreamResetter)
+	if !ok {
+		return fmt.Errorf("%s does not support resetting to upstream", e.adapter.Name())
+	}
+	return resetter.ResetHardTo(ctx, dir, ref)
+}
+
+// cleanAll removes untracked and ignored files through the adapter.
+func (e *Engine) cleanAll(ctx context.Context, dir string) error {
+	resetter, ok := e.adapter.(vcs.UpstreamResetter)
+	if !ok {
+		return fmt.Errorf("%s does not support resetting to upstream", e.adapter.Name())
+	}
+	return resetter.CleanAll(ctx, dir)
+}
@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// resetHardFixture clones a fresh upstream and leaves the clone with a local
+// commit, an uncommitted edit, an untracked file, and an ignored file.
+func resetHardFixture(t *testing.T, branch string) (clone string) {
+	t.Helper()
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream.git")
+	seed := filepath.Join(root, "seed")
+	clone = filepath.Join(root, "clone")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "--bare", "-b", branch, upstream)
+	git("init", "-b", branch, seed)
+	write(filepath.Join(seed, "README"), "upstream\n")
+	write(filepath.Join(seed, ".gitignore"), "*.log\n")
+	git("-C", seed, "add", ".")
+	git("-C", seed, "commit", "-m", "init")
+	git("-C", seed, "remote", "add", "origin", upstream)
+	git("-C", seed, "push", "origin", branch)
+	git("clone", upstream, clone)
+
+	write(filepath.Join(clone, "README"), "local commit\n")
+	git("-C", clone, "commit", "-am", "local")
+	write(filepath.Join(clone, "README"), "uncommitted\n")
+	write(filepath.Join(clone, "scratch.txt"), "untracked\n")
+	write(filepath.Join(clone, "build.log"), "ignored\n")
+	return clone
+}
+
+func newResetHardEngine(entries ...registry.Entry) *Engine {
+	return New(&config.Config{}, &registry.Registry{Entries: entries}, vcs.NewGitAdapter(nil), vcs.NewGitErrorClassifier(), vcs.NewGitURLNormalizer(), nil)
+}
+
+func TestSyncResetHardPlansAndRestoresUpstream(t *testing.T) {
+	clone := resetHardFixture(t, "work")
+	entry := registry.Entry{RepoID: "example/repo", Path: clone, RemoteURL: "https://example.com/repo.git", Status: registry.StatusPresent}
+	eng := newResetHardEngine(entry)
+	opts := SyncOptions{ResetHard: true, DryRun: true, ContinueOnError: true}
+
+	plan, err := eng.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(plan) != 1 {
+		t.Fatalf("expected one planned result, got %+v", plan)
+	}
+	planned := plan[0]
+	if planned.ResetTarget != "origin/work" || planned.Outcome != SyncOutcomePlannedFetch {
+		t.Fatalf("unexpected planned reset: %+v", planned)
+	}
+	if !strings.Contains(planned.Action, "git reset --hard origin/work && git clean -fdx") {
+		t.Fatalf("unexpected planned action %q", planned.Action)
+	}
+	wantSteps := []syncStep{syncStepFetch, syncStepResetHard, syncStepCleanAll}
+	if len(planned.steps) != len(wantSteps) {
+		t.Fatalf("expected steps %v, got %v", wantSteps, planned.steps)
+	}
+	for i := range wantSteps {
+		if planned.steps[i] != wantSteps[i] {
+			t.Fatalf("expected steps %v, got %v", wantSteps, planned.steps)
+		}
+	}
+
+	// A saved plan keeps the reset target and steps.
+	restored, err := NewSavedSyncPlan(plan, time.Now()).SyncResults()
+	if err != nil {
+		t.Fatalf("restore plan: %v", err)
+	}
+	if restored[0].ResetTarget != "origin/work" || len(restored[0].steps) != len(wantSteps) {
+		t.Fatalf("saved plan lost the reset: %+v", restored[0])
+	}
+
+	opts.DryRun = false
+	executed, err := eng.ExecuteSyncPlanWithCallbacks(context.Background(), plan, opts, nil, nil)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if len(executed) != 1 || !executed[0].OK || executed[0].Outcome != SyncOutcomeResetHard {
+		t.Fatalf("unexpected executed result: %+v", executed)
+	}
+	data, err := os.ReadFile(filepath.Join(clone, "README"))
+	if err != nil || string(data) != "upstream\n" {
+		t.Fatalf("expected README to match upstream, got %q (%v)", data, err)
+	}
+	for _, name := range []string{"scratch.txt", "build.log"} {
+		if _, err := os.Stat(filepath.Join(clone, name)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, stat err=%v", name, err)
+		}
+	}
+}
+
+func TestSyncResetHardSkipsAndGuards(t *testing.T) {
+	t.Run("protected branch is refused even with allow-protected-rebase", func(t *testing.T) {
+		clone := resetHardFixture(t, "main")
+		eng := newResetHardEngine(registry.Entry{RepoID: "example/repo", Path: clone, RemoteURL: "https://example.com/repo.git", Status: registry.StatusPresent})
+		results, err := eng.Sync(context.Background(), SyncOptions{
+			ResetHard:            true,
+			ContinueOnError:      true,
+			ProtectedBranches:    []string{"main"},
+			AllowProtectedRebase: true,
+		})
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		if len(results) != 1 || results[0].Outcome != SyncOutcomeSkippedLocalUpdate || !strings.Contains(results[0].SkipReason, "protected") {
+			t.Fatalf("expected protected skip, got %+v", results)
+		}
+		if data, _ := os.ReadFile(filepath.Join(clone, "README")); string(data) != "uncommitted\n" {
+			t.Fatalf("protected clone was modified: %q", data)
+		}
+	})
+
+	t.Run("mirror is refreshed but not reset", func(t *testing.T) {
+		entry := registry.Entry{RepoID: "example/mirror", Path: "/repo", RemoteURL: "https://example.com/mirror.git", Type: "mirror", Status: registry.StatusPresent}
+		eng := New(&config.Config{}, &registry.Registry{Entries: []registry.Entry{entry}}, &mirrorAdapter{planAdapter: &planAdapter{}}, nil, nil, nil)
+		results, err := eng.Sync(context.Background(), SyncOptions{ResetHard: true, ContinueOnError: true})
+		if err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		if len(results) != 1 || results[0].SkipReason != SyncReasonMirrorRepository {
+			t.Fatalf("expected mirror skip, got %+v", results)
+		}
+	})
+
+	t.Run("cannot combine with update-local or autostash-all", func(t *testing.T) {
+		eng := newResetHardEngine()
+		for _, opts := range []SyncOptions{{ResetHard: true, UpdateLocal: true}, {ResetHard: true, AutostashAll: true}} {
+			if _, err := eng.Sync(context.Background(), opts); err == nil {
+				t.Fatalf("expected error for %+v", opts)
+			}
+		}
+	})
+
+	t.Run("failed reset is reported", func(t *testing.T) {
+		clone := resetHardFixture(t, "work")
+		eng := newResetHardEngine()
+		plan := []SyncResult{{
+			RepoID:      "example/repo",
+			Path:        clone,
+			Outcome:     SyncOutcomePlannedFetch,
+			OK:          true,
+			Planned:     true,
+			ResetTarget: "origin/missing",
+			steps:       []syncStep{syncStepResetHard, syncStepCleanAll},
+		}}
+		results, err := eng.ExecuteSyncPlanWithCallbacks(context.Background(), plan, SyncOptions{ResetHard: true, ContinueOnError: true}, nil, nil)
+		if err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+		if len(results) != 1 || results[0].OK || results[0].Outcome != SyncOutcomeFailedReset {
+			t.Fatalf("expected failed reset, got %+v", results)
+		}
+		if _, err := os.Stat(filepath.Join(clone, "scratch.txt")); err != nil {
+			t.Fatalf("clean must not run after a failed reset: %v", err)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// defaultRetryBackoff is the first wait between attempts when RetryAttempts is
+// set and RetryBackoff is left unset. The CLI rejects a zero --retry-backoff,
+// so only callers that never set it get here.
+const defaultRetryBackoff = time.Second
+
+// syncRetryPolicy controls how transient fetch/clone failures are retried.
+type syncRetryPolicy struct {
+	retries int
+	backoff time.Duration
+}
+
+func syncRetryPolicyFor(opts SyncOptions) syncRetryPolicy {
+	policy := syncRetryPolicy{retries: max(opts.RetryAttempts, 0), backoff: opts.RetryBackoff}
+	if policy.backoff <= 0 {
+		policy.backoff = defaultRetryBackoff
+	}
+	return policy
+}
+
+// isRetryableErrorClass reports whether a failure is worth another attempt.
+// auth, host_key, corrupt, missing_remote, disk_full, and fs_permission
+// failures will not fix themselves, so they fail on the first attempt.
+func isRetryableErrorClass(class string) bool {
+	return class == "network" || class == "timeout"
+}
+
+// withRetry runs op until it succeeds, fails with a non-transient error class,
+// or runs out of attempts. Backoff doubles after every failed attempt, and a
+// retry is skipped when the wait would outlive the per-repo context deadline.
+// It returns the number of attempts made alongside the last error.
+func (e *Engine) withRetry(ctx context.Context, policy syncRetryPolicy, op func() error) (int, error) {
+	wait := policy.backoff
+	attempts := 0
+	for {
+		attempts++
+		err := op()
+		if err == nil || attempts > policy.retries || !isRetryableErrorClass(e.classifier.ClassifyError(err)) {
+			return attempts, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return attempts, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, err
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
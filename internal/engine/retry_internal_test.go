@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// flakyAdapter fails fetch and clone with a fixed error until failures runs out.
+type flakyAdapter struct {
+	*planAdapter
+	failures int
+	err      error
+	fetches  int
+	clones   int
+}
+
+func (a *flakyAdapter) Fetch(context.Context, string) error {
+	a.fetches++
+	if a.fetches <= a.failures {
+		return a.err
+	}
+	return nil
+}
+
+func (a *flakyAdapter) Clone(context.Context, string, string, string, bool) error {
+	a.clones++
+	if a.clones <= a.failures {
+		return a.err
+	}
+	return nil
+}
+
+func TestRunSyncApplyRetriesTransientFetchFailures(t *testing.T) {
+	adapter := &flakyAdapter{planAdapter: &planAdapter{}, failures: 2, err: errors.New("fatal: unable to access: Could not resolve host: github.com")}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+
+	res := eng.runSyncApply(context.Background(), entry, SyncOptions{RetryAttempts: 3, RetryBackoff: time.Millisecond}, nil)
+
+	if !res.OK || res.Outcome != SyncOutcomeFetched {
+		t.Fatalf("expected fetch to succeed after retries, got %+v", res)
+	}
+	if res.Attempts != 3 || adapter.fetches != 3 {
+		t.Fatalf("expected 3 attempts, got result=%d adapter=%d", res.Attempts, adapter.fetches)
+	}
+}
+
+func TestRunSyncApplyGivesUpAfterRetryBudget(t *testing.T) {
+	adapter := &flakyAdapter{planAdapter: &planAdapter{}, failures: 10, err: errors.New("connection timed out")}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+
+	res := eng.runSyncApply(context.Background(), entry, SyncOptions{RetryAttempts: 2, RetryBackoff: time.Millisecond}, nil)
+
+	if res.OK || res.Outcome != SyncOutcomeFailedFetch || res.Error != SyncErrorFetchNetwork {
+		t.Fatalf("expected network fetch failure, got %+v", res)
+	}
+	if res.Attempts != 3 {
+		t.Fatalf("expected initial attempt plus 2 retries, got %d", res.Attempts)
+	}
+}
+
+func TestRetryDoesNotRetryPermanentErrorClasses(t *testing.T) {
+	for _, msg := range []string{"Permission denied (publickey)", "fatal: bad object HEAD", "ERROR: Repository not found."} {
+		adapter := &flakyAdapter{planAdapter: &planAdapter{}, failures: 10, err: errors.New(msg)}
+		eng := newPlanExecEngine(adapter)
+		entry := registry.Entry{RepoID: "repo", Path: "/repo", Status: registry.StatusPresent}
+
+		res := eng.runSyncApply(context.Background(), entry, SyncOptions{RetryAttempts: 5, RetryBackoff: time.Millisecond}, nil)
+
+		if res.OK || res.Attempts != 1 || adapter.fetches != 1 {
+			t.Fatalf("%q: expected a single attempt, got attempts=%d fetches=%d", msg, res.Attempts, adapter.fetches)
+		}
+	}
+}
+
+func TestExecutePlannedCloneRetriesNetworkFailures(t *testing.T) {
+	adapter := &flakyAdapter{planAdapter: &planAdapter{}, failures: 1, err: errors.New("Failed to connect to github.com")}
+	eng := newPlanExecEngine(adapter)
+	entry := registry.Entry{RepoID: "repo", Path: "/missing", RemoteURL: "https://github.com/org/repo.git", Branch: "main", Status: registry.StatusMissing}
+	eng.registry.Entries = []registry.Entry{entry}
+
+	plan := eng.handleMissingSyncEntry(context.Background(), entry, SyncOptions{CheckoutMissing: true, DryRun: true})
+	results, err := eng.ExecuteSyncPlanWithCallbacks(context.Background(), []SyncResult{plan}, SyncOptions{ContinueOnError: true, RetryAttempts: 1, RetryBackoff: time.Millisecond}, nil, nil)
+	if err != nil {
+		t.Fatalf("execute plan: %v", err)
+	}
+	if len(results) != 1 || !results[0].OK || results[0].Attempts != 2 {
+		t.Fatalf("expected clone to succeed on the second attempt, got %+v", results)
+	}
+}
+
+func TestRetrySkipsWaitBeyondContextDeadline(t *testing.T) {
+	adapter := &flakyAdapter{planAdapter: &planAdapter{}, failures: 10, err: errors.New("could not resolve host")}
+	eng := newPlanExecEngine(adapter)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	attempts, err := eng.withRetry(ctx, syncRetryPolicy{retries: 5, backoff: time.Minute}, func() error {
+		return adapter.Fetch(ctx, "/repo")
+	})
+	if err == nil || attempts != 1 {
+		t.Fatalf("expected a single failed attempt, got attempts=%d err=%v", attempts, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("expected retry to give up instead of sleeping past the deadline")
+	}
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// recordingRunner runs real git and records every command it was asked for.
+type recordingRunner struct {
+	mu    sync.Mutex
+	calls []string
+	git   gitx.GitRunner
+}
+
+func (r *recordingRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, strings.Join(args, " "))
+	r.mu.Unlock()
+	return r.git.Run(ctx, dir, args...)
+}
+
+func (r *recordingRunner) remoteCalls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var remote []string
+	for _, call := range r.calls {
+		if strings.HasPrefix(call, "remote") || strings.Contains(call, "refs/remotes/") {
+			remote = append(remote, call)
+		}
+	}
+	return remote
+}
+
+func TestScanRegisterOnlySkipsRemoteReads(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "proj")
+	known := filepath.Join(root, "known")
+	for _, args := range [][]string{
+		{"init", repo},
+		{"-C", repo, "remote", "add", "origin", "https://github.com/acme/proj.git"},
+		{"init", known},
+		{"-C", known, "remote", "add", "origin", "https://github.com/acme/known.git"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v %s", args, err, string(out))
+		}
+	}
+
+	reg := &registry.Registry{Entries: []registry.Entry{{
+		RepoID:    "github.com/acme/known",
+		Path:      known,
+		RemoteURL: "https://github.com/acme/known.git",
+		Status:    registry.StatusPresent,
+	}}}
+	runner := &recordingRunner{}
+	eng := New(&config.Config{Exclude: []string{}}, reg, vcs.NewGitAdapter(runner), nil, nil, nil)
+	statuses, err := eng.Scan(context.Background(), ScanOptions{Roots: []string{root}, RegisterOnly: true})
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if calls := runner.remoteCalls(); len(calls) > 0 {
+		t.Fatalf("expected no remote reads with RegisterOnly, got %v", calls)
+	}
+	if len(statuses) != 2 || len(reg.Entries) != 2 {
+		t.Fatalf("expected both repos registered, got statuses %+v entries %+v", statuses, reg.Entries)
+	}
+	placeholder := "local:" + filepath.ToSlash(repo)
+	if entry := reg.FindEntry(placeholder, repo); entry == nil || entry.RemoteURL != "" {
+		t.Fatalf("expected %s registered as %s without a remote URL, got %+v", repo, placeholder, reg.Entries)
+	}
+	if entry := reg.FindEntry("github.com/acme/known", known); entry == nil || entry.RemoteURL != "https://github.com/acme/known.git" {
+		t.Fatalf("expected the registered repo to keep its ID and remote URL, got %+v", reg.Entries)
+	}
+
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll})
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if len(report.Repos) != 2 {
+		t.Fatalf("expected two repos in status, got %+v", report.Repos)
+	}
+	entry := reg.FindEntry("github.com/acme/proj", repo)
+	if entry == nil || entry.Path != repo || entry.RemoteURL != "https://github.com/acme/proj.git" {
+		t.Fatalf("expected status to resolve the deferred remote, got %+v", reg.Entries)
+	}
+	if len(reg.Entries) != 2 {
+		t.Fatalf("expected resolution to keep two entries, got %+v", reg.Entries)
+	}
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"maps"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+	"github.com/skaphos/repokeeper/internal/repometa"
+	"github.com/skaphos/repokeeper/internal/vcs"
+)
+
+// StatusCacheVersion is the current on-disk StatusCache format.
+const StatusCacheVersion = 1
+
+// StatusCacheEntry is one repo's status from an earlier run together with the
+// inspect fingerprint taken just before that inspection.
+type StatusCacheEntry struct {
+	Fingerprint string           `json:"fingerprint"`
+	Status      model.RepoStatus `json:"status"`
+}
+
+// StatusCache carries repo statuses between incremental status runs, keyed by
+// checkout path. Status reads Repos while inspecting and replaces it with the
+// refreshed statuses once the run completes.
+type StatusCache struct {
+	Version int                         `json:"version"`
+	Repos   map[string]StatusCacheEntry `json:"repos"`
+	// Reuse serves a repo from Repos when its fingerprint still matches both
+	// the cache and the registry entry's last_inspect. Without it every repo is
+	// inspected and the cache is only rebuilt.
+	Reuse bool `json:"-"`
+	// Reused counts the repos served from the cache in the last run.
+	Reused int `json:"-"`
+}
+
+// statusResult is one status worker's output. fingerprint is empty when the
+// run keeps no cache or the repo cannot be fingerprinted.
+type statusResult struct {
+	entry       registry.Entry
+	status      model.RepoStatus
+	fingerprint string
+	reused      bool
+}
+
+// cachedStatusWorker runs statusWorker, first serving the repo from
+// opts.Cache when its fingerprint is unchanged. The fingerprint is taken
+// before inspecting, so a change made mid-inspection is caught next run.
+func (e *Engine) cachedStatusWorker(ctx context.Context, entry registry.Entry, timeoutSeconds int, opts StatusOptions, urls vcs.URLNormalizer) statusResult {
+	if opts.Cache == nil || entry.Status == registry.StatusMissing {
+		return statusResult{entry: entry, status: e.statusWorker(ctx, entry, timeoutSeconds, opts, urls)}
+	}
+	fingerprint := e.inspectFingerprint(ctx, entry.Path)
+	if status, ok := opts.Cache.lookup(entry, fingerprint, opts); ok {
+		return statusResult{entry: entry, status: status, fingerprint: fingerprint, reused: true}
+	}
+	return statusResult{entry: entry, status: e.statusWorker(ctx, entry, timeoutSeconds, opts, urls), fingerprint: fingerprint}
+}
+
+func (e *Engine) inspectFingerprint(ctx context.Context, dir string) string {
+	fingerprinter, ok := e.adapter.(vcs.InspectFingerprinter)
+	if !ok {
+		return ""
+	}
+	fingerprint, err := fingerprinter.InspectFingerprint(ctx, dir)
+	if err != nil {
+		e.logger.Debugf("inspect fingerprint failed for %s: %v", dir, err)
+		return ""
+	}
+	return fingerprint
+}
+
+// lookup returns the cached status for entry when reuse is on and the
+// fingerprint matches. Failed inspections are never cached, and options that
+// add to the inspection (ignored files, branches behind base, live remote
+// HEAD probes) always inspect.
+func (c *StatusCache) lookup(entry registry.Entry, fingerprint string, opts StatusOptions) (model.RepoStatus, bool) {
+	if !c.Reuse || fingerprint == "" || entry.LastInspect != fingerprint {
+		return model.RepoStatus{}, false
+	}
+	if opts.VerifyIgnored || opts.Filter == FilterBranchesBehindDefault || opts.probeRemoteHead() {
+		return model.RepoStatus{}, false
+	}
+	cached, ok := c.Repos[entry.Path]
+	if !ok || cached.Fingerprint != fingerprint || cached.Status.Error != "" {
+		return model.RepoStatus{}, false
+	}
+	status := cached.Status
+	status.CheckoutID = entry.CheckoutID
+	if entry.Type != "" {
+		status.Type = entry.Type
+	}
+	// Repo-local metadata lives outside the git dir; Apply re-reads it only
+	// when its own fingerprint changed.
+	repometa.Apply(&status)
+	return status, true
+}
+
+// updateStatusCache records this run's fingerprints on the registry entries
+// and refreshes cache.Repos with this run's statuses. Repos outside the run
+// (filtered out, or not reached after StopWhen) keep their earlier entries;
+// repos that went missing or failed inspection are dropped.
+func (e *Engine) updateStatusCache(cache *StatusCache, entries []registry.Entry, results []statusResult) {
+	if cache == nil {
+		return
+	}
+	next := make(map[string]StatusCacheEntry, len(cache.Repos)+len(results))
+	maps.Copy(next, cache.Repos)
+	for _, entry := range entries {
+		if entry.Status == registry.StatusMissing {
+			delete(next, entry.Path)
+		}
+	}
+	reused := 0
+	e.registryMu.Lock()
+	defer e.registryMu.Unlock()
+	for _, res := range results {
+		path := res.entry.Path
+		if res.reused {
+			reused++
+		}
+		if res.fingerprint == "" || res.status.Error != "" {
+			delete(next, path)
+			continue
+		}
+		next[path] = StatusCacheEntry{Fingerprint: res.fingerprint, Status: res.status}
+		if idx := e.registry.FindEntryIndex(res.entry.RepoID, path); idx >= 0 {
+			e.registry.Entries[idx].LastInspect = res.fingerprint
+		}
+	}
+	cache.Version = StatusCacheVersion
+	cache.Repos = next
+	cache.Reused = reused
+}
+
+// Prune drops cached repos whose path is no longer in reg.
+func (c *StatusCache) Prune(reg *registry.Registry) {
+	if c == nil || reg == nil {
+		return
+	}
+	paths := make(map[string]bool, len(reg.Entries))
+	for _, entry := range reg.Entries {
+		paths[entry.Path] = true
+	}
+	maps.DeleteFunc(c.Repos, func(path string, _ StatusCacheEntry) bool {
+		return !paths[path]
+	})
+}
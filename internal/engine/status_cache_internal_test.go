@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/config"
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// fingerprintAdapter serves fixed inspect fingerprints and counts how often
+// each repo is actually inspected.
+type fingerprintAdapter struct {
+	*planAdapter
+	mu           sync.Mutex
+	fingerprints map[string]string
+	inspected    map[string]int
+}
+
+func (a *fingerprintAdapter) InspectFingerprint(_ context.Context, dir string) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fp, ok := a.fingerprints[dir]
+	if !ok {
+		return "", errors.New("no git dir")
+	}
+	return fp, nil
+}
+
+func (a *fingerprintAdapter) WorktreeStatus(ctx context.Context, dir string) (*model.Worktree, error) {
+	a.mu.Lock()
+	a.inspected[dir]++
+	a.mu.Unlock()
+	return a.planAdapter.WorktreeStatus(ctx, dir)
+}
+
+func (a *fingerprintAdapter) resetInspected() {
+	a.mu.Lock()
+	a.inspected = map[string]int{}
+	a.mu.Unlock()
+}
+
+func TestStatusCacheSkipsUnchangedRepos(t *testing.T) {
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/a", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/b", Path: "/b", Status: registry.StatusPresent},
+		{RepoID: "github.com/org/c", Path: "/c", Status: registry.StatusPresent},
+	}}
+	adapter := &fingerprintAdapter{
+		planAdapter:  &planAdapter{},
+		fingerprints: map[string]string{"/a": "a1", "/b": "b1"},
+		inspected:    map[string]int{},
+	}
+	eng := New(&config.Config{}, reg, adapter, nil, nil, nil)
+	cache := &StatusCache{Reuse: true}
+
+	// The first run has nothing to reuse; it records fingerprints for every
+	// repo that has one.
+	if _, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll, Cache: cache}); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if len(adapter.inspected) != 3 || cache.Reused != 0 {
+		t.Fatalf("expected all repos inspected on the first run, got %v (reused %d)", adapter.inspected, cache.Reused)
+	}
+	if reg.Entries[0].LastInspect != "a1" || reg.Entries[1].LastInspect != "b1" || reg.Entries[2].LastInspect != "" {
+		t.Fatalf("expected last_inspect recorded per entry, got %+v", reg.Entries)
+	}
+	if _, ok := cache.Repos["/c"]; ok || len(cache.Repos) != 2 {
+		t.Fatalf("expected only fingerprinted repos cached, got %v", cache.Repos)
+	}
+
+	// Only /b changed since, and /c cannot be fingerprinted.
+	adapter.resetInspected()
+	adapter.fingerprints["/b"] = "b2"
+	report, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll, Cache: cache})
+	if err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if adapter.inspected["/a"] != 0 || adapter.inspected["/b"] != 1 || adapter.inspected["/c"] != 1 {
+		t.Fatalf("expected only changed or unfingerprinted repos inspected, got %v", adapter.inspected)
+	}
+	if cache.Reused != 1 || len(report.Repos) != 3 {
+		t.Fatalf("expected one reused repo in a full report, got reused %d and %d rows", cache.Reused, len(report.Repos))
+	}
+	if reg.Entries[1].LastInspect != "b2" || cache.Repos["/b"].Fingerprint != "b2" {
+		t.Fatalf("expected the changed fingerprint recorded, got %q / %q", reg.Entries[1].LastInspect, cache.Repos["/b"].Fingerprint)
+	}
+
+	// Without Reuse (--full) every repo is inspected again.
+	adapter.resetInspected()
+	cache.Reuse = false
+	if _, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll, Cache: cache}); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if len(adapter.inspected) != 3 || cache.Reused != 0 {
+		t.Fatalf("expected a full run to inspect every repo, got %v (reused %d)", adapter.inspected, cache.Reused)
+	}
+}
+
+func TestStatusCacheRequiresMatchingRegistryFingerprint(t *testing.T) {
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/org/a", Path: "/a", Status: registry.StatusPresent, LastInspect: "stale"},
+	}}
+	adapter := &fingerprintAdapter{
+		planAdapter:  &planAdapter{},
+		fingerprints: map[string]string{"/a": "a1"},
+		inspected:    map[string]int{},
+	}
+	eng := New(&config.Config{}, reg, adapter, nil, nil, nil)
+	// A cache entry from another registry must not be trusted on its own.
+	cache := &StatusCache{Reuse: true, Repos: map[string]StatusCacheEntry{
+		"/a": {Fingerprint: "a1", Status: model.RepoStatus{RepoID: "github.com/org/a", Path: "/a"}},
+	}}
+	if _, err := eng.Status(context.Background(), StatusOptions{Filter: FilterAll, Cache: cache}); err != nil {
+		t.Fatalf("status failed: %v", err)
+	}
+	if adapter.inspected["/a"] != 1 || cache.Reused != 0 {
+		t.Fatalf("expected a registry fingerprint mismatch to inspect, got %v (reused %d)", adapter.inspected, cache.Reused)
+	}
+	if reg.Entries[0].LastInspect != "a1" {
+		t.Fatalf("expected last_inspect refreshed, got %q", reg.Entries[0].LastInspect)
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+// SummarizeResults counts sync results by outcome. Results without an outcome
+// are ignored so a partially populated slice never produces an empty key.
+func SummarizeResults(results []SyncResult) map[OutcomeKind]int {
+	counts := make(map[OutcomeKind]int)
+	for _, res := range results {
+		if res.Outcome == "" {
+			continue
+		}
+		counts[res.Outcome]++
+	}
+	return counts
+}
+
+// StatusSummary tallies a status report. Total counts every repo in the
+// report. A repo can count in several of dirty, diverged, and gone; clean
+// counts inspected repos whose worktree is not dirty. Missing counts repos
+// whose path is gone or whose registry entry is missing or moved; errors
+// counts the other inspection failures.
+type StatusSummary struct {
+	Total    int `json:"total"`
+	Clean    int `json:"clean"`
+	Dirty    int `json:"dirty"`
+	Diverged int `json:"diverged"`
+	Gone     int `json:"gone"`
+	Missing  int `json:"missing"`
+	Errors   int `json:"errors"`
+}
+
+// SummarizeStatus tallies report.Repos, using reg (which may be nil) to
+// recognize repos whose registry entry is missing or moved.
+func SummarizeStatus(report *model.StatusReport, reg *registry.Registry) StatusSummary {
+	var summary StatusSummary
+	if report == nil {
+		return summary
+	}
+	for _, repo := range report.Repos {
+		summary.Total++
+		if statusRepoMissing(repo, reg) {
+			summary.Missing++
+			continue
+		}
+		if repo.Error != "" {
+			summary.Errors++
+			continue
+		}
+		switch repo.Tracking.Status {
+		case model.TrackingDiverged:
+			summary.Diverged++
+		case model.TrackingGone:
+			summary.Gone++
+		}
+		if repo.Worktree != nil {
+			if repo.Worktree.Dirty {
+				summary.Dirty++
+			} else {
+				summary.Clean++
+			}
+		}
+	}
+	return summary
+}
+
+func statusRepoMissing(repo model.RepoStatus, reg *registry.Registry) bool {
+	if repo.ErrorClass == "missing" {
+		return true
+	}
+	if reg == nil {
+		return false
+	}
+	entry := reg.FindEntry(repo.RepoID, repo.Path)
+	return entry != nil && (entry.Status == registry.StatusMissing || entry.Status == registry.StatusMoved)
+}
+
+// IsPlannedOutcome reports whether an outcome describes work that has not been
+// applied yet. Planned outcomes share the planned_ prefix; SyncResult.Planned
+// is not reliable for this because executed results keep the flag from the plan.
+func IsPlannedOutcome(outcome OutcomeKind) bool {
+	return strings.HasPrefix(string(outcome), "planned_")
+}
+
+// IsSkippedOutcome reports whether an outcome records a repo that sync left
+// alone, in a dry run as well as a real one. Skipped outcomes share the
+// skipped prefix.
+func IsSkippedOutcome(outcome OutcomeKind) bool {
+	return strings.HasPrefix(string(outcome), string(SyncOutcomeSkipped))
+}
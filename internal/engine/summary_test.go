@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: MIT
+package engine
+
+import (
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/model"
+	"github.com/skaphos/repokeeper/internal/registry"
+)
+
+func TestSummarizeResultsCountsByOutcome(t *testing.T) {
+	results := []SyncResult{
+		{Outcome: SyncOutcomeFetched, OK: true},
+		{Outcome: SyncOutcomeFetched, OK: true},
+		{Outcome: SyncOutcomeFailedFetch},
+		{Outcome: SyncOutcomeSkippedNoUpstream},
+		{Outcome: ""},
+	}
+	got := SummarizeResults(results)
+	if got[SyncOutcomeFetched] != 2 {
+		t.Fatalf("expected 2 fetched, got %d", got[SyncOutcomeFetched])
+	}
+	if got[SyncOutcomeFailedFetch] != 1 || got[SyncOutcomeSkippedNoUpstream] != 1 {
+		t.Fatalf("unexpected counts: %#v", got)
+	}
+	if _, ok := got[""]; ok {
+		t.Fatalf("expected empty outcome to be ignored, got %#v", got)
+	}
+	if len(SummarizeResults(nil)) != 0 {
+		t.Fatal("expected empty summary for nil results")
+	}
+}
+
+func TestIsPlannedOutcome(t *testing.T) {
+	for _, outcome := range []OutcomeKind{SyncOutcomePlannedFetch, SyncOutcomePlannedPush, SyncOutcomePlannedCheckout} {
+		if !IsPlannedOutcome(outcome) {
+			t.Fatalf("expected %q to be planned", outcome)
+		}
+	}
+	for _, outcome := range []OutcomeKind{SyncOutcomeFetched, SyncOutcomeSkippedMissing, SyncOutcomeRebased} {
+		if IsPlannedOutcome(outcome) {
+			t.Fatalf("expected %q not to be planned", outcome)
+		}
+	}
+}
+
+func TestIsSkippedOutcome(t *testing.T) {
+	for _, outcome := range []OutcomeKind{SyncOutcomeSkipped, SyncOutcomeSkippedMissing, SyncOutcomeSkippedNoUpstream, SyncOutcomeSkippedLocalUpdate} {
+		if !IsSkippedOutcome(outcome) {
+			t.Fatalf("expected %q to be skipped", outcome)
+		}
+	}
+	for _, outcome := range []OutcomeKind{SyncOutcomeFetched, SyncOutcomePlannedFetch, SyncOutcomeFailedFetch} {
+		if IsSkippedOutcome(outcome) {
+			t.Fatalf("expected %q not to be skipped", outcome)
+		}
+	}
+}
+
+func TestSummarizeStatusTalliesMixedStates(t *testing.T) {
+	report := &model.StatusReport{Repos: []model.RepoStatus{
+		{RepoID: "clean", Path: "/clean", Worktree: &model.Worktree{}, Tracking: model.Tracking{Status: model.TrackingEqual}},
+		{RepoID: "dirty", Path: "/dirty", Worktree: &model.Worktree{Dirty: true}},
+		{RepoID: "dirty-diverged", Path: "/dd", Worktree: &model.Worktree{Dirty: true}, Tracking: model.Tracking{Status: model.TrackingDiverged}},
+		{RepoID: "gone", Path: "/gone", Worktree: &model.Worktree{}, Tracking: model.Tracking{Status: model.TrackingGone}},
+		{RepoID: "missing", Path: "/missing", Error: "path missing", ErrorClass: "missing"},
+		{RepoID: "moved", Path: "/moved", Error: "not a repo", ErrorClass: "invalid"},
+		{RepoID: "broken", Path: "/broken", Error: "boom", ErrorClass: "unknown"},
+		{RepoID: "uninspected", Path: "/uninspected"},
+	}}
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "moved", Path: "/moved", Status: registry.StatusMoved},
+		{RepoID: "clean", Path: "/clean", Status: registry.StatusPresent},
+	}}
+
+	got := SummarizeStatus(report, reg)
+	want := StatusSummary{Total: 8, Clean: 2, Dirty: 2, Diverged: 1, Gone: 1, Missing: 2, Errors: 1}
+	if got != want {
+		t.Fatalf("SummarizeStatus() = %+v, want %+v", got, want)
+	}
+
+	// Without a registry only the missing error class counts as missing.
+	want.Missing, want.Errors = 1, 2
+	if got := SummarizeStatus(report, nil); got != want {
+		t.Fatalf("SummarizeStatus(nil registry) = %+v, want %+v", got, want)
+	}
+	if got := SummarizeStatus(nil, reg); got != (StatusSummary{}) {
+		t.Fatalf("expected an empty summary for a nil report, got %+v", got)
+	}
+}
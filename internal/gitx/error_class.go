@@ -25,6 +25,17 @@ func ClassifyError(err error) string {
 	msg := strings.ToLower(err.Error())
 	// Heuristics are intentionally broad to keep categories actionable for users.
 	switch {
+	// A full disk or an unwritable object store fails the local side of a
+	// fetch or clone. Their messages mention objects and packs, so check them
+	// before corrupt, and a local "Permission denied" before auth.
+	case containsAny(msg, "no space left on device", "out of diskspace", "disk quota exceeded"):
+		return "disk_full"
+	case isLocalWriteDenied(msg):
+		return "fs_permission"
+	// ssh follows a host-key failure with "could not read from remote
+	// repository" and an access-rights hint, so check it before auth.
+	case containsAny(msg, "host key verification failed", "remote host identification has changed", "no matching host key type found"):
+		return "host_key"
 	case containsAny(msg, "permission denied", "authentication failed", "access denied", "publickey", "could not read username", "credential"):
 		return "auth"
 	case containsAny(msg, "could not resolve host", "network is unreachable", "connection timed out", "failed to connect", "temporary failure in name resolution", "tls handshake timeout"):
@@ -40,6 +51,20 @@ func ClassifyError(err error) string {
 	}
 }
 
+// isLocalWriteDenied reports a failure to write into the repository on disk,
+// as opposed to a remote rejecting credentials. ssh's "Permission denied
+// (publickey)" never names a local path, while git's own write errors name the
+// file or directory it could not create.
+func isLocalWriteDenied(msg string) bool {
+	if containsAny(msg, "insufficient permission for adding an object", "read-only file system") {
+		return true
+	}
+	if !strings.Contains(msg, "permission denied") || strings.Contains(msg, "publickey") {
+		return false
+	}
+	return containsAny(msg, "unable to create", "unable to write", "cannot open", "could not open", "unable to unlink", "objects/", "tmp_pack", "fetch_head", ".lock")
+}
+
 func containsAny(msg string, needles ...string) bool {
 	for _, needle := range needles {
 		if strings.Contains(msg, needle) {
@@ -20,7 +20,21 @@ func TestClassifyError(t *testing.T) {
 		{name: "timeout", err: context.DeadlineExceeded, want: "timeout"},
 		{name: "canceled", err: context.Canceled, want: "timeout"},
 		{name: "wrapped timeout", err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: "timeout"},
+		{name: "host key", err: errors.New("Host key verification failed.\r\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists."), want: "host_key"},
+		{name: "host key changed", err: errors.New("@@@@@@@@@@@\n@    WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED!     @\n@@@@@@@@@@@\nHost key for github.com has changed and you have requested strict checking.\nHost key verification failed."), want: "host_key"},
+		{name: "host key type", err: errors.New("Unable to negotiate with 10.0.0.5 port 22: no matching host key type found. Their offer: ssh-rsa"), want: "host_key"},
 		{name: "auth", err: errors.New("permission denied (publickey)"), want: "auth"},
+		{name: "auth ssh", err: errors.New("git fetch --all: git@github.com: Permission denied (publickey).\r\nfatal: Could not read from remote repository.\n\nPlease make sure you have the correct access rights\nand the repository exists.: exit status 128"), want: "auth"},
+		{name: "auth https", err: errors.New("remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/org/repo.git/'"), want: "auth"},
+		{name: "network refused", err: errors.New("ssh: connect to host github.com port 22: Connection refused\nfatal: Could not read from remote repository.\nfailed to connect"), want: "network"},
+		{name: "disk full pack", err: errors.New("git fetch --all: error: unable to write file .git/objects/pack/tmp_pack_Xq0L2a: No space left on device\nfatal: sha1 file '.git/objects/pack/tmp_pack_Xq0L2a' write error. Out of diskspace\nfatal: fetch-pack: invalid index-pack output: exit status 128"), want: "disk_full"},
+		{name: "disk full index-pack", err: errors.New("fatal: write error: No space left on device\nfatal: index-pack failed"), want: "disk_full"},
+		{name: "disk quota", err: errors.New("error: unable to create temporary file: Disk quota exceeded\nfatal: failed to write object"), want: "disk_full"},
+		{name: "fs permission object store", err: errors.New("error: insufficient permission for adding an object to repository database .git/objects\nfatal: failed to write object\nfatal: unpack-objects failed"), want: "fs_permission"},
+		{name: "fs permission tmp pack", err: errors.New("fatal: unable to create temporary file '/srv/repos/app/.git/objects/pack/tmp_pack_XXXXXX': Permission denied\nfatal: fetch-pack: invalid index-pack output"), want: "fs_permission"},
+		{name: "fs permission fetch head", err: errors.New("error: cannot open .git/FETCH_HEAD: Permission denied"), want: "fs_permission"},
+		{name: "fs permission ref lock", err: errors.New("error: cannot lock ref 'refs/remotes/origin/main': Unable to create '/srv/repos/app/.git/refs/remotes/origin/main.lock': Permission denied"), want: "fs_permission"},
+		{name: "fs read-only", err: errors.New("error: unable to create temporary file: Read-only file system"), want: "fs_permission"},
 		{name: "network", err: errors.New("Could not resolve host: github.com"), want: "network"},
 		{name: "timeout text", err: errors.New("network timeout"), want: "timeout"},
 		{name: "corrupt", err: errors.New("fatal: not a git repository"), want: "corrupt"},
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+package gitx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/model"
+)
+
+// InspectFingerprint summarizes the state a status inspection depends on with
+// a single git call: the HEAD contents; the size and mtime of HEAD, the
+// checked-out branch ref, packed-refs, FETCH_HEAD, the repo config, the stash
+// ref, the recorded remote HEADs (refs/remotes/*/HEAD, which the stale
+// upstream check reads), any in-progress merge, rebase, or bisect state, and
+// the worktree root directory; and `git status --porcelain=v2 --branch`,
+// which covers staged and unstaged edits, untracked files at any depth, the
+// configured upstream, and the ahead/behind counts against it. The status
+// runs with --no-optional-locks so computing the fingerprint never rewrites
+// the index.
+func InspectFingerprint(ctx context.Context, r Runner, dir string) (string, error) {
+	gitDir, commonDir, err := GitDirs(dir)
+	if err != nil {
+		return "", err
+	}
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "head=%s\n", strings.TrimSpace(string(head)))
+	paths := []string{
+		dir,
+		filepath.Join(gitDir, "HEAD"),
+		filepath.Join(gitDir, "FETCH_HEAD"),
+		filepath.Join(gitDir, "MERGE_HEAD"),
+		filepath.Join(gitDir, "rebase-merge"),
+		filepath.Join(gitDir, "rebase-apply"),
+		filepath.Join(gitDir, "BISECT_LOG"),
+		filepath.Join(commonDir, "FETCH_HEAD"),
+		filepath.Join(commonDir, "packed-refs"),
+		filepath.Join(commonDir, "config"),
+		filepath.Join(commonDir, "refs", "stash"),
+	}
+	if ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: "); ok {
+		paths = append(paths, filepath.Join(commonDir, filepath.FromSlash(ref)))
+	}
+	// Glob only fails on a malformed pattern, which this one is not.
+	remoteHeads, _ := filepath.Glob(filepath.Join(commonDir, "refs", "remotes", "*", "HEAD"))
+	paths = append(paths, remoteHeads...)
+	for _, path := range paths {
+		if err := writeStatFingerprint(h, path); err != nil {
+			return "", err
+		}
+	}
+	status, err := r.Run(ctx, dir, "--no-optional-locks", "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return "", wrapRunError("git status --porcelain=v2 --branch", status, err)
+	}
+	_, _ = fmt.Fprintf(h, "status=%s\n", status)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// InProgressOperation reports the operation left under way in the worktree at
+// dir: model.InProgressRebase, InProgressMerge, or InProgressBisect, checked in
+// that order, or "" when there is none. Like InspectFingerprint it only looks
+// at the git dir, so it costs no git invocation.
+func InProgressOperation(dir string) (string, error) {
+	gitDir, _, err := GitDirs(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, probe := range []struct {
+		name string
+		op   string
+	}{
+		{"rebase-merge", model.InProgressRebase},
+		{"rebase-apply", model.InProgressRebase},
+		{"MERGE_HEAD", model.InProgressMerge},
+		{"BISECT_LOG", model.InProgressBisect},
+	} {
+		_, err := os.Stat(filepath.Join(gitDir, probe.name))
+		if err == nil {
+			return probe.op, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// writeStatFingerprint hashes the size and mtime of path, or a marker when it
+// does not exist.
+func writeStatFingerprint(h hash.Hash, path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_, _ = fmt.Fprintf(h, "%s=-\n", filepath.Base(path))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(h, "%s=%d:%d\n", filepath.Base(path), info.Size(), info.ModTime().UnixNano())
+	return nil
+}
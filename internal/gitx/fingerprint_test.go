@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MIT
+package gitx_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/model"
+)
+
+func writeGitFile(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const fingerprintStatusKey = ":--no-optional-locks status --porcelain=v2 --branch"
+
+// fingerprintRunner answers the fingerprint's git status call with status.
+func fingerprintRunner(status string) *MockRunner {
+	return &MockRunner{Responses: map[string]MockResponse{fingerprintStatusKey: {Output: status}}}
+}
+
+func TestInspectFingerprintTracksGitState(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	writeGitFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n", base)
+	writeGitFile(t, filepath.Join(gitDir, "refs", "heads", "main"), "1111111\n", base)
+	if err := os.Chtimes(dir, base, base); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := fingerprintRunner("# branch.oid 1111111\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -0")
+	first, err := gitx.InspectFingerprint(context.Background(), runner, dir)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	again, err := gitx.InspectFingerprint(context.Background(), runner, dir)
+	if err != nil || again != first {
+		t.Fatalf("expected a stable fingerprint, got %q then %q (err %v)", first, again, err)
+	}
+
+	steps := []struct {
+		name   string
+		change func()
+	}{
+		{name: "commit", change: func() {
+			writeGitFile(t, filepath.Join(gitDir, "refs", "heads", "main"), "2222222\n", base.Add(time.Minute))
+		}},
+		{name: "edit tracked file", change: func() {
+			runner.Responses[fingerprintStatusKey] = MockResponse{Output: "# branch.upstream origin/main\n1 .M N... 100644 100644 100644 aaa aaa README.md"}
+		}},
+		{name: "untracked file below the top level", change: func() {
+			runner.Responses[fingerprintStatusKey] = MockResponse{Output: "# branch.upstream origin/main\n1 .M N... 100644 100644 100644 aaa aaa README.md\n? docs/notes/new.md"}
+		}},
+		{name: "upstream change", change: func() {
+			runner.Responses[fingerprintStatusKey] = MockResponse{Output: "# branch.upstream origin/release\n1 .M N... 100644 100644 100644 aaa aaa README.md\n? docs/notes/new.md"}
+		}},
+		{name: "fetch", change: func() {
+			writeGitFile(t, filepath.Join(gitDir, "FETCH_HEAD"), "3333333\n", base.Add(3*time.Minute))
+		}},
+		{name: "remote HEAD update", change: func() {
+			writeGitFile(t, filepath.Join(gitDir, "refs", "remotes", "origin", "HEAD"), "ref: refs/remotes/origin/trunk\n", base.Add(3*time.Minute))
+		}},
+		{name: "checkout", change: func() {
+			writeGitFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/feature\n", base.Add(4*time.Minute))
+		}},
+	}
+	prev := first
+	for _, step := range steps {
+		step.change()
+		got, err := gitx.InspectFingerprint(context.Background(), runner, dir)
+		if err != nil {
+			t.Fatalf("%s: fingerprint: %v", step.name, err)
+		}
+		if got == prev {
+			t.Fatalf("%s: expected the fingerprint to change", step.name)
+		}
+		prev = got
+	}
+}
+
+func TestInspectFingerprintFollowsGitdirFile(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	root := t.TempDir()
+	common := filepath.Join(root, "main", ".git")
+	linked := filepath.Join(common, "worktrees", "feature")
+	writeGitFile(t, filepath.Join(linked, "HEAD"), "ref: refs/heads/feature\n", base)
+	writeGitFile(t, filepath.Join(linked, "commondir"), "../..\n", base)
+	writeGitFile(t, filepath.Join(common, "refs", "heads", "feature"), "1111111\n", base)
+	work := filepath.Join(root, "feature")
+	writeGitFile(t, filepath.Join(work, ".git"), "gitdir: "+linked+"\n", base)
+
+	runner := fingerprintRunner("# branch.head feature")
+	first, err := gitx.InspectFingerprint(context.Background(), runner, work)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	// The branch ref lives in the common dir shared with the main worktree.
+	writeGitFile(t, filepath.Join(common, "refs", "heads", "feature"), "2222222\n", base.Add(time.Minute))
+	got, err := gitx.InspectFingerprint(context.Background(), runner, work)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if got == first {
+		t.Fatal("expected a commit on the shared branch ref to change the fingerprint")
+	}
+
+	if _, err := gitx.InspectFingerprint(context.Background(), runner, t.TempDir()); err == nil {
+		t.Fatal("expected an error for a directory without a git dir")
+	}
+}
+
+func TestInProgressOperationDetectsGitStateFiles(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	for _, tc := range []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "clean", want: ""},
+		{name: "interactive rebase", file: filepath.Join("rebase-merge", "head-name"), want: model.InProgressRebase},
+		{name: "am rebase", file: filepath.Join("rebase-apply", "head-name"), want: model.InProgressRebase},
+		{name: "merge", file: "MERGE_HEAD", want: model.InProgressMerge},
+		{name: "bisect", file: "BISECT_LOG", want: model.InProgressBisect},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gitDir := filepath.Join(dir, ".git")
+			writeGitFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n", base)
+			if tc.file != "" {
+				writeGitFile(t, filepath.Join(gitDir, tc.file), "x\n", base)
+			}
+			got, err := gitx.InProgressOperation(dir)
+			if err != nil {
+				t.Fatalf("in-progress operation: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestInspectFingerprintSeesWorktreeEditsInRealRepo(t *testing.T) {
+	ctx := context.Background()
+	runner := &gitx.GitRunner{}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "init"},
+	} {
+		if _, err := runner.Run(ctx, dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	tracked := filepath.Join(dir, "README.md")
+	if err := os.WriteFile(tracked, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "README.md"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "readme"},
+	} {
+		if _, err := runner.Run(ctx, dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	clean, err := gitx.InspectFingerprint(ctx, runner, dir)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+
+	// An empty directory is invisible to git; creating it now keeps the
+	// untracked file below from changing the worktree root's mtime.
+	nested := filepath.Join(dir, "docs", "notes")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tracked, []byte("two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := gitx.InspectFingerprint(ctx, runner, dir)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if edited == clean {
+		t.Fatal("expected an edit to a tracked file to change the fingerprint")
+	}
+
+	if err := os.WriteFile(filepath.Join(nested, "new.md"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	untracked, err := gitx.InspectFingerprint(ctx, runner, dir)
+	if err != nil {
+		t.Fatalf("fingerprint: %v", err)
+	}
+	if untracked == edited {
+		t.Fatal("expected an untracked file below the top level to change the fingerprint")
+	}
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT
+package gitx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/model"
+)
+
+// GitDirs returns the git dir and common dir for a worktree or bare repo at
+// dir without running git. Linked worktrees and submodules point at their git
+// dir through a .git file; linked worktrees also share refs through
+// commondir, so their git dir and common dir differ.
+func GitDirs(dir string) (string, string, error) {
+	gitDir := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitDir)
+	switch {
+	case err == nil && info.IsDir():
+	case err == nil:
+		target, ok := GitDirFromFile(gitDir)
+		if !ok {
+			return "", "", fmt.Errorf("%s is not a gitdir file", gitDir)
+		}
+		gitDir = target
+	case errors.Is(err, os.ErrNotExist):
+		// A bare repo is its own git dir.
+		if _, headErr := os.Stat(filepath.Join(dir, "HEAD")); headErr != nil {
+			return "", "", fmt.Errorf("no git dir found at %s", dir)
+		}
+		gitDir = dir
+	default:
+		return "", "", err
+	}
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolveRelative(gitDir, strings.TrimSpace(string(data)))
+	}
+	return gitDir, commonDir, nil
+}
+
+// WorktreeRole reports whether dir is the main worktree of its repository or
+// a linked one created by `git worktree add`, along with the absolute common
+// git dir they share. A linked worktree's git dir (.git/worktrees/<name>)
+// differs from the common dir; for the main worktree they are the same. Like
+// GitDirs it only reads the git dir, so it costs no git invocation.
+func WorktreeRole(dir string) (string, string, error) {
+	gitDir, commonDir, err := GitDirs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	if commonDir, err = filepath.Abs(commonDir); err != nil {
+		return "", "", err
+	}
+	if gitDir, err = filepath.Abs(gitDir); err != nil {
+		return "", "", err
+	}
+	if gitDir == commonDir {
+		return model.WorktreeRoleMain, commonDir, nil
+	}
+	return model.WorktreeRoleLinked, commonDir, nil
+}
+
+// IsShallow reports whether the repository at dir has truncated history. Git
+// records the shallow boundary commits in a shallow file in the common git
+// dir and removes it once the history is complete, so, like GitDirs, this
+// costs no git invocation.
+func IsShallow(dir string) (bool, error) {
+	_, commonDir, err := GitDirs(dir)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(filepath.Join(commonDir, "shallow"))
+	switch {
+	case err == nil:
+		return info.Size() > 0, nil
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// GitDirFromFile reads a "gitdir: <path>" .git file and returns the git dir
+// it points at, resolved against the file's directory. It reports false when
+// path cannot be read or is not a gitdir file.
+func GitDirFromFile(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	raw, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok || strings.TrimSpace(raw) == "" {
+		return "", false
+	}
+	return resolveRelative(filepath.Dir(path), strings.TrimSpace(raw)), true
+}
+
+func resolveRelative(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Clean(filepath.Join(base, path))
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+package gitx_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/gitx"
+)
+
+func TestGitDirFromFile(t *testing.T) {
+	tmp := t.TempDir()
+	if _, ok := gitx.GitDirFromFile(filepath.Join(tmp, "missing")); ok {
+		t.Fatal("expected missing file to return false")
+	}
+
+	invalid := filepath.Join(tmp, ".git.invalid")
+	if err := os.WriteFile(invalid, []byte("not-gitdir"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gitx.GitDirFromFile(invalid); ok {
+		t.Fatal("expected invalid content to return false")
+	}
+
+	empty := filepath.Join(tmp, ".git.empty")
+	if err := os.WriteFile(empty, []byte("gitdir:   "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := gitx.GitDirFromFile(empty); ok {
+		t.Fatal("expected empty gitdir to return false")
+	}
+
+	relative := filepath.Join(tmp, ".git.rel")
+	if err := os.WriteFile(relative, []byte("gitdir: ../actual.git"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := gitx.GitDirFromFile(relative)
+	if !ok {
+		t.Fatal("expected relative gitdir to parse")
+	}
+	want := filepath.Clean(filepath.Join(filepath.Dir(relative), "../actual.git"))
+	if got != want {
+		t.Fatalf("unexpected relative gitdir: got %q want %q", got, want)
+	}
+}
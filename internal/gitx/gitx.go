@@ -6,11 +6,16 @@ package gitx
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/obs"
@@ -26,6 +31,58 @@ type Runner interface {
 	Run(ctx context.Context, dir string, args ...string) (string, error)
 }
 
+type commandOutputKey struct{}
+
+// WithCommandOutput returns a context under which GitRunner writes every git
+// command it runs, with its raw stdout and stderr, to w. Commands sharing the
+// context must not run concurrently unless w is safe for concurrent use.
+func WithCommandOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, commandOutputKey{}, w)
+}
+
+// RecordCommand writes one command's transcript to the writer installed by
+// WithCommandOutput, if any: the command line, its untrimmed stdout and
+// stderr, and the error it failed with. Credentials embedded in URLs are
+// replaced with *** throughout, since transcripts end up in log files. Write
+// errors are ignored; the transcript is a debugging aid and must never fail
+// the command.
+func RecordCommand(ctx context.Context, bin string, args []string, stdout, stderr []byte, err error) {
+	w, ok := ctx.Value(commandOutputKey{}).(io.Writer)
+	if !ok || w == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "$ %s %s\n", bin, redactURLCredentials(strings.Join(args, " ")))
+	for _, out := range [][]byte{stdout, stderr} {
+		if len(out) == 0 {
+			continue
+		}
+		_, _ = io.WriteString(w, redactURLCredentials(string(out)))
+		if out[len(out)-1] != '\n' {
+			_, _ = io.WriteString(w, "\n")
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "error: %s\n", redactURLCredentials(err.Error()))
+	}
+}
+
+// urlUserinfoPattern matches the scheme and userinfo of a URL anywhere in
+// free text, such as a clone argument or a quoted URL in git's stderr.
+var urlUserinfoPattern = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://)[^\s/@'"]+@`)
+
+// redactURLCredentials replaces the userinfo of every URL in text with ***,
+// the way urlutil.RedactCredentials does for a single URL: ssh:// and git://
+// URLs carry a login name rather than a secret and are left unchanged.
+func redactURLCredentials(text string) string {
+	return urlUserinfoPattern.ReplaceAllStringFunc(text, func(match string) string {
+		scheme := match[:strings.Index(match, "://")+3]
+		if scheme == "ssh://" || scheme == "git://" {
+			return match
+		}
+		return scheme + "***@"
+	})
+}
+
 // GitRunner is the default Runner implementation that shells out to git.
 type GitRunner struct {
 	// GitBin is the path to the git binary. Defaults to "git".
@@ -58,6 +115,7 @@ func (g *GitRunner) Run(ctx context.Context, dir string, args ...string) (string
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err := cmd.Run()
+	RecordCommand(ctx, bin, args, stdout.Bytes(), stderr.Bytes(), err)
 	out := strings.TrimSpace(stdout.String())
 	if err != nil {
 		if errText := strings.TrimSpace(stderr.String()); errText != "" {
@@ -94,6 +152,20 @@ func IsBare(ctx context.Context, r Runner, dir string, logger obs.Logger) (bool,
 	return strings.TrimSpace(out) == "true", nil
 }
 
+// Version returns the installed git's version, such as 2.43.0, from
+// git --version.
+func Version(ctx context.Context, r Runner) (string, error) {
+	out, err := r.Run(ctx, "", "--version")
+	if err != nil {
+		return "", wrapRunError("git --version", out, err)
+	}
+	version, ok := strings.CutPrefix(strings.TrimSpace(out), "git version ")
+	if !ok || version == "" {
+		return "", fmt.Errorf("git --version: unexpected output %q", out)
+	}
+	return version, nil
+}
+
 // Remotes returns all configured remotes for the repo.
 func Remotes(ctx context.Context, r Runner, dir string, logger obs.Logger) ([]model.Remote, error) {
 	out, err := r.Run(ctx, dir, "remote")
@@ -154,6 +226,35 @@ func WorktreeStatus(ctx context.Context, r Runner, dir string) (*model.Worktree,
 	return ParsePorcelainStatus(out), nil
 }
 
+// IgnoredFiles lists worktree paths matched by ignore rules. The traditional
+// --ignored mode collapses fully ignored directories so large trees such as
+// node_modules cost one entry rather than a full walk in the output.
+func IgnoredFiles(ctx context.Context, r Runner, dir string) ([]string, error) {
+	out, err := r.Run(ctx, dir, "status", "--porcelain=v1", "--ignored")
+	if err != nil {
+		return nil, fmt.Errorf("git status --ignored: %w", err)
+	}
+	return ParseIgnoredStatus(out), nil
+}
+
+// LastCommitTime returns the committer date of HEAD. Empty output yields the
+// zero time; a repository with no commits makes git log fail instead.
+func LastCommitTime(ctx context.Context, r Runner, dir string) (time.Time, error) {
+	out, err := r.Run(ctx, dir, "log", "-1", "--format=%cI")
+	if err != nil {
+		return time.Time{}, wrapRunError("git log", out, err)
+	}
+	raw := strings.TrimSpace(out)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log: parse commit date %q: %w", raw, err)
+	}
+	return at, nil
+}
+
 // TrackingStatus returns upstream tracking info for the current branch.
 func TrackingStatus(ctx context.Context, r Runner, dir string) (model.Tracking, error) {
 	out, err := r.Run(ctx, dir, "for-each-ref", "--format=%(refname:short)|%(upstream:short)|%(upstream:track)|%(upstream:trackshort)", "refs/heads")
@@ -265,18 +366,268 @@ func HasSubmodules(ctx context.Context, r Runner, dir string) (bool, error) {
 	return true, nil
 }
 
+// HasLFS reports whether any tracked .gitattributes file routes paths through
+// the lfs filter. Repos without a match (git grep exits 1), and bare repos,
+// where git grep has no work tree to search, report false; any other failure
+// is returned.
+func HasLFS(ctx context.Context, r Runner, dir string) (bool, error) {
+	out, err := r.Run(ctx, dir, "grep", "-q", "--fixed-strings", "filter=lfs", "--", ".gitattributes", "*/.gitattributes")
+	if err == nil {
+		return true, nil
+	}
+	if exitCode(err) == 1 && ctx.Err() == nil {
+		return false, nil
+	}
+	if bare, bareErr := r.Run(ctx, dir, "rev-parse", "--is-bare-repository"); bareErr == nil && strings.TrimSpace(bare) == "true" {
+		return false, nil
+	}
+	return false, wrapRunError("git grep filter=lfs", out, err)
+}
+
+// LFSFetch downloads the Git LFS objects for the current checkout.
+func LFSFetch(ctx context.Context, r Runner, dir string) error {
+	out, err := r.Run(ctx, dir, "lfs", "fetch")
+	return wrapRunError("git lfs fetch", out, err)
+}
+
+// SubmoduleUpdate checks out the commits the superproject records for its
+// submodules, initializing and recursing into nested ones as needed.
+func SubmoduleUpdate(ctx context.Context, r Runner, dir string) error {
+	out, err := r.Run(ctx, dir, "submodule", "update", "--init", "--recursive")
+	return wrapRunError("git submodule update --init --recursive", out, err)
+}
+
+// RemoteDefaultBranch returns the branch remote's HEAD points at, read from
+// the local refs/remotes/<remote>/HEAD symref that clone and
+// `git remote set-head` record. It errors when the symref is not set.
+func RemoteDefaultBranch(ctx context.Context, r Runner, dir, remote string) (string, error) {
+	out, err := r.Run(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD")
+	if err != nil {
+		return "", wrapRunError("git symbolic-ref refs/remotes/"+remote+"/HEAD", out, err)
+	}
+	branch := strings.TrimPrefix(strings.TrimSpace(out), remote+"/")
+	if branch == "" {
+		return "", fmt.Errorf("remote %s has no default branch", remote)
+	}
+	return branch, nil
+}
+
+// RemoteHeadBranch asks remote which branch its HEAD points at right now,
+// via `git ls-remote --symref`, rather than trusting the
+// refs/remotes/<remote>/HEAD symref, which only clone and
+// `git remote set-head` write. It contacts the remote.
+func RemoteHeadBranch(ctx context.Context, r Runner, dir, remote string) (string, error) {
+	out, err := r.Run(ctx, dir, "ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return "", wrapRunError("git ls-remote --symref "+remote+" HEAD", out, err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		ref, ok := strings.CutPrefix(strings.TrimSpace(line), "ref: ")
+		if !ok {
+			continue
+		}
+		target, name, _ := strings.Cut(ref, "\t")
+		if branch, ok := strings.CutPrefix(target, "refs/heads/"); ok && name == "HEAD" && branch != "" {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("remote %s did not report a HEAD branch", remote)
+}
+
+// UpdateRemoteHead runs `git remote set-head <remote> --auto`, which asks
+// remote for its HEAD and rewrites the local refs/remotes/<remote>/HEAD
+// symref that RemoteDefaultBranch reads. It contacts the remote.
+func UpdateRemoteHead(ctx context.Context, r Runner, dir, remote string) error {
+	out, err := r.Run(ctx, dir, "remote", "set-head", remote, "--auto")
+	return wrapRunError("git remote set-head "+remote+" --auto", out, err)
+}
+
+// RemoteUpdate runs `git remote update --prune`, which refreshes every ref of
+// a --mirror clone from all of its remotes and drops refs deleted upstream.
+func RemoteUpdate(ctx context.Context, r Runner, dir string) error {
+	out, err := r.Run(ctx, dir, "remote", "update", "--prune")
+	return wrapRunError("git remote update --prune", out, err)
+}
+
+// DeleteBranch deletes a local branch with `git branch -d`, which refuses
+// branches that are not fully merged. force uses -D and deletes regardless.
+func DeleteBranch(ctx context.Context, r Runner, dir, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	out, err := r.Run(ctx, dir, "branch", flag, "--", branch)
+	return wrapRunError("git branch "+flag, out, err)
+}
+
+// Fsck runs `git fsck --no-dangling` and returns the problems it reports, one
+// per line; a clean repository returns none. Dangling objects are normal
+// leftovers and are not reported. Only fsck's own findings, exit statuses
+// below 128, count as issues. A path that is missing or not a repository, a
+// fatal git error, and a cancelled or timed-out ctx are returned as errors.
+func Fsck(ctx context.Context, r Runner, dir string) ([]string, error) {
+	if _, _, err := GitDirs(dir); err != nil {
+		return nil, fmt.Errorf("git fsck: %w", err)
+	}
+	out, err := r.Run(ctx, dir, "fsck", "--no-dangling")
+	if err == nil {
+		return nil, nil
+	}
+	if code := exitCode(err); ctx.Err() != nil || code < 1 || code >= 128 {
+		return nil, wrapRunError("git fsck", out, err)
+	}
+	// GitRunner reports stderr as "<stderr>: <exit error>"; keep only the
+	// stderr text so the exit status does not show up as an issue.
+	msg := err.Error()
+	if inner := errors.Unwrap(err); inner != nil {
+		msg = strings.TrimSuffix(msg, ": "+inner.Error())
+	}
+	var issues []string
+	for _, line := range strings.Split(out+"\n"+msg, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			issues = append(issues, line)
+		}
+	}
+	return issues, nil
+}
+
+// recentCommitsFormat separates log fields with the ASCII unit separator so
+// author names and subjects can contain any printable text.
+const recentCommitsFormat = "--format=%H%x1f%an%x1f%aI%x1f%s"
+
+// RecentCommits returns up to n commits reachable from HEAD, newest first.
+func RecentCommits(ctx context.Context, r Runner, dir string, n int) ([]model.Commit, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	out, err := r.Run(ctx, dir, "log", "-n", strconv.Itoa(n), recentCommitsFormat)
+	if err != nil {
+		return nil, wrapRunError("git log", out, err)
+	}
+	var commits []model.Commit
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("parse commit date %q: %w", fields[2], err)
+		}
+		commits = append(commits, model.Commit{Hash: fields[0], Author: fields[1], Date: date, Subject: fields[3]})
+	}
+	return commits, nil
+}
+
 // Fetch runs a safe fetch with submodule recursion disabled.
 func Fetch(ctx context.Context, r Runner, dir string) error {
 	out, err := r.Run(ctx, dir, "-c", "fetch.recurseSubmodules=false", "fetch", "--all", "--prune", "--prune-tags", "--no-recurse-submodules")
 	return wrapRunError("git fetch", out, err)
 }
 
+// FetchOptions selects what a sync fetch touches. The zero value runs the same
+// fetch as Fetch. A non-empty Remote fetches only that remote; a positive
+// Depth adds --deepen; NoPruneTags drops --prune-tags.
+type FetchOptions struct {
+	Remote      string
+	Depth       int
+	NoPruneTags bool
+}
+
+// FetchResult reports local changes made by FetchWithOptions.
+type FetchResult struct {
+	// TagsPruned counts local tags the fetch deleted because they no longer
+	// exist on the remote.
+	TagsPruned int
+}
+
+// FetchCommand renders the fetch FetchWithOptions runs for remote (every remote
+// when empty) as a human-readable action string.
+func FetchCommand(remote string, pruneTags bool) string {
+	return "git " + strings.Join(fetchArgs(FetchOptions{Remote: remote, NoPruneTags: !pruneTags}), " ")
+}
+
+func fetchArgs(opts FetchOptions) []string {
+	args := []string{"fetch"}
+	if opts.Remote != "" {
+		args = append(args, opts.Remote)
+	} else {
+		args = append(args, "--all")
+	}
+	args = append(args, "--prune")
+	if !opts.NoPruneTags {
+		args = append(args, "--prune-tags")
+	}
+	args = append(args, "--no-recurse-submodules")
+	if opts.Depth > 0 {
+		args = append(args, "--deepen", strconv.Itoa(opts.Depth))
+	}
+	return args
+}
+
+// FetchWithOptions runs the sync fetch described by opts. When tags are pruned,
+// the local tag refs are listed before and after the fetch to count the ones
+// that were removed; git only reports pruned refs on stderr, which the runner
+// does not return on success. A failed listing leaves the count at zero.
+func FetchWithOptions(ctx context.Context, r Runner, dir string, opts FetchOptions) (FetchResult, error) {
+	if opts.Remote != "" && (strings.TrimSpace(opts.Remote) == "" || strings.HasPrefix(opts.Remote, "-")) {
+		return FetchResult{}, fmt.Errorf("git fetch: invalid remote name %q", opts.Remote)
+	}
+	var before map[string]struct{}
+	if !opts.NoPruneTags {
+		before, _ = localTags(ctx, r, dir)
+	}
+	args := append([]string{"-c", "fetch.recurseSubmodules=false"}, fetchArgs(opts)...)
+	out, err := r.Run(ctx, dir, args...)
+	if err != nil {
+		return FetchResult{}, wrapRunError("git fetch", out, err)
+	}
+	result := FetchResult{}
+	if before == nil {
+		return result, nil
+	}
+	after, err := localTags(ctx, r, dir)
+	if err != nil {
+		return result, nil
+	}
+	for tag := range before {
+		if _, ok := after[tag]; !ok {
+			result.TagsPruned++
+		}
+	}
+	return result, nil
+}
+
+func localTags(ctx context.Context, r Runner, dir string) (map[string]struct{}, error) {
+	out, err := r.Run(ctx, dir, "for-each-ref", "--format=%(refname)", "refs/tags")
+	if err != nil {
+		return nil, wrapRunError("git for-each-ref refs/tags", out, err)
+	}
+	tags := make(map[string]struct{})
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			tags[line] = struct{}{}
+		}
+	}
+	return tags, nil
+}
+
 // PullRebase runs a safe pull --rebase with submodule recursion disabled.
 func PullRebase(ctx context.Context, r Runner, dir string) error {
 	out, err := r.Run(ctx, dir, "-c", "fetch.recurseSubmodules=false", "pull", "--rebase", "--no-recurse-submodules")
 	return wrapRunError("git pull --rebase", out, err)
 }
 
+// CreateBranch creates a local branch named name at the current HEAD without
+// switching to it. It fails when the branch already exists.
+func CreateBranch(ctx context.Context, r Runner, dir, name string) error {
+	if strings.TrimSpace(name) == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("git branch: invalid branch name %q", name)
+	}
+	out, err := r.Run(ctx, dir, "branch", "--no-track", name, "HEAD")
+	return wrapRunError("git branch "+name, out, err)
+}
+
 // Push publishes local commits on the current branch to its upstream.
 func Push(ctx context.Context, r Runner, dir string) error {
 	out, err := r.Run(ctx, dir, "push")
@@ -311,6 +662,20 @@ func SetRemoteURL(ctx context.Context, r Runner, dir, remote, remoteURL string)
 	return wrapRunError("git remote set-url", out, err)
 }
 
+// AddRemote adds a named remote pointing at remoteURL.
+func AddRemote(ctx context.Context, r Runner, dir, remote, remoteURL string) error {
+	remote = strings.TrimSpace(remote)
+	remoteURL = strings.TrimSpace(remoteURL)
+	if err := rejectFlagLike("remote", remote); err != nil {
+		return err
+	}
+	if err := rejectFlagLike("remote URL", remoteURL); err != nil {
+		return err
+	}
+	out, err := r.Run(ctx, dir, "remote", "add", remote, remoteURL)
+	return wrapRunError("git remote add", out, err)
+}
+
 // StashPush stashes current worktree changes (including untracked files).
 // Returns true when a stash entry was created.
 func StashPush(ctx context.Context, r Runner, dir, message string) (bool, error) {
@@ -325,6 +690,21 @@ func StashPush(ctx context.Context, r Runner, dir, message string) (bool, error)
 	return !strings.Contains(strings.ToLower(out), "no local changes to save"), nil
 }
 
+// StashList returns the number of entries on the stash stack.
+func StashList(ctx context.Context, r Runner, dir string) (int, error) {
+	out, err := r.Run(ctx, dir, "stash", "list")
+	if err != nil {
+		return 0, wrapRunError("git stash list", out, err)
+	}
+	count := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // StashPop reapplies the most recent stash entry.
 func StashPop(ctx context.Context, r Runner, dir string) error {
 	out, err := r.Run(ctx, dir, "stash", "pop")
@@ -342,34 +722,79 @@ func CleanFD(ctx context.Context, r Runner, dir string) error {
 	return wrapRunError("git clean -f -d", out, err)
 }
 
+// ResetHardTo resets the current branch, index, and worktree to ref,
+// discarding local commits and changes.
+func ResetHardTo(ctx context.Context, r Runner, dir, ref string) error {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid reset target %q", ref)
+	}
+	out, err := r.Run(ctx, dir, "reset", "--hard", ref)
+	return wrapRunError("git reset --hard "+ref, out, err)
+}
+
+// CleanFDX removes untracked files and directories, including ignored ones.
+func CleanFDX(ctx context.Context, r Runner, dir string) error {
+	out, err := r.Run(ctx, dir, "clean", "-f", "-d", "-x")
+	return wrapRunError("git clean -fdx", out, err)
+}
+
 // Clone runs a clone operation. Branch is ignored for mirror clones.
 func Clone(ctx context.Context, r Runner, remoteURL, targetPath, branch string, mirror bool) error {
+	args, err := cloneArgs(remoteURL, targetPath, branch, mirror, 0)
+	if err != nil {
+		return err
+	}
+	out, err := r.Run(ctx, "", args...)
+	return wrapRunError("git clone", out, err)
+}
+
+// CloneShallow clones only the newest depth commits of a single branch. When
+// branch is empty the remote's default branch is cloned.
+func CloneShallow(ctx context.Context, r Runner, remoteURL, targetPath, branch string, depth int) error {
+	if depth <= 0 {
+		return fmt.Errorf("invalid clone depth %d", depth)
+	}
+	args, err := cloneArgs(remoteURL, targetPath, branch, false, depth)
+	if err != nil {
+		return err
+	}
+	out, err := r.Run(ctx, "", args...)
+	return wrapRunError("git clone", out, err)
+}
+
+func cloneArgs(remoteURL, targetPath, branch string, mirror bool, depth int) ([]string, error) {
 	// remoteURL and targetPath are passed to git verbatim: leading/trailing
 	// whitespace is legal in local paths, and the flag-injection guard only
 	// needs to reject values whose first byte is '-' (a leading space cannot be
 	// parsed as an option), so trimming would only corrupt valid inputs.
 	branch = strings.TrimSpace(branch)
 	if err := rejectFlagLike("remote URL", remoteURL); err != nil {
-		return err
+		return nil, err
 	}
 	if targetPath != "" {
 		if err := rejectFlagLike("target path", targetPath); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	args := []string{"clone"}
 	if mirror {
-		args = append(args, "--mirror")
-	} else if branch != "" {
+		return append(args, "--mirror", remoteURL, targetPath), nil
+	}
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	if branch != "" {
 		if err := rejectFlagLike("branch", branch); err != nil {
-			return err
+			return nil, err
 		}
-		args = append(args, "--branch", branch, "--single-branch")
+		args = append(args, "--branch", branch)
 	}
-	args = append(args, remoteURL, targetPath)
-	out, err := r.Run(ctx, "", args...)
-	return wrapRunError("git clone", out, err)
+	if branch != "" || depth > 0 {
+		args = append(args, "--single-branch")
+	}
+	return append(args, remoteURL, targetPath), nil
 }
 
 // rejectFlagLike rejects a positional argument (URL, path, ref, or remote
@@ -384,6 +809,16 @@ func rejectFlagLike(field, value string) error {
 	return nil
 }
 
+// exitCode returns the exit status of a git run that failed, or -1 when err
+// carries none, such as when git could not be started.
+func exitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
 func wrapRunError(op, output string, err error) error {
 	if err == nil {
 		return nil
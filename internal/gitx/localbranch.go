@@ -4,6 +4,7 @@ package gitx
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -60,6 +61,26 @@ func MergedBranches(ctx context.Context, r Runner, dir, base string) (map[string
 	return merged, nil
 }
 
+// CommitsBehind counts the commits reachable from base but not from the local
+// branch, i.e. how far branch has fallen behind base. base must resolve to an
+// existing ref or git errors.
+func CommitsBehind(ctx context.Context, r Runner, dir, base, branch string) (int, error) {
+	base = strings.TrimSpace(base)
+	branch = strings.TrimSpace(branch)
+	if base == "" || branch == "" {
+		return 0, fmt.Errorf("behind count requires base and branch refs")
+	}
+	out, err := r.Run(ctx, dir, "rev-list", "--count", "refs/heads/"+branch+".."+base)
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list --count %q..%q: %w", branch, base, err)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list --count: parse %q: %w", strings.TrimSpace(out), err)
+	}
+	return count, nil
+}
+
 // PatchEquivalentToBase reports whether every commit unique to branch is
 // patch-equivalent to a commit already in base (the squash/rebase-merge case),
 // via git cherry. base must resolve to an existing ref or git errors.
@@ -21,6 +21,14 @@ type MockResponse struct {
 	Err    error
 }
 
+// MockExitError stands in for the *exec.ExitError of a git run that exited
+// with the given status.
+type MockExitError int
+
+func (e MockExitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+func (e MockExitError) ExitCode() int { return int(e) }
+
 func (m *MockRunner) Run(_ context.Context, dir string, args ...string) (string, error) {
 	// Copy args so LastArgs doesn't alias the caller's backing array; a caller
 	// that reuses/mutates its slice after Run returns must not change LastArgs.
@@ -2,17 +2,53 @@
 package gitx
 
 import (
+	"net"
 	"net/url"
 	"sort"
 	"strings"
+	"sync/atomic"
 )
 
+// standardPorts are dropped from host_aliases keys and values, so an alias
+// written as host:22 or host:443 matches the portless host. Repo IDs never
+// carry a port unless a host alias maps to one.
+var standardPorts = map[string]bool{"22": true, "80": true, "443": true, "9418": true}
+
+// hostAliases holds the table installed by SetHostAliases.
+var hostAliases atomic.Pointer[map[string]string]
+
+// SetHostAliases installs the alias-to-canonical-host table NormalizeURL
+// consults, e.g. {"github-work": "github.com"} for an SSH config Host alias.
+// Keys and values are lowercased with standard ports stripped. A key with a
+// non-standard port ("git.example.com:2222") matches only remotes on that
+// port, and a value with one keeps the port in the repo ID, for a host that
+// serves distinct repositories on several ports. A nil or empty map clears
+// the table. It is process-wide: call it once after
+// loading config, before any repo IDs are computed.
+func SetHostAliases(aliases map[string]string) {
+	if len(aliases) == 0 {
+		hostAliases.Store(nil)
+		return
+	}
+	table := make(map[string]string, len(aliases))
+	for alias, canonical := range aliases {
+		alias, canonical = canonicalHost(alias), canonicalHost(canonical)
+		if alias == "" || canonical == "" {
+			continue
+		}
+		table[alias] = canonical
+	}
+	hostAliases.Store(&table)
+}
+
 // NormalizeURL converts a git remote URL into a canonical repo_id.
 //
 // Rules:
 //   - Strip protocol (https://, git://, ssh://) and user (git@)
 //   - Convert git@host:path to host/path
-//   - Lowercase the host portion
+//   - Lowercase the host portion and drop any port
+//   - Rewrite the host through the SetHostAliases table, which may map a
+//     host:port to a host that keeps its port
 //   - Strip trailing ".git"
 //   - Strip trailing slashes
 //
@@ -20,6 +56,7 @@ import (
 //
 //	git@github.com:Org/Repo.git  → github.com/Org/Repo
 //	https://github.com/Org/Repo.git → github.com/Org/Repo
+//	git@github-work:Org/Repo.git → github.com/Org/Repo (with github-work aliased)
 func NormalizeURL(rawURL string) string {
 	if rawURL == "" {
 		return ""
@@ -31,9 +68,24 @@ func NormalizeURL(rawURL string) string {
 	if i := strings.Index(rawURL, "@"); i >= 0 && !strings.Contains(rawURL[:i], "://") {
 		// SSH shorthand like git@github.com:Org/Repo.git
 		rest := rawURL[i+1:]
-		if colonIdx := strings.Index(rest, ":"); colonIdx >= 0 {
-			host = rest[:colonIdx]
-			path = rest[colonIdx+1:]
+		switch {
+		case strings.HasPrefix(rest, "["):
+			// git@[host:port]:path
+			if end := strings.Index(rest, "]:"); end >= 0 {
+				host = rest[1:end]
+				path = rest[end+2:]
+			}
+		case strings.HasPrefix(rawURL, "["):
+			// [git@host:port]:path
+			if end := strings.Index(rest, "]:"); end >= 0 {
+				host = rest[:end]
+				path = rest[end+2:]
+			}
+		default:
+			if colonIdx := strings.Index(rest, ":"); colonIdx >= 0 {
+				host = rest[:colonIdx]
+				path = rest[colonIdx+1:]
+			}
 		}
 	} else {
 		// URL with protocol
@@ -41,11 +93,19 @@ func NormalizeURL(rawURL string) string {
 		if err != nil {
 			return rawURL
 		}
-		host = parsed.Hostname()
+		host = parsed.Host
 		path = strings.TrimPrefix(parsed.Path, "/")
 	}
 
-	host = strings.ToLower(host)
+	withPort := canonicalHost(host)
+	host = hostWithoutPort(host)
+	if aliases := hostAliases.Load(); aliases != nil {
+		if canonical, ok := (*aliases)[withPort]; ok {
+			host = canonical
+		} else if canonical, ok := (*aliases)[host]; ok {
+			host = canonical
+		}
+	}
 	path = strings.TrimSuffix(path, ".git")
 	path = strings.TrimRight(path, "/")
 
@@ -55,6 +115,28 @@ func NormalizeURL(rawURL string) string {
 	return host + "/" + path
 }
 
+// canonicalHost lowercases host and drops a standard port. IPv6 literals keep
+// their brackets only when a non-standard port follows.
+func canonicalHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		if standardPorts[port] || port == "" {
+			return h
+		}
+		return net.JoinHostPort(h, port)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
+// hostWithoutPort lowercases host and drops any port and IPv6 brackets.
+func hostWithoutPort(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+}
+
 // PrimaryRemote selects the preferred remote from a list.
 // Prefers "origin", falls back to first alphabetically.
 func PrimaryRemote(remoteNames []string) string {
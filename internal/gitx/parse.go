@@ -10,6 +10,8 @@ import (
 
 const wouldPrunePrefix = "* [would prune] "
 
+const ignoredStatusPrefix = "!! "
+
 // ParseRemotePruneDryRun extracts the ref names from git remote prune
 // --dry-run output. Header lines such as "Pruning origin" and "URL:" are
 // intentionally ignored.
@@ -29,6 +31,21 @@ func ParseRemotePruneDryRun(output string) []string {
 	return refs
 }
 
+// ParseIgnoredStatus extracts ignored paths ("!! " lines) from
+// `git status --porcelain=v1 --ignored` output. Fully ignored directories are
+// reported once with a trailing slash.
+func ParseIgnoredStatus(output string) []string {
+	var paths []string
+	for line := range strings.SplitSeq(output, "\n") {
+		path, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), ignoredStatusPrefix)
+		if !ok || path == "" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
 // ParsePorcelainStatus parses the output of `git status --porcelain=v1`
 // into a Worktree struct.
 func ParsePorcelainStatus(output string) *model.Worktree {
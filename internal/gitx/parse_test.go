@@ -184,3 +184,14 @@ var _ = Describe("ParseRevListCount", func() {
 		Expect(behind).To(Equal(10))
 	})
 })
+
+var _ = Describe("ParseIgnoredStatus", func() {
+	It("returns nothing when no ignored paths are reported", func() {
+		Expect(gitx.ParseIgnoredStatus("?? new_file.go\n M changed.go\n")).To(BeEmpty())
+	})
+
+	It("extracts ignored files and collapsed directories", func() {
+		output := "?? untracked.txt\n!! a.log\n!! build/\n"
+		Expect(gitx.ParseIgnoredStatus(output)).To(Equal([]string{"a.log", "build/"}))
+	})
+})
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+package gitx
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// WebURL converts a git remote URL into the browsable page of the repo on its
+// host. GitHub, GitLab, Bitbucket, and most self-hosted forges serve a repo
+// at https://host/path, so every supported remote maps the same way:
+//
+//	git@github.com:org/repo.git            → https://github.com/org/repo
+//	ssh://git@gitlab.com:2222/g/sub/r.git  → https://gitlab.com/g/sub/r
+//	https://user@bitbucket.org/org/r.git   → https://bitbucket.org/org/r
+//	http://git.example.com:8080/org/r      → http://git.example.com:8080/org/r
+//
+// SSH and git:// ports belong to the transport, not the web server, so they
+// are dropped; http(s) ports are kept unless standard. Hosts are rewritten
+// through the SetHostAliases table. Local paths and file:// remotes have no
+// web page and return an error.
+func WebURL(remoteURL string) (string, error) {
+	raw := strings.TrimSpace(remoteURL)
+	if raw == "" {
+		return "", fmt.Errorf("empty remote URL")
+	}
+
+	scheme, host, path := "https", "", ""
+	if strings.Contains(raw, "://") {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return "", fmt.Errorf("cannot parse remote URL %q: %w", remoteURL, err)
+		}
+		switch strings.ToLower(parsed.Scheme) {
+		case "http":
+			scheme = "http"
+			host = parsed.Host
+		case "https":
+			host = parsed.Host
+		case "ssh", "git", "git+ssh", "ssh+git":
+			host = parsed.Hostname()
+		default:
+			return "", fmt.Errorf("remote URL %q has no web page (unsupported scheme %q)", remoteURL, parsed.Scheme)
+		}
+		path = parsed.Path
+	} else {
+		var ok bool
+		host, path, ok = splitSCPLikeURL(raw)
+		if !ok {
+			return "", fmt.Errorf("remote URL %q has no web page (not a host remote)", remoteURL)
+		}
+	}
+
+	host = canonicalHost(host)
+	if aliases := hostAliases.Load(); aliases != nil {
+		if canonical, ok := (*aliases)[host]; ok {
+			host = canonical
+		}
+	}
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.TrimRight(path, "/")
+	if host == "" || path == "" {
+		return "", fmt.Errorf("cannot derive a web URL from remote %q", remoteURL)
+	}
+	return scheme + "://" + host + "/" + path, nil
+}
+
+// splitSCPLikeURL splits the scp-like SSH form [user@]host:path, including the
+// bracketed [host:port]:path variant. A path with a slash before the first
+// colon is local, matching how git itself tells the two apart.
+func splitSCPLikeURL(raw string) (string, string, bool) {
+	rest := raw
+	if at := strings.Index(rest, "@"); at >= 0 {
+		rest = rest[at+1:]
+	}
+	if strings.HasPrefix(rest, "[") {
+		end := strings.Index(rest, "]:")
+		if end < 0 {
+			return "", "", false
+		}
+		host := rest[1:end]
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		return host, rest[end+2:], true
+	}
+	colon := strings.Index(rest, ":")
+	// A single letter before the colon is a Windows drive, as in C:\repo.
+	if colon <= 1 || strings.Contains(rest[:colon], "/") {
+		return "", "", false
+	}
+	return rest[:colon], rest[colon+1:], true
+}
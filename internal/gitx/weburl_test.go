@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+package gitx_test
+
+import (
+	"testing"
+
+	"github.com/skaphos/repokeeper/internal/gitx"
+)
+
+func TestWebURL(t *testing.T) {
+	cases := []struct {
+		remote string
+		want   string
+	}{
+		{remote: "git@github.com:org/repo.git", want: "https://github.com/org/repo"},
+		{remote: "github.com:org/repo", want: "https://github.com/org/repo"},
+		{remote: "ssh://git@github.com/org/repo.git", want: "https://github.com/org/repo"},
+		{remote: "ssh://git@gitlab.com:2222/group/sub/repo.git", want: "https://gitlab.com/group/sub/repo"},
+		{remote: "git@[gitlab.example.com:2222]:group/repo.git", want: "https://gitlab.example.com/group/repo"},
+		{remote: "git://git.example.com/org/repo.git", want: "https://git.example.com/org/repo"},
+		{remote: "https://github.com/org/repo.git", want: "https://github.com/org/repo"},
+		{remote: "https://user@bitbucket.org/org/repo.git", want: "https://bitbucket.org/org/repo"},
+		{remote: "https://GitLab.com:443/Group/Repo/", want: "https://gitlab.com/Group/Repo"},
+		{remote: "https://git.example.com:8443/org/repo.git", want: "https://git.example.com:8443/org/repo"},
+		{remote: "http://git.example.com:8080/org/repo", want: "http://git.example.com:8080/org/repo"},
+		{remote: "  git@bitbucket.org:team/repo.git\n", want: "https://bitbucket.org/team/repo"},
+	}
+	for _, tc := range cases {
+		got, err := gitx.WebURL(tc.remote)
+		if err != nil {
+			t.Fatalf("WebURL(%q): %v", tc.remote, err)
+		}
+		if got != tc.want {
+			t.Fatalf("WebURL(%q) = %q, want %q", tc.remote, got, tc.want)
+		}
+	}
+}
+
+func TestWebURLRejectsRemotesWithoutAWebPage(t *testing.T) {
+	for _, remote := range []string{
+		"",
+		"/srv/git/repo.git",
+		"../repo.git",
+		`C:\src\repo`,
+		"file:///srv/git/repo.git",
+		"git@github.com:",
+		"https://github.com/",
+		"https://github.com/%zz",
+	} {
+		if got, err := gitx.WebURL(remote); err == nil {
+			t.Fatalf("WebURL(%q) = %q, expected an error", remote, got)
+		}
+	}
+}
+
+func TestWebURLUsesHostAliases(t *testing.T) {
+	gitx.SetHostAliases(map[string]string{"github-work": "github.com"})
+	defer gitx.SetHostAliases(nil)
+
+	got, err := gitx.WebURL("git@github-work:org/repo.git")
+	if err != nil {
+		t.Fatalf("WebURL: %v", err)
+	}
+	if got != "https://github.com/org/repo" {
+		t.Fatalf("expected the alias resolved, got %q", got)
+	}
+}
@@ -4,9 +4,14 @@ package gitx_test
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/gitx"
+	"github.com/skaphos/repokeeper/internal/model"
 )
 
 func TestPushWrapper(t *testing.T) {
@@ -121,6 +126,78 @@ func TestSetRemoteURLRejectsFlagLikeArgs(t *testing.T) {
 	}
 }
 
+func TestAddRemoteWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:remote add repokeeper-upstream git@github.com:org/repo.git": {Output: ""},
+	}}
+	if err := gitx.AddRemote(context.Background(), mock, "/repo", "repokeeper-upstream", "git@github.com:org/repo.git"); err != nil {
+		t.Fatalf("expected add remote success, got %v", err)
+	}
+	if err := gitx.AddRemote(context.Background(), mock, "/repo", "-f", "git@github.com:org/repo.git"); err == nil {
+		t.Fatal("expected error for flag-like remote name")
+	}
+	if err := gitx.AddRemote(context.Background(), mock, "/repo", "upstream", "--upload-pack=evil"); err == nil {
+		t.Fatal("expected error for flag-like remote URL")
+	}
+}
+
+func TestLFSWrappers(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/lfs:grep -q --fixed-strings filter=lfs -- .gitattributes */.gitattributes":    {Output: ""},
+		"/plain:grep -q --fixed-strings filter=lfs -- .gitattributes */.gitattributes":  {Err: MockExitError(1)},
+		"/bare:grep -q --fixed-strings filter=lfs -- .gitattributes */.gitattributes":   {Err: MockExitError(128)},
+		"/bare:rev-parse --is-bare-repository":                                          {Output: "true"},
+		"/broken:grep -q --fixed-strings filter=lfs -- .gitattributes */.gitattributes": {Err: MockExitError(128)},
+		"/broken:rev-parse --is-bare-repository":                                        {Output: "false"},
+		"/noexec:grep -q --fixed-strings filter=lfs -- .gitattributes */.gitattributes": {Err: errors.New("exec: \"git\": executable file not found in $PATH")},
+		"/lfs:lfs fetch":   {Output: ""},
+		"/plain:lfs fetch": {Err: errors.New("git: 'lfs' is not a git command")},
+	}}
+	if has, err := gitx.HasLFS(context.Background(), mock, "/lfs"); err != nil || !has {
+		t.Fatalf("expected LFS detected, got %v, %v", has, err)
+	}
+	if has, err := gitx.HasLFS(context.Background(), mock, "/plain"); err != nil || has {
+		t.Fatalf("expected no LFS, got %v, %v", has, err)
+	}
+	if has, err := gitx.HasLFS(context.Background(), mock, "/bare"); err != nil || has {
+		t.Fatalf("expected no LFS for a bare repo, got %v, %v", has, err)
+	}
+	for _, dir := range []string{"/broken", "/noexec"} {
+		if has, err := gitx.HasLFS(context.Background(), mock, dir); err == nil || has {
+			t.Fatalf("expected %s grep failure reported, got %v, %v", dir, has, err)
+		}
+	}
+	if err := gitx.LFSFetch(context.Background(), mock, "/lfs"); err != nil {
+		t.Fatalf("expected lfs fetch success, got %v", err)
+	}
+	if err := gitx.LFSFetch(context.Background(), mock, "/plain"); err == nil {
+		t.Fatal("expected lfs fetch failure")
+	}
+}
+
+func TestRecentCommitsWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:log -n 2 --format=%H%x1f%an%x1f%aI%x1f%s":  {Output: "aaa\x1fJane Doe\x1f2026-10-01T12:00:00+02:00\x1ffix: handle a|b\nbbb\x1fJohn\x1f2026-09-30T08:00:00Z\x1finit\n"},
+		"/empty:log -n 2 --format=%H%x1f%an%x1f%aI%x1f%s": {Err: errors.New("fatal: your current branch 'main' does not have any commits yet")},
+	}}
+	commits, err := gitx.RecentCommits(context.Background(), mock, "/repo", 2)
+	if err != nil {
+		t.Fatalf("recent commits: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Hash != "aaa" || commits[0].Author != "Jane Doe" || commits[0].Subject != "fix: handle a|b" {
+		t.Fatalf("unexpected commits: %+v", commits)
+	}
+	if want := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC); !commits[0].Date.Equal(want) {
+		t.Fatalf("expected date %v, got %v", want, commits[0].Date)
+	}
+	if _, err := gitx.RecentCommits(context.Background(), mock, "/empty", 2); err == nil {
+		t.Fatal("expected an error for a repo without commits")
+	}
+	if commits, err := gitx.RecentCommits(context.Background(), mock, "/repo", 0); err != nil || commits != nil {
+		t.Fatalf("expected no commits for n=0, got %v, %v", commits, err)
+	}
+}
+
 func TestStashPopWrapper(t *testing.T) {
 	mock := &MockRunner{Responses: map[string]MockResponse{
 		"/repo:stash pop": {Output: ""},
@@ -137,6 +214,246 @@ func TestStashPopWrapper(t *testing.T) {
 	}
 }
 
+func TestCreateBranchWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:branch --no-track backup/main HEAD": {Output: ""},
+	}}
+	if err := gitx.CreateBranch(context.Background(), mock, "/repo", "backup/main"); err != nil {
+		t.Fatalf("expected create branch success, got %v", err)
+	}
+	if err := gitx.CreateBranch(context.Background(), mock, "/repo", "--force"); err == nil {
+		t.Fatal("expected error for flag-like branch name")
+	}
+}
+
+func TestCommitsBehindWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:rev-list --count refs/heads/feature..origin/main": {Output: "7\n"},
+	}}
+	behind, err := gitx.CommitsBehind(context.Background(), mock, "/repo", "origin/main", "feature")
+	if err != nil || behind != 7 {
+		t.Fatalf("expected 7 commits behind, got %d (%v)", behind, err)
+	}
+	if _, err := gitx.CommitsBehind(context.Background(), mock, "/repo", "", "feature"); err == nil {
+		t.Fatal("expected error without a base ref")
+	}
+}
+
+func TestStashListWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:stash list": {Output: "stash@{0}: WIP on main: abc123 wip\nstash@{1}: On main: spike\n"},
+	}}
+	count, err := gitx.StashList(context.Background(), mock, "/repo")
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 stashes, got %d (%v)", count, err)
+	}
+
+	mock = &MockRunner{Responses: map[string]MockResponse{
+		"/repo:stash list": {Output: ""},
+	}}
+	if count, err := gitx.StashList(context.Background(), mock, "/repo"); err != nil || count != 0 {
+		t.Fatalf("expected no stashes, got %d (%v)", count, err)
+	}
+
+	mock = &MockRunner{Responses: map[string]MockResponse{
+		"/repo:stash list": {Err: errors.New("not a git repository")},
+	}}
+	if _, err := gitx.StashList(context.Background(), mock, "/repo"); err == nil {
+		t.Fatal("expected stash list failure")
+	}
+}
+
+func TestShallowWrappers(t *testing.T) {
+	base := t.TempDir()
+	mainGitDir := filepath.Join(base, "repo", ".git")
+	linkedGitDir := filepath.Join(mainGitDir, "worktrees", "linked")
+	linked := filepath.Join(base, "linked")
+	for _, dir := range []string{linkedGitDir, linked} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(linkedGitDir, "commondir"), []byte("../..\n"), 0o644); err != nil {
+		t.Fatalf("write commondir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+linkedGitDir+"\n"), 0o644); err != nil {
+		t.Fatalf("write .git file: %v", err)
+	}
+
+	if shallow, err := gitx.IsShallow(filepath.Join(base, "repo")); err != nil || shallow {
+		t.Fatalf("expected full clone, got %v (%v)", shallow, err)
+	}
+	if err := os.WriteFile(filepath.Join(mainGitDir, "shallow"), []byte("0123456789abcdef0123456789abcdef01234567\n"), 0o644); err != nil {
+		t.Fatalf("write shallow: %v", err)
+	}
+	for _, dir := range []string{filepath.Join(base, "repo"), linked} {
+		if shallow, err := gitx.IsShallow(dir); err != nil || !shallow {
+			t.Fatalf("expected %s to be shallow, got %v (%v)", dir, shallow, err)
+		}
+	}
+	if _, err := gitx.IsShallow(filepath.Join(base, "missing")); err == nil {
+		t.Fatal("expected a directory without a git dir to fail")
+	}
+}
+
+func TestRemoteUpdateWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/mirror:remote update --prune": {},
+		"/broken:remote update --prune": {Output: "fatal: unable to access", Err: errors.New("exit status 128")},
+	}}
+	if err := gitx.RemoteUpdate(context.Background(), mock, "/mirror"); err != nil {
+		t.Fatalf("expected remote update success, got %v", err)
+	}
+	if err := gitx.RemoteUpdate(context.Background(), mock, "/broken"); err == nil || !strings.Contains(err.Error(), "remote update") {
+		t.Fatalf("expected remote update failure, got %v", err)
+	}
+}
+
+func TestSubmoduleUpdateWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:submodule update --init --recursive":   {},
+		"/broken:submodule update --init --recursive": {Output: "fatal: could not read from remote repository", Err: errors.New("exit status 1")},
+	}}
+	if err := gitx.SubmoduleUpdate(context.Background(), mock, "/repo"); err != nil {
+		t.Fatalf("expected submodule update success, got %v", err)
+	}
+	if err := gitx.SubmoduleUpdate(context.Background(), mock, "/broken"); err == nil || !strings.Contains(err.Error(), "submodule update") {
+		t.Fatalf("expected submodule update failure, got %v", err)
+	}
+}
+
+func TestWorktreeRoleWrapper(t *testing.T) {
+	base := t.TempDir()
+	mainGitDir := filepath.Join(base, "repo", ".git")
+	linkedGitDir := filepath.Join(mainGitDir, "worktrees", "linked")
+	linked := filepath.Join(base, "linked")
+	for _, dir := range []string{linkedGitDir, linked} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(linkedGitDir, "commondir"), []byte("../..\n"), 0o644); err != nil {
+		t.Fatalf("write commondir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(linked, ".git"), []byte("gitdir: "+linkedGitDir+"\n"), 0o644); err != nil {
+		t.Fatalf("write .git file: %v", err)
+	}
+
+	role, commonDir, err := gitx.WorktreeRole(filepath.Join(base, "repo"))
+	if err != nil || role != model.WorktreeRoleMain || commonDir != mainGitDir {
+		t.Fatalf("expected main worktree, got %q %q (%v)", role, commonDir, err)
+	}
+	role, commonDir, err = gitx.WorktreeRole(linked)
+	if err != nil || role != model.WorktreeRoleLinked || commonDir != mainGitDir {
+		t.Fatalf("expected linked worktree, got %q %q (%v)", role, commonDir, err)
+	}
+	if _, _, err := gitx.WorktreeRole(filepath.Join(base, "missing")); err == nil {
+		t.Fatal("expected a directory without a git dir to fail")
+	}
+}
+
+func TestFetchWithOptionsSelectsRemoteAndDepth(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:-c fetch.recurseSubmodules=false fetch --all --prune --no-recurse-submodules --deepen 5":   {},
+		"/repo:-c fetch.recurseSubmodules=false fetch origin --prune --no-recurse-submodules":             {},
+		"/repo:-c fetch.recurseSubmodules=false fetch origin --prune --no-recurse-submodules --deepen 3":  {},
+		"/repo:-c fetch.recurseSubmodules=false fetch --all --prune --prune-tags --no-recurse-submodules": {},
+		"/repo:for-each-ref --format=%(refname) refs/tags":                                                {},
+	}}
+	for _, opts := range []gitx.FetchOptions{
+		{},
+		{Depth: 5, NoPruneTags: true},
+		{Remote: "origin", NoPruneTags: true},
+		{Remote: "origin", Depth: 3, NoPruneTags: true},
+	} {
+		if _, err := gitx.FetchWithOptions(context.Background(), mock, "/repo", opts); err != nil {
+			t.Fatalf("expected fetch %+v to succeed, got %v", opts, err)
+		}
+	}
+	for _, remote := range []string{" ", "--upload-pack=evil"} {
+		if _, err := gitx.FetchWithOptions(context.Background(), mock, "/repo", gitx.FetchOptions{Remote: remote}); err == nil {
+			t.Fatalf("expected remote %q to be rejected", remote)
+		}
+	}
+}
+
+// tagSnapshotRunner serves for-each-ref listings from before on the first call
+// and after on later ones, accepting any fetch.
+type tagSnapshotRunner struct {
+	before, after string
+	listings      int
+	fetches       []string
+}
+
+func (r *tagSnapshotRunner) Run(_ context.Context, _ string, args ...string) (string, error) {
+	if args[0] == "for-each-ref" {
+		r.listings++
+		if r.listings == 1 {
+			return r.before, nil
+		}
+		return r.after, nil
+	}
+	r.fetches = append(r.fetches, strings.Join(args, " "))
+	return "", nil
+}
+
+func TestFetchWithOptionsCountsPrunedTags(t *testing.T) {
+	runner := &tagSnapshotRunner{before: "refs/tags/v1\nrefs/tags/v2\nrefs/tags/local", after: "refs/tags/v2\nrefs/tags/v3"}
+	res, err := gitx.FetchWithOptions(context.Background(), runner, "/repo", gitx.FetchOptions{Remote: "origin", Depth: 2})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if res.TagsPruned != 2 {
+		t.Fatalf("expected 2 pruned tags, got %d", res.TagsPruned)
+	}
+	want := "-c fetch.recurseSubmodules=false fetch origin --prune --prune-tags --no-recurse-submodules --deepen 2"
+	if len(runner.fetches) != 1 || runner.fetches[0] != want {
+		t.Fatalf("unexpected fetch args %q", runner.fetches)
+	}
+
+	runner = &tagSnapshotRunner{before: "refs/tags/v1", after: ""}
+	res, err = gitx.FetchWithOptions(context.Background(), runner, "/repo", gitx.FetchOptions{NoPruneTags: true})
+	if err != nil || res.TagsPruned != 0 || runner.listings != 0 {
+		t.Fatalf("expected no tag listing without pruning, got %+v (%v), %d listings", res, err, runner.listings)
+	}
+	if runner.fetches[0] != "-c fetch.recurseSubmodules=false fetch --all --prune --no-recurse-submodules" {
+		t.Fatalf("unexpected fetch args %q", runner.fetches[0])
+	}
+	if got := gitx.FetchCommand("", true); got != "git fetch --all --prune --prune-tags --no-recurse-submodules" {
+		t.Fatalf("unexpected default fetch command %q", got)
+	}
+	if _, err := gitx.FetchWithOptions(context.Background(), runner, "/repo", gitx.FetchOptions{Remote: "--upload-pack=evil"}); err == nil {
+		t.Fatal("expected an option-like remote name to be rejected")
+	}
+}
+
+func TestLastCommitTimeWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:log -1 --format=%cI": {Output: "2026-03-04T05:06:07+02:00\n"},
+	}}
+	at, err := gitx.LastCommitTime(context.Background(), mock, "/repo")
+	if err != nil {
+		t.Fatalf("expected last commit success, got %v", err)
+	}
+	if want := time.Date(2026, 3, 4, 3, 6, 7, 0, time.UTC); !at.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, at)
+	}
+
+	mock = &MockRunner{Responses: map[string]MockResponse{
+		"/repo:log -1 --format=%cI": {Err: errors.New("does not have any commits yet")},
+	}}
+	if _, err := gitx.LastCommitTime(context.Background(), mock, "/repo"); err == nil {
+		t.Fatal("expected failure for repo without commits")
+	}
+
+	mock = &MockRunner{Responses: map[string]MockResponse{
+		"/repo:log -1 --format=%cI": {Output: "yesterday"},
+	}}
+	if _, err := gitx.LastCommitTime(context.Background(), mock, "/repo"); err == nil {
+		t.Fatal("expected parse failure")
+	}
+}
+
 func TestCloneWrapper(t *testing.T) {
 	mock := &MockRunner{Responses: map[string]MockResponse{
 		":clone --mirror git@github.com:org/repo.git /target": {Output: ""},
@@ -160,6 +477,26 @@ func TestCloneWrapper(t *testing.T) {
 	}
 }
 
+func TestCloneShallowWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		":clone --depth 1 --branch main --single-branch git@github.com:org/repo.git /target": {Output: ""},
+	}}
+	if err := gitx.CloneShallow(context.Background(), mock, "git@github.com:org/repo.git", "/target", "main", 1); err != nil {
+		t.Fatalf("expected shallow branch clone success, got %v", err)
+	}
+
+	mock = &MockRunner{Responses: map[string]MockResponse{
+		":clone --depth 5 --single-branch git@github.com:org/repo.git /target": {Output: ""},
+	}}
+	if err := gitx.CloneShallow(context.Background(), mock, "git@github.com:org/repo.git", "/target", "", 5); err != nil {
+		t.Fatalf("expected shallow default-branch clone success, got %v", err)
+	}
+
+	if err := gitx.CloneShallow(context.Background(), mock, "git@github.com:org/repo.git", "/target", "main", 0); err == nil {
+		t.Fatal("expected error for non-positive depth")
+	}
+}
+
 func TestCloneRejectsFlagLikeArgs(t *testing.T) {
 	// A remote URL, target path, or branch beginning with "-" must be
 	// rejected before it reaches git, where it would otherwise be parsed
@@ -200,3 +537,75 @@ func TestCloneDoesNotTrimURLOrPath(t *testing.T) {
 		t.Fatalf("target path passed to git = %q, want verbatim %q", gotPath, spacedPath)
 	}
 }
+
+func TestRemoteDefaultBranchWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:symbolic-ref --quiet --short refs/remotes/origin/HEAD":   {Output: "origin/develop\n"},
+		"/unset:symbolic-ref --quiet --short refs/remotes/origin/HEAD":  {Err: errors.New("exit status 1")},
+		"/nested:symbolic-ref --quiet --short refs/remotes/origin/HEAD": {Output: "origin/release/2.x\n"},
+	}}
+	branch, err := gitx.RemoteDefaultBranch(context.Background(), mock, "/repo", "origin")
+	if err != nil || branch != "develop" {
+		t.Fatalf("expected develop, got %q (err %v)", branch, err)
+	}
+	branch, err = gitx.RemoteDefaultBranch(context.Background(), mock, "/nested", "origin")
+	if err != nil || branch != "release/2.x" {
+		t.Fatalf("expected release/2.x, got %q (err %v)", branch, err)
+	}
+	if _, err := gitx.RemoteDefaultBranch(context.Background(), mock, "/unset", "origin"); err == nil {
+		t.Fatal("expected an error when the remote HEAD symref is not set")
+	}
+}
+
+func TestRemoteHeadBranchWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:ls-remote --symref origin HEAD":    {Output: "ref: refs/heads/main\tHEAD\n0123456789abcdef0123456789abcdef01234567\tHEAD\n"},
+		"/nested:ls-remote --symref origin HEAD":  {Output: "ref: refs/heads/release/2.x\tHEAD\n"},
+		"/nosym:ls-remote --symref origin HEAD":   {Output: "0123456789abcdef0123456789abcdef01234567\tHEAD\n"},
+		"/offline:ls-remote --symref origin HEAD": {Err: errors.New("exit status 128")},
+	}}
+	branch, err := gitx.RemoteHeadBranch(context.Background(), mock, "/repo", "origin")
+	if err != nil || branch != "main" {
+		t.Fatalf("expected main, got %q (err %v)", branch, err)
+	}
+	branch, err = gitx.RemoteHeadBranch(context.Background(), mock, "/nested", "origin")
+	if err != nil || branch != "release/2.x" {
+		t.Fatalf("expected release/2.x, got %q (err %v)", branch, err)
+	}
+	if _, err := gitx.RemoteHeadBranch(context.Background(), mock, "/nosym", "origin"); err == nil {
+		t.Fatal("expected an error when the remote reports no HEAD symref")
+	}
+	if _, err := gitx.RemoteHeadBranch(context.Background(), mock, "/offline", "origin"); err == nil {
+		t.Fatal("expected an error when the remote is unreachable")
+	}
+}
+
+func TestUpdateRemoteHeadWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		"/repo:remote set-head origin --auto":    {Output: "origin/HEAD set to main\n"},
+		"/offline:remote set-head origin --auto": {Output: "fatal: unable to access remote", Err: errors.New("exit status 128")},
+	}}
+	if err := gitx.UpdateRemoteHead(context.Background(), mock, "/repo", "origin"); err != nil {
+		t.Fatalf("expected set-head to succeed, got %v", err)
+	}
+	if err := gitx.UpdateRemoteHead(context.Background(), mock, "/offline", "origin"); err == nil || !strings.Contains(err.Error(), "git remote set-head origin --auto") {
+		t.Fatalf("expected wrapped run error, got %v", err)
+	}
+}
+
+func TestVersionWrapper(t *testing.T) {
+	mock := &MockRunner{Responses: map[string]MockResponse{
+		":--version": {Output: "git version 2.43.0"},
+	}}
+	if version, err := gitx.Version(context.Background(), mock); err != nil || version != "2.43.0" {
+		t.Fatalf("expected 2.43.0, got %q, %v", version, err)
+	}
+	mock.Responses[":--version"] = MockResponse{Output: "not git"}
+	if _, err := gitx.Version(context.Background(), mock); err == nil {
+		t.Fatal("expected unexpected output to fail")
+	}
+	mock.Responses[":--version"] = MockResponse{Err: errors.New(`exec: "git": executable file not found in $PATH`)}
+	if _, err := gitx.Version(context.Background(), mock); err == nil || !strings.Contains(err.Error(), "git --version") {
+		t.Fatalf("expected wrapped run error, got %v", err)
+	}
+}
@@ -173,7 +173,7 @@ func buildWorkspaceInventoryTool() mcp.Tool {
 			ReadOnlyHint: boolPtr(true),
 		}),
 		mcp.WithString("filter",
-			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing (default: all)"),
+			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata, stale-upstream, branches-behind-default (default: all)"),
 		),
 		mcp.WithString("label_selector",
 			mcp.Description("Label filter (e.g. team=platform,role=service)"),
@@ -266,7 +266,7 @@ func planSyncTool() mcp.Tool {
 			ReadOnlyHint: boolPtr(true),
 		}),
 		mcp.WithString("filter",
-			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing"),
+			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata"),
 		),
 		mcp.WithString("label_selector",
 			mcp.Description("Label filter"),
@@ -288,7 +288,7 @@ func executeSyncTool() mcp.Tool {
 			DestructiveHint: boolPtr(true),
 		}),
 		mcp.WithString("filter",
-			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing"),
+			mcp.Description("Health filter: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata"),
 		),
 		mcp.WithString("label_selector",
 			mcp.Description("Label filter"),
@@ -45,7 +45,7 @@ func (s *MCPServer) handleScanWorkspace(ctx context.Context, req mcp.CallToolReq
 
 	scanRoots := rootsRaw
 	if len(scanRoots) == 0 {
-		scanRoots = []string{config.EffectiveRoot(s.cfgPath)}
+		scanRoots = config.DefaultScanRoots(cfg, s.cfgPath)
 	}
 
 	reg := s.engine.Registry()
@@ -55,8 +55,9 @@ func (s *MCPServer) handleScanWorkspace(ctx context.Context, req mcp.CallToolReq
 	}
 
 	statuses, err := s.engine.Scan(ctx, engine.ScanOptions{
-		Roots:   scanRoots,
-		Exclude: cfg.Exclude,
+		Roots:       scanRoots,
+		Exclude:     cfg.Exclude,
+		RootExclude: config.RootExcludes(cfg, s.cfgPath),
 	})
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -373,12 +374,13 @@ var validSyncFilters = map[string]struct{}{
 	"equal":           {},
 	"remote-mismatch": {},
 	"missing":         {},
+	"stale-metadata":  {},
 }
 
 func parseSyncOptions(req mcp.CallToolRequest) (engine.SyncOptions, error) {
 	filterRaw := strings.ToLower(strings.TrimSpace(req.GetString("filter", "all")))
 	if _, ok := validSyncFilters[filterRaw]; !ok {
-		return engine.SyncOptions{}, fmt.Errorf("invalid filter %q: must be one of all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing", filterRaw)
+		return engine.SyncOptions{}, fmt.Errorf("invalid filter %q: must be one of all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata", filterRaw)
 	}
 	return engine.SyncOptions{
 		Filter:      engine.FilterKind(filterRaw),
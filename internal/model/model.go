@@ -58,6 +58,13 @@ type Tracking struct {
 	Ahead *int `json:"ahead" yaml:"ahead"` // nil when gone or none
 	// Behind is the number of commits local is behind upstream. Nil when unknown/not applicable.
 	Behind *int `json:"behind" yaml:"behind"` // nil when gone or none
+	// StaleUpstream is set when the branch tracks a mainline branch (such as
+	// master) that still exists, but the remote's HEAD now points at another
+	// branch, as after a host renames master to main.
+	StaleUpstream bool `json:"stale_upstream,omitempty" yaml:"stale_upstream,omitempty"`
+	// RemoteDefault is the branch the upstream remote's HEAD points at. It is
+	// only set when StaleUpstream is.
+	RemoteDefault string `json:"remote_default,omitempty" yaml:"remote_default,omitempty"`
 }
 
 // Submodules indicates whether the repo contains submodules.
@@ -76,6 +83,14 @@ type RemoteTrackingRefStatus struct {
 	InspectionError string   `json:"inspection_error,omitempty" yaml:"inspection_error,omitempty"`
 }
 
+// IgnoredFileStatus lists worktree paths matched by ignore rules. It is only
+// populated when a caller opts in, because walking ignored trees can be slow.
+type IgnoredFileStatus struct {
+	Count           int      `json:"count" yaml:"count"`
+	Paths           []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	InspectionError string   `json:"inspection_error,omitempty" yaml:"inspection_error,omitempty"`
+}
+
 // PruneCategory classifies a local branch by how safe it is to prune. Only
 // PruneSafeToPrune is eligible for automated/batch prune; PruneProbablySafe
 // carries positive evidence but is review-required and never auto-pruned.
@@ -163,6 +178,9 @@ type LocalBranch struct {
 	MergedIntoBase *bool `json:"merged_into_base" yaml:"merged_into_base"`
 	// PatchEquivalentToBase reports patch-equivalence to base (squash/rebase merges). Nil when unavailable.
 	PatchEquivalentToBase *bool `json:"patch_equivalent_to_base" yaml:"patch_equivalent_to_base"`
+	// BehindBase is the commit count behind the base branch. Nil unless
+	// behind-base counting was requested, or when it could not be computed.
+	BehindBase *int `json:"behind_base,omitempty" yaml:"behind_base,omitempty"`
 	// LastCommitAt is the branch tip committer date. Nil when unavailable.
 	LastCommitAt *time.Time `json:"last_commit_at" yaml:"last_commit_at"`
 	// Category is the computed prune-safety classification.
@@ -177,6 +195,52 @@ type LocalBranch struct {
 type LocalBranchStatus struct {
 	Branches        []LocalBranch `json:"branches,omitempty" yaml:"branches,omitempty"`
 	InspectionError string        `json:"inspection_error,omitempty" yaml:"inspection_error,omitempty"`
+	// BehindBaseCount is the number of unmerged branches at least the requested
+	// threshold behind the base branch. Zero unless counting was requested.
+	BehindBaseCount int `json:"behind_base_count,omitempty" yaml:"behind_base_count,omitempty"`
+}
+
+// BranchTracking is a local branch and the state of its upstream.
+type BranchTracking struct {
+	// Name is the short branch name.
+	Name string `json:"name" yaml:"name"`
+	// Upstream is the configured upstream ref (for example, "origin/feature/x").
+	Upstream string `json:"upstream,omitempty" yaml:"upstream,omitempty"`
+	// Status is the branch's relationship to its upstream.
+	Status TrackingStatus `json:"status" yaml:"status"`
+}
+
+// GoneBranchesRepo lists one repository's local branches whose upstream was
+// deleted. Error is set instead when the branches could not be listed.
+type GoneBranchesRepo struct {
+	RepoID   string           `json:"repo_id" yaml:"repo_id"`
+	Path     string           `json:"path" yaml:"path"`
+	Branches []BranchTracking `json:"branches" yaml:"branches"`
+	Error    string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// GoneBranchesReport is the result of `report gone-branches`: only repos with
+// at least one gone branch, or an error, are listed.
+type GoneBranchesReport struct {
+	GeneratedAt time.Time          `json:"generated_at" yaml:"generated_at"`
+	Repos       []GoneBranchesRepo `json:"repos" yaml:"repos"`
+}
+
+// FsckRepo lists the integrity problems `git fsck` found in one repository.
+// Error is set instead when the check could not run.
+type FsckRepo struct {
+	RepoID string   `json:"repo_id" yaml:"repo_id"`
+	Path   string   `json:"path" yaml:"path"`
+	Issues []string `json:"issues" yaml:"issues"`
+	Error  string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// FsckReport is the result of `fsck`: only repos with at least one issue, or
+// an error, are listed. Checked counts every repo that was examined.
+type FsckReport struct {
+	GeneratedAt time.Time  `json:"generated_at" yaml:"generated_at"`
+	Checked     int        `json:"checked" yaml:"checked"`
+	Repos       []FsckRepo `json:"repos" yaml:"repos"`
 }
 
 // SyncResult records the outcome of the last sync operation.
@@ -189,6 +253,34 @@ type SyncResult struct {
 	Error string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
+// Commit is one entry from a repository's recent history.
+type Commit struct {
+	// Hash is the full commit hash.
+	Hash string `json:"hash" yaml:"hash"`
+	// Author is the commit author's name.
+	Author string `json:"author" yaml:"author"`
+	// Date is the author date.
+	Date time.Time `json:"date" yaml:"date"`
+	// Subject is the first line of the commit message.
+	Subject string `json:"subject" yaml:"subject"`
+}
+
+// Worktree roles reported in RepoStatus.WorktreeRole.
+const (
+	// WorktreeRoleMain is the checkout that owns the repository's .git dir.
+	WorktreeRoleMain = "main"
+	// WorktreeRoleLinked is a checkout added with `git worktree add` that
+	// shares the main worktree's git dir.
+	WorktreeRoleLinked = "linked"
+)
+
+// Interrupted operations reported in RepoStatus.InProgress.
+const (
+	InProgressRebase = "rebase"
+	InProgressMerge  = "merge"
+	InProgressBisect = "bisect"
+)
+
 // RepoMetadataPaths groups path hints declared by a repository.
 type RepoMetadataPaths struct {
 	// Authoritative highlights the paths most worth consulting first.
@@ -251,6 +343,8 @@ type RepoStatus struct {
 	RepoMetadata *RepoMetadata `json:"repo_metadata,omitempty" yaml:"repo_metadata,omitempty"`
 	// Bare indicates whether the repository has no working tree.
 	Bare bool `json:"bare" yaml:"bare"`
+	// Shallow indicates the repository was cloned with truncated history.
+	Shallow bool `json:"shallow" yaml:"shallow"`
 	// Remotes contains all configured remotes.
 	Remotes []Remote `json:"remotes" yaml:"remotes"`
 	// PrimaryRemote is the preferred remote name used for identity and sync behavior.
@@ -259,14 +353,35 @@ type RepoStatus struct {
 	Head Head `json:"head" yaml:"head"`
 	// Worktree is nil for bare repositories.
 	Worktree *Worktree `json:"worktree" yaml:"worktree"` // nil for bare repos
+	// WorktreeRole is WorktreeRoleMain or WorktreeRoleLinked; empty for bare
+	// repos and backends without worktrees. Linked worktrees share the main
+	// worktree's repo ID, so they show up as extra checkouts of it.
+	WorktreeRole string `json:"worktree_role,omitempty" yaml:"worktree_role,omitempty"`
+	// GitCommonDir is the git dir shared by every worktree of the repository.
+	GitCommonDir string `json:"git_common_dir,omitempty" yaml:"git_common_dir,omitempty"`
+	// InProgress names an interrupted rebase, merge, or bisect (one of the
+	// InProgress constants); empty when none is under way.
+	InProgress string `json:"in_progress,omitempty" yaml:"in_progress,omitempty"`
+	// Archived mirrors the registry entry's archived flag.
+	Archived bool `json:"archived,omitempty" yaml:"archived,omitempty"`
 	// Tracking describes upstream tracking status for the current branch.
 	Tracking Tracking `json:"tracking" yaml:"tracking"`
 	// Submodules indicates whether the repository contains submodules.
 	Submodules Submodules `json:"submodules" yaml:"submodules"`
+	// HasLFS indicates .gitattributes routes paths through Git LFS. It is only
+	// probed by sync --lfs and is false otherwise.
+	HasLFS bool `json:"has_lfs,omitempty" yaml:"has_lfs,omitempty"`
+	// StashCount is the number of stash entries; always zero for bare repos.
+	StashCount int `json:"stash_count" yaml:"stash_count"`
+	// LastCommit is the committer date of HEAD; zero for bare repos and repos
+	// without commits.
+	LastCommit time.Time `json:"last_commit,omitzero" yaml:"last_commit,omitempty"`
 	// RemoteTrackingRefs describes refs that a fetch with prune would remove.
 	RemoteTrackingRefs RemoteTrackingRefStatus `json:"remote_tracking_refs" yaml:"remote_tracking_refs"`
 	// LocalBranches describes local branches classified by prune safety.
 	LocalBranches LocalBranchStatus `json:"local_branches" yaml:"local_branches"`
+	// Ignored lists ignored worktree paths; nil unless ignored files were verified.
+	Ignored *IgnoredFileStatus `json:"ignored,omitempty" yaml:"ignored,omitempty"`
 	// LastSync is the latest sync outcome metadata when available.
 	LastSync *SyncResult `json:"last_sync,omitempty" yaml:"last_sync,omitempty"`
 	// Error holds repository-specific inspect or sync error text.
@@ -281,4 +396,7 @@ type StatusReport struct {
 	GeneratedAt time.Time `json:"generated_at" yaml:"generated_at"`
 	// Repos is the full set of repository status rows in the report.
 	Repos []RepoStatus `json:"repos" yaml:"repos"`
+	// Stopped is set when the run ended early (status --fail-fast); Repos then
+	// covers only the repos inspected before it stopped.
+	Stopped bool `json:"stopped,omitempty" yaml:"stopped,omitempty"`
 }
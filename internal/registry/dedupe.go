@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: MIT
+package registry
+
+import "sort"
+
+// DedupeConflict is a label or annotation key that two entries merged by
+// Dedupe set to different values. Kept is the value the merged entry ends
+// up with; Dropped is the value it replaced.
+type DedupeConflict struct {
+	RepoID  string `json:"repo_id"`
+	Field   string `json:"field"` // labels | annotations
+	Key     string `json:"key"`
+	Kept    string `json:"kept"`
+	Dropped string `json:"dropped"`
+}
+
+// Dedupe collapses entries that share a RepoID. Present entries are distinct
+// checkouts and are all kept; every other entry in the group is dropped and
+// its labels and annotations are merged into the best remaining entry: the
+// most recently seen present one, else the most recently seen moved one, else
+// the most recently seen of the rest, with ties going to the earlier entry.
+// Values are merged in registry order, so later entries win a key, and each
+// overridden value is reported as a conflict. Entries keep their relative
+// order and the input is not modified.
+//
+// Dedupe does not touch the filesystem; callers that want on-disk validity to
+// decide the winner should refresh Status first.
+func Dedupe(entries []Entry) ([]Entry, []DedupeConflict) {
+	groups := make(map[string][]int, len(entries))
+	for i, entry := range entries {
+		groups[entry.RepoID] = append(groups[entry.RepoID], i)
+	}
+
+	drop := make(map[int]bool)
+	merged := make(map[int]Entry)
+	var conflicts []DedupeConflict
+	for i, entry := range entries {
+		members := groups[entry.RepoID]
+		if len(members) < 2 || members[0] != i {
+			continue
+		}
+		keep := dedupeKeeper(entries, members)
+		target := entries[keep]
+		target.Labels, target.Annotations = nil, nil
+		dropped := false
+		for _, idx := range members {
+			if idx != keep && entries[idx].Status == StatusPresent {
+				continue
+			}
+			if idx != keep {
+				drop[idx] = true
+				dropped = true
+			}
+			var labelConflicts, annotationConflicts []DedupeConflict
+			target.Labels, labelConflicts = mergeDedupeValues(entry.RepoID, "labels", target.Labels, entries[idx].Labels)
+			target.Annotations, annotationConflicts = mergeDedupeValues(entry.RepoID, "annotations", target.Annotations, entries[idx].Annotations)
+			conflicts = append(conflicts, labelConflicts...)
+			conflicts = append(conflicts, annotationConflicts...)
+		}
+		if dropped {
+			merged[keep] = target
+		}
+	}
+
+	out := make([]Entry, 0, len(entries)-len(drop))
+	for i, entry := range entries {
+		if drop[i] {
+			continue
+		}
+		if target, ok := merged[i]; ok {
+			entry = target
+		}
+		out = append(out, entry)
+	}
+	return out, conflicts
+}
+
+// dedupeKeeper picks the entry of a RepoID group that survives a merge.
+func dedupeKeeper(entries []Entry, members []int) int {
+	best := members[0]
+	for _, idx := range members[1:] {
+		rank, bestRank := dedupeRank(entries[idx]), dedupeRank(entries[best])
+		// Status decides first; within a status the entry seen most recently
+		// wins, and a tie keeps the earlier entry.
+		if rank < bestRank || (rank == bestRank && entries[idx].LastSeen.After(entries[best].LastSeen)) {
+			best = idx
+		}
+	}
+	return best
+}
+
+func dedupeRank(entry Entry) int {
+	switch entry.Status {
+	case StatusPresent:
+		return 0
+	case StatusMoved:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// MergeMetadata returns local with the labels and annotations of incoming
+// merged in key by key. Every other field keeps its local value. Incoming
+// wins a key that both set differently, and each such key is reported as a
+// conflict with Kept set to the incoming value. local's maps are not modified.
+func MergeMetadata(local, incoming Entry) (Entry, []DedupeConflict) {
+	merged := local
+	var labelConflicts, annotationConflicts []DedupeConflict
+	merged.Labels, labelConflicts = mergeDedupeValues(local.RepoID, "labels", cloneStringMap(local.Labels), incoming.Labels)
+	merged.Annotations, annotationConflicts = mergeDedupeValues(local.RepoID, "annotations", cloneStringMap(local.Annotations), incoming.Annotations)
+	return merged, append(labelConflicts, annotationConflicts...)
+}
+
+// mergeDedupeValues overlays src onto dst, reporting every key whose value
+// changed as a conflict.
+func mergeDedupeValues(repoID, field string, dst, src map[string]string) (map[string]string, []DedupeConflict) {
+	if len(src) == 0 {
+		return dst, nil
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	var conflicts []DedupeConflict
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := src[key]
+		if existing, ok := dst[key]; ok && existing != value {
+			conflicts = append(conflicts, DedupeConflict{RepoID: repoID, Field: field, Key: key, Kept: value, Dropped: existing})
+		}
+		dst[key] = value
+	}
+	return dst, conflicts
+}
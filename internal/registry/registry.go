@@ -29,20 +29,38 @@ const (
 
 // Entry is a single repo entry in the registry.
 type Entry struct {
-	RepoID                  string              `yaml:"repo_id"`
-	CheckoutID              string              `yaml:"checkout_id,omitempty"`
-	Path                    string              `yaml:"path"`
-	RemoteURL               string              `yaml:"remote_url"`
-	Type                    string              `yaml:"type,omitempty"` // checkout | mirror
-	Branch                  string              `yaml:"branch,omitempty"`
+	RepoID     string `yaml:"repo_id"`
+	CheckoutID string `yaml:"checkout_id,omitempty"`
+	Path       string `yaml:"path"`
+	RemoteURL  string `yaml:"remote_url"`
+	Type       string `yaml:"type,omitempty"` // checkout | mirror
+	Branch     string `yaml:"branch,omitempty"`
+	// DefaultBranch is the repo's mainline branch, read by scan from the
+	// primary remote's HEAD. Empty falls back to defaults.main_branch.
+	DefaultBranch string `yaml:"default_branch,omitempty"`
+	// TimeoutSeconds overrides the per-repo timeout for this repo in status
+	// and sync. Zero falls back to --timeout, then defaults.timeout_seconds.
+	TimeoutSeconds          int                 `yaml:"timeout_seconds,omitempty"`
 	Labels                  map[string]string   `yaml:"labels,omitempty"`
 	Annotations             map[string]string   `yaml:"annotations,omitempty"`
 	RepoMetadataFile        string              `yaml:"repo_metadata_file,omitempty"`
 	RepoMetadataError       string              `yaml:"repo_metadata_error,omitempty"`
 	RepoMetadataFingerprint string              `yaml:"repo_metadata_fingerprint,omitempty"`
 	RepoMetadata            *model.RepoMetadata `yaml:"repo_metadata,omitempty"`
-	LastSeen                time.Time           `yaml:"last_seen,omitempty"`
-	Status                  EntryStatus         `yaml:"status"`
+	// LastInspect is the inspect fingerprint recorded by the last status run
+	// that kept a status cache; see status --since-scan.
+	LastInspect string `yaml:"last_inspect,omitempty"`
+	// Shallow records whether the checkout had truncated history when scan
+	// or status last inspected it.
+	Shallow bool `yaml:"shallow,omitempty"`
+	// Archived hides the entry from status and sync unless
+	// --include-archived is given. Set with repokeeper archive.
+	Archived bool `yaml:"archived,omitempty"`
+	// MovedTo is where scan found a moved entry's repo. It is set only while
+	// Status is moved, until reconcile paths applies it.
+	MovedTo  string      `yaml:"moved_to,omitempty"`
+	LastSeen time.Time   `yaml:"last_seen,omitempty"`
+	Status   EntryStatus `yaml:"status"`
 }
 
 // Registry is the per-machine mapping of repo identities to local paths.
@@ -252,6 +270,18 @@ func mergeRegistryEntry(existing, incoming Entry) Entry {
 	if merged.RepoMetadataFingerprint == "" {
 		merged.RepoMetadataFingerprint = existing.RepoMetadataFingerprint
 	}
+	if merged.DefaultBranch == "" {
+		merged.DefaultBranch = existing.DefaultBranch
+	}
+	if merged.TimeoutSeconds == 0 {
+		merged.TimeoutSeconds = existing.TimeoutSeconds
+	}
+	if merged.LastInspect == "" {
+		merged.LastInspect = existing.LastInspect
+	}
+	if !merged.Archived {
+		merged.Archived = existing.Archived
+	}
 	if merged.RepoMetadata == nil && existing.RepoMetadata != nil {
 		merged.RepoMetadata = cloneRepoMetadata(existing.RepoMetadata)
 	}
@@ -350,22 +380,70 @@ func cloneStringSlice(in []string) []string {
 }
 
 // ValidatePaths checks all entries against the filesystem and marks
-// entries as missing or moved as appropriate.
+// entries as present or missing. A recorded move is dropped because scan
+// detects it again (see MarkMoved). Entries that cannot be checked because a
+// parent directory is unreadable keep their status.
 func (r *Registry) ValidatePaths() error {
 	for i := range r.Entries {
 		_, err := os.Stat(r.Entries[i].Path)
 		if err != nil {
 			if os.IsNotExist(err) {
 				r.Entries[i].Status = StatusMissing
+				r.Entries[i].MovedTo = ""
+				continue
+			}
+			if os.IsPermission(err) {
 				continue
 			}
 			return err
 		}
 		r.Entries[i].Status = StatusPresent
+		r.Entries[i].MovedTo = ""
 	}
 	return nil
 }
 
+// MarkMoved records that the repo repoID now lives at newPath when an entry
+// for it still points at a path that no longer exists and no entry already
+// tracks newPath. The entry keeps its old path, is marked moved, and records
+// newPath in MovedTo for reconcile paths to apply. It reports whether an entry
+// was marked.
+func (r *Registry) MarkMoved(repoID, newPath string, now time.Time) bool {
+	if r == nil || strings.TrimSpace(repoID) == "" || strings.TrimSpace(newPath) == "" {
+		return false
+	}
+	for i := range r.Entries {
+		if sameRegistryPath(r.Entries[i].Path, newPath) {
+			return false
+		}
+	}
+	for i := range r.Entries {
+		entry := &r.Entries[i]
+		if entry.RepoID != repoID || pathExists(entry.Path) {
+			continue
+		}
+		entry.Status = StatusMoved
+		entry.MovedTo = filepath.Clean(newPath)
+		entry.LastSeen = now
+		return true
+	}
+	return false
+}
+
+// ApplyMove points a moved entry at the path recorded in MovedTo and marks it
+// present again. It reports false, leaving the entry untouched, when the entry
+// is not moved or has no recorded destination.
+func (e *Entry) ApplyMove(now time.Time) bool {
+	if e == nil || e.Status != StatusMoved || strings.TrimSpace(e.MovedTo) == "" {
+		return false
+	}
+	e.Path = e.MovedTo
+	e.MovedTo = ""
+	e.Status = StatusPresent
+	e.LastSeen = now
+	return true
+}
+
 // PruneStale removes entries marked as missing that are older than
 // the given threshold.
 func (r *Registry) PruneStale(olderThan time.Duration) int {
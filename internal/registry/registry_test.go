@@ -100,6 +100,14 @@ var _ = Describe("Registry", func() {
 		Expect(reg.Entries[0].Annotations).To(HaveKeyWithValue("owner", "sre"))
 	})
 
+	It("keeps an entry archived when a rescan upserts it", func() {
+		reg := &registry.Registry{}
+		reg.Upsert(registry.Entry{RepoID: "repo1", Path: "/a", Archived: true, Status: registry.StatusPresent})
+		reg.Upsert(registry.Entry{RepoID: "repo1", Path: "/a", Status: registry.StatusPresent})
+		Expect(reg.Entries).To(HaveLen(1))
+		Expect(reg.Entries[0].Archived).To(BeTrue())
+	})
+
 	It("validates paths and marks missing", func() {
 		dir := GinkgoT().TempDir()
 		existing := filepath.Join(dir, "exists")
@@ -462,3 +470,144 @@ func TestLookupsDoNotMutateEntries(t *testing.T) {
 
 	g.Expect(reg.Entries[0].CheckoutID).To(BeEmpty(), "read-only lookups must not mutate the stored entry")
 }
+
+// TestMarkMovedRecordsNewPathUntilApplied confirms scan-side move detection
+// keeps the old path and records the destination, and that ApplyMove re-homes
+// the entry.
+func TestMarkMovedRecordsNewPathUntilApplied(t *testing.T) {
+	g := NewWithT(t)
+	root := t.TempDir()
+	newPath := filepath.Join(root, "renamed")
+	g.Expect(os.MkdirAll(newPath, 0o755)).To(Succeed())
+	oldPath := filepath.Join(root, "proj") // gone from disk
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/acme/proj", Path: oldPath, Status: registry.StatusMissing},
+	}}
+	now := time.Now()
+	g.Expect(reg.MarkMoved("github.com/acme/other", newPath, now)).To(BeFalse())
+	g.Expect(reg.MarkMoved("github.com/acme/proj", newPath, now)).To(BeTrue())
+	g.Expect(reg.Entries[0].Path).To(Equal(oldPath))
+	g.Expect(reg.Entries[0].Status).To(Equal(registry.StatusMoved))
+	g.Expect(reg.Entries[0].MovedTo).To(Equal(newPath))
+
+	g.Expect(reg.Entries[0].ApplyMove(now)).To(BeTrue())
+	g.Expect(reg.Entries[0].Path).To(Equal(newPath))
+	g.Expect(reg.Entries[0].MovedTo).To(BeEmpty())
+	g.Expect(reg.Entries[0].Status).To(Equal(registry.StatusPresent))
+	g.Expect(reg.Entries[0].ApplyMove(now)).To(BeFalse(), "a present entry has no move to apply")
+}
+
+// TestMarkMovedSkipsLiveAndTrackedPaths confirms a second live checkout is
+// not mistaken for a move, and a destination already tracked is left alone.
+func TestMarkMovedSkipsLiveAndTrackedPaths(t *testing.T) {
+	g := NewWithT(t)
+	root := t.TempDir()
+	live := filepath.Join(root, "live")
+	other := filepath.Join(root, "other")
+	g.Expect(os.MkdirAll(live, 0o755)).To(Succeed())
+	g.Expect(os.MkdirAll(other, 0o755)).To(Succeed())
+
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/acme/proj", Path: live, Status: registry.StatusPresent},
+	}}
+	g.Expect(reg.MarkMoved("github.com/acme/proj", other, time.Now())).To(BeFalse())
+
+	reg.Entries = append(reg.Entries,
+		registry.Entry{RepoID: "github.com/acme/proj", Path: filepath.Join(root, "gone"), Status: registry.StatusMissing})
+	g.Expect(reg.MarkMoved("github.com/acme/proj", live, time.Now())).To(BeFalse())
+	g.Expect(reg.Entries[1].Status).To(Equal(registry.StatusMissing))
+}
+
+// TestDedupeMergesStaleEntriesIntoTheLiveCheckout covers the winner choice,
+// label merging in registry order, and that live checkouts are never dropped.
+func TestDedupeMergesStaleEntriesIntoTheLiveCheckout(t *testing.T) {
+	g := NewWithT(t)
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	entries := []registry.Entry{
+		{RepoID: "a", Path: "/old/a", Status: registry.StatusMissing, Labels: map[string]string{"team": "core", "tier": "1"}},
+		{RepoID: "b", Path: "/b", Status: registry.StatusPresent},
+		{RepoID: "a", Path: "/src/a", Status: registry.StatusPresent, Labels: map[string]string{"team": "infra"}},
+		{RepoID: "a", Path: "/wt/a", Status: registry.StatusPresent, Labels: map[string]string{"team": "other"}},
+		{RepoID: "c", Path: "/c1", Status: registry.StatusMissing, LastSeen: newer, Annotations: map[string]string{"note": "x"}},
+		{RepoID: "c", Path: "/c2", Status: registry.StatusMissing, LastSeen: older, Annotations: map[string]string{"owner": "me"}},
+	}
+
+	got, conflicts := registry.Dedupe(entries)
+	g.Expect(got).To(HaveLen(4))
+	g.Expect(got[0].RepoID).To(Equal("b"))
+	g.Expect(got[1].Path).To(Equal("/src/a"))
+	g.Expect(got[1].Labels).To(Equal(map[string]string{"team": "infra", "tier": "1"}))
+	g.Expect(got[2].Path).To(Equal("/wt/a"), "a second present checkout is kept untouched")
+	g.Expect(got[2].Labels).To(Equal(map[string]string{"team": "other"}))
+	g.Expect(got[3].Path).To(Equal("/c1"), "the most recently seen entry wins when none is present")
+	g.Expect(got[3].Annotations).To(Equal(map[string]string{"note": "x", "owner": "me"}))
+	g.Expect(conflicts).To(Equal([]registry.DedupeConflict{
+		{RepoID: "a", Field: "labels", Key: "team", Kept: "infra", Dropped: "core"},
+	}))
+	g.Expect(entries[2].Labels).To(Equal(map[string]string{"team": "infra"}), "the input is not modified")
+
+	unique := []registry.Entry{{RepoID: "x", Path: "/x"}, {RepoID: "y", Path: "/y"}}
+	got, conflicts = registry.Dedupe(unique)
+	g.Expect(got).To(Equal(unique))
+	g.Expect(conflicts).To(BeEmpty())
+}
+
+// TestDedupeRanksStatusBeforeLastSeen confirms a recently seen missing entry
+// never beats a live checkout, and that LastSeen only orders entries with the
+// same status.
+func TestDedupeRanksStatusBeforeLastSeen(t *testing.T) {
+	g := NewWithT(t)
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	entries := []registry.Entry{
+		{RepoID: "a", Path: "/gone/a", Status: registry.StatusMissing, LastSeen: newer, Labels: map[string]string{"team": "core"}},
+		{RepoID: "a", Path: "/src/a", Status: registry.StatusPresent, LastSeen: older},
+		{RepoID: "a", Path: "/wt/a", Status: registry.StatusPresent, LastSeen: newer},
+	}
+
+	got, _ := registry.Dedupe(entries)
+	g.Expect(got).To(HaveLen(2), "present entries are never dropped")
+	g.Expect(got[0].Path).To(Equal("/src/a"))
+	g.Expect(got[0].Labels).To(BeEmpty())
+	g.Expect(got[1].Path).To(Equal("/wt/a"))
+	g.Expect(got[1].Labels).To(Equal(map[string]string{"team": "core"}), "the most recently seen present entry takes the merged values")
+}
+
+// TestMergeMetadataUnionsKeysAndKeepsLocalFields covers the per-key
+// precedence of MergeMetadata and that every other field stays local.
+func TestMergeMetadataUnionsKeysAndKeepsLocalFields(t *testing.T) {
+	g := NewWithT(t)
+	local := registry.Entry{
+		RepoID: "a", Path: "/src/a", RemoteURL: "git@example.com:a.git",
+		Labels:      map[string]string{"team": "core", "tier": "dev"},
+		Annotations: map[string]string{"owner": "me"},
+	}
+	incoming := registry.Entry{
+		RepoID: "a", Path: "/bundle/a", RemoteURL: "https://example.com/a.git",
+		Labels: map[string]string{"tier": "prod", "env": "eu"},
+	}
+
+	got, conflicts := registry.MergeMetadata(local, incoming)
+	g.Expect(got.Path).To(Equal("/src/a"))
+	g.Expect(got.RemoteURL).To(Equal("git@example.com:a.git"))
+	g.Expect(got.Labels).To(Equal(map[string]string{"team": "core", "tier": "prod", "env": "eu"}))
+	g.Expect(got.Annotations).To(Equal(map[string]string{"owner": "me"}))
+	g.Expect(conflicts).To(Equal([]registry.DedupeConflict{
+		{RepoID: "a", Field: "labels", Key: "tier", Kept: "prod", Dropped: "dev"},
+	}))
+	g.Expect(local.Labels).To(HaveKeyWithValue("tier", "dev"), "the local maps are not modified")
+}
+
+// TestUpsertKeepsTimeoutOverride confirms a rescan does not drop a repo's
+// timeout_seconds override.
+func TestUpsertKeepsTimeoutOverride(t *testing.T) {
+	g := NewWithT(t)
+	reg := &registry.Registry{Entries: []registry.Entry{
+		{RepoID: "github.com/acme/huge", Path: "/src/huge", Status: registry.StatusPresent, TimeoutSeconds: 600},
+	}}
+	reg.Upsert(registry.Entry{RepoID: "github.com/acme/huge", Path: "/src/huge", Status: registry.StatusPresent})
+	g.Expect(reg.Entries).To(HaveLen(1))
+	g.Expect(reg.Entries[0].TimeoutSeconds).To(Equal(600))
+}
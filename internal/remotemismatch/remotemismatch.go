@@ -19,6 +19,19 @@ const (
 	ReconcileNone     ReconcileMode = "none"
 	ReconcileRegistry ReconcileMode = "registry"
 	ReconcileGit      ReconcileMode = "git"
+	// ReconcileAddRemote adds the registry URL as AddedRemoteName instead of
+	// rewriting the primary remote, for repos that need both (e.g. a fork).
+	ReconcileAddRemote ReconcileMode = "add-remote"
+)
+
+// AddedRemoteName is the remote ReconcileAddRemote creates for the registry URL.
+const AddedRemoteName = "repokeeper-upstream"
+
+// Plan verbs name the change a Plan makes.
+const (
+	VerbUpdateRegistry = "update-registry"
+	VerbSetURL         = "set-url"
+	VerbAdd            = "add"
 )
 
 // Plan describes one remote mismatch reconcile action for a repo.
@@ -30,6 +43,11 @@ type Plan struct {
 	RegistryURL   string
 	EntryIndex    int
 	Action        string
+	// Remote is the git remote the plan changes: the primary remote for
+	// set-url, AddedRemoteName for add. Empty for registry updates.
+	Remote string
+	// Verb is VerbUpdateRegistry, VerbSetURL, or VerbAdd.
+	Verb string
 }
 
 // ParseReconcileMode validates and parses a reconcile mode flag value.
@@ -38,10 +56,10 @@ func ParseReconcileMode(raw string) (ReconcileMode, error) {
 	switch mode {
 	case "", ReconcileNone:
 		return ReconcileNone, nil
-	case ReconcileRegistry, ReconcileGit:
+	case ReconcileRegistry, ReconcileGit, ReconcileAddRemote:
 		return mode, nil
 	default:
-		return "", fmt.Errorf("unsupported --reconcile-remote-mismatch value %q (expected none, registry, or git)", raw)
+		return "", fmt.Errorf("unsupported --reconcile-remote-mismatch value %q (expected none, registry, git, or add-remote)", raw)
 	}
 }
 
@@ -65,18 +83,29 @@ func BuildPlans(repos []model.RepoStatus, reg *registry.Registry, adapter vcs.Ad
 			continue
 		}
 		repoRemoteURL := primaryRemoteURL(repo)
-		action := ""
+		action, remote, verb := "", "", ""
 		switch mode {
 		case ReconcileRegistry:
 			if repoRemoteURL == "" {
 				continue
 			}
-			action = "set registry remote_url to live git remote"
+			action, verb = "set registry remote_url to live git remote", VerbUpdateRegistry
 		case ReconcileGit:
 			if strings.TrimSpace(repo.PrimaryRemote) == "" {
 				continue
 			}
-			action = "set git remote URL to registry remote_url"
+			action, remote, verb = "set git remote URL to registry remote_url", repo.PrimaryRemote, VerbSetURL
+		case ReconcileAddRemote:
+			if hasRemoteURL(repo, registryURL, adapter) {
+				continue
+			}
+			remote, verb = AddedRemoteName, VerbAdd
+			action = "add git remote " + AddedRemoteName + " for registry remote_url"
+			if hasRemoteNamed(repo, AddedRemoteName) {
+				// A previous run already added the remote; repoint it.
+				verb = VerbSetURL
+				action = "set git remote " + AddedRemoteName + " URL to registry remote_url"
+			}
 		}
 		plans = append(plans, Plan{
 			RepoID:        repo.RepoID,
@@ -86,6 +115,8 @@ func BuildPlans(repos []model.RepoStatus, reg *registry.Registry, adapter vcs.Ad
 			RegistryURL:   registryURL,
 			EntryIndex:    entryIndex,
 			Action:        action,
+			Remote:        remote,
+			Verb:          verb,
 		})
 	}
 	return plans
@@ -123,6 +154,37 @@ func ApplyPlans(ctx context.Context, plans []Plan, reg *registry.Registry, mode
 				return fmt.Errorf("git remote set-url %q %q (%q): %w", plan.PrimaryRemote, plan.RegistryURL, plan.Path, err)
 			}
 		}
+	case ReconcileAddRemote:
+		if adapter == nil {
+			return fmt.Errorf("adapter is required for git remote reconciliation")
+		}
+		for _, plan := range plans {
+			if err := applyAddRemotePlan(ctx, plan, adapter); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyAddRemotePlan adds plan.Remote, or repoints it when the plan found it
+// already present.
+func applyAddRemotePlan(ctx context.Context, plan Plan, adapter vcs.Adapter) error {
+	if strings.TrimSpace(plan.Remote) == "" {
+		return nil
+	}
+	if plan.Verb == VerbSetURL {
+		if err := adapter.SetRemoteURL(ctx, plan.Path, plan.Remote, plan.RegistryURL); err != nil {
+			return fmt.Errorf("git remote set-url %q %q (%q): %w", plan.Remote, plan.RegistryURL, plan.Path, err)
+		}
+		return nil
+	}
+	adder, ok := adapter.(vcs.RemoteAdder)
+	if !ok {
+		return fmt.Errorf("%s does not support adding remotes", adapter.Name())
+	}
+	if err := adder.AddRemote(ctx, plan.Path, plan.Remote, plan.RegistryURL); err != nil {
+		return fmt.Errorf("git remote add %q %q (%q): %w", plan.Remote, plan.RegistryURL, plan.Path, err)
 	}
 	return nil
 }
@@ -131,6 +193,27 @@ func findRegistryEntryIndexForStatus(reg *registry.Registry, repo model.RepoStat
 	return reg.FindEntryIndex(repo.RepoID, repo.Path)
 }
 
+// hasRemoteURL reports whether any of the repo's remotes already points at
+// remoteURL, compared by normalized identity.
+func hasRemoteURL(repo model.RepoStatus, remoteURL string, adapter vcs.Adapter) bool {
+	want := adapter.NormalizeURL(remoteURL)
+	for _, remote := range repo.Remotes {
+		if strings.TrimSpace(remote.URL) != "" && adapter.NormalizeURL(remote.URL) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRemoteNamed(repo model.RepoStatus, name string) bool {
+	for _, remote := range repo.Remotes {
+		if remote.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func primaryRemoteURL(repo model.RepoStatus) string {
 	for _, remote := range repo.Remotes {
 		if remote.Name == repo.PrimaryRemote {
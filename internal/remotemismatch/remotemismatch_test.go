@@ -4,6 +4,7 @@ package remotemismatch
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,17 @@ type adapterStub struct {
 	setRemoteErr   error
 }
 
+// remoteAdderStub adds the optional RemoteAdder capability to adapterStub.
+type remoteAdderStub struct {
+	adapterStub
+	addRemoteCalls []string
+}
+
+func (a *remoteAdderStub) AddRemote(_ context.Context, dir, remote, remoteURL string) error {
+	a.addRemoteCalls = append(a.addRemoteCalls, dir+":"+remote+":"+remoteURL)
+	return nil
+}
+
 func (a *adapterStub) Name() string                                            { return "stub" }
 func (a *adapterStub) IsRepo(context.Context, string) (bool, error)            { return true, nil }
 func (a *adapterStub) IsBare(context.Context, string) (bool, error)            { return false, nil }
@@ -64,6 +76,10 @@ func TestParseReconcileMode(t *testing.T) {
 	if err != nil || mode != ReconcileGit {
 		t.Fatalf("expected git mode, got %q (%v)", mode, err)
 	}
+	mode, err = ParseReconcileMode("add-remote")
+	if err != nil || mode != ReconcileAddRemote {
+		t.Fatalf("expected add-remote mode, got %q (%v)", mode, err)
+	}
 	if _, err := ParseReconcileMode("invalid"); err == nil {
 		t.Fatal("expected invalid mode to error")
 	}
@@ -183,3 +199,79 @@ func TestApplyPlansEdgeModes(t *testing.T) {
 		t.Fatalf("expected unchanged entry for invalid index, got %q", got)
 	}
 }
+
+func TestBuildPlansAddRemote(t *testing.T) {
+	reg := &registry.Registry{
+		Entries: []registry.Entry{
+			{RepoID: "github.com/fork/repo-a", Path: "/tmp/repo-a", RemoteURL: "git@github.com:org/repo-a.git"},
+			{RepoID: "github.com/fork/repo-b", Path: "/tmp/repo-b", RemoteURL: "git@github.com:org/repo-b.git"},
+			{RepoID: "github.com/fork/repo-c", Path: "/tmp/repo-c", RemoteURL: "git@github.com:org/repo-c.git"},
+		},
+	}
+	repos := []model.RepoStatus{
+		{
+			RepoID:        "github.com/fork/repo-a",
+			Path:          "/tmp/repo-a",
+			PrimaryRemote: "origin",
+			Remotes:       []model.Remote{{Name: "origin", URL: "git@github.com:fork/repo-a.git"}},
+		},
+		{
+			// The registry URL is already configured as a second remote.
+			RepoID:        "github.com/fork/repo-b",
+			Path:          "/tmp/repo-b",
+			PrimaryRemote: "origin",
+			Remotes: []model.Remote{
+				{Name: "origin", URL: "git@github.com:fork/repo-b.git"},
+				{Name: "upstream", URL: "git@github.com:org/repo-b.git"},
+			},
+		},
+		{
+			// An earlier run added repokeeper-upstream with a stale URL.
+			RepoID:        "github.com/fork/repo-c",
+			Path:          "/tmp/repo-c",
+			PrimaryRemote: "origin",
+			Remotes: []model.Remote{
+				{Name: "origin", URL: "git@github.com:fork/repo-c.git"},
+				{Name: AddedRemoteName, URL: "git@github.com:old/repo-c.git"},
+			},
+		},
+	}
+	adapter := &remoteAdderStub{}
+
+	plans := BuildPlans(repos, reg, adapter, ReconcileAddRemote)
+	if len(plans) != 2 {
+		t.Fatalf("expected plans for repo-a and repo-c only, got %+v", plans)
+	}
+	if plans[0].Verb != VerbAdd || plans[0].Remote != AddedRemoteName || plans[0].Path != "/tmp/repo-a" {
+		t.Fatalf("expected add plan for repo-a, got %+v", plans[0])
+	}
+	if plans[1].Verb != VerbSetURL || plans[1].Remote != AddedRemoteName {
+		t.Fatalf("expected set-url plan for existing %s, got %+v", AddedRemoteName, plans[1])
+	}
+
+	if err := ApplyPlans(context.Background(), plans, reg, ReconcileAddRemote, adapter, nil); err != nil {
+		t.Fatalf("apply add-remote plans: %v", err)
+	}
+	if len(adapter.addRemoteCalls) != 1 || adapter.addRemoteCalls[0] != "/tmp/repo-a:repokeeper-upstream:git@github.com:org/repo-a.git" {
+		t.Fatalf("expected one add-remote call, got %v", adapter.addRemoteCalls)
+	}
+	if len(adapter.setRemoteCalls) != 1 || adapter.setRemoteCalls[0] != "/tmp/repo-c:repokeeper-upstream:git@github.com:org/repo-c.git" {
+		t.Fatalf("expected set-url only on %s, got %v", AddedRemoteName, adapter.setRemoteCalls)
+	}
+	if reg.Entries[0].RemoteURL != "git@github.com:org/repo-a.git" {
+		t.Fatalf("expected registry untouched, got %q", reg.Entries[0].RemoteURL)
+	}
+
+	gitPlans := BuildPlans(repos[:1], reg, adapter, ReconcileGit)
+	if len(gitPlans) != 1 || gitPlans[0].Verb != VerbSetURL || gitPlans[0].Remote != "origin" {
+		t.Fatalf("expected git mode to plan set-url on origin, got %+v", gitPlans)
+	}
+}
+
+func TestApplyPlansAddRemoteRequiresCapability(t *testing.T) {
+	plans := []Plan{{Path: "/tmp/repo-a", Remote: AddedRemoteName, Verb: VerbAdd, RegistryURL: "git@github.com:org/repo-a.git"}}
+	err := ApplyPlans(context.Background(), plans, &registry.Registry{}, ReconcileAddRemote, &adapterStub{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "does not support adding remotes") {
+		t.Fatalf("expected unsupported adapter error, got %v", err)
+	}
+}
@@ -14,17 +14,20 @@ import (
 // constants: an unrecognized value must be rejected rather than silently
 // falling through to "match everything".
 var knownOnlyFilterKinds = map[engine.FilterKind]struct{}{
-	engine.FilterAll:            {},
-	engine.FilterErrors:         {},
-	engine.FilterDirty:          {},
-	engine.FilterClean:          {},
-	engine.FilterGone:           {},
-	engine.FilterDiverged:       {},
-	engine.FilterBehind:         {},
-	engine.FilterAhead:          {},
-	engine.FilterEqual:          {},
-	engine.FilterRemoteMismatch: {},
-	engine.FilterMissing:        {},
+	engine.FilterAll:                   {},
+	engine.FilterErrors:                {},
+	engine.FilterDirty:                 {},
+	engine.FilterClean:                 {},
+	engine.FilterGone:                  {},
+	engine.FilterDiverged:              {},
+	engine.FilterBehind:                {},
+	engine.FilterAhead:                 {},
+	engine.FilterEqual:                 {},
+	engine.FilterRemoteMismatch:        {},
+	engine.FilterMissing:               {},
+	engine.FilterStaleMetadata:         {},
+	engine.FilterStaleUpstream:         {},
+	engine.FilterBranchesBehindDefault: {},
 }
 
 // ResolveRepoFilter combines --only and --field-selector into a single FilterKind.
@@ -42,7 +45,7 @@ func ResolveRepoFilter(only, fieldSelector string) (engine.FilterKind, error) {
 		}
 		kind := engine.FilterKind(onlyTrimmed)
 		if _, ok := knownOnlyFilterKinds[kind]; !ok {
-			return "", fmt.Errorf("unsupported --only value %q (expected one of: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing)", only)
+			return "", fmt.Errorf("unsupported --only value %q (expected one of: all, errors, dirty, clean, gone, diverged, behind, ahead, equal, remote-mismatch, missing, stale-metadata, stale-upstream, branches-behind-default)", only)
 		}
 		return kind, nil
 	}
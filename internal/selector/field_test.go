@@ -64,6 +64,8 @@ var _ = Describe("Field Selector", func() {
 			Entry("equal", "equal", engine.FilterEqual),
 			Entry("remote-mismatch", "remote-mismatch", engine.FilterRemoteMismatch),
 			Entry("missing", "missing", engine.FilterMissing),
+			Entry("stale-metadata", "stale-metadata", engine.FilterStaleMetadata),
+			Entry("branches-behind-default", "branches-behind-default", engine.FilterBranchesBehindDefault),
 			Entry("empty defaults to all", "", engine.FilterAll),
 			Entry("uppercase is case-insensitive", "DIRTY", engine.FilterDirty),
 		)
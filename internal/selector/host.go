@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skaphos/repokeeper/internal/gitx"
+)
+
+// ParseHostPatterns validates and lowercases --exclude-remote-host values. A
+// pattern is a host name such as "github.com" or "*.corp.example" to match any
+// subdomain of corp.example.
+func ParseHostPatterns(raw []string, flagName string) ([]string, error) {
+	patterns := make([]string, 0, len(raw))
+	for _, value := range raw {
+		pattern := strings.ToLower(strings.TrimSpace(value))
+		if pattern == "" {
+			return nil, fmt.Errorf("invalid %s: host cannot be empty", flagName)
+		}
+		if strings.ContainsAny(strings.TrimPrefix(pattern, "*."), "*/:@ \t") {
+			return nil, fmt.Errorf("invalid %s %q: expected a host name, optionally prefixed with *.", flagName, value)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// RemoteHost returns the lowercased host of a registry entry, taken from the
+// normalized remote URL when there is one and from the repo ID otherwise.
+// Local-only repos have no host and return "".
+func RemoteHost(repoID, remoteURL string) string {
+	normalized := strings.TrimSpace(repoID)
+	if remoteURL = strings.TrimSpace(remoteURL); remoteURL != "" {
+		normalized = gitx.NormalizeURL(remoteURL)
+	}
+	if normalized == "" || strings.HasPrefix(normalized, "local:") {
+		return ""
+	}
+	host, _, ok := strings.Cut(normalized, "/")
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(host)
+}
+
+// HostMatchesAny reports whether host matches one of the patterns from
+// ParseHostPatterns. A "*." pattern matches subdomains only, not the bare
+// domain. An empty host never matches.
+func HostMatchesAny(host string, patterns []string) bool {
+	if host == "" {
+		return false
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: MIT
+package selector_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/skaphos/repokeeper/internal/selector"
+)
+
+var _ = Describe("Remote host selector", func() {
+	It("lowercases patterns and rejects malformed hosts", func() {
+		patterns, err := selector.ParseHostPatterns([]string{" GitHub.com ", "*.Corp.Example"}, "--exclude-remote-host")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patterns).To(Equal([]string{"github.com", "*.corp.example"}))
+
+		for _, bad := range []string{"", "github.com/org", "git*.com", "*.*.example", "git@github.com"} {
+			_, err := selector.ParseHostPatterns([]string{bad}, "--exclude-remote-host")
+			Expect(err).To(HaveOccurred(), bad)
+		}
+	})
+
+	It("takes the host from the remote URL before the repo ID", func() {
+		Expect(selector.RemoteHost("github.com/org/repo", "git@GitLab.com:org/repo.git")).To(Equal("gitlab.com"))
+		Expect(selector.RemoteHost("GitHub.com/org/repo", "")).To(Equal("github.com"))
+		Expect(selector.RemoteHost("local:/work/repo", "")).To(BeEmpty())
+	})
+
+	It("matches exact hosts and subdomain wildcards", func() {
+		patterns := []string{"github.com", "*.corp.example"}
+		Expect(selector.HostMatchesAny("GITHUB.COM", patterns)).To(BeTrue())
+		Expect(selector.HostMatchesAny("git.corp.example", patterns)).To(BeTrue())
+		Expect(selector.HostMatchesAny("a.git.corp.example", patterns)).To(BeTrue())
+		Expect(selector.HostMatchesAny("corp.example", patterns)).To(BeFalse())
+		Expect(selector.HostMatchesAny("notcorp.example", patterns)).To(BeFalse())
+		Expect(selector.HostMatchesAny("gist.github.com", patterns)).To(BeFalse())
+		Expect(selector.HostMatchesAny("", patterns)).To(BeFalse())
+	})
+})
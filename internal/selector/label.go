@@ -60,19 +60,30 @@ func ParseLabelSelectorForFlag(raw, flagName string) ([]LabelRequirement, error)
 // LabelsMatchSelector returns true if the given labels satisfy all requirements.
 // An empty requirement list matches everything.
 func LabelsMatchSelector(labels map[string]string, reqs []LabelRequirement) bool {
+	matched, _ := ExplainLabelSelector(labels, reqs)
+	return matched
+}
+
+// ExplainLabelSelector is LabelsMatchSelector with a reason. A match is
+// explained by the matching labels (for example "tier=prod,team=core"), a
+// miss by the first requirement that failed. An empty requirement list
+// matches with an empty reason.
+func ExplainLabelSelector(labels map[string]string, reqs []LabelRequirement) (bool, string) {
 	if len(reqs) == 0 {
-		return true
+		return true, ""
 	}
+	matched := make([]string, 0, len(reqs))
 	for _, req := range reqs {
 		got, ok := labels[req.Key]
 		if !ok {
-			return false
+			return false, fmt.Sprintf("missing label %s", req.Key)
 		}
 		if req.HasValue && got != req.Value {
-			return false
+			return false, fmt.Sprintf("label %s=%s, want %s", req.Key, got, req.Value)
 		}
+		matched = append(matched, req.Key+"="+got)
 	}
-	return true
+	return true, strings.Join(matched, ",")
 }
 
 // validateKey checks that a selector key is non-empty and contains no
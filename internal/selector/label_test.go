@@ -73,4 +73,32 @@ var _ = Describe("Label Selector", func() {
 			Expect(selector.LabelsMatchSelector(labels, nil)).To(BeTrue())
 		})
 	})
+
+	Describe("ExplainLabelSelector", func() {
+		labels := map[string]string{
+			"team": "platform",
+			"env":  "prod",
+		}
+
+		It("explains a match with the matching labels", func() {
+			reqs, err := selector.ParseLabelSelector("env=prod,team")
+			Expect(err).NotTo(HaveOccurred())
+			matched, reason := selector.ExplainLabelSelector(labels, reqs)
+			Expect(matched).To(BeTrue())
+			Expect(reason).To(Equal("env=prod,team=platform"))
+		})
+
+		It("explains a miss with the failing requirement", func() {
+			reqs, err := selector.ParseLabelSelector("team=app")
+			Expect(err).NotTo(HaveOccurred())
+			matched, reason := selector.ExplainLabelSelector(labels, reqs)
+			Expect(matched).To(BeFalse())
+			Expect(reason).To(Equal("label team=platform, want app"))
+
+			reqs, err = selector.ParseLabelSelector("tier")
+			Expect(err).NotTo(HaveOccurred())
+			_, reason = selector.ExplainLabelSelector(labels, reqs)
+			Expect(reason).To(Equal("missing label tier"))
+		})
+	})
 })
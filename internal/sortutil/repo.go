@@ -2,7 +2,10 @@
 package sortutil
 
 import (
+	"cmp"
+	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/registry"
@@ -30,3 +33,124 @@ func SortRegistryEntries(entries []registry.Entry) {
 		return LessRepoIDPath(entries[i].RepoID, entries[i].Path, entries[j].RepoID, entries[j].Path)
 	})
 }
+
+// Sort keys accepted by ParseRepoStatusOrder.
+const (
+	SortKeyRepo     = "repo"
+	SortKeyPath     = "path"
+	SortKeyTracking = "tracking"
+	SortKeyDirty    = "dirty"
+	SortKeyBehind   = "behind"
+)
+
+// RepoStatusOrder selects how SortRepoStatusesBy orders status rows.
+type RepoStatusOrder struct {
+	Key        string
+	Descending bool
+}
+
+// ParseRepoStatusOrder parses a sort key with an optional "-" prefix that
+// reverses it. An empty value is the default repo order.
+func ParseRepoStatusOrder(raw string) (RepoStatusOrder, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	order := RepoStatusOrder{Key: SortKeyRepo}
+	if raw == "" {
+		return order, nil
+	}
+	if rest, ok := strings.CutPrefix(raw, "-"); ok {
+		order.Descending = true
+		raw = rest
+	}
+	switch raw {
+	case SortKeyRepo, SortKeyPath, SortKeyTracking, SortKeyDirty, SortKeyBehind:
+		order.Key = raw
+		return order, nil
+	default:
+		return RepoStatusOrder{}, fmt.Errorf("unsupported sort key %q (expected path, repo, tracking, dirty, or behind, optionally prefixed with -)", raw)
+	}
+}
+
+// SortRepoStatusesBy orders status rows by order.Key:
+//
+//   - repo: repo ID, then path (the default order)
+//   - path: path, then repo ID
+//   - tracking: most severe first: gone, diverged, behind, ahead, none, equal,
+//     then repos with no tracking status
+//   - dirty: dirty worktrees first, then clean ones, then repos whose worktree
+//     state is unknown (missing, bare, or failed to inspect)
+//   - behind: most commits behind upstream first; repos with no behind count
+//     come last
+//
+// Descending reverses the key's order. Rows equal under the key always fall
+// back to repo ID, then path, ascending, so the result is a total order that
+// does not depend on the input order.
+func SortRepoStatusesBy(statuses []model.RepoStatus, order RepoStatusOrder) {
+	compare := repoStatusComparators[order.Key]
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if compare != nil {
+			c := compare(statuses[i], statuses[j])
+			if order.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return LessRepoIDPath(statuses[i].RepoID, statuses[i].Path, statuses[j].RepoID, statuses[j].Path)
+	})
+}
+
+var repoStatusComparators = map[string]func(a, b model.RepoStatus) int{
+	SortKeyRepo: func(a, b model.RepoStatus) int {
+		return cmp.Or(strings.Compare(a.RepoID, b.RepoID), strings.Compare(a.Path, b.Path))
+	},
+	SortKeyPath: func(a, b model.RepoStatus) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.RepoID, b.RepoID))
+	},
+	SortKeyTracking: func(a, b model.RepoStatus) int {
+		return cmp.Compare(trackingRank(a.Tracking.Status), trackingRank(b.Tracking.Status))
+	},
+	SortKeyDirty: func(a, b model.RepoStatus) int { return cmp.Compare(dirtyRank(a), dirtyRank(b)) },
+	SortKeyBehind: func(a, b model.RepoStatus) int {
+		// Negated so that more commits behind sorts first.
+		return cmp.Compare(behindRank(b), behindRank(a))
+	},
+}
+
+func trackingRank(status model.TrackingStatus) int {
+	switch status {
+	case model.TrackingGone:
+		return 0
+	case model.TrackingDiverged:
+		return 1
+	case model.TrackingBehind:
+		return 2
+	case model.TrackingAhead:
+		return 3
+	case model.TrackingNone:
+		return 4
+	case model.TrackingEqual:
+		return 5
+	default:
+		return 6
+	}
+}
+
+func dirtyRank(repo model.RepoStatus) int {
+	switch {
+	case repo.Worktree == nil:
+		return 2
+	case repo.Worktree.Dirty:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// behindRank is the behind count, or -1 when it is unknown.
+func behindRank(repo model.RepoStatus) int {
+	if repo.Tracking.Behind == nil {
+		return -1
+	}
+	return *repo.Tracking.Behind
+}
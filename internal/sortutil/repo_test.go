@@ -55,3 +55,66 @@ func TestSortRegistryEntries(t *testing.T) {
 		t.Fatalf("unexpected third item: %+v", entries[2])
 	}
 }
+
+func TestParseRepoStatusOrder(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want RepoStatusOrder
+	}{
+		{raw: "", want: RepoStatusOrder{Key: SortKeyRepo}},
+		{raw: "path", want: RepoStatusOrder{Key: SortKeyPath}},
+		{raw: "-Behind", want: RepoStatusOrder{Key: SortKeyBehind, Descending: true}},
+		{raw: " dirty ", want: RepoStatusOrder{Key: SortKeyDirty}},
+	}
+	for _, tc := range cases {
+		got, err := ParseRepoStatusOrder(tc.raw)
+		if err != nil || got != tc.want {
+			t.Fatalf("ParseRepoStatusOrder(%q) = %+v, %v; want %+v", tc.raw, got, err, tc.want)
+		}
+	}
+	for _, raw := range []string{"size", "-", "--path"} {
+		if _, err := ParseRepoStatusOrder(raw); err == nil {
+			t.Fatalf("expected an error for %q", raw)
+		}
+	}
+}
+
+func TestSortRepoStatusesBy(t *testing.T) {
+	behind := func(n int) *int { return &n }
+	fixture := func() []model.RepoStatus {
+		return []model.RepoStatus{
+			{RepoID: "e", Path: "/5", Tracking: model.Tracking{Status: model.TrackingEqual, Behind: behind(0)}, Worktree: &model.Worktree{}},
+			{RepoID: "d", Path: "/1", Tracking: model.Tracking{Status: model.TrackingBehind, Behind: behind(7)}, Worktree: &model.Worktree{Dirty: true}},
+			{RepoID: "c", Path: "/3", Tracking: model.Tracking{Status: model.TrackingGone}},
+			{RepoID: "b", Path: "/4", Tracking: model.Tracking{Status: model.TrackingDiverged, Behind: behind(2)}, Worktree: &model.Worktree{Dirty: true}},
+			{RepoID: "a", Path: "/2", Tracking: model.Tracking{Status: model.TrackingNone}, Worktree: &model.Worktree{}},
+		}
+	}
+	cases := []struct {
+		order RepoStatusOrder
+		want  string
+	}{
+		{order: RepoStatusOrder{Key: SortKeyRepo}, want: "abcde"},
+		{order: RepoStatusOrder{Key: SortKeyRepo, Descending: true}, want: "edcba"},
+		{order: RepoStatusOrder{Key: SortKeyPath}, want: "dacbe"},
+		{order: RepoStatusOrder{Key: SortKeyPath, Descending: true}, want: "ebcad"},
+		{order: RepoStatusOrder{Key: SortKeyTracking}, want: "cbdae"},
+		{order: RepoStatusOrder{Key: SortKeyTracking, Descending: true}, want: "eadbc"},
+		// Ties fall back to repo ID ascending in both directions.
+		{order: RepoStatusOrder{Key: SortKeyDirty}, want: "bdaec"},
+		{order: RepoStatusOrder{Key: SortKeyDirty, Descending: true}, want: "caebd"},
+		{order: RepoStatusOrder{Key: SortKeyBehind}, want: "dbeac"},
+		{order: RepoStatusOrder{Key: SortKeyBehind, Descending: true}, want: "acebd"},
+	}
+	for _, tc := range cases {
+		statuses := fixture()
+		SortRepoStatusesBy(statuses, tc.order)
+		got := ""
+		for _, repo := range statuses {
+			got += repo.RepoID
+		}
+		if got != tc.want {
+			t.Fatalf("SortRepoStatusesBy(%+v) = %q, want %q", tc.order, got, tc.want)
+		}
+	}
+}
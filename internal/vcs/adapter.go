@@ -42,6 +42,174 @@ type RemoteTrackingRefInspector interface {
 	StaleRemoteTrackingRefs(ctx context.Context, dir string, remoteNames []string) ([]string, error)
 }
 
+// IgnoredFileInspector is an optional adapter capability for listing worktree
+// paths hidden by ignore rules. Non-Git adapters need not implement it.
+type IgnoredFileInspector interface {
+	IgnoredFiles(ctx context.Context, dir string) ([]string, error)
+}
+
+// StashInspector is an optional adapter capability for counting stash entries
+// left in a worktree. Non-Git adapters need not implement it.
+type StashInspector interface {
+	StashList(ctx context.Context, dir string) (int, error)
+}
+
+// LastCommitInspector is an optional adapter capability for reading the commit
+// date of HEAD. Non-Git adapters need not implement it.
+type LastCommitInspector interface {
+	LastCommit(ctx context.Context, dir string) (time.Time, error)
+}
+
+// ShallowInspector is an optional adapter capability for detecting shallow
+// clones. Non-Git adapters need not implement it.
+type ShallowInspector interface {
+	IsShallow(ctx context.Context, dir string) (bool, error)
+}
+
+// ShallowCloner is an optional adapter capability for cloning only recent
+// history of a single branch. Non-Git adapters need not implement it.
+type ShallowCloner interface {
+	CloneShallow(ctx context.Context, remoteURL, targetPath, branch string, depth int) error
+}
+
+// FetchOptions selects what an OptionFetcher fetch touches. The zero value is
+// the default Fetch. A non-empty Remote fetches only that remote; a positive
+// Depth deepens shallow history; NoPruneTags keeps local tags that no longer
+// exist on the remote.
+type FetchOptions struct {
+	Remote      string
+	Depth       int
+	NoPruneTags bool
+}
+
+// FetchResult reports local changes made by an OptionFetcher fetch.
+type FetchResult struct {
+	TagsPruned int
+}
+
+// OptionFetcher is an optional adapter capability for the sync fetch beyond
+// the default Fetch: a single remote, deepened history, or local-only tags
+// left in place. It reports how many tags it pruned. Non-Git adapters need
+// not implement it.
+type OptionFetcher interface {
+	FetchWithOptions(ctx context.Context, dir string, opts FetchOptions) (FetchResult, error)
+}
+
+// GitFetchAction renders the git fetch the sync step runs for remote (every
+// remote when empty) as a human-readable action string.
+func GitFetchAction(remote string, pruneTags bool) string {
+	return gitx.FetchCommand(remote, pruneTags)
+}
+
+// BranchCreator is an optional adapter capability for creating a local branch
+// at the current tip, used to keep a backup before a rebase. Non-Git adapters
+// need not implement it.
+type BranchCreator interface {
+	CreateBranch(ctx context.Context, dir, name string) error
+}
+
+// LFSFetcher is an optional adapter capability for repos that store content in
+// Git LFS: HasLFS probes for filter=lfs attributes and LFSFetch downloads the
+// LFS objects after a normal fetch. Non-Git adapters need not implement it.
+type LFSFetcher interface {
+	HasLFS(ctx context.Context, dir string) (bool, error)
+	LFSFetch(ctx context.Context, dir string) error
+}
+
+// SubmoduleUpdater is an optional adapter capability for bringing submodule
+// working trees in line with the superproject after a local update. Non-Git
+// adapters need not implement it.
+type SubmoduleUpdater interface {
+	SubmoduleUpdate(ctx context.Context, dir string) error
+}
+
+// RemoteDefaultBranchReader is an optional adapter capability for learning
+// the branch a remote's HEAD points at, recorded as a registry entry's
+// default_branch.
+type RemoteDefaultBranchReader interface {
+	RemoteDefaultBranch(ctx context.Context, dir, remote string) (string, error)
+}
+
+// RemoteHeadReader is an optional adapter capability for asking a remote
+// which branch its HEAD points at now, used to spot upstreams left on a
+// renamed default branch. Unlike RemoteDefaultBranchReader it contacts the
+// remote.
+type RemoteHeadReader interface {
+	RemoteHeadBranch(ctx context.Context, dir, remote string) (string, error)
+}
+
+// RemoteHeadUpdater is an optional adapter capability for refreshing the
+// locally recorded remote HEAD that RemoteDefaultBranchReader reads, so it
+// follows a renamed default branch without a live query on every inspection.
+type RemoteHeadUpdater interface {
+	UpdateRemoteHead(ctx context.Context, dir, remote string) error
+}
+
+// InspectFingerprinter is an optional adapter capability for incremental
+// status: InspectFingerprint cheaply summarizes the repo state an inspection
+// reads, so an unchanged fingerprint means a cached status is still valid.
+// Adapters without it are always inspected in full.
+type InspectFingerprinter interface {
+	InspectFingerprint(ctx context.Context, dir string) (string, error)
+}
+
+// UpstreamResetter is an optional adapter capability for forcing a checkout
+// to match its upstream, as sync --reset-hard does: ResetHardTo moves the
+// current branch, index, and worktree to ref, and CleanAll removes every
+// untracked file, including ignored ones. Non-Git adapters need not
+// implement it.
+type UpstreamResetter interface {
+	ResetHardTo(ctx context.Context, dir, ref string) error
+	CleanAll(ctx context.Context, dir string) error
+}
+
+// MirrorUpdater is an optional adapter capability for refreshing --mirror
+// clones, which need every ref updated rather than a checkout-style fetch.
+// Adapters without it sync mirrors with Fetch.
+type MirrorUpdater interface {
+	MirrorUpdate(ctx context.Context, dir string) error
+}
+
+// BranchDeleter is an optional adapter capability for deleting a local
+// branch. Without force it must refuse branches that are not fully merged.
+type BranchDeleter interface {
+	DeleteBranch(ctx context.Context, dir, branch string, force bool) error
+}
+
+// IntegrityChecker is an optional adapter capability for verifying a
+// repository's object store. It returns the problems found, none for a healthy
+// repo, and an error only when the check could not run to completion.
+type IntegrityChecker interface {
+	Fsck(ctx context.Context, dir string) ([]string, error)
+}
+
+// WorktreeRoleInspector is an optional adapter capability for telling a main
+// worktree from one added with `git worktree add`. It returns the role and the
+// shared common dir. Non-Git adapters need not implement it.
+type WorktreeRoleInspector interface {
+	WorktreeRole(ctx context.Context, dir string) (string, string, error)
+}
+
+// InProgressInspector is an optional adapter capability for spotting a rebase,
+// merge, or bisect left unfinished in a worktree. It returns one of the
+// model.InProgress constants, or "" when nothing is under way.
+type InProgressInspector interface {
+	InProgressOperation(ctx context.Context, dir string) (string, error)
+}
+
+// CommitLister is an optional adapter capability for reading a repo's recent
+// history, used by describe --history.
+type CommitLister interface {
+	RecentCommits(ctx context.Context, dir string, n int) ([]model.Commit, error)
+}
+
+// RemoteAdder is an optional adapter capability for adding a named remote,
+// used when remote mismatch reconciliation adds the registry URL alongside the
+// existing remotes. Non-Git adapters need not implement it.
+type RemoteAdder interface {
+	AddRemote(ctx context.Context, dir, remote, remoteURL string) error
+}
+
 // LocalBranchSignal is the raw per-branch prune-safety signal set produced by an
 // inspector: enumeration data plus tri-state integration results against a base
 // ref. The engine maps this into model.LocalBranch and classifies it; the
@@ -70,6 +238,13 @@ type LocalBranchInspector interface {
 	InspectLocalBranches(ctx context.Context, dir, base string, patchEquivalence bool) ([]LocalBranchSignal, error)
 }
 
+// BranchBehindCounter is an optional adapter capability for counting how many
+// commits a local branch is behind a base ref. Non-Git adapters need not
+// implement it.
+type BranchBehindCounter interface {
+	CommitsBehind(ctx context.Context, dir, base, branch string) (int, error)
+}
+
 // GitAdapter implements Adapter using the git CLI via gitx.
 type GitAdapter struct {
 	Runner gitx.Runner
@@ -119,6 +294,10 @@ func (g *GitAdapter) StaleRemoteTrackingRefs(ctx context.Context, dir string, re
 	return gitx.StaleRemoteTrackingRefs(ctx, g.Runner, dir, remoteNames)
 }
 
+func (g *GitAdapter) IgnoredFiles(ctx context.Context, dir string) ([]string, error) {
+	return gitx.IgnoredFiles(ctx, g.Runner, dir)
+}
+
 // InspectLocalBranches enumerates local branches and computes reachability and,
 // when patchEquivalence is set, per-branch patch-equivalence against base. A
 // failed merged check leaves MergedIntoBase nil so the classifier treats it as
@@ -177,10 +356,75 @@ func (g *GitAdapter) Fetch(ctx context.Context, dir string) error {
 	return gitx.Fetch(ctx, g.Runner, dir)
 }
 
+func (g *GitAdapter) FetchWithOptions(ctx context.Context, dir string, opts FetchOptions) (FetchResult, error) {
+	res, err := gitx.FetchWithOptions(ctx, g.Runner, dir, gitx.FetchOptions{Remote: opts.Remote, Depth: opts.Depth, NoPruneTags: opts.NoPruneTags})
+	return FetchResult{TagsPruned: res.TagsPruned}, err
+}
+
+func (g *GitAdapter) IsShallow(_ context.Context, dir string) (bool, error) {
+	return gitx.IsShallow(dir)
+}
+
 func (g *GitAdapter) PullRebase(ctx context.Context, dir string) error {
 	return gitx.PullRebase(ctx, g.Runner, dir)
 }
 
+func (g *GitAdapter) CreateBranch(ctx context.Context, dir, name string) error {
+	return gitx.CreateBranch(ctx, g.Runner, dir, name)
+}
+
+func (g *GitAdapter) HasLFS(ctx context.Context, dir string) (bool, error) {
+	return gitx.HasLFS(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) LFSFetch(ctx context.Context, dir string) error {
+	return gitx.LFSFetch(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) SubmoduleUpdate(ctx context.Context, dir string) error {
+	return gitx.SubmoduleUpdate(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) RemoteDefaultBranch(ctx context.Context, dir, remote string) (string, error) {
+	return gitx.RemoteDefaultBranch(ctx, g.Runner, dir, remote)
+}
+
+func (g *GitAdapter) RemoteHeadBranch(ctx context.Context, dir, remote string) (string, error) {
+	return gitx.RemoteHeadBranch(ctx, g.Runner, dir, remote)
+}
+
+func (g *GitAdapter) UpdateRemoteHead(ctx context.Context, dir, remote string) error {
+	return gitx.UpdateRemoteHead(ctx, g.Runner, dir, remote)
+}
+
+func (g *GitAdapter) InspectFingerprint(ctx context.Context, dir string) (string, error) {
+	return gitx.InspectFingerprint(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) MirrorUpdate(ctx context.Context, dir string) error {
+	return gitx.RemoteUpdate(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) DeleteBranch(ctx context.Context, dir, branch string, force bool) error {
+	return gitx.DeleteBranch(ctx, g.Runner, dir, branch, force)
+}
+
+func (g *GitAdapter) Fsck(ctx context.Context, dir string) ([]string, error) {
+	return gitx.Fsck(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) WorktreeRole(ctx context.Context, dir string) (string, string, error) {
+	return gitx.WorktreeRole(dir)
+}
+
+func (g *GitAdapter) InProgressOperation(_ context.Context, dir string) (string, error) {
+	return gitx.InProgressOperation(dir)
+}
+
+func (g *GitAdapter) RecentCommits(ctx context.Context, dir string, n int) ([]model.Commit, error) {
+	return gitx.RecentCommits(ctx, g.Runner, dir, n)
+}
+
 func (g *GitAdapter) Push(ctx context.Context, dir string) error {
 	return gitx.Push(ctx, g.Runner, dir)
 }
@@ -193,10 +437,26 @@ func (g *GitAdapter) SetRemoteURL(ctx context.Context, dir, remote, remoteURL st
 	return gitx.SetRemoteURL(ctx, g.Runner, dir, remote, remoteURL)
 }
 
+func (g *GitAdapter) AddRemote(ctx context.Context, dir, remote, remoteURL string) error {
+	return gitx.AddRemote(ctx, g.Runner, dir, remote, remoteURL)
+}
+
 func (g *GitAdapter) StashPush(ctx context.Context, dir, message string) (bool, error) {
 	return gitx.StashPush(ctx, g.Runner, dir, message)
 }
 
+func (g *GitAdapter) LastCommit(ctx context.Context, dir string) (time.Time, error) {
+	return gitx.LastCommitTime(ctx, g.Runner, dir)
+}
+
+func (g *GitAdapter) CommitsBehind(ctx context.Context, dir, base, branch string) (int, error) {
+	return gitx.CommitsBehind(ctx, g.Runner, dir, base, branch)
+}
+
+func (g *GitAdapter) StashList(ctx context.Context, dir string) (int, error) {
+	return gitx.StashList(ctx, g.Runner, dir)
+}
+
 func (g *GitAdapter) StashPop(ctx context.Context, dir string) error {
 	return gitx.StashPop(ctx, g.Runner, dir)
 }
@@ -209,10 +469,22 @@ func (g *GitAdapter) CleanFD(ctx context.Context, dir string) error {
 	return gitx.CleanFD(ctx, g.Runner, dir)
 }
 
+func (g *GitAdapter) ResetHardTo(ctx context.Context, dir, ref string) error {
+	return gitx.ResetHardTo(ctx, g.Runner, dir, ref)
+}
+
+func (g *GitAdapter) CleanAll(ctx context.Context, dir string) error {
+	return gitx.CleanFDX(ctx, g.Runner, dir)
+}
+
 func (g *GitAdapter) Clone(ctx context.Context, remoteURL, targetPath, branch string, mirror bool) error {
 	return gitx.Clone(ctx, g.Runner, remoteURL, targetPath, branch, mirror)
 }
 
+func (g *GitAdapter) CloneShallow(ctx context.Context, remoteURL, targetPath, branch string, depth int) error {
+	return gitx.CloneShallow(ctx, g.Runner, remoteURL, targetPath, branch, depth)
+}
+
 func (g *GitAdapter) NormalizeURL(rawURL string) string {
 	return gitx.NormalizeURL(rawURL)
 }
@@ -229,5 +501,5 @@ func (g *GitAdapter) SupportsLocalUpdate(context.Context, string) (bool, string,
 
 // FetchAction returns the human-readable safe fetch action for this adapter.
 func (g *GitAdapter) FetchAction(context.Context, string) (string, error) {
-	return "git fetch --all --prune --prune-tags --no-recurse-submodules", nil
+	return gitx.FetchCommand("", true), nil
 }
@@ -2,6 +2,8 @@
 package vcs
 
 import (
+	"sync"
+
 	"github.com/skaphos/repokeeper/internal/gitx"
 )
 
@@ -40,3 +42,34 @@ func (gitURLNormalizer) NormalizeURL(rawURL string) string {
 func NewGitURLNormalizer() URLNormalizer {
 	return gitURLNormalizer{}
 }
+
+// CachingURLNormalizer memoizes another URLNormalizer by raw URL so each
+// distinct URL is normalized once. It is safe for concurrent use. The cache
+// never expires, so create one per command or run rather than sharing it
+// across runs whose normalization rules may differ.
+type CachingURLNormalizer struct {
+	inner URLNormalizer
+	mu    sync.RWMutex
+	cache map[string]string
+}
+
+// NewCachingURLNormalizer returns a CachingURLNormalizer backed by inner.
+func NewCachingURLNormalizer(inner URLNormalizer) *CachingURLNormalizer {
+	return &CachingURLNormalizer{inner: inner, cache: make(map[string]string)}
+}
+
+// NormalizeURL returns the cached normalization of rawURL, computing it with
+// the inner normalizer on first use.
+func (c *CachingURLNormalizer) NormalizeURL(rawURL string) string {
+	c.mu.RLock()
+	normalized, ok := c.cache[rawURL]
+	c.mu.RUnlock()
+	if ok {
+		return normalized
+	}
+	normalized = c.inner.NormalizeURL(rawURL)
+	c.mu.Lock()
+	c.cache[rawURL] = normalized
+	c.mu.Unlock()
+	return normalized
+}
@@ -181,3 +181,39 @@ func TestGitURLNormalizerMatchesGitx(t *testing.T) {
 		}
 	}
 }
+
+type countingURLNormalizer struct {
+	calls map[string]int
+}
+
+func (c *countingURLNormalizer) NormalizeURL(rawURL string) string {
+	c.calls[rawURL]++
+	return gitx.NormalizeURL(rawURL)
+}
+
+func TestCachingURLNormalizerNormalizesEachURLOnce(t *testing.T) {
+	inner := &countingURLNormalizer{calls: map[string]int{}}
+	normalizer := vcs.NewCachingURLNormalizer(inner)
+
+	urls := []string{
+		"git@github.com:Org/Repo.git",
+		"https://github.com/Org/Repo.git",
+		"git@github.com:Org/Repo.git",
+		"",
+		"https://github.com/Org/Repo.git",
+		"",
+	}
+	for _, url := range urls {
+		if got, want := normalizer.NormalizeURL(url), gitx.NormalizeURL(url); got != want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+	if len(inner.calls) != 3 {
+		t.Fatalf("expected 3 distinct URLs normalized, got %v", inner.calls)
+	}
+	for url, n := range inner.calls {
+		if n != 1 {
+			t.Errorf("inner NormalizeURL(%q) called %d times, want 1", url, n)
+		}
+	}
+}
@@ -5,10 +5,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+
+	"github.com/skaphos/repokeeper/internal/gitx"
 )
 
+// WithCommandOutput returns a context under which the adapters write every
+// command they run, with its raw output, to w. See gitx.WithCommandOutput.
+func WithCommandOutput(ctx context.Context, w io.Writer) context.Context {
+	return gitx.WithCommandOutput(ctx, w)
+}
+
 func runCommand(ctx context.Context, dir, bin string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, bin, args...)
 	if strings.TrimSpace(dir) != "" {
@@ -18,7 +27,9 @@ func runCommand(ctx context.Context, dir, bin string, args ...string) (string, e
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+	gitx.RecordCommand(ctx, bin, args, stdout.Bytes(), stderr.Bytes(), err)
+	if err != nil {
 		errText := strings.TrimSpace(stderr.String())
 		if errText != "" {
 			return "", fmt.Errorf("%s %s: %s: %w", bin, strings.Join(args, " "), errText, err)
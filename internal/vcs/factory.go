@@ -4,14 +4,18 @@ package vcs
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/skaphos/repokeeper/internal/model"
 	"github.com/skaphos/repokeeper/internal/strutil"
 )
 
-// ParseAdapterSelection parses --vcs selections.
+// ParseAdapterSelection parses --vcs selections. "auto" must be given on its
+// own.
 func ParseAdapterSelection(raw string) ([]string, error) {
 	values := strutil.SplitCSV(raw)
 	if len(values) == 0 {
@@ -23,8 +27,12 @@ func ParseAdapterSelection(raw string) ([]string, error) {
 		name := strings.ToLower(strings.TrimSpace(value))
 		switch name {
 		case "git", "hg":
+		case "auto":
+			if len(values) > 1 {
+				return nil, fmt.Errorf("vcs auto cannot be combined with other backends")
+			}
 		default:
-			return nil, fmt.Errorf("unsupported vcs %q (supported: git,hg)", value)
+			return nil, fmt.Errorf("unsupported vcs %q (supported: git,hg,auto)", value)
 		}
 		if _, ok := seen[name]; ok {
 			continue
@@ -44,6 +52,9 @@ func NewAdapterForSelection(raw string) (Adapter, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(selected) == 1 && selected[0] == "auto" {
+		return NewAutoAdapter(), nil
+	}
 	adapters := make([]Adapter, 0, len(selected))
 	for _, name := range selected {
 		switch name {
@@ -62,15 +73,45 @@ func NewAdapterForSelection(raw string) (Adapter, error) {
 	}, nil
 }
 
+// NewAutoAdapter returns the --vcs auto adapter. It handles Git and
+// Mercurial repos in one run, picking the backend for each directory from its
+// .git or .hg marker without running either tool. Directories with neither
+// marker fall back to asking each backend in turn.
+// Clones, URL normalization, and primary remote selection use Git's rules;
+// Mercurial's single "default" remote is primary under them too.
+func NewAutoAdapter() *MultiAdapter {
+	return &MultiAdapter{
+		adapters:     []Adapter{NewGitAdapter(nil), NewHgAdapter()},
+		byPath:       map[string]Adapter{},
+		probeMarkers: true,
+	}
+}
+
 // MultiAdapter delegates per-path operations to the first matching backend.
-// This enables --vcs=git,hg scans/status in mixed roots.
+// This enables --vcs=git,hg scans/status in mixed roots. The decision is
+// cached per directory.
 type MultiAdapter struct {
 	adapters []Adapter
 	byPath   map[string]Adapter
 	mu       sync.Mutex
+	// probeMarkers checks for a backend's marker directory (see vcsMarkers)
+	// before asking the backends themselves.
+	probeMarkers bool
 }
 
-func (m *MultiAdapter) Name() string { return "multi" }
+// vcsMarkers maps a backend name to the entry its repos keep at the top of
+// the working tree.
+var vcsMarkers = map[string]string{
+	"git": ".git",
+	"hg":  ".hg",
+}
+
+func (m *MultiAdapter) Name() string {
+	if m.probeMarkers {
+		return "auto"
+	}
+	return "multi"
+}
 
 func (m *MultiAdapter) IsRepo(ctx context.Context, dir string) (bool, error) {
 	adapter, ok := m.detectAdapter(ctx, dir)
@@ -135,6 +176,48 @@ func (m *MultiAdapter) StaleRemoteTrackingRefs(ctx context.Context, dir string,
 	return inspector.StaleRemoteTrackingRefs(ctx, dir, remoteNames)
 }
 
+// IgnoredFiles delegates the optional ignored-file capability to the backend
+// selected for dir. Unsupported backends report no ignored files.
+func (m *MultiAdapter) IgnoredFiles(ctx context.Context, dir string) ([]string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	inspector, ok := adapter.(IgnoredFileInspector)
+	if !ok {
+		return nil, nil
+	}
+	return inspector.IgnoredFiles(ctx, dir)
+}
+
+// StashList delegates the optional stash-count capability to the backend
+// selected for dir. Unsupported backends report no stashes.
+func (m *MultiAdapter) StashList(ctx context.Context, dir string) (int, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+	inspector, ok := adapter.(StashInspector)
+	if !ok {
+		return 0, nil
+	}
+	return inspector.StashList(ctx, dir)
+}
+
+// LastCommit delegates the optional last-commit capability to the backend
+// selected for dir. Unsupported backends report the zero time.
+func (m *MultiAdapter) LastCommit(ctx context.Context, dir string) (time.Time, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return time.Time{}, err
+	}
+	inspector, ok := adapter.(LastCommitInspector)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return inspector.LastCommit(ctx, dir)
+}
+
 // InspectLocalBranches delegates the optional local-branch inspection capability
 // to the backend selected for dir. Unsupported backends report no branches.
 func (m *MultiAdapter) InspectLocalBranches(ctx context.Context, dir, base string, patchEquivalence bool) ([]LocalBranchSignal, error) {
@@ -149,6 +232,20 @@ func (m *MultiAdapter) InspectLocalBranches(ctx context.Context, dir, base strin
 	return inspector.InspectLocalBranches(ctx, dir, base, patchEquivalence)
 }
 
+// CommitsBehind delegates the optional behind-count capability to the backend
+// selected for dir. Unsupported backends report zero commits behind.
+func (m *MultiAdapter) CommitsBehind(ctx context.Context, dir, base, branch string) (int, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return 0, err
+	}
+	counter, ok := adapter.(BranchBehindCounter)
+	if !ok {
+		return 0, nil
+	}
+	return counter.CommitsBehind(ctx, dir, base, branch)
+}
+
 func (m *MultiAdapter) HasSubmodules(ctx context.Context, dir string) (bool, error) {
 	adapter, err := m.adapterForPath(ctx, dir)
 	if err != nil {
@@ -165,6 +262,41 @@ func (m *MultiAdapter) Fetch(ctx context.Context, dir string) error {
 	return adapter.Fetch(ctx, dir)
 }
 
+// IsShallow delegates the optional shallow-clone capability to the backend
+// selected for dir. Unsupported backends report full history.
+func (m *MultiAdapter) IsShallow(ctx context.Context, dir string) (bool, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	inspector, ok := adapter.(ShallowInspector)
+	if !ok {
+		return false, nil
+	}
+	return inspector.IsShallow(ctx, dir)
+}
+
+// FetchWithOptions delegates to the backend selected for dir. Backends without
+// the capability run their default fetch, and fail when opts asks for a single
+// remote or a deepened history; tag pruning is a git concept, so NoPruneTags
+// is ignored for them.
+func (m *MultiAdapter) FetchWithOptions(ctx context.Context, dir string, opts FetchOptions) (FetchResult, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return FetchResult{}, err
+	}
+	if fetcher, ok := adapter.(OptionFetcher); ok {
+		return fetcher.FetchWithOptions(ctx, dir, opts)
+	}
+	switch {
+	case opts.Remote != "":
+		return FetchResult{}, fmt.Errorf("%s does not support fetching a single remote", adapter.Name())
+	case opts.Depth > 0:
+		return FetchResult{}, fmt.Errorf("%s does not support deepening shallow history", adapter.Name())
+	}
+	return FetchResult{}, adapter.Fetch(ctx, dir)
+}
+
 func (m *MultiAdapter) PullRebase(ctx context.Context, dir string) error {
 	adapter, err := m.adapterForPath(ctx, dir)
 	if err != nil {
@@ -173,6 +305,214 @@ func (m *MultiAdapter) PullRebase(ctx context.Context, dir string) error {
 	return adapter.PullRebase(ctx, dir)
 }
 
+// CreateBranch delegates to the backend selected for dir and fails when that
+// backend cannot create branches.
+func (m *MultiAdapter) CreateBranch(ctx context.Context, dir, name string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	creator, ok := adapter.(BranchCreator)
+	if !ok {
+		return fmt.Errorf("%s does not support creating branches", adapter.Name())
+	}
+	return creator.CreateBranch(ctx, dir, name)
+}
+
+// AddRemote delegates to the backend selected for dir and fails when that
+// backend cannot add remotes.
+func (m *MultiAdapter) AddRemote(ctx context.Context, dir, remote, remoteURL string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	adder, ok := adapter.(RemoteAdder)
+	if !ok {
+		return fmt.Errorf("%s does not support adding remotes", adapter.Name())
+	}
+	return adder.AddRemote(ctx, dir, remote, remoteURL)
+}
+
+// HasLFS delegates to the backend selected for dir. Backends without LFS
+// support report false.
+func (m *MultiAdapter) HasLFS(ctx context.Context, dir string) (bool, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+	fetcher, ok := adapter.(LFSFetcher)
+	if !ok {
+		return false, nil
+	}
+	return fetcher.HasLFS(ctx, dir)
+}
+
+// LFSFetch delegates to the backend selected for dir and fails when that
+// backend has no LFS support.
+func (m *MultiAdapter) LFSFetch(ctx context.Context, dir string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	fetcher, ok := adapter.(LFSFetcher)
+	if !ok {
+		return fmt.Errorf("%s does not support git lfs", adapter.Name())
+	}
+	return fetcher.LFSFetch(ctx, dir)
+}
+
+// SubmoduleUpdate delegates to the backend selected for dir and fails when
+// that backend has no submodule support.
+func (m *MultiAdapter) SubmoduleUpdate(ctx context.Context, dir string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	updater, ok := adapter.(SubmoduleUpdater)
+	if !ok {
+		return fmt.Errorf("%s does not support submodules", adapter.Name())
+	}
+	return updater.SubmoduleUpdate(ctx, dir)
+}
+
+// RemoteDefaultBranch delegates to the backend selected for dir and fails when
+// that backend cannot read a remote's default branch.
+func (m *MultiAdapter) RemoteDefaultBranch(ctx context.Context, dir, remote string) (string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	reader, ok := adapter.(RemoteDefaultBranchReader)
+	if !ok {
+		return "", fmt.Errorf("%s does not support remote default branches", adapter.Name())
+	}
+	return reader.RemoteDefaultBranch(ctx, dir, remote)
+}
+
+// RemoteHeadBranch delegates to the backend selected for dir and fails when
+// that backend cannot query a remote's HEAD.
+func (m *MultiAdapter) RemoteHeadBranch(ctx context.Context, dir, remote string) (string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	reader, ok := adapter.(RemoteHeadReader)
+	if !ok {
+		return "", fmt.Errorf("%s does not support querying remote HEAD", adapter.Name())
+	}
+	return reader.RemoteHeadBranch(ctx, dir, remote)
+}
+
+// UpdateRemoteHead delegates to the backend selected for dir and fails when
+// that backend cannot refresh a remote's recorded HEAD.
+func (m *MultiAdapter) UpdateRemoteHead(ctx context.Context, dir, remote string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	updater, ok := adapter.(RemoteHeadUpdater)
+	if !ok {
+		return fmt.Errorf("%s does not support updating remote HEAD", adapter.Name())
+	}
+	return updater.UpdateRemoteHead(ctx, dir, remote)
+}
+
+// InspectFingerprint delegates to the backend selected for dir and fails when
+// that backend cannot fingerprint repos, so the repo is inspected in full.
+func (m *MultiAdapter) InspectFingerprint(ctx context.Context, dir string) (string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	fingerprinter, ok := adapter.(InspectFingerprinter)
+	if !ok {
+		return "", fmt.Errorf("%s does not support inspect fingerprints", adapter.Name())
+	}
+	return fingerprinter.InspectFingerprint(ctx, dir)
+}
+
+// MirrorUpdate delegates to the backend selected for dir, falling back to a
+// plain Fetch for backends without mirror support.
+func (m *MultiAdapter) MirrorUpdate(ctx context.Context, dir string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	updater, ok := adapter.(MirrorUpdater)
+	if !ok {
+		return adapter.Fetch(ctx, dir)
+	}
+	return updater.MirrorUpdate(ctx, dir)
+}
+
+func (m *MultiAdapter) DeleteBranch(ctx context.Context, dir, branch string, force bool) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	deleter, ok := adapter.(BranchDeleter)
+	if !ok {
+		return fmt.Errorf("%s does not support deleting branches", adapter.Name())
+	}
+	return deleter.DeleteBranch(ctx, dir, branch, force)
+}
+
+// Fsck delegates to the backend selected for dir and fails when that backend
+// cannot check repository integrity.
+func (m *MultiAdapter) Fsck(ctx context.Context, dir string) ([]string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	checker, ok := adapter.(IntegrityChecker)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support integrity checks", adapter.Name())
+	}
+	return checker.Fsck(ctx, dir)
+}
+
+// WorktreeRole delegates to the backend selected for dir. Backends without
+// worktrees report an empty role.
+func (m *MultiAdapter) WorktreeRole(ctx context.Context, dir string) (string, string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return "", "", err
+	}
+	inspector, ok := adapter.(WorktreeRoleInspector)
+	if !ok {
+		return "", "", nil
+	}
+	return inspector.WorktreeRole(ctx, dir)
+}
+
+// InProgressOperation delegates to the backend selected for dir. Backends
+// that cannot tell report nothing in progress.
+func (m *MultiAdapter) InProgressOperation(ctx context.Context, dir string) (string, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return "", err
+	}
+	inspector, ok := adapter.(InProgressInspector)
+	if !ok {
+		return "", nil
+	}
+	return inspector.InProgressOperation(ctx, dir)
+}
+
+// RecentCommits delegates to the backend selected for dir and fails when that
+// backend cannot list history.
+func (m *MultiAdapter) RecentCommits(ctx context.Context, dir string, n int) ([]model.Commit, error) {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := adapter.(CommitLister)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support listing commits", adapter.Name())
+	}
+	return lister.RecentCommits(ctx, dir, n)
+}
+
 func (m *MultiAdapter) Push(ctx context.Context, dir string) error {
 	adapter, err := m.adapterForPath(ctx, dir)
 	if err != nil {
@@ -229,10 +569,48 @@ func (m *MultiAdapter) CleanFD(ctx context.Context, dir string) error {
 	return adapter.CleanFD(ctx, dir)
 }
 
+// ResetHardTo delegates to the backend selected for dir and fails when that
+// backend cannot reset to an upstream.
+func (m *MultiAdapter) ResetHardTo(ctx context.Context, dir, ref string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	resetter, ok := adapter.(UpstreamResetter)
+	if !ok {
+		return fmt.Errorf("%s does not support resetting to upstream", adapter.Name())
+	}
+	return resetter.ResetHardTo(ctx, dir, ref)
+}
+
+// CleanAll delegates to the backend selected for dir and fails when that
+// backend cannot reset to an upstream.
+func (m *MultiAdapter) CleanAll(ctx context.Context, dir string) error {
+	adapter, err := m.adapterForPath(ctx, dir)
+	if err != nil {
+		return err
+	}
+	resetter, ok := adapter.(UpstreamResetter)
+	if !ok {
+		return fmt.Errorf("%s does not support resetting to upstream", adapter.Name())
+	}
+	return resetter.CleanAll(ctx, dir)
+}
+
 func (m *MultiAdapter) Clone(ctx context.Context, remoteURL, targetPath, branch string, mirror bool) error {
 	return m.adapters[0].Clone(ctx, remoteURL, targetPath, branch, mirror)
 }
 
+// CloneShallow delegates to the primary backend and fails when that backend
+// cannot clone shallow history.
+func (m *MultiAdapter) CloneShallow(ctx context.Context, remoteURL, targetPath, branch string, depth int) error {
+	cloner, ok := m.adapters[0].(ShallowCloner)
+	if !ok {
+		return fmt.Errorf("%s does not support shallow clones", m.adapters[0].Name())
+	}
+	return cloner.CloneShallow(ctx, remoteURL, targetPath, branch, depth)
+}
+
 func (m *MultiAdapter) NormalizeURL(rawURL string) string {
 	return NewGitAdapter(nil).NormalizeURL(rawURL)
 }
@@ -266,7 +644,7 @@ func (m *MultiAdapter) FetchAction(ctx context.Context, dir string) (string, err
 		FetchAction(context.Context, string) (string, error)
 	})
 	if !ok {
-		return "git fetch --all --prune --prune-tags --no-recurse-submodules", nil
+		return GitFetchAction("", true), nil
 	}
 	return provider.FetchAction(ctx, dir)
 }
@@ -288,6 +666,18 @@ func (m *MultiAdapter) adapterForPath(ctx context.Context, dir string) (Adapter,
 }
 
 func (m *MultiAdapter) detectAdapter(ctx context.Context, dir string) (Adapter, bool) {
+	if m.probeMarkers {
+		for _, adapter := range m.adapters {
+			marker, ok := vcsMarkers[adapter.Name()]
+			if !ok {
+				continue
+			}
+			// .git is a file in linked worktrees and submodules, so any entry counts.
+			if _, err := os.Lstat(filepath.Join(dir, marker)); err == nil {
+				return adapter, true
+			}
+		}
+	}
 	for _, adapter := range m.adapters {
 		ok, err := adapter.IsRepo(ctx, dir)
 		if err != nil || !ok {
@@ -4,6 +4,9 @@ package vcs
 import (
 	"context"
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/skaphos/repokeeper/internal/model"
@@ -20,6 +23,8 @@ func TestParseAdapterSelection(t *testing.T) {
 		{name: "single", raw: "hg", want: []string{"hg"}},
 		{name: "multi", raw: "git,hg", want: []string{"git", "hg"}},
 		{name: "dedupe", raw: "git,git,hg", want: []string{"git", "hg"}},
+		{name: "auto", raw: "auto", want: []string{"auto"}},
+		{name: "auto combined", raw: "auto,hg", hasErr: true},
 		{name: "invalid", raw: "svn", hasErr: true},
 	}
 
@@ -220,6 +225,14 @@ func TestNewAdapterForSelection(t *testing.T) {
 		t.Fatalf("unexpected adapter: %s", adapter.Name())
 	}
 
+	adapter, err = NewAdapterForSelection("auto")
+	if err != nil {
+		t.Fatalf("auto selection error: %v", err)
+	}
+	if adapter.Name() != "auto" {
+		t.Fatalf("unexpected adapter: %s", adapter.Name())
+	}
+
 	if _, err := NewAdapterForSelection("svn"); err == nil {
 		t.Fatal("expected empty selection error")
 	}
@@ -299,3 +312,49 @@ func TestMultiAdapterDelegatesAllMethods(t *testing.T) {
 		t.Fatalf("FetchAction unexpected result: action=%q err=%v", action, err)
 	}
 }
+
+func TestAutoAdapterDetectsBackendPerRepo(t *testing.T) {
+	root := t.TempDir()
+	gitRepo := filepath.Join(root, "git-repo")
+	if out, err := exec.Command("git", "init", "-q", gitRepo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v %s", err, out)
+	}
+	// A stub Mercurial repo: the marker alone decides, so hg need not be installed.
+	hgRepo := filepath.Join(root, "hg-repo")
+	plain := filepath.Join(root, "plain")
+	for _, dir := range []string{filepath.Join(hgRepo, ".hg"), plain} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	auto := NewAutoAdapter()
+	ctx := context.Background()
+	for dir, want := range map[string]string{gitRepo: "git", hgRepo: "hg"} {
+		ok, err := auto.IsRepo(ctx, dir)
+		if err != nil || !ok {
+			t.Fatalf("IsRepo(%s) = %t, %v", dir, ok, err)
+		}
+		adapter, err := auto.adapterForPath(ctx, dir)
+		if err != nil {
+			t.Fatalf("adapterForPath(%s): %v", dir, err)
+		}
+		if adapter.Name() != want {
+			t.Fatalf("%s: expected %s adapter, got %s", dir, want, adapter.Name())
+		}
+	}
+	if ok, _ := auto.IsRepo(ctx, plain); ok {
+		t.Fatal("expected a plain directory not to be a repo")
+	}
+
+	// The decision is cached, so removing the marker does not change it.
+	if err := os.RemoveAll(filepath.Join(hgRepo, ".hg")); err != nil {
+		t.Fatal(err)
+	}
+	if adapter, err := auto.adapterForPath(ctx, hgRepo); err != nil || adapter.Name() != "hg" {
+		t.Fatalf("expected the cached hg adapter, got %v, %v", adapter, err)
+	}
+	if got := auto.PrimaryRemote([]string{"default"}); got != "default" {
+		t.Fatalf("expected hg default remote to be primary, got %q", got)
+	}
+}
@@ -48,8 +48,32 @@ func (h *HgAdapter) WorktreeStatus(ctx context.Context, dir string) (*model.Work
 	if err != nil {
 		return nil, err
 	}
-	dirty := strings.TrimSpace(out) != ""
-	return &model.Worktree{Dirty: dirty}, nil
+	return parseHgStatus(out), nil
+}
+
+// parseHgStatus maps `hg status` codes onto the git-shaped worktree counts.
+// Mercurial has no index, so scheduled adds/removes are reported as staged and
+// content edits or missing files as unstaged.
+func parseHgStatus(out string) *model.Worktree {
+	wt := &model.Worktree{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'A', 'R':
+			wt.Staged++
+		case 'M', '!':
+			wt.Unstaged++
+		case '?':
+			wt.Untracked++
+		default:
+			continue
+		}
+		wt.Dirty = true
+	}
+	return wt
 }
 
 func (h *HgAdapter) TrackingStatus(context.Context, string) (model.Tracking, error) {
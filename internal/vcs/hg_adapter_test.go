@@ -195,3 +195,13 @@ func TestHgAdapterIsRepoGracefullyHandlesCommandError(t *testing.T) {
 		t.Fatal("expected IsRepo false when hg root fails")
 	}
 }
+
+func TestParseHgStatusCounts(t *testing.T) {
+	wt := parseHgStatus("M edited.txt\nA added.txt\nR removed.txt\n! missing.txt\n? new.txt\n? other.txt\n")
+	if !wt.Dirty || wt.Staged != 2 || wt.Unstaged != 2 || wt.Untracked != 2 {
+		t.Fatalf("unexpected worktree counts: %+v", wt)
+	}
+	if clean := parseHgStatus(""); clean.Dirty {
+		t.Fatalf("expected clean worktree for empty status, got %+v", clean)
+	}
+}